@@ -0,0 +1,56 @@
+// Command itemsync loads data/items into the items table, the same sync the server runs at
+// startup, for deployments that turn that off with ITEM_CATALOG_SYNC=false.
+//
+//	go run ./backend/cmd/itemsync -data ./data [-force]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/config"
+	"github.com/ctclostio/DnD-Game/backend/internal/database"
+	"github.com/ctclostio/DnD-Game/backend/internal/services"
+)
+
+func main() {
+	dataPath := flag.String("data", "data", "directory holding the items folder")
+	force := flag.Bool("force", false, "sync even if the database already holds this catalog version")
+	flag.Parse()
+
+	if err := run(*dataPath, *force); err != nil {
+		fmt.Fprintf(os.Stderr, "itemsync: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(dataPath string, force bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	catalog, err := services.NewItemCatalog(dataPath)
+	if err != nil {
+		return err
+	}
+
+	db, repos, err := database.Initialize(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+
+	sync, err := services.NewItemLibraryService(repos.ItemLibrary, catalog).SyncCatalog(context.Background(), force)
+	if err != nil {
+		return err
+	}
+	if sync.Skipped {
+		fmt.Printf("item catalog %s is already synced (%d items)\n", sync.Version, sync.ItemCount)
+		return nil
+	}
+	fmt.Printf("synced %d items from catalog version %s\n", sync.ItemCount, sync.Version)
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
@@ -13,10 +14,13 @@ import (
 	"github.com/rs/cors"
 
 	"github.com/ctclostio/DnD-Game/backend/internal/auth"
+	"github.com/ctclostio/DnD-Game/backend/internal/cache"
 	"github.com/ctclostio/DnD-Game/backend/internal/config"
 	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/crdt"
 	"github.com/ctclostio/DnD-Game/backend/internal/database"
 	"github.com/ctclostio/DnD-Game/backend/internal/handlers"
+	"github.com/ctclostio/DnD-Game/backend/internal/jobs"
 	"github.com/ctclostio/DnD-Game/backend/internal/middleware"
 	"github.com/ctclostio/DnD-Game/backend/internal/routes"
 	"github.com/ctclostio/DnD-Game/backend/internal/services"
@@ -53,18 +57,24 @@ func main() {
 	// Start refresh token cleanup
 	startRefreshTokenCleanup(svc.RefreshTokens, log)
 
+	// Start background jobs
+	jobQueue := startJobQueue(cfg, svc, log)
+
 	// Initialize WebSocket hub
-	hub := initializeWebSocket(jwtManager, log)
+	hub := initializeWebSocket(cfg, repos, jwtManager, log)
 
 	// Create handlers
 	h := handlers.NewHandlers(svc, db, hub)
 	log.Info().Msg("Handlers initialized")
 
+	// Start collaborative document sync
+	crdtHandler := initializeCRDT(cfg, repos, jwtManager, log)
+
 	// Setup HTTP server
-	handler := setupHTTPServer(cfg, h, jwtManager, log)
+	handler := setupHTTPServer(cfg, h, crdtHandler, jwtManager, log)
 
 	// Run server and handle shutdown
-	runServer(cfg, handler, svc.RefreshTokens, hub, log)
+	runServer(cfg, handler, svc.RefreshTokens, hub, jobQueue, log)
 
 	log.Info().Msg("Server shutdown complete")
 }
@@ -185,8 +195,16 @@ func initializeServices(
 	// Token service
 	refreshTokenService := services.NewRefreshTokenService(repos.RefreshTokens, jwtManager)
 
+	// Character services, each recording sheet history through the version service
+	characterVersionService := services.NewCharacterVersionService(repos.CharacterVersions, repos.Characters)
+	characterService := services.NewCharacterService(repos.Characters, repos.CustomClasses, llmProvider)
+	characterService.SetVersionService(characterVersionService)
+	characterResourceService := services.NewCharacterResourceService(repos.CharacterResources, repos.Characters)
+	characterResourceService.SetVersionService(characterVersionService)
+
 	// Combat services
 	combatService := services.NewCombatService()
+	combatService.SetResourceService(characterResourceService)
 	combatAutomationService := services.NewCombatAutomationService(repos.CombatAnalytics, repos.Characters, repos.NPCs)
 	combatAnalyticsService := services.NewCombatAnalyticsService(repos.CombatAnalytics, combatService)
 
@@ -196,6 +214,7 @@ func initializeServices(
 	factionSystem := services.NewFactionSystemService(llmProvider, worldBuildingRepo)
 	worldEventEngine := services.NewWorldEventEngineService(llmProvider, worldBuildingRepo, factionSystem)
 	economicSimulator := services.NewEconomicSimulatorService(worldBuildingRepo)
+	economicSimulator.SetTradeRepository(worldBuildingRepo)
 
 	// Narrative engine
 	narrativeEngine, err := services.NewNarrativeEngine(cfg)
@@ -212,25 +231,146 @@ func initializeServices(
 	balanceAnalyzer := services.NewAIBalanceAnalyzer(cfg, llmProvider, ruleEngine, combatService)
 	conditionalReality := services.NewConditionalRealitySystem(ruleEngine)
 
+	// Inventory services
+	inventoryService := services.NewInventoryService(repos.Inventory, repos.Characters)
+	inventoryService.SetVersionService(characterVersionService)
+	characterResourceService.SetInventoryService(inventoryService)
+	inventoryService.SetGameSessionRepository(repos.GameSessions)
+	combatService.SetInventoryService(inventoryService)
+	dataPath := filepath.Join(".", "data")
+	itemCatalog, err := services.NewItemCatalog(dataPath)
+	var startingEquipmentService *services.StartingEquipmentService
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load item catalog - starting equipment disabled")
+		itemCatalog = nil
+	} else {
+		startingEquipmentService = services.NewStartingEquipmentService(dataPath, itemCatalog, inventoryService, repos.Inventory, repos.Characters)
+	}
+
+	// Keep the items table in step with data/items; homebrew items live alongside it
+	itemLibraryService := services.NewItemLibraryService(repos.ItemLibrary, itemCatalog)
+	if itemCatalog != nil && getEnvOrDefault("ITEM_CATALOG_SYNC", "true") == "true" {
+		if sync, err := itemLibraryService.SyncCatalog(context.Background(), false); err != nil {
+			log.Error().Err(err).Msg("Failed to sync item catalog")
+		} else {
+			log.Info().Str("version", sync.Version).Int("items", sync.ItemCount).Bool("skipped", sync.Skipped).Msg("Item catalog synced")
+		}
+	}
+
+	// Settlement shops priced by the economy simulator
+	shopService := services.NewShopService(worldBuildingRepo, economicSimulator, inventoryService, repos.Inventory, repos.Characters)
+	if itemCatalog != nil {
+		shopService.SetItemCatalog(itemCatalog)
+	}
+
+	// Party stashes and trades between characters
+	partyService := services.NewPartyService(repos.Parties, inventoryService, repos.Inventory)
+
+	// Downtime crafting from data/recipes and per-session homebrew
+	craftingService := services.NewCraftingService(repos.Crafting, inventoryService, repos.Inventory, repos.Characters, diceRollService)
+	if itemCatalog != nil {
+		if recipeCatalog, err := services.NewRecipeCatalog(dataPath, itemCatalog); err != nil {
+			log.Error().Err(err).Msg("Failed to load recipes - only homebrew recipes available")
+		} else {
+			craftingService.SetRecipeCatalog(recipeCatalog, itemCatalog)
+		}
+	}
+
+	// Loot pools for fights and encounter objectives
+	lootService := services.NewLootService(repos.LootPools, inventoryService, repos.Inventory, repos.GameSessions)
+	if itemCatalog != nil {
+		lootService.SetItemCatalog(itemCatalog)
+	}
+	lootService.SetCharacterService(characterService)
+	combatAutomationService.SetLootService(lootService)
+	encounterService := services.NewEncounterService(repos.Encounters, aiEncounterBuilder, combatService)
+	encounterService.SetLootService(lootService)
+
+	// Treasure rolled on the treasure tables, optionally described by the AI
+	var treasureService *services.TreasureService
+	if itemCatalog != nil {
+		if treasureTables, err := services.NewTreasureTables(dataPath, itemCatalog); err != nil {
+			log.Error().Err(err).Msg("Failed to load treasure tables - treasure generation disabled")
+		} else {
+			treasureService = services.NewTreasureService(treasureTables)
+			treasureService.SetLLMProvider(llmProvider)
+			treasureService.SetLootService(lootService)
+		}
+	}
+
+	// Spell management
+	var spellManagementService *services.SpellManagementService
+	if spellCatalog, err := services.NewSpellCatalog(dataPath); err != nil {
+		log.Error().Err(err).Msg("Failed to load spell catalog - spell management disabled")
+	} else {
+		spellManagementService = services.NewSpellManagementService(dataPath, spellCatalog, repos.Characters, repos.Inventory)
+		spellManagementService.SetCustomClassRepository(repos.CustomClasses)
+		spellManagementService.SetResourceService(characterResourceService)
+		spellManagementService.SetVersionService(characterVersionService)
+		inventoryService.SetSpellService(spellManagementService)
+	}
+
+	// Character import and export
+	characterExportService := services.NewCharacterExportService(dataPath, itemCatalog, repos.Characters, repos.Inventory)
+	characterExportService.SetVersionService(characterVersionService)
+
 	// Game session service with security dependencies
 	gameSessionService := services.NewGameSessionService(repos.GameSessions)
 	gameSessionService.SetCharacterRepository(repos.Characters)
 	gameSessionService.SetUserRepository(repos.Users)
 
+	// One event log per session that rolls, chat, combat, NPC dialog, loot and experience
+	// are recorded in, and that timelines, recaps, combat analytics and exports read from
+	gameEventService := services.NewGameEventService(repos.GameEvents)
+	diceRollService.SetEventLog(gameEventService)
+	combatService.SetEventLog(gameEventService)
+	inventoryService.SetEventLog(gameEventService)
+	lootService.SetEventLog(gameEventService)
+	chatService := services.NewChatService(repos.Chat, repos.GameSessions, diceRollService)
+	chatService.SetEventLog(gameEventService)
+	dmAssistantService := services.NewDMAssistantService(repos.DMAssistant, aiDMAssistant)
+	dmAssistantService.SetEventLog(gameEventService)
+	campaignService := services.NewCampaignService(repos.Campaign, repos.GameSessions, aiCampaignManager)
+	campaignService.SetEventLog(gameEventService)
+	combatAnalyticsService.SetEventLog(gameEventService)
+	characterExportService.SetEventLog(gameEventService)
+
+	// Planned sessions, RSVPs and availability polls; startJobQueue sends their reminders
+	schedulingService := services.NewSchedulingService(repos.Schedules, repos.GameSessions, repos.Users)
+
+	// Invites to private sessions; startJobQueue emails the ones sent to an address
+	gameInviteService := services.NewGameInviteService(repos.GameInvites, gameSessionService, repos.Users)
+
 	// Aggregate all services
 	return &services.Services{
 		DB:                 db,
 		Users:              services.NewUserService(repos.Users),
-		Characters:         services.NewCharacterService(repos.Characters, repos.CustomClasses, llmProvider),
+		Characters:         characterService,
+		CharacterVersions:  characterVersionService,
 		GameSessions:       gameSessionService,
 		DiceRolls:          diceRollService,
+		Chat:               chatService,
+		GameEvents:         gameEventService,
+		Scheduling:         schedulingService,
+		GameInvites:        gameInviteService,
 		Combat:             combatService,
 		NPCs:               services.NewNPCService(repos.NPCs),
-		Inventory:          services.NewInventoryService(repos.Inventory, repos.Characters),
+		Inventory:          inventoryService,
+		ItemCatalog:        itemCatalog,
+		ItemLibrary:        itemLibraryService,
+		Shops:              shopService,
+		Parties:            partyService,
+		Crafting:           craftingService,
+		Loot:               lootService,
+		Treasure:           treasureService,
+		StartingEquipment:  startingEquipmentService,
+		CharacterResources: characterResourceService,
+		SpellManagement:    spellManagementService,
+		CharacterExport:    characterExportService,
 		CustomRaces:        services.NewCustomRaceService(repos.CustomRaces, aiRaceGenerator),
-		DMAssistant:        services.NewDMAssistantService(repos.DMAssistant, aiDMAssistant),
-		Encounters:         services.NewEncounterService(repos.Encounters, aiEncounterBuilder, combatService),
-		Campaign:           services.NewCampaignService(repos.Campaign, repos.GameSessions, aiCampaignManager),
+		DMAssistant:        dmAssistantService,
+		Encounters:         encounterService,
+		Campaign:           campaignService,
 		CombatAutomation:   combatAutomationService,
 		CombatAnalytics:    combatAnalyticsService,
 		SettlementGen:      settlementGenerator,
@@ -257,18 +397,93 @@ func startRefreshTokenCleanup(service *services.RefreshTokenService, log *logger
 	log.Info().Msg("Refresh token cleanup task started")
 }
 
-// initializeWebSocket initializes the WebSocket hub
-func initializeWebSocket(jwtManager *auth.JWTManager, log *logger.LoggerV2) *websocket.Hub {
-	hub := websocket.InitHub()
+// startJobQueue runs background jobs through Redis, which is how scheduled sessions
+// email their reminders and invites are emailed. It returns nil, leaving that mail off,
+// when no SMTP server is configured or Redis can't be reached.
+func startJobQueue(cfg *config.Config, svc *services.Services, log *logger.LoggerV2) *jobs.JobQueue {
+	if cfg.Email.SMTPHost == "" {
+		log.Warn().Msg("SMTP_HOST not set - session reminders and invites are not emailed")
+		return nil
+	}
+	queue, err := jobs.NewJobQueue(&cfg.Redis, log)
+	if err == nil {
+		if err = queue.Ping(); err == nil {
+			email := services.NewSMTPEmailService(cfg.Email)
+			jobs.SetupNotifications(queue, jobs.NewJobHandlers(log, nil, email, nil, nil, nil, nil), svc.Scheduling, svc.GameInvites)
+			if err = queue.Start(); err == nil {
+				log.Info().Msg("Job queue started")
+				return queue
+			}
+		}
+		_ = queue.Stop()
+		svc.Scheduling.SetReminderQueue(nil)
+		svc.GameInvites.SetEmailQueue(nil)
+	}
+	log.Error().Err(err).Msg("Failed to start job queue - session reminders and invites are not emailed")
+	return nil
+}
+
+// initializeWebSocket initializes the WebSocket hub. With WEBSOCKET_BACKEND=redis, rooms
+// are shared through Redis so every backend replica sees the same games. Otherwise rooms
+// stay on this node, keeping their sequence numbers and replay buffers in the database so
+// reconnecting players are caught up after a restart.
+func initializeWebSocket(cfg *config.Config, repos *database.Repositories, jwtManager *auth.JWTManager, log *logger.LoggerV2) *websocket.Hub {
+	backend := startWebSocketBackend(cfg, log)
+	clustered := backend != nil
+	if !clustered {
+		memory := websocket.NewMemoryBackend()
+		memory.SetReplayStore(repos.RoomMessages)
+		backend = memory
+	}
+	hub := websocket.InitHubWithBackend(backend)
 	websocket.SetJWTManager(jwtManager)
-	log.Info().Msg("WebSocket hub started")
+	log.Info().Bool("clustered", clustered).Msg("WebSocket hub started")
 	return hub
 }
 
+// startWebSocketBackend connects a node to Redis when WEBSOCKET_BACKEND=redis. It returns
+// nil when rooms stay on this node.
+func startWebSocketBackend(cfg *config.Config, log *logger.LoggerV2) websocket.Backend {
+	if getEnvOrDefault("WEBSOCKET_BACKEND", "memory") != "redis" {
+		return nil
+	}
+	redisClient, err := cache.NewRedisClient(&cfg.Redis, log)
+	if err == nil {
+		var backend *websocket.RedisBackend
+		if backend, err = websocket.NewRedisBackend(redisClient); err == nil {
+			return backend
+		}
+	}
+	log.Error().Err(err).Msg("Failed to start Redis websocket backend - rooms stay on this node")
+	return nil
+}
+
+// initializeCRDT loads collaborative documents from the database on demand, compacting
+// and evicting them in the background. Character sheets can be synced by their owner and
+// the people they play with, party notes and battle map annotations by the session's DM
+// and players, and session prep by the DM alone. Clustered servers share changes through
+// a websocket backend of their own.
+func initializeCRDT(cfg *config.Config, repos *database.Repositories, jwtManager *auth.JWTManager, log *logger.LoggerV2) *crdt.Handler {
+	manager := crdt.NewManager(repos.CRDTDocuments)
+	if backend := startWebSocketBackend(cfg, log); backend != nil {
+		manager.SetBackend(backend)
+	}
+	manager.StartMaintenance(time.Minute)
+	log.Info().Msg("CRDT document maintenance started")
+
+	handler := crdt.NewHandler(manager, jwtManager)
+	handler.SetAccess(crdt.DocumentCharacter, repos.CRDTDocuments.CanAccessCharacter)
+	handler.SetAccess(crdt.DocumentPartyNotes, repos.CRDTDocuments.CanAccessPartyNotes)
+	handler.SetAccess(crdt.DocumentBattleMapAnnotations, repos.CRDTDocuments.CanAccessBattleMap)
+	handler.SetAccess(crdt.DocumentSessionPrep, repos.CRDTDocuments.CanAccessSessionPrep)
+	return handler
+}
+
 // setupHTTPServer configures the HTTP server with all middleware and routes
 func setupHTTPServer(
 	cfg *config.Config,
 	h *handlers.Handlers,
+	crdtHandler *crdt.Handler,
 	jwtManager *auth.JWTManager,
 	log *logger.LoggerV2,
 ) http.Handler {
@@ -295,12 +510,14 @@ func setupHTTPServer(
 
 	// Setup route config
 	routeConfig := &routes.Config{
-		Handlers:        h,
-		AuthMiddleware:  authMiddleware,
-		CSRFStore:       csrfStore,
-		AuthRateLimiter: authRateLimiter,
-		APIRateLimiter:  apiRateLimiter,
-		IsProduction:    !isDevelopment,
+		Handlers:         h,
+		WebSocketHandler: h.WebSocketHandler(log.WithOperation("websocket", "handler")),
+		CRDTHandler:      crdtHandler,
+		AuthMiddleware:   authMiddleware,
+		CSRFStore:        csrfStore,
+		AuthRateLimiter:  authRateLimiter,
+		APIRateLimiter:   apiRateLimiter,
+		IsProduction:     !isDevelopment,
 	}
 
 	// Setup all routes
@@ -331,6 +548,7 @@ func runServer(
 	handler http.Handler,
 	refreshTokenService *services.RefreshTokenService,
 	hub *websocket.Hub,
+	jobQueue *jobs.JobQueue,
 	log *logger.LoggerV2,
 ) {
 	srv := &http.Server{
@@ -379,6 +597,12 @@ func runServer(
 	if err := hub.Shutdown(ctx); err != nil {
 		log.Error().Err(err).Msg("Failed to shutdown websocket hub")
 	}
+
+	if jobQueue != nil {
+		if err := jobQueue.Stop(); err != nil {
+			log.Error().Err(err).Msg("Failed to stop job queue")
+		}
+	}
 }
 
 // Helper function to get environment variable with default
@@ -0,0 +1,25 @@
+// Command wsschema prints the machine-readable description of the websocket protocol,
+// the same document the server serves at /api/v1/ws/schema.
+//
+//	go run ./backend/cmd/wsschema > websocket-protocol.json
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/handlers"
+	"github.com/ctclostio/DnD-Game/backend/internal/services"
+)
+
+func main() {
+	protocol := handlers.NewHandlers(&services.Services{}, nil, nil).WebSocketProtocol()
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(protocol.Schema()); err != nil {
+		fmt.Fprintf(os.Stderr, "wsschema: %v\n", err)
+		os.Exit(1)
+	}
+}
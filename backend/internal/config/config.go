@@ -14,6 +14,7 @@ type Config struct {
 	Redis    RedisConfig
 	Auth     AuthConfig
 	AI       AIConfig
+	Email    EmailConfig
 }
 
 // ServerConfig holds server-related configuration
@@ -59,6 +60,15 @@ type AIConfig struct {
 	Enabled  bool
 }
 
+// EmailConfig holds the SMTP server outgoing mail is sent through
+type EmailConfig struct {
+	SMTPHost string // Mail is only sent when set
+	SMTPPort int
+	Username string
+	Password string
+	From     string
+}
+
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	cfg := &Config{}
@@ -95,6 +105,13 @@ func Load() (*Config, error) {
 	cfg.AI.APIKey = getEnv("AI_API_KEY", "")
 	cfg.AI.Model = getEnv("AI_MODEL", "gpt-4-turbo-preview") // Default model
 
+	// Email configuration
+	cfg.Email.SMTPHost = getEnv("SMTP_HOST", "")
+	cfg.Email.SMTPPort = getEnvAsInt("SMTP_PORT", 587)
+	cfg.Email.Username = getEnv("SMTP_USERNAME", "")
+	cfg.Email.Password = getEnv("SMTP_PASSWORD", "")
+	cfg.Email.From = getEnv("EMAIL_FROM", "noreply@dndgame.local")
+
 	return cfg, nil
 }
 
@@ -21,6 +21,7 @@ func TestLoad(t *testing.T) {
 		"REDIS_HOST", "REDIS_PORT", "REDIS_PASSWORD", "REDIS_DB",
 		"JWT_SECRET", "ACCESS_TOKEN_DURATION", "REFRESH_TOKEN_DURATION", "BCRYPT_COST",
 		"AI_PROVIDER", "AI_API_KEY", "AI_MODEL",
+		"SMTP_HOST", "SMTP_PORT", "SMTP_USERNAME", "SMTP_PASSWORD", "EMAIL_FROM",
 	}
 	for _, key := range envVars {
 		originalEnv[key] = os.Getenv(key)
@@ -68,6 +69,10 @@ func TestLoad(t *testing.T) {
 		assert.Equal(t, "mock", cfg.AI.Provider)
 		assert.Equal(t, "", cfg.AI.APIKey)
 		assert.Equal(t, "gpt-4-turbo-preview", cfg.AI.Model)
+
+		assert.Equal(t, "", cfg.Email.SMTPHost) // No mail without an SMTP server
+		assert.Equal(t, 587, cfg.Email.SMTPPort)
+		assert.Equal(t, "noreply@dndgame.local", cfg.Email.From)
 	})
 
 	t.Run("loads from environment variables", func(t *testing.T) {
@@ -94,6 +99,11 @@ func TestLoad(t *testing.T) {
 		require.NoError(t, os.Setenv("AI_PROVIDER", "openai"))
 		require.NoError(t, os.Setenv("AI_API_KEY", "test-api-key"))
 		require.NoError(t, os.Setenv("AI_MODEL", "gpt-4"))
+		require.NoError(t, os.Setenv("SMTP_HOST", "smtp-host"))
+		require.NoError(t, os.Setenv("SMTP_PORT", "2525"))
+		require.NoError(t, os.Setenv("SMTP_USERNAME", "smtp-user"))
+		require.NoError(t, os.Setenv("SMTP_PASSWORD", "smtp-pass"))
+		require.NoError(t, os.Setenv("EMAIL_FROM", "dm@example.com"))
 
 		cfg, err := Load()
 		require.NoError(t, err)
@@ -120,6 +130,11 @@ func TestLoad(t *testing.T) {
 		assert.Equal(t, "openai", cfg.AI.Provider)
 		assert.Equal(t, "test-api-key", cfg.AI.APIKey)
 		assert.Equal(t, "gpt-4", cfg.AI.Model)
+		assert.Equal(t, "smtp-host", cfg.Email.SMTPHost)
+		assert.Equal(t, 2525, cfg.Email.SMTPPort)
+		assert.Equal(t, "smtp-user", cfg.Email.Username)
+		assert.Equal(t, "smtp-pass", cfg.Email.Password)
+		assert.Equal(t, "dm@example.com", cfg.Email.From)
 	})
 
 	t.Run("handles invalid port", func(t *testing.T) {
@@ -7,6 +7,7 @@ const (
 	ClassCleric    = "cleric"
 	ClassDruid     = "druid"
 	ClassFighter   = "fighter"
+	ClassMonk      = "monk"
 	ClassPaladin   = "paladin"
 	ClassRanger    = "ranger"
 	ClassSorcerer  = "sorcerer"
@@ -95,8 +96,10 @@ const (
 	OutcomeVictory         = "victory"
 	OutcomeDecisiveVictory = "decisive_victory"
 	OutcomeHit             = "hit"
+	OutcomeMiss            = "miss"
 	OutcomeKillingBlow     = "killing_blow"
 	OutcomeCostlyVictory   = "costly_victory"
+	OutcomeRetreat         = "retreat"
 	OutcomeDefeat          = "defeat"
 	// RelationNeutral can be used for neutral outcome
 )
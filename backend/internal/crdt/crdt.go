@@ -1,49 +1,468 @@
 package crdt
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/automerge/automerge-go"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/internal/websocket"
+	"github.com/ctclostio/DnD-Game/backend/pkg/logger"
+)
+
+const (
+	// compactAfterChanges folds a document's change log into its snapshot once it grows
+	// this long
+	compactAfterChanges = 200
+	// compactInterval is how often a document with new changes is compacted anyway
+	compactInterval = 5 * time.Minute
+	// idleTimeout is how long a document nobody is syncing stays in memory
+	idleTimeout = 10 * time.Minute
+	// roomPrefix names the backend room a document's changes are published to
+	roomPrefix = "crdt:"
 )
 
-var store = struct {
-	sync.RWMutex
-	docs map[string]*automerge.Doc
-}{docs: make(map[string]*automerge.Doc)}
+// Store persists documents as a snapshot plus the incremental changes made since.
+// database.CRDTDocumentRepository implements it.
+type Store interface {
+	LoadDocument(ctx context.Context, id string) (*models.CRDTDocument, error)
+	AppendChange(ctx context.Context, documentID string, data []byte) (int64, error)
+	CompactDocument(ctx context.Context, documentID string, snapshot []byte, changeIDs []int64) error
+}
+
+// Manager keeps the documents being synced in memory. Every change a client syncs is
+// appended to the store before it is acknowledged, the change log is compacted into a
+// snapshot from time to time, and documents nobody has synced for a while are evicted.
+// With a backend, changes are also published to the other server nodes syncing the
+// document.
+type Manager struct {
+	store           Store
+	backend         websocket.Backend
+	compactInterval time.Duration
+	idleTimeout     time.Duration
+
+	mu   sync.Mutex
+	docs map[string]*Document
+}
+
+// Document is an automerge document loaded by a Manager
+type Document struct {
+	id     string
+	doc    *automerge.Doc
+	loaded chan struct{}
+	err    error
+
+	// guarded by Manager.mu
+	refs     int
+	lastUsed time.Time
+
+	// mu serializes applying changes with persisting them, and guards every peer's sync
+	// state
+	mu    sync.Mutex
+	peers map[*Peer]struct{}
+	// changeIDs are the stored changes the document includes, which compaction may drop
+	changeIDs   map[int64]struct{}
+	unsaved     bool
+	compactedAt time.Time
+}
+
+// remoteChange is a change published to the other nodes syncing a document. ChangeID is
+// zero when the change could not be stored.
+type remoteChange struct {
+	NodeID   string `json:"nodeId"`
+	ChangeID int64  `json:"changeId"`
+	Data     []byte `json:"data"`
+}
+
+// Peer is one connection syncing a document. Its Notify channel fires whenever the
+// server has something to send it: a reply to its own message or changes made by
+// another peer.
+type Peer struct {
+	doc    *Document
+	state  *automerge.SyncState
+	notify chan struct{}
+}
+
+// NewManager creates a manager persisting documents to store
+func NewManager(store Store) *Manager {
+	return &Manager{
+		store:           store,
+		compactInterval: compactInterval,
+		idleTimeout:     idleTimeout,
+		docs:            make(map[string]*Document),
+	}
+}
+
+// SetBackend shares changes with the other server nodes through backend. The manager
+// reads the backend's messages itself, so it must not be a hub's backend too.
+func (m *Manager) SetBackend(backend websocket.Backend) {
+	m.backend = backend
+	go m.receiveRemote()
+}
+
+// Open returns a document, loading it from the store unless it is already in memory.
+// Release it when done.
+func (m *Manager) Open(ctx context.Context, id string) (*Document, error) {
+	m.mu.Lock()
+	d, ok := m.docs[id]
+	if !ok {
+		d = &Document{
+			id:        id,
+			loaded:    make(chan struct{}),
+			peers:     make(map[*Peer]struct{}),
+			changeIDs: make(map[int64]struct{}),
+		}
+		m.docs[id] = d
+	}
+	d.refs++
+	m.mu.Unlock()
+
+	if !ok {
+		d.err = m.load(ctx, d)
+		if d.err != nil {
+			m.mu.Lock()
+			delete(m.docs, id)
+			m.unsubscribe(ctx, id)
+			m.mu.Unlock()
+		}
+		close(d.loaded)
+	}
+
+	<-d.loaded
+	if d.err != nil {
+		m.Release(d)
+		return nil, d.err
+	}
+	return d, nil
+}
+
+// Release marks a document as no longer used by the caller
+func (m *Manager) Release(d *Document) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	d.refs--
+	d.lastUsed = time.Now()
+}
+
+func (m *Manager) load(ctx context.Context, d *Document) error {
+	// Subscribe first so no change stored by another node after the load is missed
+	if m.backend != nil {
+		if err := m.backend.Subscribe(ctx, roomPrefix+d.id); err != nil {
+			return fmt.Errorf("failed to subscribe to document %s: %w", d.id, err)
+		}
+	}
+
+	stored, err := m.store.LoadDocument(ctx, d.id)
+	if err != nil {
+		return err
+	}
+	d.compactedAt = time.Now()
+	if stored == nil {
+		d.doc = automerge.New()
+		return nil
+	}
+
+	if len(stored.Snapshot) > 0 {
+		d.doc, err = automerge.Load(stored.Snapshot)
+		if err != nil {
+			return fmt.Errorf("failed to load document %s: %w", d.id, err)
+		}
+	} else {
+		d.doc = automerge.New()
+	}
+	for _, change := range stored.Changes {
+		if err := d.doc.LoadIncremental(change.Data); err != nil {
+			return fmt.Errorf("failed to load change %d of document %s: %w", change.ID, d.id, err)
+		}
+		d.changeIDs[change.ID] = struct{}{}
+	}
+	// Start incremental saves from what was just loaded
+	d.doc.SaveIncremental()
+	return nil
+}
+
+// ID identifies the document
+func (d *Document) ID() string {
+	return d.id
+}
+
+// Join starts syncing the document with a new peer. The peer is notified straight away
+// so the server opens the sync.
+func (d *Document) Join() *Peer {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	p := &Peer{doc: d, state: automerge.NewSyncState(d.doc), notify: make(chan struct{}, 1)}
+	d.peers[p] = struct{}{}
+	p.signal()
+	return p
+}
+
+// Leave stops syncing the document with a peer
+func (d *Document) Leave(p *Peer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.peers, p)
+}
+
+// Notify fires when the peer has sync messages waiting in Outgoing
+func (p *Peer) Notify() <-chan struct{} {
+	return p.notify
+}
+
+// Outgoing generates the sync messages to send the peer now
+func (p *Peer) Outgoing() [][]byte {
+	p.doc.mu.Lock()
+	defer p.doc.mu.Unlock()
+	var messages [][]byte
+	for {
+		msg, ok := p.state.GenerateMessage()
+		if !ok {
+			return messages
+		}
+		messages = append(messages, msg.Bytes())
+	}
+}
 
-func LoadDoc(id string) (*automerge.Doc, error) {
-	store.RLock()
-	doc, ok := store.docs[id]
-	store.RUnlock()
-	if ok {
-		return doc, nil
+// signal wakes the peer's writer without blocking; one pending wake-up is enough since
+// Outgoing sends everything the peer is missing
+func (p *Peer) signal() {
+	select {
+	case p.notify <- struct{}{}:
+	default:
 	}
-	newDoc := automerge.New()
-	store.Lock()
-	store.docs[id] = newDoc
-	store.Unlock()
-	return newDoc, nil
 }
 
-func ApplyChanges(id string, changes []byte) (*automerge.Doc, error) {
-	doc, err := LoadDoc(id)
+// Save returns the whole document
+func (d *Document) Save() []byte {
+	return d.doc.Save()
+}
+
+// Receive applies a sync message from a peer and stores any changes it carried. Every
+// other peer of the document is then notified so the changes reach them live.
+func (m *Manager) Receive(ctx context.Context, p *Peer, message []byte) error {
+	d := p.doc
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, err := p.state.ReceiveMessage(message); err != nil {
+		return err
+	}
+	p.signal()
+	changes := d.doc.SaveIncremental()
+	if len(changes) == 0 {
+		return nil
+	}
+	for peer := range d.peers {
+		peer.signal()
+	}
+
+	id, err := m.store.AppendChange(ctx, d.id, changes)
+	m.publish(ctx, d, id, changes)
 	if err != nil {
-		return nil, err
+		// The changes are in memory; the next compaction writes them out
+		d.unsaved = true
+		return fmt.Errorf("failed to store changes to document %s: %w", d.id, err)
+	}
+	d.changeIDs[id] = struct{}{}
+	if len(d.changeIDs) >= compactAfterChanges {
+		m.compact(ctx, d)
+	}
+	return nil
+}
+
+// publish sends changes to the other nodes syncing the document. Callers hold d.mu.
+func (m *Manager) publish(ctx context.Context, d *Document, changeID int64, changes []byte) {
+	if m.backend == nil {
+		return
+	}
+	payload, err := json.Marshal(remoteChange{NodeID: m.backend.NodeID(), ChangeID: changeID, Data: changes})
+	if err == nil {
+		_, err = m.backend.Publish(ctx, roomPrefix+d.id, payload)
 	}
-	chgs, err := automerge.LoadChanges(changes)
 	if err != nil {
-		return nil, err
+		logger.Error().
+			Err(err).
+			Str("document_id", d.id).
+			Msg("Failed to publish CRDT changes")
 	}
-	if err := doc.Apply(chgs...); err != nil {
-		return nil, err
+}
+
+// receiveRemote applies the changes other nodes publish to the documents loaded here
+func (m *Manager) receiveRemote() {
+	for message := range m.backend.Messages() {
+		var change remoteChange
+		if err := json.Unmarshal(message.Data, &change); err != nil || change.NodeID == m.backend.NodeID() {
+			continue
+		}
+
+		m.mu.Lock()
+		d := m.docs[strings.TrimPrefix(message.RoomID, roomPrefix)]
+		m.mu.Unlock()
+		if d == nil {
+			continue
+		}
+		<-d.loaded
+		if d.err != nil {
+			continue
+		}
+
+		d.mu.Lock()
+		if err := d.doc.LoadIncremental(change.Data); err != nil {
+			logger.Error().
+				Err(err).
+				Str("document_id", d.id).
+				Msg("Failed to apply CRDT changes from another node")
+		} else {
+			d.applied(change.ChangeID)
+		}
+		d.mu.Unlock()
 	}
-	return doc, nil
 }
 
-func SaveSnapshot(id string) ([]byte, error) {
-	doc, err := LoadDoc(id)
+// applied records changes merged into the document from elsewhere and tells every peer.
+// Callers hold d.mu.
+func (d *Document) applied(changeIDs ...int64) {
+	for _, id := range changeIDs {
+		if id != 0 {
+			d.changeIDs[id] = struct{}{}
+		}
+	}
+	// Merged changes were stored by whoever made them, so they are not saved again
+	d.doc.SaveIncremental()
+	for peer := range d.peers {
+		peer.signal()
+	}
+}
+
+// compact folds the change log into a new snapshot. What is stored is merged in first:
+// another node may have stored changes, or compacted them into the snapshot, without
+// this one seeing them. Only the changes the document then includes are dropped.
+// Callers hold d.mu.
+func (m *Manager) compact(ctx context.Context, d *Document) bool {
+	err := m.reload(ctx, d)
+	if err == nil {
+		changeIDs := make([]int64, 0, len(d.changeIDs))
+		for id := range d.changeIDs {
+			changeIDs = append(changeIDs, id)
+		}
+		err = m.store.CompactDocument(ctx, d.id, d.doc.Save(), changeIDs)
+	}
 	if err != nil {
-		return nil, err
+		logger.Error().
+			Err(err).
+			Str("document_id", d.id).
+			Msg("Failed to compact CRDT document")
+		return false
+	}
+	d.changeIDs = make(map[int64]struct{})
+	d.unsaved = false
+	d.compactedAt = time.Now()
+	return true
+}
+
+// reload merges the stored snapshot and changes into the document. Callers hold d.mu.
+func (m *Manager) reload(ctx context.Context, d *Document) error {
+	stored, err := m.store.LoadDocument(ctx, d.id)
+	if err != nil || stored == nil {
+		return err
+	}
+	if len(stored.Snapshot) > 0 {
+		snapshot, err := automerge.Load(stored.Snapshot)
+		if err != nil {
+			return fmt.Errorf("failed to load document %s: %w", d.id, err)
+		}
+		if _, err := d.doc.Merge(snapshot); err != nil {
+			return fmt.Errorf("failed to merge document %s: %w", d.id, err)
+		}
+	}
+	changeIDs := make([]int64, 0, len(stored.Changes))
+	for _, change := range stored.Changes {
+		if err := d.doc.LoadIncremental(change.Data); err != nil {
+			return fmt.Errorf("failed to load change %d of document %s: %w", change.ID, d.id, err)
+		}
+		changeIDs = append(changeIDs, change.ID)
+	}
+	d.applied(changeIDs...)
+	return nil
+}
+
+// Sweep compacts documents with changes older than compactInterval and evicts documents
+// idle for idleTimeout, compacting them first. A document whose changes could not be
+// written out stays in memory until they are.
+func (m *Manager) Sweep(ctx context.Context) {
+	now := time.Now()
+	m.mu.Lock()
+	docs := make([]*Document, 0, len(m.docs))
+	evicted := make(map[*Document]bool)
+	for id, d := range m.docs {
+		select {
+		case <-d.loaded:
+		default:
+			continue
+		}
+		docs = append(docs, d)
+		if d.refs == 0 && now.Sub(d.lastUsed) >= m.idleTimeout {
+			evicted[d] = true
+			delete(m.docs, id)
+		}
 	}
-	return doc.Save(), nil
+	m.mu.Unlock()
+
+	for _, d := range docs {
+		d.mu.Lock()
+		saved := true
+		if (len(d.changeIDs) > 0 || d.unsaved) && (evicted[d] || now.Sub(d.compactedAt) >= m.compactInterval) {
+			saved = m.compact(ctx, d)
+		}
+		d.mu.Unlock()
+
+		if evicted[d] {
+			m.mu.Lock()
+			if _, reopened := m.docs[d.id]; !reopened {
+				if saved {
+					// Under m.mu so a reopened document never loses its new subscription
+					m.unsubscribe(ctx, d.id)
+				} else {
+					m.docs[d.id] = d
+				}
+			}
+			m.mu.Unlock()
+		}
+	}
+}
+
+// unsubscribe stops receiving the changes other nodes make to an evicted document
+func (m *Manager) unsubscribe(ctx context.Context, id string) {
+	if m.backend == nil {
+		return
+	}
+	if err := m.backend.Unsubscribe(ctx, roomPrefix+id); err != nil {
+		logger.Error().
+			Err(err).
+			Str("document_id", id).
+			Msg("Failed to unsubscribe from CRDT document")
+	}
+}
+
+// Len reports how many documents are in memory
+func (m *Manager) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.docs)
+}
+
+// StartMaintenance sweeps the documents in memory every interval
+func (m *Manager) StartMaintenance(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			m.Sweep(context.Background())
+		}
+	}()
 }
@@ -0,0 +1,430 @@
+package crdt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/automerge/automerge-go"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/auth"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	ws "github.com/ctclostio/DnD-Game/backend/internal/websocket"
+)
+
+// memoryStore keeps documents the way the crdt tables do
+type memoryStore struct {
+	mu        sync.Mutex
+	snapshots map[string][]byte
+	changes   map[string][]*models.CRDTChange
+	nextID    int64
+	failWrite bool
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{snapshots: make(map[string][]byte), changes: make(map[string][]*models.CRDTChange)}
+}
+
+func (s *memoryStore) LoadDocument(_ context.Context, id string) (*models.CRDTDocument, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshot, ok := s.snapshots[id]
+	if !ok && len(s.changes[id]) == 0 {
+		return nil, nil
+	}
+	return &models.CRDTDocument{ID: id, Snapshot: snapshot, Changes: append([]*models.CRDTChange(nil), s.changes[id]...)}, nil
+}
+
+func (s *memoryStore) AppendChange(_ context.Context, documentID string, data []byte) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failWrite {
+		return 0, errors.New("database is down")
+	}
+	s.nextID++
+	s.changes[documentID] = append(s.changes[documentID], &models.CRDTChange{ID: s.nextID, Data: data})
+	return s.nextID, nil
+}
+
+func (s *memoryStore) CompactDocument(_ context.Context, documentID string, snapshot []byte, changeIDs []int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failWrite {
+		return errors.New("database is down")
+	}
+	s.snapshots[documentID] = snapshot
+	compacted := make(map[int64]bool, len(changeIDs))
+	for _, id := range changeIDs {
+		compacted[id] = true
+	}
+	var kept []*models.CRDTChange
+	for _, change := range s.changes[documentID] {
+		if !compacted[change.ID] {
+			kept = append(kept, change)
+		}
+	}
+	s.changes[documentID] = kept
+	return nil
+}
+
+func (s *memoryStore) changeCount(id string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.changes[id])
+}
+
+// editDoc sets a key on a client's copy of a document
+func editDoc(t *testing.T, doc *automerge.Doc, key, value string) {
+	t.Helper()
+	require.NoError(t, doc.RootMap().Set(key, value))
+	_, err := doc.Commit("edit " + key)
+	require.NoError(t, err)
+}
+
+// syncWith exchanges sync messages between a client's copy and the manager's until
+// neither side has anything left to send
+func syncWith(t *testing.T, m *Manager, doc *Document, client *automerge.Doc) {
+	t.Helper()
+	peer := doc.Join()
+	defer doc.Leave(peer)
+	clientState := automerge.NewSyncState(client)
+	for {
+		sent := false
+		if msg, ok := clientState.GenerateMessage(); ok {
+			require.NoError(t, m.Receive(context.Background(), peer, msg.Bytes()))
+			sent = true
+		}
+		for _, msg := range peer.Outgoing() {
+			_, err := clientState.ReceiveMessage(msg)
+			require.NoError(t, err)
+			sent = true
+		}
+		if !sent {
+			return
+		}
+	}
+}
+
+func valueOf(t *testing.T, doc *Document, key string) string {
+	t.Helper()
+	value, err := doc.doc.RootMap().Get(key)
+	require.NoError(t, err)
+	return value.Str()
+}
+
+func TestManager_Persistence(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+
+	m := NewManager(store)
+	doc, err := m.Open(ctx, "char-1")
+	require.NoError(t, err)
+	client := automerge.New()
+	editDoc(t, client, "name", "Thorin")
+	syncWith(t, m, doc, client)
+	editDoc(t, client, "class", "Fighter")
+	syncWith(t, m, doc, client)
+	m.Release(doc)
+
+	assert.Equal(t, 2, store.changeCount("char-1"), "each synced change is stored")
+
+	t.Run("a restarted server loads the stored changes", func(t *testing.T) {
+		restarted := NewManager(store)
+		doc, err := restarted.Open(ctx, "char-1")
+		require.NoError(t, err)
+		defer restarted.Release(doc)
+
+		assert.Equal(t, "Thorin", valueOf(t, doc, "name"))
+		assert.Equal(t, "Fighter", valueOf(t, doc, "class"))
+	})
+
+	t.Run("compaction folds the changes into a snapshot", func(t *testing.T) {
+		m.compactInterval = 0
+		m.Sweep(ctx)
+
+		assert.Equal(t, 0, store.changeCount("char-1"))
+		assert.NotEmpty(t, store.snapshots["char-1"])
+
+		restarted := NewManager(store)
+		doc, err := restarted.Open(ctx, "char-1")
+		require.NoError(t, err)
+		defer restarted.Release(doc)
+		assert.Equal(t, "Thorin", valueOf(t, doc, "name"))
+
+		editDoc(t, client, "level", "2")
+		syncWith(t, restarted, doc, client)
+		assert.Equal(t, 1, store.changeCount("char-1"), "changes after the snapshot are appended")
+	})
+}
+
+func TestManager_Eviction(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	m := NewManager(store)
+	m.idleTimeout = 0
+
+	doc, err := m.Open(ctx, "char-1")
+	require.NoError(t, err)
+	client := automerge.New()
+	editDoc(t, client, "name", "Thorin")
+	syncWith(t, m, doc, client)
+
+	m.Sweep(ctx)
+	assert.Equal(t, 1, m.Len(), "documents being synced stay loaded")
+
+	m.Release(doc)
+	store.failWrite = true
+	m.Sweep(ctx)
+	assert.Equal(t, 1, m.Len(), "a document is kept until it has been written out")
+
+	store.failWrite = false
+	m.Sweep(ctx)
+	assert.Equal(t, 0, m.Len())
+	assert.Equal(t, 0, store.changeCount("char-1"), "evicted documents are compacted")
+
+	doc, err = m.Open(ctx, "char-1")
+	require.NoError(t, err)
+	defer m.Release(doc)
+	assert.Equal(t, "Thorin", valueOf(t, doc, "name"))
+}
+
+func TestManager_FanOut(t *testing.T) {
+	ctx := context.Background()
+	m := NewManager(newMemoryStore())
+	doc, err := m.Open(ctx, "party-notes:party-1")
+	require.NoError(t, err)
+	defer m.Release(doc)
+
+	alice, bob := automerge.New(), automerge.New()
+	syncWith(t, m, doc, bob)
+	watcher := doc.Join()
+	defer doc.Leave(watcher)
+	<-watcher.Notify() // joining wakes the peer once
+	watcher.Outgoing()
+
+	editDoc(t, alice, "loot", "Three gold rings")
+	syncWith(t, m, doc, alice)
+
+	select {
+	case <-watcher.Notify():
+	default:
+		t.Fatal("other peers are notified of new changes")
+	}
+	assert.NotEmpty(t, watcher.Outgoing())
+
+	syncWith(t, m, doc, bob)
+	value, err := bob.RootMap().Get("loot")
+	require.NoError(t, err)
+	assert.Equal(t, "Three gold rings", value.Str())
+}
+
+func TestManager_Replicas(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("changes reach peers on other nodes", func(t *testing.T) {
+		store := newMemoryStore()
+		backend := ws.NewMemoryBackend()
+		first, second := NewManager(store), NewManager(store)
+		first.SetBackend(backend)
+		second.SetBackend(backend.Peer())
+
+		doc, err := first.Open(ctx, "party-notes:party-1")
+		require.NoError(t, err)
+		defer first.Release(doc)
+		replica, err := second.Open(ctx, "party-notes:party-1")
+		require.NoError(t, err)
+		defer second.Release(replica)
+		watcher := replica.Join()
+		defer replica.Leave(watcher)
+		<-watcher.Notify()
+
+		client := automerge.New()
+		editDoc(t, client, "loot", "Three gold rings")
+		syncWith(t, first, doc, client)
+
+		select {
+		case <-watcher.Notify():
+		case <-time.After(5 * time.Second):
+			t.Fatal("peers on other nodes are notified of new changes")
+		}
+		replica.mu.Lock()
+		assert.Equal(t, "Three gold rings", valueOf(t, replica, "loot"))
+		assert.Len(t, replica.changeIDs, 1, "the other node's stored change can be compacted here")
+		replica.mu.Unlock()
+		assert.Equal(t, 1, store.changeCount("party-notes:party-1"), "a change is stored once")
+	})
+
+	t.Run("compaction keeps changes another node made", func(t *testing.T) {
+		store := newMemoryStore()
+		first, second := NewManager(store), NewManager(store)
+		first.compactInterval, second.compactInterval = 0, 0
+
+		doc, err := first.Open(ctx, "char-1")
+		require.NoError(t, err)
+		replica, err := second.Open(ctx, "char-1")
+		require.NoError(t, err)
+		syncWith(t, second, replica, newClient(t, "name", "Thorin"))
+		syncWith(t, first, doc, newClient(t, "class", "Fighter"))
+
+		first.Sweep(ctx)
+		second.Sweep(ctx)
+		first.Release(doc)
+		second.Release(replica)
+		assert.Equal(t, 0, store.changeCount("char-1"))
+
+		restarted := NewManager(store)
+		doc, err = restarted.Open(ctx, "char-1")
+		require.NoError(t, err)
+		defer restarted.Release(doc)
+		assert.Equal(t, "Thorin", valueOf(t, doc, "name"))
+		assert.Equal(t, "Fighter", valueOf(t, doc, "class"))
+	})
+}
+
+// newClient is a client's copy of a document with one key set
+func newClient(t *testing.T, key, value string) *automerge.Doc {
+	t.Helper()
+	doc := automerge.New()
+	editDoc(t, doc, key, value)
+	return doc
+}
+
+func TestHandler_Authentication(t *testing.T) {
+	jwtManager := auth.NewJWTManager("test-secret-key-that-is-long-enough", time.Hour, time.Hour)
+	owner, err := jwtManager.GenerateTokenPair("user-1", "alice", "alice@example.com", "player")
+	require.NoError(t, err)
+	stranger, err := jwtManager.GenerateTokenPair("user-2", "mallory", "mallory@example.com", "player")
+	require.NoError(t, err)
+
+	access := func(_ context.Context, id, userID string) (bool, error) {
+		return id == "char-1" && userID == "user-1", nil
+	}
+	handler := NewHandler(NewManager(newMemoryStore()), jwtManager)
+	handler.SetAccess(DocumentCharacter, access)
+	router := mux.NewRouter()
+	router.HandleFunc("/ws/v1/characters/{id}/sync", handler.SyncHandler(DocumentCharacter))
+	router.HandleFunc("/ws/v1/sessions/{id}/prep/sync", handler.SyncHandler(DocumentSessionPrep))
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/v1/characters/char-1/sync"
+
+	dial := func(t *testing.T, header http.Header) (*websocket.Conn, *http.Response, error) {
+		header.Set("Origin", "http://localhost:3000")
+		conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+		if conn != nil {
+			t.Cleanup(func() { _ = conn.Close() })
+		}
+		return conn, resp, err
+	}
+
+	t.Run("header tokens are checked before the upgrade", func(t *testing.T) {
+		_, resp, err := dial(t, http.Header{"Authorization": []string{"Bearer not-a-token"}})
+		require.Error(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		_, resp, err = dial(t, http.Header{"Authorization": []string{"Bearer " + stranger.AccessToken}})
+		require.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		_, _, err = dial(t, http.Header{"Authorization": []string{"Bearer " + owner.AccessToken}})
+		require.NoError(t, err)
+	})
+
+	t.Run("kinds without access rules are not served", func(t *testing.T) {
+		header := http.Header{"Origin": []string{"http://localhost:3000"}}
+		_, resp, err := websocket.DefaultDialer.Dial(strings.Replace(url, "characters/char-1/sync", "sessions/s-1/prep/sync", 1), header)
+		require.Error(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("unknown origins are refused", func(t *testing.T) {
+		_, _, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": []string{"http://evil.example.com"}})
+		assert.Error(t, err)
+	})
+
+	t.Run("users without access are disconnected", func(t *testing.T) {
+		conn, _, err := dial(t, http.Header{})
+		require.NoError(t, err)
+		require.NoError(t, conn.WriteJSON(AuthMessage{Type: "auth", Token: stranger.AccessToken}))
+
+		_, _, err = conn.ReadMessage()
+		var closeErr *websocket.CloseError
+		require.ErrorAs(t, err, &closeErr)
+		assert.Equal(t, websocket.ClosePolicyViolation, closeErr.Code)
+	})
+
+	t.Run("allowed users see each other's changes live", func(t *testing.T) {
+		connect := func() (*websocket.Conn, *automerge.Doc, *automerge.SyncState) {
+			conn, _, err := dial(t, http.Header{})
+			require.NoError(t, err)
+			require.NoError(t, conn.WriteJSON(AuthMessage{Type: "auth", Token: owner.AccessToken}))
+			var reply map[string]string
+			require.NoError(t, conn.ReadJSON(&reply))
+			require.Equal(t, "auth_success", reply["type"])
+			doc := automerge.New()
+			return conn, doc, automerge.NewSyncState(doc)
+		}
+		// flush sends whatever the client has for the server
+		flush := func(conn *websocket.Conn, state *automerge.SyncState) {
+			for {
+				msg, ok := state.GenerateMessage()
+				if !ok {
+					return
+				}
+				require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, msg.Bytes()))
+			}
+		}
+
+		laptop, laptopDoc, laptopState := connect()
+		tablet, tabletDoc, tabletState := connect()
+		flush(tablet, tabletState)
+
+		editDoc(t, laptopDoc, "name", "Thorin")
+		flush(laptop, laptopState)
+		// The laptop answers the server until its change has gone across
+		go func() {
+			for {
+				_, msg, err := laptop.ReadMessage()
+				if err != nil {
+					return
+				}
+				if _, err := laptopState.ReceiveMessage(msg); err != nil {
+					return
+				}
+				for {
+					reply, ok := laptopState.GenerateMessage()
+					if !ok {
+						break
+					}
+					if err := laptop.WriteMessage(websocket.BinaryMessage, reply.Bytes()); err != nil {
+						return
+					}
+				}
+			}
+		}()
+
+		// The tablet sees the laptop's edit without making one of its own
+		require.NoError(t, tablet.SetReadDeadline(time.Now().Add(5*time.Second)))
+		for {
+			mt, msg, err := tablet.ReadMessage()
+			require.NoError(t, err)
+			require.Equal(t, websocket.BinaryMessage, mt)
+			_, err = tabletState.ReceiveMessage(msg)
+			require.NoError(t, err)
+			if value, err := tabletDoc.RootMap().Get("name"); err == nil && value.Kind() == automerge.KindStr {
+				assert.Equal(t, "Thorin", value.Str())
+				break
+			}
+			flush(tablet, tabletState)
+		}
+	})
+}
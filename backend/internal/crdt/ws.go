@@ -1,38 +1,220 @@
 package crdt
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
+	"os"
+	"time"
 
-	"github.com/automerge/automerge-go"
+	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/auth"
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/middleware"
+	"github.com/ctclostio/DnD-Game/backend/pkg/logger"
+)
+
+const (
+	// authTimeout is how long a client that didn't send an Authorization header has to
+	// send its auth message
+	authTimeout = 10 * time.Second
+	// writeWait is how long a sync message may take to write
+	writeWait = 10 * time.Second
 )
 
-var upgrader = websocket.Upgrader{CheckOrigin: func(_ *http.Request) bool { return true }}
+// Kinds of collaborative document. A document is stored under its kind and the id of
+// the record it belongs to, so a party and its session never share a document.
+const (
+	DocumentCharacter            = "character"
+	DocumentPartyNotes           = "party-notes"
+	DocumentBattleMapAnnotations = "battle-map-annotations"
+	DocumentSessionPrep          = "session-prep"
+)
+
+// DocumentID is the stored id of the document of a kind belonging to a record
+func DocumentID(kind, id string) string {
+	return kind + ":" + id
+}
+
+// AccessFunc reports whether a user may sync the document belonging to a record
+type AccessFunc func(ctx context.Context, id, userID string) (bool, error)
+
+// AuthMessage authenticates a connection that couldn't send an Authorization header,
+// as browsers can't. It is the first text message on the socket.
+type AuthMessage struct {
+	Type  string `json:"type"`
+	Token string `json:"token"`
+}
+
+// Handler serves automerge sync over websockets to users allowed to see the document
+type Handler struct {
+	manager        *Manager
+	jwtManager     *auth.JWTManager
+	access         map[string]AccessFunc
+	upgrader       websocket.Upgrader
+	allowedOrigins []string
+}
+
+// NewHandler creates a sync handler. Each kind of document is served once SetAccess
+// says who may sync it.
+func NewHandler(manager *Manager, jwtManager *auth.JWTManager) *Handler {
+	allowedOrigins := []string{
+		"http://localhost:3000",
+		"http://localhost:8080",
+	}
+	if prodOrigin := os.Getenv("PRODUCTION_ORIGIN"); prodOrigin != "" {
+		allowedOrigins = append(allowedOrigins, prodOrigin)
+	}
+
+	h := &Handler{
+		manager:        manager,
+		jwtManager:     jwtManager,
+		access:         make(map[string]AccessFunc),
+		allowedOrigins: allowedOrigins,
+	}
+	h.upgrader = websocket.Upgrader{CheckOrigin: h.checkOrigin}
+	return h
+}
+
+// SetAccess decides who may sync documents of a kind
+func (h *Handler) SetAccess(kind string, access AccessFunc) {
+	h.access[kind] = access
+}
+
+func (h *Handler) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if os.Getenv("GO_ENV") == constants.EnvDevelopment && origin == "" {
+		return true
+	}
+	return middleware.ValidateOrigin(h.allowedOrigins, origin)
+}
 
-// setupCRDTConnection attempts to upgrade the HTTP connection to a WebSocket connection
-// and retrieves the document ID. It handles writing HTTP errors if setup fails.
-// It returns the established WebSocket connection, the document ID, and an error.
-// If an error occurs that prevents further processing (e.g., ID missing, upgrade failed),
-// an HTTP error is written to w and a non-nil error is returned.
-func setupCRDTConnection(w http.ResponseWriter, r *http.Request) (*websocket.Conn, string, error) {
-	id := r.URL.Query().Get("id")
+// SyncHandler serves the documents of a kind for the record named by the {id} path
+// variable. The client authenticates with an Authorization header or, failing that, an
+// AuthMessage, then exchanges binary automerge sync messages with the server and,
+// through it, every other client syncing the document.
+func (h *Handler) SyncHandler(kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.serveSync(w, r, kind)
+	}
+}
+
+func (h *Handler) serveSync(w http.ResponseWriter, r *http.Request, kind string) {
+	access, ok := h.access[kind]
+	if !ok {
+		http.Error(w, "unknown document", http.StatusNotFound)
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		id = r.URL.Query().Get("id")
+	}
 	if id == "" {
 		http.Error(w, "missing id", http.StatusBadRequest)
-		return nil, "", http.ErrMissingFile // Using a generic error, specific error type might be better
+		return
 	}
 
-	conn, err := upgrader.Upgrade(w, r, nil)
+	// Clients that can send headers are refused before the upgrade
+	var userID string
+	if header := r.Header.Get("Authorization"); header != "" {
+		claims, err := h.authenticate(header)
+		if err != nil {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		if !allowed(r.Context(), access, kind, id, claims.UserID) {
+			http.Error(w, "access denied", http.StatusForbidden)
+			return
+		}
+		userID = claims.UserID
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		// upgrader.Upgrade handles writing the error response internally if it fails
-		return nil, "", err
+		return
 	}
-	return conn, id, nil
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	if userID == "" {
+		if reason := h.authenticateConn(r.Context(), conn, access, kind, id); reason != "" {
+			_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason))
+			return
+		}
+	}
+
+	documentID := DocumentID(kind, id)
+	doc, err := h.manager.Open(r.Context(), documentID)
+	if err != nil {
+		logger.Error().Err(err).Str("document_id", documentID).Msg("Failed to open CRDT document")
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, "failed to load document"))
+		return
+	}
+	defer h.manager.Release(doc)
+
+	h.processMessages(r.Context(), conn, doc)
+}
+
+func (h *Handler) authenticate(header string) (*auth.Claims, error) {
+	token, err := auth.ExtractTokenFromHeader(header)
+	if err != nil {
+		return nil, err
+	}
+	return h.jwtManager.ValidateToken(token, auth.AccessToken)
+}
+
+// authenticateConn reads the auth message and checks the user may sync the document,
+// returning why the connection is refused or "" if it isn't
+func (h *Handler) authenticateConn(ctx context.Context, conn *websocket.Conn, access AccessFunc, kind, id string) string {
+	_ = conn.SetReadDeadline(time.Now().Add(authTimeout))
+	var msg AuthMessage
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "auth" {
+		return "authentication required"
+	}
+	claims, err := h.jwtManager.ValidateToken(msg.Token, auth.AccessToken)
+	if err != nil {
+		return "invalid token"
+	}
+	if !allowed(ctx, access, kind, id, claims.UserID) {
+		return "access denied"
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+
+	reply, _ := json.Marshal(map[string]string{"type": "auth_success"})
+	if err := conn.WriteMessage(websocket.TextMessage, reply); err != nil {
+		return "authentication failed"
+	}
+	return ""
+}
+
+func allowed(ctx context.Context, access AccessFunc, kind, id, userID string) bool {
+	ok, err := access(ctx, id, userID)
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Str("document_id", DocumentID(kind, id)).
+			Str("user_id", userID).
+			Msg("Failed to check CRDT document access")
+		return false
+	}
+	return ok
 }
 
-// processCRDTMessages handles the synchronization logic for a given document
-// over an established WebSocket connection.
-func processCRDTMessages(conn *websocket.Conn, doc *automerge.Doc) {
-	state := automerge.NewSyncState(doc)
+// processMessages handles the synchronization logic for a given document
+// over an established WebSocket connection. Messages are read here and written by a
+// second goroutine whenever the peer is notified, including by other connections.
+func (h *Handler) processMessages(ctx context.Context, conn *websocket.Conn, doc *Document) {
+	peer := doc.Join()
+	defer doc.Leave(peer)
+
+	done := make(chan struct{})
+	defer close(done)
+	go writeMessages(conn, peer, done)
+
 	for {
 		mt, msg, err := conn.ReadMessage()
 		if err != nil {
@@ -44,46 +226,30 @@ func processCRDTMessages(conn *websocket.Conn, doc *automerge.Doc) {
 			continue
 		}
 
-		// Apply incoming message to the sync state
-		// The original code ignores the patch, so we do too.
-		if _, err := state.ReceiveMessage(msg); err != nil {
-			// Error processing message
+		// Changes are stored before anything is sent back, so a client never sees its
+		// changes acknowledged unless they are persisted
+		if err := h.manager.Receive(ctx, peer, msg); err != nil {
+			logger.Error().Err(err).Str("document_id", doc.ID()).Msg("Failed to apply CRDT sync message")
 			return
 		}
-
-		// Generate and send any outgoing messages
-		for {
-			syncMsg, ok := state.GenerateMessage()
-			if !ok {
-				// No more messages to generate at this time
-				break
-			}
-			if err := conn.WriteMessage(websocket.BinaryMessage, syncMsg.Bytes()); err != nil {
-				// Error writing message
-				return
-			}
-		}
 	}
 }
 
-func SyncHandler(w http.ResponseWriter, r *http.Request) {
-	conn, id, err := setupCRDTConnection(w, r)
-	if err != nil {
-		// setupCRDTConnection already handled writing the HTTP error
-		return
-	}
-	defer func() {
-		_ = conn.Close()
-	}()
-
-	// The original code ignores the error from LoadDoc.
-	// This behavior is preserved here but might be an area for future improvement.
-	doc, err := LoadDoc(id)
-	if err != nil || doc == nil {
-		// Handle document loading failure
-		http.Error(w, "failed to load document", http.StatusInternalServerError)
-		return
+// writeMessages sends a peer its sync messages each time it is notified
+func writeMessages(conn *websocket.Conn, peer *Peer, done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case <-peer.Notify():
+			for _, msg := range peer.Outgoing() {
+				_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+				if err := conn.WriteMessage(websocket.BinaryMessage, msg); err != nil {
+					// Unblock the reader so the connection is torn down
+					_ = conn.Close()
+					return
+				}
+			}
+		}
 	}
-
-	processCRDTMessages(conn, doc)
 }
@@ -56,6 +56,22 @@ func (r *characterRepository) scanCharacter(row RowScanner) (*models.Character,
 
 // Create creates a new character
 func (r *characterRepository) Create(ctx context.Context, character *models.Character) error {
+	query, args, err := characterInsert(character)
+	if err != nil {
+		return err
+	}
+	err = r.db.QueryRowContextRebind(ctx, query, args...).
+		Scan(&character.ID, &character.CreatedAt, &character.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create character: %w", err)
+	}
+
+	return nil
+}
+
+// characterInsert builds the INSERT for a new character, returning its id and timestamps,
+// so it can run on the connection or inside another repository's transaction
+func characterInsert(character *models.Character) (string, []interface{}, error) {
 	// Generate ID if not provided (for SQLite compatibility)
 	if character.ID == "" {
 		character.ID = uuid.New().String()
@@ -64,22 +80,22 @@ func (r *characterRepository) Create(ctx context.Context, character *models.Char
 	// Convert complex types to JSON
 	attributesJSON, err := json.Marshal(character.Attributes)
 	if err != nil {
-		return fmt.Errorf("failed to marshal attributes: %w", err)
+		return "", nil, fmt.Errorf("failed to marshal attributes: %w", err)
 	}
 
 	skillsJSON, err := json.Marshal(character.Skills)
 	if err != nil {
-		return fmt.Errorf("failed to marshal skills: %w", err)
+		return "", nil, fmt.Errorf("failed to marshal skills: %w", err)
 	}
 
 	equipmentJSON, err := json.Marshal(character.Equipment)
 	if err != nil {
-		return fmt.Errorf("failed to marshal equipment: %w", err)
+		return "", nil, fmt.Errorf("failed to marshal equipment: %w", err)
 	}
 
 	spellsJSON, err := json.Marshal(character.Spells)
 	if err != nil {
-		return fmt.Errorf("failed to marshal spells: %w", err)
+		return "", nil, fmt.Errorf("failed to marshal spells: %w", err)
 	}
 
 	query := `
@@ -90,17 +106,13 @@ func (r *characterRepository) Create(ctx context.Context, character *models.Char
 		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		RETURNING id, created_at, updated_at`
 
-	err = r.db.QueryRowContextRebind(ctx, query,
+	args := []interface{}{
 		character.ID, character.UserID, character.Name, character.Race, character.Class,
 		character.Level, character.ExperiencePoints, character.HitPoints,
 		character.MaxHitPoints, character.ArmorClass, character.Speed,
-		attributesJSON, skillsJSON, equipmentJSON, spellsJSON).
-		Scan(&character.ID, &character.CreatedAt, &character.UpdatedAt)
-	if err != nil {
-		return fmt.Errorf("failed to create character: %w", err)
+		attributesJSON, skillsJSON, equipmentJSON, spellsJSON,
 	}
-
-	return nil
+	return query, args, nil
 }
 
 // GetByID retrieves a character by ID
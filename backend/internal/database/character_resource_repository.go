@@ -0,0 +1,175 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+// CharacterResourceRepository defines the interface for class resource and rest state operations
+type CharacterResourceRepository interface {
+	GetResources(ctx context.Context, characterID string) ([]*models.CharacterResource, error)
+	GetResource(ctx context.Context, characterID, key string) (*models.CharacterResource, error)
+	UpsertResource(ctx context.Context, resource *models.CharacterResource) error
+	SpendResource(ctx context.Context, characterID, key string, amount int) (bool, error)
+	RegainResource(ctx context.Context, characterID, key string, amount int) error
+	DeleteResource(ctx context.Context, characterID, key string) error
+	GetRestState(ctx context.Context, characterID string) (*models.CharacterRestState, error)
+	UpsertRestState(ctx context.Context, state *models.CharacterRestState) error
+}
+
+// characterResourceRepository implements CharacterResourceRepository
+type characterResourceRepository struct {
+	db *DB
+}
+
+// NewCharacterResourceRepository creates a new character resource repository
+func NewCharacterResourceRepository(db *DB) CharacterResourceRepository {
+	return &characterResourceRepository{db: db}
+}
+
+const characterResourceColumns = `id, character_id, resource_key, name, current_uses, max_uses,
+	recovery, die, custom, created_at, updated_at`
+
+// GetResources returns every tracked resource for a character
+func (r *characterResourceRepository) GetResources(ctx context.Context, characterID string) ([]*models.CharacterResource, error) {
+	query := `SELECT ` + characterResourceColumns + ` FROM character_resources
+		WHERE character_id = ? ORDER BY resource_key`
+
+	var resources []*models.CharacterResource
+	if err := r.db.SelectContext(ctx, &resources, r.db.Rebind(query), characterID); err != nil {
+		return nil, fmt.Errorf("failed to get character resources: %w", err)
+	}
+	return resources, nil
+}
+
+// GetResource returns a single resource, or nil if it is not tracked
+func (r *characterResourceRepository) GetResource(ctx context.Context, characterID, key string) (*models.CharacterResource, error) {
+	query := `SELECT ` + characterResourceColumns + ` FROM character_resources
+		WHERE character_id = ? AND resource_key = ?`
+
+	var resource models.CharacterResource
+	err := r.db.GetContext(ctx, &resource, r.db.Rebind(query), characterID, key)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get character resource: %w", err)
+	}
+	return &resource, nil
+}
+
+// UpsertResource creates or updates a resource keyed by character and resource key
+func (r *characterResourceRepository) UpsertResource(ctx context.Context, resource *models.CharacterResource) error {
+	if resource.ID == "" {
+		resource.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if resource.CreatedAt.IsZero() {
+		resource.CreatedAt = now
+	}
+	resource.UpdatedAt = now
+
+	query := `
+		INSERT INTO character_resources (` + characterResourceColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (character_id, resource_key)
+		DO UPDATE SET
+			name = excluded.name,
+			current_uses = excluded.current_uses,
+			max_uses = excluded.max_uses,
+			recovery = excluded.recovery,
+			die = excluded.die,
+			custom = excluded.custom,
+			updated_at = excluded.updated_at`
+
+	_, err := r.db.ExecContextRebind(ctx, query,
+		resource.ID, resource.CharacterID, resource.Key, resource.Name,
+		resource.Current, resource.Max, resource.Recovery, resource.Die,
+		resource.Custom, resource.CreatedAt, resource.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save character resource: %w", err)
+	}
+	return nil
+}
+
+// SpendResource takes amount uses from a resource in one statement. It reports false when
+// fewer than amount remain, so two spends racing for the last uses cannot both have them.
+func (r *characterResourceRepository) SpendResource(ctx context.Context, characterID, key string, amount int) (bool, error) {
+	query := `UPDATE character_resources SET current_uses = current_uses - ?, updated_at = ?
+		WHERE character_id = ? AND resource_key = ? AND current_uses >= ?`
+	result, err := r.db.ExecContextRebind(ctx, query, amount, time.Now(), characterID, key, amount)
+	if err != nil {
+		return false, fmt.Errorf("failed to spend character resource: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to spend character resource: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// RegainResource gives back amount uses of a resource, up to its maximum. A non-positive
+// amount refills it.
+func (r *characterResourceRepository) RegainResource(ctx context.Context, characterID, key string, amount int) error {
+	query := `UPDATE character_resources SET updated_at = ?,
+			current_uses = CASE WHEN ? <= 0 OR current_uses + ? > max_uses THEN max_uses ELSE current_uses + ? END
+		WHERE character_id = ? AND resource_key = ?`
+	if _, err := r.db.ExecContextRebind(ctx, query, time.Now(), amount, amount, amount, characterID, key); err != nil {
+		return fmt.Errorf("failed to regain character resource: %w", err)
+	}
+	return nil
+}
+
+// DeleteResource stops tracking a resource
+func (r *characterResourceRepository) DeleteResource(ctx context.Context, characterID, key string) error {
+	query := `DELETE FROM character_resources WHERE character_id = ? AND resource_key = ?`
+	if _, err := r.db.ExecContextRebind(ctx, query, characterID, key); err != nil {
+		return fmt.Errorf("failed to delete character resource: %w", err)
+	}
+	return nil
+}
+
+// GetRestState returns the rest state for a character, or nil if none has been recorded
+func (r *characterResourceRepository) GetRestState(ctx context.Context, characterID string) (*models.CharacterRestState, error) {
+	query := `SELECT character_id, exhaustion_level, last_short_rest_at, last_long_rest_at, updated_at
+		FROM character_rest_state WHERE character_id = ?`
+
+	var state models.CharacterRestState
+	err := r.db.GetContext(ctx, &state, r.db.Rebind(query), characterID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rest state: %w", err)
+	}
+	return &state, nil
+}
+
+// UpsertRestState creates or updates the rest state for a character
+func (r *characterResourceRepository) UpsertRestState(ctx context.Context, state *models.CharacterRestState) error {
+	state.UpdatedAt = time.Now()
+
+	query := `
+		INSERT INTO character_rest_state (character_id, exhaustion_level, last_short_rest_at, last_long_rest_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (character_id)
+		DO UPDATE SET
+			exhaustion_level = excluded.exhaustion_level,
+			last_short_rest_at = excluded.last_short_rest_at,
+			last_long_rest_at = excluded.last_long_rest_at,
+			updated_at = excluded.updated_at`
+
+	_, err := r.db.ExecContextRebind(ctx, query,
+		state.CharacterID, state.ExhaustionLevel, state.LastShortRestAt,
+		state.LastLongRestAt, state.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save rest state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+// CharacterVersionRepository defines the interface for character history operations
+type CharacterVersionRepository interface {
+	CreateVersion(ctx context.Context, version *models.CharacterVersion) error
+	GetVersions(ctx context.Context, characterID string) ([]*models.CharacterVersion, error)
+	GetVersion(ctx context.Context, characterID string, version int) (*models.CharacterVersion, error)
+	GetVersionAt(ctx context.Context, characterID string, at time.Time) (*models.CharacterVersion, error)
+	GetLatestVersion(ctx context.Context, characterID string) (*models.CharacterVersion, error)
+	GetVersionsSince(ctx context.Context, characterID string, since time.Time) ([]*models.CharacterVersion, error)
+}
+
+// characterVersionRepository implements CharacterVersionRepository
+type characterVersionRepository struct {
+	db *DB
+}
+
+// NewCharacterVersionRepository creates a new character version repository
+func NewCharacterVersionRepository(db *DB) CharacterVersionRepository {
+	return &characterVersionRepository{db: db}
+}
+
+// characterVersionSummaryColumns omits the snapshot for history listings
+const characterVersionSummaryColumns = `id, character_id, version, change_type, reason, changed_by, created_at`
+
+// createVersionAttempts bounds retries when concurrent saves race for the same version number
+const createVersionAttempts = 3
+
+// CreateVersion stores a snapshot as the next version number for the character. Two saves
+// of the same character can compute the same number; the loser hits the unique key on
+// (character_id, version) and retries with the next one.
+func (r *characterVersionRepository) CreateVersion(ctx context.Context, version *models.CharacterVersion) error {
+	if version.ID == "" {
+		version.ID = uuid.New().String()
+	}
+	if version.CreatedAt.IsZero() {
+		version.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO character_versions (id, character_id, version, change_type, reason, changed_by, snapshot, created_at)
+		SELECT ?, ?, COALESCE(MAX(version), 0) + 1, ?, ?, ?, ?, ?
+		FROM character_versions WHERE character_id = ?
+		RETURNING version`
+
+	var err error
+	for attempt := 0; attempt < createVersionAttempts; attempt++ {
+		err = r.db.QueryRowContextRebind(ctx, query,
+			version.ID, version.CharacterID, version.ChangeType, version.Reason,
+			version.ChangedBy, version.Snapshot, version.CreatedAt, version.CharacterID,
+		).Scan(&version.Version)
+		if err == nil || !isUniqueViolation(err) {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create character version: %w", err)
+	}
+	return nil
+}
+
+// GetVersions lists a character's versions, newest first, without snapshots
+func (r *characterVersionRepository) GetVersions(ctx context.Context, characterID string) ([]*models.CharacterVersion, error) {
+	query := `SELECT ` + characterVersionSummaryColumns + ` FROM character_versions
+		WHERE character_id = ? ORDER BY version DESC`
+
+	var versions []*models.CharacterVersion
+	if err := r.db.SelectContext(ctx, &versions, r.db.Rebind(query), characterID); err != nil {
+		return nil, fmt.Errorf("failed to get character versions: %w", err)
+	}
+	return versions, nil
+}
+
+// GetVersion returns a single version with its snapshot, or nil if it does not exist
+func (r *characterVersionRepository) GetVersion(ctx context.Context, characterID string, version int) (*models.CharacterVersion, error) {
+	query := `SELECT ` + characterVersionSummaryColumns + `, snapshot FROM character_versions
+		WHERE character_id = ? AND version = ?`
+	return r.getOne(ctx, query, characterID, version)
+}
+
+// GetVersionAt returns the last version recorded at or before the given time, or nil if none
+func (r *characterVersionRepository) GetVersionAt(ctx context.Context, characterID string, at time.Time) (*models.CharacterVersion, error) {
+	query := `SELECT ` + characterVersionSummaryColumns + `, snapshot FROM character_versions
+		WHERE character_id = ? AND created_at <= ? ORDER BY version DESC LIMIT 1`
+	return r.getOne(ctx, query, characterID, at)
+}
+
+// GetLatestVersion returns the most recent version, or nil if the character has no history
+func (r *characterVersionRepository) GetLatestVersion(ctx context.Context, characterID string) (*models.CharacterVersion, error) {
+	query := `SELECT ` + characterVersionSummaryColumns + `, snapshot FROM character_versions
+		WHERE character_id = ? ORDER BY version DESC LIMIT 1`
+	return r.getOne(ctx, query, characterID)
+}
+
+// GetVersionsSince lists versions recorded after the given time, oldest first, without snapshots
+func (r *characterVersionRepository) GetVersionsSince(ctx context.Context, characterID string, since time.Time) ([]*models.CharacterVersion, error) {
+	query := `SELECT ` + characterVersionSummaryColumns + ` FROM character_versions
+		WHERE character_id = ? AND created_at > ? ORDER BY version`
+
+	var versions []*models.CharacterVersion
+	if err := r.db.SelectContext(ctx, &versions, r.db.Rebind(query), characterID, since); err != nil {
+		return nil, fmt.Errorf("failed to get character versions: %w", err)
+	}
+	return versions, nil
+}
+
+func (r *characterVersionRepository) getOne(ctx context.Context, query string, args ...interface{}) (*models.CharacterVersion, error) {
+	var version models.CharacterVersion
+	err := r.db.GetContext(ctx, &version, r.db.Rebind(query), args...)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get character version: %w", err)
+	}
+	return &version, nil
+}
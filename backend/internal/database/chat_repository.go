@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+// ChatRepository defines the interface for game session chat history
+type ChatRepository interface {
+	CreateMessage(ctx context.Context, message *models.ChatMessage) error
+	ListMessages(ctx context.Context, sessionID, viewerID string, filter models.ChatFilter) ([]*models.ChatMessage, error)
+}
+
+// chatRepository implements ChatRepository
+type chatRepository struct {
+	db *DB
+}
+
+// NewChatRepository creates a new chat repository
+func NewChatRepository(db *DB) ChatRepository {
+	return &chatRepository{db: db}
+}
+
+const chatMessageColumns = `m.id, m.session_id, m.sender_id, m.sender_name, m.channel, m.kind, m.content,
+	m.dice_roll_id, m.created_at`
+
+// CreateMessage stores a chat message with its whisper recipients
+func (r *chatRepository) CreateMessage(ctx context.Context, message *models.ChatMessage) error {
+	if message.ID == "" {
+		message.ID = uuid.New().String()
+	}
+	message.CreatedAt = time.Now()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `INSERT INTO chat_messages (id, session_id, sender_id, sender_name, channel, kind, content,
+			is_whisper, dice_roll_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	if _, err := tx.ExecContext(ctx, r.db.Rebind(query), message.ID, message.SessionID, message.UserID,
+		message.Username, message.Channel, message.Kind, message.Message, message.IsWhisper(),
+		message.DiceRollID, message.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create chat message: %w", err)
+	}
+
+	query = r.db.Rebind(`INSERT INTO chat_message_recipients (message_id, user_id) VALUES (?, ?)`)
+	for _, userID := range message.Recipients {
+		if _, err := tx.ExecContext(ctx, query, message.ID, userID); err != nil {
+			return fmt.Errorf("failed to add chat recipient: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListMessages returns a page of a session's chat, newest first, leaving out whispers
+// the viewer neither sent nor received
+func (r *chatRepository) ListMessages(ctx context.Context, sessionID, viewerID string, filter models.ChatFilter) ([]*models.ChatMessage, error) {
+	query := `SELECT ` + chatMessageColumns + ` FROM chat_messages m
+		WHERE m.session_id = ?
+			AND (NOT m.is_whisper OR m.sender_id = ? OR EXISTS (
+				SELECT 1 FROM chat_message_recipients cr WHERE cr.message_id = m.id AND cr.user_id = ?))`
+	args := []interface{}{sessionID, viewerID, viewerID}
+	if filter.Channel != "" {
+		query += ` AND m.channel = ?`
+		args = append(args, filter.Channel)
+	}
+	if filter.Search != "" {
+		query += ` AND LOWER(m.content) LIKE ?`
+		args = append(args, "%"+strings.ToLower(filter.Search)+"%")
+	}
+	if filter.Before != "" {
+		query += ` AND (m.created_at, m.id) < (SELECT created_at, id FROM chat_messages WHERE id = ?)`
+		args = append(args, filter.Before)
+	}
+	query += ` ORDER BY m.created_at DESC, m.id DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	messages := make([]*models.ChatMessage, 0)
+	if err := r.db.SelectContext(ctx, &messages, r.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to list chat messages: %w", err)
+	}
+	if err := r.loadRecipients(ctx, messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// loadRecipients fills in who each whisper was sent to
+func (r *chatRepository) loadRecipients(ctx context.Context, messages []*models.ChatMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	byID := make(map[string]*models.ChatMessage, len(messages))
+	ids := make([]string, 0, len(messages))
+	for _, message := range messages {
+		byID[message.ID] = message
+		ids = append(ids, message.ID)
+	}
+
+	query, args, err := sqlx.In(`SELECT message_id, user_id FROM chat_message_recipients
+		WHERE message_id IN (?) ORDER BY user_id`, ids)
+	if err != nil {
+		return err
+	}
+	var rows []struct {
+		MessageID string `db:"message_id"`
+		UserID    string `db:"user_id"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return fmt.Errorf("failed to load chat recipients: %w", err)
+	}
+	for _, row := range rows {
+		message := byID[row.MessageID]
+		message.Recipients = append(message.Recipients, row.UserID)
+	}
+	return nil
+}
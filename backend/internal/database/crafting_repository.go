@@ -0,0 +1,388 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+// CraftingRepository defines the interface for homebrew recipe and crafting project operations.
+// Finishing a project goes through InventoryRepository.ApplyTransaction.
+type CraftingRepository interface {
+	CreateRecipe(ctx context.Context, recipe *models.Recipe) error
+	GetRecipe(ctx context.Context, id string) (*models.Recipe, error)
+	ListRecipes(ctx context.Context, sessionID string) ([]*models.Recipe, error)
+	DeleteRecipe(ctx context.Context, id string) error
+
+	CreateProject(ctx context.Context, project *models.CraftingProject) error
+	GetProject(ctx context.Context, id string) (*models.CraftingProject, error)
+	ListProjects(ctx context.Context, characterID string, status models.CraftingStatus) ([]*models.CraftingProject, error)
+	WorkOnProject(ctx context.Context, project *models.CraftingProject, days int) error
+	AbandonProject(ctx context.Context, id string) error
+
+	GetDowntime(ctx context.Context, characterID string) (int, error)
+	GrantDowntime(ctx context.Context, characterID string, days int, grantedBy string) (int, error)
+}
+
+// craftingRepository implements CraftingRepository
+type craftingRepository struct {
+	db *DB
+}
+
+// NewCraftingRepository creates a new crafting repository
+func NewCraftingRepository(db *DB) CraftingRepository {
+	return &craftingRepository{db: db}
+}
+
+const recipeColumns = `id, session_id, name, category, description, output, ingredients, tool, ability,
+	gold_cost, workdays, dc, created_by, created_at`
+
+const craftingProjectColumns = `id, character_id, session_id, recipe, status, days_worked, check_roll,
+	check_total, transaction_id, created_by, created_at, updated_at, completed_at`
+
+// recipeRow is a homebrew recipe as stored, with its output and ingredients as JSON
+type recipeRow struct {
+	ID          string    `db:"id"`
+	SessionID   string    `db:"session_id"`
+	Name        string    `db:"name"`
+	Category    string    `db:"category"`
+	Description string    `db:"description"`
+	Output      []byte    `db:"output"`
+	Ingredients []byte    `db:"ingredients"`
+	Tool        string    `db:"tool"`
+	Ability     string    `db:"ability"`
+	GoldCost    int       `db:"gold_cost"`
+	Workdays    int       `db:"workdays"`
+	DC          int       `db:"dc"`
+	CreatedBy   string    `db:"created_by"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+func (row *recipeRow) toModel() (*models.Recipe, error) {
+	sessionID := row.SessionID
+	recipe := &models.Recipe{
+		ID:          row.ID,
+		SessionID:   &sessionID,
+		Name:        row.Name,
+		Category:    models.RecipeCategory(row.Category),
+		Description: row.Description,
+		Tool:        row.Tool,
+		Ability:     row.Ability,
+		GoldCost:    row.GoldCost,
+		Workdays:    row.Workdays,
+		DC:          row.DC,
+		CreatedBy:   row.CreatedBy,
+		CreatedAt:   row.CreatedAt,
+	}
+	if err := json.Unmarshal(row.Output, &recipe.Output); err != nil {
+		return nil, fmt.Errorf("failed to decode recipe output: %w", err)
+	}
+	if len(row.Ingredients) > 0 {
+		if err := json.Unmarshal(row.Ingredients, &recipe.Ingredients); err != nil {
+			return nil, fmt.Errorf("failed to decode recipe ingredients: %w", err)
+		}
+	}
+	return recipe, nil
+}
+
+// craftingProjectRow is a crafting project as stored, with its recipe as JSON
+type craftingProjectRow struct {
+	ID            string         `db:"id"`
+	CharacterID   string         `db:"character_id"`
+	SessionID     sql.NullString `db:"session_id"`
+	Recipe        []byte         `db:"recipe"`
+	Status        string         `db:"status"`
+	DaysWorked    int            `db:"days_worked"`
+	CheckRoll     sql.NullInt64  `db:"check_roll"`
+	CheckTotal    sql.NullInt64  `db:"check_total"`
+	TransactionID sql.NullString `db:"transaction_id"`
+	CreatedBy     string         `db:"created_by"`
+	CreatedAt     time.Time      `db:"created_at"`
+	UpdatedAt     time.Time      `db:"updated_at"`
+	CompletedAt   sql.NullTime   `db:"completed_at"`
+}
+
+func (row *craftingProjectRow) toModel() (*models.CraftingProject, error) {
+	project := &models.CraftingProject{
+		ID:          row.ID,
+		CharacterID: row.CharacterID,
+		Status:      models.CraftingStatus(row.Status),
+		DaysWorked:  row.DaysWorked,
+		CreatedBy:   row.CreatedBy,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+	if row.SessionID.Valid {
+		project.SessionID = &row.SessionID.String
+	}
+	if row.CheckRoll.Valid {
+		roll := int(row.CheckRoll.Int64)
+		project.CheckRoll = &roll
+	}
+	if row.CheckTotal.Valid {
+		total := int(row.CheckTotal.Int64)
+		project.CheckTotal = &total
+	}
+	if row.TransactionID.Valid {
+		project.TransactionID = &row.TransactionID.String
+	}
+	if row.CompletedAt.Valid {
+		project.CompletedAt = &row.CompletedAt.Time
+	}
+	if err := json.Unmarshal(row.Recipe, &project.Recipe); err != nil {
+		return nil, fmt.Errorf("failed to decode project recipe: %w", err)
+	}
+	return project, nil
+}
+
+// CreateRecipe stores a homebrew recipe for a game session
+func (r *craftingRepository) CreateRecipe(ctx context.Context, recipe *models.Recipe) error {
+	if recipe.SessionID == nil {
+		return fmt.Errorf("homebrew recipes belong to a game session")
+	}
+	if recipe.ID == "" {
+		recipe.ID = uuid.New().String()
+	}
+	if recipe.CreatedAt.IsZero() {
+		recipe.CreatedAt = time.Now()
+	}
+
+	output, err := json.Marshal(recipe.Output)
+	if err != nil {
+		return err
+	}
+	ingredients := recipe.Ingredients
+	if ingredients == nil {
+		ingredients = []models.RecipeComponent{}
+	}
+	ingredientsJSON, err := json.Marshal(ingredients)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO crafting_recipes (` + recipeColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), recipe.ID, *recipe.SessionID, recipe.Name,
+		recipe.Category, recipe.Description, output, ingredientsJSON, recipe.Tool, recipe.Ability,
+		recipe.GoldCost, recipe.Workdays, recipe.DC, recipe.CreatedBy, recipe.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create recipe: %w", err)
+	}
+	return nil
+}
+
+// GetRecipe returns a homebrew recipe, or nil if it does not exist
+func (r *craftingRepository) GetRecipe(ctx context.Context, id string) (*models.Recipe, error) {
+	query := `SELECT ` + recipeColumns + ` FROM crafting_recipes WHERE id = ?`
+
+	var row recipeRow
+	err := r.db.GetContext(ctx, &row, r.db.Rebind(query), id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recipe: %w", err)
+	}
+	return row.toModel()
+}
+
+// ListRecipes returns a game session's homebrew recipes by name
+func (r *craftingRepository) ListRecipes(ctx context.Context, sessionID string) ([]*models.Recipe, error) {
+	query := `SELECT ` + recipeColumns + ` FROM crafting_recipes WHERE session_id = ? ORDER BY name`
+
+	var rows []recipeRow
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), sessionID); err != nil {
+		return nil, fmt.Errorf("failed to list recipes: %w", err)
+	}
+
+	recipes := make([]*models.Recipe, 0, len(rows))
+	for i := range rows {
+		recipe, err := rows[i].toModel()
+		if err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, recipe)
+	}
+	return recipes, nil
+}
+
+// DeleteRecipe removes a homebrew recipe. Projects already started keep their copy of it.
+func (r *craftingRepository) DeleteRecipe(ctx context.Context, id string) error {
+	query := `DELETE FROM crafting_recipes WHERE id = ?`
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), id); err != nil {
+		return fmt.Errorf("failed to delete recipe: %w", err)
+	}
+	return nil
+}
+
+// CreateProject stores a new crafting project
+func (r *craftingRepository) CreateProject(ctx context.Context, project *models.CraftingProject) error {
+	if project.ID == "" {
+		project.ID = uuid.New().String()
+	}
+	now := time.Now()
+	project.CreatedAt = now
+	project.UpdatedAt = now
+	if project.Status == "" {
+		project.Status = models.CraftingStatusInProgress
+	}
+
+	recipe, err := json.Marshal(project.Recipe)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO crafting_projects (id, character_id, session_id, recipe_id, recipe, status,
+			days_worked, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), project.ID, project.CharacterID, project.SessionID,
+		project.Recipe.ID, recipe, project.Status, project.DaysWorked, project.CreatedBy, now, now); err != nil {
+		return fmt.Errorf("failed to create crafting project: %w", err)
+	}
+	return nil
+}
+
+// GetProject returns a crafting project, or nil if it does not exist
+func (r *craftingRepository) GetProject(ctx context.Context, id string) (*models.CraftingProject, error) {
+	query := `SELECT ` + craftingProjectColumns + ` FROM crafting_projects WHERE id = ?`
+
+	var row craftingProjectRow
+	err := r.db.GetContext(ctx, &row, r.db.Rebind(query), id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get crafting project: %w", err)
+	}
+	return row.toModel()
+}
+
+// ListProjects returns a character's crafting projects, newest first.
+// An empty status lists projects in every status.
+func (r *craftingRepository) ListProjects(ctx context.Context, characterID string, status models.CraftingStatus) ([]*models.CraftingProject, error) {
+	query := `SELECT ` + craftingProjectColumns + ` FROM crafting_projects WHERE character_id = ?`
+	args := []interface{}{characterID}
+	if status != "" {
+		query += ` AND status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY created_at DESC`
+
+	var rows []craftingProjectRow
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to list crafting projects: %w", err)
+	}
+
+	projects := make([]*models.CraftingProject, 0, len(rows))
+	for i := range rows {
+		project, err := rows[i].toModel()
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+	return projects, nil
+}
+
+// WorkOnProject records the workdays spent on a project still in progress, spending the
+// same number of the character's downtime days
+func (r *craftingRepository) WorkOnProject(ctx context.Context, project *models.CraftingProject, days int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now()
+	if err := spendDowntime(tx, r.db, project.CharacterID, days, now); err != nil {
+		return err
+	}
+
+	query := `UPDATE crafting_projects SET days_worked = ?, updated_at = ? WHERE id = ? AND status = ?`
+	result, err := tx.Exec(r.db.Rebind(query), project.DaysWorked, now, project.ID, models.CraftingStatusInProgress)
+	if err != nil {
+		return fmt.Errorf("failed to update crafting project: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("crafting project is no longer in progress")
+	}
+	return tx.Commit()
+}
+
+// AbandonProject stops work on a project. Nothing it would have used has been consumed yet.
+func (r *craftingRepository) AbandonProject(ctx context.Context, id string) error {
+	query := `UPDATE crafting_projects SET status = ?, updated_at = ? WHERE id = ? AND status = ?`
+	return r.updateInProgress(ctx, query, models.CraftingStatusAbandoned, time.Now(), id, models.CraftingStatusInProgress)
+}
+
+func (r *craftingRepository) updateInProgress(ctx context.Context, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), args...)
+	if err != nil {
+		return fmt.Errorf("failed to update crafting project: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("crafting project is no longer in progress")
+	}
+	return nil
+}
+
+// GetDowntime returns how many downtime days the character has left to spend
+func (r *craftingRepository) GetDowntime(ctx context.Context, characterID string) (int, error) {
+	var days int
+	query := `SELECT days_available FROM character_downtime WHERE character_id = ?`
+	err := r.db.QueryRowContextRebind(ctx, query, characterID).Scan(&days)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get downtime: %w", err)
+	}
+	return days, nil
+}
+
+// GrantDowntime adds downtime days to the character's balance and returns the new balance
+func (r *craftingRepository) GrantDowntime(ctx context.Context, characterID string, days int, grantedBy string) (int, error) {
+	query := `INSERT INTO character_downtime (character_id, days_available, updated_by, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (character_id) DO UPDATE SET
+			days_available = character_downtime.days_available + EXCLUDED.days_available,
+			updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+		RETURNING days_available`
+	var balance int
+	if err := r.db.QueryRowContextRebind(ctx, query, characterID, days, grantedBy, time.Now()).Scan(&balance); err != nil {
+		return 0, fmt.Errorf("failed to grant downtime: %w", err)
+	}
+	return balance, nil
+}
+
+// spendDowntime takes days from the character's downtime balance inside a transaction
+func spendDowntime(tx *sqlx.Tx, db *DB, characterID string, days int, now time.Time) error {
+	query := `UPDATE character_downtime SET days_available = days_available - ?, updated_at = ?
+		WHERE character_id = ? AND days_available >= ?`
+	result, err := tx.Exec(db.Rebind(query), days, now, characterID, days)
+	if err != nil {
+		return fmt.Errorf("failed to spend downtime: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("not enough downtime: the DM must grant %d more days", days)
+	}
+	return nil
+}
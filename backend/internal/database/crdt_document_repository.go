@@ -0,0 +1,173 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+// CRDTDocumentRepository defines the interface for stored automerge documents
+type CRDTDocumentRepository interface {
+	LoadDocument(ctx context.Context, id string) (*models.CRDTDocument, error)
+	AppendChange(ctx context.Context, documentID string, data []byte) (int64, error)
+	CompactDocument(ctx context.Context, documentID string, snapshot []byte, changeIDs []int64) error
+	CanAccessCharacter(ctx context.Context, characterID, userID string) (bool, error)
+	CanAccessPartyNotes(ctx context.Context, partyID, userID string) (bool, error)
+	CanAccessBattleMap(ctx context.Context, battleMapID, userID string) (bool, error)
+	CanAccessSessionPrep(ctx context.Context, sessionID, userID string) (bool, error)
+}
+
+// crdtDocumentRepository implements CRDTDocumentRepository
+type crdtDocumentRepository struct {
+	db *DB
+}
+
+// NewCRDTDocumentRepository creates a new CRDT document repository
+func NewCRDTDocumentRepository(db *DB) CRDTDocumentRepository {
+	return &crdtDocumentRepository{db: db}
+}
+
+// LoadDocument returns a document's snapshot and the changes appended since, or nil if
+// the document has never been stored
+func (r *crdtDocumentRepository) LoadDocument(ctx context.Context, id string) (*models.CRDTDocument, error) {
+	doc := &models.CRDTDocument{ID: id}
+	err := r.db.QueryRowContextRebind(ctx, `SELECT snapshot FROM crdt_documents WHERE id = ?`, id).Scan(&doc.Snapshot)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load crdt document: %w", err)
+	}
+
+	query := `SELECT id, data FROM crdt_changes WHERE document_id = ? ORDER BY id`
+	if err := r.db.SelectContext(ctx, &doc.Changes, r.db.Rebind(query), id); err != nil {
+		return nil, fmt.Errorf("failed to load crdt changes: %w", err)
+	}
+	return doc, nil
+}
+
+// AppendChange stores an incremental save of a document, creating the document on its
+// first change, and returns the change's id
+func (r *crdtDocumentRepository) AppendChange(ctx context.Context, documentID string, data []byte) (int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now()
+	query := `INSERT INTO crdt_documents (id, created_at, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET updated_at = excluded.updated_at`
+	if _, err := tx.ExecContext(ctx, r.db.Rebind(query), documentID, now, now); err != nil {
+		return 0, fmt.Errorf("failed to create crdt document: %w", err)
+	}
+
+	var id int64
+	query = `INSERT INTO crdt_changes (document_id, data, created_at) VALUES (?, ?, ?) RETURNING id`
+	if err := tx.QueryRowContext(ctx, r.db.Rebind(query), documentID, data, now).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to append crdt change: %w", err)
+	}
+	return id, tx.Commit()
+}
+
+// CompactDocument replaces a document's snapshot and drops the changes it now includes.
+// Only the listed changes are dropped: another server may have appended changes this
+// snapshot has never seen.
+func (r *crdtDocumentRepository) CompactDocument(ctx context.Context, documentID string, snapshot []byte, changeIDs []int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now()
+	query := `INSERT INTO crdt_documents (id, snapshot, compacted_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET snapshot = excluded.snapshot, compacted_at = excluded.compacted_at,
+			updated_at = excluded.updated_at`
+	if _, err := tx.ExecContext(ctx, r.db.Rebind(query), documentID, snapshot, now, now, now); err != nil {
+		return fmt.Errorf("failed to save crdt snapshot: %w", err)
+	}
+
+	if len(changeIDs) > 0 {
+		query, args, err := sqlx.In(`DELETE FROM crdt_changes WHERE document_id = ? AND id IN (?)`, documentID, changeIDs)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, r.db.Rebind(query), args...); err != nil {
+			return fmt.Errorf("failed to drop compacted crdt changes: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// CanAccessCharacter reports whether a user may sync a character's document: its owner,
+// the DM of a session it plays in, or another player in such a session
+func (r *crdtDocumentRepository) CanAccessCharacter(ctx context.Context, characterID, userID string) (bool, error) {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM characters WHERE id = ? AND user_id = ?)
+			OR EXISTS (
+				SELECT 1 FROM game_participants gp
+				JOIN game_sessions gs ON gs.id = gp.game_session_id
+				WHERE gp.character_id = ?
+					AND (gs.dm_user_id = ? OR EXISTS (
+						SELECT 1 FROM game_participants other
+						WHERE other.game_session_id = gp.game_session_id AND other.user_id = ?
+							AND other.role = 'player'))
+			)`
+
+	var allowed bool
+	err := r.db.QueryRowContextRebind(ctx, query, characterID, userID, characterID, userID, userID).Scan(&allowed)
+	if err != nil {
+		return false, fmt.Errorf("failed to check character access: %w", err)
+	}
+	return allowed, nil
+}
+
+// CanAccessPartyNotes reports whether a user may sync a party's notes: the DM or a player
+// of the party's session
+func (r *crdtDocumentRepository) CanAccessPartyNotes(ctx context.Context, partyID, userID string) (bool, error) {
+	return r.isSessionMember(ctx, `SELECT session_id FROM parties WHERE id = ?`, partyID, userID)
+}
+
+// CanAccessBattleMap reports whether a user may sync a battle map's annotations: the DM
+// or a player of the map's session
+func (r *crdtDocumentRepository) CanAccessBattleMap(ctx context.Context, battleMapID, userID string) (bool, error) {
+	return r.isSessionMember(ctx, `SELECT game_session_id FROM battle_maps WHERE id = ?`, battleMapID, userID)
+}
+
+// CanAccessSessionPrep reports whether a user may sync a session's prep document, which
+// only its DM may see
+func (r *crdtDocumentRepository) CanAccessSessionPrep(ctx context.Context, sessionID, userID string) (bool, error) {
+	var allowed bool
+	query := `SELECT EXISTS (SELECT 1 FROM game_sessions WHERE id = ? AND dm_user_id = ?)`
+	if err := r.db.QueryRowContextRebind(ctx, query, sessionID, userID).Scan(&allowed); err != nil {
+		return false, fmt.Errorf("failed to check session prep access: %w", err)
+	}
+	return allowed, nil
+}
+
+// isSessionMember reports whether a user is the DM or a player of the session selected
+// by sessionQuery, which takes the record's id. Spectators only watch, so they cannot edit
+// the session's documents.
+func (r *crdtDocumentRepository) isSessionMember(ctx context.Context, sessionQuery, id, userID string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM game_sessions gs
+			WHERE gs.id = (` + sessionQuery + `)
+				AND (gs.dm_user_id = ? OR EXISTS (
+					SELECT 1 FROM game_participants gp
+					WHERE gp.game_session_id = gs.id AND gp.user_id = ? AND gp.role = 'player'))
+		)`
+
+	var allowed bool
+	if err := r.db.QueryRowContextRebind(ctx, query, id, userID, userID).Scan(&allowed); err != nil {
+		return false, fmt.Errorf("failed to check session membership: %w", err)
+	}
+	return allowed, nil
+}
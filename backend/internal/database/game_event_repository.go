@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+// GameEventRepository defines the interface for the session event log
+type GameEventRepository interface {
+	Append(ctx context.Context, event *models.GameEvent) error
+	List(ctx context.Context, filter models.GameEventFilter) ([]*models.GameEvent, error)
+}
+
+// gameEventRepository implements GameEventRepository
+type gameEventRepository struct {
+	db *DB
+}
+
+// NewGameEventRepository creates a new game event repository
+func NewGameEventRepository(db *DB) GameEventRepository {
+	return &gameEventRepository{db: db}
+}
+
+// gameEventRow is a game event as stored, with its data still encoded
+type gameEventRow struct {
+	models.GameEvent
+	Payload []byte `db:"payload"`
+}
+
+// Append adds an event to the end of its session's log
+func (r *gameEventRepository) Append(ctx context.Context, event *models.GameEvent) error {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	payload, err := json.Marshal(event.Data)
+	if err != nil {
+		return fmt.Errorf("failed to encode game event data: %w", err)
+	}
+	if event.Data == nil {
+		payload = []byte("{}")
+	}
+
+	query := `INSERT INTO game_events (id, session_id, type, actor_id, character_id, payload, game_time, occurred_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	if _, err := r.db.ExecContextRebind(ctx, query, event.ID, event.SessionID, event.Type, event.PlayerID,
+		event.CharacterID, string(payload), event.GameTime, event.Timestamp); err != nil {
+		return fmt.Errorf("failed to record game event: %w", err)
+	}
+	return nil
+}
+
+// List returns the events matching the filter, oldest first
+func (r *gameEventRepository) List(ctx context.Context, filter models.GameEventFilter) ([]*models.GameEvent, error) {
+	query := `SELECT e.id, e.session_id, e.type, e.actor_id, e.character_id, e.payload, e.game_time, e.occurred_at
+		FROM game_events e WHERE 1 = 1`
+	var args []interface{}
+	if filter.SessionID != "" {
+		query += ` AND e.session_id = ?`
+		args = append(args, filter.SessionID)
+	}
+	if filter.CharacterID != "" {
+		query += ` AND e.character_id = ?`
+		args = append(args, filter.CharacterID)
+	}
+	if filter.PlayerID != "" {
+		query += ` AND e.actor_id = ?`
+		args = append(args, filter.PlayerID)
+	}
+	if len(filter.Types) > 0 {
+		query += ` AND e.type IN (?)`
+		args = append(args, filter.Types)
+	}
+	if filter.Since != nil {
+		query += ` AND e.occurred_at >= ?`
+		args = append(args, *filter.Since)
+	}
+	if filter.Until != nil {
+		query += ` AND e.occurred_at < ?`
+		args = append(args, *filter.Until)
+	}
+	if filter.After != "" {
+		query += ` AND (e.occurred_at, e.id) > (SELECT occurred_at, id FROM game_events WHERE id = ?)`
+		args = append(args, filter.After)
+	}
+	query += ` ORDER BY e.occurred_at, e.id`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	query, args, err := sqlx.In(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	var rows []gameEventRow
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to list game events: %w", err)
+	}
+
+	events := make([]*models.GameEvent, 0, len(rows))
+	for i := range rows {
+		event := rows[i].GameEvent
+		if err := json.Unmarshal(rows[i].Payload, &event.Data); err != nil {
+			return nil, fmt.Errorf("failed to decode game event %s: %w", event.ID, err)
+		}
+		events = append(events, &event)
+	}
+	return events, nil
+}
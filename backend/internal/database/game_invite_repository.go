@@ -0,0 +1,133 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+// GameInviteRepository defines the interface for invitations to game sessions
+type GameInviteRepository interface {
+	Create(ctx context.Context, invite *models.GameInvite) error
+	GetByID(ctx context.Context, id string) (*models.GameInvite, error)
+	GetByCode(ctx context.Context, code string) (*models.GameInvite, error)
+	ListPending(ctx context.Context, sessionID string, now time.Time) ([]*models.GameInvite, error)
+	Claim(ctx context.Context, id, userID string, now time.Time) (bool, error)
+	Release(ctx context.Context, id string) error
+	Revoke(ctx context.Context, id string, now time.Time) error
+}
+
+// gameInviteRepository implements GameInviteRepository
+type gameInviteRepository struct {
+	db *DB
+}
+
+// NewGameInviteRepository creates a new game invite repository
+func NewGameInviteRepository(db *DB) GameInviteRepository {
+	return &gameInviteRepository{db: db}
+}
+
+const gameInviteColumns = `id, session_id, inviter_id, invitee_email, invitee_id, code, max_uses, use_count,
+	expires_at, used_at, revoked_at, created_at`
+
+// Create stores a new invite
+func (r *gameInviteRepository) Create(ctx context.Context, invite *models.GameInvite) error {
+	if invite.ID == "" {
+		invite.ID = uuid.New().String()
+	}
+	invite.CreatedAt = time.Now()
+
+	query := `INSERT INTO game_invites (id, session_id, inviter_id, invitee_email, code, max_uses, use_count,
+			expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	if _, err := r.db.ExecContextRebind(ctx, query, invite.ID, invite.SessionID, invite.InviterID, invite.InviteeEmail,
+		invite.Code, invite.MaxUses, invite.UseCount, invite.ExpiresAt, invite.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create game invite: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns an invite, or nil if it does not exist
+func (r *gameInviteRepository) GetByID(ctx context.Context, id string) (*models.GameInvite, error) {
+	return r.get(ctx, `id = ?`, id)
+}
+
+// GetByCode returns the invite with the given code, or nil if there is none
+func (r *gameInviteRepository) GetByCode(ctx context.Context, code string) (*models.GameInvite, error) {
+	return r.get(ctx, `code = ?`, code)
+}
+
+func (r *gameInviteRepository) get(ctx context.Context, where string, arg string) (*models.GameInvite, error) {
+	query := `SELECT ` + gameInviteColumns + ` FROM game_invites WHERE ` + where
+
+	var invite models.GameInvite
+	err := r.db.GetContext(ctx, &invite, r.db.Rebind(query), arg)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get game invite: %w", err)
+	}
+	return &invite, nil
+}
+
+// ListPending returns a session's invites that can still be redeemed, newest first
+func (r *gameInviteRepository) ListPending(ctx context.Context, sessionID string, now time.Time) ([]*models.GameInvite, error) {
+	query := `SELECT ` + gameInviteColumns + ` FROM game_invites
+		WHERE session_id = ? AND revoked_at IS NULL AND expires_at > ? AND use_count < max_uses
+		ORDER BY created_at DESC, id`
+
+	invites := make([]*models.GameInvite, 0)
+	if err := r.db.SelectContext(ctx, &invites, r.db.Rebind(query), sessionID, now); err != nil {
+		return nil, fmt.Errorf("failed to list game invites: %w", err)
+	}
+	return invites, nil
+}
+
+// Claim uses up one redemption of an invite for the user. It reports false when the invite
+// was revoked, has expired or has no uses left, so two players racing for the last use of
+// an invite cannot both have it.
+func (r *gameInviteRepository) Claim(ctx context.Context, id, userID string, now time.Time) (bool, error) {
+	query := `UPDATE game_invites SET use_count = use_count + 1, used_at = ?,
+			invitee_id = CASE WHEN invitee_email = '' THEN invitee_id ELSE ? END
+		WHERE id = ? AND revoked_at IS NULL AND expires_at > ? AND use_count < max_uses`
+	result, err := r.db.ExecContextRebind(ctx, query, now, userID, id, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim game invite: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim game invite: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// Release gives back a use claimed by a player who then could not join
+func (r *gameInviteRepository) Release(ctx context.Context, id string) error {
+	query := `UPDATE game_invites SET use_count = use_count - 1,
+			used_at = CASE WHEN use_count = 1 THEN NULL ELSE used_at END,
+			invitee_id = CASE WHEN use_count = 1 THEN NULL ELSE invitee_id END
+		WHERE id = ? AND use_count > 0`
+	if _, err := r.db.ExecContextRebind(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to release game invite: %w", err)
+	}
+	return nil
+}
+
+// Revoke stops an invite from being redeemed again
+func (r *gameInviteRepository) Revoke(ctx context.Context, id string, now time.Time) error {
+	result, err := r.db.ExecContextRebind(ctx,
+		`UPDATE game_invites SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`, now, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke game invite: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
@@ -193,17 +193,27 @@ func (r *gameSessionRepository) GetByParticipantUserID(ctx context.Context, user
 
 // Update updates a game session
 func (r *gameSessionRepository) Update(ctx context.Context, session *models.GameSession) error {
+	// State holds the DM's table rules, so it is saved along with the rest of the session
+	stateJSON := constants.EmptyJSON
+	if len(session.State) > 0 {
+		data, err := json.Marshal(session.State)
+		if err != nil {
+			return fmt.Errorf("failed to marshal session state: %w", err)
+		}
+		stateJSON = string(data)
+	}
+
 	query := `
 		UPDATE game_sessions
 		SET name = ?, description = ?, status = ?, is_active = ?, max_players = ?, 
 		    is_public = ?, requires_invite = ?, allowed_character_level = ?,
-		    updated_at = CURRENT_TIMESTAMP
+		    session_state = ?, updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?`
 
 	_, err := r.db.ExecContextRebind(ctx, query,
 		session.Name, session.Description, session.Status, session.IsActive,
 		session.MaxPlayers, session.IsPublic, session.RequiresInvite,
-		session.AllowedCharacterLevel, session.ID)
+		session.AllowedCharacterLevel, stateJSON, session.ID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return fmt.Errorf(constants.ErrGameSessionNotFound)
@@ -290,6 +300,27 @@ func (r *gameSessionRepository) AddParticipant(ctx context.Context, sessionID, u
 	return nil
 }
 
+// AddSpectator adds a user who watches a game session without taking a seat or playing a
+// character
+func (r *gameSessionRepository) AddSpectator(ctx context.Context, sessionID, userID string) error {
+	participantID := fmt.Sprintf("participant-%s-%s-%d", userID, sessionID, time.Now().UnixNano())
+
+	query := `
+		INSERT INTO game_participants (id, session_id, user_id, is_online, role)
+		VALUES (?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, r.db.Rebind(query), participantID, sessionID, userID, false,
+		models.ParticipantRoleSpectator)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return fmt.Errorf("user already in session")
+		}
+		return fmt.Errorf("failed to add spectator: %w", err)
+	}
+
+	return nil
+}
+
 // RemoveParticipant removes a participant from a game session
 func (r *gameSessionRepository) RemoveParticipant(ctx context.Context, sessionID, userID string) error {
 	query := `
@@ -313,12 +344,15 @@ func (r *gameSessionRepository) RemoveParticipant(ctx context.Context, sessionID
 	return nil
 }
 
-// GetParticipants retrieves all participants for a game session
+// GetParticipants retrieves all participants for a game session. The session's DM has the
+// DM role; everyone else joined as a player or a spectator.
 func (r *gameSessionRepository) GetParticipants(ctx context.Context, sessionID string) ([]*models.GameParticipant, error) {
 	query := `
 		SELECT 
-			gp.session_id, gp.user_id, gp.character_id, gp.is_online, gp.joined_at
+			gp.session_id, gp.user_id, gp.character_id, gp.is_online, gp.joined_at,
+			CASE WHEN gs.dm_user_id = gp.user_id THEN 'dm' ELSE gp.role END
 		FROM game_participants gp
+		JOIN game_sessions gs ON gs.id = gp.session_id
 		WHERE gp.session_id = ?
 		ORDER BY gp.joined_at`
 
@@ -335,7 +369,7 @@ func (r *gameSessionRepository) GetParticipants(ctx context.Context, sessionID s
 		var p models.GameParticipant
 
 		err := rows.Scan(
-			&p.SessionID, &p.UserID, &p.CharacterID, &p.IsOnline, &p.JoinedAt)
+			&p.SessionID, &p.UserID, &p.CharacterID, &p.IsOnline, &p.JoinedAt, &p.Role)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan participant: %w", err)
 		}
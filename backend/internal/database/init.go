@@ -83,22 +83,34 @@ func InitializeWithLogging(cfg *config.Config, log *logger.LoggerV2) (*DB, *Repo
 
 	// Create repositories
 	repos := &Repositories{
-		Users:           NewUserRepository(db),
-		Characters:      NewCharacterRepository(db),
-		GameSessions:    NewGameSessionRepository(db),
-		DiceRolls:       NewDiceRollRepository(db),
-		NPCs:            NewNPCRepository(db.DB),
-		Inventory:       NewInventoryRepository(db),
-		RefreshTokens:   NewRefreshTokenRepository(db.DB),
-		CustomRaces:     NewCustomRaceRepository(db.DB),
-		CustomClasses:   NewCustomClassRepository(db),
-		DMAssistant:     NewDMAssistantRepository(db.DB),
-		Encounters:      NewEncounterRepository(db),
-		Campaign:        NewCampaignRepository(db.DB),
-		CombatAnalytics: NewCombatAnalyticsRepository(db.DB),
-		WorldBuilding:   NewWorldBuildingRepository(db),
-		Narrative:       NewNarrativeRepository(db.DB),
-		RuleBuilder:     NewRuleBuilderRepository(db),
+		Users:              NewUserRepository(db),
+		Characters:         NewCharacterRepository(db),
+		GameSessions:       NewGameSessionRepository(db),
+		DiceRolls:          NewDiceRollRepository(db),
+		NPCs:               NewNPCRepository(db.DB),
+		Inventory:          NewInventoryRepository(db),
+		CharacterResources: NewCharacterResourceRepository(db),
+		CharacterVersions:  NewCharacterVersionRepository(db),
+		Parties:            NewPartyRepository(db),
+		Crafting:           NewCraftingRepository(db),
+		LootPools:          NewLootPoolRepository(db),
+		CRDTDocuments:      NewCRDTDocumentRepository(db),
+		RoomMessages:       NewRoomMessageRepository(db),
+		Chat:               NewChatRepository(db),
+		GameEvents:         NewGameEventRepository(db),
+		Schedules:          NewScheduleRepository(db),
+		GameInvites:        NewGameInviteRepository(db),
+		ItemLibrary:        NewItemLibraryRepository(db),
+		RefreshTokens:      NewRefreshTokenRepository(db.DB),
+		CustomRaces:        NewCustomRaceRepository(db.DB),
+		CustomClasses:      NewCustomClassRepository(db),
+		DMAssistant:        NewDMAssistantRepository(db.DB),
+		Encounters:         NewEncounterRepository(db),
+		Campaign:           NewCampaignRepository(db.DB),
+		CombatAnalytics:    NewCombatAnalyticsRepository(db.DB),
+		WorldBuilding:      NewWorldBuildingRepository(db),
+		Narrative:          NewNarrativeRepository(db.DB),
+		RuleBuilder:        NewRuleBuilderRepository(db),
 	}
 
 	return db, repos, nil
@@ -3,9 +3,13 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 
 	"github.com/ctclostio/DnD-Game/backend/internal/models"
 )
@@ -36,47 +40,49 @@ func (r *inventoryRepository) CreateItem(item *models.Item) error {
 	return err
 }
 
+// itemColumns are read by scanItem
+const itemColumns = `id, name, type, rarity, weight, value, properties,
+	requires_attunement, attunement_requirements, description, source, session_id, created_at, updated_at`
+
+// GetItem returns any item by ID, homebrew included, or nil if it does not exist. It is for items a
+// character or party already holds; items being handed out are looked up with GetSessionItem or
+// GetCharacterItem so one session's homebrew never reaches another.
 func (r *inventoryRepository) GetItem(itemID string) (*models.Item, error) {
-	var item models.Item
-	var attunementReq, description sql.NullString
+	return r.getItem(`id = ?`, itemID)
+}
 
-	query := `
-		SELECT id, name, type, rarity, weight, value, properties, 
-			requires_attunement, attunement_requirements, description, created_at, updated_at
-		FROM items WHERE id = ?
-	`
-	err := r.db.QueryRowRebind(query, itemID).Scan(
-		&item.ID, &item.Name, &item.Type, &item.Rarity, &item.Weight, &item.Value,
-		&item.Properties, &item.RequiresAttunement, &attunementReq, &description,
-		&item.CreatedAt, &item.UpdatedAt,
-	)
+// GetSessionItem returns a shared item or one of the session's homebrew items, or nil if the session
+// can't see it. An empty sessionID sees only shared items.
+func (r *inventoryRepository) GetSessionItem(itemID, sessionID string) (*models.Item, error) {
+	return r.getItem(`id = ? AND (session_id IS NULL OR session_id = ?)`, itemID, sessionID)
+}
+
+// GetCharacterItem returns a shared item or homebrew from a game session the character has joined,
+// or nil if the character can't see it
+func (r *inventoryRepository) GetCharacterItem(itemID, characterID string) (*models.Item, error) {
+	return r.getItem(`id = ? AND (session_id IS NULL OR session_id IN (
+		SELECT session_id FROM game_participants WHERE character_id = ?))`, itemID, characterID)
+}
+
+func (r *inventoryRepository) getItem(where string, args ...interface{}) (*models.Item, error) {
+	query := `SELECT ` + itemColumns + ` FROM items WHERE ` + where
+	item, err := scanItem(r.db.QueryRowRebind(query, args...))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
-
-	// Handle nullable fields
-	if attunementReq.Valid {
-		item.AttunementRequirements = attunementReq.String
-	}
-	if description.Valid {
-		item.Description = description.String
-	}
-
-	return &item, nil
+	return item, nil
 }
 
-func (r *inventoryRepository) GetItemsByType(itemType models.ItemType) ([]*models.Item, error) {
-	query := `
-		SELECT id, name, type, rarity, weight, value, properties, 
-			requires_attunement, attunement_requirements, description, created_at, updated_at
-		FROM items WHERE type = ? ORDER BY name
-	`
+// GetItemsByType lists the shared items of a type plus, when sessionID is set, that session's homebrew
+func (r *inventoryRepository) GetItemsByType(itemType models.ItemType, sessionID string) ([]*models.Item, error) {
+	query := `SELECT ` + itemColumns + ` FROM items
+		WHERE type = ? AND (session_id IS NULL OR session_id = ?) ORDER BY name`
 
 	query = r.db.Rebind(query)
-	rows, err := r.db.Query(query, itemType)
+	rows, err := r.db.Query(query, itemType, sessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -86,41 +92,47 @@ func (r *inventoryRepository) GetItemsByType(itemType models.ItemType) ([]*model
 
 	items := make([]*models.Item, 0, 20)
 	for rows.Next() {
-		var item models.Item
-		var attunementReq, description sql.NullString
-
-		err := rows.Scan(
-			&item.ID, &item.Name, &item.Type, &item.Rarity, &item.Weight, &item.Value,
-			&item.Properties, &item.RequiresAttunement, &attunementReq, &description,
-			&item.CreatedAt, &item.UpdatedAt,
-		)
+		item, err := scanItem(rows)
 		if err != nil {
 			return nil, err
 		}
+		items = append(items, item)
+	}
 
-		// Handle nullable fields
-		if attunementReq.Valid {
-			item.AttunementRequirements = attunementReq.String
-		}
-		if description.Valid {
-			item.Description = description.String
-		}
+	return items, rows.Err()
+}
 
-		items = append(items, &item)
+// scanItem reads a row of itemColumns
+func scanItem(row interface{ Scan(...interface{}) error }) (*models.Item, error) {
+	var item models.Item
+	var attunementReq, description, source sql.NullString
+
+	err := row.Scan(
+		&item.ID, &item.Name, &item.Type, &item.Rarity, &item.Weight, &item.Value,
+		&item.Properties, &item.RequiresAttunement, &attunementReq, &description,
+		&source, &item.SessionID, &item.CreatedAt, &item.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
 	}
 
-	return items, nil
+	// Handle nullable fields
+	item.AttunementRequirements = attunementReq.String
+	item.Description = description.String
+	item.Source = models.ItemSource(source.String)
+
+	return &item, nil
 }
 
 func (r *inventoryRepository) AddItemToInventory(characterID, itemID string, quantity int) error {
 	id := uuid.New().String()
 	now := time.Now()
 
-	// SQLite requires different syntax for upsert
+	// New items join the character's loose stack, never a container's
 	query := `
 		INSERT INTO character_inventory (id, character_id, item_id, quantity, created_at, updated_at)
 		VALUES (?, ?, ?, ?, ?, ?)
-		ON CONFLICT (character_id, item_id) 
+		ON CONFLICT (character_id, item_id) WHERE container_id IS NULL
 		DO UPDATE SET 
 			quantity = character_inventory.quantity + excluded.quantity,
 			updated_at = excluded.updated_at
@@ -133,6 +145,8 @@ func (r *inventoryRepository) AddItemToInventory(characterID, itemID string, qua
 	return r.updateCharacterWeight(characterID)
 }
 
+// RemoveItemFromInventory takes up to quantity of an item from the character, emptying
+// the loose stack before reaching into containers
 func (r *inventoryRepository) RemoveItemFromInventory(characterID, itemID string, quantity int) error {
 	tx, err := r.db.Beginx()
 	if err != nil {
@@ -140,24 +154,14 @@ func (r *inventoryRepository) RemoveItemFromInventory(characterID, itemID string
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	var currentQty int
-	query := `SELECT quantity FROM character_inventory WHERE character_id = ? AND item_id = ?`
-	query = r.db.Rebind(query)
-	err = tx.Get(&currentQty, query, characterID, itemID)
+	stacks, err := r.lockStacks(tx, characterID, itemID)
 	if err != nil {
 		return err
 	}
-
-	if currentQty <= quantity {
-		query := `DELETE FROM character_inventory WHERE character_id = ? AND item_id = ?`
-		query = r.db.Rebind(query)
-		_, err = tx.Exec(query, characterID, itemID)
-	} else {
-		query := `UPDATE character_inventory SET quantity = quantity - ?, updated_at = ? WHERE character_id = ? AND item_id = ?`
-		query = r.db.Rebind(query)
-		_, err = tx.Exec(query, quantity, time.Now(), characterID, itemID)
+	if len(stacks) == 0 {
+		return sql.ErrNoRows
 	}
-	if err != nil {
+	if err := r.takeFromStacks(tx, characterID, itemID, stacks, quantity, time.Now()); err != nil {
 		return err
 	}
 
@@ -172,14 +176,15 @@ func (r *inventoryRepository) GetCharacterInventory(characterID string) ([]*mode
 	query := `
 		SELECT 
 			ci.id, ci.character_id, ci.item_id, ci.quantity, ci.equipped, ci.attuned,
-			ci.custom_properties, ci.notes, ci.created_at, ci.updated_at,
+			ci.custom_properties, ci.notes, ci.container_id, ci.location, ci.storage_note,
+			ci.charges, ci.curse_active, ci.created_at, ci.updated_at,
 			i.id, i.name, i.type, i.rarity, i.weight, i.value, i.properties,
 			i.requires_attunement, i.attunement_requirements, i.description,
 			i.created_at, i.updated_at
 		FROM character_inventory ci
 		JOIN items i ON ci.item_id = i.id
 		WHERE ci.character_id = ?
-		ORDER BY i.name
+		ORDER BY i.name, ci.container_id IS NOT NULL, ci.created_at
 	`
 
 	query = r.db.Rebind(query)
@@ -195,12 +200,14 @@ func (r *inventoryRepository) GetCharacterInventory(characterID string) ([]*mode
 	for rows.Next() {
 		var inv models.InventoryItem
 		var item models.Item
-		var invNotes, attunementReq, description sql.NullString
+		var invNotes, containerID, storageNote, attunementReq, description sql.NullString
+		var charges sql.NullInt64
 
 		err := rows.Scan(
 			&inv.ID, &inv.CharacterID, &inv.ItemID, &inv.Quantity,
 			&inv.Equipped, &inv.Attuned, &inv.CustomProperties, &invNotes,
-			&inv.CreatedAt, &inv.UpdatedAt,
+			&containerID, &inv.Location, &storageNote,
+			&charges, &inv.CurseActive, &inv.CreatedAt, &inv.UpdatedAt,
 			&item.ID, &item.Name, &item.Type, &item.Rarity, &item.Weight,
 			&item.Value, &item.Properties, &item.RequiresAttunement,
 			&attunementReq, &description,
@@ -214,6 +221,16 @@ func (r *inventoryRepository) GetCharacterInventory(characterID string) ([]*mode
 		if invNotes.Valid {
 			inv.Notes = invNotes.String
 		}
+		if containerID.Valid {
+			inv.ContainerID = &containerID.String
+		}
+		if storageNote.Valid {
+			inv.StorageNote = storageNote.String
+		}
+		if charges.Valid {
+			remaining := int(charges.Int64)
+			inv.Charges = &remaining
+		}
 		if attunementReq.Valid {
 			item.AttunementRequirements = attunementReq.String
 		}
@@ -228,9 +245,15 @@ func (r *inventoryRepository) GetCharacterInventory(characterID string) ([]*mode
 	return items, nil
 }
 
+// primaryStack matches the stack that single-stack operations such as equipping act on when
+// an item is split across containers: the loose stack if there is one, otherwise the oldest.
+// It takes the character and item IDs as its two arguments.
+const primaryStack = `id = (SELECT id FROM character_inventory WHERE character_id = ? AND item_id = ?
+	ORDER BY container_id IS NOT NULL, created_at LIMIT 1)`
+
 func (r *inventoryRepository) EquipItem(characterID, itemID string, equip bool) error {
 	// Use ? placeholders and rebind for database compatibility
-	query := `UPDATE character_inventory SET equipped = ?, updated_at = ? WHERE character_id = ? AND item_id = ?`
+	query := `UPDATE character_inventory SET equipped = ?, updated_at = ? WHERE ` + primaryStack
 	// Rebind the query to match the database driver's placeholder style
 	query = r.db.Rebind(query)
 	result, err := r.db.Exec(query, equip, time.Now(), characterID, itemID)
@@ -288,12 +311,17 @@ func (r *inventoryRepository) AttuneItem(characterID, itemID string) error {
 		return fmt.Errorf("item does not require attunement")
 	}
 
-	query = `UPDATE character_inventory SET attuned = true, updated_at = ? WHERE character_id = ? AND item_id = ?`
+	query = `UPDATE character_inventory SET attuned = true, updated_at = ? WHERE ` + primaryStack
 	query = r.db.Rebind(query)
-	_, err = tx.Exec(query, time.Now(), characterID, itemID)
+	result, err := tx.Exec(query, time.Now(), characterID, itemID)
 	if err != nil {
 		return err
 	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return err
+	} else if rows == 0 {
+		return fmt.Errorf("no inventory item found for character %s and item %s", characterID, itemID)
+	}
 
 	query = `UPDATE characters SET attunement_slots_used = attunement_slots_used + 1 WHERE id = ?`
 	query = r.db.Rebind(query)
@@ -312,16 +340,21 @@ func (r *inventoryRepository) UnattuneItem(characterID, itemID string) error {
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	query := `UPDATE character_inventory SET attuned = false, updated_at = ? WHERE character_id = ? AND item_id = ?`
+	query := `UPDATE character_inventory SET attuned = false, updated_at = ?
+		WHERE character_id = ? AND item_id = ? AND attuned = true`
 	query = r.db.Rebind(query)
-	_, err = tx.Exec(query, time.Now(), characterID, itemID)
+	result, err := tx.Exec(query, time.Now(), characterID, itemID)
+	if err != nil {
+		return err
+	}
+	released, err := result.RowsAffected()
 	if err != nil {
 		return err
 	}
 
-	query = `UPDATE characters SET attunement_slots_used = attunement_slots_used - 1 WHERE id = ?`
+	query = `UPDATE characters SET attunement_slots_used = attunement_slots_used - ? WHERE id = ?`
 	query = r.db.Rebind(query)
-	_, err = tx.Exec(query, characterID)
+	_, err = tx.Exec(query, released, characterID)
 	if err != nil {
 		return err
 	}
@@ -329,6 +362,34 @@ func (r *inventoryRepository) UnattuneItem(characterID, itemID string) error {
 	return tx.Commit()
 }
 
+// SetItemCharges records how many charges an inventory stack has left
+func (r *inventoryRepository) SetItemCharges(characterID, itemID string, charges int) error {
+	query := `UPDATE character_inventory SET charges = ?, updated_at = ? WHERE ` + primaryStack
+	return r.updateInventoryRow(query, charges, time.Now(), characterID, itemID)
+}
+
+// SetItemCurse records whether a cursed item's curse binds the character
+func (r *inventoryRepository) SetItemCurse(characterID, itemID string, active bool) error {
+	query := `UPDATE character_inventory SET curse_active = ?, updated_at = ? WHERE ` + primaryStack
+	return r.updateInventoryRow(query, active, time.Now(), characterID, itemID)
+}
+
+// updateInventoryRow runs an update against one inventory stack, failing if it does not exist
+func (r *inventoryRepository) updateInventoryRow(query string, args ...interface{}) error {
+	result, err := r.db.Exec(r.db.Rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("no inventory item found for character %s and item %s", args[len(args)-2], args[len(args)-1])
+	}
+	return nil
+}
+
 func (r *inventoryRepository) GetCharacterCurrency(characterID string) (*models.Currency, error) {
 	var currency models.Currency
 	query := `SELECT * FROM character_currency WHERE character_id = ?`
@@ -370,33 +431,723 @@ func (r *inventoryRepository) UpdateCharacterCurrency(currency *models.Currency)
 	return err
 }
 
-func (r *inventoryRepository) updateCharacterWeight(characterID string) error {
-	query := `
-		UPDATE characters 
-		SET current_weight = (
-			SELECT COALESCE(SUM(i.weight * ci.quantity), 0)
-			FROM character_inventory ci
-			JOIN items i ON ci.item_id = i.id
-			WHERE ci.character_id = ?
-		)
-		WHERE id = ?
+// inventoryWeightQuery loads what recalculating a character's weight needs from their inventory
+const inventoryWeightQuery = `
+		SELECT ci.id, ci.container_id, ci.location, ci.quantity, i.weight, i.properties
+		FROM character_inventory ci
+		JOIN items i ON ci.item_id = i.id
+		WHERE ci.character_id = ?
 	`
-	_, err := r.db.ExecRebind(query, characterID, characterID)
+
+// weightQueryer is satisfied by both the database and a transaction
+type weightQueryer interface {
+	Select(dest interface{}, query string, args ...interface{}) error
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func (r *inventoryRepository) updateCharacterWeight(characterID string) error {
+	return r.recalculateWeight(r.db, characterID)
+}
+
+// recalculateWeight stores the weight a character carries on their body. Items on a mount
+// or in storage don't count, and bags of holding weigh the same however full they are.
+func (r *inventoryRepository) recalculateWeight(q weightQueryer, characterID string) error {
+	weight, err := r.weighInventory(q, characterID)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE characters SET current_weight = ? WHERE id = ?`
+	_, err = q.Exec(r.db.Rebind(query), weight.CurrentWeight, characterID)
 	return err
 }
 
+func (r *inventoryRepository) weighInventory(q weightQueryer, characterID string) (models.InventoryWeight, error) {
+	var rows []struct {
+		ID          string                `db:"id"`
+		ContainerID *string               `db:"container_id"`
+		Location    models.ItemLocation   `db:"location"`
+		Quantity    int                   `db:"quantity"`
+		Weight      float64               `db:"weight"`
+		Properties  models.ItemProperties `db:"properties"`
+	}
+	if err := q.Select(&rows, r.db.Rebind(inventoryWeightQuery), characterID); err != nil {
+		return models.InventoryWeight{}, err
+	}
+
+	items := make([]*models.InventoryItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, &models.InventoryItem{
+			ID:          row.ID,
+			ContainerID: row.ContainerID,
+			Location:    row.Location,
+			Quantity:    row.Quantity,
+			Item:        &models.Item{Weight: row.Weight, Properties: row.Properties},
+		})
+	}
+	return models.WeighInventory(items), nil
+}
+
 func (r *inventoryRepository) GetCharacterWeight(characterID string) (*models.InventoryWeight, error) {
-	var weight models.InventoryWeight
-	query := `
-		SELECT current_weight, carry_capacity 
-		FROM characters 
-		WHERE id = ?
-	`
-	err := r.db.QueryRowRebind(query, characterID).Scan(&weight.CurrentWeight, &weight.CarryCapacity)
+	weight, err := r.weighInventory(r.db, characterID)
 	if err != nil {
 		return nil, err
 	}
 
+	query := `SELECT carry_capacity FROM characters WHERE id = ?`
+	if err := r.db.QueryRowRebind(query, characterID).Scan(&weight.CarryCapacity); err != nil {
+		return nil, err
+	}
+
 	weight.UpdateEncumbrance()
 	return &weight, nil
 }
+
+// MoveInventoryItem moves a stack, or part of one, into a container or to a location.
+// When the destination already holds a stack of the same item the two are merged.
+func (r *inventoryRepository) MoveInventoryItem(characterID string, move *models.InventoryMove) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now()
+	var storageNote *string
+	if move.StorageNote != "" {
+		storageNote = &move.StorageNote
+	}
+
+	var source inventoryStack
+	query := `SELECT id, quantity, attuned FROM character_inventory
+		WHERE id = ? AND character_id = ?` + r.forUpdate()
+	err = tx.Get(&source, r.db.Rebind(query), move.StackID, characterID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("no inventory item found for character %s and item %s", characterID, move.ItemID)
+	}
+	if err != nil {
+		return err
+	}
+	quantity := move.Quantity
+	if quantity <= 0 || quantity > source.Quantity {
+		quantity = source.Quantity
+	}
+
+	var destinationID string
+	query = `SELECT id FROM character_inventory
+		WHERE character_id = ? AND item_id = ? AND id <> ? AND container_id IS NULL`
+	args := []interface{}{characterID, move.ItemID, move.StackID}
+	if move.ContainerID != nil {
+		query = `SELECT id FROM character_inventory
+			WHERE character_id = ? AND item_id = ? AND id <> ? AND container_id = ?`
+		args = append(args, *move.ContainerID)
+	}
+	err = tx.Get(&destinationID, r.db.Rebind(query+r.forUpdate()), args...)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	switch {
+	case destinationID != "":
+		query = `UPDATE character_inventory SET quantity = quantity + ?, updated_at = ? WHERE id = ?`
+		if _, err := tx.Exec(r.db.Rebind(query), quantity, now, destinationID); err != nil {
+			return err
+		}
+		if err := r.takeFromStacks(tx, characterID, move.ItemID, []inventoryStack{source}, quantity, now); err != nil {
+			return err
+		}
+	case quantity < source.Quantity:
+		query = `UPDATE character_inventory SET quantity = quantity - ?, updated_at = ? WHERE id = ?`
+		if _, err := tx.Exec(r.db.Rebind(query), quantity, now, source.ID); err != nil {
+			return err
+		}
+		query = `INSERT INTO character_inventory (id, character_id, item_id, quantity, container_id,
+				location, storage_note, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		if _, err := tx.Exec(r.db.Rebind(query), uuid.New().String(), characterID, move.ItemID, quantity,
+			move.ContainerID, move.Location, storageNote, now, now); err != nil {
+			return err
+		}
+	default:
+		query = `UPDATE character_inventory
+			SET container_id = ?, location = ?, storage_note = ?, updated_at = ?
+			WHERE id = ?`
+		if _, err := tx.Exec(r.db.Rebind(query), move.ContainerID, move.Location, storageNote,
+			now, source.ID); err != nil {
+			return err
+		}
+
+		if len(move.Contents) > 0 {
+			query, args, err := sqlx.In(`UPDATE character_inventory
+				SET location = ?, storage_note = ?, updated_at = ?
+				WHERE character_id = ? AND id IN (?)`,
+				move.Location, storageNote, now, characterID, move.Contents)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.Exec(r.db.Rebind(query), args...); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := r.recalculateWeight(tx, characterID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *inventoryRepository) GetStartingEquipmentGrant(characterID string) (*models.StartingEquipmentGrant, error) {
+	var grant models.StartingEquipmentGrant
+	query := `
+		SELECT character_id, method, items, gold_awarded, wealth_roll, created_at
+		FROM starting_equipment_grants
+		WHERE character_id = ?
+	`
+	err := r.db.QueryRowRebind(query, characterID).Scan(
+		&grant.CharacterID, &grant.Method, &grant.Items, &grant.GoldAwarded,
+		&grant.WealthRoll, &grant.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
+
+// insertStartingEquipmentGrant records a character's starting equipment. The row is keyed
+// on character_id, so a second grant for the same character fails instead of duplicating it.
+func (r *inventoryRepository) insertStartingEquipmentGrant(tx *sqlx.Tx, grant *models.StartingEquipmentGrant, now time.Time) error {
+	grant.CreatedAt = now
+	query := `
+		INSERT INTO starting_equipment_grants (character_id, method, items, gold_awarded, wealth_roll, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	_, err := tx.Exec(r.db.Rebind(query), grant.CharacterID, grant.Method, grant.Items,
+		grant.GoldAwarded, grant.WealthRoll, grant.CreatedAt)
+	if err != nil && isUniqueViolation(err) {
+		return fmt.Errorf("starting equipment has already been granted")
+	}
+	return err
+}
+
+// ApplyTransaction applies every money and item movement in txn inside one database
+// transaction and appends a ledger entry for each. Purses and inventory rows are locked
+// in character order, so concurrent transactions on the same characters wait for each
+// other instead of spending the same coins twice.
+func (r *inventoryRepository) ApplyTransaction(txn *models.EconomyTransaction) ([]*models.LedgerEntry, error) {
+	if txn.ID == "" {
+		txn.ID = uuid.New().String()
+	}
+	now := time.Now()
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if txn.NewCharacter != nil {
+		query, args, err := characterInsert(txn.NewCharacter)
+		if err != nil {
+			return nil, err
+		}
+		character := txn.NewCharacter
+		if err := tx.QueryRowx(r.db.Rebind(query), args...).
+			Scan(&character.ID, &character.CreatedAt, &character.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to create character: %w", err)
+		}
+	}
+	if txn.StartingEquipment != nil {
+		if err := r.insertStartingEquipmentGrant(tx, txn.StartingEquipment, now); err != nil {
+			return nil, err
+		}
+	}
+
+	characterIDs := transactionCharacterIDs(txn)
+	purses := make(map[string]*models.Currency, len(characterIDs))
+	for _, characterID := range characterIDs {
+		purse, err := r.lockCurrency(tx, characterID, now)
+		if err != nil {
+			return nil, err
+		}
+		purses[characterID] = purse
+	}
+	partyPurses := make(map[string]*models.Currency, len(txn.PartyCurrency))
+	for _, partyID := range partyCurrencyIDs(txn) {
+		purse, err := r.lockPartyCurrency(tx, partyID, now)
+		if err != nil {
+			return nil, err
+		}
+		partyPurses[partyID] = purse
+	}
+
+	entries := make([]*models.LedgerEntry, 0, len(txn.Currency)+len(txn.Items))
+	for _, movement := range txn.Currency {
+		purse := purses[movement.CharacterID]
+		if !purse.Apply(movement.Coins) {
+			return nil, fmt.Errorf("insufficient funds")
+		}
+		entry := newLedgerEntry(txn, movement.CharacterID, now)
+		entry.CopperDelta = movement.Coins.TotalInCopper()
+		entry.BalanceAfter = purse.TotalInCopper()
+		entries = append(entries, entry)
+	}
+
+	for _, movement := range txn.Items {
+		if err := r.moveItem(tx, movement, now); err != nil {
+			return nil, err
+		}
+		itemID := movement.ItemID
+		entry := newLedgerEntry(txn, movement.CharacterID, now)
+		entry.ItemID = &itemID
+		entry.Quantity = movement.Quantity
+		entry.BalanceAfter = purses[movement.CharacterID].TotalInCopper()
+		entries = append(entries, entry)
+	}
+
+	for _, movement := range txn.ShopStock {
+		if err := r.moveShopStock(tx, movement, now); err != nil {
+			return nil, err
+		}
+	}
+	for _, movement := range txn.PartyStash {
+		if err := r.movePartyStash(tx, movement, now); err != nil {
+			return nil, err
+		}
+	}
+	for _, movement := range txn.PartyCurrency {
+		if !partyPurses[movement.PartyID].Apply(movement.Coins) {
+			return nil, fmt.Errorf("the party purse has insufficient funds")
+		}
+	}
+
+	for _, characterID := range characterIDs {
+		purse := purses[characterID]
+		query := `UPDATE character_currency
+			SET copper = ?, silver = ?, electrum = ?, gold = ?, platinum = ?, updated_at = ?
+			WHERE character_id = ?`
+		if _, err := tx.Exec(r.db.Rebind(query), purse.Copper, purse.Silver, purse.Electrum,
+			purse.Gold, purse.Platinum, now, characterID); err != nil {
+			return nil, err
+		}
+	}
+	for partyID, purse := range partyPurses {
+		query := `UPDATE party_currency
+			SET copper = ?, silver = ?, electrum = ?, gold = ?, platinum = ?, updated_at = ?
+			WHERE party_id = ?`
+		if _, err := tx.Exec(r.db.Rebind(query), purse.Copper, purse.Silver, purse.Electrum,
+			purse.Gold, purse.Platinum, now, partyID); err != nil {
+			return nil, err
+		}
+	}
+	for _, characterID := range itemCharacterIDs(txn) {
+		if err := r.recalculateWeight(tx, characterID); err != nil {
+			return nil, err
+		}
+	}
+
+	if txn.TradeOfferID != "" {
+		if err := r.acceptTradeOffer(tx, txn, now); err != nil {
+			return nil, err
+		}
+	}
+	if target := txn.ItemTarget; target != nil {
+		query := `UPDATE characters SET hit_points = ?, temp_hit_points = ?, updated_at = ? WHERE id = ?`
+		if _, err := tx.Exec(r.db.Rebind(query), target.HitPoints, target.TempHitPoints, now, target.ID); err != nil {
+			return nil, fmt.Errorf("failed to update %s: %w", target.Name, err)
+		}
+	}
+	if txn.CraftingProject != nil {
+		if err := r.finishCraftingProject(tx, txn, now); err != nil {
+			return nil, err
+		}
+	}
+	if txn.LootPool != nil {
+		if err := r.distributeLootPool(tx, txn, now); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, entry := range entries {
+		query := `INSERT INTO economy_ledger (id, transaction_id, character_id, entry_type, item_id,
+				quantity, copper_delta, balance_after, description, created_by, session_id, party_id, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		if _, err := tx.Exec(r.db.Rebind(query), entry.ID, entry.TransactionID, entry.CharacterID,
+			entry.EntryType, entry.ItemID, entry.Quantity, entry.CopperDelta, entry.BalanceAfter,
+			entry.Description, entry.CreatedBy, entry.SessionID, entry.PartyID, entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to write ledger: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetLedgerEntries returns ledger entries matching the filter, newest first
+func (r *inventoryRepository) GetLedgerEntries(filter models.LedgerFilter) ([]*models.LedgerEntry, error) {
+	query := `SELECT id, transaction_id, character_id, entry_type, item_id, quantity, copper_delta,
+			balance_after, description, created_by, session_id, party_id, created_at
+		FROM economy_ledger WHERE 1 = 1`
+	var args []interface{}
+
+	if len(filter.CharacterIDs) > 0 {
+		query += ` AND character_id IN (?)`
+		args = append(args, filter.CharacterIDs)
+	}
+	if filter.SessionID != "" {
+		query += ` AND session_id = ?`
+		args = append(args, filter.SessionID)
+	}
+	if filter.PartyID != "" {
+		query += ` AND party_id = ?`
+		args = append(args, filter.PartyID)
+	}
+	if filter.Since != nil {
+		query += ` AND created_at > ?`
+		args = append(args, *filter.Since)
+	}
+	query += ` ORDER BY created_at DESC, id`
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(` LIMIT %d`, filter.Limit)
+	}
+
+	query, args, err := sqlx.In(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*models.LedgerEntry, 0, 20)
+	if err := r.db.Select(&entries, r.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to get ledger entries: %w", err)
+	}
+	return entries, nil
+}
+
+// lockCurrency loads a character's purse for update, creating an empty one if needed
+func (r *inventoryRepository) lockCurrency(tx *sqlx.Tx, characterID string, now time.Time) (*models.Currency, error) {
+	query := `INSERT INTO character_currency (character_id, created_at, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT (character_id) DO NOTHING`
+	if _, err := tx.Exec(r.db.Rebind(query), characterID, now, now); err != nil {
+		return nil, err
+	}
+
+	var currency models.Currency
+	query = `SELECT character_id, copper, silver, electrum, gold, platinum, created_at, updated_at
+		FROM character_currency WHERE character_id = ?` + r.forUpdate()
+	if err := tx.Get(&currency, r.db.Rebind(query), characterID); err != nil {
+		return nil, err
+	}
+	return &currency, nil
+}
+
+// moveItem adds or removes a quantity of an item inside a transaction
+func (r *inventoryRepository) moveItem(tx *sqlx.Tx, movement models.ItemMovement, now time.Time) error {
+	if movement.Quantity > 0 {
+		query := `INSERT INTO character_inventory (id, character_id, item_id, quantity, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (character_id, item_id) WHERE container_id IS NULL
+			DO UPDATE SET
+				quantity = character_inventory.quantity + excluded.quantity,
+				updated_at = excluded.updated_at`
+		_, err := tx.Exec(r.db.Rebind(query), uuid.New().String(), movement.CharacterID, movement.ItemID,
+			movement.Quantity, now, now)
+		return err
+	}
+
+	stacks, err := r.lockStacks(tx, movement.CharacterID, movement.ItemID)
+	if err != nil {
+		return err
+	}
+	if len(stacks) == 0 {
+		return fmt.Errorf("item %s is not in the inventory", movement.ItemID)
+	}
+	held := 0
+	for _, stack := range stacks {
+		held += stack.Quantity
+	}
+	if held < -movement.Quantity {
+		return fmt.Errorf("only %d of item %s in the inventory", held, movement.ItemID)
+	}
+	return r.takeFromStacks(tx, movement.CharacterID, movement.ItemID, stacks, -movement.Quantity, now)
+}
+
+// inventoryStack is one of a character's stacks of an item
+type inventoryStack struct {
+	ID       string `db:"id"`
+	Quantity int    `db:"quantity"`
+	Attuned  bool   `db:"attuned"`
+}
+
+// lockStacks locks every stack of an item the character holds, loose stack first
+func (r *inventoryRepository) lockStacks(tx *sqlx.Tx, characterID, itemID string) ([]inventoryStack, error) {
+	query := `SELECT id, quantity, attuned FROM character_inventory
+		WHERE character_id = ? AND item_id = ?
+		ORDER BY container_id IS NOT NULL, created_at` + r.forUpdate()
+	var stacks []inventoryStack
+	if err := tx.Select(&stacks, r.db.Rebind(query), characterID, itemID); err != nil {
+		return nil, err
+	}
+	return stacks, nil
+}
+
+// takeFromStacks removes up to quantity items from the stacks in order. A container is only
+// removed once it is empty, so its contents are never orphaned.
+func (r *inventoryRepository) takeFromStacks(tx *sqlx.Tx, characterID, itemID string, stacks []inventoryStack, quantity int, now time.Time) error {
+	for _, stack := range stacks {
+		if quantity <= 0 {
+			break
+		}
+		if stack.Quantity > quantity {
+			query := `UPDATE character_inventory SET quantity = quantity - ?, updated_at = ? WHERE id = ?`
+			_, err := tx.Exec(r.db.Rebind(query), quantity, now, stack.ID)
+			return err
+		}
+
+		var contents int
+		query := `SELECT COUNT(*) FROM character_inventory WHERE container_id = ?`
+		if err := tx.Get(&contents, r.db.Rebind(query), stack.ID); err != nil {
+			return err
+		}
+		if contents > 0 {
+			return fmt.Errorf("item %s still holds other items; empty it first", itemID)
+		}
+		query = `DELETE FROM character_inventory WHERE id = ?`
+		if _, err := tx.Exec(r.db.Rebind(query), stack.ID); err != nil {
+			return err
+		}
+		if stack.Attuned {
+			query = `UPDATE characters SET attunement_slots_used = attunement_slots_used - 1 WHERE id = ?`
+			if _, err := tx.Exec(r.db.Rebind(query), characterID); err != nil {
+				return err
+			}
+		}
+		quantity -= stack.Quantity
+	}
+	return nil
+}
+
+// moveShopStock adds or removes a quantity of a shop's stock inside a transaction
+func (r *inventoryRepository) moveShopStock(tx *sqlx.Tx, movement models.ShopStockMovement, now time.Time) error {
+	if movement.Quantity > 0 {
+		query := `INSERT INTO shop_stock (shop_id, item_id, quantity, updated_at)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT (shop_id, item_id)
+			DO UPDATE SET
+				quantity = shop_stock.quantity + excluded.quantity,
+				updated_at = excluded.updated_at`
+		_, err := tx.Exec(r.db.Rebind(query), movement.ShopID, movement.ItemID, movement.Quantity, now)
+		return err
+	}
+
+	var stocked int
+	query := `SELECT quantity FROM shop_stock WHERE shop_id = ? AND item_id = ?` + r.forUpdate()
+	err := tx.Get(&stocked, r.db.Rebind(query), movement.ShopID, movement.ItemID)
+	if err == sql.ErrNoRows || (err == nil && stocked == 0) {
+		return fmt.Errorf("item %s is out of stock", movement.ItemID)
+	}
+	if err != nil {
+		return err
+	}
+	if stocked < -movement.Quantity {
+		return fmt.Errorf("only %d of item %s in stock", stocked, movement.ItemID)
+	}
+
+	query = `UPDATE shop_stock SET quantity = quantity - ?, updated_at = ? WHERE shop_id = ? AND item_id = ?`
+	_, err = tx.Exec(r.db.Rebind(query), -movement.Quantity, now, movement.ShopID, movement.ItemID)
+	return err
+}
+
+// lockPartyCurrency loads a party's purse for update, creating an empty one if needed
+func (r *inventoryRepository) lockPartyCurrency(tx *sqlx.Tx, partyID string, now time.Time) (*models.Currency, error) {
+	query := `INSERT INTO party_currency (party_id, created_at, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT (party_id) DO NOTHING`
+	if _, err := tx.Exec(r.db.Rebind(query), partyID, now, now); err != nil {
+		return nil, err
+	}
+
+	var currency models.Currency
+	query = `SELECT copper, silver, electrum, gold, platinum, created_at, updated_at
+		FROM party_currency WHERE party_id = ?` + r.forUpdate()
+	if err := tx.Get(&currency, r.db.Rebind(query), partyID); err != nil {
+		return nil, err
+	}
+	return &currency, nil
+}
+
+// movePartyStash adds or removes a quantity of an item in a party stash inside a transaction
+func (r *inventoryRepository) movePartyStash(tx *sqlx.Tx, movement models.PartyStashMovement, now time.Time) error {
+	if movement.Quantity > 0 {
+		query := `INSERT INTO party_stash (party_id, item_id, quantity, updated_at)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT (party_id, item_id)
+			DO UPDATE SET
+				quantity = party_stash.quantity + excluded.quantity,
+				updated_at = excluded.updated_at`
+		_, err := tx.Exec(r.db.Rebind(query), movement.PartyID, movement.ItemID, movement.Quantity, now)
+		return err
+	}
+
+	var held int
+	query := `SELECT quantity FROM party_stash WHERE party_id = ? AND item_id = ?` + r.forUpdate()
+	err := tx.Get(&held, r.db.Rebind(query), movement.PartyID, movement.ItemID)
+	if err == sql.ErrNoRows || (err == nil && held == 0) {
+		return fmt.Errorf("item %s is not in the party stash", movement.ItemID)
+	}
+	if err != nil {
+		return err
+	}
+
+	remove := -movement.Quantity
+	switch {
+	case held < remove:
+		return fmt.Errorf("only %d of item %s in the party stash", held, movement.ItemID)
+	case held == remove:
+		query = `DELETE FROM party_stash WHERE party_id = ? AND item_id = ?`
+		_, err = tx.Exec(r.db.Rebind(query), movement.PartyID, movement.ItemID)
+	default:
+		query = `UPDATE party_stash SET quantity = quantity - ?, updated_at = ? WHERE party_id = ? AND item_id = ?`
+		_, err = tx.Exec(r.db.Rebind(query), remove, now, movement.PartyID, movement.ItemID)
+	}
+	return err
+}
+
+// acceptTradeOffer marks a pending trade offer accepted by the transaction that carries it out
+func (r *inventoryRepository) acceptTradeOffer(tx *sqlx.Tx, txn *models.EconomyTransaction, now time.Time) error {
+	query := `UPDATE trade_offers SET status = ?, resolved_by = ?, transaction_id = ?, resolved_at = ?
+		WHERE id = ? AND status = ?`
+	result, err := tx.Exec(r.db.Rebind(query), models.TradeStatusAccepted, txn.CreatedBy, txn.ID, now,
+		txn.TradeOfferID, models.TradeStatusPending)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("trade offer is no longer pending")
+	}
+	return nil
+}
+
+// finishCraftingProject records the check and outcome of an in-progress project finished by the transaction
+func (r *inventoryRepository) finishCraftingProject(tx *sqlx.Tx, txn *models.EconomyTransaction, now time.Time) error {
+	project := txn.CraftingProject
+	if txn.CraftingDays > 0 {
+		if err := spendDowntime(tx, r.db, project.CharacterID, txn.CraftingDays, now); err != nil {
+			return err
+		}
+	}
+	query := `UPDATE crafting_projects SET status = ?, days_worked = ?, check_roll = ?, check_total = ?,
+			transaction_id = ?, completed_at = ?, updated_at = ?
+		WHERE id = ? AND status = ?`
+	result, err := tx.Exec(r.db.Rebind(query), project.Status, project.DaysWorked, project.CheckRoll,
+		project.CheckTotal, txn.ID, now, now, project.ID, models.CraftingStatusInProgress)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("crafting project is no longer in progress")
+	}
+	return nil
+}
+
+// distributeLootPool closes an open loot pool and records who ended up with each of its items
+func (r *inventoryRepository) distributeLootPool(tx *sqlx.Tx, txn *models.EconomyTransaction, now time.Time) error {
+	pool := txn.LootPool
+	query := `UPDATE loot_pools SET status = ?, transaction_id = ?, distributed_at = ?
+		WHERE id = ? AND status = ?`
+	result, err := tx.Exec(r.db.Rebind(query), models.LootPoolStatusDistributed, txn.ID, now, pool.ID,
+		models.LootPoolStatusOpen)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("loot pool has already been distributed")
+	}
+
+	for _, item := range pool.Items {
+		query := `UPDATE loot_pool_items SET assigned_to = ? WHERE id = ?`
+		if _, err := tx.Exec(r.db.Rebind(query), item.AssignedTo, item.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forUpdate locks selected rows on PostgreSQL; SQLite already serializes writers
+func (r *inventoryRepository) forUpdate() string {
+	if r.db.DriverName() == "postgres" {
+		return " FOR UPDATE"
+	}
+	return ""
+}
+
+func newLedgerEntry(txn *models.EconomyTransaction, characterID string, now time.Time) *models.LedgerEntry {
+	entry := &models.LedgerEntry{
+		ID:            uuid.New().String(),
+		TransactionID: txn.ID,
+		CharacterID:   characterID,
+		EntryType:     txn.Type,
+		Description:   txn.Description,
+		CreatedBy:     txn.CreatedBy,
+		CreatedAt:     now,
+	}
+	if txn.SessionID != "" {
+		sessionID := txn.SessionID
+		entry.SessionID = &sessionID
+	}
+	if txn.PartyID != "" {
+		partyID := txn.PartyID
+		entry.PartyID = &partyID
+	}
+	return entry
+}
+
+// transactionCharacterIDs lists every character a transaction touches in a stable order
+func transactionCharacterIDs(txn *models.EconomyTransaction) []string {
+	ids := itemCharacterIDs(txn)
+	for _, movement := range txn.Currency {
+		ids = append(ids, movement.CharacterID)
+	}
+	return uniqueSorted(ids)
+}
+
+func itemCharacterIDs(txn *models.EconomyTransaction) []string {
+	ids := make([]string, 0, len(txn.Items))
+	for _, movement := range txn.Items {
+		ids = append(ids, movement.CharacterID)
+	}
+	return uniqueSorted(ids)
+}
+
+func partyCurrencyIDs(txn *models.EconomyTransaction) []string {
+	ids := make([]string, 0, len(txn.PartyCurrency))
+	for _, movement := range txn.PartyCurrency {
+		ids = append(ids, movement.PartyID)
+	}
+	return uniqueSorted(ids)
+}
+
+// isUniqueViolation reports whether err is a unique or primary key conflict
+func isUniqueViolation(err error) bool {
+	errStr := err.Error()
+	return strings.Contains(errStr, "duplicate key") || strings.Contains(errStr, "UNIQUE constraint failed")
+}
+
+func uniqueSorted(ids []string) []string {
+	sort.Strings(ids)
+	return slices.Compact(ids)
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -106,28 +107,41 @@ func TestInventoryRepositoryGetItem(t *testing.T) {
 
 		propertiesJSON, _ := json.Marshal(expectedItem.Properties)
 
-		mock.ExpectQuery(`SELECT id, name, type, rarity, weight, value, properties, requires_attunement, attunement_requirements, description, created_at, updated_at FROM items WHERE id = \?`).
+		mock.ExpectQuery(`SELECT id, name, type, rarity, weight, value, properties, requires_attunement, attunement_requirements, description, source, session_id, created_at, updated_at FROM items WHERE id = \?`).
 			WithArgs(testutil.TestItemID).
 			WillReturnRows(sqlmock.NewRows([]string{
 				"id", "name", "type", "rarity", "weight", "value", "properties",
 				"requires_attunement", "attunement_requirements", "description",
-				"created_at", "updated_at",
+				"source", "session_id", "created_at", "updated_at",
 			}).AddRow(
 				expectedItem.ID, expectedItem.Name, expectedItem.Type, expectedItem.Rarity,
 				expectedItem.Weight, expectedItem.Value, propertiesJSON,
 				expectedItem.RequiresAttunement, expectedItem.AttunementRequirements,
-				expectedItem.Description, expectedItem.CreatedAt, expectedItem.UpdatedAt,
+				expectedItem.Description, models.ItemSourceCatalog, nil, expectedItem.CreatedAt, expectedItem.UpdatedAt,
 			))
 
 		item, err := repo.GetItem(testutil.TestItemID)
 		assert.NoError(t, err)
 		assert.NotNil(t, item)
 		assert.Equal(t, expectedItem.Name, item.Name)
+		assert.Equal(t, models.ItemSourceCatalog, item.Source)
+		assert.Nil(t, item.SessionID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("session lookups only see shared items and that session's homebrew", func(t *testing.T) {
+		mock.ExpectQuery(`FROM items WHERE id = \? AND \(session_id IS NULL OR session_id = \?\)`).
+			WithArgs("homebrew-1", "session-2").
+			WillReturnError(sql.ErrNoRows)
+
+		item, err := repo.GetSessionItem("homebrew-1", "session-2")
+		assert.NoError(t, err)
+		assert.Nil(t, item)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("item not found", func(t *testing.T) {
-		mock.ExpectQuery(`SELECT id, name, type, rarity, weight, value, properties, requires_attunement, attunement_requirements, description, created_at, updated_at FROM items WHERE id = \?`).
+		mock.ExpectQuery(`SELECT id, name, type, rarity, weight, value, properties, requires_attunement, attunement_requirements, description, source, session_id, created_at, updated_at FROM items WHERE id = \?`).
 			WithArgs("non-existent").
 			WillReturnError(sql.ErrNoRows)
 
@@ -159,11 +173,13 @@ func TestInventoryRepositoryAddItemToInventory(t *testing.T) {
 			sqlmock.AnyArg(), characterID, itemID, quantity, sqlmock.AnyArg(), sqlmock.AnyArg(),
 		).WillReturnResult(sqlmock.NewResult(1, 1))
 
-		// Expect the weight update query from updateCharacterWeight
-		// Need to match the full query including WHERE clause
-		mock.ExpectExec(
-			`UPDATE characters SET current_weight = \(\s*SELECT COALESCE\(SUM\(i\.weight \* ci\.quantity\), 0\)\s*FROM character_inventory ci\s*JOIN items i ON ci\.item_id = i\.id\s*WHERE ci\.character_id = \?\s*\)\s*WHERE id = \?`,
-		).WithArgs(characterID, characterID).WillReturnResult(sqlmock.NewResult(0, 1))
+		// Expect updateCharacterWeight to weigh the inventory and store the carried weight
+		mock.ExpectQuery(`SELECT ci\.id, ci\.container_id, ci\.location, ci\.quantity, i\.weight, i\.properties FROM character_inventory ci`).
+			WithArgs(characterID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "container_id", "location", "quantity", "weight", "properties"}).
+				AddRow("inv-1", nil, "carried", 1, 3.0, `{}`))
+		mock.ExpectExec(`UPDATE characters SET current_weight = \? WHERE id = \?`).
+			WithArgs(3.0, characterID).WillReturnResult(sqlmock.NewResult(0, 1))
 
 		err := repo.AddItemToInventory(characterID, itemID, quantity)
 		assert.NoError(t, err)
@@ -186,25 +202,29 @@ func TestInventoryRepositoryGetCharacterInventory(t *testing.T) {
 		// Mock the query that joins character_inventory with items
 		rows := sqlmock.NewRows([]string{
 			"id", "character_id", "item_id", "quantity", "equipped", "attuned",
-			"custom_properties", "notes", "created_at", "updated_at",
+			"custom_properties", "notes", "container_id", "location", "storage_note",
+			"charges", "curse_active", "created_at", "updated_at",
 			"item_id", "name", "type", "rarity", "weight", "value", "properties",
 			"requires_attunement", "attunement_requirements", "description",
 			"item_created_at", "item_updated_at",
 		}).AddRow(
 			"inv-1", characterID, "item-1", 1, false, false,
-			"{}", "", time.Now(), time.Now(),
+			"{}", "", nil, "carried", nil,
+			3, false, time.Now(), time.Now(),
 			"item-1", "Longsword", "weapon", "common", 3.0, 15, `{"damage":"1d8"}`,
 			false, "", "A standard longsword",
 			time.Now(), time.Now(),
 		)
 
-		mock.ExpectQuery(`SELECT ci\.id, ci\.character_id, ci\.item_id, ci\.quantity, ci\.equipped, ci\.attuned, ci\.custom_properties, ci\.notes, ci\.created_at, ci\.updated_at, i\.id, i\.name, i\.type, i\.rarity, i\.weight, i\.value, i\.properties, i\.requires_attunement, i\.attunement_requirements, i\.description, i\.created_at, i\.updated_at FROM character_inventory ci JOIN items i ON ci\.item_id = i\.id WHERE ci\.character_id = \? ORDER BY i\.name`).
+		mock.ExpectQuery(`SELECT ci\.id, ci\.character_id, ci\.item_id, ci\.quantity, ci\.equipped, ci\.attuned, ci\.custom_properties, ci\.notes, ci\.container_id, ci\.location, ci\.storage_note, ci\.charges, ci\.curse_active, ci\.created_at, ci\.updated_at, i\.id, i\.name, i\.type, i\.rarity, i\.weight, i\.value, i\.properties, i\.requires_attunement, i\.attunement_requirements, i\.description, i\.created_at, i\.updated_at FROM character_inventory ci JOIN items i ON ci\.item_id = i\.id WHERE ci\.character_id = \? ORDER BY i\.name`).
 			WithArgs(characterID).
 			WillReturnRows(rows)
 
 		items, err := repo.GetCharacterInventory(characterID)
 		assert.NoError(t, err)
-		assert.Len(t, items, 1)
+		require.Len(t, items, 1)
+		require.NotNil(t, items[0].Charges)
+		assert.Equal(t, 3, *items[0].Charges)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
@@ -269,3 +289,456 @@ func TestInventoryRepositoryGetCharacterCurrency(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
+
+func TestInventoryRepositoryApplyTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	// The postgres driver name turns on row locking
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	dbWrapper := &DB{DB: sqlxDB}
+	repo := NewInventoryRepository(dbWrapper)
+
+	characterID := testutil.TestCharacterID
+	currencyColumns := []string{"character_id", "copper", "silver", "electrum", "gold", "platinum", "created_at", "updated_at"}
+	purchase := func() *models.EconomyTransaction {
+		return &models.EconomyTransaction{
+			Type:        models.LedgerEntryPurchase,
+			Description: "purchased 3 x Healing Potion",
+			Currency:    []models.CurrencyMovement{{CharacterID: characterID, Coins: models.Coins{Copper: -150}}},
+			Items:       []models.ItemMovement{{CharacterID: characterID, ItemID: testutil.TestItemID, Quantity: 3}},
+		}
+	}
+
+	t.Run("purchase moves coins and items and writes the ledger", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO character_currency .* ON CONFLICT \(character_id\) DO NOTHING`).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT .* FROM character_currency WHERE character_id = \$1 FOR UPDATE`).
+			WithArgs(characterID).
+			WillReturnRows(sqlmock.NewRows(currencyColumns).AddRow(characterID, 0, 0, 0, 2, 0, time.Now(), time.Now()))
+		mock.ExpectExec(`INSERT INTO character_inventory`).
+			WithArgs(sqlmock.AnyArg(), characterID, testutil.TestItemID, 3, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(`UPDATE character_currency`).
+			WithArgs(0, 0, 1, 0, 0, sqlmock.AnyArg(), characterID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`SELECT ci\.id, ci\.container_id, ci\.location`).
+			WithArgs(characterID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "container_id", "location", "quantity", "weight", "properties"}).
+				AddRow("inv-1", nil, "carried", 3, 0.5, `{}`))
+		mock.ExpectExec(`UPDATE characters SET current_weight = \$1 WHERE id = \$2`).
+			WithArgs(1.5, characterID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO economy_ledger`).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), characterID, models.LedgerEntryPurchase, nil, 0, -150, 50,
+				sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(`INSERT INTO economy_ledger`).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), characterID, models.LedgerEntryPurchase, sqlmock.AnyArg(), 3, 0, 50,
+				sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		txn := purchase()
+		entries, err := repo.ApplyTransaction(txn)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.NotEmpty(t, txn.ID)
+		assert.Equal(t, txn.ID, entries[1].TransactionID)
+		assert.Equal(t, 50, entries[1].BalanceAfter)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("insufficient funds rolls back", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO character_currency`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT .* FROM character_currency WHERE character_id = \$1 FOR UPDATE`).
+			WithArgs(characterID).
+			WillReturnRows(sqlmock.NewRows(currencyColumns).AddRow(characterID, 20, 0, 0, 0, 0, time.Now(), time.Now()))
+		mock.ExpectRollback()
+
+		_, err := repo.ApplyTransaction(purchase())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "insufficient funds")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("removing more items than held rolls back", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO character_currency`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT .* FROM character_currency`).
+			WillReturnRows(sqlmock.NewRows(currencyColumns).AddRow(characterID, 0, 0, 0, 0, 0, time.Now(), time.Now()))
+		mock.ExpectQuery(`SELECT id, quantity, attuned FROM character_inventory .* FOR UPDATE`).
+			WithArgs(characterID, testutil.TestItemID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "quantity", "attuned"}).AddRow("inv-1", 1, false))
+		mock.ExpectRollback()
+
+		_, err := repo.ApplyTransaction(&models.EconomyTransaction{
+			Type:  models.LedgerEntrySale,
+			Items: []models.ItemMovement{{CharacterID: characterID, ItemID: testutil.TestItemID, Quantity: -2}},
+		})
+		require.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("buying more than the shop stocks rolls back", func(t *testing.T) {
+		shopID := uuid.New()
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO character_currency`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT .* FROM character_currency`).
+			WillReturnRows(sqlmock.NewRows(currencyColumns).AddRow(characterID, 0, 0, 0, 2, 0, time.Now(), time.Now()))
+		mock.ExpectExec(`INSERT INTO character_inventory`).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery(`SELECT quantity FROM shop_stock .* FOR UPDATE`).
+			WithArgs(shopID, testutil.TestItemID).
+			WillReturnRows(sqlmock.NewRows([]string{"quantity"}).AddRow(1))
+		mock.ExpectRollback()
+
+		txn := purchase()
+		txn.ShopStock = []models.ShopStockMovement{{ShopID: shopID, ItemID: testutil.TestItemID, Quantity: -3}}
+		_, err := repo.ApplyTransaction(txn)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only 1")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("stash deposit moves coins into the party purse and tags the ledger", func(t *testing.T) {
+		partyID := uuid.New().String()
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO character_currency`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT .* FROM character_currency`).
+			WillReturnRows(sqlmock.NewRows(currencyColumns).AddRow(characterID, 0, 0, 0, 5, 0, time.Now(), time.Now()))
+		mock.ExpectExec(`INSERT INTO party_currency .* ON CONFLICT \(party_id\) DO NOTHING`).
+			WithArgs(partyID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`SELECT copper, silver, electrum, gold, platinum, created_at, updated_at FROM party_currency WHERE party_id = \$1 FOR UPDATE`).
+			WithArgs(partyID).
+			WillReturnRows(sqlmock.NewRows([]string{"copper", "silver", "electrum", "gold", "platinum", "created_at", "updated_at"}).
+				AddRow(0, 0, 0, 1, 0, time.Now(), time.Now()))
+		mock.ExpectExec(`UPDATE character_currency`).
+			WithArgs(0, 0, 0, 3, 0, sqlmock.AnyArg(), characterID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE party_currency`).
+			WithArgs(0, 0, 0, 3, 0, sqlmock.AnyArg(), partyID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO economy_ledger`).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), characterID, models.LedgerEntryStash, nil, 0, -200, 300,
+				sqlmock.AnyArg(), sqlmock.AnyArg(), nil, partyID, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		entries, err := repo.ApplyTransaction(&models.EconomyTransaction{
+			Type:          models.LedgerEntryStash,
+			PartyID:       partyID,
+			Currency:      []models.CurrencyMovement{{CharacterID: characterID, Coins: models.Coins{Gold: -2}}},
+			PartyCurrency: []models.PartyCurrencyMovement{{PartyID: partyID, Coins: models.Coins{Gold: 2}}},
+		})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, partyID, *entries[0].PartyID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("taking more from the party stash than it holds rolls back", func(t *testing.T) {
+		partyID := uuid.New().String()
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO character_currency`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT .* FROM character_currency`).
+			WillReturnRows(sqlmock.NewRows(currencyColumns).AddRow(characterID, 0, 0, 0, 0, 0, time.Now(), time.Now()))
+		mock.ExpectExec(`INSERT INTO character_inventory`).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery(`SELECT quantity FROM party_stash .* FOR UPDATE`).
+			WithArgs(partyID, testutil.TestItemID).
+			WillReturnRows(sqlmock.NewRows([]string{"quantity"}).AddRow(2))
+		mock.ExpectRollback()
+
+		_, err := repo.ApplyTransaction(&models.EconomyTransaction{
+			Type:       models.LedgerEntryStash,
+			Items:      []models.ItemMovement{{CharacterID: characterID, ItemID: testutil.TestItemID, Quantity: 3}},
+			PartyStash: []models.PartyStashMovement{{PartyID: partyID, ItemID: testutil.TestItemID, Quantity: -3}},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only 2")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("accepting an offer that is no longer pending rolls back", func(t *testing.T) {
+		otherID := uuid.New().String()
+		mock.ExpectBegin()
+		for range 2 {
+			mock.ExpectExec(`INSERT INTO character_currency`).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery(`SELECT .* FROM character_currency`).
+				WillReturnRows(sqlmock.NewRows(currencyColumns).AddRow(characterID, 0, 0, 0, 5, 0, time.Now(), time.Now()))
+		}
+		mock.ExpectExec(`UPDATE character_currency`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE character_currency`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE trade_offers SET status = \$1.* WHERE id = \$5 AND status = \$6`).
+			WithArgs(models.TradeStatusAccepted, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "offer-1", models.TradeStatusPending).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		_, err := repo.ApplyTransaction(&models.EconomyTransaction{
+			Type: models.LedgerEntryTransfer,
+			Currency: []models.CurrencyMovement{
+				{CharacterID: characterID, Coins: models.Coins{Gold: -1}},
+				{CharacterID: otherID, Coins: models.Coins{Gold: 1}},
+			},
+			TradeOfferID: "offer-1",
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no longer pending")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("finishing a crafting project twice rolls back", func(t *testing.T) {
+		roll, total := 14, 16
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO character_currency`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT .* FROM character_currency`).
+			WillReturnRows(sqlmock.NewRows(currencyColumns).AddRow(characterID, 0, 0, 0, 20, 0, time.Now(), time.Now()))
+		mock.ExpectExec(`UPDATE character_currency`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE crafting_projects SET status = \$1.* WHERE id = \$8 AND status = \$9`).
+			WithArgs(models.CraftingStatusCompleted, 1, &roll, &total, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+				"project-1", models.CraftingStatusInProgress).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		_, err := repo.ApplyTransaction(&models.EconomyTransaction{
+			Type:     models.LedgerEntryCraft,
+			Currency: []models.CurrencyMovement{{CharacterID: characterID, Coins: models.Coins{Gold: -15}}},
+			CraftingProject: &models.CraftingProject{
+				ID:         "project-1",
+				Status:     models.CraftingStatusCompleted,
+				DaysWorked: 1,
+				CheckRoll:  &roll,
+				CheckTotal: &total,
+			},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no longer in progress")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("loot closes the pool", func(t *testing.T) {
+		assignedTo := characterID
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO character_currency`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT .* FROM character_currency`).
+			WillReturnRows(sqlmock.NewRows(currencyColumns).AddRow(characterID, 0, 0, 0, 0, 0, time.Now(), time.Now()))
+		mock.ExpectExec(`UPDATE character_currency`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE loot_pools SET status = \$1.* WHERE id = \$4 AND status = \$5`).
+			WithArgs(models.LootPoolStatusDistributed, sqlmock.AnyArg(), sqlmock.AnyArg(), "pool-1", models.LootPoolStatusOpen).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE loot_pool_items SET assigned_to = \$1 WHERE id = \$2`).
+			WithArgs(&assignedTo, "pool-item-1").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO economy_ledger`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		entries, err := repo.ApplyTransaction(&models.EconomyTransaction{
+			Type:     models.LedgerEntryLoot,
+			Currency: []models.CurrencyMovement{{CharacterID: characterID, Coins: models.Coins{Gold: 5}}},
+			LootPool: &models.LootPool{
+				ID:    "pool-1",
+				Items: []*models.LootPoolItem{{ID: "pool-item-1", AssignedTo: &assignedTo}},
+			},
+		})
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("distributing a loot pool twice rolls back", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO character_currency`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT .* FROM character_currency`).
+			WillReturnRows(sqlmock.NewRows(currencyColumns).AddRow(characterID, 0, 0, 0, 0, 0, time.Now(), time.Now()))
+		mock.ExpectExec(`UPDATE character_currency`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE loot_pools SET status`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		_, err := repo.ApplyTransaction(&models.EconomyTransaction{
+			Type:     models.LedgerEntryLoot,
+			Currency: []models.CurrencyMovement{{CharacterID: characterID, Coins: models.Coins{Gold: 5}}},
+			LootPool: &models.LootPool{ID: "pool-1"},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already been distributed")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("an imported character is created with its purse or not at all", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO characters .* RETURNING id, created_at, updated_at`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("imported-1", time.Now(), time.Now()))
+		mock.ExpectExec(`INSERT INTO character_currency`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT .* FROM character_currency`).
+			WillReturnRows(sqlmock.NewRows(currencyColumns).AddRow("imported-1", 0, 0, 0, 0, 0, time.Now(), time.Now()))
+		mock.ExpectRollback()
+
+		_, err := repo.ApplyTransaction(&models.EconomyTransaction{
+			Type:         models.LedgerEntryAdjustment,
+			NewCharacter: &models.Character{ID: "imported-1", Name: "Imported"},
+			Currency:     []models.CurrencyMovement{{CharacterID: "imported-1", Coins: models.Coins{Gold: -5}}},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "insufficient funds")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestInventoryRepositoryGetCharacterWeight(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	dbWrapper := &DB{DB: sqlxDB}
+	repo := NewInventoryRepository(dbWrapper)
+
+	characterID := testutil.TestCharacterID
+	mock.ExpectQuery(`SELECT ci\.id, ci\.container_id, ci\.location, ci\.quantity, i\.weight, i\.properties`).
+		WithArgs(characterID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "container_id", "location", "quantity", "weight", "properties"}).
+			AddRow("pack", nil, "carried", 1, 5.0, `{"container": true, "capacity_weight": 30}`).
+			AddRow("rope", "pack", "carried", 1, 10.0, `{}`).
+			AddRow("bag", "pack", "carried", 1, 15.0, `{"container": true, "extradimensional": true}`).
+			AddRow("ingots", "bag", "carried", 10, 10.0, `{}`).
+			AddRow("saddlebags", nil, "mount", 1, 8.0, `{"container": true}`).
+			AddRow("tent", "saddlebags", "mount", 1, 20.0, `{}`).
+			AddRow("chest", nil, "stored", 1, 25.0, `{"container": true}`))
+	mock.ExpectQuery(`SELECT carry_capacity FROM characters WHERE id = \?`).
+		WithArgs(characterID).
+		WillReturnRows(sqlmock.NewRows([]string{"carry_capacity"}).AddRow(150.0))
+
+	weight, err := repo.GetCharacterWeight(characterID)
+	require.NoError(t, err)
+	// The bag of holding weighs 15 lb however many ingots are inside it
+	assert.InDelta(t, 30.0, weight.CurrentWeight, 0.001)
+	assert.InDelta(t, 28.0, weight.MountWeight, 0.001)
+	assert.InDelta(t, 25.0, weight.StoredWeight, 0.001)
+	assert.False(t, weight.Encumbered)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInventoryRepositoryMoveInventoryItem(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	dbWrapper := &DB{DB: sqlxDB}
+	repo := NewInventoryRepository(dbWrapper)
+
+	characterID := testutil.TestCharacterID
+
+	expectWeigh := func() {
+		mock.ExpectQuery(`SELECT ci\.id, ci\.container_id, ci\.location`).
+			WithArgs(characterID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "container_id", "location", "quantity", "weight", "properties"}))
+		mock.ExpectExec(`UPDATE characters SET current_weight = \? WHERE id = \?`).
+			WithArgs(0.0, characterID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	stackColumns := []string{"id", "quantity", "attuned"}
+
+	t.Run("stores a container with its contents", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id, quantity, attuned FROM character_inventory\s+WHERE id = \? AND character_id = \?`).
+			WithArgs("inv-backpack", characterID).
+			WillReturnRows(sqlmock.NewRows(stackColumns).AddRow("inv-backpack", 1, false))
+		mock.ExpectQuery(`SELECT id FROM character_inventory\s+WHERE character_id = \? AND item_id = \? AND id <> \? AND container_id IS NULL`).
+			WithArgs(characterID, "backpack", "inv-backpack").
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectExec(`UPDATE character_inventory\s+SET container_id = \?, location = \?, storage_note = \?`).
+			WithArgs(nil, models.ItemLocationStored, "at the Yawning Portal", sqlmock.AnyArg(), "inv-backpack").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE character_inventory\s+SET location = \?, storage_note = \?, updated_at = \?\s+WHERE character_id = \? AND id IN \(\?, \?\)`).
+			WithArgs(models.ItemLocationStored, "at the Yawning Portal", sqlmock.AnyArg(), characterID, "inv-rope", "inv-torch").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		expectWeigh()
+		mock.ExpectCommit()
+
+		err := repo.MoveInventoryItem(characterID, &models.InventoryMove{
+			StackID:     "inv-backpack",
+			ItemID:      "backpack",
+			Location:    models.ItemLocationStored,
+			StorageNote: "at the Yawning Portal",
+			Contents:    []string{"inv-rope", "inv-torch"},
+		})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("splits part of a stack into a container", func(t *testing.T) {
+		backpack := "inv-backpack"
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id, quantity, attuned FROM character_inventory`).
+			WithArgs("inv-torch", characterID).
+			WillReturnRows(sqlmock.NewRows(stackColumns).AddRow("inv-torch", 10, false))
+		mock.ExpectQuery(`SELECT id FROM character_inventory\s+WHERE .* AND container_id = \?`).
+			WithArgs(characterID, "torch", "inv-torch", backpack).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectExec(`UPDATE character_inventory SET quantity = quantity - \?`).
+			WithArgs(4, sqlmock.AnyArg(), "inv-torch").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO character_inventory \(id, character_id, item_id, quantity, container_id`).
+			WithArgs(sqlmock.AnyArg(), characterID, "torch", 4, &backpack, models.ItemLocationCarried, nil,
+				sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectWeigh()
+		mock.ExpectCommit()
+
+		err := repo.MoveInventoryItem(characterID, &models.InventoryMove{
+			StackID:     "inv-torch",
+			ItemID:      "torch",
+			Quantity:    4,
+			ContainerID: &backpack,
+			Location:    models.ItemLocationCarried,
+		})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("merges into the stack already in the container", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id, quantity, attuned FROM character_inventory`).
+			WithArgs("inv-packed-torch", characterID).
+			WillReturnRows(sqlmock.NewRows(stackColumns).AddRow("inv-packed-torch", 4, false))
+		mock.ExpectQuery(`SELECT id FROM character_inventory\s+WHERE .* AND container_id IS NULL`).
+			WithArgs(characterID, "torch", "inv-packed-torch").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("inv-torch"))
+		mock.ExpectExec(`UPDATE character_inventory SET quantity = quantity \+ \?`).
+			WithArgs(4, sqlmock.AnyArg(), "inv-torch").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM character_inventory WHERE container_id = \?`).
+			WithArgs("inv-packed-torch").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec(`DELETE FROM character_inventory WHERE id = \?`).
+			WithArgs("inv-packed-torch").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectWeigh()
+		mock.ExpectCommit()
+
+		err := repo.MoveInventoryItem(characterID, &models.InventoryMove{
+			StackID:  "inv-packed-torch",
+			ItemID:   "torch",
+			Location: models.ItemLocationCarried,
+		})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("missing item rolls back", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id, quantity, attuned FROM character_inventory`).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		err := repo.MoveInventoryItem(characterID, &models.InventoryMove{
+			StackID:  "inv-backpack",
+			ItemID:   "backpack",
+			Location: models.ItemLocationCarried,
+		})
+		require.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,215 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+// ItemLibraryRepository defines the interface for syncing the item catalog into the items table
+// and for the homebrew items a DM makes for one game session
+type ItemLibraryRepository interface {
+	LatestCatalogVersion(ctx context.Context) (string, error)
+	SyncCatalog(ctx context.Context, version string, items []*models.Item) error
+	ListItems(ctx context.Context, filter models.ItemFilter) ([]*models.Item, error)
+	GetItem(ctx context.Context, id string) (*models.Item, error)
+
+	CreateHomebrewItems(ctx context.Context, items []*models.Item) error
+	UpdateHomebrewItem(ctx context.Context, item *models.Item) error
+	DeleteHomebrewItem(ctx context.Context, sessionID, id string) error
+}
+
+// itemLibraryRepository implements ItemLibraryRepository
+type itemLibraryRepository struct {
+	db *DB
+}
+
+// NewItemLibraryRepository creates a new item library repository
+func NewItemLibraryRepository(db *DB) ItemLibraryRepository {
+	return &itemLibraryRepository{db: db}
+}
+
+const itemLibraryColumns = `id, name, type, rarity, weight, value, properties, requires_attunement,
+	COALESCE(attunement_requirements, '') AS attunement_requirements, COALESCE(description, '') AS description,
+	source, session_id, created_at, updated_at`
+
+// LatestCatalogVersion returns the version of data/items most recently synced, or "" if none has been
+func (r *itemLibraryRepository) LatestCatalogVersion(ctx context.Context) (string, error) {
+	query := `SELECT version FROM item_catalog_versions ORDER BY synced_at DESC LIMIT 1`
+
+	var version string
+	err := r.db.GetContext(ctx, &version, query)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get item catalog version: %w", err)
+	}
+	return version, nil
+}
+
+// SyncCatalog inserts or updates every catalog item and records the version, all in one transaction.
+// Rows keep their IDs, so inventories holding a catalog item pick up the new definition.
+func (r *itemLibraryRepository) SyncCatalog(ctx context.Context, version string, items []*models.Item) error {
+	now := time.Now()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := r.db.Rebind(`INSERT INTO items (id, name, type, rarity, weight, value, properties,
+			requires_attunement, attunement_requirements, description, source, catalog_version, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET name = excluded.name, type = excluded.type, rarity = excluded.rarity,
+			weight = excluded.weight, value = excluded.value, properties = excluded.properties,
+			requires_attunement = excluded.requires_attunement,
+			attunement_requirements = excluded.attunement_requirements, description = excluded.description,
+			source = excluded.source, catalog_version = excluded.catalog_version, updated_at = excluded.updated_at`)
+	for _, item := range items {
+		if _, err := tx.ExecContext(ctx, query, item.ID, item.Name, item.Type, item.Rarity, item.Weight,
+			item.Value, item.Properties, item.RequiresAttunement, item.AttunementRequirements, item.Description,
+			models.ItemSourceCatalog, version, now, now); err != nil {
+			return fmt.Errorf("failed to sync item %s: %w", item.ID, err)
+		}
+	}
+
+	versionQuery := `INSERT INTO item_catalog_versions (version, item_count, synced_at) VALUES (?, ?, ?)
+		ON CONFLICT (version) DO UPDATE SET item_count = excluded.item_count, synced_at = excluded.synced_at`
+	if _, err := tx.ExecContext(ctx, r.db.Rebind(versionQuery), version, len(items), now); err != nil {
+		return fmt.Errorf("failed to record item catalog version: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ListItems returns the items a game session can see, by name: every shared item plus,
+// when filter.SessionID is set, that session's homebrew
+func (r *itemLibraryRepository) ListItems(ctx context.Context, filter models.ItemFilter) ([]*models.Item, error) {
+	query := `SELECT ` + itemLibraryColumns + ` FROM items WHERE `
+	args := []interface{}{}
+	if filter.SessionID != "" {
+		query += `(session_id IS NULL OR session_id = ?)`
+		args = append(args, filter.SessionID)
+	} else {
+		query += `session_id IS NULL`
+	}
+	if filter.Type != "" {
+		query += ` AND type = ?`
+		args = append(args, filter.Type)
+	}
+	if filter.Source != "" {
+		query += ` AND source = ?`
+		args = append(args, filter.Source)
+	}
+	if filter.Search != "" {
+		query += ` AND LOWER(name) LIKE ?`
+		args = append(args, "%"+strings.ToLower(filter.Search)+"%")
+	}
+	query += ` ORDER BY name`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	items := make([]*models.Item, 0)
+	if err := r.db.SelectContext(ctx, &items, r.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to list items: %w", err)
+	}
+	return items, nil
+}
+
+// GetItem returns an item with its source and session, or nil if it does not exist
+func (r *itemLibraryRepository) GetItem(ctx context.Context, id string) (*models.Item, error) {
+	query := `SELECT ` + itemLibraryColumns + ` FROM items WHERE id = ?`
+
+	var item models.Item
+	err := r.db.GetContext(ctx, &item, r.db.Rebind(query), id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item: %w", err)
+	}
+	return &item, nil
+}
+
+// CreateHomebrewItems stores a batch of homebrew items for one game session; either all are stored or none
+func (r *itemLibraryRepository) CreateHomebrewItems(ctx context.Context, items []*models.Item) error {
+	now := time.Now()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := r.db.Rebind(`INSERT INTO items (id, name, type, rarity, weight, value, properties,
+			requires_attunement, attunement_requirements, description, source, session_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	for _, item := range items {
+		if item.SessionID == nil {
+			return fmt.Errorf("homebrew items belong to a game session")
+		}
+		if item.ID == "" {
+			item.ID = uuid.New().String()
+		}
+		item.Source = models.ItemSourceHomebrew
+		item.CreatedAt = now
+		item.UpdatedAt = now
+		if _, err := tx.ExecContext(ctx, query, item.ID, item.Name, item.Type, item.Rarity, item.Weight,
+			item.Value, item.Properties, item.RequiresAttunement, item.AttunementRequirements, item.Description,
+			item.Source, *item.SessionID, now, now); err != nil {
+			return fmt.Errorf("failed to create homebrew item %s: %w", item.Name, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UpdateHomebrewItem changes a homebrew item of the item's session
+func (r *itemLibraryRepository) UpdateHomebrewItem(ctx context.Context, item *models.Item) error {
+	if item.SessionID == nil {
+		return fmt.Errorf("homebrew items belong to a game session")
+	}
+	item.UpdatedAt = time.Now()
+
+	query := `UPDATE items SET name = ?, type = ?, rarity = ?, weight = ?, value = ?, properties = ?,
+			requires_attunement = ?, attunement_requirements = ?, description = ?, updated_at = ?
+		WHERE id = ? AND session_id = ? AND source = ?`
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), item.Name, item.Type, item.Rarity, item.Weight,
+		item.Value, item.Properties, item.RequiresAttunement, item.AttunementRequirements, item.Description,
+		item.UpdatedAt, item.ID, *item.SessionID, models.ItemSourceHomebrew)
+	if err != nil {
+		return fmt.Errorf("failed to update homebrew item: %w", err)
+	}
+	return requireHomebrewRow(result)
+}
+
+// DeleteHomebrewItem removes a homebrew item no character is carrying
+func (r *itemLibraryRepository) DeleteHomebrewItem(ctx context.Context, sessionID, id string) error {
+	query := `DELETE FROM items WHERE id = ? AND session_id = ? AND source = ?`
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), id, sessionID, models.ItemSourceHomebrew)
+	if err != nil {
+		return fmt.Errorf("failed to delete homebrew item, it may still be carried: %w", err)
+	}
+	return requireHomebrewRow(result)
+}
+
+func requireHomebrewRow(result sql.Result) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("homebrew item not found")
+	}
+	return nil
+}
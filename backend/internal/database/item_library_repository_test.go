@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+func TestItemLibraryRepository(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	repo := NewItemLibraryRepository(&DB{DB: sqlx.NewDb(db, "sqlmock")})
+	ctx := context.Background()
+	itemColumns := []string{"id", "name", "type", "rarity", "weight", "value", "properties", "requires_attunement",
+		"attunement_requirements", "description", "source", "session_id", "created_at", "updated_at"}
+
+	t.Run("a session sees shared items and its own homebrew", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT .* FROM items WHERE \(session_id IS NULL OR session_id = \?\) AND type = \? ORDER BY name`).
+			WithArgs("session-1", models.ItemTypeWeapon).
+			WillReturnRows(sqlmock.NewRows(itemColumns).
+				AddRow("longsword", "Longsword", "weapon", "common", 3.0, 1500, `{"damage":"1d8"}`, false, "", "",
+					"catalog", nil, time.Now(), time.Now()).
+				AddRow("item-1", "Sunblade Shard", "weapon", "rare", 1.0, 0, `{}`, false, "", "",
+					"homebrew", "session-1", time.Now(), time.Now()))
+
+		items, err := repo.ListItems(ctx, models.ItemFilter{SessionID: "session-1", Type: models.ItemTypeWeapon})
+
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		assert.Nil(t, items[0].SessionID)
+		assert.Equal(t, "1d8", items[0].Properties["damage"])
+		assert.Equal(t, models.ItemSourceHomebrew, items[1].Source)
+		assert.Equal(t, "session-1", *items[1].SessionID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("without a session only shared items are listed", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT .* FROM items WHERE session_id IS NULL ORDER BY name`).
+			WillReturnRows(sqlmock.NewRows(itemColumns))
+
+		items, err := repo.ListItems(ctx, models.ItemFilter{})
+
+		require.NoError(t, err)
+		assert.Empty(t, items)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("sync upserts items and records the version together", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO items .* ON CONFLICT \(id\) DO UPDATE`).
+			WithArgs("longsword", "Longsword", models.ItemTypeWeapon, models.ItemRarityCommon, 3.0, 1500,
+				sqlmock.AnyArg(), false, "", "", models.ItemSourceCatalog, "v2", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO item_catalog_versions`).
+			WithArgs("v2", 1, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.SyncCatalog(ctx, "v2", []*models.Item{{
+			ID: "longsword", Name: "Longsword", Type: models.ItemTypeWeapon, Rarity: models.ItemRarityCommon,
+			Weight: 3.0, Value: 1500, Properties: models.ItemProperties{"damage": "1d8"},
+		}})
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("homebrew of another session is not deleted", func(t *testing.T) {
+		mock.ExpectExec(`DELETE FROM items WHERE id = \? AND session_id = \? AND source = \?`).
+			WithArgs("item-1", "session-2", models.ItemSourceHomebrew).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.DeleteHomebrewItem(ctx, "session-2", "item-1")
+
+		assert.EqualError(t, err, "homebrew item not found")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
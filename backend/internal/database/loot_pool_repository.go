@@ -0,0 +1,321 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+// LootPoolRepository defines the interface for loot pool operations.
+// Distributing a pool goes through InventoryRepository.ApplyTransaction.
+type LootPoolRepository interface {
+	CreatePool(ctx context.Context, pool *models.LootPool) error
+	GetPool(ctx context.Context, id string) (*models.LootPool, error)
+	ListPools(ctx context.Context, sessionID string, status models.LootPoolStatus) ([]*models.LootPool, error)
+	AssignItem(ctx context.Context, poolItemID, characterID string) error
+	ClaimItem(ctx context.Context, poolItemID, characterID string) error
+	RecordRoll(ctx context.Context, roll *models.LootRoll) error
+}
+
+// lootPoolRepository implements LootPoolRepository
+type lootPoolRepository struct {
+	db *DB
+}
+
+// NewLootPoolRepository creates a new loot pool repository
+func NewLootPoolRepository(db *DB) LootPoolRepository {
+	return &lootPoolRepository{db: db}
+}
+
+const lootPoolColumns = `id, session_id, source_type, source_id, description, mode, status, coins,
+	experience, character_ids, transaction_id, created_by, created_at, distributed_at`
+
+// lootPoolRow is a loot pool as stored, with its coins and characters as JSON
+type lootPoolRow struct {
+	ID            string         `db:"id"`
+	SessionID     string         `db:"session_id"`
+	SourceType    string         `db:"source_type"`
+	SourceID      string         `db:"source_id"`
+	Description   string         `db:"description"`
+	Mode          string         `db:"mode"`
+	Status        string         `db:"status"`
+	Coins         []byte         `db:"coins"`
+	Experience    int            `db:"experience"`
+	CharacterIDs  []byte         `db:"character_ids"`
+	TransactionID sql.NullString `db:"transaction_id"`
+	CreatedBy     string         `db:"created_by"`
+	CreatedAt     time.Time      `db:"created_at"`
+	DistributedAt sql.NullTime   `db:"distributed_at"`
+}
+
+func (row *lootPoolRow) toModel() (*models.LootPool, error) {
+	pool := &models.LootPool{
+		ID:          row.ID,
+		SessionID:   row.SessionID,
+		SourceType:  models.LootSourceType(row.SourceType),
+		SourceID:    row.SourceID,
+		Description: row.Description,
+		Mode:        models.LootDistributionMode(row.Mode),
+		Status:      models.LootPoolStatus(row.Status),
+		Experience:  row.Experience,
+		CreatedBy:   row.CreatedBy,
+		CreatedAt:   row.CreatedAt,
+		Items:       []*models.LootPoolItem{},
+	}
+	if row.TransactionID.Valid {
+		pool.TransactionID = &row.TransactionID.String
+	}
+	if row.DistributedAt.Valid {
+		pool.DistributedAt = &row.DistributedAt.Time
+	}
+	if err := json.Unmarshal(row.Coins, &pool.Coins); err != nil {
+		return nil, fmt.Errorf("failed to decode loot pool coins: %w", err)
+	}
+	if err := json.Unmarshal(row.CharacterIDs, &pool.CharacterIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode loot pool characters: %w", err)
+	}
+	return pool, nil
+}
+
+// lootPoolItemRow is a pool item joined to the item it holds
+type lootPoolItemRow struct {
+	ID         string         `db:"id"`
+	PoolID     string         `db:"pool_id"`
+	ItemID     string         `db:"item_id"`
+	Name       string         `db:"name"`
+	Rarity     string         `db:"rarity"`
+	Quantity   int            `db:"quantity"`
+	AssignedTo sql.NullString `db:"assigned_to"`
+}
+
+// CreatePool stores a loot pool and its items
+func (r *lootPoolRepository) CreatePool(ctx context.Context, pool *models.LootPool) error {
+	if pool.ID == "" {
+		pool.ID = uuid.New().String()
+	}
+	pool.CreatedAt = time.Now()
+	if pool.Status == "" {
+		pool.Status = models.LootPoolStatusOpen
+	}
+	if pool.Mode == "" {
+		pool.Mode = models.LootModeDM
+	}
+	if pool.SourceType == "" {
+		pool.SourceType = models.LootSourceManual
+	}
+
+	coins, err := json.Marshal(pool.Coins)
+	if err != nil {
+		return err
+	}
+	characterIDs := pool.CharacterIDs
+	if characterIDs == nil {
+		characterIDs = []string{}
+	}
+	characters, err := json.Marshal(characterIDs)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `INSERT INTO loot_pools (id, session_id, source_type, source_id, description, mode, status,
+			coins, experience, character_ids, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	if _, err := tx.ExecContext(ctx, r.db.Rebind(query), pool.ID, pool.SessionID, pool.SourceType, pool.SourceID,
+		pool.Description, pool.Mode, pool.Status, coins, pool.Experience, characters, pool.CreatedBy,
+		pool.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create loot pool: %w", err)
+	}
+
+	for position, item := range pool.Items {
+		if item.ID == "" {
+			item.ID = uuid.New().String()
+		}
+		item.PoolID = pool.ID
+		query := `INSERT INTO loot_pool_items (id, pool_id, item_id, quantity, assigned_to, position)
+			VALUES (?, ?, ?, ?, ?, ?)`
+		if _, err := tx.ExecContext(ctx, r.db.Rebind(query), item.ID, pool.ID, item.ItemID, item.Quantity,
+			item.AssignedTo, position); err != nil {
+			return fmt.Errorf("failed to add loot pool item: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetPool returns a loot pool with its items and need/greed rolls, or nil if it does not exist
+func (r *lootPoolRepository) GetPool(ctx context.Context, id string) (*models.LootPool, error) {
+	query := `SELECT ` + lootPoolColumns + ` FROM loot_pools WHERE id = ?`
+
+	var row lootPoolRow
+	err := r.db.GetContext(ctx, &row, r.db.Rebind(query), id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loot pool: %w", err)
+	}
+
+	pool, err := row.toModel()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadItems(ctx, pool); err != nil {
+		return nil, err
+	}
+	return pool, nil
+}
+
+// ListPools returns a session's loot pools, newest first.
+// An empty status lists pools in every status.
+func (r *lootPoolRepository) ListPools(ctx context.Context, sessionID string, status models.LootPoolStatus) ([]*models.LootPool, error) {
+	query := `SELECT ` + lootPoolColumns + ` FROM loot_pools WHERE session_id = ?`
+	args := []interface{}{sessionID}
+	if status != "" {
+		query += ` AND status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY created_at DESC`
+
+	var rows []lootPoolRow
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to list loot pools: %w", err)
+	}
+
+	pools := make([]*models.LootPool, 0, len(rows))
+	for i := range rows {
+		pool, err := rows[i].toModel()
+		if err != nil {
+			return nil, err
+		}
+		if err := r.loadItems(ctx, pool); err != nil {
+			return nil, err
+		}
+		pools = append(pools, pool)
+	}
+	return pools, nil
+}
+
+// AssignItem gives a pool item to a character, replacing any earlier assignment, while the pool is open
+func (r *lootPoolRepository) AssignItem(ctx context.Context, poolItemID, characterID string) error {
+	query := `UPDATE loot_pool_items SET assigned_to = ?
+		WHERE id = ? AND pool_id IN (SELECT id FROM loot_pools WHERE status = ?)`
+	return r.updateOpenItem(ctx, query, "loot pool has already been distributed",
+		characterID, poolItemID, models.LootPoolStatusOpen)
+}
+
+// ClaimItem gives a pool item to a character only if nobody has it yet
+func (r *lootPoolRepository) ClaimItem(ctx context.Context, poolItemID, characterID string) error {
+	query := `UPDATE loot_pool_items SET assigned_to = ?
+		WHERE id = ? AND assigned_to IS NULL AND pool_id IN (SELECT id FROM loot_pools WHERE status = ?)`
+	return r.updateOpenItem(ctx, query, "item has already been claimed",
+		characterID, poolItemID, models.LootPoolStatusOpen)
+}
+
+// RecordRoll stores a character's need/greed roll. Each character rolls once per item.
+func (r *lootPoolRepository) RecordRoll(ctx context.Context, roll *models.LootRoll) error {
+	roll.CreatedAt = time.Now()
+	query := `INSERT INTO loot_rolls (pool_item_id, character_id, choice, roll, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (pool_item_id, character_id) DO NOTHING`
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), roll.PoolItemID, roll.CharacterID, roll.Choice,
+		roll.Roll, roll.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record loot roll: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("character has already rolled for this item")
+	}
+	return nil
+}
+
+func (r *lootPoolRepository) updateOpenItem(ctx context.Context, query, conflict string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), args...)
+	if err != nil {
+		return fmt.Errorf("failed to assign loot: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("%s", conflict)
+	}
+	return nil
+}
+
+// loadItems fills in a pool's items, in the order they were added, and their rolls
+func (r *lootPoolRepository) loadItems(ctx context.Context, pool *models.LootPool) error {
+	query := `SELECT lpi.id, lpi.pool_id, lpi.item_id, i.name, i.rarity, lpi.quantity, lpi.assigned_to
+		FROM loot_pool_items lpi
+		JOIN items i ON i.id = lpi.item_id
+		WHERE lpi.pool_id = ?
+		ORDER BY lpi.position`
+	var rows []lootPoolItemRow
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), pool.ID); err != nil {
+		return fmt.Errorf("failed to get loot pool items: %w", err)
+	}
+
+	byID := make(map[string]*models.LootPoolItem, len(rows))
+	for _, row := range rows {
+		item := &models.LootPoolItem{
+			ID:       row.ID,
+			PoolID:   row.PoolID,
+			ItemID:   row.ItemID,
+			Name:     row.Name,
+			Rarity:   models.ItemRarity(row.Rarity),
+			Quantity: row.Quantity,
+		}
+		if row.AssignedTo.Valid {
+			item.AssignedTo = &row.AssignedTo.String
+		}
+		byID[item.ID] = item
+		pool.Items = append(pool.Items, item)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	query = `SELECT lr.pool_item_id, lr.character_id, lr.choice, lr.roll, lr.created_at
+		FROM loot_rolls lr
+		JOIN loot_pool_items lpi ON lpi.id = lr.pool_item_id
+		WHERE lpi.pool_id = ?
+		ORDER BY lr.created_at`
+	var rolls []struct {
+		PoolItemID  string    `db:"pool_item_id"`
+		CharacterID string    `db:"character_id"`
+		Choice      string    `db:"choice"`
+		Roll        int       `db:"roll"`
+		CreatedAt   time.Time `db:"created_at"`
+	}
+	if err := r.db.SelectContext(ctx, &rolls, r.db.Rebind(query), pool.ID); err != nil {
+		return fmt.Errorf("failed to get loot rolls: %w", err)
+	}
+	for _, roll := range rolls {
+		if item := byID[roll.PoolItemID]; item != nil {
+			item.Rolls = append(item.Rolls, models.LootRoll{
+				PoolItemID:  roll.PoolItemID,
+				CharacterID: roll.CharacterID,
+				Choice:      models.LootRollChoice(roll.Choice),
+				Roll:        roll.Roll,
+				CreatedAt:   roll.CreatedAt,
+			})
+		}
+	}
+	return nil
+}
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS starting_equipment_grants;
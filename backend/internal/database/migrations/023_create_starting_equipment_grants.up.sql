@@ -0,0 +1,9 @@
+-- Records the one-time starting equipment step for each character
+CREATE TABLE IF NOT EXISTS starting_equipment_grants (
+    character_id UUID PRIMARY KEY REFERENCES characters(id) ON DELETE CASCADE,
+    method TEXT NOT NULL, -- equipment, gold
+    items JSONB DEFAULT '[]', -- items written to character_inventory
+    gold_awarded INTEGER DEFAULT 0, -- in gold pieces (background pouches + rolled wealth)
+    wealth_roll INTEGER DEFAULT 0, -- raw dice total when starting gold was taken
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
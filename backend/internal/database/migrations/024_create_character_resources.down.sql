@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS character_rest_state;
+DROP TABLE IF EXISTS character_resources;
@@ -0,0 +1,26 @@
+-- Typed class resource ledger (ki, rage, hit dice, ...)
+CREATE TABLE IF NOT EXISTS character_resources (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    character_id UUID NOT NULL REFERENCES characters(id) ON DELETE CASCADE,
+    resource_key TEXT NOT NULL,
+    name TEXT NOT NULL,
+    current_uses INTEGER NOT NULL DEFAULT 0,
+    max_uses INTEGER NOT NULL DEFAULT 0,
+    recovery TEXT NOT NULL, -- short_rest, long_rest, dawn, custom
+    die TEXT DEFAULT '',
+    custom BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(character_id, resource_key)
+);
+
+CREATE INDEX idx_character_resources_character ON character_resources(character_id);
+
+-- Exhaustion and rest bookkeeping
+CREATE TABLE IF NOT EXISTS character_rest_state (
+    character_id UUID PRIMARY KEY REFERENCES characters(id) ON DELETE CASCADE,
+    exhaustion_level INTEGER NOT NULL DEFAULT 0 CHECK (exhaustion_level BETWEEN 0 AND 6),
+    last_short_rest_at TIMESTAMP,
+    last_long_rest_at TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
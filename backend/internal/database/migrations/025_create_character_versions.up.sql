@@ -0,0 +1,14 @@
+-- Immutable character sheet history
+CREATE TABLE IF NOT EXISTS character_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    character_id UUID NOT NULL REFERENCES characters(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    change_type TEXT NOT NULL, -- create, update, experience, level_up, rest, spells, inventory, restore
+    reason TEXT NOT NULL DEFAULT '',
+    changed_by TEXT NOT NULL DEFAULT '',
+    snapshot JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(character_id, version)
+);
+
+CREATE INDEX idx_character_versions_character_created ON character_versions(character_id, created_at);
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS economy_ledger_append_only ON economy_ledger;
+DROP FUNCTION IF EXISTS prevent_economy_ledger_changes();
+DROP TABLE IF EXISTS economy_ledger;
@@ -0,0 +1,31 @@
+-- Append-only record of every money and item movement. Rows deliberately have no foreign
+-- keys so the audit trail outlives deleted characters, items and sessions.
+CREATE TABLE IF NOT EXISTS economy_ledger (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transaction_id UUID NOT NULL,
+    character_id UUID NOT NULL,
+    entry_type TEXT NOT NULL, -- purchase, sale, transfer, loot, adjustment
+    item_id TEXT,
+    quantity INTEGER NOT NULL DEFAULT 0,
+    copper_delta INTEGER NOT NULL DEFAULT 0,
+    balance_after INTEGER NOT NULL DEFAULT 0,
+    description TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL DEFAULT '',
+    session_id UUID,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_economy_ledger_character_created ON economy_ledger(character_id, created_at);
+CREATE INDEX idx_economy_ledger_transaction ON economy_ledger(transaction_id);
+CREATE INDEX idx_economy_ledger_session ON economy_ledger(session_id);
+
+-- Ledger rows are never rewritten
+CREATE OR REPLACE FUNCTION prevent_economy_ledger_changes() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'economy_ledger is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER economy_ledger_append_only
+    BEFORE UPDATE OR DELETE ON economy_ledger
+    FOR EACH ROW EXECUTE FUNCTION prevent_economy_ledger_changes();
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_markets_settlement;
+DROP TABLE IF EXISTS market_trade_volume;
+DROP TABLE IF EXISTS shop_stock;
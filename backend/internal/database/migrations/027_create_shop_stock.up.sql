@@ -0,0 +1,22 @@
+-- Finite stock held by settlement shops
+CREATE TABLE IF NOT EXISTS shop_stock (
+    shop_id UUID NOT NULL REFERENCES settlement_shops(id) ON DELETE CASCADE,
+    item_id TEXT NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (shop_id, item_id)
+);
+
+-- Units players bought from and sold to each settlement's market, per goods category.
+-- The economic simulator turns these into demand and surplus and decays them each cycle.
+CREATE TABLE IF NOT EXISTS market_trade_volume (
+    settlement_id UUID NOT NULL REFERENCES settlements(id) ON DELETE CASCADE,
+    category TEXT NOT NULL,
+    units_bought INTEGER NOT NULL DEFAULT 0,
+    units_sold INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (settlement_id, category)
+);
+
+-- Markets are upserted per settlement
+CREATE UNIQUE INDEX IF NOT EXISTS idx_markets_settlement ON markets(settlement_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_character_inventory_container;
+
+ALTER TABLE character_inventory
+DROP COLUMN IF EXISTS storage_note,
+DROP COLUMN IF EXISTS location,
+DROP COLUMN IF EXISTS container_id;
@@ -0,0 +1,9 @@
+-- Inventory items can sit inside container items (backpacks, quivers, bags of holding)
+-- and be carried, loaded on a mount or stored away from the character.
+ALTER TABLE character_inventory
+ADD COLUMN IF NOT EXISTS container_id TEXT REFERENCES character_inventory(id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS location TEXT NOT NULL DEFAULT 'carried'
+    CHECK (location IN ('carried', 'mount', 'stored')),
+ADD COLUMN IF NOT EXISTS storage_note TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_character_inventory_container ON character_inventory(container_id);
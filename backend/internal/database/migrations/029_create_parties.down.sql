@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_economy_ledger_party;
+ALTER TABLE economy_ledger DROP COLUMN IF EXISTS party_id;
+DROP TABLE IF EXISTS trade_offers;
+DROP TABLE IF EXISTS party_currency;
+DROP TABLE IF EXISTS party_stash;
+DROP TABLE IF EXISTS parties;
@@ -0,0 +1,52 @@
+-- One party per game session, with a stash and coin purse the whole group shares
+CREATE TABLE IF NOT EXISTS parties (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL UNIQUE REFERENCES game_sessions(id) ON DELETE CASCADE,
+    name TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS party_stash (
+    party_id UUID NOT NULL REFERENCES parties(id) ON DELETE CASCADE,
+    item_id TEXT NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (party_id, item_id)
+);
+
+CREATE TABLE IF NOT EXISTS party_currency (
+    party_id UUID PRIMARY KEY REFERENCES parties(id) ON DELETE CASCADE,
+    copper INTEGER NOT NULL DEFAULT 0,
+    silver INTEGER NOT NULL DEFAULT 0,
+    electrum INTEGER NOT NULL DEFAULT 0,
+    gold INTEGER NOT NULL DEFAULT 0,
+    platinum INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Character-to-character trades waiting on the receiver. Accepting one applies the
+-- transfer and marks the offer in the same database transaction.
+CREATE TABLE IF NOT EXISTS trade_offers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID REFERENCES game_sessions(id) ON DELETE CASCADE,
+    from_character_id UUID NOT NULL REFERENCES characters(id) ON DELETE CASCADE,
+    to_character_id UUID NOT NULL REFERENCES characters(id) ON DELETE CASCADE,
+    coins JSONB NOT NULL DEFAULT '{}',
+    items JSONB NOT NULL DEFAULT '[]',
+    message TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined', 'cancelled')),
+    created_by TEXT NOT NULL DEFAULT '',
+    resolved_by TEXT,
+    transaction_id UUID,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP
+);
+
+CREATE INDEX idx_trade_offers_to_status ON trade_offers(to_character_id, status);
+CREATE INDEX idx_trade_offers_from_status ON trade_offers(from_character_id, status);
+
+-- Stash deposits and withdrawals are ledgered against the character and tagged with the party
+ALTER TABLE economy_ledger ADD COLUMN IF NOT EXISTS party_id UUID;
+CREATE INDEX idx_economy_ledger_party ON economy_ledger(party_id, created_at);
@@ -0,0 +1,3 @@
+ALTER TABLE character_inventory
+DROP COLUMN IF EXISTS curse_active,
+DROP COLUMN IF EXISTS charges;
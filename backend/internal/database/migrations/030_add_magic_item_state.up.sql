@@ -0,0 +1,5 @@
+-- Per-item magic state: charges left on wands, staffs and rings (NULL until first
+-- spent, meaning fully charged) and whether a cursed item's curse has taken hold.
+ALTER TABLE character_inventory
+ADD COLUMN IF NOT EXISTS charges INTEGER CHECK (charges >= 0),
+ADD COLUMN IF NOT EXISTS curse_active BOOLEAN NOT NULL DEFAULT FALSE;
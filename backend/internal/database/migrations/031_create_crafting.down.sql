@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS crafting_projects;
+DROP TABLE IF EXISTS crafting_recipes;
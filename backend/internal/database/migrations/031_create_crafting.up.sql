@@ -0,0 +1,41 @@
+-- Homebrew crafting recipes a DM defines for their game session. Built-in recipes
+-- ship in data/recipes and are not stored here.
+CREATE TABLE IF NOT EXISTS crafting_recipes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL REFERENCES game_sessions(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    category TEXT NOT NULL DEFAULT 'gear',
+    description TEXT NOT NULL DEFAULT '',
+    output JSONB NOT NULL,
+    ingredients JSONB NOT NULL DEFAULT '[]',
+    tool TEXT NOT NULL DEFAULT '',
+    ability TEXT NOT NULL DEFAULT '',
+    gold_cost INTEGER NOT NULL DEFAULT 0 CHECK (gold_cost >= 0),
+    workdays INTEGER NOT NULL DEFAULT 1 CHECK (workdays > 0),
+    dc INTEGER NOT NULL DEFAULT 10,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_crafting_recipes_session ON crafting_recipes(session_id);
+
+-- Downtime crafting in progress. The recipe is copied in when work starts; finishing a
+-- project consumes its inputs and produces its output in the same ledgered transaction.
+CREATE TABLE IF NOT EXISTS crafting_projects (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    character_id UUID NOT NULL REFERENCES characters(id) ON DELETE CASCADE,
+    session_id UUID REFERENCES game_sessions(id) ON DELETE SET NULL,
+    recipe_id TEXT NOT NULL,
+    recipe JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'in_progress' CHECK (status IN ('in_progress', 'completed', 'failed', 'abandoned')),
+    days_worked INTEGER NOT NULL DEFAULT 0 CHECK (days_worked >= 0),
+    check_roll INTEGER,
+    check_total INTEGER,
+    transaction_id UUID,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX idx_crafting_projects_character_status ON crafting_projects(character_id, status);
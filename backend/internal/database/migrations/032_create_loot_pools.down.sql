@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS loot_rolls;
+DROP TABLE IF EXISTS loot_pool_items;
+DROP TABLE IF EXISTS loot_pools;
@@ -0,0 +1,41 @@
+-- Treasure from a fight or an encounter objective, held until the party divides it.
+-- Coins and experience are split evenly between character_ids when the pool is distributed.
+CREATE TABLE IF NOT EXISTS loot_pools (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL REFERENCES game_sessions(id) ON DELETE CASCADE,
+    source_type TEXT NOT NULL DEFAULT 'manual' CHECK (source_type IN ('combat', 'auto_resolution', 'encounter_objective', 'manual')),
+    source_id TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    mode TEXT NOT NULL DEFAULT 'dm' CHECK (mode IN ('dm', 'claim', 'round_robin', 'need_greed')),
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'distributed')),
+    coins JSONB NOT NULL DEFAULT '{}',
+    experience INTEGER NOT NULL DEFAULT 0 CHECK (experience >= 0),
+    character_ids JSONB NOT NULL DEFAULT '[]',
+    transaction_id UUID,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    distributed_at TIMESTAMP
+);
+
+CREATE INDEX idx_loot_pools_session_status ON loot_pools(session_id, status);
+
+CREATE TABLE IF NOT EXISTS loot_pool_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pool_id UUID NOT NULL REFERENCES loot_pools(id) ON DELETE CASCADE,
+    item_id TEXT NOT NULL REFERENCES items(id),
+    quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),
+    assigned_to UUID REFERENCES characters(id) ON DELETE SET NULL,
+    position INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_loot_pool_items_pool ON loot_pool_items(pool_id);
+
+-- One need/greed roll per character per item
+CREATE TABLE IF NOT EXISTS loot_rolls (
+    pool_item_id UUID NOT NULL REFERENCES loot_pool_items(id) ON DELETE CASCADE,
+    character_id UUID NOT NULL REFERENCES characters(id) ON DELETE CASCADE,
+    choice TEXT NOT NULL CHECK (choice IN ('need', 'greed', 'pass')),
+    roll INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (pool_item_id, character_id)
+);
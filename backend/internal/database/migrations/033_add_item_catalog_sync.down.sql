@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS item_catalog_versions;
+
+DROP INDEX IF EXISTS idx_items_source;
+DROP INDEX IF EXISTS idx_items_session;
+
+ALTER TABLE items
+DROP COLUMN IF EXISTS catalog_version,
+DROP COLUMN IF EXISTS session_id,
+DROP COLUMN IF EXISTS source;
@@ -0,0 +1,16 @@
+-- Items are synced from data/items, created as a DM's homebrew for one game session,
+-- or created ad hoc. Homebrew items are only listed within their session.
+ALTER TABLE items
+ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT 'custom' CHECK (source IN ('catalog', 'homebrew', 'custom')),
+ADD COLUMN IF NOT EXISTS session_id UUID REFERENCES game_sessions(id) ON DELETE CASCADE,
+ADD COLUMN IF NOT EXISTS catalog_version TEXT;
+
+CREATE INDEX idx_items_session ON items(session_id) WHERE session_id IS NOT NULL;
+CREATE INDEX idx_items_source ON items(source);
+
+-- Every version of data/items loaded into the items table
+CREATE TABLE IF NOT EXISTS item_catalog_versions (
+    version TEXT PRIMARY KEY,
+    item_count INTEGER NOT NULL DEFAULT 0,
+    synced_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS crdt_changes;
+DROP TABLE IF EXISTS crdt_documents;
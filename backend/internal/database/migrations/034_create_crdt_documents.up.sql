@@ -0,0 +1,19 @@
+-- Automerge documents synced over /ws/v1/characters/{id}/sync. The snapshot holds a
+-- document as of its last compaction; changes synced since then are appended to
+-- crdt_changes and folded into the snapshot at the next compaction.
+CREATE TABLE IF NOT EXISTS crdt_documents (
+    id TEXT PRIMARY KEY,
+    snapshot BYTEA,
+    compacted_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS crdt_changes (
+    id BIGSERIAL PRIMARY KEY,
+    document_id TEXT NOT NULL REFERENCES crdt_documents(id) ON DELETE CASCADE,
+    data BYTEA NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_crdt_changes_document ON crdt_changes(document_id, id);
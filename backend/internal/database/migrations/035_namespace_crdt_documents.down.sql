@@ -0,0 +1,9 @@
+ALTER TABLE crdt_changes DROP CONSTRAINT IF EXISTS crdt_changes_document_id_fkey;
+
+DELETE FROM crdt_changes WHERE document_id NOT LIKE 'character:%';
+DELETE FROM crdt_documents WHERE id NOT LIKE 'character:%';
+UPDATE crdt_documents SET id = substr(id, length('character:') + 1);
+UPDATE crdt_changes SET document_id = substr(document_id, length('character:') + 1);
+
+ALTER TABLE crdt_changes ADD CONSTRAINT crdt_changes_document_id_fkey
+    FOREIGN KEY (document_id) REFERENCES crdt_documents(id) ON DELETE CASCADE;
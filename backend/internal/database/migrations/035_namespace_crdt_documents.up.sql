@@ -0,0 +1,10 @@
+-- Collaborative documents now come in kinds (character sheets, party notes, battle map
+-- annotations, session prep), so ids are prefixed with their kind. Existing documents
+-- are all character sheets.
+ALTER TABLE crdt_changes DROP CONSTRAINT IF EXISTS crdt_changes_document_id_fkey;
+
+UPDATE crdt_documents SET id = 'character:' || id WHERE id NOT LIKE '%:%';
+UPDATE crdt_changes SET document_id = 'character:' || document_id WHERE document_id NOT LIKE '%:%';
+
+ALTER TABLE crdt_changes ADD CONSTRAINT crdt_changes_document_id_fkey
+    FOREIGN KEY (document_id) REFERENCES crdt_documents(id) ON DELETE CASCADE;
@@ -0,0 +1,23 @@
+-- Game session chat. A message with rows in chat_message_recipients is a whisper, seen
+-- only by its sender and those recipients.
+CREATE TABLE IF NOT EXISTS chat_messages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL REFERENCES game_sessions(id) ON DELETE CASCADE,
+    sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    sender_name TEXT NOT NULL DEFAULT '',
+    channel VARCHAR(10) NOT NULL CHECK (channel IN ('ic', 'ooc')),
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('message', 'emote', 'narration', 'roll')),
+    content TEXT NOT NULL,
+    is_whisper BOOLEAN NOT NULL DEFAULT FALSE,
+    dice_roll_id UUID REFERENCES dice_rolls(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS chat_message_recipients (
+    message_id UUID NOT NULL REFERENCES chat_messages(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (message_id, user_id)
+);
+
+CREATE INDEX idx_chat_messages_session ON chat_messages(session_id, created_at DESC, id DESC);
+CREATE INDEX idx_chat_message_recipients_user ON chat_message_recipients(user_id);
@@ -0,0 +1,16 @@
+-- A session's event log: rolls, chat, combat actions, NPC dialog, loot and experience,
+-- all in one stream that timelines, recaps, analytics and exports read from.
+CREATE TABLE IF NOT EXISTS game_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL REFERENCES game_sessions(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    actor_id TEXT NOT NULL DEFAULT '',
+    character_id UUID REFERENCES characters(id) ON DELETE SET NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    game_time TEXT NOT NULL DEFAULT '',
+    occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_game_events_session ON game_events(session_id, occurred_at, id);
+CREATE INDEX idx_game_events_character ON game_events(character_id, occurred_at, id) WHERE character_id IS NOT NULL;
+CREATE INDEX idx_game_events_type ON game_events(session_id, type);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS calendar_feeds;
+DROP TABLE IF EXISTS availability_poll_votes;
+DROP TABLE IF EXISTS availability_poll_options;
+DROP TABLE IF EXISTS availability_polls;
+DROP TABLE IF EXISTS session_rsvps;
+DROP TABLE IF EXISTS session_schedules;
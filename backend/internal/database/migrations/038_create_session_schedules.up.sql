@@ -0,0 +1,65 @@
+-- Planned game sessions: recurring slots, each player's RSVP to an occurrence, polls
+-- proposing dates, and the secret tokens behind each user's iCalendar feed.
+CREATE TABLE IF NOT EXISTS session_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL REFERENCES game_sessions(id) ON DELETE CASCADE,
+    title VARCHAR(200) NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    duration_minutes INTEGER NOT NULL DEFAULT 240 CHECK (duration_minutes > 0),
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    recurrence VARCHAR(10) NOT NULL DEFAULT 'none' CHECK (recurrence IN ('none', 'weekly', 'monthly')),
+    recurrence_interval INTEGER NOT NULL DEFAULT 1 CHECK (recurrence_interval > 0),
+    until TIMESTAMP WITH TIME ZONE,
+    reminder_minutes INTEGER NOT NULL DEFAULT 1440 CHECK (reminder_minutes >= 0),
+    created_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS session_rsvps (
+    schedule_id UUID NOT NULL REFERENCES session_schedules(id) ON DELETE CASCADE,
+    occurrence_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    response VARCHAR(5) NOT NULL CHECK (response IN ('yes', 'no', 'maybe')),
+    note TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (schedule_id, occurrence_start, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS availability_polls (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL REFERENCES game_sessions(id) ON DELETE CASCADE,
+    title VARCHAR(200) NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    duration_minutes INTEGER NOT NULL DEFAULT 240 CHECK (duration_minutes > 0),
+    status VARCHAR(10) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed')),
+    chosen_option_id UUID,
+    schedule_id UUID REFERENCES session_schedules(id) ON DELETE SET NULL,
+    created_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    closed_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS availability_poll_options (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    poll_id UUID NOT NULL REFERENCES availability_polls(id) ON DELETE CASCADE,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS availability_poll_votes (
+    option_id UUID NOT NULL REFERENCES availability_poll_options(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    response VARCHAR(5) NOT NULL CHECK (response IN ('yes', 'no', 'maybe')),
+    PRIMARY KEY (option_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_session_schedules_session ON session_schedules(session_id);
+CREATE INDEX idx_availability_polls_session ON availability_polls(session_id, created_at DESC);
+CREATE INDEX idx_availability_poll_options_poll ON availability_poll_options(poll_id, starts_at);
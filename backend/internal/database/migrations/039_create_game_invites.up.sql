@@ -0,0 +1,19 @@
+-- Invitations to private game sessions. An invite sent to an email address is redeemed
+-- once; a shareable link (no invitee email) up to max_uses times.
+CREATE TABLE IF NOT EXISTS game_invites (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    session_id UUID NOT NULL REFERENCES game_sessions(id) ON DELETE CASCADE,
+    inviter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    invitee_email VARCHAR(255) NOT NULL DEFAULT '',
+    invitee_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    code VARCHAR(64) NOT NULL UNIQUE,
+    max_uses INTEGER NOT NULL DEFAULT 1 CHECK (max_uses > 0),
+    use_count INTEGER NOT NULL DEFAULT 0 CHECK (use_count >= 0),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (invitee_email = '' OR max_uses = 1)
+);
+
+CREATE INDEX idx_game_invites_session ON game_invites(session_id, created_at DESC);
@@ -0,0 +1,2 @@
+ALTER TABLE game_participants
+DROP COLUMN IF EXISTS role;
@@ -0,0 +1,5 @@
+-- A participant joins as a player, who takes a seat and plays a character, or as a
+-- spectator, who watches the session without a seat. The DM is the session's dm_user_id.
+ALTER TABLE game_participants
+ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'player'
+    CHECK (role IN ('player', 'spectator'));
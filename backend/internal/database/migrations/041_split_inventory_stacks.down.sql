@@ -0,0 +1,25 @@
+DROP INDEX IF EXISTS idx_character_inventory_container_stack;
+DROP INDEX IF EXISTS idx_character_inventory_loose_stack;
+
+-- Fold split stacks back into one row per item before restoring the old key
+UPDATE character_inventory ci
+SET quantity = totals.quantity, container_id = NULL
+FROM (
+    SELECT character_id, item_id, SUM(quantity) AS quantity, MIN(id) AS keep_id
+    FROM character_inventory
+    GROUP BY character_id, item_id
+    HAVING COUNT(*) > 1
+) totals
+WHERE ci.id = totals.keep_id;
+
+DELETE FROM character_inventory ci
+USING (
+    SELECT character_id, item_id, MIN(id) AS keep_id
+    FROM character_inventory
+    GROUP BY character_id, item_id
+    HAVING COUNT(*) > 1
+) totals
+WHERE ci.character_id = totals.character_id AND ci.item_id = totals.item_id AND ci.id <> totals.keep_id;
+
+ALTER TABLE character_inventory
+ADD CONSTRAINT unique_character_item UNIQUE(character_id, item_id);
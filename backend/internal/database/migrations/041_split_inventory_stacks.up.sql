@@ -0,0 +1,8 @@
+-- A character can split one item across containers: one loose stack per item,
+-- plus at most one stack of it inside each container.
+ALTER TABLE character_inventory DROP CONSTRAINT IF EXISTS unique_character_item;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_character_inventory_loose_stack
+    ON character_inventory(character_id, item_id) WHERE container_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_character_inventory_container_stack
+    ON character_inventory(character_id, item_id, container_id) WHERE container_id IS NOT NULL;
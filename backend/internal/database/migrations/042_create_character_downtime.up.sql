@@ -0,0 +1,8 @@
+-- Downtime days the DM has granted a character and they have not yet spent. Crafting
+-- work spends them, so players cannot log more days than the story allows.
+CREATE TABLE IF NOT EXISTS character_downtime (
+    character_id UUID PRIMARY KEY REFERENCES characters(id) ON DELETE CASCADE,
+    days_available INTEGER NOT NULL DEFAULT 0 CHECK (days_available >= 0),
+    updated_by TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
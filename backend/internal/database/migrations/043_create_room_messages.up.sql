@@ -0,0 +1,12 @@
+-- Room messages kept for websocket clients that reconnect, so restarting a single server
+-- neither resets a room's sequence numbers nor loses what its players missed. Each room
+-- keeps its newest messages; rooms quiet for a day are forgotten.
+CREATE TABLE IF NOT EXISTS room_messages (
+    room_id TEXT NOT NULL,
+    seq BIGINT NOT NULL,
+    data BYTEA NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (room_id, seq)
+);
+
+CREATE INDEX idx_room_messages_created ON room_messages(created_at);
@@ -0,0 +1,247 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+// PartyRepository defines the interface for party stash and trade offer operations.
+// Coins and items move through InventoryRepository.ApplyTransaction.
+type PartyRepository interface {
+	GetPartyBySession(ctx context.Context, sessionID string) (*models.Party, error)
+	CreateParty(ctx context.Context, party *models.Party) error
+	GetPartyStash(ctx context.Context, partyID string) ([]*models.PartyStashItem, error)
+	GetPartyCurrency(ctx context.Context, partyID string) (models.Coins, error)
+
+	CreateTradeOffer(ctx context.Context, offer *models.TradeOffer) error
+	GetTradeOffer(ctx context.Context, id string) (*models.TradeOffer, error)
+	ListTradeOffers(ctx context.Context, characterID string, status models.TradeStatus) ([]*models.TradeOffer, error)
+	ResolveTradeOffer(ctx context.Context, id string, status models.TradeStatus, resolvedBy string) error
+}
+
+// partyRepository implements PartyRepository
+type partyRepository struct {
+	db *DB
+}
+
+// NewPartyRepository creates a new party repository
+func NewPartyRepository(db *DB) PartyRepository {
+	return &partyRepository{db: db}
+}
+
+const tradeOfferColumns = `id, session_id, from_character_id, to_character_id, coins, items, message,
+	status, created_by, resolved_by, transaction_id, created_at, resolved_at`
+
+// tradeOfferRow is a trade offer as stored, with coins and items as JSON
+type tradeOfferRow struct {
+	ID              string         `db:"id"`
+	SessionID       sql.NullString `db:"session_id"`
+	FromCharacterID string         `db:"from_character_id"`
+	ToCharacterID   string         `db:"to_character_id"`
+	Coins           []byte         `db:"coins"`
+	Items           []byte         `db:"items"`
+	Message         string         `db:"message"`
+	Status          string         `db:"status"`
+	CreatedBy       string         `db:"created_by"`
+	ResolvedBy      sql.NullString `db:"resolved_by"`
+	TransactionID   sql.NullString `db:"transaction_id"`
+	CreatedAt       time.Time      `db:"created_at"`
+	ResolvedAt      sql.NullTime   `db:"resolved_at"`
+}
+
+func (row *tradeOfferRow) toModel() (*models.TradeOffer, error) {
+	offer := &models.TradeOffer{
+		ID:              row.ID,
+		FromCharacterID: row.FromCharacterID,
+		ToCharacterID:   row.ToCharacterID,
+		Message:         row.Message,
+		Status:          models.TradeStatus(row.Status),
+		CreatedBy:       row.CreatedBy,
+		CreatedAt:       row.CreatedAt,
+	}
+	if row.SessionID.Valid {
+		offer.SessionID = &row.SessionID.String
+	}
+	if row.ResolvedBy.Valid {
+		offer.ResolvedBy = &row.ResolvedBy.String
+	}
+	if row.TransactionID.Valid {
+		offer.TransactionID = &row.TransactionID.String
+	}
+	if row.ResolvedAt.Valid {
+		offer.ResolvedAt = &row.ResolvedAt.Time
+	}
+	if len(row.Coins) > 0 {
+		if err := json.Unmarshal(row.Coins, &offer.Coins); err != nil {
+			return nil, fmt.Errorf("failed to decode trade offer coins: %w", err)
+		}
+	}
+	if len(row.Items) > 0 {
+		if err := json.Unmarshal(row.Items, &offer.Items); err != nil {
+			return nil, fmt.Errorf("failed to decode trade offer items: %w", err)
+		}
+	}
+	return offer, nil
+}
+
+// GetPartyBySession returns the session's party, or nil if it has none yet
+func (r *partyRepository) GetPartyBySession(ctx context.Context, sessionID string) (*models.Party, error) {
+	query := `SELECT id, session_id, name, created_at, updated_at FROM parties WHERE session_id = ?`
+
+	var party models.Party
+	err := r.db.GetContext(ctx, &party, r.db.Rebind(query), sessionID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get party: %w", err)
+	}
+	return &party, nil
+}
+
+// CreateParty stores a party for a session. It does nothing if the session already has one.
+func (r *partyRepository) CreateParty(ctx context.Context, party *models.Party) error {
+	if party.ID == "" {
+		party.ID = uuid.New().String()
+	}
+	now := time.Now()
+	party.CreatedAt = now
+	party.UpdatedAt = now
+
+	query := `INSERT INTO parties (id, session_id, name, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (session_id) DO NOTHING`
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), party.ID, party.SessionID, party.Name, now, now); err != nil {
+		return fmt.Errorf("failed to create party: %w", err)
+	}
+	return nil
+}
+
+// GetPartyStash lists the items in a party's stash
+func (r *partyRepository) GetPartyStash(ctx context.Context, partyID string) ([]*models.PartyStashItem, error) {
+	query := `SELECT party_id, item_id, quantity, updated_at FROM party_stash
+		WHERE party_id = ? AND quantity > 0 ORDER BY item_id`
+
+	stash := make([]*models.PartyStashItem, 0, 20)
+	if err := r.db.SelectContext(ctx, &stash, r.db.Rebind(query), partyID); err != nil {
+		return nil, fmt.Errorf("failed to get party stash: %w", err)
+	}
+	return stash, nil
+}
+
+// GetPartyCurrency returns the coins in a party's purse, which is empty until first used
+func (r *partyRepository) GetPartyCurrency(ctx context.Context, partyID string) (models.Coins, error) {
+	query := `SELECT copper, silver, electrum, gold, platinum FROM party_currency WHERE party_id = ?`
+
+	var coins models.Coins
+	err := r.db.QueryRowContext(ctx, r.db.Rebind(query), partyID).
+		Scan(&coins.Copper, &coins.Silver, &coins.Electrum, &coins.Gold, &coins.Platinum)
+	if err != nil && err != sql.ErrNoRows {
+		return models.Coins{}, fmt.Errorf("failed to get party currency: %w", err)
+	}
+	return coins, nil
+}
+
+// CreateTradeOffer stores a new trade offer
+func (r *partyRepository) CreateTradeOffer(ctx context.Context, offer *models.TradeOffer) error {
+	if offer.ID == "" {
+		offer.ID = uuid.New().String()
+	}
+	if offer.CreatedAt.IsZero() {
+		offer.CreatedAt = time.Now()
+	}
+	if offer.Status == "" {
+		offer.Status = models.TradeStatusPending
+	}
+
+	coins, err := json.Marshal(offer.Coins)
+	if err != nil {
+		return err
+	}
+	items := offer.Items
+	if items == nil {
+		items = []models.LootItem{}
+	}
+	itemsJSON, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO trade_offers (id, session_id, from_character_id, to_character_id, coins, items,
+			message, status, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	if _, err := r.db.ExecContext(ctx, r.db.Rebind(query), offer.ID, offer.SessionID, offer.FromCharacterID,
+		offer.ToCharacterID, coins, itemsJSON, offer.Message, offer.Status, offer.CreatedBy, offer.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create trade offer: %w", err)
+	}
+	return nil
+}
+
+// GetTradeOffer returns a trade offer, or nil if it does not exist
+func (r *partyRepository) GetTradeOffer(ctx context.Context, id string) (*models.TradeOffer, error) {
+	query := `SELECT ` + tradeOfferColumns + ` FROM trade_offers WHERE id = ?`
+
+	var row tradeOfferRow
+	err := r.db.GetContext(ctx, &row, r.db.Rebind(query), id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade offer: %w", err)
+	}
+	return row.toModel()
+}
+
+// ListTradeOffers returns the offers a character made or received, newest first.
+// An empty status lists offers in every status.
+func (r *partyRepository) ListTradeOffers(ctx context.Context, characterID string, status models.TradeStatus) ([]*models.TradeOffer, error) {
+	query := `SELECT ` + tradeOfferColumns + ` FROM trade_offers
+		WHERE (from_character_id = ? OR to_character_id = ?)`
+	args := []interface{}{characterID, characterID}
+	if status != "" {
+		query += ` AND status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY created_at DESC`
+
+	var rows []tradeOfferRow
+	if err := r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to list trade offers: %w", err)
+	}
+
+	offers := make([]*models.TradeOffer, 0, len(rows))
+	for i := range rows {
+		offer, err := rows[i].toModel()
+		if err != nil {
+			return nil, err
+		}
+		offers = append(offers, offer)
+	}
+	return offers, nil
+}
+
+// ResolveTradeOffer declines or cancels a pending offer. Accepting goes through ApplyTransaction
+// so the offer and the transfer it makes commit together.
+func (r *partyRepository) ResolveTradeOffer(ctx context.Context, id string, status models.TradeStatus, resolvedBy string) error {
+	query := `UPDATE trade_offers SET status = ?, resolved_by = ?, resolved_at = ?
+		WHERE id = ? AND status = ?`
+	result, err := r.db.ExecContext(ctx, r.db.Rebind(query), status, resolvedBy, time.Now(), id, models.TradeStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to resolve trade offer: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("trade offer is no longer pending")
+	}
+	return nil
+}
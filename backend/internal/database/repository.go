@@ -40,6 +40,7 @@ type GameSessionRepository interface {
 
 	// Participant management
 	AddParticipant(ctx context.Context, sessionID, userID string, characterID *string) error
+	AddSpectator(ctx context.Context, sessionID, userID string) error
 	RemoveParticipant(ctx context.Context, sessionID, userID string) error
 	GetParticipants(ctx context.Context, sessionID string) ([]*models.GameParticipant, error)
 	UpdateParticipantOnlineStatus(ctx context.Context, sessionID, userID string, isOnline bool) error
@@ -60,7 +61,9 @@ type InventoryRepository interface {
 	// Item operations
 	CreateItem(item *models.Item) error
 	GetItem(itemID string) (*models.Item, error)
-	GetItemsByType(itemType models.ItemType) ([]*models.Item, error)
+	GetSessionItem(itemID, sessionID string) (*models.Item, error)
+	GetCharacterItem(itemID, characterID string) (*models.Item, error)
+	GetItemsByType(itemType models.ItemType, sessionID string) ([]*models.Item, error)
 
 	// Inventory operations
 	AddItemToInventory(characterID, itemID string, quantity int) error
@@ -69,6 +72,9 @@ type InventoryRepository interface {
 	EquipItem(characterID, itemID string, equip bool) error
 	AttuneItem(characterID, itemID string) error
 	UnattuneItem(characterID, itemID string) error
+	MoveInventoryItem(characterID string, move *models.InventoryMove) error
+	SetItemCharges(characterID, itemID string, charges int) error
+	SetItemCurse(characterID, itemID string, active bool) error
 
 	// Currency operations
 	GetCharacterCurrency(characterID string) (*models.Currency, error)
@@ -77,6 +83,13 @@ type InventoryRepository interface {
 
 	// Weight operations
 	GetCharacterWeight(characterID string) (*models.InventoryWeight, error)
+
+	// Starting equipment operations
+	GetStartingEquipmentGrant(characterID string) (*models.StartingEquipmentGrant, error)
+
+	// Economy operations, applied atomically and recorded in the ledger
+	ApplyTransaction(txn *models.EconomyTransaction) ([]*models.LedgerEntry, error)
+	GetLedgerEntries(filter models.LedgerFilter) ([]*models.LedgerEntry, error)
 }
 
 // RefreshTokenRepository defines the interface for refresh token data operations
@@ -105,20 +118,32 @@ type NPCRepository interface {
 
 // Repositories aggregates all repository interfaces
 type Repositories struct {
-	Users           UserRepository
-	Characters      CharacterRepository
-	GameSessions    GameSessionRepository
-	DiceRolls       DiceRollRepository
-	NPCs            NPCRepository
-	Inventory       InventoryRepository
-	RefreshTokens   RefreshTokenRepository
-	CustomRaces     CustomRaceRepository
-	CustomClasses   *CustomClassRepository
-	DMAssistant     DMAssistantRepository
-	Encounters      *EncounterRepository
-	Campaign        CampaignRepository
-	CombatAnalytics CombatAnalyticsRepository
-	WorldBuilding   *WorldBuildingRepository
-	Narrative       *NarrativeRepository
-	RuleBuilder     *RuleBuilderRepository
+	Users              UserRepository
+	Characters         CharacterRepository
+	GameSessions       GameSessionRepository
+	DiceRolls          DiceRollRepository
+	NPCs               NPCRepository
+	Inventory          InventoryRepository
+	CharacterResources CharacterResourceRepository
+	CharacterVersions  CharacterVersionRepository
+	Parties            PartyRepository
+	Crafting           CraftingRepository
+	LootPools          LootPoolRepository
+	CRDTDocuments      CRDTDocumentRepository
+	RoomMessages       RoomMessageRepository
+	Chat               ChatRepository
+	GameEvents         GameEventRepository
+	Schedules          ScheduleRepository
+	GameInvites        GameInviteRepository
+	ItemLibrary        ItemLibraryRepository
+	RefreshTokens      RefreshTokenRepository
+	CustomRaces        CustomRaceRepository
+	CustomClasses      *CustomClassRepository
+	DMAssistant        DMAssistantRepository
+	Encounters         *EncounterRepository
+	Campaign           CampaignRepository
+	CombatAnalytics    CombatAnalyticsRepository
+	WorldBuilding      *WorldBuildingRepository
+	Narrative          *NarrativeRepository
+	RuleBuilder        *RuleBuilderRepository
 }
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+// RoomMessageRepository defines the interface for the websocket rooms' replay buffers
+type RoomMessageRepository interface {
+	AppendRoomMessage(ctx context.Context, message *models.RoomMessage, keep int) error
+	ListRoomMessages(ctx context.Context, roomID string) ([]*models.RoomMessage, error)
+	DeleteQuietRooms(ctx context.Context, before time.Time) error
+}
+
+// roomMessageRepository implements RoomMessageRepository
+type roomMessageRepository struct {
+	db *DB
+}
+
+// NewRoomMessageRepository creates a new room message repository
+func NewRoomMessageRepository(db *DB) RoomMessageRepository {
+	return &roomMessageRepository{db: db}
+}
+
+// AppendRoomMessage stores a room message and drops the room's messages older than the
+// newest keep
+func (r *roomMessageRepository) AppendRoomMessage(ctx context.Context, message *models.RoomMessage, keep int) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if message.CreatedAt.IsZero() {
+		message.CreatedAt = time.Now()
+	}
+	query := `INSERT INTO room_messages (room_id, seq, data, created_at) VALUES (?, ?, ?, ?)`
+	if _, err := tx.ExecContext(ctx, r.db.Rebind(query), message.RoomID, message.Seq, message.Data, message.CreatedAt); err != nil {
+		return fmt.Errorf("failed to append room message: %w", err)
+	}
+	query = `DELETE FROM room_messages WHERE room_id = ? AND seq <= ?`
+	if _, err := tx.ExecContext(ctx, r.db.Rebind(query), message.RoomID, message.Seq-int64(keep)); err != nil {
+		return fmt.Errorf("failed to trim room messages: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ListRoomMessages returns the messages kept for a room in sequence order
+func (r *roomMessageRepository) ListRoomMessages(ctx context.Context, roomID string) ([]*models.RoomMessage, error) {
+	query := `SELECT room_id, seq, data, created_at FROM room_messages WHERE room_id = ? ORDER BY seq`
+
+	var messages []*models.RoomMessage
+	if err := r.db.SelectContext(ctx, &messages, r.db.Rebind(query), roomID); err != nil {
+		return nil, fmt.Errorf("failed to list room messages: %w", err)
+	}
+	return messages, nil
+}
+
+// DeleteQuietRooms forgets the rooms with no message since before
+func (r *roomMessageRepository) DeleteQuietRooms(ctx context.Context, before time.Time) error {
+	query := `DELETE FROM room_messages WHERE room_id IN (
+		SELECT room_id FROM room_messages GROUP BY room_id HAVING MAX(created_at) < ?)`
+	if _, err := r.db.ExecContextRebind(ctx, query, before); err != nil {
+		return fmt.Errorf("failed to delete quiet rooms: %w", err)
+	}
+	return nil
+}
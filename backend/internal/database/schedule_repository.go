@@ -0,0 +1,349 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+// ScheduleRepository defines the interface for planned sessions, RSVPs, availability
+// polls and calendar feeds
+type ScheduleRepository interface {
+	CreateSchedule(ctx context.Context, schedule *models.SessionSchedule) error
+	GetSchedule(ctx context.Context, id string) (*models.SessionSchedule, error)
+	ListSchedules(ctx context.Context, sessionID string) ([]*models.SessionSchedule, error)
+	ListSchedulesForUser(ctx context.Context, userID string) ([]*models.SessionSchedule, error)
+	UpdateSchedule(ctx context.Context, schedule *models.SessionSchedule) error
+	DeleteSchedule(ctx context.Context, id string) error
+
+	SetRSVP(ctx context.Context, rsvp *models.SessionRSVP) error
+	ListRSVPs(ctx context.Context, scheduleID string, from, to time.Time) ([]*models.SessionRSVP, error)
+
+	CreatePoll(ctx context.Context, poll *models.AvailabilityPoll) error
+	GetPoll(ctx context.Context, id string) (*models.AvailabilityPoll, error)
+	ListPolls(ctx context.Context, sessionID string) ([]*models.AvailabilityPoll, error)
+	SetVotes(ctx context.Context, votes []*models.PollVote) error
+	ClosePoll(ctx context.Context, poll *models.AvailabilityPoll) error
+
+	GetCalendarToken(ctx context.Context, userID string) (string, error)
+	SetCalendarToken(ctx context.Context, userID, token string) error
+	GetCalendarUser(ctx context.Context, token string) (string, error)
+}
+
+// scheduleRepository implements ScheduleRepository
+type scheduleRepository struct {
+	db *DB
+}
+
+// NewScheduleRepository creates a new schedule repository
+func NewScheduleRepository(db *DB) ScheduleRepository {
+	return &scheduleRepository{db: db}
+}
+
+const sessionScheduleColumns = `s.id, s.session_id, s.title, s.notes, s.starts_at, s.duration_minutes, s.timezone,
+	s.recurrence, s.recurrence_interval, s.until, s.reminder_minutes, s.created_by, s.created_at, s.updated_at`
+
+const availabilityPollColumns = `id, session_id, title, timezone, duration_minutes, status, chosen_option_id,
+	schedule_id, created_by, created_at, closed_at`
+
+// CreateSchedule stores a new session schedule
+func (r *scheduleRepository) CreateSchedule(ctx context.Context, schedule *models.SessionSchedule) error {
+	if schedule.ID == "" {
+		schedule.ID = uuid.New().String()
+	}
+	now := time.Now()
+	schedule.CreatedAt = now
+	schedule.UpdatedAt = now
+
+	query := `INSERT INTO session_schedules (id, session_id, title, notes, starts_at, duration_minutes, timezone,
+			recurrence, recurrence_interval, until, reminder_minutes, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	if _, err := r.db.ExecContextRebind(ctx, query, schedule.ID, schedule.SessionID, schedule.Title, schedule.Notes,
+		schedule.StartsAt, schedule.DurationMinutes, schedule.Timezone, schedule.Recurrence, schedule.Interval,
+		schedule.Until, schedule.ReminderMinutes, schedule.CreatedBy, schedule.CreatedAt, schedule.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to create session schedule: %w", err)
+	}
+	return nil
+}
+
+// GetSchedule returns a session schedule, or nil if it does not exist
+func (r *scheduleRepository) GetSchedule(ctx context.Context, id string) (*models.SessionSchedule, error) {
+	query := `SELECT ` + sessionScheduleColumns + ` FROM session_schedules s WHERE s.id = ?`
+
+	var schedule models.SessionSchedule
+	err := r.db.GetContext(ctx, &schedule, r.db.Rebind(query), id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session schedule: %w", err)
+	}
+	return &schedule, nil
+}
+
+// ListSchedules returns a game session's schedules, earliest first
+func (r *scheduleRepository) ListSchedules(ctx context.Context, sessionID string) ([]*models.SessionSchedule, error) {
+	query := `SELECT ` + sessionScheduleColumns + ` FROM session_schedules s
+		WHERE s.session_id = ? ORDER BY s.starts_at, s.id`
+
+	schedules := make([]*models.SessionSchedule, 0)
+	if err := r.db.SelectContext(ctx, &schedules, r.db.Rebind(query), sessionID); err != nil {
+		return nil, fmt.Errorf("failed to list session schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+// ListSchedulesForUser returns the schedules of every game session the user runs or plays in
+func (r *scheduleRepository) ListSchedulesForUser(ctx context.Context, userID string) ([]*models.SessionSchedule, error) {
+	query := `SELECT ` + sessionScheduleColumns + ` FROM session_schedules s
+		JOIN game_sessions gs ON gs.id = s.session_id
+		WHERE gs.dm_user_id = ? OR EXISTS (
+			SELECT 1 FROM game_participants gp WHERE gp.session_id = s.session_id AND gp.user_id = ?)
+		ORDER BY s.starts_at, s.id`
+
+	schedules := make([]*models.SessionSchedule, 0)
+	if err := r.db.SelectContext(ctx, &schedules, r.db.Rebind(query), userID, userID); err != nil {
+		return nil, fmt.Errorf("failed to list session schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+// UpdateSchedule replaces a session schedule's timing and details
+func (r *scheduleRepository) UpdateSchedule(ctx context.Context, schedule *models.SessionSchedule) error {
+	schedule.UpdatedAt = time.Now()
+
+	query := `UPDATE session_schedules SET title = ?, notes = ?, starts_at = ?, duration_minutes = ?, timezone = ?,
+			recurrence = ?, recurrence_interval = ?, until = ?, reminder_minutes = ?, updated_at = ?
+		WHERE id = ?`
+	result, err := r.db.ExecContextRebind(ctx, query, schedule.Title, schedule.Notes, schedule.StartsAt,
+		schedule.DurationMinutes, schedule.Timezone, schedule.Recurrence, schedule.Interval, schedule.Until,
+		schedule.ReminderMinutes, schedule.UpdatedAt, schedule.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update session schedule: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteSchedule removes a session schedule along with its RSVPs
+func (r *scheduleRepository) DeleteSchedule(ctx context.Context, id string) error {
+	if _, err := r.db.ExecContextRebind(ctx, `DELETE FROM session_schedules WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete session schedule: %w", err)
+	}
+	return nil
+}
+
+// SetRSVP records a player's answer to an occurrence, replacing any earlier answer
+func (r *scheduleRepository) SetRSVP(ctx context.Context, rsvp *models.SessionRSVP) error {
+	rsvp.UpdatedAt = time.Now()
+
+	query := `INSERT INTO session_rsvps (schedule_id, occurrence_start, user_id, response, note, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (schedule_id, occurrence_start, user_id)
+		DO UPDATE SET response = excluded.response, note = excluded.note, updated_at = excluded.updated_at`
+	if _, err := r.db.ExecContextRebind(ctx, query, rsvp.ScheduleID, rsvp.StartsAt, rsvp.UserID, rsvp.Response,
+		rsvp.Note, rsvp.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to save RSVP: %w", err)
+	}
+	return nil
+}
+
+// ListRSVPs returns the answers to a schedule's occurrences starting from from up to to
+func (r *scheduleRepository) ListRSVPs(ctx context.Context, scheduleID string, from, to time.Time) ([]*models.SessionRSVP, error) {
+	query := `SELECT schedule_id, occurrence_start, user_id, response, note, updated_at FROM session_rsvps
+		WHERE schedule_id = ? AND occurrence_start >= ? AND occurrence_start <= ?
+		ORDER BY occurrence_start, user_id`
+
+	rsvps := make([]*models.SessionRSVP, 0)
+	if err := r.db.SelectContext(ctx, &rsvps, r.db.Rebind(query), scheduleID, from, to); err != nil {
+		return nil, fmt.Errorf("failed to list RSVPs: %w", err)
+	}
+	return rsvps, nil
+}
+
+// CreatePoll stores an availability poll with its options
+func (r *scheduleRepository) CreatePoll(ctx context.Context, poll *models.AvailabilityPoll) error {
+	if poll.ID == "" {
+		poll.ID = uuid.New().String()
+	}
+	poll.CreatedAt = time.Now()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `INSERT INTO availability_polls (id, session_id, title, timezone, duration_minutes, status, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	if _, err := tx.ExecContext(ctx, r.db.Rebind(query), poll.ID, poll.SessionID, poll.Title, poll.Timezone,
+		poll.DurationMinutes, poll.Status, poll.CreatedBy, poll.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create availability poll: %w", err)
+	}
+
+	query = r.db.Rebind(`INSERT INTO availability_poll_options (id, poll_id, starts_at) VALUES (?, ?, ?)`)
+	for _, option := range poll.Options {
+		if option.ID == "" {
+			option.ID = uuid.New().String()
+		}
+		option.PollID = poll.ID
+		if _, err := tx.ExecContext(ctx, query, option.ID, poll.ID, option.StartsAt); err != nil {
+			return fmt.Errorf("failed to add poll option: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetPoll returns an availability poll with its options and votes, or nil if it does not exist
+func (r *scheduleRepository) GetPoll(ctx context.Context, id string) (*models.AvailabilityPoll, error) {
+	query := `SELECT ` + availabilityPollColumns + ` FROM availability_polls WHERE id = ?`
+
+	var poll models.AvailabilityPoll
+	err := r.db.GetContext(ctx, &poll, r.db.Rebind(query), id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get availability poll: %w", err)
+	}
+	if err := r.loadOptions(ctx, []*models.AvailabilityPoll{&poll}); err != nil {
+		return nil, err
+	}
+	return &poll, nil
+}
+
+// ListPolls returns a session's availability polls, newest first
+func (r *scheduleRepository) ListPolls(ctx context.Context, sessionID string) ([]*models.AvailabilityPoll, error) {
+	query := `SELECT ` + availabilityPollColumns + ` FROM availability_polls
+		WHERE session_id = ? ORDER BY created_at DESC`
+
+	polls := make([]*models.AvailabilityPoll, 0)
+	if err := r.db.SelectContext(ctx, &polls, r.db.Rebind(query), sessionID); err != nil {
+		return nil, fmt.Errorf("failed to list availability polls: %w", err)
+	}
+	if err := r.loadOptions(ctx, polls); err != nil {
+		return nil, err
+	}
+	return polls, nil
+}
+
+// SetVotes records a member's availability for poll options, replacing earlier votes
+func (r *scheduleRepository) SetVotes(ctx context.Context, votes []*models.PollVote) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := r.db.Rebind(`INSERT INTO availability_poll_votes (option_id, user_id, response) VALUES (?, ?, ?)
+		ON CONFLICT (option_id, user_id) DO UPDATE SET response = excluded.response`)
+	for _, vote := range votes {
+		if _, err := tx.ExecContext(ctx, query, vote.OptionID, vote.UserID, vote.Response); err != nil {
+			return fmt.Errorf("failed to save poll vote: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ClosePoll stops a poll taking votes, recording the option chosen and the session booked on it
+func (r *scheduleRepository) ClosePoll(ctx context.Context, poll *models.AvailabilityPoll) error {
+	query := `UPDATE availability_polls SET status = ?, chosen_option_id = ?, schedule_id = ?, closed_at = ?
+		WHERE id = ?`
+	if _, err := r.db.ExecContextRebind(ctx, query, poll.Status, poll.ChosenOptionID, poll.ScheduleID,
+		poll.ClosedAt, poll.ID); err != nil {
+		return fmt.Errorf("failed to close availability poll: %w", err)
+	}
+	return nil
+}
+
+// GetCalendarToken returns the token of the user's calendar feed, or "" if they have none
+func (r *scheduleRepository) GetCalendarToken(ctx context.Context, userID string) (string, error) {
+	var token string
+	err := r.db.GetContext(ctx, &token, r.db.Rebind(`SELECT token FROM calendar_feeds WHERE user_id = ?`), userID)
+	if err != nil && err != sql.ErrNoRows {
+		return "", fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+	return token, nil
+}
+
+// SetCalendarToken gives the user's calendar feed a new token, retiring the old one
+func (r *scheduleRepository) SetCalendarToken(ctx context.Context, userID, token string) error {
+	query := `INSERT INTO calendar_feeds (user_id, token, created_at) VALUES (?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET token = excluded.token, created_at = excluded.created_at`
+	if _, err := r.db.ExecContextRebind(ctx, query, userID, token, time.Now()); err != nil {
+		return fmt.Errorf("failed to save calendar feed: %w", err)
+	}
+	return nil
+}
+
+// GetCalendarUser returns the user a calendar feed token belongs to, or "" if none does
+func (r *scheduleRepository) GetCalendarUser(ctx context.Context, token string) (string, error) {
+	var userID string
+	err := r.db.GetContext(ctx, &userID, r.db.Rebind(`SELECT user_id FROM calendar_feeds WHERE token = ?`), token)
+	if err != nil && err != sql.ErrNoRows {
+		return "", fmt.Errorf("failed to get calendar feed: %w", err)
+	}
+	return userID, nil
+}
+
+// loadOptions fills in each poll's options, earliest first, and the votes for them
+func (r *scheduleRepository) loadOptions(ctx context.Context, polls []*models.AvailabilityPoll) error {
+	if len(polls) == 0 {
+		return nil
+	}
+	byID := make(map[string]*models.AvailabilityPoll, len(polls))
+	ids := make([]string, 0, len(polls))
+	for _, poll := range polls {
+		poll.Options = make([]*models.PollOption, 0)
+		byID[poll.ID] = poll
+		ids = append(ids, poll.ID)
+	}
+
+	query, args, err := sqlx.In(`SELECT id, poll_id, starts_at FROM availability_poll_options
+		WHERE poll_id IN (?) ORDER BY starts_at, id`, ids)
+	if err != nil {
+		return err
+	}
+	var options []*models.PollOption
+	if err := r.db.SelectContext(ctx, &options, r.db.Rebind(query), args...); err != nil {
+		return fmt.Errorf("failed to load poll options: %w", err)
+	}
+	if len(options) == 0 {
+		return nil
+	}
+
+	optionsByID := make(map[string]*models.PollOption, len(options))
+	optionIDs := make([]string, 0, len(options))
+	for _, option := range options {
+		option.Votes = make([]*models.PollVote, 0)
+		optionsByID[option.ID] = option
+		optionIDs = append(optionIDs, option.ID)
+		poll := byID[option.PollID]
+		poll.Options = append(poll.Options, option)
+	}
+
+	query, args, err = sqlx.In(`SELECT option_id, user_id, response FROM availability_poll_votes
+		WHERE option_id IN (?) ORDER BY user_id`, optionIDs)
+	if err != nil {
+		return err
+	}
+	var votes []*models.PollVote
+	if err := r.db.SelectContext(ctx, &votes, r.db.Rebind(query), args...); err != nil {
+		return fmt.Errorf("failed to load poll votes: %w", err)
+	}
+	for _, vote := range votes {
+		option := optionsByID[vote.OptionID]
+		option.Votes = append(option.Votes, vote)
+	}
+	return nil
+}
@@ -338,6 +338,66 @@ func (r *WorldBuildingRepository) GetSettlementShops(settlementID uuid.UUID) ([]
 	return shops, nil
 }
 
+// GetSettlementShop retrieves a single shop by ID
+func (r *WorldBuildingRepository) GetSettlementShop(id uuid.UUID) (*models.SettlementShop, error) {
+	var shop models.SettlementShop
+	var availableItems, specialItems, craftingSpecialties, factionDiscount, operatingHours, currentRumors []byte
+
+	query := `
+		SELECT id, settlement_id, name, type, owner_npc_id, quality_level, price_modifier,
+			available_items, special_items, can_craft, crafting_specialties,
+			black_market, ancient_artifacts, faction_discount,
+			reputation_required, operating_hours, current_rumors, created_at
+		FROM settlement_shops WHERE id = ?`
+
+	err := r.db.QueryRowRebind(query, id).Scan(
+		&shop.ID, &shop.SettlementID, &shop.Name, &shop.Type, &shop.OwnerNPCID,
+		&shop.QualityLevel, &shop.PriceModifier, &availableItems, &specialItems,
+		&shop.CanCraft, &craftingSpecialties, &shop.BlackMarket, &shop.AncientArtifacts,
+		&factionDiscount, &shop.ReputationRequired, &operatingHours, &currentRumors,
+		&shop.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	shop.AvailableItems = models.JSONB(availableItems)
+	shop.SpecialItems = models.JSONB(specialItems)
+	shop.CraftingSpecialties = models.JSONB(craftingSpecialties)
+	shop.FactionDiscount = models.JSONB(factionDiscount)
+	shop.OperatingHours = models.JSONB(operatingHours)
+	shop.CurrentRumors = models.JSONB(currentRumors)
+
+	return &shop, nil
+}
+
+// GetShopStock retrieves everything a shop has in stock
+func (r *WorldBuildingRepository) GetShopStock(shopID uuid.UUID) ([]*models.ShopStockItem, error) {
+	query := `
+		SELECT shop_id, item_id, quantity, updated_at
+		FROM shop_stock
+		WHERE shop_id = ? AND quantity > 0
+		ORDER BY item_id`
+
+	stock := make([]*models.ShopStockItem, 0, 20)
+	if err := r.db.Select(&stock, r.db.Rebind(query), shopID); err != nil {
+		return nil, err
+	}
+	return stock, nil
+}
+
+// SetShopStock sets how many of an item a shop holds
+func (r *WorldBuildingRepository) SetShopStock(shopID uuid.UUID, itemID string, quantity int) error {
+	query := `
+		INSERT INTO shop_stock (shop_id, item_id, quantity, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (shop_id, item_id)
+		DO UPDATE SET quantity = excluded.quantity, updated_at = excluded.updated_at`
+
+	_, err := r.db.ExecRebind(query, shopID, itemID, quantity, time.Now())
+	return err
+}
+
 // Faction operations
 
 // CreateFaction creates a new faction
@@ -624,12 +684,18 @@ func (r *WorldBuildingRepository) CreateOrUpdateMarket(market *models.Market) er
 		) VALUES (
 			?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 		) ON CONFLICT (settlement_id) DO UPDATE SET
-			food_price_modifier = ?, common_goods_modifier = ?,
-			weapons_armor_modifier = ?, magical_items_modifier = ?,
-			ancient_artifacts_modifier = ?, high_demand_items = ?,
-			surplus_items = ?, banned_items = ?, black_market_active = ?,
-			artifact_dealer_present = ?, economic_boom = ?,
-			economic_depression = ?, last_updated = ?`
+			food_price_modifier = excluded.food_price_modifier,
+			common_goods_modifier = excluded.common_goods_modifier,
+			weapons_armor_modifier = excluded.weapons_armor_modifier,
+			magical_items_modifier = excluded.magical_items_modifier,
+			ancient_artifacts_modifier = excluded.ancient_artifacts_modifier,
+			high_demand_items = excluded.high_demand_items,
+			surplus_items = excluded.surplus_items, banned_items = excluded.banned_items,
+			black_market_active = excluded.black_market_active,
+			artifact_dealer_present = excluded.artifact_dealer_present,
+			economic_boom = excluded.economic_boom,
+			economic_depression = excluded.economic_depression,
+			last_updated = excluded.last_updated`
 
 	_, err := r.db.ExecRebind(query,
 		market.ID, market.SettlementID,
@@ -677,6 +743,45 @@ func (r *WorldBuildingRepository) GetMarketBySettlement(settlementID uuid.UUID)
 	return &market, nil
 }
 
+// RecordMarketTrade adds units players bought from and sold to a settlement's market
+func (r *WorldBuildingRepository) RecordMarketTrade(settlementID uuid.UUID, category string, bought, sold int) error {
+	query := `
+		INSERT INTO market_trade_volume (settlement_id, category, units_bought, units_sold, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (settlement_id, category) DO UPDATE SET
+			units_bought = market_trade_volume.units_bought + excluded.units_bought,
+			units_sold = market_trade_volume.units_sold + excluded.units_sold,
+			updated_at = excluded.updated_at`
+
+	_, err := r.db.ExecRebind(query, settlementID, category, bought, sold, time.Now())
+	return err
+}
+
+// GetMarketTradeVolumes retrieves player trade volumes for a settlement's market
+func (r *WorldBuildingRepository) GetMarketTradeVolumes(settlementID uuid.UUID) ([]*models.MarketTradeVolume, error) {
+	query := `
+		SELECT settlement_id, category, units_bought, units_sold, updated_at
+		FROM market_trade_volume
+		WHERE settlement_id = ?`
+
+	volumes := make([]*models.MarketTradeVolume, 0, 10)
+	if err := r.db.Select(&volumes, r.db.Rebind(query), settlementID); err != nil {
+		return nil, err
+	}
+	return volumes, nil
+}
+
+// DecayMarketTradeVolumes halves player trade volumes so old trades stop moving prices
+func (r *WorldBuildingRepository) DecayMarketTradeVolumes(settlementID uuid.UUID) error {
+	query := `
+		UPDATE market_trade_volume
+		SET units_bought = units_bought / 2, units_sold = units_sold / 2, updated_at = ?
+		WHERE settlement_id = ?`
+
+	_, err := r.db.ExecRebind(query, time.Now(), settlementID)
+	return err
+}
+
 // Trade Route operations
 
 // CreateTradeRoute creates a new trade route
@@ -9,6 +9,7 @@ import (
 	"github.com/ctclostio/DnD-Game/backend/internal/auth"
 	"github.com/ctclostio/DnD-Game/backend/internal/constants"
 	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/internal/services"
 	"github.com/ctclostio/DnD-Game/backend/pkg/response"
 )
 
@@ -110,7 +111,13 @@ func (h *Handlers) UpdateCharacter(w http.ResponseWriter, r *http.Request) {
 	character.ID = id
 	character.UserID = userID // Ensure user can't change ownership
 
-	if err := h.characterService.UpdateCharacter(r.Context(), &character); err != nil {
+	// An optional reason is stored with the version history entry
+	ctx := r.Context()
+	if reason := r.URL.Query().Get("reason"); reason != "" {
+		ctx = services.WithCharacterChange(ctx, "", reason)
+	}
+
+	if err := h.characterService.UpdateCharacter(ctx, &character); err != nil {
 		response.InternalServerError(w, r, err)
 		return
 	}
@@ -162,13 +169,33 @@ func (h *Handlers) CastSpell(w http.ResponseWriter, r *http.Request) {
 	characterID := vars["id"]
 
 	var req struct {
-		SpellLevel int `json:"spellLevel"`
+		SpellLevel int    `json:"spellLevel"`
+		Spell      string `json:"spell"`
+		Ritual     bool   `json:"ritual"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
 		return
 	}
 
+	// Named spells go through spell management for preparation, ritual and component rules
+	if req.Spell != "" {
+		if !h.authorizeSpellManagement(w, r, characterID) {
+			return
+		}
+		result, err := h.spellService.CastSpell(r.Context(), characterID, &models.CastSpellRequest{
+			Spell:     req.Spell,
+			SlotLevel: req.SpellLevel,
+			Ritual:    req.Ritual,
+		})
+		if err != nil {
+			response.BadRequest(w, r, err.Error())
+			return
+		}
+		response.JSON(w, r, http.StatusOK, result)
+		return
+	}
+
 	err := h.characterService.UseSpellSlot(r.Context(), characterID, req.SpellLevel)
 	if err != nil {
 		response.BadRequest(w, r, err.Error())
@@ -329,16 +356,43 @@ func (h *Handlers) Rest(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	characterID := vars["id"]
 
+	if _, ok := h.authorizeCharacterAccess(w, r, characterID, characterOwnerOnly); !ok {
+		return
+	}
+
 	var req struct {
-		RestType string `json:"restType"` // "short" or "long"
+		RestType string `json:"restType"` // "short", "long" or "dawn"
+		HitDice  int    `json:"hitDice"`  // hit dice to spend on a short rest
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
 		return
 	}
 
-	err := h.characterService.RestoreSpellSlots(r.Context(), characterID, req.RestType)
-	if err != nil {
+	// Rest through the resource ledger when it is available
+	if h.resourceService != nil {
+		var result *models.RestResult
+		var err error
+		switch req.RestType {
+		case models.RestTypeShort:
+			result, err = h.resourceService.ShortRest(r.Context(), characterID, req.HitDice)
+		case models.RestTypeLong:
+			result, err = h.resourceService.LongRest(r.Context(), characterID)
+		case models.RestTypeDawn:
+			result, err = h.resourceService.Dawn(r.Context(), characterID)
+		default:
+			response.BadRequest(w, r, "invalid rest type: "+req.RestType)
+			return
+		}
+		if err != nil {
+			response.BadRequest(w, r, err.Error())
+			return
+		}
+		response.JSON(w, r, http.StatusOK, result)
+		return
+	}
+
+	if err := h.characterService.RestoreSpellSlots(r.Context(), characterID, req.RestType); err != nil {
 		response.BadRequest(w, r, err.Error())
 		return
 	}
@@ -361,3 +415,34 @@ func (h *Handlers) Rest(w http.ResponseWriter, r *http.Request) {
 
 	response.JSON(w, r, http.StatusOK, char)
 }
+
+// characterAccess is who besides a character's owner may use a character route
+type characterAccess int
+
+const (
+	characterOwnerOnly characterAccess = iota
+	// characterOwnerOrDM also admits the DM of a session the character plays in
+	characterOwnerOrDM
+)
+
+// authorizeCharacterAccess loads the character and checks the authenticated user may use
+// it, writing the error response and returning false if not
+func (h *Handlers) authorizeCharacterAccess(w http.ResponseWriter, r *http.Request, characterID string, access characterAccess) (*models.Character, bool) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, r, "Unauthorized")
+		return nil, false
+	}
+
+	character, err := h.characterService.GetCharacterByID(r.Context(), characterID)
+	if err != nil {
+		response.NotFound(w, r, err.Error())
+		return nil, false
+	}
+	if character.UserID == userID || (access == characterOwnerOrDM && h.isDMForCharacter(r, userID, characterID)) {
+		return character, true
+	}
+
+	response.Forbidden(w, r, "You don't have permission to access this character")
+	return nil, false
+}
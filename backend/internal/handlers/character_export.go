@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/auth"
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/response"
+)
+
+// ExportCharacter handles GET /api/characters/{id}/export?format=json|pdf
+func (h *Handlers) ExportCharacter(w http.ResponseWriter, r *http.Request) {
+	characterID := mux.Vars(r)["id"]
+	if h.exportService == nil {
+		response.BadRequest(w, r, "Character export is not available")
+		return
+	}
+	character, ok := h.authorizeCharacterAccess(w, r, characterID, characterOwnerOrDM)
+	if !ok {
+		return
+	}
+	fileName := characterExportFileName(character.Name)
+
+	switch models.CharacterExportFormat(r.URL.Query().Get("format")) {
+	case "", models.CharacterExportJSON:
+		doc, err := h.exportService.ExportCharacter(r.Context(), characterID)
+		if err != nil {
+			response.InternalServerError(w, r, err)
+			return
+		}
+		w.Header().Set(constants.ContentType, constants.ApplicationJSON)
+		w.Header().Set("Content-Disposition", "attachment; filename="+fileName+".json")
+		if err := json.NewEncoder(w).Encode(doc); err != nil {
+			http.Error(w, constants.ErrFailedToEncode, http.StatusInternalServerError)
+			return
+		}
+	case models.CharacterExportPDF:
+		pdf, err := h.exportService.RenderCharacterSheet(r.Context(), characterID)
+		if err != nil {
+			response.InternalServerError(w, r, err)
+			return
+		}
+		w.Header().Set(constants.ContentType, "application/pdf")
+		w.Header().Set("Content-Disposition", "attachment; filename="+fileName+".pdf")
+		_, _ = w.Write(pdf)
+	default:
+		response.BadRequest(w, r, "Unsupported export format")
+	}
+}
+
+// ImportCharacter handles POST /api/characters/import with an interchange document
+func (h *Handlers) ImportCharacter(w http.ResponseWriter, r *http.Request) {
+	if h.exportService == nil {
+		response.BadRequest(w, r, "Character import is not available")
+		return
+	}
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	var doc models.CharacterExportDocument
+	if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+
+	result, err := h.exportService.ImportCharacter(r.Context(), userID, &doc)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusCreated, result)
+}
+
+// GetCharacterExportSchema handles GET /api/characters/export-schema
+func (h *Handlers) GetCharacterExportSchema(w http.ResponseWriter, r *http.Request) {
+	if h.exportService == nil {
+		response.BadRequest(w, r, "Character export is not available")
+		return
+	}
+
+	schema, err := h.exportService.Schema()
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+	w.Header().Set(constants.ContentType, "application/schema+json")
+	_, _ = w.Write(schema)
+}
+
+// characterExportFileName keeps letters and digits from a character name for download file names
+func characterExportFileName(name string) string {
+	fileName := strings.Trim(strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, name), "-")
+	if fileName == "" {
+		return "character"
+	}
+	return fileName
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/response"
+)
+
+// GetCharacterResources returns the class resource ledger and exhaustion for a character
+func (h *Handlers) GetCharacterResources(w http.ResponseWriter, r *http.Request) {
+	characterID := mux.Vars(r)["id"]
+	if !h.authorizeCharacterResources(w, r, characterID) {
+		return
+	}
+
+	resources, err := h.resourceService.GetResources(r.Context(), characterID)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+	state, err := h.resourceService.GetRestState(r.Context(), characterID)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, map[string]interface{}{
+		"resources": resources,
+		"restState": state,
+	})
+}
+
+// UseCharacterResource spends uses of a resource
+func (h *Handlers) UseCharacterResource(w http.ResponseWriter, r *http.Request) {
+	h.adjustCharacterResource(w, r, true)
+}
+
+// RestoreCharacterResource regains uses of a resource
+func (h *Handlers) RestoreCharacterResource(w http.ResponseWriter, r *http.Request) {
+	h.adjustCharacterResource(w, r, false)
+}
+
+func (h *Handlers) adjustCharacterResource(w http.ResponseWriter, r *http.Request, use bool) {
+	vars := mux.Vars(r)
+	characterID := vars["id"]
+	key := vars["key"]
+
+	var req struct {
+		Amount int `json:"amount"`
+	}
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+			return
+		}
+	}
+
+	if !h.authorizeCharacterResources(w, r, characterID) {
+		return
+	}
+
+	var resource *models.CharacterResource
+	var err error
+	if use {
+		resource, err = h.resourceService.UseResource(r.Context(), characterID, key, req.Amount)
+	} else {
+		resource, err = h.resourceService.RestoreResource(r.Context(), characterID, key, req.Amount)
+	}
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, resource)
+}
+
+// SetCustomCharacterResource creates or updates a custom resource
+func (h *Handlers) SetCustomCharacterResource(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	characterID := vars["id"]
+
+	var resource models.CharacterResource
+	if err := json.NewDecoder(r.Body).Decode(&resource); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+	resource.Key = vars["key"]
+
+	if !h.authorizeCharacterResources(w, r, characterID) {
+		return
+	}
+
+	saved, err := h.resourceService.SetCustomResource(r.Context(), characterID, &resource)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, saved)
+}
+
+// SetExhaustion sets a character's exhaustion level
+func (h *Handlers) SetExhaustion(w http.ResponseWriter, r *http.Request) {
+	characterID := mux.Vars(r)["id"]
+
+	var req struct {
+		Level int `json:"level"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+
+	if !h.authorizeCharacterResources(w, r, characterID) {
+		return
+	}
+
+	state, err := h.resourceService.SetExhaustion(r.Context(), characterID, req.Level)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, state)
+}
+
+// authorizeCharacterResources checks the service is available and the character
+// belongs to the authenticated user, writing the error response if not.
+func (h *Handlers) authorizeCharacterResources(w http.ResponseWriter, r *http.Request, characterID string) bool {
+	if h.resourceService == nil {
+		response.BadRequest(w, r, "Character resources are not available")
+		return false
+	}
+	_, ok := h.authorizeCharacterAccess(w, r, characterID, characterOwnerOnly)
+	return ok
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ctclostio/DnD-Game/backend/pkg/response"
+)
+
+// GetCharacterVersions lists a character's version history, newest first
+func (h *Handlers) GetCharacterVersions(w http.ResponseWriter, r *http.Request) {
+	characterID := mux.Vars(r)["id"]
+	if !h.authorizeCharacterHistory(w, r, characterID) {
+		return
+	}
+
+	versions, err := h.versionService.ListVersions(r.Context(), characterID)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, versions)
+}
+
+// GetCharacterVersion returns a single version including its full snapshot
+func (h *Handlers) GetCharacterVersion(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	characterID := vars["id"]
+	version, err := strconv.Atoi(vars["version"])
+	if err != nil {
+		response.BadRequest(w, r, "Invalid version")
+		return
+	}
+	if !h.authorizeCharacterHistory(w, r, characterID) {
+		return
+	}
+
+	v, err := h.versionService.GetVersion(r.Context(), characterID, version)
+	if err != nil {
+		response.NotFound(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, v)
+}
+
+// DiffCharacterVersions compares two versions field by field (?from=1&to=2)
+func (h *Handlers) DiffCharacterVersions(w http.ResponseWriter, r *http.Request) {
+	characterID := mux.Vars(r)["id"]
+	from, fromErr := strconv.Atoi(r.URL.Query().Get("from"))
+	to, toErr := strconv.Atoi(r.URL.Query().Get("to"))
+	if fromErr != nil || toErr != nil {
+		response.BadRequest(w, r, "from and to versions are required")
+		return
+	}
+	if !h.authorizeCharacterHistory(w, r, characterID) {
+		return
+	}
+
+	diff, err := h.versionService.DiffVersions(r.Context(), characterID, from, to)
+	if err != nil {
+		response.NotFound(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, diff)
+}
+
+// RestoreCharacterVersion rolls a character back to a prior version
+func (h *Handlers) RestoreCharacterVersion(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	characterID := vars["id"]
+	version, err := strconv.Atoi(vars["version"])
+	if err != nil {
+		response.BadRequest(w, r, "Invalid version")
+		return
+	}
+	if !h.authorizeCharacterHistory(w, r, characterID) {
+		return
+	}
+
+	character, err := h.versionService.RestoreVersion(r.Context(), characterID, version)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, character)
+}
+
+// AuditCharacterChanges shows what changed on a character since a session ended (?sessionId=)
+// or since a given time (?since=RFC3339)
+func (h *Handlers) AuditCharacterChanges(w http.ResponseWriter, r *http.Request) {
+	characterID := mux.Vars(r)["id"]
+	if !h.authorizeCharacterHistory(w, r, characterID) {
+		return
+	}
+
+	var since time.Time
+	query := r.URL.Query()
+	switch {
+	case query.Get("sessionId") != "":
+		session, err := h.gameService.GetSessionByID(r.Context(), query.Get("sessionId"))
+		if err != nil {
+			response.NotFound(w, r, "Game session not found")
+			return
+		}
+		if session.EndedAt == nil {
+			response.BadRequest(w, r, "Game session has not ended")
+			return
+		}
+		since = *session.EndedAt
+	case query.Get("since") != "":
+		parsed, err := time.Parse(time.RFC3339, query.Get("since"))
+		if err != nil {
+			response.BadRequest(w, r, "since must be an RFC3339 timestamp")
+			return
+		}
+		since = parsed
+	default:
+		response.BadRequest(w, r, "sessionId or since is required")
+		return
+	}
+
+	audit, err := h.versionService.AuditSince(r.Context(), characterID, since)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, audit)
+}
+
+// authorizeCharacterHistory allows the character's owner, or the DM of a session the character plays in
+func (h *Handlers) authorizeCharacterHistory(w http.ResponseWriter, r *http.Request, characterID string) bool {
+	if h.versionService == nil {
+		response.BadRequest(w, r, "Character history is not available")
+		return false
+	}
+	_, ok := h.authorizeCharacterAccess(w, r, characterID, characterOwnerOrDM)
+	return ok
+}
+
+// isDMForCharacter reports whether the user runs a session the character has joined
+func (h *Handlers) isDMForCharacter(r *http.Request, userID, characterID string) bool {
+	sessions, err := h.gameService.GetSessionsByDM(r.Context(), userID)
+	if err != nil {
+		return false
+	}
+	for _, session := range sessions {
+		participants, err := h.gameService.GetSessionParticipants(r.Context(), session.ID)
+		if err != nil {
+			continue
+		}
+		for _, p := range participants {
+			if p.CharacterID != nil && *p.CharacterID == characterID {
+				return true
+			}
+		}
+	}
+	return false
+}
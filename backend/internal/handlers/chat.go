@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/auth"
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/internal/websocket"
+	"github.com/ctclostio/DnD-Game/backend/pkg/response"
+)
+
+// SendChatMessage handles POST /api/game/sessions/{id}/chat
+func (h *Handlers) SendChatMessage(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if err := validateUserSession(w, r, h.gameService, sessionID); err != nil {
+		return
+	}
+	claims, _ := auth.GetUserFromContext(r.Context())
+
+	var req models.ChatMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+
+	message, err := h.chatService.SendMessage(r.Context(), sessionID, claims.UserID, claims.Username, &req)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	h.broadcastChatMessage(message)
+	response.JSON(w, r, http.StatusCreated, message)
+}
+
+// GetChatHistory handles GET /api/game/sessions/{id}/chat, newest first. It takes the
+// channel, search, before and limit query parameters; before is the nextCursor of the
+// previous page.
+func (h *Handlers) GetChatHistory(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if err := validateUserSession(w, r, h.gameService, sessionID); err != nil {
+		return
+	}
+	userID, _ := auth.GetUserIDFromContext(r.Context())
+
+	query := r.URL.Query()
+	filter := models.ChatFilter{
+		Channel: models.ChatChannel(query.Get("channel")),
+		Search:  query.Get("search"),
+		Before:  query.Get("before"),
+	}
+	if limit := query.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed < 1 {
+			response.BadRequest(w, r, "limit must be a positive number")
+			return
+		}
+		filter.Limit = parsed
+	}
+
+	page, err := h.chatService.GetHistory(r.Context(), sessionID, userID, filter)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, page)
+}
+
+// broadcastChatMessage delivers a chat message to the session's room; a whisper only
+// reaches its sender and recipients
+func (h *Handlers) broadcastChatMessage(message *models.ChatMessage) {
+	if h.websocketHub == nil {
+		return
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+	msgBytes, err := json.Marshal(websocket.Message{
+		Type:     wsEventChat,
+		RoomID:   message.SessionID,
+		PlayerID: message.UserID,
+		Username: message.Username,
+		To:       chatAudience(message),
+		Data:     data,
+	})
+	if err != nil {
+		return
+	}
+
+	h.websocketHub.Broadcast(msgBytes)
+}
+
+// chatAudience is who a chat message is delivered to: nil for everyone, or the sender
+// and recipients of a whisper
+func chatAudience(message *models.ChatMessage) []string {
+	if !message.IsWhisper() {
+		return nil
+	}
+	return append([]string{message.UserID}, message.Recipients...)
+}
@@ -72,7 +72,7 @@ func (h *Handlers) GetCombat(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response.JSON(w, r, http.StatusOK, combat)
+	h.respondWithCombat(w, r, combat)
 }
 
 func (h *Handlers) GetCombatBySession(w http.ResponseWriter, r *http.Request) {
@@ -85,6 +85,27 @@ func (h *Handlers) GetCombatBySession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.respondWithCombat(w, r, combat)
+}
+
+// respondWithCombat writes a combat as the caller may see it: whole for its session's DM
+// and players, and without hidden combatants for spectators
+func (h *Handlers) respondWithCombat(w http.ResponseWriter, r *http.Request, combat *models.Combat) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, r, "")
+		return
+	}
+
+	role, err := h.gameService.ParticipantRole(r.Context(), combat.GameSessionID, userID)
+	if err != nil {
+		response.Forbidden(w, r, "You don't have access to this game session")
+		return
+	}
+	if role == models.ParticipantRoleSpectator {
+		combat = combat.SpectatorView()
+	}
+
 	response.JSON(w, r, http.StatusOK, combat)
 }
 
@@ -126,9 +147,10 @@ func (h *Handlers) NextTurn(w http.ResponseWriter, r *http.Request) {
 
 	// Broadcast turn change
 	h.broadcastCombatUpdate(combat.GameSessionID, models.CombatUpdate{
-		Type:    models.UpdateTypeTurnStart,
-		Combat:  updatedCombat,
-		Message: combatant.Name + "'s turn",
+		Type:        models.UpdateTypeTurnStart,
+		Combat:      updatedCombat,
+		CombatantID: combatant.ID,
+		Message:     combatant.Name + "'s turn",
 	})
 
 	response.JSON(w, r, http.StatusOK, map[string]interface{}{
@@ -158,6 +180,17 @@ func (h *Handlers) ProcessCombatAction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Only the DM and players act; spectators only watch, even with a character in the fight
+	role, err := h.gameService.ParticipantRole(r.Context(), combat.GameSessionID, claims.UserID)
+	if err != nil {
+		response.Forbidden(w, r, "User is not a participant in this game session")
+		return
+	}
+	if role == models.ParticipantRoleSpectator {
+		response.Forbidden(w, r, "Spectators cannot take actions")
+		return
+	}
+
 	// Verify user can control the actor
 	if !h.canControlCombatant(r.Context(), claims.UserID, combat, request.ActorID) {
 		response.ErrorWithCode(w, r, errors.ErrCodeInsufficientPrivilege, "You cannot control this combatant")
@@ -325,9 +358,10 @@ func (h *Handlers) ApplyDamage(w http.ResponseWriter, r *http.Request) {
 
 	// Broadcast HP change
 	h.broadcastCombatUpdate(combat.GameSessionID, models.CombatUpdate{
-		Type:    models.UpdateTypeHPChange,
-		Combat:  updatedCombat,
-		Message: fmt.Sprintf("%s takes %d damage", combatantName, totalDamage),
+		Type:        models.UpdateTypeHPChange,
+		Combat:      updatedCombat,
+		CombatantID: combatantID,
+		Message:     fmt.Sprintf("%s takes %d damage", combatantName, totalDamage),
 	})
 
 	response.JSON(w, r, http.StatusOK, map[string]int{"totalDamage": totalDamage})
@@ -384,9 +418,10 @@ func (h *Handlers) HealCombatant(w http.ResponseWriter, r *http.Request) {
 
 	// Broadcast HP change
 	h.broadcastCombatUpdate(combat.GameSessionID, models.CombatUpdate{
-		Type:    models.UpdateTypeHPChange,
-		Combat:  updatedCombat,
-		Message: fmt.Sprintf("%s heals for %d HP", combatantName, req.Healing),
+		Type:        models.UpdateTypeHPChange,
+		Combat:      updatedCombat,
+		CombatantID: combatantID,
+		Message:     fmt.Sprintf("%s heals for %d HP", combatantName, req.Healing),
 	})
 
 	response.JSON(w, r, http.StatusOK, map[string]string{"message": "Healing applied"})
@@ -407,9 +442,12 @@ func (h *Handlers) canControlCombatant(ctx context.Context, userID string, comba
 		return false
 	}
 
-	// DM can control all combatants
-	session, err := h.gameService.GetGameSession(ctx, combat.GameSessionID)
-	if err == nil && session.DMID == userID {
+	// DM can control all combatants, and spectators none
+	role, err := h.gameService.ParticipantRole(ctx, combat.GameSessionID, userID)
+	if err != nil || role == models.ParticipantRoleSpectator {
+		return false
+	}
+	if role == models.ParticipantRoleDM {
 		return true
 	}
 
@@ -424,11 +462,44 @@ func (h *Handlers) canControlCombatant(ctx context.Context, userID string, comba
 	return false
 }
 
-// Helper function to broadcast combat updates
+// broadcastCombatUpdate sends a combat update to the session's room. While the DM keeps
+// combatants hidden, the DM and players are sent the update as it is and spectators are
+// sent their view of it.
 func (h *Handlers) broadcastCombatUpdate(gameSessionID string, update models.CombatUpdate) {
+	if update.Combat == nil || len(update.Combat.HiddenCombatants()) == 0 {
+		h.sendCombatUpdate(gameSessionID, update, nil)
+		return
+	}
+
+	participants, err := h.gameService.GetSessionParticipants(context.Background(), gameSessionID)
+	if err != nil {
+		// Without knowing who is spectating, everyone gets what spectators may see
+		h.sendCombatUpdate(gameSessionID, update.SpectatorView(), nil)
+		return
+	}
+	var players, spectators []string
+	for _, p := range participants {
+		if p.Role == models.ParticipantRoleSpectator {
+			spectators = append(spectators, p.UserID)
+		} else {
+			players = append(players, p.UserID)
+		}
+	}
+	if len(spectators) == 0 {
+		h.sendCombatUpdate(gameSessionID, update, nil)
+		return
+	}
+	h.sendCombatUpdate(gameSessionID, update, players)
+	h.sendCombatUpdate(gameSessionID, update.SpectatorView(), spectators)
+}
+
+// sendCombatUpdate sends a combat update to some users in the session's room, or to
+// everyone when to is empty
+func (h *Handlers) sendCombatUpdate(gameSessionID string, update models.CombatUpdate, to []string) {
 	message := websocket.Message{
 		Type:   "combat",
 		RoomID: gameSessionID,
+		To:     to,
 		Data:   nil,
 	}
 
@@ -394,6 +394,7 @@ func TestCombatAuthorization_Integration(t *testing.T) {
 
 	authMiddleware := auth.NewMiddleware(ctx.JWTManager)
 	api.HandleFunc("/combat/start", authMiddleware.Authenticate(h.StartCombat)).Methods("POST")
+	api.HandleFunc("/combat/{combatId}/action", authMiddleware.Authenticate(h.ProcessCombatAction)).Methods("POST")
 
 	// Create test users
 	dmID := ctx.CreateTestUser("auth_dm", "auth_dm@test.com", "password123")
@@ -476,6 +477,29 @@ func TestCombatAuthorization_Integration(t *testing.T) {
 		assert.NotEqual(t, http.StatusOK, w.Code)
 	})
 
+	t.Run("Spectator Cannot Act", func(t *testing.T) {
+		spectatorID := ctx.CreateTestUser("auth_spectator", "auth_spectator@test.com", "password123")
+		_, err := ctx.SQLXDB.Exec(ctx.SQLXDB.Rebind(`INSERT INTO game_participants (id, session_id, user_id, is_online, role) VALUES (?, ?, ?, ?, ?)`),
+			"participant-spectator", sessionID, spectatorID, false, models.ParticipantRoleSpectator)
+		require.NoError(t, err)
+		spectatorToken, _ := ctx.JWTManager.GenerateTokenPair(spectatorID, "auth_spectator", "auth_spectator@test.com", "player")
+
+		combat, err := svc.Combat.StartCombat(context.Background(), sessionID, []models.Combatant{
+			{ID: "dummy", Name: "Dummy", Type: models.CombatantTypeNPC, Initiative: 10, HP: 10, MaxHP: 10, AC: 10},
+		})
+		require.NoError(t, err)
+
+		body, _ := json.Marshal(models.CombatRequest{ActorID: "dummy", Action: models.ActionTypeEndTurn})
+		req := httptest.NewRequest("POST", constants.CombatByIDPath+combat.ID+"/action", bytes.NewBuffer(body))
+		req.Header.Set(constants.ContentType, constants.ApplicationJSON)
+		req.Header.Set("Authorization", constants.Bearer+spectatorToken.AccessToken)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
 	t.Run("Player Cannot Act for Another Player's Character", func(t *testing.T) {
 		// This would be tested in the ExecuteCombatAction endpoint
 		// Skipping for now as it requires a running combat
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/auth"
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/response"
+)
+
+// ListRecipes handles GET /api/recipes, the built-in crafting recipes
+func (h *Handlers) ListRecipes(w http.ResponseWriter, r *http.Request) {
+	recipes, err := h.craftingService.ListRecipes(r.Context(), "")
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, recipes)
+}
+
+// ListSessionRecipes handles GET /api/game/sessions/{id}/recipes, the built-in recipes plus the session's homebrew
+func (h *Handlers) ListSessionRecipes(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if err := validateUserSession(w, r, h.gameService, sessionID); err != nil {
+		return
+	}
+
+	recipes, err := h.craftingService.ListRecipes(r.Context(), sessionID)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, recipes)
+}
+
+// CreateHomebrewRecipe handles POST /api/game/sessions/{id}/recipes
+func (h *Handlers) CreateHomebrewRecipe(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if !h.authorizeSessionDM(w, r, sessionID) {
+		return
+	}
+
+	var recipe models.Recipe
+	if err := json.NewDecoder(r.Body).Decode(&recipe); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+	if err := h.craftingService.CreateHomebrewRecipe(r.Context(), sessionID, &recipe); err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusCreated, recipe)
+}
+
+// DeleteHomebrewRecipe handles DELETE /api/game/sessions/{id}/recipes/{recipeId}
+func (h *Handlers) DeleteHomebrewRecipe(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sessionID := vars["id"]
+	if !h.authorizeSessionDM(w, r, sessionID) {
+		return
+	}
+
+	if err := h.craftingService.DeleteHomebrewRecipe(r.Context(), sessionID, vars["recipeId"]); err != nil {
+		response.NotFound(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusNoContent, nil)
+}
+
+// ListCraftingProjects handles GET /api/characters/{id}/crafting?status=
+func (h *Handlers) ListCraftingProjects(w http.ResponseWriter, r *http.Request) {
+	characterID := mux.Vars(r)["id"]
+	if _, ok := h.authorizeCharacterAccess(w, r, characterID, characterOwnerOrDM); !ok {
+		return
+	}
+
+	status := models.CraftingStatus(r.URL.Query().Get("status"))
+	projects, err := h.craftingService.ListProjects(r.Context(), characterID, status)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, projects)
+}
+
+// StartCraftingProject handles POST /api/characters/{id}/crafting
+func (h *Handlers) StartCraftingProject(w http.ResponseWriter, r *http.Request) {
+	characterID := mux.Vars(r)["id"]
+	if _, ok := h.authorizeCharacterAccess(w, r, characterID, characterOwnerOrDM); !ok {
+		return
+	}
+
+	var req models.StartCraftingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+	if req.SessionID != "" {
+		inSession, err := h.charactersInSession(r, req.SessionID, characterID)
+		if err != nil {
+			response.InternalServerError(w, r, err)
+			return
+		}
+		if !inSession {
+			response.Forbidden(w, r, "Character is not part of this game session")
+			return
+		}
+	}
+
+	project, err := h.craftingService.StartProject(r.Context(), characterID, &req)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusCreated, project)
+}
+
+// WorkOnCraftingProject handles POST /api/crafting/{id}/work
+func (h *Handlers) WorkOnCraftingProject(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.craftingProjectForCrafter(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CraftingWorkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+
+	progress, err := h.craftingService.Work(r.Context(), project.ID, req.Days)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, progress)
+}
+
+// AbandonCraftingProject handles POST /api/crafting/{id}/abandon
+func (h *Handlers) AbandonCraftingProject(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.craftingProjectForCrafter(w, r)
+	if !ok {
+		return
+	}
+
+	project, err := h.craftingService.AbandonProject(r.Context(), project.ID)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, project)
+}
+
+// craftingProjectForCrafter loads a project that only the crafting character's owner, or their DM, may work on
+func (h *Handlers) craftingProjectForCrafter(w http.ResponseWriter, r *http.Request) (*models.CraftingProject, bool) {
+	project, err := h.craftingService.GetProject(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		response.NotFound(w, r, err.Error())
+		return nil, false
+	}
+	if _, ok := h.authorizeCharacterAccess(w, r, project.CharacterID, characterOwnerOrDM); !ok {
+		return nil, false
+	}
+	return project, true
+}
+
+// GetCraftingDowntime handles GET /api/characters/{id}/downtime
+func (h *Handlers) GetCraftingDowntime(w http.ResponseWriter, r *http.Request) {
+	characterID := mux.Vars(r)["id"]
+	if _, ok := h.authorizeCharacterAccess(w, r, characterID, characterOwnerOrDM); !ok {
+		return
+	}
+
+	days, err := h.craftingService.GetDowntime(r.Context(), characterID)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, models.CraftingDowntime{CharacterID: characterID, DaysAvailable: days})
+}
+
+// GrantCraftingDowntime handles POST /api/characters/{id}/downtime; only the character's DM may let days pass
+func (h *Handlers) GrantCraftingDowntime(w http.ResponseWriter, r *http.Request) {
+	characterID := mux.Vars(r)["id"]
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, r, "Unauthorized")
+		return
+	}
+	if !h.isDMForCharacter(r, userID, characterID) {
+		response.Forbidden(w, r, "Only the character's DM can grant downtime")
+		return
+	}
+
+	var req models.CraftingDowntimeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+	days, err := h.craftingService.GrantDowntime(r.Context(), characterID, req.Days)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, models.CraftingDowntime{CharacterID: characterID, DaysAvailable: days})
+}
@@ -40,11 +40,16 @@ func (h *Handlers) RollDice(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate user is in the game session
-	if err := h.gameService.ValidateUserInSession(r.Context(), req.GameSessionID, userID); err != nil {
+	// Validate user is in the game session, and playing rather than spectating
+	role, err := h.gameService.ParticipantRole(r.Context(), req.GameSessionID, userID)
+	if err != nil {
 		response.Forbidden(w, r, "User is not a participant in this game session")
 		return
 	}
+	if role == models.ParticipantRoleSpectator {
+		response.Forbidden(w, r, "Spectators cannot roll dice")
+		return
+	}
 
 	// Create dice roll
 	roll := &models.DiceRoll{
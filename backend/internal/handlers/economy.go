@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/auth"
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/response"
+)
+
+// TransferToCharacter handles POST /api/characters/{id}/transfer. A DM's transfer happens
+// at once; anyone else's becomes a trade offer the receiving character has to accept.
+func (h *Handlers) TransferToCharacter(w http.ResponseWriter, r *http.Request) {
+	characterID := mux.Vars(r)["id"]
+	if _, ok := h.authorizeCharacterAccess(w, r, characterID, characterOwnerOrDM); !ok {
+		return
+	}
+
+	var req models.TransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+	req.FromCharacterID = characterID
+
+	if _, err := h.characterService.GetCharacterByID(r.Context(), req.ToCharacterID); err != nil {
+		response.NotFound(w, r, "Receiving character not found")
+		return
+	}
+	if req.SessionID != "" {
+		inSession, err := h.charactersInSession(r, req.SessionID, req.FromCharacterID, req.ToCharacterID)
+		if err != nil {
+			response.InternalServerError(w, r, err)
+			return
+		}
+		if !inSession {
+			response.BadRequest(w, r, "Both characters must be part of the game session")
+			return
+		}
+	}
+
+	userID, _ := auth.GetUserIDFromContext(r.Context())
+	if h.isDMForCharacter(r, userID, req.FromCharacterID) && h.isDMForCharacter(r, userID, req.ToCharacterID) {
+		entries, err := h.inventoryService.Transfer(r.Context(), &req)
+		if err != nil {
+			response.BadRequest(w, r, err.Error())
+			return
+		}
+		h.broadcastPartyEvent(r, req.SessionID, partyEvent{Event: partyEventTransfer, CharacterID: req.FromCharacterID, Ledger: entries})
+		response.JSON(w, r, http.StatusOK, entries)
+		return
+	}
+
+	offer := &models.TradeOffer{
+		FromCharacterID: req.FromCharacterID,
+		ToCharacterID:   req.ToCharacterID,
+		Coins:           req.Coins,
+		Items:           req.Items,
+		Message:         req.Message,
+	}
+	if req.SessionID != "" {
+		offer.SessionID = &req.SessionID
+	}
+	if err := h.partyService.OfferTrade(r.Context(), offer); err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	h.broadcastTradeEvent(r, partyEventTradeOffered, offer, nil)
+	response.JSON(w, r, http.StatusAccepted, offer)
+}
+
+// AwardSessionLoot handles POST /api/game/sessions/{id}/loot
+func (h *Handlers) AwardSessionLoot(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if !h.authorizeSessionDM(w, r, sessionID) {
+		return
+	}
+
+	var award models.LootAward
+	if err := json.NewDecoder(r.Body).Decode(&award); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+	award.SessionID = sessionID
+
+	participants, err := h.gameService.GetSessionParticipants(r.Context(), sessionID)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+	inSession := make(map[string]bool, len(participants))
+	for _, p := range participants {
+		if p.CharacterID != nil {
+			inSession[*p.CharacterID] = true
+		}
+	}
+	for _, recipient := range award.Recipients {
+		if !inSession[recipient.CharacterID] {
+			response.BadRequest(w, r, "Loot can only be awarded to characters in the session")
+			return
+		}
+	}
+
+	entries, err := h.inventoryService.AwardLoot(r.Context(), &award)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusCreated, entries)
+}
+
+// GetCharacterLedger handles GET /api/characters/{id}/ledger?since=&limit=
+func (h *Handlers) GetCharacterLedger(w http.ResponseWriter, r *http.Request) {
+	characterID := mux.Vars(r)["id"]
+	if _, ok := h.authorizeCharacterAccess(w, r, characterID, characterOwnerOrDM); !ok {
+		return
+	}
+
+	filter, ok := ledgerFilterFromQuery(w, r)
+	if !ok {
+		return
+	}
+	filter.CharacterIDs = []string{characterID}
+
+	entries, err := h.inventoryService.GetLedger(filter)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, entries)
+}
+
+// GetSessionLedger handles GET /api/game/sessions/{id}/ledger so the DM can audit the party's gold
+func (h *Handlers) GetSessionLedger(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if !h.authorizeSessionDM(w, r, sessionID) {
+		return
+	}
+
+	filter, ok := ledgerFilterFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	// Include everything the party's characters did, not just entries tagged with the session
+	participants, err := h.gameService.GetSessionParticipants(r.Context(), sessionID)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+	for _, p := range participants {
+		if p.CharacterID != nil {
+			filter.CharacterIDs = append(filter.CharacterIDs, *p.CharacterID)
+		}
+	}
+	if len(filter.CharacterIDs) == 0 {
+		filter.SessionID = sessionID
+	}
+
+	entries, err := h.inventoryService.GetLedger(filter)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, entries)
+}
+
+// authorizeSessionDM allows only the DM of the game session
+func (h *Handlers) authorizeSessionDM(w http.ResponseWriter, r *http.Request, sessionID string) bool {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, r, "Unauthorized")
+		return false
+	}
+
+	session, err := h.gameService.GetSessionByID(r.Context(), sessionID)
+	if err != nil {
+		response.NotFound(w, r, "Game session not found")
+		return false
+	}
+	if session.DMID != userID {
+		response.Forbidden(w, r, "Only the DM can manage the session's treasure")
+		return false
+	}
+	return true
+}
+
+func ledgerFilterFromQuery(w http.ResponseWriter, r *http.Request) (models.LedgerFilter, bool) {
+	var filter models.LedgerFilter
+	query := r.URL.Query()
+
+	if since := query.Get("since"); since != "" {
+		parsed, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			response.BadRequest(w, r, "since must be an RFC3339 timestamp")
+			return filter, false
+		}
+		filter.Since = &parsed
+	}
+	if limit := query.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed < 1 {
+			response.BadRequest(w, r, "limit must be a positive number")
+			return filter, false
+		}
+		filter.Limit = parsed
+	}
+	return filter, true
+}
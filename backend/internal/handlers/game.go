@@ -146,6 +146,23 @@ func (h *Handlers) JoinGameSession(w http.ResponseWriter, r *http.Request) {
 	response.JSON(w, r, http.StatusOK, map[string]string{"message": "Successfully joined game session"})
 }
 
+// SpectateGameSession handles POST /api/game/sessions/{id}/spectate, joining the caller to
+// the session as a spectator who watches without a seat or a character
+func (h *Handlers) SpectateGameSession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, r, constants.ErrUnauthorized)
+		return
+	}
+
+	if err := h.gameService.SpectateSession(r.Context(), mux.Vars(r)["id"], userID); err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, map[string]string{"message": "Now spectating game session"})
+}
+
 func (h *Handlers) LeaveGameSession(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	sessionID := vars["id"]
@@ -288,6 +305,29 @@ func applyGameSessionUpdates(session *models.GameSession, updateData map[string]
 	applyIntUpdate(&session.MaxPlayers, updateData, "max_players", "maxPlayers")
 	applyBoolUpdate(&session.IsPublic, updateData, "is_public", "isPublic")
 	applyBoolUpdate(&session.RequiresInvite, updateData, "requires_invite", "requiresInvite")
+	applyTableRulesUpdate(session, updateData)
+}
+
+// applyTableRulesUpdate stores the DM's optional table rules in the session state. Rules
+// left out of the update keep their setting, so spectator chat can be toggled on its own.
+func applyTableRulesUpdate(session *models.GameSession, data map[string]interface{}) {
+	rules, ok := data["table_rules"].(map[string]interface{})
+	if !ok {
+		rules, ok = data["tableRules"].(map[string]interface{})
+	}
+	if !ok {
+		return
+	}
+	if session.State == nil {
+		session.State = make(map[string]interface{})
+	}
+	if stored, ok := session.State[models.SessionStateTableRules].(map[string]interface{}); ok {
+		for rule, setting := range rules {
+			stored[rule] = setting
+		}
+		rules = stored
+	}
+	session.State[models.SessionStateTableRules] = rules
 }
 
 // applyBoolUpdate updates a bool field from either snake_case or camelCase key
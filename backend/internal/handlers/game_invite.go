@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/auth"
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/response"
+)
+
+// ListGameInvites handles GET /api/game/sessions/{id}/invites, the invites that can still
+// be redeemed
+func (h *Handlers) ListGameInvites(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if !h.authorizeSessionDM(w, r, sessionID) {
+		return
+	}
+
+	invites, err := h.inviteService.ListPendingInvites(r.Context(), sessionID)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, invites)
+}
+
+// CreateGameInvite handles POST /api/game/sessions/{id}/invites. With an email the invite
+// is sent to that address; without one it is a shareable link.
+func (h *Handlers) CreateGameInvite(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if !h.authorizeSessionDM(w, r, sessionID) {
+		return
+	}
+	userID, _ := auth.GetUserIDFromContext(r.Context())
+
+	var req models.GameInviteRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+			return
+		}
+	}
+
+	invite, err := h.inviteService.CreateInvite(r.Context(), sessionID, userID, &req)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusCreated, invite)
+}
+
+// RevokeGameInvite handles DELETE /api/invites/{id}
+func (h *Handlers) RevokeGameInvite(w http.ResponseWriter, r *http.Request) {
+	invite, err := h.inviteService.GetInvite(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		response.NotFound(w, r, err.Error())
+		return
+	}
+	if !h.authorizeSessionDM(w, r, invite.SessionID) {
+		return
+	}
+
+	if err := h.inviteService.RevokeInvite(r.Context(), invite.ID); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			response.BadRequest(w, r, "invite has already been revoked")
+			return
+		}
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, map[string]string{"message": "Invite revoked"})
+}
+
+// RedeemGameInvite handles POST /api/invites/{code}/redeem, joining the caller to the
+// invite's session with the character they choose, or as a spectator
+func (h *Handlers) RedeemGameInvite(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, r, constants.ErrUnauthorized)
+		return
+	}
+
+	var req models.RedeemInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+
+	invite, err := h.inviteService.RedeemInvite(r.Context(), mux.Vars(r)["code"], userID, &req)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, map[string]string{
+		"message":   "Successfully joined game session",
+		"sessionId": invite.SessionID,
+	})
+}
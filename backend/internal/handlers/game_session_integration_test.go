@@ -311,6 +311,7 @@ func TestGameSessionWithWebSocket_Integration(t *testing.T) {
 		NameField:        WebSocketSessionName,
 		DescriptionField: WebSocketSessionDesc,
 		MaxPlayersField: 6,
+		"isPublic":       true, // joined directly, without an invite
 	}
 	w := ctx.MakeAuthenticatedRequest("POST", APISessionsPath[:len(APISessionsPath)-1], createReq, dmID)
 	require.Equal(t, http.StatusCreated, w.Code)
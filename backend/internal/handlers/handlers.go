@@ -15,11 +15,26 @@ import (
 type Handlers struct {
 	userService         *services.UserService
 	characterService    *services.CharacterService
+	versionService      *services.CharacterVersionService
 	gameService         *services.GameSessionService
 	diceService         *services.DiceRollService
+	chatService         *services.ChatService
+	eventService        *services.GameEventService
+	scheduleService     *services.SchedulingService
+	inviteService       *services.GameInviteService
 	combatService       *services.CombatService
 	npcService          *services.NPCService
 	inventoryService    *services.InventoryService
+	shopService         *services.ShopService
+	partyService        *services.PartyService
+	craftingService     *services.CraftingService
+	lootService         *services.LootService
+	treasureService     *services.TreasureService
+	itemLibraryService  *services.ItemLibraryService
+	startingEquipment   *services.StartingEquipmentService
+	resourceService     *services.CharacterResourceService
+	spellService        *services.SpellManagementService
+	exportService       *services.CharacterExportService
 	encounterService    *services.EncounterService
 	customRaceService   *services.CustomRaceService
 	dmAssistantService  *services.DMAssistantService
@@ -37,11 +52,26 @@ func NewHandlers(svc *services.Services, db *database.DB, hub *websocket.Hub) *H
 	return &Handlers{
 		userService:         svc.Users,
 		characterService:    svc.Characters,
+		versionService:      svc.CharacterVersions,
 		gameService:         svc.GameSessions,
 		diceService:         svc.DiceRolls,
+		chatService:         svc.Chat,
+		eventService:        svc.GameEvents,
+		scheduleService:     svc.Scheduling,
+		inviteService:       svc.GameInvites,
 		combatService:       svc.Combat,
 		npcService:          svc.NPCs,
 		inventoryService:    svc.Inventory,
+		shopService:         svc.Shops,
+		partyService:        svc.Parties,
+		craftingService:     svc.Crafting,
+		lootService:         svc.Loot,
+		treasureService:     svc.Treasure,
+		itemLibraryService:  svc.ItemLibrary,
+		startingEquipment:   svc.StartingEquipment,
+		resourceService:     svc.CharacterResources,
+		spellService:        svc.SpellManagement,
+		exportService:       svc.CharacterExport,
 		encounterService:    svc.Encounters,
 		customRaceService:   svc.CustomRaces,
 		dmAssistantService:  svc.DMAssistant,
@@ -41,7 +41,7 @@ func (h *InventoryHandler) AddItemToInventory(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	if err := h.inventoryService.AddItemToCharacter(characterID, req.ItemID, req.Quantity); err != nil {
+	if err := h.inventoryService.AddItemToCharacter(r.Context(), characterID, req.ItemID, req.Quantity); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -59,7 +59,7 @@ func (h *InventoryHandler) RemoveItemFromInventory(w http.ResponseWriter, r *htt
 		return
 	}
 
-	if err := h.inventoryService.RemoveItemFromCharacter(characterID, req.ItemID, req.Quantity); err != nil {
+	if err := h.inventoryService.RemoveItemFromCharacter(r.Context(), characterID, req.ItemID, req.Quantity); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -72,7 +72,7 @@ func (h *InventoryHandler) EquipItem(w http.ResponseWriter, r *http.Request) {
 	characterID := vars["characterId"]
 	itemID := vars["itemId"]
 
-	if err := h.inventoryService.EquipItem(characterID, itemID); err != nil {
+	if err := h.inventoryService.EquipItem(r.Context(), characterID, itemID); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -85,7 +85,7 @@ func (h *InventoryHandler) UnequipItem(w http.ResponseWriter, r *http.Request) {
 	characterID := vars["characterId"]
 	itemID := vars["itemId"]
 
-	if err := h.inventoryService.UnequipItem(characterID, itemID); err != nil {
+	if err := h.inventoryService.UnequipItem(r.Context(), characterID, itemID); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -93,12 +93,32 @@ func (h *InventoryHandler) UnequipItem(w http.ResponseWriter, r *http.Request) {
 	sendSuccessResponse(w, "unequipped")
 }
 
+// MoveItem packs an item into a container, or takes it out to carry, load on a mount or store
+func (h *InventoryHandler) MoveItem(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	characterID := vars["characterId"]
+	itemID := vars["itemId"]
+
+	var req models.MoveItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.inventoryService.MoveItem(r.Context(), characterID, itemID, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sendSuccessResponse(w, "moved")
+}
+
 func (h *InventoryHandler) AttuneItem(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	characterID := vars["characterId"]
 	itemID := vars["itemId"]
 
-	if err := h.inventoryService.AttuneToItem(characterID, itemID); err != nil {
+	if err := h.inventoryService.AttuneToItem(r.Context(), characterID, itemID); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -111,7 +131,7 @@ func (h *InventoryHandler) UnattuneItem(w http.ResponseWriter, r *http.Request)
 	characterID := vars["characterId"]
 	itemID := vars["itemId"]
 
-	if err := h.inventoryService.UnattuneFromItem(characterID, itemID); err != nil {
+	if err := h.inventoryService.UnattuneFromItem(r.Context(), characterID, itemID); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -119,6 +139,62 @@ func (h *InventoryHandler) UnattuneItem(w http.ResponseWriter, r *http.Request)
 	sendSuccessResponse(w, "unattuned")
 }
 
+// UseItemCharges spends charges from a magic item, optionally casting one of its spells
+func (h *InventoryHandler) UseItemCharges(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	characterID := vars["characterId"]
+	itemID := vars["itemId"]
+
+	var req models.UseChargesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	use, err := h.inventoryService.UseItemCharges(r.Context(), characterID, itemID, &req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sendJSONResponse(w, use)
+}
+
+// UseItem drinks, throws or reads a consumable outside combat, on the character or the request's target
+func (h *InventoryHandler) UseItem(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	characterID := vars["characterId"]
+	itemID := vars["itemId"]
+
+	var req models.UseItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	use, err := h.inventoryService.UseItem(r.Context(), characterID, itemID, &req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sendJSONResponse(w, use)
+}
+
+// GetItemEffects returns the bonuses the character's equipped and attuned items grant
+func (h *InventoryHandler) GetItemEffects(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	characterID := vars["characterId"]
+
+	effects, err := h.inventoryService.GetItemEffects(r.Context(), characterID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sendJSONResponse(w, effects)
+}
+
 func (h *InventoryHandler) GetCharacterCurrency(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	characterID := vars["characterId"]
@@ -149,7 +225,7 @@ func (h *InventoryHandler) UpdateCharacterCurrency(w http.ResponseWriter, r *htt
 		return
 	}
 
-	if err := h.inventoryService.UpdateCharacterCurrency(characterID, req.Copper, req.Silver,
+	if err := h.inventoryService.UpdateCharacterCurrency(r.Context(), characterID, req.Copper, req.Silver,
 		req.Electrum, req.Gold, req.Platinum); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -174,7 +250,7 @@ func (h *InventoryHandler) PurchaseItem(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := h.inventoryService.PurchaseItem(characterID, req.ItemID, req.Quantity); err != nil {
+	if err := h.inventoryService.PurchaseItem(r.Context(), characterID, req.ItemID, req.Quantity); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -192,7 +268,7 @@ func (h *InventoryHandler) SellItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.inventoryService.SellItem(characterID, req.ItemID, req.Quantity); err != nil {
+	if err := h.inventoryService.SellItem(r.Context(), characterID, req.ItemID, req.Quantity); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -235,7 +311,8 @@ func (h *InventoryHandler) GetItemsByType(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	items, err := h.inventoryService.GetItemsByType(models.ItemType(itemType))
+	// Only shared items; a session's homebrew is listed by GET /game/sessions/{id}/items
+	items, err := h.inventoryService.GetItemsByType(models.ItemType(itemType), "")
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/response"
+)
+
+// ListItems handles GET /api/items?type=&source=&search=&limit=, the items every session shares
+func (h *Handlers) ListItems(w http.ResponseWriter, r *http.Request) {
+	items, err := h.itemLibraryService.ListItems(r.Context(), itemFilterFromQuery(r))
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, items)
+}
+
+// ListSessionItems handles GET /api/game/sessions/{id}/items, the shared items plus the session's homebrew
+func (h *Handlers) ListSessionItems(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if err := validateUserSession(w, r, h.gameService, sessionID); err != nil {
+		return
+	}
+
+	filter := itemFilterFromQuery(r)
+	filter.SessionID = sessionID
+	items, err := h.itemLibraryService.ListItems(r.Context(), filter)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, items)
+}
+
+// CreateHomebrewItems handles POST /api/game/sessions/{id}/items with one item or a data/items file
+func (h *Handlers) CreateHomebrewItems(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if !h.authorizeSessionDM(w, r, sessionID) {
+		return
+	}
+
+	var body json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+
+	items, err := h.itemLibraryService.CreateHomebrewItems(r.Context(), sessionID, body)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusCreated, items)
+}
+
+// UpdateHomebrewItem handles PUT /api/game/sessions/{id}/items/{itemId}
+func (h *Handlers) UpdateHomebrewItem(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sessionID := vars["id"]
+	if !h.authorizeSessionDM(w, r, sessionID) {
+		return
+	}
+
+	var body json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+
+	item, err := h.itemLibraryService.UpdateHomebrewItem(r.Context(), sessionID, vars["itemId"], body)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, item)
+}
+
+// DeleteHomebrewItem handles DELETE /api/game/sessions/{id}/items/{itemId}
+func (h *Handlers) DeleteHomebrewItem(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sessionID := vars["id"]
+	if !h.authorizeSessionDM(w, r, sessionID) {
+		return
+	}
+
+	if err := h.itemLibraryService.DeleteHomebrewItem(r.Context(), sessionID, vars["itemId"]); err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusNoContent, nil)
+}
+
+func itemFilterFromQuery(r *http.Request) models.ItemFilter {
+	query := r.URL.Query()
+	filter := models.ItemFilter{
+		Type:   models.ItemType(query.Get("type")),
+		Source: models.ItemSource(query.Get("source")),
+		Search: query.Get("search"),
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 {
+		filter.Limit = limit
+	}
+	return filter
+}
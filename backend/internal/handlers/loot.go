@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/response"
+)
+
+// ListLootPools handles GET /api/game/sessions/{id}/loot-pools?status=
+func (h *Handlers) ListLootPools(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if err := validateUserSession(w, r, h.gameService, sessionID); err != nil {
+		return
+	}
+
+	status := models.LootPoolStatus(r.URL.Query().Get("status"))
+	pools, err := h.lootService.ListPools(r.Context(), sessionID, status)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, pools)
+}
+
+// CreateLootPool handles POST /api/game/sessions/{id}/loot-pools
+func (h *Handlers) CreateLootPool(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if !h.authorizeSessionDM(w, r, sessionID) {
+		return
+	}
+
+	var req models.CreateLootPoolRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+
+	pool, err := h.lootService.CreatePool(r.Context(), sessionID, &req)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	h.broadcastPartyEvent(r, sessionID, partyEvent{Event: "loot_pool_created", LootPool: pool})
+	response.JSON(w, r, http.StatusCreated, pool)
+}
+
+// GetLootPool handles GET /api/loot-pools/{id}
+func (h *Handlers) GetLootPool(w http.ResponseWriter, r *http.Request) {
+	pool, ok := h.lootPoolForSession(w, r)
+	if !ok {
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, pool)
+}
+
+// AssignLoot handles POST /api/loot-pools/{id}/assign, the DM handing an item to a character
+func (h *Handlers) AssignLoot(w http.ResponseWriter, r *http.Request) {
+	pool, ok := h.lootPoolForSession(w, r)
+	if !ok || !h.authorizeSessionDM(w, r, pool.SessionID) {
+		return
+	}
+
+	var req models.LootPickRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+
+	pool, err := h.lootService.AssignItem(r.Context(), pool.ID, &req)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	h.broadcastPartyEvent(r, pool.SessionID, partyEvent{Event: "loot_assigned", CharacterID: req.CharacterID, LootPool: pool})
+	response.JSON(w, r, http.StatusOK, pool)
+}
+
+// ClaimLoot handles POST /api/loot-pools/{id}/claim
+func (h *Handlers) ClaimLoot(w http.ResponseWriter, r *http.Request) {
+	pool, req, ok := h.lootPickForCharacter(w, r)
+	if !ok {
+		return
+	}
+
+	pool, err := h.lootService.ClaimItem(r.Context(), pool.ID, req)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	h.broadcastPartyEvent(r, pool.SessionID, partyEvent{Event: "loot_claimed", CharacterID: req.CharacterID, LootPool: pool})
+	response.JSON(w, r, http.StatusOK, pool)
+}
+
+// RollForLoot handles POST /api/loot-pools/{id}/roll with a need, greed or pass
+func (h *Handlers) RollForLoot(w http.ResponseWriter, r *http.Request) {
+	pool, req, ok := h.lootPickForCharacter(w, r)
+	if !ok {
+		return
+	}
+
+	roll, err := h.lootService.RollForItem(r.Context(), pool.ID, req)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	h.broadcastPartyEvent(r, pool.SessionID, partyEvent{Event: "loot_rolled", CharacterID: req.CharacterID, LootRoll: roll})
+	response.JSON(w, r, http.StatusCreated, roll)
+}
+
+// DistributeLoot handles POST /api/loot-pools/{id}/distribute
+func (h *Handlers) DistributeLoot(w http.ResponseWriter, r *http.Request) {
+	pool, ok := h.lootPoolForSession(w, r)
+	if !ok || !h.authorizeSessionDM(w, r, pool.SessionID) {
+		return
+	}
+
+	distribution, err := h.lootService.Distribute(r.Context(), pool.ID)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	h.broadcastPartyEvent(r, pool.SessionID, partyEvent{Event: "loot_distributed", LootPool: distribution.Pool, Ledger: distribution.Ledger})
+	response.JSON(w, r, http.StatusOK, distribution)
+}
+
+// lootPoolForSession loads a loot pool that only members of its game session may see
+func (h *Handlers) lootPoolForSession(w http.ResponseWriter, r *http.Request) (*models.LootPool, bool) {
+	pool, err := h.lootService.GetPool(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		response.NotFound(w, r, err.Error())
+		return nil, false
+	}
+	if err := validateUserSession(w, r, h.gameService, pool.SessionID); err != nil {
+		return nil, false
+	}
+	return pool, true
+}
+
+// lootPickForCharacter decodes a claim or roll made on behalf of a character the user controls
+func (h *Handlers) lootPickForCharacter(w http.ResponseWriter, r *http.Request) (*models.LootPool, *models.LootPickRequest, bool) {
+	pool, ok := h.lootPoolForSession(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+
+	var req models.LootPickRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return nil, nil, false
+	}
+	if _, ok := h.authorizeCharacterAccess(w, r, req.CharacterID, characterOwnerOrDM); !ok {
+		return nil, nil, false
+	}
+	return pool, &req, true
+}
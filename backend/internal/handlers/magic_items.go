@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/auth"
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/response"
+)
+
+// RemoveItemCurse handles POST /api/characters/{id}/items/{itemId}/remove-curse.
+// Remove Curse is cast by a character the caller manages, spending their spell slot; only the
+// DM may lift a curse outright.
+func (h *Handlers) RemoveItemCurse(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	characterID := vars["id"]
+	if _, ok := h.authorizeCharacterAccess(w, r, characterID, characterOwnerOrDM); !ok {
+		return
+	}
+
+	var req models.RemoveCurseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+	if req.Method == "" {
+		req.Method = models.RemoveCurseBySpell
+	}
+	if req.Method == models.RemoveCurseByDM {
+		userID, _ := auth.GetUserIDFromContext(r.Context())
+		if !h.isDMForCharacter(r, userID, characterID) {
+			response.Forbidden(w, r, "Only the DM can lift a curse without Remove Curse")
+			return
+		}
+	}
+	if req.Method == models.RemoveCurseBySpell && req.CasterID != "" && req.CasterID != characterID {
+		if _, ok := h.authorizeCharacterAccess(w, r, req.CasterID, characterOwnerOrDM); !ok {
+			return
+		}
+	}
+
+	if err := h.inventoryService.RemoveCurse(r.Context(), characterID, vars["itemId"], &req); err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, map[string]string{"status": "curse removed"})
+}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/auth"
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/internal/websocket"
+	"github.com/ctclostio/DnD-Game/backend/pkg/logger"
+	"github.com/ctclostio/DnD-Game/backend/pkg/response"
+)
+
+// Session feed events for the party stash and trades
+const (
+	partyEventStashDeposit  = "stash_deposit"
+	partyEventStashWithdraw = "stash_withdraw"
+	partyEventTransfer      = "transfer"
+	partyEventTradeOffered  = "trade_offered"
+	partyEventTradeAccepted = "trade_accepted"
+	partyEventTradeDeclined = "trade_declined"
+	partyEventTradeCanceled = "trade_cancelled"
+)
+
+// partyEvent is what the session feed shows when coins or items change hands
+type partyEvent struct {
+	Event       string                `json:"event"`
+	CharacterID string                `json:"characterId,omitempty"`
+	Offer       *models.TradeOffer    `json:"offer,omitempty"`
+	Ledger      []*models.LedgerEntry `json:"ledger,omitempty"`
+	LootPool    *models.LootPool      `json:"lootPool,omitempty"`
+	LootRoll    *models.LootRoll      `json:"lootRoll,omitempty"`
+}
+
+// GetPartyStash handles GET /api/game/sessions/{id}/party
+func (h *Handlers) GetPartyStash(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if err := validateUserSession(w, r, h.gameService, sessionID); err != nil {
+		return
+	}
+
+	stash, err := h.partyService.GetStash(r.Context(), sessionID)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, stash)
+}
+
+// DepositToPartyStash handles POST /api/game/sessions/{id}/party/deposit
+func (h *Handlers) DepositToPartyStash(w http.ResponseWriter, r *http.Request) {
+	h.movePartyStash(w, r, true)
+}
+
+// WithdrawFromPartyStash handles POST /api/game/sessions/{id}/party/withdraw
+func (h *Handlers) WithdrawFromPartyStash(w http.ResponseWriter, r *http.Request) {
+	h.movePartyStash(w, r, false)
+}
+
+func (h *Handlers) movePartyStash(w http.ResponseWriter, r *http.Request, deposit bool) {
+	sessionID := mux.Vars(r)["id"]
+
+	var req models.StashRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+	if _, ok := h.authorizeCharacterAccess(w, r, req.CharacterID, characterOwnerOrDM); !ok {
+		return
+	}
+	inSession, err := h.charactersInSession(r, sessionID, req.CharacterID)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+	if !inSession {
+		response.Forbidden(w, r, "Character is not part of this game session")
+		return
+	}
+
+	var entries []*models.LedgerEntry
+	event := partyEventStashWithdraw
+	if deposit {
+		event = partyEventStashDeposit
+		entries, err = h.partyService.DepositToStash(r.Context(), sessionID, &req)
+	} else {
+		entries, err = h.partyService.WithdrawFromStash(r.Context(), sessionID, &req)
+	}
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	h.broadcastPartyEvent(r, sessionID, partyEvent{Event: event, CharacterID: req.CharacterID, Ledger: entries})
+	response.JSON(w, r, http.StatusOK, entries)
+}
+
+// ListCharacterTrades handles GET /api/characters/{id}/trades?status=
+func (h *Handlers) ListCharacterTrades(w http.ResponseWriter, r *http.Request) {
+	characterID := mux.Vars(r)["id"]
+	if _, ok := h.authorizeCharacterAccess(w, r, characterID, characterOwnerOrDM); !ok {
+		return
+	}
+
+	offers, err := h.partyService.ListTradeOffers(r.Context(), characterID, models.TradeStatus(r.URL.Query().Get("status")))
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, offers)
+}
+
+// AcceptTrade handles POST /api/trades/{id}/accept
+func (h *Handlers) AcceptTrade(w http.ResponseWriter, r *http.Request) {
+	offer, ok := h.tradeOfferForReceiver(w, r)
+	if !ok {
+		return
+	}
+
+	offer, entries, err := h.partyService.AcceptTrade(r.Context(), offer.ID)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	h.broadcastTradeEvent(r, partyEventTradeAccepted, offer, entries)
+	response.JSON(w, r, http.StatusOK, map[string]interface{}{
+		"offer":  offer,
+		"ledger": entries,
+	})
+}
+
+// DeclineTrade handles POST /api/trades/{id}/decline
+func (h *Handlers) DeclineTrade(w http.ResponseWriter, r *http.Request) {
+	offer, ok := h.tradeOfferForReceiver(w, r)
+	if !ok {
+		return
+	}
+
+	offer, err := h.partyService.DeclineTrade(r.Context(), offer.ID)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	h.broadcastTradeEvent(r, partyEventTradeDeclined, offer, nil)
+	response.JSON(w, r, http.StatusOK, offer)
+}
+
+// CancelTrade handles POST /api/trades/{id}/cancel
+func (h *Handlers) CancelTrade(w http.ResponseWriter, r *http.Request) {
+	offer, err := h.partyService.GetTradeOffer(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		response.NotFound(w, r, err.Error())
+		return
+	}
+	if _, ok := h.authorizeCharacterAccess(w, r, offer.FromCharacterID, characterOwnerOrDM); !ok {
+		return
+	}
+
+	offer, err = h.partyService.CancelTrade(r.Context(), offer.ID)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	h.broadcastTradeEvent(r, partyEventTradeCanceled, offer, nil)
+	response.JSON(w, r, http.StatusOK, offer)
+}
+
+// tradeOfferForReceiver loads an offer that only the receiving character's owner, or their DM, may answer
+func (h *Handlers) tradeOfferForReceiver(w http.ResponseWriter, r *http.Request) (*models.TradeOffer, bool) {
+	offer, err := h.partyService.GetTradeOffer(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		response.NotFound(w, r, err.Error())
+		return nil, false
+	}
+	if _, ok := h.authorizeCharacterAccess(w, r, offer.ToCharacterID, characterOwnerOrDM); !ok {
+		return nil, false
+	}
+	return offer, true
+}
+
+// charactersInSession reports whether every character has joined the game session
+func (h *Handlers) charactersInSession(r *http.Request, sessionID string, characterIDs ...string) (bool, error) {
+	participants, err := h.gameService.GetSessionParticipants(r.Context(), sessionID)
+	if err != nil {
+		return false, err
+	}
+	joined := make(map[string]bool, len(participants))
+	for _, p := range participants {
+		if p.CharacterID != nil {
+			joined[*p.CharacterID] = true
+		}
+	}
+	for _, characterID := range characterIDs {
+		if !joined[characterID] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (h *Handlers) broadcastTradeEvent(r *http.Request, event string, offer *models.TradeOffer, entries []*models.LedgerEntry) {
+	if offer.SessionID == nil {
+		return
+	}
+	h.broadcastPartyEvent(r, *offer.SessionID, partyEvent{Event: event, CharacterID: offer.FromCharacterID, Offer: offer, Ledger: entries})
+}
+
+// broadcastPartyEvent writes a party event to the session's event log and posts it to the
+// session's feed
+func (h *Handlers) broadcastPartyEvent(r *http.Request, sessionID string, event partyEvent) {
+	if sessionID == "" {
+		return
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	h.recordPartyEvent(r, sessionID, event, data)
+
+	if h.websocketHub == nil {
+		return
+	}
+	msgBytes, err := json.Marshal(websocket.Message{
+		Type:   "party",
+		RoomID: sessionID,
+		Data:   data,
+	})
+	if err != nil {
+		return
+	}
+
+	h.websocketHub.Broadcast(msgBytes)
+}
+
+// recordPartyEvent keeps a party event in the event log. The coins and items have already
+// moved, so a failure is logged rather than failing the request.
+func (h *Handlers) recordPartyEvent(r *http.Request, sessionID string, event partyEvent, data []byte) {
+	if h.eventService == nil {
+		return
+	}
+
+	gameEvent := &models.GameEvent{
+		SessionID: sessionID,
+		Type:      models.GameEventParty,
+	}
+	gameEvent.PlayerID, _ = auth.GetUserIDFromContext(r.Context())
+	if event.CharacterID != "" {
+		gameEvent.CharacterID = &event.CharacterID
+	}
+	err := json.Unmarshal(data, &gameEvent.Data)
+	if err == nil {
+		err = h.eventService.Record(r.Context(), gameEvent)
+	}
+	if err != nil {
+		logger.WithContext(r.Context()).WithError(err).Error().
+			Str("session_id", sessionID).
+			Str("event", event.Event).
+			Msg("Failed to record party event")
+	}
+}
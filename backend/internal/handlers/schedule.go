@@ -0,0 +1,344 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/auth"
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/response"
+)
+
+const (
+	calendarContentType     = "text/calendar; charset=utf-8"
+	defaultUpcomingDuration = 90 * 24 * time.Hour
+)
+
+// ListSessionSchedules handles GET /api/game/sessions/{id}/schedules
+func (h *Handlers) ListSessionSchedules(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if err := validateUserSession(w, r, h.gameService, sessionID); err != nil {
+		return
+	}
+
+	schedules, err := h.scheduleService.ListSchedules(r.Context(), sessionID)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, schedules)
+}
+
+// CreateSessionSchedule handles POST /api/game/sessions/{id}/schedules
+func (h *Handlers) CreateSessionSchedule(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if !h.authorizeSessionDM(w, r, sessionID) {
+		return
+	}
+	userID, _ := auth.GetUserIDFromContext(r.Context())
+
+	var req models.SessionScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+
+	schedule, err := h.scheduleService.CreateSchedule(r.Context(), sessionID, userID, &req)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusCreated, schedule)
+}
+
+// UpdateSessionSchedule handles PUT /api/schedules/{id}
+func (h *Handlers) UpdateSessionSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := h.scheduleForSession(w, r)
+	if !ok || !h.authorizeSessionDM(w, r, schedule.SessionID) {
+		return
+	}
+
+	var req models.SessionScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+
+	schedule, err := h.scheduleService.UpdateSchedule(r.Context(), schedule.ID, &req)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, schedule)
+}
+
+// DeleteSessionSchedule handles DELETE /api/schedules/{id}
+func (h *Handlers) DeleteSessionSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := h.scheduleForSession(w, r)
+	if !ok || !h.authorizeSessionDM(w, r, schedule.SessionID) {
+		return
+	}
+
+	if err := h.scheduleService.DeleteSchedule(r.Context(), schedule.ID); err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, map[string]string{"message": "Schedule deleted"})
+}
+
+// RSVPSessionSchedule handles POST /api/schedules/{id}/rsvp, a member answering one
+// occurrence of the schedule
+func (h *Handlers) RSVPSessionSchedule(w http.ResponseWriter, r *http.Request) {
+	schedule, ok := h.scheduleForSession(w, r)
+	if !ok {
+		return
+	}
+	userID, _ := auth.GetUserIDFromContext(r.Context())
+
+	var req models.RSVPRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+
+	rsvp, err := h.scheduleService.RSVP(r.Context(), schedule.ID, userID, &req)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, rsvp)
+}
+
+// GetUpcomingSessions handles GET /api/game/sessions/{id}/upcoming. It takes the from and
+// to query parameters, RFC3339 timestamps defaulting to the next 90 days, and tz, an IANA
+// timezone the times are given in.
+func (h *Handlers) GetUpcomingSessions(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if err := validateUserSession(w, r, h.gameService, sessionID); err != nil {
+		return
+	}
+
+	query := r.URL.Query()
+	from := time.Now()
+	if value := query.Get("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			response.BadRequest(w, r, "from must be an RFC3339 timestamp")
+			return
+		}
+		from = parsed
+	}
+	to := from.Add(defaultUpcomingDuration)
+	if value := query.Get("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			response.BadRequest(w, r, "to must be an RFC3339 timestamp")
+			return
+		}
+		to = parsed
+	}
+	loc := time.UTC
+	if tz := query.Get("tz"); tz != "" {
+		parsed, err := time.LoadLocation(tz)
+		if err != nil {
+			response.BadRequest(w, r, "tz must be an IANA timezone such as America/New_York")
+			return
+		}
+		loc = parsed
+	}
+
+	upcoming, err := h.scheduleService.GetUpcoming(r.Context(), sessionID, from, to)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+	for _, occurrence := range upcoming {
+		occurrence.StartsAt = occurrence.StartsAt.In(loc)
+		occurrence.EndsAt = occurrence.EndsAt.In(loc)
+	}
+
+	response.JSON(w, r, http.StatusOK, upcoming)
+}
+
+// GetSessionCalendar handles GET /api/game/sessions/{id}/calendar.ics
+func (h *Handlers) GetSessionCalendar(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if err := validateUserSession(w, r, h.gameService, sessionID); err != nil {
+		return
+	}
+
+	calendar, err := h.scheduleService.SessionCalendar(r.Context(), sessionID)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set(constants.ContentType, calendarContentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=session-"+sessionID+".ics")
+	_, _ = w.Write(calendar)
+}
+
+// ListAvailabilityPolls handles GET /api/game/sessions/{id}/polls
+func (h *Handlers) ListAvailabilityPolls(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if err := validateUserSession(w, r, h.gameService, sessionID); err != nil {
+		return
+	}
+
+	polls, err := h.scheduleService.ListPolls(r.Context(), sessionID)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, polls)
+}
+
+// CreateAvailabilityPoll handles POST /api/game/sessions/{id}/polls
+func (h *Handlers) CreateAvailabilityPoll(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if !h.authorizeSessionDM(w, r, sessionID) {
+		return
+	}
+	userID, _ := auth.GetUserIDFromContext(r.Context())
+
+	var req models.AvailabilityPollRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+
+	poll, err := h.scheduleService.CreatePoll(r.Context(), sessionID, userID, &req)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusCreated, poll)
+}
+
+// GetAvailabilityPoll handles GET /api/polls/{id}, its options ranked best first
+func (h *Handlers) GetAvailabilityPoll(w http.ResponseWriter, r *http.Request) {
+	poll, ok := h.pollForSession(w, r)
+	if !ok {
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, poll)
+}
+
+// VoteAvailabilityPoll handles POST /api/polls/{id}/vote
+func (h *Handlers) VoteAvailabilityPoll(w http.ResponseWriter, r *http.Request) {
+	poll, ok := h.pollForSession(w, r)
+	if !ok {
+		return
+	}
+	userID, _ := auth.GetUserIDFromContext(r.Context())
+
+	var req models.PollVoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+
+	poll, err := h.scheduleService.Vote(r.Context(), poll.ID, userID, &req)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, poll)
+}
+
+// CloseAvailabilityPoll handles POST /api/polls/{id}/close, booking the chosen date
+func (h *Handlers) CloseAvailabilityPoll(w http.ResponseWriter, r *http.Request) {
+	poll, ok := h.pollForSession(w, r)
+	if !ok || !h.authorizeSessionDM(w, r, poll.SessionID) {
+		return
+	}
+	userID, _ := auth.GetUserIDFromContext(r.Context())
+
+	var req models.ClosePollRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+			return
+		}
+	}
+
+	poll, err := h.scheduleService.ClosePoll(r.Context(), poll.ID, userID, &req)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, poll)
+}
+
+// GetCalendarFeed handles GET /api/calendar/feed, the address of the caller's calendar
+// feed. POST rotates it, retiring the old address.
+func (h *Handlers) GetCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, r, "Unauthorized")
+		return
+	}
+
+	token, err := h.scheduleService.CalendarFeedToken(r.Context(), userID, r.Method == http.MethodPost)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	path := strings.TrimSuffix(r.URL.Path, "/feed") + "/" + token + ".ics"
+	response.JSON(w, r, http.StatusOK, map[string]string{"token": token, "path": path})
+}
+
+// ServeCalendarFeed handles GET /api/calendar/{token}.ics. The token stands in for the
+// login calendar apps cannot send.
+func (h *Handlers) ServeCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	calendar, err := h.scheduleService.CalendarFeed(r.Context(), mux.Vars(r)["token"])
+	if err != nil {
+		response.NotFound(w, r, err.Error())
+		return
+	}
+
+	w.Header().Set(constants.ContentType, calendarContentType)
+	_, _ = w.Write(calendar)
+}
+
+// scheduleForSession loads a schedule that only members of its game session may see
+func (h *Handlers) scheduleForSession(w http.ResponseWriter, r *http.Request) (*models.SessionSchedule, bool) {
+	schedule, err := h.scheduleService.GetSchedule(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		response.NotFound(w, r, err.Error())
+		return nil, false
+	}
+	if err := validateUserSession(w, r, h.gameService, schedule.SessionID); err != nil {
+		return nil, false
+	}
+	return schedule, true
+}
+
+// pollForSession loads an availability poll that only members of its game session may see
+func (h *Handlers) pollForSession(w http.ResponseWriter, r *http.Request) (*models.AvailabilityPoll, bool) {
+	poll, err := h.scheduleService.GetPoll(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		response.NotFound(w, r, err.Error())
+		return nil, false
+	}
+	if err := validateUserSession(w, r, h.gameService, poll.SessionID); err != nil {
+		return nil, false
+	}
+	return poll, true
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/response"
+)
+
+// GetShop handles GET /api/world/shops/{id}
+func (h *Handlers) GetShop(w http.ResponseWriter, r *http.Request) {
+	shopID, ok := h.shopIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	shop, err := h.shopService.GetShop(shopID)
+	if err != nil {
+		response.NotFound(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, shop)
+}
+
+// QuoteShopTrade handles GET /api/world/shops/{id}/quote?itemId=&quantity=&side=
+func (h *Handlers) QuoteShopTrade(w http.ResponseWriter, r *http.Request) {
+	shopID, ok := h.shopIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	quantity := 1
+	if q := query.Get("quantity"); q != "" {
+		parsed, err := strconv.Atoi(q)
+		if err != nil {
+			response.BadRequest(w, r, "quantity must be a number")
+			return
+		}
+		quantity = parsed
+	}
+	side := models.ShopTradeSide(query.Get("side"))
+	if side == "" {
+		side = models.ShopTradeBuy
+	}
+
+	quote, err := h.shopService.Quote(shopID, query.Get("itemId"), quantity, side)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, quote)
+}
+
+// BuyFromShop handles POST /api/world/shops/{id}/buy
+func (h *Handlers) BuyFromShop(w http.ResponseWriter, r *http.Request) {
+	h.tradeWithShop(w, r, models.ShopTradeBuy)
+}
+
+// SellToShop handles POST /api/world/shops/{id}/sell
+func (h *Handlers) SellToShop(w http.ResponseWriter, r *http.Request) {
+	h.tradeWithShop(w, r, models.ShopTradeSell)
+}
+
+// RestockShop handles POST /api/world/shops/{id}/restock
+func (h *Handlers) RestockShop(w http.ResponseWriter, r *http.Request) {
+	shopID, ok := h.shopIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	sessionID, err := h.shopService.GetShopSessionID(shopID)
+	if err != nil {
+		response.NotFound(w, r, err.Error())
+		return
+	}
+	if !h.authorizeSessionDM(w, r, sessionID) {
+		return
+	}
+
+	shop, err := h.shopService.Restock(shopID)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, shop)
+}
+
+func (h *Handlers) tradeWithShop(w http.ResponseWriter, r *http.Request, side models.ShopTradeSide) {
+	shopID, ok := h.shopIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ShopTradeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+	if _, ok := h.authorizeCharacterAccess(w, r, req.CharacterID, characterOwnerOrDM); !ok {
+		return
+	}
+
+	// Characters can only trade in settlements of a campaign they play in
+	sessionID, err := h.shopService.GetShopSessionID(shopID)
+	if err != nil {
+		response.NotFound(w, r, err.Error())
+		return
+	}
+	participants, err := h.gameService.GetSessionParticipants(r.Context(), sessionID)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+	inSession := false
+	for _, p := range participants {
+		if p.CharacterID != nil && *p.CharacterID == req.CharacterID {
+			inSession = true
+			break
+		}
+	}
+	if !inSession {
+		response.Forbidden(w, r, "Character is not part of this shop's game session")
+		return
+	}
+
+	var result *models.ShopTradeResult
+	if side == models.ShopTradeSell {
+		result, err = h.shopService.Sell(r.Context(), shopID, &req)
+	} else {
+		result, err = h.shopService.Buy(r.Context(), shopID, &req)
+	}
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, result)
+}
+
+func (h *Handlers) shopIDFromRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	if h.shopService == nil {
+		response.BadRequest(w, r, "Shops are not available")
+		return uuid.Nil, false
+	}
+	shopID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		response.BadRequest(w, r, "Invalid shop ID")
+		return uuid.Nil, false
+	}
+	return shopID, true
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/response"
+)
+
+// GetCharacterSpells returns a character's spells alongside their class limits
+func (h *Handlers) GetCharacterSpells(w http.ResponseWriter, r *http.Request) {
+	characterID := mux.Vars(r)["id"]
+	if !h.authorizeSpellManagement(w, r, characterID) {
+		return
+	}
+
+	summary, err := h.spellService.GetSpellcasting(r.Context(), characterID)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, summary)
+}
+
+// GetAvailableSpells returns the class list spells a character can learn or prepare
+func (h *Handlers) GetAvailableSpells(w http.ResponseWriter, r *http.Request) {
+	characterID := mux.Vars(r)["id"]
+	if !h.authorizeSpellManagement(w, r, characterID) {
+		return
+	}
+
+	spells, err := h.spellService.GetAvailableSpells(r.Context(), characterID)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, spells)
+}
+
+// LearnSpell adds a spell to a character's known spells or spellbook
+func (h *Handlers) LearnSpell(w http.ResponseWriter, r *http.Request) {
+	characterID := mux.Vars(r)["id"]
+
+	var req models.LearnSpellRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Spell == "" {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+
+	if !h.authorizeSpellManagement(w, r, characterID) {
+		return
+	}
+
+	summary, err := h.spellService.LearnSpell(r.Context(), characterID, &req)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, summary)
+}
+
+// ForgetSpell removes a spell from a character's known spells or spellbook
+func (h *Handlers) ForgetSpell(w http.ResponseWriter, r *http.Request) {
+	characterID := mux.Vars(r)["id"]
+
+	var req struct {
+		Spell string `json:"spell"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Spell == "" {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+
+	if !h.authorizeSpellManagement(w, r, characterID) {
+		return
+	}
+
+	summary, err := h.spellService.ForgetSpell(r.Context(), characterID, req.Spell)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, summary)
+}
+
+// PrepareSpells replaces the list of spells a character has prepared for the day
+func (h *Handlers) PrepareSpells(w http.ResponseWriter, r *http.Request) {
+	characterID := mux.Vars(r)["id"]
+
+	var req struct {
+		Spells []string `json:"spells"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+
+	if !h.authorizeSpellManagement(w, r, characterID) {
+		return
+	}
+
+	summary, err := h.spellService.PrepareSpells(r.Context(), characterID, req.Spells)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, summary)
+}
+
+func (h *Handlers) authorizeSpellManagement(w http.ResponseWriter, r *http.Request, characterID string) bool {
+	if h.spellService == nil {
+		response.BadRequest(w, r, "Spell management is not available")
+		return false
+	}
+	_, ok := h.authorizeCharacterAccess(w, r, characterID, characterOwnerOnly)
+	return ok
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/response"
+)
+
+const errStartingEquipmentUnavailable = "Starting equipment is not available"
+
+// GetStartingEquipmentOptions returns the equipment choices for a class and background
+func (h *Handlers) GetStartingEquipmentOptions(w http.ResponseWriter, r *http.Request) {
+	if h.startingEquipment == nil {
+		response.BadRequest(w, r, errStartingEquipmentUnavailable)
+		return
+	}
+
+	class := r.URL.Query().Get("class")
+	if class == "" {
+		response.BadRequest(w, r, "class is required")
+		return
+	}
+
+	options, err := h.startingEquipment.GetStartingEquipmentOptions(class, r.URL.Query().Get("background"))
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, options)
+}
+
+// GetStartingEquipment returns the starting equipment already granted to a character
+func (h *Handlers) GetStartingEquipment(w http.ResponseWriter, r *http.Request) {
+	characterID := mux.Vars(r)["id"]
+	if !h.authorizeStartingEquipment(w, r, characterID) {
+		return
+	}
+
+	grant, err := h.startingEquipment.GetStartingEquipmentGrant(characterID)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+	if grant == nil {
+		response.NotFound(w, r, "Starting equipment has not been granted")
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, grant)
+}
+
+// ApplyStartingEquipment grants the selected starting equipment or starting gold
+func (h *Handlers) ApplyStartingEquipment(w http.ResponseWriter, r *http.Request) {
+	characterID := mux.Vars(r)["id"]
+
+	var selection models.StartingEquipmentSelection
+	if err := json.NewDecoder(r.Body).Decode(&selection); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+
+	if !h.authorizeStartingEquipment(w, r, characterID) {
+		return
+	}
+
+	grant, err := h.startingEquipment.ApplyStartingEquipment(r.Context(), characterID, &selection)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusCreated, grant)
+}
+
+// authorizeStartingEquipment checks the service is available and the character
+// belongs to the authenticated user, writing the error response if not.
+func (h *Handlers) authorizeStartingEquipment(w http.ResponseWriter, r *http.Request, characterID string) bool {
+	if h.startingEquipment == nil {
+		response.BadRequest(w, r, errStartingEquipmentUnavailable)
+		return false
+	}
+	_, ok := h.authorizeCharacterAccess(w, r, characterID, characterOwnerOnly)
+	return ok
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/auth"
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/internal/services"
+	"github.com/ctclostio/DnD-Game/backend/pkg/response"
+)
+
+// GetSessionTimeline handles GET /api/game/sessions/{id}/timeline, oldest first. It takes
+// the type (comma separated), player, character, since, until, after and limit query
+// parameters; after is the nextCursor of the previous page.
+func (h *Handlers) GetSessionTimeline(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if err := validateUserSession(w, r, h.gameService, sessionID); err != nil {
+		return
+	}
+	filter, ok := timelineFilterFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.eventService.GetSessionTimeline(r.Context(), sessionID, filter)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+	page.Events = h.visibleEvents(r, sessionID, page.Events)
+
+	response.JSON(w, r, http.StatusOK, page)
+}
+
+// RecordSessionEvent handles POST /api/game/sessions/{id}/timeline, for the DM to note
+// what the other services don't record, such as a scene change or the passing of time.
+// Setting dmOnly in the data keeps the note from everyone else.
+func (h *Handlers) RecordSessionEvent(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if !h.authorizeSessionDM(w, r, sessionID) {
+		return
+	}
+	userID, _ := auth.GetUserIDFromContext(r.Context())
+
+	var req models.GameEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+	if req.Type == "" {
+		response.BadRequest(w, r, "Event type is required")
+		return
+	}
+
+	event := &models.GameEvent{
+		SessionID:   sessionID,
+		Type:        req.Type,
+		PlayerID:    userID,
+		CharacterID: req.CharacterID,
+		Data:        req.Data,
+		GameTime:    req.GameTime,
+	}
+	if err := h.eventService.Record(r.Context(), event); err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusCreated, event)
+}
+
+// GetSessionTimelineSummary handles GET /api/game/sessions/{id}/timeline/summary
+func (h *Handlers) GetSessionTimelineSummary(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if err := validateUserSession(w, r, h.gameService, sessionID); err != nil {
+		return
+	}
+
+	summary, err := h.eventService.Summarize(r.Context(), sessionID, h.viewerRole(r, sessionID))
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, summary)
+}
+
+// ExportSessionTimeline handles GET /api/game/sessions/{id}/timeline/export, the whole
+// log as a JSON download. It takes the same filters as the timeline, bar paging.
+func (h *Handlers) ExportSessionTimeline(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if err := validateUserSession(w, r, h.gameService, sessionID); err != nil {
+		return
+	}
+	filter, ok := timelineFilterFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	events, err := h.eventService.GetSessionEvents(r.Context(), sessionID, filter)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+	events = h.visibleEvents(r, sessionID, events)
+
+	w.Header().Set(constants.ContentType, constants.ApplicationJSON)
+	w.Header().Set("Content-Disposition", "attachment; filename=session-"+sessionID+"-timeline.json")
+	if err := json.NewEncoder(w).Encode(events); err != nil {
+		http.Error(w, constants.ErrFailedToEncode, http.StatusInternalServerError)
+	}
+}
+
+// GetCharacterTimeline handles GET /api/characters/{id}/timeline, everything that
+// happened to the character in every session, oldest first. It takes the same query
+// parameters as the session timeline.
+func (h *Handlers) GetCharacterTimeline(w http.ResponseWriter, r *http.Request) {
+	characterID := mux.Vars(r)["id"]
+	if _, ok := h.authorizeCharacterAccess(w, r, characterID, characterOwnerOrDM); !ok {
+		return
+	}
+	filter, ok := timelineFilterFromQuery(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.eventService.GetCharacterTimeline(r.Context(), characterID, filter)
+	if err != nil {
+		response.InternalServerError(w, r, err)
+		return
+	}
+	page.Events = h.visibleCharacterEvents(r, page.Events)
+
+	response.JSON(w, r, http.StatusOK, page)
+}
+
+// visibleEvents drops the events the user may not see from a page of the session's log.
+// The page keeps its cursor, so paging on carries on past the dropped events.
+func (h *Handlers) visibleEvents(r *http.Request, sessionID string, events []*models.GameEvent) []*models.GameEvent {
+	return services.VisibleEvents(events, h.viewerRole(r, sessionID))
+}
+
+// visibleCharacterEvents drops the events the user may not see from a page of a
+// character's timeline, which spans every session the character played in
+func (h *Handlers) visibleCharacterEvents(r *http.Request, events []*models.GameEvent) []*models.GameEvent {
+	roles := make(map[string]models.ParticipantRole)
+	visible := make([]*models.GameEvent, 0, len(events))
+	for _, event := range events {
+		role, ok := roles[event.SessionID]
+		if !ok {
+			role = h.viewerRole(r, event.SessionID)
+			roles[event.SessionID] = role
+		}
+		if event.VisibleTo(role) {
+			visible = append(visible, event)
+		}
+	}
+	return visible
+}
+
+// viewerRole is the user's role in a session, or spectator when it can't be told, so
+// nothing hidden leaks on an error
+func (h *Handlers) viewerRole(r *http.Request, sessionID string) models.ParticipantRole {
+	userID, _ := auth.GetUserIDFromContext(r.Context())
+	role, err := h.gameService.ParticipantRole(r.Context(), sessionID, userID)
+	if err != nil {
+		return models.ParticipantRoleSpectator
+	}
+	return role
+}
+
+func timelineFilterFromQuery(w http.ResponseWriter, r *http.Request) (models.GameEventFilter, bool) {
+	query := r.URL.Query()
+	filter := models.GameEventFilter{
+		CharacterID: query.Get("character"),
+		PlayerID:    query.Get("player"),
+		After:       query.Get("after"),
+	}
+
+	if types := query.Get("type"); types != "" {
+		filter.Types = strings.Split(types, ",")
+	}
+	for _, bound := range []struct {
+		name   string
+		target **time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		value := query.Get(bound.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			response.BadRequest(w, r, bound.name+" must be an RFC3339 timestamp")
+			return filter, false
+		}
+		*bound.target = &parsed
+	}
+	if limit := query.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed < 1 {
+			response.BadRequest(w, r, "limit must be a positive number")
+			return filter, false
+		}
+		filter.Limit = parsed
+	}
+	return filter, true
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/response"
+)
+
+const errMsgTreasureUnavailable = "Treasure generation is not available"
+
+// GenerateTreasure handles POST /api/dm/assistant/treasure/generate, rolling treasure on the
+// treasure tables. Send back the returned seed to roll the same treasure again.
+func (h *Handlers) GenerateTreasure(w http.ResponseWriter, r *http.Request) {
+	if h.treasureService == nil {
+		response.BadRequest(w, r, errMsgTreasureUnavailable)
+		return
+	}
+
+	var req models.TreasureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+
+	hoard, err := h.treasureService.Generate(r.Context(), &req)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	response.JSON(w, r, http.StatusOK, hoard)
+}
+
+// GenerateTreasureLootPool handles POST /api/game/sessions/{id}/treasure, rolling treasure
+// straight into a loot pool for the session's characters
+func (h *Handlers) GenerateTreasureLootPool(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["id"]
+	if !h.authorizeSessionDM(w, r, sessionID) {
+		return
+	}
+	if h.treasureService == nil {
+		response.BadRequest(w, r, errMsgTreasureUnavailable)
+		return
+	}
+
+	var req models.TreasurePoolRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, r, constants.ErrInvalidRequestBody)
+		return
+	}
+
+	treasure, err := h.treasureService.GenerateLootPool(r.Context(), sessionID, &req)
+	if err != nil {
+		response.BadRequest(w, r, err.Error())
+		return
+	}
+
+	if treasure.Pool != nil {
+		h.broadcastPartyEvent(r, sessionID, partyEvent{Event: "loot_pool_created", LootPool: treasure.Pool})
+	}
+	response.JSON(w, r, http.StatusCreated, treasure)
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/internal/websocket"
+	"github.com/ctclostio/DnD-Game/backend/pkg/logger"
+)
+
+// Room events the websocket commands broadcast
+const (
+	wsEventChat     = "chat"
+	wsEventDiceRoll = "dice_roll"
+	wsEventCombat   = "combat"
+)
+
+// DiceRollCommand rolls dice in the caller's game session
+type DiceRollCommand struct {
+	Notation string `json:"notation" validate:"required"`
+	Purpose  string `json:"purpose,omitempty"`
+}
+
+// CombatActionCommand takes a combat action for a combatant the caller controls
+type CombatActionCommand struct {
+	CombatID string `json:"combatId" validate:"required"`
+	models.CombatRequest
+}
+
+// CombatActionResult is the action taken and the combat it left behind
+type CombatActionResult struct {
+	Action *models.CombatAction `json:"action"`
+	Combat *models.Combat       `json:"combat,omitempty"`
+}
+
+// ChatCommand sends a chat message to the caller's game session, the same way
+// POST /api/v1/game/sessions/{id}/chat does
+type ChatCommand struct {
+	models.ChatMessageRequest
+}
+
+// DiceRollEvent is a dice roll as the room sees it
+type DiceRollEvent struct {
+	PlayerName string           `json:"playerName"`
+	DiceType   string           `json:"diceType"`
+	Purpose    string           `json:"purpose,omitempty"`
+	Result     DiceRollTotals   `json:"result"`
+	Roll       *models.DiceRoll `json:"roll"`
+}
+
+// DiceRollTotals are the dice and total of a roll
+type DiceRollTotals struct {
+	Total int   `json:"total"`
+	Rolls []int `json:"rolls"`
+}
+
+// WebSocketProtocol registers the commands protocol v2 clients can run over the socket.
+// Each checks the caller the same way the matching HTTP endpoint does.
+func (h *Handlers) WebSocketProtocol() *websocket.Protocol {
+	protocol := websocket.NewProtocol()
+	websocket.RegisterCommand(protocol, "dice.roll", "Roll dice in the game session and show the room", h.wsRollDice)
+	websocket.RegisterCommand(protocol, "combat.action", "Take a combat action for a combatant you control", h.wsCombatAction)
+	websocket.RegisterCommand(protocol, "chat.send", "Send a chat message to the game session", h.wsSendChat)
+
+	protocol.RegisterEvent(wsEventChat, "A chat message; whispers reach only their sender and recipients", models.ChatMessage{})
+	protocol.RegisterEvent(wsEventDiceRoll, "A dice roll", DiceRollEvent{})
+	protocol.RegisterEvent(wsEventCombat, "A change to a combat", models.CombatUpdate{})
+	return protocol
+}
+
+// WebSocketHandler creates the websocket endpoint, offering the commands of
+// WebSocketProtocol to clients that negotiate protocol v2. A client joins its game
+// session's room as the session's DM, a player or a spectator.
+func (h *Handlers) WebSocketHandler(log *logger.LoggerV2) *websocket.HandlerV2 {
+	handler := websocket.NewHandlerV2(h.websocketHub, h.jwtManager, log)
+	handler.SetProtocol(h.WebSocketProtocol())
+	handler.SetRoomRoles(func(ctx context.Context, sessionID, userID string) (string, error) {
+		role, err := h.gameService.ParticipantRole(ctx, sessionID, userID)
+		return string(role), err
+	})
+	return handler
+}
+
+// WebSocketSchema handles GET /api/v1/ws/schema, describing the websocket protocol as a
+// JSON document generated from WebSocketProtocol
+func (h *Handlers) WebSocketSchema(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(h.WebSocketProtocol().Schema())
+}
+
+func (h *Handlers) wsRollDice(ctx context.Context, call *websocket.Call, cmd *DiceRollCommand) (*models.DiceRoll, error) {
+	if err := h.requireSessionPlayer(ctx, call); err != nil {
+		return nil, err
+	}
+
+	roll := &models.DiceRoll{
+		GameSessionID: call.RoomID,
+		UserID:        call.UserID,
+		RollNotation:  cmd.Notation,
+		Purpose:       cmd.Purpose,
+	}
+	if err := h.diceService.RollDice(ctx, roll); err != nil {
+		return nil, err
+	}
+
+	_ = call.Broadcast(wsEventDiceRoll, DiceRollEvent{
+		PlayerName: call.Username,
+		DiceType:   roll.RollNotation,
+		Purpose:    roll.Purpose,
+		Result:     DiceRollTotals{Total: roll.Total, Rolls: roll.Results},
+		Roll:       roll,
+	})
+	return roll, nil
+}
+
+func (h *Handlers) wsCombatAction(ctx context.Context, call *websocket.Call, cmd *CombatActionCommand) (*CombatActionResult, error) {
+	if cmd.Action == "" || cmd.ActorID == "" {
+		return nil, &websocket.CommandError{
+			Code:    websocket.ErrCodeInvalidParams,
+			Message: "Invalid params",
+			Fields:  map[string]string{"action": "required", "actorId": "required"},
+		}
+	}
+
+	if err := h.requireSessionPlayer(ctx, call); err != nil {
+		return nil, err
+	}
+
+	combat, err := h.combatService.GetCombat(ctx, cmd.CombatID)
+	if err != nil {
+		return nil, websocket.NewCommandError(websocket.ErrCodeNotFound, "Combat not found")
+	}
+	if combat.GameSessionID != call.RoomID || !h.canControlCombatant(ctx, call.UserID, combat, cmd.ActorID) {
+		return nil, websocket.NewCommandError(websocket.ErrCodeForbidden, "You cannot control this combatant")
+	}
+
+	action, err := h.combatService.ProcessAction(ctx, cmd.CombatID, cmd.CombatRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedCombat, _ := h.combatService.GetCombat(ctx, cmd.CombatID)
+	h.broadcastCombatUpdate(combat.GameSessionID, models.CombatUpdate{
+		Type:    models.UpdateTypeAction,
+		Combat:  updatedCombat,
+		Action:  action,
+		Message: action.Description,
+	})
+	return &CombatActionResult{Action: action, Combat: updatedCombat}, nil
+}
+
+func (h *Handlers) wsSendChat(ctx context.Context, call *websocket.Call, cmd *ChatCommand) (*models.ChatMessage, error) {
+	if err := h.requireSessionMember(ctx, call); err != nil {
+		return nil, err
+	}
+
+	message, err := h.chatService.SendMessage(ctx, call.RoomID, call.UserID, call.Username, &cmd.ChatMessageRequest)
+	if err != nil {
+		return nil, websocket.NewCommandError(websocket.ErrCodeInvalidParams, err.Error())
+	}
+	if err := call.BroadcastTo(wsEventChat, message, chatAudience(message)); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// requireSessionMember checks the caller belongs to the game session their room is for
+func (h *Handlers) requireSessionMember(ctx context.Context, call *websocket.Call) error {
+	if call.RoomID == "" {
+		return websocket.NewCommandError(websocket.ErrCodeForbidden, "Join a game session first")
+	}
+	if err := h.gameService.ValidateUserInSession(ctx, call.RoomID, call.UserID); err != nil {
+		return websocket.NewCommandError(websocket.ErrCodeForbidden, "User is not a participant in this game session")
+	}
+	return nil
+}
+
+// requireSessionPlayer checks the caller takes part in the game session their room is for
+// as its DM or a player. Spectators only watch.
+func (h *Handlers) requireSessionPlayer(ctx context.Context, call *websocket.Call) error {
+	if call.RoomID == "" {
+		return websocket.NewCommandError(websocket.ErrCodeForbidden, "Join a game session first")
+	}
+	role, err := h.gameService.ParticipantRole(ctx, call.RoomID, call.UserID)
+	if err != nil {
+		return websocket.NewCommandError(websocket.ErrCodeForbidden, "User is not a participant in this game session")
+	}
+	if role == models.ParticipantRoleSpectator {
+		return websocket.NewCommandError(websocket.ErrCodeForbidden, "Spectators cannot take actions")
+	}
+	return nil
+}
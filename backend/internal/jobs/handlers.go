@@ -20,6 +20,7 @@ type JobHandlers struct {
 	campaignService  services.CampaignServiceInterface
 	exportService    services.ExportServiceInterface
 	cleanupService   services.CleanupServiceInterface
+	reminderService  services.SessionReminderServiceInterface
 }
 
 // NewJobHandlers creates a new job handlers instance
@@ -43,6 +44,11 @@ func NewJobHandlers(
 	}
 }
 
+// SetSessionReminderService sets the service that writes reminders for scheduled sessions
+func (jh *JobHandlers) SetSessionReminderService(reminderService services.SessionReminderServiceInterface) {
+	jh.reminderService = reminderService
+}
+
 // RegisterAll registers all job handlers with the queue
 func (jh *JobHandlers) RegisterAll(queue *JobQueue) {
 	queue.RegisterHandler(JobTypeAIContentGeneration, jh.HandleAIGeneration)
@@ -54,6 +60,7 @@ func (jh *JobHandlers) RegisterAll(queue *JobQueue) {
 	queue.RegisterHandler(JobTypeImageOptimization, jh.HandleImageOptimization)
 	queue.RegisterHandler(JobTypeAnalyticsProcess, jh.HandleAnalyticsProcess)
 	queue.RegisterHandler(JobTypeCleanupExpired, jh.HandleCleanupExpired)
+	queue.RegisterHandler(JobTypeSessionReminder, jh.HandleSessionReminder)
 }
 
 // HandleAIGeneration processes AI content generation jobs
@@ -163,6 +170,42 @@ func (jh *JobHandlers) HandleEmailNotification(ctx context.Context, task *asynq.
 	return nil
 }
 
+// HandleSessionReminder emails a scheduled session's members before it starts
+func (jh *JobHandlers) HandleSessionReminder(ctx context.Context, task *asynq.Task) error {
+	var payload SessionReminderPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf(ErrFailedToUnmarshalPayload, err)
+	}
+
+	if jh.reminderService == nil || jh.emailService == nil {
+		jh.logger.Debug().Msg("Session reminders not available")
+		return nil
+	}
+
+	reminder, err := jh.reminderService.SessionReminder(ctx, payload.ScheduleID, payload.StartsAt)
+	if err != nil {
+		return fmt.Errorf("failed to prepare session reminder: %w", err)
+	}
+	if reminder == nil {
+		jh.logger.Debug().
+			Str("schedule_id", payload.ScheduleID).
+			Time("starts_at", payload.StartsAt).
+			Msg("Session reminder no longer needed")
+		return nil
+	}
+
+	jh.logger.Info().
+		Str("schedule_id", payload.ScheduleID).
+		Time("starts_at", payload.StartsAt).
+		Int("recipients", len(reminder.To)).
+		Msg("Sending session reminder")
+
+	if err := jh.emailService.Send(ctx, reminder.To, reminder.Subject, reminder.Body, false); err != nil {
+		return fmt.Errorf("failed to send session reminder: %w", err)
+	}
+	return nil
+}
+
 // HandleReportGeneration generates reports
 func (jh *JobHandlers) HandleReportGeneration(ctx context.Context, task *asynq.Task) error {
 	var payload struct {
@@ -240,11 +283,14 @@ func (jh *JobHandlers) HandleDataExport(ctx context.Context, task *asynq.Task) e
 		return fmt.Errorf("export failed: %w", err)
 	}
 
-	// Store result
+	// Store result; rendered documents such as PDF sheets are stored as-is
 	if exportData != nil {
-		resultData, err := json.Marshal(exportData)
-		if err != nil {
-			return fmt.Errorf("failed to marshal export data: %w", err)
+		resultData, isRendered := exportData.([]byte)
+		if !isRendered {
+			resultData, err = json.Marshal(exportData)
+			if err != nil {
+				return fmt.Errorf("failed to marshal export data: %w", err)
+			}
 		}
 		if _, err := task.ResultWriter().Write(resultData); err != nil {
 			return fmt.Errorf("failed to write result: %w", err)
@@ -0,0 +1,26 @@
+package jobs
+
+import "github.com/ctclostio/DnD-Game/backend/internal/services"
+
+// ReminderScheduler queues the reminders for scheduled sessions and writes each one when
+// it comes due
+type ReminderScheduler interface {
+	services.SessionReminderServiceInterface
+	SetReminderQueue(reminders services.SessionReminderQueue)
+}
+
+// InviteMailer queues the emails that carry invites to private sessions
+type InviteMailer interface {
+	SetEmailQueue(emails services.EmailQueue)
+}
+
+// SetupNotifications registers the job handlers on the queue and has the scheduling
+// service queue its session reminders, and the invite service its emails, there. The
+// handlers write each reminder with the scheduling service and send all of it with their
+// email service.
+func SetupNotifications(queue *JobQueue, handlers *JobHandlers, scheduling ReminderScheduler, invites InviteMailer) {
+	handlers.SetSessionReminderService(scheduling)
+	handlers.RegisterAll(queue)
+	scheduling.SetReminderQueue(queue)
+	invites.SetEmailQueue(queue)
+}
@@ -0,0 +1,96 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/config"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/internal/services"
+	"github.com/ctclostio/DnD-Game/backend/pkg/logger"
+)
+
+type mockEmailService struct {
+	mock.Mock
+}
+
+func (m *mockEmailService) Send(ctx context.Context, to []string, subject, body string, isHTML bool) error {
+	return m.Called(ctx, to, subject, body, isHTML).Error(0)
+}
+
+func (m *mockEmailService) SendWithAttachment(ctx context.Context, to []string, subject, body string, isHTML bool, attachments []services.Attachment) error {
+	return m.Called(ctx, to, subject, body, isHTML, attachments).Error(0)
+}
+
+func (m *mockEmailService) SendTemplate(ctx context.Context, to []string, templateName string, data interface{}) error {
+	return m.Called(ctx, to, templateName, data).Error(0)
+}
+
+// fakeScheduler writes the same reminder for every session and remembers its queue
+type fakeScheduler struct {
+	reminder *models.SessionReminder
+	queue    services.SessionReminderQueue
+}
+
+func (f *fakeScheduler) SessionReminder(ctx context.Context, scheduleID string, startsAt time.Time) (*models.SessionReminder, error) {
+	return f.reminder, nil
+}
+
+func (f *fakeScheduler) SetReminderQueue(reminders services.SessionReminderQueue) {
+	f.queue = reminders
+}
+
+// fakeInvites remembers the queue its invite emails go to
+type fakeInvites struct {
+	queue services.EmailQueue
+}
+
+func (f *fakeInvites) SetEmailQueue(emails services.EmailQueue) {
+	f.queue = emails
+}
+
+func newTestQueue(t *testing.T) *JobQueue {
+	t.Helper()
+	// Nothing here reaches Redis: tasks are handed straight to the queue's handlers
+	queue, err := NewJobQueue(&config.RedisConfig{Host: "localhost", Port: 6379}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = queue.client.Close() })
+	return queue
+}
+
+func TestSetupNotifications(t *testing.T) {
+	cfg := logger.DefaultConfig()
+	log, err := logger.NewV2(&cfg)
+	require.NoError(t, err)
+
+	queue := newTestQueue(t)
+	email := new(mockEmailService)
+	scheduling := &fakeScheduler{reminder: &models.SessionReminder{
+		To:      []string{"alice@example.com"},
+		Subject: "Reminder: Lost Mine",
+		Body:    "Lost Mine starts soon.",
+	}}
+
+	invites := &fakeInvites{}
+
+	SetupNotifications(queue, NewJobHandlers(log, nil, email, nil, nil, nil, nil), scheduling, invites)
+
+	assert.Same(t, queue, scheduling.queue, "scheduling queues its reminders on the job queue")
+	assert.Same(t, queue, invites.queue, "invites queue their emails on the job queue")
+
+	email.On("Send", mock.Anything, []string{"alice@example.com"}, "Reminder: Lost Mine", "Lost Mine starts soon.", false).Return(nil).Once()
+	payload, _ := json.Marshal(SessionReminderPayload{ScheduleID: "schedule-1", StartsAt: time.Now().Add(time.Hour)})
+	require.NoError(t, queue.mux.ProcessTask(context.Background(), asynq.NewTask(string(JobTypeSessionReminder), payload)))
+
+	email.On("Send", mock.Anything, []string{"bob@example.com"}, "You're invited", "Join us.", false).Return(nil).Once()
+	payload, _ = json.Marshal(EmailPayload{To: []string{"bob@example.com"}, Subject: "You're invited", Body: "Join us."})
+	require.NoError(t, queue.mux.ProcessTask(context.Background(), asynq.NewTask(string(JobTypeEmailNotification), payload)))
+	email.AssertExpectations(t)
+}
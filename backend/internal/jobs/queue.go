@@ -3,6 +3,7 @@ package jobs
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -26,6 +27,7 @@ const (
 	JobTypeImageOptimization   JobType = "image:optimize"
 	JobTypeAnalyticsProcess    JobType = "analytics:process"
 	JobTypeCleanupExpired      JobType = "cleanup:expired"
+	JobTypeSessionReminder     JobType = "session:reminder"
 	
 	// Queue names
 	QueueCritical = "critical"
@@ -246,6 +248,34 @@ func (jq *JobQueue) Enqueue(ctx context.Context, jobType JobType, payload interf
 	return info, nil
 }
 
+// EnqueueEmail queues an email for the email worker to send
+func (jq *JobQueue) EnqueueEmail(ctx context.Context, to []string, subject, body string, isHTML bool) error {
+	_, err := jq.Enqueue(ctx, JobTypeEmailNotification, EmailPayload{
+		To:      to,
+		Subject: subject,
+		Body:    body,
+		HTML:    isHTML,
+	})
+	return err
+}
+
+// EnqueueSessionReminder schedules the reminder for an occurrence of a scheduled session.
+// Each occurrence is queued once; queueing it again is a no-op.
+func (jq *JobQueue) EnqueueSessionReminder(ctx context.Context, scheduleID string, startsAt, sendAt time.Time) error {
+	opts := DefaultJobOptions()
+	opts.ProcessAt = sendAt
+	opts.TaskID = fmt.Sprintf("session-reminder:%s:%d", scheduleID, startsAt.Unix())
+
+	_, err := jq.Enqueue(ctx, JobTypeSessionReminder, SessionReminderPayload{
+		ScheduleID: scheduleID,
+		StartsAt:   startsAt,
+	}, opts)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		return nil
+	}
+	return err
+}
+
 // Start begins processing jobs
 func (jq *JobQueue) Start() error {
 	if jq.logger != nil {
@@ -303,6 +333,11 @@ func (jq *JobQueue) GetQueueStats() (map[string]*asynq.QueueInfo, error) {
 	return stats, nil
 }
 
+// Ping checks that Redis can be reached
+func (jq *JobQueue) Ping() error {
+	return jq.client.Ping()
+}
+
 // HealthCheck verifies the job queue is functional
 func (jq *JobQueue) HealthCheck(ctx context.Context) error {
 	// Try to get queue stats
@@ -404,4 +439,10 @@ type BackupPayload struct {
 type CleanupPayload struct {
 	Type      string    `json:"type"` // expired_tokens, old_sessions, etc.
 	OlderThan time.Time `json:"older_than"`
-}
\ No newline at end of file
+}
+
+// SessionReminderPayload identifies the occurrence of a scheduled session to remind its members of
+type SessionReminderPayload struct {
+	ScheduleID string    `json:"schedule_id"`
+	StartsAt   time.Time `json:"starts_at"`
+}
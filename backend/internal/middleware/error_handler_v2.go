@@ -1,8 +1,10 @@
 package middleware
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"runtime/debug"
 
@@ -61,6 +63,14 @@ type panicCapturingResponseWriter struct {
 	requestID string
 }
 
+// Hijack implements http.Hijacker for WebSocket support
+func (w *panicCapturingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, fmt.Errorf("response writer does not support hijacking")
+}
+
 func (w *panicCapturingResponseWriter) handlePanic(rec interface{}, r *http.Request) {
 	// Log the panic with stack trace
 	stackTrace := string(debug.Stack())
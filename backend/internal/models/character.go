@@ -66,6 +66,7 @@ type Spell struct {
 	Duration    string `json:"duration" db:"duration"`
 	Description string `json:"description" db:"description"`
 	Prepared    bool   `json:"prepared,omitempty" db:"prepared"`
+	Ritual      bool   `json:"ritual,omitempty" db:"ritual"`
 }
 
 type SavingThrows struct {
@@ -103,6 +104,7 @@ type SpellData struct {
 	SpellSlots          []SpellSlot `json:"spellSlots,omitempty" db:"spell_slots"`
 	SpellsKnown         []Spell     `json:"spellsKnown,omitempty" db:"spells_known"`
 	CantripsKnown       int         `json:"cantripsKnown,omitempty" db:"cantrips_known"`
+	PreparedAt          *time.Time  `json:"preparedAt,omitempty" db:"prepared_at"`
 }
 
 type SpellSlot struct {
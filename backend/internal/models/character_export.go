@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// CharacterExportSchemaVersion is bumped whenever the interchange document changes shape.
+// The matching JSON Schema lives in data/schemas/character-v<version>.schema.json.
+const CharacterExportSchemaVersion = 1
+
+// CharacterExportSchemaID identifies the interchange format inside exported documents
+const CharacterExportSchemaID = "https://dnd-game.app/schemas/character-v1.schema.json"
+
+// CharacterExportFormat is an output format for character exports
+type CharacterExportFormat string
+
+const (
+	CharacterExportJSON CharacterExportFormat = "json"
+	CharacterExportPDF  CharacterExportFormat = "pdf"
+)
+
+// CharacterExportDocument is the versioned interchange document for a single character.
+// The sheet carries the character's spells; inventory and currency are exported alongside it.
+type CharacterExportDocument struct {
+	Schema        string                  `json:"$schema"`
+	SchemaVersion int                     `json:"schemaVersion"`
+	ExportedAt    time.Time               `json:"exportedAt"`
+	Character     *Character              `json:"character"`
+	Inventory     []ExportedInventoryItem `json:"inventory"`
+	Currency      ExportedCurrency        `json:"currency"`
+	// History is the character's timeline from the session event log; it is not imported
+	History []*GameEvent `json:"history,omitempty"`
+}
+
+// ExportedInventoryItem is an inventory entry with its full item definition, so the
+// receiving server can recreate items it does not know about
+type ExportedInventoryItem struct {
+	Quantity         int            `json:"quantity"`
+	Equipped         bool           `json:"equipped"`
+	Attuned          bool           `json:"attuned"`
+	CustomProperties ItemProperties `json:"customProperties,omitempty"`
+	Notes            string         `json:"notes,omitempty"`
+	Item             *Item          `json:"item"`
+}
+
+// ExportedCurrency is a character's purse
+type ExportedCurrency struct {
+	Copper   int `json:"copper"`
+	Silver   int `json:"silver"`
+	Electrum int `json:"electrum"`
+	Gold     int `json:"gold"`
+	Platinum int `json:"platinum"`
+}
+
+// CharacterImportResult reports the character created from an interchange document
+type CharacterImportResult struct {
+	Character     *Character `json:"character"`
+	ItemsImported int        `json:"itemsImported"`
+	Warnings      []string   `json:"warnings,omitempty"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+// ResourceRecovery describes when a tracked class resource refills
+type ResourceRecovery string
+
+const (
+	// RecoveryShortRest refills on a short or long rest
+	RecoveryShortRest ResourceRecovery = "short_rest"
+	// RecoveryLongRest refills only on a long rest
+	RecoveryLongRest ResourceRecovery = "long_rest"
+	// RecoveryDawn refills at dawn each day
+	RecoveryDawn ResourceRecovery = "dawn"
+	// RecoveryCustom is never refilled automatically
+	RecoveryCustom ResourceRecovery = "custom"
+)
+
+// Well-known resource keys
+const (
+	ResourceHitDice           = "hit_dice"
+	ResourceRage              = "rage"
+	ResourceBardicInspiration = "bardic_inspiration"
+	ResourceChannelDivinity   = "channel_divinity"
+	ResourceKi                = "ki"
+	ResourceSorceryPoints     = "sorcery_points"
+	ResourceActionSurge       = "action_surge"
+	ResourceSecondWind        = "second_wind"
+	ResourceWildShape         = "wild_shape"
+	ResourceLayOnHands        = "lay_on_hands"
+)
+
+// Rest types and limits
+const (
+	RestTypeShort      = "short"
+	RestTypeLong       = "long"
+	RestTypeDawn       = "dawn" // not a rest, but when dawn-recharging resources and items renew
+	MaxExhaustionLevel = 6
+)
+
+// CharacterResource is one entry in a character's resource ledger
+type CharacterResource struct {
+	ID          string           `json:"id" db:"id"`
+	CharacterID string           `json:"characterId" db:"character_id"`
+	Key         string           `json:"key" db:"resource_key"`
+	Name        string           `json:"name" db:"name"`
+	Current     int              `json:"current" db:"current_uses"`
+	Max         int              `json:"max" db:"max_uses"`
+	Recovery    ResourceRecovery `json:"recovery" db:"recovery"`
+	Die         string           `json:"die,omitempty" db:"die"` // e.g. "d10" for hit dice or "d6" for bardic inspiration
+	Custom      bool             `json:"custom" db:"custom"`     // added by the DM rather than derived from class
+	CreatedAt   time.Time        `json:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time        `json:"updatedAt" db:"updated_at"`
+}
+
+// CharacterRestState tracks exhaustion and rest timestamps for a character
+type CharacterRestState struct {
+	CharacterID     string     `json:"characterId" db:"character_id"`
+	ExhaustionLevel int        `json:"exhaustionLevel" db:"exhaustion_level"`
+	LastShortRestAt *time.Time `json:"lastShortRestAt,omitempty" db:"last_short_rest_at"`
+	LastLongRestAt  *time.Time `json:"lastLongRestAt,omitempty" db:"last_long_rest_at"`
+	UpdatedAt       time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// RestResult summarizes what a short or long rest recovered
+type RestResult struct {
+	RestType           string               `json:"restType"`
+	HitDiceSpent       int                  `json:"hitDiceSpent"`
+	HitDiceRolls       []int                `json:"hitDiceRolls,omitempty"`
+	HitDiceRecovered   int                  `json:"hitDiceRecovered"`
+	HitPointsRecovered int                  `json:"hitPointsRecovered"`
+	ResourcesRestored  map[string]int       `json:"resourcesRestored"`
+	ItemsRecharged     map[string]int       `json:"itemsRecharged,omitempty"` // charges regained by item ID
+	ExhaustionLevel    int                  `json:"exhaustionLevel"`
+	Character          *Character           `json:"character"`
+	Resources          []*CharacterResource `json:"resources"`
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// CharacterChangeType describes what kind of change produced a character version
+type CharacterChangeType string
+
+const (
+	CharacterChangeCreate     CharacterChangeType = "create"
+	CharacterChangeUpdate     CharacterChangeType = "update"
+	CharacterChangeExperience CharacterChangeType = "experience"
+	CharacterChangeLevelUp    CharacterChangeType = "level_up"
+	CharacterChangeRest       CharacterChangeType = "rest"
+	CharacterChangeSpells     CharacterChangeType = "spells"
+	CharacterChangeInventory  CharacterChangeType = "inventory"
+	CharacterChangeRestore    CharacterChangeType = "restore"
+)
+
+// CharacterSnapshot is a JSON-backed copy of a character sheet
+type CharacterSnapshot Character
+
+func (c CharacterSnapshot) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+func (c *CharacterSnapshot) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(b, c)
+}
+
+// CharacterVersion is an immutable snapshot of a character taken after a change
+type CharacterVersion struct {
+	ID          string              `json:"id" db:"id"`
+	CharacterID string              `json:"characterId" db:"character_id"`
+	Version     int                 `json:"version" db:"version"`
+	ChangeType  CharacterChangeType `json:"changeType" db:"change_type"`
+	Reason      string              `json:"reason,omitempty" db:"reason"`
+	ChangedBy   string              `json:"changedBy,omitempty" db:"changed_by"`
+	Snapshot    *CharacterSnapshot  `json:"snapshot,omitempty" db:"snapshot"`
+	CreatedAt   time.Time           `json:"createdAt" db:"created_at"`
+}
+
+// CharacterFieldChange is a single field that differs between two versions, e.g. "attributes.strength"
+type CharacterFieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// CharacterVersionDiff lists the field changes between two versions
+type CharacterVersionDiff struct {
+	CharacterID string                 `json:"characterId"`
+	FromVersion int                    `json:"fromVersion"`
+	ToVersion   int                    `json:"toVersion"`
+	Changes     []CharacterFieldChange `json:"changes"`
+}
+
+// CharacterAudit summarizes what changed on a character over a period, such as between sessions
+type CharacterAudit struct {
+	CharacterID string                 `json:"characterId"`
+	Since       time.Time              `json:"since"`
+	Versions    []*CharacterVersion    `json:"versions"`
+	Changes     []CharacterFieldChange `json:"changes"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// ChatChannel separates what characters say from what their players say
+type ChatChannel string
+
+const (
+	ChatChannelIC  ChatChannel = "ic"  // in character
+	ChatChannelOOC ChatChannel = "ooc" // out of character
+)
+
+// ChatKind is how a chat message is shown
+type ChatKind string
+
+const (
+	ChatKindMessage   ChatKind = "message"   // something said
+	ChatKindEmote     ChatKind = "emote"     // /me, something done
+	ChatKindNarration ChatKind = "narration" // /narrate, the DM describing the scene
+	ChatKindRoll      ChatKind = "roll"      // /r, a dice roll made through the dice service
+)
+
+// ChatMessage is a line of a game session's chat. A message with recipients is a
+// whisper: only they and the sender ever see it.
+type ChatMessage struct {
+	ID         string      `json:"id" db:"id"`
+	SessionID  string      `json:"sessionId" db:"session_id"`
+	UserID     string      `json:"userId" db:"sender_id"`
+	Username   string      `json:"username" db:"sender_name"`
+	Channel    ChatChannel `json:"channel" db:"channel"`
+	Kind       ChatKind    `json:"kind" db:"kind"`
+	Message    string      `json:"message" db:"content"`
+	Recipients []string    `json:"recipients,omitempty" db:"-"`
+	DiceRollID *string     `json:"diceRollId,omitempty" db:"dice_roll_id"`
+	Roll       *DiceRoll   `json:"roll,omitempty" db:"-"`
+	CreatedAt  time.Time   `json:"createdAt" db:"created_at"`
+}
+
+// IsWhisper reports whether the message is private to its sender and recipients
+func (m *ChatMessage) IsWhisper() bool {
+	return len(m.Recipients) > 0
+}
+
+// VisibleTo reports whether a user may see the message
+func (m *ChatMessage) VisibleTo(userID string) bool {
+	if !m.IsWhisper() || m.UserID == userID {
+		return true
+	}
+	for _, recipient := range m.Recipients {
+		if recipient == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// ChatMessageRequest sends a chat message. Message may start with a command:
+// /me or /em for an emote, /r or /roll for a dice roll ("/r 1d20+5 stealth"), and
+// /narrate for DM narration. Recipients, user IDs from the session, make it a whisper.
+type ChatMessageRequest struct {
+	Channel    ChatChannel `json:"channel,omitempty" validate:"omitempty,oneof=ic ooc"`
+	Message    string      `json:"message" validate:"required,max=2000"`
+	Recipients []string    `json:"recipients,omitempty"`
+}
+
+// ChatFilter selects a page of chat history, newest first
+type ChatFilter struct {
+	Channel ChatChannel
+	Search  string
+	Before  string // a message ID; only older messages are listed
+	Limit   int
+}
+
+// ChatPage is a page of chat history, newest first. NextCursor, when set, is the Before
+// that fetches the page after it.
+type ChatPage struct {
+	Messages   []*ChatMessage `json:"messages"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -93,6 +94,18 @@ type Combatant struct {
 	Position           Position `json:"position,omitempty"`
 }
 
+// UnmarshalJSON reads a combatant. One that doesn't say whether it is visible is, so only
+// combatants the DM hides are kept from spectators.
+func (c *Combatant) UnmarshalJSON(data []byte) error {
+	type combatant Combatant
+	decoded := combatant{IsVisible: true}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*c = Combatant(decoded)
+	return nil
+}
+
 type DeathSaves struct {
 	Successes int  `json:"successes"`
 	Failures  int  `json:"failures"`
@@ -220,6 +233,7 @@ const (
 	ActionTypeSavingThrow   ActionType = "savingThrow"
 	ActionTypeEndTurn       ActionType = "endTurn"
 	ActionTypeCastSpell     ActionType = "castSpell"
+	ActionTypeUseResource   ActionType = "useResource"
 )
 
 type Roll struct {
@@ -268,6 +282,7 @@ const (
 	RollTypeInitiative    RollType = "initiative"
 	RollTypeDeathSave     RollType = "deathSave"
 	RollTypeConcentration RollType = "concentration"
+	RollTypeHealing       RollType = "healing"
 )
 
 type Damage struct {
@@ -285,13 +300,92 @@ type CombatRequest struct {
 	Advantage    bool         `json:"advantage"`
 	Disadvantage bool         `json:"disadvantage"`
 	Description  string       `json:"description,omitempty"`
+
+	// Class resource spent by a useResource action, e.g. "rage" or "ki"
+	ResourceKey    string `json:"resourceKey,omitempty"`
+	ResourceAmount int    `json:"resourceAmount,omitempty"`
+
+	// Consumable used by a useItem action; SpellID names the spell on a scroll that does not
+	ItemID string `json:"itemId,omitempty"`
 }
 
 type CombatUpdate struct {
-	Type    UpdateType    `json:"type"`
-	Combat  *Combat       `json:"combat,omitempty"`
-	Action  *CombatAction `json:"action,omitempty"`
-	Message string        `json:"message,omitempty"`
+	Type        UpdateType    `json:"type"`
+	Combat      *Combat       `json:"combat,omitempty"`
+	Action      *CombatAction `json:"action,omitempty"`
+	CombatantID string        `json:"combatantId,omitempty"` // the combatant a turn or HP change is about
+	Message     string        `json:"message,omitempty"`
+}
+
+// HiddenCombatants returns the IDs of the combatants the DM has hidden from spectators
+func (c *Combat) HiddenCombatants() map[string]bool {
+	hidden := make(map[string]bool)
+	for i := range c.Combatants {
+		if !c.Combatants[i].IsVisible {
+			hidden[c.Combatants[i].ID] = true
+		}
+	}
+	return hidden
+}
+
+// SpectatorView returns the combat as spectators see it, without its hidden combatants or
+// the effects they give or suffer. While a hidden combatant takes its turn, spectators see
+// the next visible combatant in the turn order as up.
+func (c *Combat) SpectatorView() *Combat {
+	hidden := c.HiddenCombatants()
+	if len(hidden) == 0 {
+		return c
+	}
+
+	view := *c
+	view.Combatants = make([]Combatant, 0, len(c.Combatants)-len(hidden))
+	for i := range c.Combatants {
+		if !hidden[c.Combatants[i].ID] {
+			view.Combatants = append(view.Combatants, c.Combatants[i])
+		}
+	}
+	view.TurnOrder = make([]string, 0, len(c.TurnOrder))
+	view.CurrentTurn = 0
+	for i, id := range c.TurnOrder {
+		if hidden[id] {
+			continue
+		}
+		if i < c.CurrentTurn {
+			view.CurrentTurn++
+		}
+		view.TurnOrder = append(view.TurnOrder, id)
+	}
+	if view.CurrentTurn >= len(view.TurnOrder) {
+		view.CurrentTurn = 0
+	}
+	view.Turn = view.CurrentTurn
+	view.ActiveEffects = make([]CombatEffect, 0, len(c.ActiveEffects))
+	for _, effect := range c.ActiveEffects {
+		if !hidden[effect.SourceID] && !hidden[effect.TargetID] {
+			view.ActiveEffects = append(view.ActiveEffects, effect)
+		}
+	}
+	view.ActionHistory = nil
+	return &view
+}
+
+// SpectatorView returns the update as spectators see it. An action or a message about a
+// hidden combatant is left out, so the update tells them no more than the combat does.
+func (u CombatUpdate) SpectatorView() CombatUpdate {
+	if u.Combat == nil {
+		return u
+	}
+	hidden := u.Combat.HiddenCombatants()
+	u.Combat = u.Combat.SpectatorView()
+	if hidden[u.CombatantID] {
+		u.CombatantID = ""
+		u.Message = ""
+	}
+	if u.Action != nil && (hidden[u.Action.ActorID] || hidden[u.Action.TargetID]) {
+		u.Action = nil
+		u.Message = ""
+	}
+	return u
 }
 
 type UpdateType string
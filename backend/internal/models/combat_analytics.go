@@ -76,6 +76,7 @@ type AutoCombatResolution struct {
 	ExperienceAwarded   int       `json:"experience_awarded" db:"experience_awarded"`
 	NarrativeSummary    string    `json:"narrative_summary" db:"narrative_summary"`
 	CreatedAt           time.Time `json:"created_at" db:"created_at"`
+	LootPoolID          *string   `json:"loot_pool_id,omitempty" db:"-"` // the pool holding the loot, when one was made
 }
 
 // BattleMap represents a generated tactical map
@@ -0,0 +1,137 @@
+package models
+
+import "strings"
+
+// ItemEffect is what a consumable does to its target: hit points restored, or damage the
+// target can resist with a saving throw or that needs an attack roll to land
+type ItemEffect struct {
+	Healing      string     `json:"healing,omitempty"` // dice, e.g. "2d4+2"
+	HealingBonus int        `json:"healing_bonus,omitempty"`
+	Damage       string     `json:"damage,omitempty"`
+	DamageType   DamageType `json:"damage_type,omitempty"`
+	Darts        int        `json:"darts,omitempty"` // separate damage rolls, as Magic Missile's darts
+	SaveAbility  string     `json:"save_ability,omitempty"`
+	SaveDC       int        `json:"save_dc,omitempty"`
+	HalfOnSave   bool       `json:"half_on_save,omitempty"` // otherwise a successful save negates the damage
+	// AttackBonus is set when the effect must hit the target's AC, as a spell attack does
+	AttackBonus *int `json:"attack_bonus,omitempty"`
+}
+
+// IsZero reports whether the effect does nothing the item pipeline can roll
+func (e ItemEffect) IsZero() bool {
+	return e.Healing == "" && e.Damage == ""
+}
+
+// IsConsumable reports whether the item is used up when it is used
+func (i *Item) IsConsumable() bool {
+	return i.Type == ItemTypeConsumable || i.Properties["consumable"] == true
+}
+
+// IsPotion reports whether the item is a potion, which table rules may let a character drink
+// as a bonus action
+func (i *Item) IsPotion() bool {
+	return i.Properties["potion"] == true || strings.Contains(strings.ToLower(i.Name), "potion")
+}
+
+// IsSpellScroll reports whether the item is a spell scroll, marked by its spell_level property
+func (i *Item) IsSpellScroll() bool {
+	_, ok := i.Properties["spell_level"]
+	return ok && i.IsConsumable()
+}
+
+// ScrollSpell is the spell written on a scroll and the level it is written at. Generic
+// scrolls such as "Spell Scroll (1st Level)" name no spell; the reader says which it holds.
+func (i *Item) ScrollSpell() (string, int) {
+	name, _ := i.Properties["spell"].(string)
+	return name, spellLevel(i.Properties["spell_level"])
+}
+
+// UseCost is the action using the item takes in combat: ActionTypeBonusAction when its
+// action_type property says so, ActionTypeUseItem (an action) otherwise
+func (i *Item) UseCost() ActionType {
+	switch actionType, _ := i.Properties["action_type"].(string); strings.ToLower(actionType) {
+	case "bonus_action", "bonus", "bonusaction":
+		return ActionTypeBonusAction
+	}
+	return ActionTypeUseItem
+}
+
+// ConsumableEffect reads a consumable's effect from its healing, damage, damage_type,
+// save_ability, save_dc, half_on_save and attack_bonus properties
+func (i *Item) ConsumableEffect() ItemEffect {
+	p := i.Properties
+	effect := ItemEffect{
+		SaveDC:     int(propertyNumber(p, "save_dc")),
+		HalfOnSave: p["half_on_save"] == true,
+	}
+	effect.Healing, _ = p["healing"].(string)
+	effect.Damage, _ = p["damage"].(string)
+	if damageType, ok := p["damage_type"].(string); ok {
+		effect.DamageType = DamageType(strings.ToLower(damageType))
+	}
+	if ability, ok := p["save_ability"].(string); ok {
+		effect.SaveAbility = strings.ToLower(ability)
+	}
+	if _, ok := p["attack_bonus"]; ok {
+		bonus := int(propertyNumber(p, "attack_bonus"))
+		effect.AttackBonus = &bonus
+	}
+	return effect
+}
+
+// SavingThrowModifier returns the character's saving throw modifier for an ability
+func (c *Character) SavingThrowModifier(ability string) int {
+	if save := c.SavingThrows.field(strings.ToLower(ability)); save != nil {
+		return save.Modifier
+	}
+	return 0
+}
+
+// UseItemRequest uses one consumable from a character's inventory
+type UseItemRequest struct {
+	// TargetID is the character, or combatant during combat, the item is used on; empty is the user
+	TargetID  string `json:"target_id,omitempty"`
+	Spell     string `json:"spell,omitempty"`      // spell on a scroll that does not name one
+	SessionID string `json:"session_id,omitempty"` // game session whose table rules apply
+}
+
+// AbilityCheck is a d20 ability check against a DC
+type AbilityCheck struct {
+	Ability string `json:"ability"`
+	DC      int    `json:"dc"`
+	Roll    Roll   `json:"roll"`
+	Success bool   `json:"success"`
+}
+
+// ItemSave is the target's saving throw against an item's effect
+type ItemSave struct {
+	Ability string `json:"ability"`
+	DC      int    `json:"dc"`
+	Roll    Roll   `json:"roll"`
+	Success bool   `json:"success"`
+}
+
+// ItemUse is what happened when a consumable was used
+type ItemUse struct {
+	ItemID   string     `json:"item_id"`
+	ItemName string     `json:"item_name"`
+	Cost     ActionType `json:"cost"` // the action it took: useItem for an action, or bonusAction
+	Target   string     `json:"target,omitempty"`
+
+	// Scrolls cast their spell with the scroll's own save DC and attack bonus
+	Spell            *Spell        `json:"spell,omitempty"`
+	SpellSaveDC      int           `json:"spell_save_dc,omitempty"`
+	SpellAttackBonus int           `json:"spell_attack_bonus,omitempty"`
+	ScrollCheck      *AbilityCheck `json:"scroll_check,omitempty"`
+	Wasted           bool          `json:"wasted,omitempty"` // the scroll check failed; the spell fades with no effect
+
+	Rolls       []Roll    `json:"rolls,omitempty"`
+	Missed      bool      `json:"missed,omitempty"`
+	Save        *ItemSave `json:"save,omitempty"`
+	Healing     int       `json:"healing,omitempty"`
+	Damage      []Damage  `json:"damage,omitempty"`
+	DamageTaken int       `json:"damage_taken,omitempty"` // after resistances and temporary hit points
+	TargetHP    int       `json:"target_hp"`
+
+	QuantityRemaining int `json:"quantity_remaining"`
+}
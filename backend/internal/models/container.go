@@ -0,0 +1,189 @@
+package models
+
+// ItemLocation is where an inventory stack physically is
+type ItemLocation string
+
+const (
+	ItemLocationCarried ItemLocation = "carried" // on the character, counts toward encumbrance
+	ItemLocationMount   ItemLocation = "mount"   // in saddlebags or on a pack animal
+	ItemLocationStored  ItemLocation = "stored"  // left behind: at the inn, on the cart, in a vault
+)
+
+// IsValid reports whether the location is one of the known locations
+func (l ItemLocation) IsValid() bool {
+	switch l {
+	case ItemLocationCarried, ItemLocationMount, ItemLocationStored:
+		return true
+	}
+	return false
+}
+
+// ContainerCapacity limits what a container item can hold. Zero limits are unlimited.
+type ContainerCapacity struct {
+	Weight float64 `json:"weight,omitempty"` // pounds
+	Volume float64 `json:"volume,omitempty"` // cubic feet
+	Count  int     `json:"count,omitempty"`  // items, e.g. 20 arrows in a quiver
+	// FixedWeight containers (bags of holding) weigh the same however full they are
+	FixedWeight bool `json:"fixed_weight,omitempty"`
+}
+
+// Container returns the item's capacity, or nil when the item cannot hold other items.
+// Capacities come from the item's properties: container, capacity_weight (or the older
+// weight_limit and capacity keys), capacity_volume, capacity_count and fixed_weight
+// (or extradimensional).
+func (i *Item) Container() *ContainerCapacity {
+	p := i.Properties
+	capacity := &ContainerCapacity{
+		Weight:      propertyNumber(p, "capacity_weight", "weight_limit", "capacity"),
+		Volume:      propertyNumber(p, "capacity_volume"),
+		Count:       int(propertyNumber(p, "capacity_count")),
+		FixedWeight: p["fixed_weight"] == true || p["extradimensional"] == true,
+	}
+	if p["container"] != true && capacity.Weight == 0 && capacity.Volume == 0 && capacity.Count == 0 {
+		return nil
+	}
+	return capacity
+}
+
+// Volume returns the item's size in cubic feet, or zero when unknown
+func (i *Item) Volume() float64 {
+	return propertyNumber(i.Properties, "volume")
+}
+
+// MoveItemRequest moves an inventory stack, or part of one, into a container, or out of one to a location
+type MoveItemRequest struct {
+	ContainerItemID string       `json:"container_item_id,omitempty"` // item ID of a container in the same inventory
+	Location        ItemLocation `json:"location,omitempty"`          // used when not moving into a container
+	StorageNote     string       `json:"storage_note,omitempty"`
+	// FromContainerItemID picks the stack inside that container; empty picks the loose stack, or the first packed one
+	FromContainerItemID string `json:"from_container_item_id,omitempty"`
+	// Quantity splits off part of the stack; zero moves all of it
+	Quantity int `json:"quantity,omitempty"`
+}
+
+// InventoryMove places an inventory stack, or part of one, in a container or at a location
+type InventoryMove struct {
+	StackID     string // inventory row being moved
+	ItemID      string
+	Quantity    int     // how many to move; zero moves the whole stack
+	ContainerID *string // inventory row of the destination container; nil takes the stack out
+	Location    ItemLocation
+	StorageNote string
+	// Contents lists the inventory rows nested inside the moved stack; they follow it to its new location
+	Contents []string
+}
+
+// ContainerLoad is what a container currently holds
+type ContainerLoad struct {
+	Count  int
+	Weight float64
+	Volume float64
+}
+
+// LoadOf totals the direct contents of the container stored in inventory row containerID.
+// Nested containers count with their contents unless they have a fixed weight.
+func LoadOf(items []*InventoryItem, containerID string) ContainerLoad {
+	children := inventoryChildren(items)
+	var load ContainerLoad
+	for _, child := range children[containerID] {
+		stack := stackLoad(child, children)
+		load.Count += stack.Count
+		load.Weight += stack.Weight
+		load.Volume += stack.Volume
+	}
+	return load
+}
+
+// StackLoad is the room a stack, with everything inside it, takes up in a container
+func StackLoad(items []*InventoryItem, stack *InventoryItem) ContainerLoad {
+	return stackLoad(stack, inventoryChildren(items))
+}
+
+func stackLoad(inv *InventoryItem, children map[string][]*InventoryItem) ContainerLoad {
+	load := ContainerLoad{Count: inv.Quantity, Weight: stackWeight(inv, children)}
+	if inv.Item != nil {
+		load.Volume = inv.Item.Volume() * float64(inv.Quantity)
+	}
+	return load
+}
+
+// ContentsOf returns every stack nested inside inventory row containerID, at any depth
+func ContentsOf(items []*InventoryItem, containerID string) []*InventoryItem {
+	children := inventoryChildren(items)
+	var contents []*InventoryItem
+	pending := []string{containerID}
+	for len(pending) > 0 {
+		id := pending[0]
+		pending = pending[1:]
+		for _, child := range children[id] {
+			contents = append(contents, child)
+			pending = append(pending, child.ID)
+		}
+	}
+	return contents
+}
+
+// WeighInventory splits a character's inventory weight between the body, their mount and
+// storage. Items inside containers count toward wherever their outermost container is.
+func WeighInventory(items []*InventoryItem) InventoryWeight {
+	children := inventoryChildren(items)
+	byID := make(map[string]bool, len(items))
+	for _, inv := range items {
+		byID[inv.ID] = true
+	}
+
+	var weight InventoryWeight
+	for _, inv := range items {
+		if inv.ContainerID != nil && byID[*inv.ContainerID] {
+			continue
+		}
+		w := stackWeight(inv, children)
+		switch inv.Location {
+		case ItemLocationMount:
+			weight.MountWeight += w
+		case ItemLocationStored:
+			weight.StoredWeight += w
+		default:
+			weight.CurrentWeight += w
+		}
+	}
+	return weight
+}
+
+// stackWeight is the weight of a stack plus, for ordinary containers, everything inside it
+func stackWeight(inv *InventoryItem, children map[string][]*InventoryItem) float64 {
+	if inv.Item == nil {
+		return 0
+	}
+	weight := inv.Item.Weight * float64(inv.Quantity)
+	if capacity := inv.Item.Container(); capacity != nil && capacity.FixedWeight {
+		return weight
+	}
+	for _, child := range children[inv.ID] {
+		weight += stackWeight(child, children)
+	}
+	return weight
+}
+
+func inventoryChildren(items []*InventoryItem) map[string][]*InventoryItem {
+	children := make(map[string][]*InventoryItem)
+	for _, inv := range items {
+		if inv.ContainerID != nil {
+			children[*inv.ContainerID] = append(children[*inv.ContainerID], inv)
+		}
+	}
+	return children
+}
+
+// propertyNumber returns the first numeric property among keys
+func propertyNumber(p ItemProperties, keys ...string) float64 {
+	for _, key := range keys {
+		switch v := p[key].(type) {
+		case float64:
+			return v
+		case int:
+			return float64(v)
+		}
+	}
+	return 0
+}
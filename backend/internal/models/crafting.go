@@ -0,0 +1,177 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RecipeCategory groups recipes by what they make
+type RecipeCategory string
+
+const (
+	RecipeCategoryPotion RecipeCategory = "potion"
+	RecipeCategoryScroll RecipeCategory = "scroll"
+	RecipeCategoryGear   RecipeCategory = "gear"
+)
+
+// RecipeComponent is an item and how many of it a recipe uses or makes
+type RecipeComponent struct {
+	ItemID   string `json:"itemId"`
+	Quantity int    `json:"quantity"`
+}
+
+// Recipe describes how to craft an item during downtime. Built-in recipes come from
+// data/recipes; homebrew recipes belong to the game session whose DM wrote them.
+type Recipe struct {
+	ID          string            `json:"id"`
+	SessionID   *string           `json:"sessionId,omitempty"` // nil for built-in recipes
+	Name        string            `json:"name"`
+	Category    RecipeCategory    `json:"category"`
+	Description string            `json:"description,omitempty"`
+	Output      RecipeComponent   `json:"output"`
+	Ingredients []RecipeComponent `json:"ingredients"`
+	// Tool is the tool the crafter must be proficient with, e.g. "Herbalism Kit". Empty needs none.
+	Tool      string    `json:"tool,omitempty"`
+	Ability   string    `json:"ability"`  // ability used for the crafting check
+	GoldCost  int       `json:"goldCost"` // in gold pieces, paid when the work is finished
+	Workdays  int       `json:"workdays"`
+	DC        int       `json:"dc"`
+	CreatedBy string    `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+}
+
+// Validate checks the recipe is complete enough to craft from
+func (r *Recipe) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("recipe name is required")
+	}
+	if r.Output.ItemID == "" || r.Output.Quantity < 1 {
+		return fmt.Errorf("recipe must produce at least one item")
+	}
+	for _, ingredient := range r.Ingredients {
+		if ingredient.ItemID == "" || ingredient.Quantity < 1 {
+			return fmt.Errorf("ingredients need an item and a positive quantity")
+		}
+	}
+	if r.Workdays < 1 {
+		return fmt.Errorf("recipe must take at least one workday")
+	}
+	if r.GoldCost < 0 {
+		return fmt.Errorf("gold cost cannot be negative")
+	}
+	if r.DC < 0 || r.DC > 30 {
+		return fmt.Errorf("DC must be between 0 and 30")
+	}
+	if r.Ability != "" && (&Attributes{}).field(r.Ability) == nil {
+		return fmt.Errorf("unknown ability: %s", r.Ability)
+	}
+	return nil
+}
+
+// CheckAbility is the ability the crafting check uses, Intelligence unless the recipe says otherwise
+func (r *Recipe) CheckAbility() string {
+	if r.Ability == "" {
+		return "intelligence"
+	}
+	return r.Ability
+}
+
+// ProficientCrafter reports whether the character is proficient with the recipe's tool.
+// Recipes that need no tool can be made by anyone.
+func (r *Recipe) ProficientCrafter(char *Character) bool {
+	if r.Tool == "" {
+		return true
+	}
+	for _, tool := range char.Proficiencies.Tools {
+		if strings.EqualFold(strings.TrimSpace(tool), r.Tool) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckModifier is the character's bonus to the crafting check: the ability modifier,
+// plus their proficiency bonus when the recipe calls for a tool
+func (r *Recipe) CheckModifier(char *Character) int {
+	modifier := 0
+	if score := char.Attributes.field(r.CheckAbility()); score != nil {
+		modifier = abilityModifier(*score)
+	}
+	if r.Tool != "" && r.ProficientCrafter(char) {
+		modifier += char.ProficiencyBonus
+	}
+	return modifier
+}
+
+// CraftingStatus is where a crafting project stands
+type CraftingStatus string
+
+const (
+	CraftingStatusInProgress CraftingStatus = "in_progress"
+	CraftingStatusCompleted  CraftingStatus = "completed"
+	CraftingStatusFailed     CraftingStatus = "failed"
+	CraftingStatusAbandoned  CraftingStatus = "abandoned"
+)
+
+// CraftingProject is a character's work on a recipe, counted in in-game workdays.
+// The recipe is copied in when work starts so later edits to homebrew don't change it.
+type CraftingProject struct {
+	ID            string         `json:"id"`
+	CharacterID   string         `json:"characterId"`
+	SessionID     *string        `json:"sessionId,omitempty"`
+	Recipe        Recipe         `json:"recipe"`
+	Status        CraftingStatus `json:"status"`
+	DaysWorked    int            `json:"daysWorked"`
+	CheckRoll     *int           `json:"checkRoll,omitempty"`
+	CheckTotal    *int           `json:"checkTotal,omitempty"`
+	TransactionID *string        `json:"transactionId,omitempty"`
+	CreatedBy     string         `json:"createdBy,omitempty"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	UpdatedAt     time.Time      `json:"updatedAt"`
+	CompletedAt   *time.Time     `json:"completedAt,omitempty"`
+}
+
+// DaysRemaining is how many more workdays the project needs
+func (p *CraftingProject) DaysRemaining() int {
+	return max(0, p.Recipe.Workdays-p.DaysWorked)
+}
+
+// StartCraftingRequest begins work on a recipe
+type StartCraftingRequest struct {
+	RecipeID  string `json:"recipeId"`
+	SessionID string `json:"sessionId,omitempty"`
+}
+
+// CraftingWorkRequest spends in-game days working on a project
+type CraftingWorkRequest struct {
+	Days int `json:"days"`
+}
+
+// CraftingDowntime is how many downtime days a character has been granted to spend on projects
+type CraftingDowntime struct {
+	CharacterID   string `json:"characterId"`
+	DaysAvailable int    `json:"daysAvailable"`
+}
+
+// CraftingDowntimeRequest is the DM granting a character downtime days
+type CraftingDowntimeRequest struct {
+	Days int `json:"days"`
+}
+
+// CraftingCheck is the ability check rolled when a project's last workday is done
+type CraftingCheck struct {
+	Roll       int    `json:"roll"`
+	Modifier   int    `json:"modifier"`
+	Total      int    `json:"total"`
+	DC         int    `json:"dc"`
+	Success    bool   `json:"success"`
+	DiceRollID string `json:"diceRollId,omitempty"`
+}
+
+// CraftingProgress is the outcome of a day or more of crafting work
+type CraftingProgress struct {
+	Project *CraftingProject `json:"project"`
+	Check   *CraftingCheck   `json:"check,omitempty"`
+	Ledger  []*LedgerEntry   `json:"ledger,omitempty"`
+}
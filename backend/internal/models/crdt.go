@@ -0,0 +1,15 @@
+package models
+
+// CRDTDocument is a stored automerge document: the snapshot from its last compaction and
+// the changes synced since, oldest first
+type CRDTDocument struct {
+	ID       string        `json:"id" db:"id"`
+	Snapshot []byte        `json:"-" db:"snapshot"`
+	Changes  []*CRDTChange `json:"-"`
+}
+
+// CRDTChange is one incremental save of a document
+type CRDTChange struct {
+	ID   int64  `db:"id"`
+	Data []byte `db:"data"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -307,6 +308,12 @@ type RulingSuggestion struct {
 	BalanceConsiderations string   `json:"balanceConsiderations,omitempty"`
 }
 
+// Treasure types the treasure tables cover
+const (
+	TreasureTypeIndividual = "individual" // pocket change carried by one creature
+	TreasureTypeHoard      = "hoard"      // a lair or boss hoard
+)
+
 // TreasureRequest for generating treasure
 type TreasureRequest struct {
 	ChallengeRating int    `json:"challengeRating"`
@@ -314,6 +321,22 @@ type TreasureRequest struct {
 	PartyLevel      int    `json:"partyLevel"`
 	Context         string `json:"context"`
 	PartySize       int    `json:"partySize,omitempty"`
+	Seed            *int64 `json:"seed,omitempty"`       // the same seed rolls the same treasure
+	WithFlavor      bool   `json:"withFlavor,omitempty"` // ask the AI to describe the treasure
+}
+
+// TreasurePoolRequest rolls treasure straight into a game session's loot pool
+type TreasurePoolRequest struct {
+	TreasureRequest
+	Mode         LootDistributionMode `json:"mode,omitempty"`
+	CharacterIDs []string             `json:"characterIds,omitempty"` // defaults to every character in the session
+}
+
+// TreasurePool is rolled treasure and the loot pool it went into. Pool is nil when
+// nothing was found.
+type TreasurePool struct {
+	Hoard *TreasureHoard `json:"hoard"`
+	Pool  *LootPool      `json:"pool,omitempty"`
 }
 
 // TreasureHoard represents generated treasure
@@ -327,18 +350,67 @@ type TreasureHoard struct {
 	Gems             []Gem            `json:"gems,omitempty"`
 	ArtObjects       []ArtObject      `json:"artObjects,omitempty"`
 	MagicItemDetails []MagicItem      `json:"magicItemDetails,omitempty"`
+	TreasureType     string           `json:"treasureType,omitempty"`
+	ChallengeRating  int              `json:"challengeRating"`
+	Seed             int64            `json:"seed"`
+	Flavor           string           `json:"flavor,omitempty"`
+}
+
+// LootPoolRequest puts the treasure up for the party as a loot pool. Gems, art objects
+// and magic items go in as items; hoards rolled by the AI without item IDs leave them out.
+func (h *TreasureHoard) LootPoolRequest(mode LootDistributionMode) *CreateLootPoolRequest {
+	req := &CreateLootPoolRequest{
+		Description: h.Description(),
+		Mode:        mode,
+		Coins:       h.Coins.Coins(),
+		SourceType:  LootSourceManual,
+	}
+	add := func(itemID string, quantity int) {
+		if itemID != "" && quantity > 0 {
+			req.Items = append(req.Items, LootItem{ItemID: itemID, Quantity: quantity})
+		}
+	}
+	for _, gem := range h.Gems {
+		add(gem.ItemID, gem.Quantity)
+	}
+	for _, art := range h.ArtObjects {
+		add(art.ItemID, art.Quantity)
+	}
+	for _, item := range h.MagicItemDetails {
+		add(item.ItemID, 1)
+	}
+	return req
+}
+
+// Description names the treasure for a loot pool
+func (h *TreasureHoard) Description() string {
+	kind := "Treasure"
+	switch h.TreasureType {
+	case TreasureTypeHoard:
+		kind = "Treasure hoard"
+	case TreasureTypeIndividual:
+		kind = "Individual treasure"
+	}
+	return fmt.Sprintf("%s (CR %d, seed %d)", kind, h.ChallengeRating, h.Seed)
 }
 
 // CoinageBreakdown represents the breakdown of coins in treasure
 type CoinageBreakdown struct {
 	Copper   int `json:"copper"`
 	Silver   int `json:"silver"`
+	Electrum int `json:"electrum"`
 	Gold     int `json:"gold"`
 	Platinum int `json:"platinum"`
 }
 
+// Coins converts the breakdown into a coin purse
+func (c CoinageBreakdown) Coins() Coins {
+	return Coins{Copper: c.Copper, Silver: c.Silver, Electrum: c.Electrum, Gold: c.Gold, Platinum: c.Platinum}
+}
+
 // Gem represents a gem found in treasure
 type Gem struct {
+	ItemID      string `json:"itemId,omitempty"`
 	Name        string `json:"name"`
 	Value       int    `json:"value"`
 	Quantity    int    `json:"quantity"`
@@ -347,13 +419,17 @@ type Gem struct {
 
 // ArtObject represents an art object found in treasure
 type ArtObject struct {
+	ItemID      string `json:"itemId,omitempty"`
 	Name        string `json:"name"`
 	Value       int    `json:"value"`
+	Quantity    int    `json:"quantity,omitempty"`
 	Description string `json:"description"`
 }
 
 // MagicItem represents a magic item with detailed properties
 type MagicItem struct {
+	ItemID      string   `json:"itemId,omitempty"`
+	Table       string   `json:"table,omitempty"` // the magic item table, A to I, it was rolled on
 	Name        string   `json:"name"`
 	Rarity      string   `json:"rarity"`
 	Description string   `json:"description"`
@@ -31,6 +31,27 @@ type GameSession struct {
 	EndedAt               *time.Time             `json:"endedAt,omitempty" db:"ended_at"`
 }
 
+// SessionStateTableRules is the session state key holding the DM's table rules
+const SessionStateTableRules = "table_rules"
+
+// TableRules are optional rules a DM turns on for their table
+type TableRules struct {
+	// PotionsAsBonusAction lets a character drink a potion as a bonus action instead of an action
+	PotionsAsBonusAction bool `json:"potions_as_bonus_action"`
+	// SpectatorChat lets spectators talk out of character in the session chat
+	SpectatorChat bool `json:"spectator_chat"`
+}
+
+// TableRules reads the session's table rules from its state. Rules never set are off.
+func (s *GameSession) TableRules() TableRules {
+	var rules TableRules
+	if stored, ok := s.State[SessionStateTableRules].(map[string]interface{}); ok {
+		rules.PotionsAsBonusAction = stored["potions_as_bonus_action"] == true
+		rules.SpectatorChat = stored["spectator_chat"] == true
+	}
+	return rules
+}
+
 type Player struct {
 	ID          string    `json:"id" db:"user_id"`
 	Name        string    `json:"name" db:"name"`
@@ -53,24 +74,45 @@ type DiceRoll struct {
 	Timestamp     time.Time `json:"timestamp" db:"timestamp"`
 }
 
-type GameEvent struct {
-	ID        string                 `json:"id" db:"id"`
-	SessionID string                 `json:"sessionId" db:"session_id"`
-	Type      string                 `json:"type" db:"type"` // roll, message, combat, etc.
-	PlayerID  string                 `json:"playerId" db:"player_id"`
-	Data      map[string]interface{} `json:"data" db:"data"`
-	Timestamp time.Time              `json:"timestamp" db:"timestamp"`
-}
-
-// GameInvite represents an invitation to join a game session
+// GameInvite is an invitation to join a game session. An invite sent to an email address
+// is redeemed once, by that address. A shareable link has no invitee and can be redeemed by
+// anyone holding its code, up to MaxUses times.
 type GameInvite struct {
 	ID           string     `json:"id" db:"id"`
 	SessionID    string     `json:"sessionId" db:"session_id"`
 	InviterID    string     `json:"inviterId" db:"inviter_id"`
-	InviteeEmail string     `json:"inviteeEmail" db:"invitee_email"`
+	InviteeEmail string     `json:"inviteeEmail,omitempty" db:"invitee_email"` // empty for a shareable link
 	InviteeID    *string    `json:"inviteeId,omitempty" db:"invitee_id"`
 	Code         string     `json:"code" db:"code"`
+	MaxUses      int        `json:"maxUses" db:"max_uses"`
+	UseCount     int        `json:"useCount" db:"use_count"`
 	ExpiresAt    time.Time  `json:"expiresAt" db:"expires_at"`
-	UsedAt       *time.Time `json:"usedAt,omitempty" db:"used_at"`
+	UsedAt       *time.Time `json:"usedAt,omitempty" db:"used_at"` // when it was last redeemed
+	RevokedAt    *time.Time `json:"revokedAt,omitempty" db:"revoked_at"`
 	CreatedAt    time.Time  `json:"createdAt" db:"created_at"`
 }
+
+// IsLink reports whether the invite is a shareable link rather than sent to one address
+func (i *GameInvite) IsLink() bool {
+	return i.InviteeEmail == ""
+}
+
+// Pending reports whether the invite can still be redeemed at the given time
+func (i *GameInvite) Pending(now time.Time) bool {
+	return i.RevokedAt == nil && now.Before(i.ExpiresAt) && i.UseCount < i.MaxUses
+}
+
+// GameInviteRequest invites a player to a game session by email or, without an email,
+// creates a shareable link
+type GameInviteRequest struct {
+	Email          string `json:"email,omitempty" validate:"omitempty,email"`
+	MaxUses        int    `json:"maxUses,omitempty" validate:"min=0,max=100"`        // links only; defaults to the session's player seats
+	ExpiresInHours int    `json:"expiresInHours,omitempty" validate:"min=0,max=720"` // defaults to a week
+}
+
+// RedeemInviteRequest joins the session an invite is for with one of the caller's
+// characters, or as a spectator without one
+type RedeemInviteRequest struct {
+	CharacterID string `json:"characterId,omitempty"`
+	Spectate    bool   `json:"spectate,omitempty"`
+}
@@ -0,0 +1,100 @@
+package models
+
+import "time"
+
+// Game event types written to a session's event log
+const (
+	GameEventRoll         = "roll"          // a dice roll
+	GameEventChat         = "chat"          // a public chat message
+	GameEventCombatAction = "combat_action" // an action taken in combat
+	GameEventNPCDialog    = "npc_dialog"    // an NPC answering a player
+	GameEventLoot         = "loot"          // a character's share of a loot pool
+	GameEventExperience   = "experience"    // experience awarded to a character
+	GameEventParty        = "party"         // coins or items moving through the party stash, trades and loot pools
+)
+
+// GameEvent is an entry in a session's event log: what happened, who did it, the
+// character it happened to, and when, both at the table and in the game world. Every
+// subsystem writes here, and timelines, recaps, analytics and exports read from here.
+type GameEvent struct {
+	ID          string                 `json:"id" db:"id"`
+	SessionID   string                 `json:"sessionId" db:"session_id"`
+	Type        string                 `json:"type" db:"type"`
+	PlayerID    string                 `json:"playerId" db:"actor_id"` // the user who acted
+	CharacterID *string                `json:"characterId,omitempty" db:"character_id"`
+	Data        map[string]interface{} `json:"data" db:"-"`
+	GameTime    string                 `json:"gameTime,omitempty" db:"game_time"` // in-game time as the DM keeps it, e.g. "Day 3, dusk"
+	Timestamp   time.Time              `json:"timestamp" db:"occurred_at"`
+}
+
+// Flags an event carries in its data to keep it from part of the table
+const (
+	// GameEventDataHidden keeps an event from spectators, such as the action of a
+	// combatant the DM hides
+	GameEventDataHidden = "hidden"
+	// GameEventDataDMOnly keeps an event from everyone but the DM, such as a note the DM
+	// records for themselves
+	GameEventDataDMOnly = "dmOnly"
+)
+
+// HiddenFromSpectators reports whether spectators may not see the event
+func (e *GameEvent) HiddenFromSpectators() bool {
+	return e.Data[GameEventDataHidden] == true || e.DMOnly()
+}
+
+// DMOnly reports whether only the session's DM may see the event
+func (e *GameEvent) DMOnly() bool {
+	return e.Data[GameEventDataDMOnly] == true
+}
+
+// VisibleTo reports whether a participant of the event's session with the given role may
+// see it. The DM sees everything, players everything but DM-only events, and spectators
+// neither those nor hidden ones.
+func (e *GameEvent) VisibleTo(role ParticipantRole) bool {
+	switch role {
+	case ParticipantRoleDM:
+		return true
+	case ParticipantRolePlayer:
+		return !e.DMOnly()
+	default:
+		return !e.HiddenFromSpectators()
+	}
+}
+
+// GameEventRequest records an event by hand, such as the DM noting a scene change
+type GameEventRequest struct {
+	Type        string                 `json:"type" validate:"required,max=50"`
+	CharacterID *string                `json:"characterId,omitempty"`
+	Data        map[string]interface{} `json:"data,omitempty"`
+	GameTime    string                 `json:"gameTime,omitempty" validate:"max=100"`
+}
+
+// GameEventFilter selects a page of the event log, oldest first
+type GameEventFilter struct {
+	SessionID   string
+	CharacterID string
+	PlayerID    string
+	Types       []string
+	Since       *time.Time
+	Until       *time.Time
+	After       string // an event ID; only later events are listed
+	Limit       int
+}
+
+// GameEventPage is a page of the event log, oldest first. NextCursor, when set, is the
+// After that fetches the page after it.
+type GameEventPage struct {
+	Events     []*GameEvent `json:"events"`
+	NextCursor string       `json:"nextCursor,omitempty"`
+}
+
+// GameEventSummary counts what happened in a session
+type GameEventSummary struct {
+	SessionID   string         `json:"sessionId"`
+	Total       int            `json:"total"`
+	ByType      map[string]int `json:"byType"`
+	ByPlayer    map[string]int `json:"byPlayer"`
+	ByCharacter map[string]int `json:"byCharacter"`
+	FirstAt     *time.Time     `json:"firstAt,omitempty"`
+	LastAt      *time.Time     `json:"lastAt,omitempty"`
+}
@@ -39,11 +39,14 @@ func (p *ItemProperties) Scan(value interface{}) error {
 		*p = make(ItemProperties)
 		return nil
 	}
-	b, ok := value.([]byte)
-	if !ok {
-		return nil
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		// SQLite returns JSON columns as text
+		return json.Unmarshal([]byte(v), p)
 	}
-	return json.Unmarshal(b, p)
+	return nil
 }
 
 type Item struct {
@@ -57,6 +60,8 @@ type Item struct {
 	RequiresAttunement     bool           `json:"requires_attunement" db:"requires_attunement"`
 	AttunementRequirements string         `json:"attunement_requirements,omitempty" db:"attunement_requirements"`
 	Description            string         `json:"description,omitempty" db:"description"`
+	Source                 ItemSource     `json:"source,omitempty" db:"source"`
+	SessionID              *string        `json:"session_id,omitempty" db:"session_id"` // set on homebrew items, visible only in that game session
 	CreatedAt              time.Time      `json:"created_at" db:"created_at"`
 	UpdatedAt              time.Time      `json:"updated_at" db:"updated_at"`
 }
@@ -70,6 +75,11 @@ type InventoryItem struct {
 	Attuned          bool           `json:"attuned" db:"attuned"`
 	CustomProperties ItemProperties `json:"custom_properties" db:"custom_properties"`
 	Notes            string         `json:"notes,omitempty" db:"notes"`
+	ContainerID      *string        `json:"container_id,omitempty" db:"container_id"` // inventory row of the container holding this stack
+	Location         ItemLocation   `json:"location" db:"location"`
+	StorageNote      string         `json:"storage_note,omitempty" db:"storage_note"` // where stored items were left, e.g. "at the Yawning Portal"
+	Charges          *int           `json:"charges,omitempty" db:"charges"`           // charges left; nil until first spent
+	CurseActive      bool           `json:"curse_active" db:"curse_active"`           // a cursed item's curse has taken hold
 	Item             *Item          `json:"item,omitempty"`
 	CreatedAt        time.Time      `json:"created_at" db:"created_at"`
 	UpdatedAt        time.Time      `json:"updated_at" db:"updated_at"`
@@ -99,8 +109,32 @@ func (c *Currency) Subtract(copperValue int) bool {
 		return false
 	}
 
-	total := c.TotalInCopper() - copperValue
+	c.setTotal(c.TotalInCopper() - copperValue)
+	return true
+}
+
+// Apply adds or removes coins. When the purse lacks a denomination being spent, it makes
+// change from the whole purse. It returns false, leaving the purse untouched, if the
+// purse cannot cover the amount.
+func (c *Currency) Apply(delta Coins) bool {
+	total := c.TotalInCopper() + delta.TotalInCopper()
+	if total < 0 {
+		return false
+	}
+
+	c.Copper += delta.Copper
+	c.Silver += delta.Silver
+	c.Electrum += delta.Electrum
+	c.Gold += delta.Gold
+	c.Platinum += delta.Platinum
+	if c.Copper < 0 || c.Silver < 0 || c.Electrum < 0 || c.Gold < 0 || c.Platinum < 0 {
+		c.setTotal(total)
+	}
+	return true
+}
 
+// setTotal replaces the purse with the fewest coins worth total copper
+func (c *Currency) setTotal(total int) {
 	c.Platinum = total / 1000
 	total %= 1000
 
@@ -112,12 +146,12 @@ func (c *Currency) Subtract(copperValue int) bool {
 
 	c.Silver = total / 10
 	c.Copper = total % 10
-
-	return true
 }
 
 type InventoryWeight struct {
-	CurrentWeight     float64 `json:"current_weight"`
+	CurrentWeight     float64 `json:"current_weight"` // weight carried on the body, which is what encumbers
+	MountWeight       float64 `json:"mount_weight"`
+	StoredWeight      float64 `json:"stored_weight"`
 	CarryCapacity     float64 `json:"carry_capacity"`
 	Encumbered        bool    `json:"encumbered"`
 	HeavilyEncumbered bool    `json:"heavily_encumbered"`
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// ItemSource records where a row in the items table came from
+type ItemSource string
+
+const (
+	ItemSourceCatalog  ItemSource = "catalog"  // synced from data/items
+	ItemSourceHomebrew ItemSource = "homebrew" // a DM's item for one game session
+	ItemSourceCustom   ItemSource = "custom"   // created ad hoc, e.g. by a character import
+)
+
+// CatalogSync is the outcome of loading data/items into the items table
+type CatalogSync struct {
+	Version   string    `json:"version"`
+	ItemCount int       `json:"itemCount"`
+	Skipped   bool      `json:"skipped"` // the database already held this version
+	SyncedAt  time.Time `json:"syncedAt"`
+}
+
+// ItemFilter selects items visible to a game session
+type ItemFilter struct {
+	Type      ItemType
+	Source    ItemSource
+	SessionID string // includes the session's homebrew; without it only shared items are listed
+	Search    string
+	Limit     int
+}
@@ -0,0 +1,156 @@
+package models
+
+import "time"
+
+// LedgerEntryType describes why money or items moved
+type LedgerEntryType string
+
+const (
+	LedgerEntryPurchase   LedgerEntryType = "purchase"
+	LedgerEntrySale       LedgerEntryType = "sale"
+	LedgerEntryTransfer   LedgerEntryType = "transfer"
+	LedgerEntryLoot       LedgerEntryType = "loot"
+	LedgerEntryAdjustment LedgerEntryType = "adjustment"
+	LedgerEntryStash      LedgerEntryType = "stash"
+	LedgerEntryCraft      LedgerEntryType = "craft"
+	LedgerEntryUse        LedgerEntryType = "use"
+	// LedgerEntryStartingEquipment is a new character's one-time class and background kit
+	LedgerEntryStartingEquipment LedgerEntryType = "starting_equipment"
+)
+
+// Coins is a signed amount of each denomination
+type Coins struct {
+	Copper   int `json:"copper"`
+	Silver   int `json:"silver"`
+	Electrum int `json:"electrum"`
+	Gold     int `json:"gold"`
+	Platinum int `json:"platinum"`
+}
+
+// TotalInCopper returns the combined value of the coins in copper pieces
+func (c Coins) TotalInCopper() int {
+	return c.Copper + (c.Silver * 10) + (c.Electrum * 50) + (c.Gold * 100) + (c.Platinum * 1000)
+}
+
+// IsZero reports whether no coins move
+func (c Coins) IsZero() bool {
+	return c == Coins{}
+}
+
+// Negate returns the same coins moving the other way
+func (c Coins) Negate() Coins {
+	return Coins{Copper: -c.Copper, Silver: -c.Silver, Electrum: -c.Electrum, Gold: -c.Gold, Platinum: -c.Platinum}
+}
+
+// IsNegative reports whether any denomination is below zero
+func (c Coins) IsNegative() bool {
+	return c.Copper < 0 || c.Silver < 0 || c.Electrum < 0 || c.Gold < 0 || c.Platinum < 0
+}
+
+// CoinsFromCopper pays out a copper value in gold, silver and copper pieces
+func CoinsFromCopper(copper int) Coins {
+	return Coins{Gold: copper / 100, Silver: (copper % 100) / 10, Copper: copper % 10}
+}
+
+// CurrencyMovement adds coins to (or, when negative, takes coins from) a character's purse
+type CurrencyMovement struct {
+	CharacterID string `json:"characterId"`
+	Coins       Coins  `json:"coins"`
+}
+
+// ItemMovement adds items to (or, when negative, removes items from) a character's inventory
+type ItemMovement struct {
+	CharacterID string `json:"characterId"`
+	ItemID      string `json:"itemId"`
+	Quantity    int    `json:"quantity"`
+}
+
+// EconomyTransaction is a set of money and item movements that succeed or fail together.
+// Every character movement is written to the ledger under the transaction's ID; shop
+// stock and party stash changes happen in the same transaction but are not ledger parties.
+type EconomyTransaction struct {
+	ID            string                  `json:"id"`
+	Type          LedgerEntryType         `json:"type"`
+	Description   string                  `json:"description"`
+	CreatedBy     string                  `json:"createdBy,omitempty"`
+	SessionID     string                  `json:"sessionId,omitempty"`
+	PartyID       string                  `json:"partyId,omitempty"` // tags the ledger entries of stash movements
+	Currency      []CurrencyMovement      `json:"currency,omitempty"`
+	Items         []ItemMovement          `json:"items,omitempty"`
+	ShopStock     []ShopStockMovement     `json:"shopStock,omitempty"`
+	PartyCurrency []PartyCurrencyMovement `json:"partyCurrency,omitempty"`
+	PartyStash    []PartyStashMovement    `json:"partyStash,omitempty"`
+	// TradeOfferID marks a pending trade offer accepted when the transaction commits
+	TradeOfferID string `json:"tradeOfferId,omitempty"`
+	// CraftingProject is an in-progress project finished, with its check result, when the transaction commits
+	CraftingProject *CraftingProject `json:"craftingProject,omitempty"`
+	// CraftingDays is the downtime the crafter spends on the project's last workdays
+	CraftingDays int `json:"-"`
+	// LootPool is an open loot pool marked distributed, with its final item assignments, when the transaction commits
+	LootPool *LootPool `json:"lootPool,omitempty"`
+	// ItemTarget is the character a consumable was used on, whose hit points are saved
+	// in the same transaction as the item is used up
+	ItemTarget *Character `json:"-"`
+	// NewCharacter is created before anything moves, so an imported character only
+	// exists once its whole inventory and purse have been written with it
+	NewCharacter *Character `json:"-"`
+	// StartingEquipment is recorded before anything else moves; its key on character_id
+	// fails the whole transaction if the character's equipment was already granted
+	StartingEquipment *StartingEquipmentGrant `json:"startingEquipment,omitempty"`
+}
+
+// LedgerEntry is one append-only record of money or items moving in or out of a character's possession
+type LedgerEntry struct {
+	ID            string          `json:"id" db:"id"`
+	TransactionID string          `json:"transactionId" db:"transaction_id"`
+	CharacterID   string          `json:"characterId" db:"character_id"`
+	EntryType     LedgerEntryType `json:"entryType" db:"entry_type"`
+	ItemID        *string         `json:"itemId,omitempty" db:"item_id"`
+	Quantity      int             `json:"quantity,omitempty" db:"quantity"`
+	CopperDelta   int             `json:"copperDelta,omitempty" db:"copper_delta"`
+	BalanceAfter  int             `json:"balanceAfter" db:"balance_after"` // purse value in copper after the entry
+	Description   string          `json:"description" db:"description"`
+	CreatedBy     string          `json:"createdBy,omitempty" db:"created_by"`
+	SessionID     *string         `json:"sessionId,omitempty" db:"session_id"`
+	PartyID       *string         `json:"partyId,omitempty" db:"party_id"`
+	CreatedAt     time.Time       `json:"createdAt" db:"created_at"`
+}
+
+// LedgerFilter selects ledger entries for an audit
+type LedgerFilter struct {
+	CharacterIDs []string
+	SessionID    string
+	PartyID      string
+	Since        *time.Time
+	Limit        int
+}
+
+// LootAward splits treasure between characters in a single transaction
+type LootAward struct {
+	SessionID   string          `json:"sessionId,omitempty"`
+	Description string          `json:"description"`
+	Recipients  []LootRecipient `json:"recipients"`
+}
+
+// LootRecipient is one character's share of a loot award
+type LootRecipient struct {
+	CharacterID string     `json:"characterId"`
+	Coins       Coins      `json:"coins"`
+	Items       []LootItem `json:"items,omitempty"`
+}
+
+// LootItem is a quantity of an item handed to a loot recipient
+type LootItem struct {
+	ItemID   string `json:"itemId"`
+	Quantity int    `json:"quantity"`
+}
+
+// TransferRequest hands coins and items from one character to another
+type TransferRequest struct {
+	FromCharacterID string     `json:"fromCharacterId"`
+	ToCharacterID   string     `json:"toCharacterId"`
+	SessionID       string     `json:"sessionId,omitempty"`
+	Coins           Coins      `json:"coins"`
+	Items           []LootItem `json:"items,omitempty"`
+	Message         string     `json:"message,omitempty"` // shown to the receiver of a trade offer
+}
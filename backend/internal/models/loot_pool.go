@@ -0,0 +1,219 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// LootDistributionMode decides who gets each item in a loot pool
+type LootDistributionMode string
+
+const (
+	LootModeDM         LootDistributionMode = "dm"          // the DM assigns every item
+	LootModeClaim      LootDistributionMode = "claim"       // first come, first served
+	LootModeRoundRobin LootDistributionMode = "round_robin" // items go to the party in turn
+	LootModeNeedGreed  LootDistributionMode = "need_greed"  // highest need roll wins, then highest greed roll
+)
+
+// Valid reports whether the mode is one of the supported distribution modes
+func (m LootDistributionMode) Valid() bool {
+	switch m {
+	case LootModeDM, LootModeClaim, LootModeRoundRobin, LootModeNeedGreed:
+		return true
+	}
+	return false
+}
+
+// LootPoolStatus tracks whether a pool has been handed out
+type LootPoolStatus string
+
+const (
+	LootPoolStatusOpen        LootPoolStatus = "open"
+	LootPoolStatusDistributed LootPoolStatus = "distributed"
+)
+
+// LootSourceType records what produced a loot pool
+type LootSourceType string
+
+const (
+	LootSourceCombat         LootSourceType = "combat"
+	LootSourceAutoResolution LootSourceType = "auto_resolution"
+	LootSourceObjective      LootSourceType = "encounter_objective"
+	LootSourceManual         LootSourceType = "manual"
+)
+
+// LootRollChoice is a character's call on a need/greed item
+type LootRollChoice string
+
+const (
+	LootRollNeed  LootRollChoice = "need"
+	LootRollGreed LootRollChoice = "greed"
+	LootRollPass  LootRollChoice = "pass"
+)
+
+// LootPool holds the treasure and experience from one fight or objective until it is
+// handed out. Coins and experience are split evenly between the pool's characters;
+// items go to whoever the distribution mode picks.
+type LootPool struct {
+	ID            string               `json:"id"`
+	SessionID     string               `json:"sessionId"`
+	SourceType    LootSourceType       `json:"sourceType"`
+	SourceID      string               `json:"sourceId,omitempty"`
+	Description   string               `json:"description"`
+	Mode          LootDistributionMode `json:"mode"`
+	Status        LootPoolStatus       `json:"status"`
+	Coins         Coins                `json:"coins"`
+	Experience    int                  `json:"experience"`
+	CharacterIDs  []string             `json:"characterIds"`
+	Items         []*LootPoolItem      `json:"items"`
+	TransactionID *string              `json:"transactionId,omitempty"`
+	CreatedBy     string               `json:"createdBy,omitempty"`
+	CreatedAt     time.Time            `json:"createdAt"`
+	DistributedAt *time.Time           `json:"distributedAt,omitempty"`
+}
+
+// LootPoolItem is a stack of one item in a loot pool
+type LootPoolItem struct {
+	ID         string     `json:"id"`
+	PoolID     string     `json:"poolId"`
+	ItemID     string     `json:"itemId"`
+	Name       string     `json:"name"`
+	Rarity     ItemRarity `json:"rarity,omitempty"`
+	Quantity   int        `json:"quantity"`
+	AssignedTo *string    `json:"assignedTo,omitempty"`
+	Rolls      []LootRoll `json:"rolls,omitempty"`
+}
+
+// LootRoll is one character's need/greed roll on a pool item
+type LootRoll struct {
+	PoolItemID  string         `json:"poolItemId"`
+	CharacterID string         `json:"characterId"`
+	Choice      LootRollChoice `json:"choice"`
+	Roll        int            `json:"roll"`
+	CreatedAt   time.Time      `json:"createdAt"`
+}
+
+// Item returns the pool item with the given ID
+func (p *LootPool) Item(poolItemID string) *LootPoolItem {
+	for _, item := range p.Items {
+		if item.ID == poolItemID {
+			return item
+		}
+	}
+	return nil
+}
+
+// HasCharacter reports whether the character shares in the pool
+func (p *LootPool) HasCharacter(characterID string) bool {
+	for _, id := range p.CharacterIDs {
+		if id == characterID {
+			return true
+		}
+	}
+	return false
+}
+
+// RollWinner returns the character whose need/greed roll takes the item: any need beats
+// every greed, and the earlier roll wins a tie. It returns "" when everyone passed.
+func (item *LootPoolItem) RollWinner() string {
+	var best *LootRoll
+	for i := range item.Rolls {
+		roll := &item.Rolls[i]
+		if roll.Choice == LootRollPass {
+			continue
+		}
+		if best == nil || rollBeats(roll, best) {
+			best = roll
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return best.CharacterID
+}
+
+func rollBeats(roll, best *LootRoll) bool {
+	if roll.Choice != best.Choice {
+		return roll.Choice == LootRollNeed
+	}
+	return roll.Roll > best.Roll
+}
+
+// SplitCoins divides coins evenly between n shares, making change so every share is
+// worth the same to the copper piece. Copper that does not divide goes one piece each
+// to the first shares.
+func SplitCoins(coins Coins, n int) []Coins {
+	shares := make([]Coins, n)
+	for i, copper := range SplitEvenly(coins.TotalInCopper(), n) {
+		shares[i] = CoinsFromCopper(copper)
+	}
+	return shares
+}
+
+// SplitEvenly divides total into n whole parts that differ by at most one, larger parts first
+func SplitEvenly(total, n int) []int {
+	parts := make([]int, n)
+	if n == 0 {
+		return parts
+	}
+	for i := range parts {
+		parts[i] = total / n
+		if i < total%n {
+			parts[i]++
+		}
+	}
+	return parts
+}
+
+// CreateLootPoolRequest is a DM putting treasure up for the party
+type CreateLootPoolRequest struct {
+	Description  string               `json:"description"`
+	Mode         LootDistributionMode `json:"mode"`
+	Coins        Coins                `json:"coins"`
+	Experience   int                  `json:"experience"`
+	Items        []LootItem           `json:"items,omitempty"`
+	CharacterIDs []string             `json:"characterIds,omitempty"` // defaults to every character in the session
+	SourceType   LootSourceType       `json:"sourceType,omitempty"`
+	SourceID     string               `json:"sourceId,omitempty"`
+}
+
+// Validate checks the request describes something worth distributing
+func (r *CreateLootPoolRequest) Validate() error {
+	if r.Mode != "" && !r.Mode.Valid() {
+		return fmt.Errorf("unknown distribution mode %q", r.Mode)
+	}
+	if r.Coins.IsNegative() || r.Experience < 0 {
+		return fmt.Errorf("loot cannot be negative")
+	}
+	for _, item := range r.Items {
+		if item.ItemID == "" || item.Quantity < 1 {
+			return fmt.Errorf("every loot item needs an item and a positive quantity")
+		}
+	}
+	if r.Coins.IsZero() && r.Experience == 0 && len(r.Items) == 0 {
+		return fmt.Errorf("loot pool is empty")
+	}
+	return nil
+}
+
+// LootPickRequest names a pool item and the character assigned, claiming or rolling for it
+type LootPickRequest struct {
+	PoolItemID  string         `json:"poolItemId"`
+	CharacterID string         `json:"characterId"`
+	Choice      LootRollChoice `json:"choice,omitempty"` // need/greed rolls only
+}
+
+// LootDistribution is what each character received when a pool was handed out
+type LootDistribution struct {
+	Pool   *LootPool      `json:"pool"`
+	Shares []*LootShare   `json:"shares"`
+	Ledger []*LedgerEntry `json:"ledger"`
+}
+
+// LootShare is one character's part of a distributed loot pool
+type LootShare struct {
+	CharacterID string     `json:"characterId"`
+	Coins       Coins      `json:"coins"`
+	Items       []LootItem `json:"items,omitempty"`
+	Experience  int        `json:"experience"`
+}
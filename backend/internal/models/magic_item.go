@@ -0,0 +1,382 @@
+package models
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ItemCharges is how many charges a wand, staff or ring holds and how it regains them
+type ItemCharges struct {
+	Max      int              `json:"max"`
+	Recharge ResourceRecovery `json:"recharge"`
+	// RechargeDice is rolled for the charges regained, e.g. "1d6+1". Empty regains all of them.
+	RechargeDice string `json:"recharge_dice,omitempty"`
+	// DestroyOnEmpty items roll a d20 when their last charge is spent and crumble on a 1
+	DestroyOnEmpty bool `json:"destroy_on_empty,omitempty"`
+}
+
+// ItemSpell is a spell a character can cast from an item by spending its charges
+type ItemSpell struct {
+	Name    string `json:"name"`
+	Level   int    `json:"level"`
+	Charges int    `json:"charges"`
+	// Upcast spells gain a spell level for each charge spent beyond the minimum
+	Upcast bool `json:"upcast,omitempty"`
+	SaveDC int  `json:"save_dc,omitempty"` // fixed DC; zero uses the caster's own
+}
+
+// ItemBonuses are the bonuses an item grants while equipped, or attuned if it requires attunement
+type ItemBonuses struct {
+	ArmorClass  int `json:"armor_class,omitempty"`
+	SavingThrow int `json:"saving_throw,omitempty"`
+	SpellAttack int `json:"spell_attack,omitempty"`
+	SpellSaveDC int `json:"spell_save_dc,omitempty"`
+	Speed       int `json:"speed,omitempty"`
+	// WeaponBonus applies to attack and damage rolls made with this weapon only
+	WeaponBonus int `json:"weapon_bonus,omitempty"`
+	// AbilityScores raise scores to a fixed value, as Gauntlets of Ogre Power set Strength to 19
+	AbilityScores map[string]int `json:"ability_scores,omitempty"`
+}
+
+// IsZero reports whether the item grants no bonuses
+func (b ItemBonuses) IsZero() bool {
+	return b.ArmorClass == 0 && b.SavingThrow == 0 && b.SpellAttack == 0 && b.SpellSaveDC == 0 &&
+		b.Speed == 0 && b.WeaponBonus == 0 && len(b.AbilityScores) == 0
+}
+
+// rechargeTextPattern reads older free-text recharge rules such as "1d6+1 at dawn"
+var rechargeTextPattern = regexp.MustCompile(`^\s*(\d*d\d+(?:\s*[+-]\s*\d+)?)?\s*(?:at\s+)?(.*?)\s*$`)
+
+// Charges returns the item's charges, or nil when it has none. Charges come from the
+// charges, recharge (dawn, long_rest, short_rest or text such as "1d6+1 at dawn"),
+// recharge_dice and destroy_on_empty properties.
+func (i *Item) Charges() *ItemCharges {
+	maxCharges := int(propertyNumber(i.Properties, "charges", "max_charges"))
+	if maxCharges <= 0 {
+		return nil
+	}
+
+	charges := &ItemCharges{
+		Max:            maxCharges,
+		Recharge:       RecoveryCustom,
+		DestroyOnEmpty: i.Properties["destroy_on_empty"] == true,
+	}
+	if dice, ok := i.Properties["recharge_dice"].(string); ok {
+		charges.RechargeDice = strings.ReplaceAll(dice, " ", "")
+	}
+	if rule, ok := i.Properties["recharge"].(string); ok {
+		match := rechargeTextPattern.FindStringSubmatch(strings.ToLower(rule))
+		if match[1] != "" && charges.RechargeDice == "" {
+			charges.RechargeDice = strings.ReplaceAll(match[1], " ", "")
+		}
+		switch strings.ReplaceAll(match[2], " ", "_") {
+		case "dawn", "daily_at_dawn":
+			charges.Recharge = RecoveryDawn
+		case "long_rest":
+			charges.Recharge = RecoveryLongRest
+		case "short_rest", "short_or_long_rest":
+			charges.Recharge = RecoveryShortRest
+		}
+	}
+	if strings.HasPrefix(charges.RechargeDice, "d") {
+		charges.RechargeDice = "1" + charges.RechargeDice
+	}
+	return charges
+}
+
+// Spells lists the spells the item can cast, from its spells property or the older
+// spell, spell_level and charges_per_cast keys
+func (i *Item) Spells() []ItemSpell {
+	var spells []ItemSpell
+	if list, ok := i.Properties["spells"].([]interface{}); ok {
+		for _, entry := range list {
+			p, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := p["name"].(string)
+			if name == "" {
+				continue
+			}
+			spells = append(spells, ItemSpell{
+				Name:    name,
+				Level:   spellLevel(p["level"]),
+				Charges: max(1, int(propertyNumber(p, "charges"))),
+				Upcast:  p["upcast"] == true,
+				SaveDC:  int(propertyNumber(p, "save_dc")),
+			})
+		}
+	}
+	if name, ok := i.Properties["spell"].(string); ok && name != "" {
+		spells = append(spells, ItemSpell{
+			Name:    name,
+			Level:   spellLevel(i.Properties["spell_level"]),
+			Charges: max(1, int(propertyNumber(i.Properties, "charges_per_cast"))),
+			// Spending extra charges to raise the spell level is how charged wands work
+			Upcast: i.Charges() != nil,
+		})
+	}
+	return spells
+}
+
+// Spell returns the named spell the item can cast, or nil
+func (i *Item) Spell(name string) *ItemSpell {
+	for _, spell := range i.Spells() {
+		if strings.EqualFold(spell.Name, name) {
+			return &spell
+		}
+	}
+	return nil
+}
+
+// Bonuses returns what the item adds to its wielder's statistics. A weapon's magic_bonus
+// improves its own attacks; on armor or a shield it improves AC.
+func (i *Item) Bonuses() ItemBonuses {
+	p := i.Properties
+	bonuses := ItemBonuses{
+		ArmorClass:  int(propertyNumber(p, "ac_bonus")),
+		SavingThrow: int(propertyNumber(p, "saving_throw_bonus")),
+		SpellAttack: int(propertyNumber(p, "spell_attack_bonus")),
+		SpellSaveDC: int(propertyNumber(p, "spell_save_dc_bonus")),
+		Speed:       int(propertyNumber(p, "speed_bonus")),
+	}
+	switch magic := int(propertyNumber(p, "magic_bonus")); i.Type {
+	case ItemTypeWeapon:
+		bonuses.WeaponBonus = magic
+	case ItemTypeArmor:
+		bonuses.ArmorClass += magic
+	}
+	if scores, ok := p["ability_scores"].(map[string]interface{}); ok {
+		bonuses.AbilityScores = make(map[string]int, len(scores))
+		for ability := range scores {
+			bonuses.AbilityScores[strings.ToLower(ability)] = int(propertyNumber(scores, ability))
+		}
+	}
+	return bonuses
+}
+
+// IsCursed reports whether the item carries a curse that binds its owner once it takes hold
+func (i *Item) IsCursed() bool {
+	return i.Properties["cursed"] == true
+}
+
+// Curse describes the item's curse, if it has one
+func (i *Item) Curse() string {
+	curse, _ := i.Properties["curse"].(string)
+	return curse
+}
+
+// ChargesRemaining is how many charges the stack has left. A stack that has never
+// been used is fully charged.
+func (inv *InventoryItem) ChargesRemaining() int {
+	if inv.Charges != nil {
+		return *inv.Charges
+	}
+	if inv.Item == nil {
+		return 0
+	}
+	if charges := inv.Item.Charges(); charges != nil {
+		return charges.Max
+	}
+	return 0
+}
+
+// IsActive reports whether the item's bonuses apply: it must be carried and equipped,
+// and attuned as well if it requires attunement
+func (inv *InventoryItem) IsActive() bool {
+	if inv.Item == nil || (inv.Location != "" && inv.Location != ItemLocationCarried) {
+		return false
+	}
+	if inv.Item.RequiresAttunement {
+		return inv.Attuned
+	}
+	return inv.Equipped
+}
+
+// UseChargesRequest spends an item's charges, usually to cast one of its spells
+type UseChargesRequest struct {
+	Spell   string `json:"spell,omitempty"`
+	Charges int    `json:"charges,omitempty"` // defaults to the spell's cost, or 1
+}
+
+// ChargeUse is the outcome of spending an item's charges
+type ChargeUse struct {
+	ItemID           string     `json:"item_id"`
+	Spell            *ItemSpell `json:"spell,omitempty"`
+	CastLevel        int        `json:"cast_level,omitempty"`
+	ChargesSpent     int        `json:"charges_spent"`
+	ChargesRemaining int        `json:"charges_remaining"`
+	DestroyRoll      int        `json:"destroy_roll,omitempty"`
+	Destroyed        bool       `json:"destroyed,omitempty"`
+}
+
+// RemoveCurseMethod is how a curse was lifted
+type RemoveCurseMethod string
+
+const (
+	RemoveCurseByDM    RemoveCurseMethod = "dm"
+	RemoveCurseBySpell RemoveCurseMethod = "remove_curse"
+)
+
+// RemoveCurseRequest lifts the curse binding a character to an item. Casting Remove Curse
+// spends the caster's spell slot, so the caster must know the spell and have one left.
+type RemoveCurseRequest struct {
+	Method    RemoveCurseMethod `json:"method"`
+	CasterID  string            `json:"caster_id,omitempty"`  // character casting Remove Curse; empty is the cursed character
+	SlotLevel int               `json:"slot_level,omitempty"` // defaults to 3rd level
+}
+
+// ActiveItemBonus is one equipped or attuned item's contribution to a character's statistics
+type ActiveItemBonus struct {
+	ItemID  string      `json:"item_id"`
+	Name    string      `json:"name"`
+	Bonuses ItemBonuses `json:"bonuses"`
+}
+
+// ItemEffects is a character's statistics with the bonuses of their active magic items applied
+type ItemEffects struct {
+	Sources      []ActiveItemBonus `json:"sources"`
+	ArmorClass   int               `json:"armor_class"`
+	Speed        int               `json:"speed"`
+	WeaponAttack int               `json:"weapon_attack_bonus"` // the best magic bonus among active weapons
+	SpellAttack  int               `json:"spell_attack_bonus"`
+	SpellSaveDC  int               `json:"spell_save_dc"`
+	Attributes   Attributes        `json:"attributes"`
+	SavingThrows SavingThrows      `json:"saving_throws"`
+}
+
+// SavingThrowModifier returns the saving throw modifier for an ability with item bonuses applied
+func (e *ItemEffects) SavingThrowModifier(ability string) int {
+	if save := e.SavingThrows.field(strings.ToLower(ability)); save != nil {
+		return save.Modifier
+	}
+	return 0
+}
+
+// ApplyToCombatant gives a character's combatant the AC, speed, attack and save bonuses their
+// items grant. Combatants are built from stored character values, which never include them.
+func (e *ItemEffects) ApplyToCombatant(c *Combatant) {
+	c.AC = e.ArmorClass
+	c.Speed = e.Speed
+	c.AttackBonus += e.WeaponAttack
+	if e.SpellSaveDC > 0 {
+		c.SpellAttackBonus = e.SpellAttack
+		c.SpellSaveDC = e.SpellSaveDC
+	}
+	if c.SavingThrows == nil {
+		c.SavingThrows = make(map[string]int, len(abilityNames))
+	}
+	for _, ability := range abilityNames {
+		c.SavingThrows[ability] = e.SavingThrowModifier(ability)
+	}
+}
+
+// ApplyItemBonuses derives a character's statistics with their active items' bonuses.
+// The character itself is left unchanged, so stored values never include item bonuses.
+func ApplyItemBonuses(char *Character, inventory []*InventoryItem) *ItemEffects {
+	effects := &ItemEffects{
+		Sources:      []ActiveItemBonus{},
+		ArmorClass:   char.ArmorClass,
+		Speed:        char.Speed,
+		SpellAttack:  char.Spells.SpellAttackBonus,
+		SpellSaveDC:  char.Spells.SpellSaveDC,
+		Attributes:   char.Attributes,
+		SavingThrows: char.SavingThrows,
+	}
+
+	saveBonus := 0
+	for _, inv := range inventory {
+		if !inv.IsActive() {
+			continue
+		}
+		bonuses := inv.Item.Bonuses()
+		if bonuses.IsZero() {
+			continue
+		}
+		effects.Sources = append(effects.Sources, ActiveItemBonus{ItemID: inv.ItemID, Name: inv.Item.Name, Bonuses: bonuses})
+		effects.ArmorClass += bonuses.ArmorClass
+		effects.Speed += bonuses.Speed
+		effects.WeaponAttack = max(effects.WeaponAttack, bonuses.WeaponBonus)
+		effects.SpellAttack += bonuses.SpellAttack
+		effects.SpellSaveDC += bonuses.SpellSaveDC
+		saveBonus += bonuses.SavingThrow
+		for ability, score := range bonuses.AbilityScores {
+			if field := effects.Attributes.field(ability); field != nil && *field < score {
+				*field = score
+			}
+		}
+	}
+
+	// Saving throws follow any ability score an item raised, then take flat save bonuses
+	for _, ability := range abilityNames {
+		save := effects.SavingThrows.field(ability)
+		before, after := char.Attributes.field(ability), effects.Attributes.field(ability)
+		save.Modifier += abilityModifier(*after) - abilityModifier(*before) + saveBonus
+	}
+	return effects
+}
+
+var abilityNames = []string{"strength", "dexterity", "constitution", "intelligence", "wisdom", "charisma"}
+
+func (a *Attributes) field(ability string) *int {
+	switch ability {
+	case "strength":
+		return &a.Strength
+	case "dexterity":
+		return &a.Dexterity
+	case "constitution":
+		return &a.Constitution
+	case "intelligence":
+		return &a.Intelligence
+	case "wisdom":
+		return &a.Wisdom
+	case "charisma":
+		return &a.Charisma
+	}
+	return nil
+}
+
+func (s *SavingThrows) field(ability string) *SavingThrow {
+	switch ability {
+	case "strength":
+		return &s.Strength
+	case "dexterity":
+		return &s.Dexterity
+	case "constitution":
+		return &s.Constitution
+	case "intelligence":
+		return &s.Intelligence
+	case "wisdom":
+		return &s.Wisdom
+	case "charisma":
+		return &s.Charisma
+	}
+	return nil
+}
+
+func abilityModifier(score int) int {
+	// Floor division so that 9 gives -1 rather than 0
+	if score >= 10 {
+		return (score - 10) / 2
+	}
+	return (score - 11) / 2
+}
+
+// spellLevel reads a spell level given as a number or as text like "1st" or "cantrip"
+func spellLevel(value interface{}) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case string:
+		if strings.EqualFold(v, "cantrip") {
+			return 0
+		}
+		digits := strings.TrimRight(v, "stndrh")
+		level, _ := strconv.Atoi(digits)
+		return level
+	}
+	return 0
+}
@@ -0,0 +1,91 @@
+package models
+
+import "time"
+
+// Party is the group of characters playing a game session. It owns a shared stash and coin purse.
+type Party struct {
+	ID        string    `json:"id" db:"id"`
+	SessionID string    `json:"sessionId" db:"session_id"`
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// PartyStashItem is a quantity of an item held in the party stash
+type PartyStashItem struct {
+	PartyID   string    `json:"partyId" db:"party_id"`
+	ItemID    string    `json:"itemId" db:"item_id"`
+	Quantity  int       `json:"quantity" db:"quantity"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+
+	// Related data
+	Item *Item `json:"item,omitempty" db:"-"`
+}
+
+// PartyStash is everything a party holds in common
+type PartyStash struct {
+	Party *Party            `json:"party"`
+	Coins Coins             `json:"coins"`
+	Items []*PartyStashItem `json:"items"`
+}
+
+// PartyStashMovement adds items to (or, when negative, takes items from) a party stash
+type PartyStashMovement struct {
+	PartyID  string `json:"partyId"`
+	ItemID   string `json:"itemId"`
+	Quantity int    `json:"quantity"`
+}
+
+// PartyCurrencyMovement adds coins to (or, when negative, takes coins from) a party purse
+type PartyCurrencyMovement struct {
+	PartyID string `json:"partyId"`
+	Coins   Coins  `json:"coins"`
+}
+
+// StashRequest moves a character's coins and items into or out of the party stash
+type StashRequest struct {
+	CharacterID string     `json:"characterId"`
+	Coins       Coins      `json:"coins"`
+	Items       []LootItem `json:"items,omitempty"`
+}
+
+// TradeStatus is where a trade offer stands
+type TradeStatus string
+
+const (
+	TradeStatusPending   TradeStatus = "pending"
+	TradeStatusAccepted  TradeStatus = "accepted"
+	TradeStatusDeclined  TradeStatus = "declined"
+	TradeStatusCancelled TradeStatus = "cancelled"
+)
+
+// TradeOffer is a transfer from one character to another that waits for the receiver to accept it
+type TradeOffer struct {
+	ID              string      `json:"id"`
+	SessionID       *string     `json:"sessionId,omitempty"`
+	FromCharacterID string      `json:"fromCharacterId"`
+	ToCharacterID   string      `json:"toCharacterId"`
+	Coins           Coins       `json:"coins"`
+	Items           []LootItem  `json:"items,omitempty"`
+	Message         string      `json:"message,omitempty"`
+	Status          TradeStatus `json:"status"`
+	CreatedBy       string      `json:"createdBy"`
+	ResolvedBy      *string     `json:"resolvedBy,omitempty"`
+	TransactionID   *string     `json:"transactionId,omitempty"` // ledger transaction of an accepted offer
+	CreatedAt       time.Time   `json:"createdAt"`
+	ResolvedAt      *time.Time  `json:"resolvedAt,omitempty"`
+}
+
+// TransferRequest returns the transfer the offer makes once accepted
+func (o *TradeOffer) TransferRequest() *TransferRequest {
+	req := &TransferRequest{
+		FromCharacterID: o.FromCharacterID,
+		ToCharacterID:   o.ToCharacterID,
+		Coins:           o.Coins,
+		Items:           o.Items,
+	}
+	if o.SessionID != nil {
+		req.SessionID = *o.SessionID
+	}
+	return req
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// RoomMessage is a numbered websocket room message, kept for clients that reconnect
+type RoomMessage struct {
+	RoomID    string    `db:"room_id"`
+	Seq       int64     `db:"seq"`
+	Data      []byte    `db:"data"`
+	CreatedAt time.Time `db:"created_at"`
+}
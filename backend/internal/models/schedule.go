@@ -0,0 +1,160 @@
+package models
+
+import "time"
+
+// Recurrence is how often a scheduled session repeats
+type Recurrence string
+
+const (
+	RecurrenceNone    Recurrence = "none"    // a one-off session
+	RecurrenceWeekly  Recurrence = "weekly"  // every Interval weeks, on the same weekday
+	RecurrenceMonthly Recurrence = "monthly" // every Interval months, on the same day of the month
+)
+
+// RSVPResponse is a player's answer to a scheduled session or a poll option
+type RSVPResponse string
+
+const (
+	RSVPYes   RSVPResponse = "yes"
+	RSVPNo    RSVPResponse = "no"
+	RSVPMaybe RSVPResponse = "maybe"
+)
+
+// Valid reports whether the response is yes, no or maybe
+func (r RSVPResponse) Valid() bool {
+	return r == RSVPYes || r == RSVPNo || r == RSVPMaybe
+}
+
+// SessionSchedule is a slot the DM has set aside for a game session, such as every other
+// Friday at 7pm. Its occurrences fall at the same wall clock time in its timezone, so a
+// session keeps its local start time across daylight saving changes.
+type SessionSchedule struct {
+	ID              string     `json:"id" db:"id"`
+	SessionID       string     `json:"sessionId" db:"session_id"`
+	Title           string     `json:"title" db:"title"`
+	Notes           string     `json:"notes,omitempty" db:"notes"`
+	StartsAt        time.Time  `json:"startsAt" db:"starts_at"` // the first occurrence
+	DurationMinutes int        `json:"durationMinutes" db:"duration_minutes"`
+	Timezone        string     `json:"timezone" db:"timezone"` // an IANA zone, e.g. "Europe/Berlin"
+	Recurrence      Recurrence `json:"recurrence" db:"recurrence"`
+	Interval        int        `json:"interval" db:"recurrence_interval"`
+	Until           *time.Time `json:"until,omitempty" db:"until"`            // no occurrences start after it
+	ReminderMinutes int        `json:"reminderMinutes" db:"reminder_minutes"` // 0 sends no reminder
+	CreatedBy       string     `json:"createdBy" db:"created_by"`
+	CreatedAt       time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// SessionScheduleRequest creates or replaces a session schedule. StartsAt is read as wall
+// clock time in Timezone when it carries no offset of its own.
+type SessionScheduleRequest struct {
+	Title           string     `json:"title" validate:"required,max=200"`
+	Notes           string     `json:"notes,omitempty" validate:"max=2000"`
+	StartsAt        string     `json:"startsAt" validate:"required"`
+	DurationMinutes int        `json:"durationMinutes,omitempty" validate:"min=0,max=1440"`
+	Timezone        string     `json:"timezone" validate:"required"`
+	Recurrence      Recurrence `json:"recurrence,omitempty" validate:"omitempty,oneof=none weekly monthly"`
+	Interval        int        `json:"interval,omitempty" validate:"min=0,max=52"`
+	Until           *time.Time `json:"until,omitempty"`
+	ReminderMinutes *int       `json:"reminderMinutes,omitempty"`
+}
+
+// SessionRSVP is a player's answer to one occurrence of a scheduled session
+type SessionRSVP struct {
+	ScheduleID string       `json:"scheduleId" db:"schedule_id"`
+	StartsAt   time.Time    `json:"startsAt" db:"occurrence_start"`
+	UserID     string       `json:"userId" db:"user_id"`
+	Response   RSVPResponse `json:"response" db:"response"`
+	Note       string       `json:"note,omitempty" db:"note"`
+	UpdatedAt  time.Time    `json:"updatedAt" db:"updated_at"`
+}
+
+// RSVPRequest answers one occurrence of a scheduled session
+type RSVPRequest struct {
+	StartsAt time.Time    `json:"startsAt" validate:"required"`
+	Response RSVPResponse `json:"response" validate:"required,oneof=yes no maybe"`
+	Note     string       `json:"note,omitempty" validate:"max=500"`
+}
+
+// ScheduledSession is one occurrence of a schedule with the answers to it so far
+type ScheduledSession struct {
+	ScheduleID string               `json:"scheduleId"`
+	SessionID  string               `json:"sessionId"`
+	Title      string               `json:"title"`
+	StartsAt   time.Time            `json:"startsAt"`
+	EndsAt     time.Time            `json:"endsAt"`
+	Timezone   string               `json:"timezone"`
+	RSVPs      []*SessionRSVP       `json:"rsvps"`
+	Counts     map[RSVPResponse]int `json:"counts"`
+	Pending    []string             `json:"pending"` // members who have not answered
+}
+
+// AvailabilityPollStatus is whether a poll still takes votes
+type AvailabilityPollStatus string
+
+const (
+	AvailabilityPollOpen   AvailabilityPollStatus = "open"
+	AvailabilityPollClosed AvailabilityPollStatus = "closed"
+)
+
+// AvailabilityPoll asks a session's members which of several dates suit them
+type AvailabilityPoll struct {
+	ID              string                 `json:"id" db:"id"`
+	SessionID       string                 `json:"sessionId" db:"session_id"`
+	Title           string                 `json:"title" db:"title"`
+	Timezone        string                 `json:"timezone" db:"timezone"`
+	DurationMinutes int                    `json:"durationMinutes" db:"duration_minutes"`
+	Status          AvailabilityPollStatus `json:"status" db:"status"`
+	ChosenOptionID  *string                `json:"chosenOptionId,omitempty" db:"chosen_option_id"`
+	ScheduleID      *string                `json:"scheduleId,omitempty" db:"schedule_id"` // the session booked when it closed
+	CreatedBy       string                 `json:"createdBy" db:"created_by"`
+	CreatedAt       time.Time              `json:"createdAt" db:"created_at"`
+	ClosedAt        *time.Time             `json:"closedAt,omitempty" db:"closed_at"`
+	Options         []*PollOption          `json:"options" db:"-"`
+}
+
+// PollOption is a proposed date in an availability poll and the votes for it
+type PollOption struct {
+	ID       string               `json:"id" db:"id"`
+	PollID   string               `json:"pollId" db:"poll_id"`
+	StartsAt time.Time            `json:"startsAt" db:"starts_at"`
+	Votes    []*PollVote          `json:"votes" db:"-"`
+	Counts   map[RSVPResponse]int `json:"counts" db:"-"`
+	Rank     int                  `json:"rank" db:"-"` // 1 for the best date
+}
+
+// PollVote is a member's availability for a poll option
+type PollVote struct {
+	OptionID string       `json:"optionId" db:"option_id"`
+	UserID   string       `json:"userId" db:"user_id"`
+	Response RSVPResponse `json:"response" db:"response"`
+}
+
+// AvailabilityPollRequest proposes dates to a session's members. Options are read as wall
+// clock times in Timezone when they carry no offset of their own.
+type AvailabilityPollRequest struct {
+	Title           string   `json:"title" validate:"required,max=200"`
+	Timezone        string   `json:"timezone" validate:"required"`
+	DurationMinutes int      `json:"durationMinutes,omitempty" validate:"min=0,max=1440"`
+	Options         []string `json:"options" validate:"required,min=2,max=20"`
+}
+
+// PollVoteRequest sets the caller's availability, by option ID
+type PollVoteRequest struct {
+	Votes map[string]RSVPResponse `json:"votes" validate:"required"`
+}
+
+// ClosePollRequest closes a poll, booking a session on the chosen option. Without an
+// option the best ranked date is booked.
+type ClosePollRequest struct {
+	OptionID string `json:"optionId,omitempty"`
+}
+
+// SessionReminder is the email sent to a session's members before it starts
+type SessionReminder struct {
+	ScheduleID string    `json:"scheduleId"`
+	StartsAt   time.Time `json:"startsAt"`
+	To         []string  `json:"to"`
+	Subject    string    `json:"subject"`
+	Body       string    `json:"body"`
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ShopTradeSide is whether a character buys from or sells to a shop
+type ShopTradeSide string
+
+const (
+	ShopTradeBuy  ShopTradeSide = "buy"
+	ShopTradeSell ShopTradeSide = "sell"
+)
+
+// ShopStockItem is a quantity of an item a settlement shop has for sale
+type ShopStockItem struct {
+	ShopID    uuid.UUID `json:"shopId" db:"shop_id"`
+	ItemID    string    `json:"itemId" db:"item_id"`
+	Quantity  int       `json:"quantity" db:"quantity"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+
+	// Related data
+	Item      *Item `json:"item,omitempty" db:"-"`
+	UnitPrice int   `json:"unitPrice,omitempty" db:"-"` // current asking price in copper
+}
+
+// ShopStockMovement adds stock to (or, when negative, takes stock from) a shop
+type ShopStockMovement struct {
+	ShopID   uuid.UUID `json:"shopId"`
+	ItemID   string    `json:"itemId"`
+	Quantity int       `json:"quantity"`
+}
+
+// MarketTradeVolume counts units players bought from and sold to a settlement's market
+type MarketTradeVolume struct {
+	SettlementID uuid.UUID `json:"settlementId" db:"settlement_id"`
+	Category     string    `json:"category" db:"category"`
+	UnitsBought  int       `json:"unitsBought" db:"units_bought"`
+	UnitsSold    int       `json:"unitsSold" db:"units_sold"`
+	UpdatedAt    time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// PriceFactor is one multiplier that went into a market price
+type PriceFactor struct {
+	Name       string  `json:"name"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+// ShopDetails is a shop with its current stock and asking prices
+type ShopDetails struct {
+	Shop  *SettlementShop  `json:"shop"`
+	Stock []*ShopStockItem `json:"stock"`
+}
+
+// ShopTradeRequest buys items from or sells items to a shop
+type ShopTradeRequest struct {
+	CharacterID string `json:"characterId"`
+	ItemID      string `json:"itemId"`
+	Quantity    int    `json:"quantity"`
+	Haggle      bool   `json:"haggle,omitempty"` // roll Persuasion for a better price
+}
+
+// ShopQuote is the price a shop offers for a trade
+type ShopQuote struct {
+	ShopID     uuid.UUID     `json:"shopId"`
+	ItemID     string        `json:"itemId"`
+	ItemName   string        `json:"itemName"`
+	Side       ShopTradeSide `json:"side"`
+	Category   string        `json:"category"`
+	Quantity   int           `json:"quantity"`
+	InStock    int           `json:"inStock"`
+	BasePrice  int           `json:"basePrice"` // list value of one item in copper
+	UnitPrice  int           `json:"unitPrice"` // copper per item after all factors
+	TotalPrice int           `json:"totalPrice"`
+	Factors    []PriceFactor `json:"factors"`
+}
+
+// HaggleResult is the Persuasion check made while haggling
+type HaggleResult struct {
+	Roll       int     `json:"roll"`
+	Modifier   int     `json:"modifier"`
+	Total      int     `json:"total"`
+	DC         int     `json:"dc"`
+	Success    bool    `json:"success"`
+	Adjustment float64 `json:"adjustment"` // fraction knocked off (buying) or added to (selling) the price
+}
+
+// ShopTradeResult reports a completed shop trade
+type ShopTradeResult struct {
+	Quote  *ShopQuote     `json:"quote"`
+	Haggle *HaggleResult  `json:"haggle,omitempty"`
+	Ledger []*LedgerEntry `json:"ledger"`
+}
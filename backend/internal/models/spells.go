@@ -0,0 +1,63 @@
+package models
+
+// SpellCasterType describes how a class gains access to the spells it can cast
+type SpellCasterType string
+
+const (
+	// SpellCasterKnown casters (bard, sorcerer, ranger, warlock) always have their known spells ready
+	SpellCasterKnown SpellCasterType = "known"
+	// SpellCasterPrepared casters (cleric, druid, paladin) prepare from their whole class list each day
+	SpellCasterPrepared SpellCasterType = "prepared"
+	// SpellCasterSpellbook casters (wizard) prepare each day from the spells copied into their spellbook
+	SpellCasterSpellbook SpellCasterType = "spellbook"
+)
+
+// SpellbookCopyCostPerLevel is the gold cost per spell level to copy a spell into a spellbook
+const SpellbookCopyCostPerLevel = 50
+
+// SpellcastingSummary reports a character's spell access against their class limits
+type SpellcastingSummary struct {
+	CharacterID      string          `json:"characterId"`
+	CasterType       SpellCasterType `json:"casterType"`
+	Ability          string          `json:"ability"`
+	RitualCasting    bool            `json:"ritualCasting"`
+	MaxSpellLevel    int             `json:"maxSpellLevel"`
+	CantripsKnown    int             `json:"cantripsKnown"`
+	CantripsLimit    int             `json:"cantripsLimit"`
+	SpellsKnown      int             `json:"spellsKnown"`
+	SpellsKnownLimit int             `json:"spellsKnownLimit,omitempty"` // zero means no fixed cap
+	FreeSpellbook    int             `json:"freeSpellbookSpells,omitempty"`
+	Prepared         int             `json:"prepared"`
+	PreparationLimit int             `json:"preparationLimit,omitempty"`
+	Spells           []Spell         `json:"spells"`
+}
+
+// LearnSpellRequest adds a spell to a character's known spells or spellbook
+type LearnSpellRequest struct {
+	Spell string `json:"spell"`
+	// Copy pays the spellbook copying cost once a wizard's free spells are used up
+	Copy bool `json:"copy,omitempty"`
+}
+
+// CastSpellRequest casts a known or prepared spell
+type CastSpellRequest struct {
+	Spell     string `json:"spell"`
+	SlotLevel int    `json:"slotLevel,omitempty"` // defaults to the spell's level
+	Ritual    bool   `json:"ritual,omitempty"`
+}
+
+// MaterialComponentUse records a costly component checked or consumed by a casting
+type MaterialComponentUse struct {
+	ItemID   string `json:"itemId"`
+	Quantity int    `json:"quantity"`
+	Consumed bool   `json:"consumed"`
+}
+
+// SpellCastResult describes the outcome of casting a spell
+type SpellCastResult struct {
+	Spell      Spell                 `json:"spell"`
+	SlotLevel  int                   `json:"slotLevel"`
+	Ritual     bool                  `json:"ritual"`
+	Components *MaterialComponentUse `json:"components,omitempty"`
+	Character  *Character            `json:"character"`
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// StartingEquipmentMethod describes how a new character receives starting gear
+type StartingEquipmentMethod string
+
+const (
+	// StartingEquipmentMethodEquipment grants the class and background equipment packages
+	StartingEquipmentMethodEquipment StartingEquipmentMethod = "equipment"
+	// StartingEquipmentMethodGold replaces the class equipment with rolled starting wealth
+	StartingEquipmentMethodGold StartingEquipmentMethod = "gold"
+)
+
+// EquipmentEntry is a single parsed line of starting equipment, e.g. "20 arrows"
+type EquipmentEntry struct {
+	Name         string   `json:"name"`
+	ItemID       string   `json:"itemId,omitempty"`
+	Quantity     int      `json:"quantity"`
+	Category     string   `json:"category,omitempty"`     // set when the player must pick an item, e.g. "martial weapon"
+	Alternatives []string `json:"alternatives,omitempty"` // set for "X or Y" entries
+	IsPack       bool     `json:"isPack,omitempty"`
+	Gold         int      `json:"gold,omitempty"`
+}
+
+// EquipmentOption is one alternative of a starting equipment choice
+type EquipmentOption struct {
+	Description string           `json:"description"`
+	Entries     []EquipmentEntry `json:"entries"`
+}
+
+// EquipmentChoiceGroup is a "choose one of" block from the class starting equipment
+type EquipmentChoiceGroup struct {
+	Index   int               `json:"index"`
+	Options []EquipmentOption `json:"options"`
+}
+
+// StartingWealth is the class starting gold formula, e.g. 5d4 x 10 gp
+type StartingWealth struct {
+	Dice       string `json:"dice"`
+	Multiplier int    `json:"multiplier"`
+}
+
+// StartingEquipmentOptions lists everything a player can choose from during creation
+type StartingEquipmentOptions struct {
+	Class               string                 `json:"class"`
+	Background          string                 `json:"background,omitempty"`
+	Choices             []EquipmentChoiceGroup `json:"choices"`
+	Fixed               []EquipmentEntry       `json:"fixed"`
+	BackgroundEquipment []EquipmentEntry       `json:"backgroundEquipment"`
+	StartingWealth      *StartingWealth        `json:"startingWealth,omitempty"`
+}
+
+// EquipmentChoiceSelection records which option a player picked for a choice group.
+// Substitutions map an entry index to the concrete item(s) chosen for a placeholder
+// entry such as "any simple weapon".
+type EquipmentChoiceSelection struct {
+	Group         int              `json:"group"`
+	Option        int              `json:"option"`
+	Substitutions map[int][]string `json:"substitutions,omitempty"`
+}
+
+// StartingEquipmentSelection is the player's answer to StartingEquipmentOptions
+type StartingEquipmentSelection struct {
+	Method                  StartingEquipmentMethod    `json:"method"`
+	Choices                 []EquipmentChoiceSelection `json:"choices,omitempty"`
+	FixedSubstitutions      map[int][]string           `json:"fixedSubstitutions,omitempty"`
+	BackgroundSubstitutions map[int][]string           `json:"backgroundSubstitutions,omitempty"`
+}
+
+// GrantedItem is an item written to a character's inventory by the starting equipment step
+type GrantedItem struct {
+	ItemID   string `json:"itemId"`
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+}
+
+// GrantedItems is a JSON-backed list of granted items
+type GrantedItems []GrantedItem
+
+func (g GrantedItems) Value() (driver.Value, error) {
+	return json.Marshal(g)
+}
+
+func (g *GrantedItems) Scan(value interface{}) error {
+	if value == nil {
+		*g = GrantedItems{}
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(b, g)
+}
+
+// StartingEquipmentGrant records that a character has received its starting equipment
+type StartingEquipmentGrant struct {
+	CharacterID string                  `json:"characterId" db:"character_id"`
+	Method      StartingEquipmentMethod `json:"method" db:"method"`
+	Items       GrantedItems            `json:"items" db:"items"`
+	GoldAwarded int                     `json:"goldAwarded" db:"gold_awarded"`
+	WealthRoll  int                     `json:"wealthRoll,omitempty" db:"wealth_roll"`
+	CreatedAt   time.Time               `json:"createdAt" db:"created_at"`
+}
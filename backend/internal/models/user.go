@@ -26,8 +26,9 @@ type UserWithCharacters struct {
 type ParticipantRole string
 
 const (
-	ParticipantRoleDM     ParticipantRole = "dm"
-	ParticipantRolePlayer ParticipantRole = "player"
+	ParticipantRoleDM        ParticipantRole = "dm"
+	ParticipantRolePlayer    ParticipantRole = "player"
+	ParticipantRoleSpectator ParticipantRole = "spectator" // watches the session without a seat or a character
 )
 
 // GameParticipant represents a user participating in a game session
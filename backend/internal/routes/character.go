@@ -30,6 +30,8 @@ func RegisterCharacterRoutes(api *mux.Router, cfg *Config) {
 	// Character CRUD routes
 	api.HandleFunc("/characters", auth(cfg.Handlers.GetCharacters)).Methods("GET")
 	api.HandleFunc("/characters", auth(cfg.Handlers.CreateCharacter)).Methods("POST")
+	api.HandleFunc("/characters/import", auth(cfg.Handlers.ImportCharacter)).Methods("POST")
+	api.HandleFunc("/characters/export-schema", auth(cfg.Handlers.GetCharacterExportSchema)).Methods("GET")
 	api.HandleFunc(characterByIDPath, auth(cfg.Handlers.GetCharacter)).Methods("GET")
 	api.HandleFunc(characterByIDPath, auth(cfg.Handlers.UpdateCharacter)).Methods("PUT")
 	api.HandleFunc(characterByIDPath, auth(cfg.Handlers.DeleteCharacter)).Methods("DELETE")
@@ -39,6 +41,61 @@ func RegisterCharacterRoutes(api *mux.Router, cfg *Config) {
 	api.HandleFunc("/characters/{id}/rest", auth(cfg.Handlers.Rest)).Methods("POST")
 	api.HandleFunc("/characters/{id}/add-experience", auth(cfg.Handlers.AddExperience)).Methods("POST")
 
+	// Version history routes
+	api.HandleFunc("/characters/{id}/versions", auth(cfg.Handlers.GetCharacterVersions)).Methods("GET")
+	api.HandleFunc("/characters/{id}/versions/diff", auth(cfg.Handlers.DiffCharacterVersions)).Methods("GET")
+	api.HandleFunc("/characters/{id}/versions/audit", auth(cfg.Handlers.AuditCharacterChanges)).Methods("GET")
+	api.HandleFunc("/characters/{id}/versions/{version:[0-9]+}", auth(cfg.Handlers.GetCharacterVersion)).Methods("GET")
+	api.HandleFunc("/characters/{id}/versions/{version:[0-9]+}/restore", auth(cfg.Handlers.RestoreCharacterVersion)).Methods("POST")
+
+	// Everything that happened to the character, from every session's event log
+	api.HandleFunc("/characters/{id}/timeline", auth(cfg.Handlers.GetCharacterTimeline)).Methods("GET")
+
+	// Spell management routes
+	api.HandleFunc("/characters/{id}/spells", auth(cfg.Handlers.GetCharacterSpells)).Methods("GET")
+	api.HandleFunc("/characters/{id}/spells/available", auth(cfg.Handlers.GetAvailableSpells)).Methods("GET")
+	api.HandleFunc("/characters/{id}/spells/learn", auth(cfg.Handlers.LearnSpell)).Methods("POST")
+	api.HandleFunc("/characters/{id}/spells/forget", auth(cfg.Handlers.ForgetSpell)).Methods("POST")
+	api.HandleFunc("/characters/{id}/spells/prepared", auth(cfg.Handlers.PrepareSpells)).Methods("PUT")
+
+	// Class resource routes
+	api.HandleFunc("/characters/{id}/resources", auth(cfg.Handlers.GetCharacterResources)).Methods("GET")
+	api.HandleFunc("/characters/{id}/resources/{key}", auth(cfg.Handlers.SetCustomCharacterResource)).Methods("PUT")
+	api.HandleFunc("/characters/{id}/resources/{key}/use", auth(cfg.Handlers.UseCharacterResource)).Methods("POST")
+	api.HandleFunc("/characters/{id}/resources/{key}/restore", auth(cfg.Handlers.RestoreCharacterResource)).Methods("POST")
+	api.HandleFunc("/characters/{id}/exhaustion", auth(cfg.Handlers.SetExhaustion)).Methods("PUT")
+
+	// Import and export routes
+	api.HandleFunc("/characters/{id}/export", auth(cfg.Handlers.ExportCharacter)).Methods("GET")
+
+	// Economy routes
+	api.HandleFunc("/characters/{id}/transfer", auth(cfg.Handlers.TransferToCharacter)).Methods("POST")
+	api.HandleFunc("/characters/{id}/ledger", auth(cfg.Handlers.GetCharacterLedger)).Methods("GET")
+	api.HandleFunc("/characters/{id}/trades", auth(cfg.Handlers.ListCharacterTrades)).Methods("GET")
+	api.HandleFunc("/trades/{id}/accept", auth(cfg.Handlers.AcceptTrade)).Methods("POST")
+	api.HandleFunc("/trades/{id}/decline", auth(cfg.Handlers.DeclineTrade)).Methods("POST")
+	api.HandleFunc("/trades/{id}/cancel", auth(cfg.Handlers.CancelTrade)).Methods("POST")
+
+	// Item library synced from data/items
+	api.HandleFunc("/items", auth(cfg.Handlers.ListItems)).Methods("GET")
+
+	// Crafting routes
+	api.HandleFunc("/recipes", auth(cfg.Handlers.ListRecipes)).Methods("GET")
+	api.HandleFunc("/characters/{id}/crafting", auth(cfg.Handlers.ListCraftingProjects)).Methods("GET")
+	api.HandleFunc("/characters/{id}/crafting", auth(cfg.Handlers.StartCraftingProject)).Methods("POST")
+	api.HandleFunc("/characters/{id}/downtime", auth(cfg.Handlers.GetCraftingDowntime)).Methods("GET")
+	api.HandleFunc("/characters/{id}/downtime", auth(cfg.Handlers.GrantCraftingDowntime)).Methods("POST")
+	api.HandleFunc("/crafting/{id}/work", auth(cfg.Handlers.WorkOnCraftingProject)).Methods("POST")
+	api.HandleFunc("/crafting/{id}/abandon", auth(cfg.Handlers.AbandonCraftingProject)).Methods("POST")
+
+	// Magic item routes
+	api.HandleFunc("/characters/{id}/items/{itemId}/remove-curse", auth(cfg.Handlers.RemoveItemCurse)).Methods("POST")
+
+	// Starting equipment routes
+	api.HandleFunc("/starting-equipment/options", auth(cfg.Handlers.GetStartingEquipmentOptions)).Methods("GET")
+	api.HandleFunc("/characters/{id}/starting-equipment", auth(cfg.Handlers.GetStartingEquipment)).Methods("GET")
+	api.HandleFunc("/characters/{id}/starting-equipment", auth(cfg.Handlers.ApplyStartingEquipment)).Methods("POST")
+
 	// Skill check routes
 	api.HandleFunc("/skill-check", auth(cfg.Handlers.PerformSkillCheck)).Methods("POST")
 	api.HandleFunc("/characters/{id}/checks", auth(cfg.Handlers.GetCharacterChecks)).Methods("GET")
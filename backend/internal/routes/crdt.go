@@ -6,6 +6,14 @@ import (
 	"github.com/ctclostio/DnD-Game/backend/internal/crdt"
 )
 
-func RegisterCRDTRoutes(router *mux.Router) {
-	router.HandleFunc("/ws/v1/characters/{id}/sync", crdt.SyncHandler)
+// RegisterCRDTRoutes registers the collaborative document sync endpoints. They sit
+// outside /api/v1 because the handler authenticates each socket itself.
+func RegisterCRDTRoutes(router *mux.Router, cfg *Config) {
+	if cfg.CRDTHandler == nil {
+		return
+	}
+	router.HandleFunc("/ws/v1/characters/{id}/sync", cfg.CRDTHandler.SyncHandler(crdt.DocumentCharacter))
+	router.HandleFunc("/ws/v1/parties/{id}/notes/sync", cfg.CRDTHandler.SyncHandler(crdt.DocumentPartyNotes))
+	router.HandleFunc("/ws/v1/battle-maps/{id}/annotations/sync", cfg.CRDTHandler.SyncHandler(crdt.DocumentBattleMapAnnotations))
+	router.HandleFunc("/ws/v1/sessions/{id}/prep/sync", cfg.CRDTHandler.SyncHandler(crdt.DocumentSessionPrep))
 }
@@ -19,6 +19,8 @@ func RegisterDMAssistantRoutes(api *mux.Router, cfg *Config) {
 		dmOnly(cfg.Handlers.GenerateEncounter)).Methods("POST")
 	api.HandleFunc("/dm/assistant/quest/generate",
 		dmOnly(cfg.Handlers.GenerateQuest)).Methods("POST")
+	api.HandleFunc("/dm/assistant/treasure/generate",
+		dmOnly(cfg.Handlers.GenerateTreasure)).Methods("POST")
 
 	// DM notes and session management
 	api.HandleFunc("/dm/assistant/sessions/{sessionId}/notes",
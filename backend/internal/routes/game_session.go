@@ -15,6 +15,7 @@ func RegisterGameSessionRoutes(api *mux.Router, cfg *Config) {
 	api.HandleFunc("/game/sessions/{id}", auth(cfg.Handlers.GetGameSession)).Methods("GET")
 	api.HandleFunc("/game/sessions/{id}", dmOnly(cfg.Handlers.UpdateGameSession)).Methods("PUT")
 	api.HandleFunc("/game/sessions/{id}/join", auth(cfg.Handlers.JoinGameSession)).Methods("POST")
+	api.HandleFunc("/game/sessions/{id}/spectate", auth(cfg.Handlers.SpectateGameSession)).Methods("POST")
 	api.HandleFunc("/game/sessions/{id}/leave", auth(cfg.Handlers.LeaveGameSession)).Methods("POST")
 
 	// Additional session routes
@@ -22,4 +23,66 @@ func RegisterGameSessionRoutes(api *mux.Router, cfg *Config) {
 	api.HandleFunc("/game/sessions/{id}/players", auth(cfg.Handlers.GetSessionPlayers)).Methods("GET")
 	api.HandleFunc("/game/sessions/{id}/kick/{playerId}",
 		dmOnly(cfg.Handlers.KickPlayer)).Methods("POST")
+
+	// Session chat: history the caller can see, and sending through the chat service
+	api.HandleFunc("/game/sessions/{id}/chat", auth(cfg.Handlers.GetChatHistory)).Methods("GET")
+	api.HandleFunc("/game/sessions/{id}/chat", auth(cfg.Handlers.SendChatMessage)).Methods("POST")
+
+	// Session event log: the timeline, what it adds up to, and the whole log for export
+	api.HandleFunc("/game/sessions/{id}/timeline", auth(cfg.Handlers.GetSessionTimeline)).Methods("GET")
+	api.HandleFunc("/game/sessions/{id}/timeline", dmOnly(cfg.Handlers.RecordSessionEvent)).Methods("POST")
+	api.HandleFunc("/game/sessions/{id}/timeline/summary", auth(cfg.Handlers.GetSessionTimelineSummary)).Methods("GET")
+	api.HandleFunc("/game/sessions/{id}/timeline/export", auth(cfg.Handlers.ExportSessionTimeline)).Methods("GET")
+
+	// Planned sessions: recurring slots, RSVPs, availability polls and calendar feeds
+	api.HandleFunc("/game/sessions/{id}/schedules", auth(cfg.Handlers.ListSessionSchedules)).Methods("GET")
+	api.HandleFunc("/game/sessions/{id}/schedules", dmOnly(cfg.Handlers.CreateSessionSchedule)).Methods("POST")
+	api.HandleFunc("/game/sessions/{id}/upcoming", auth(cfg.Handlers.GetUpcomingSessions)).Methods("GET")
+	api.HandleFunc("/game/sessions/{id}/calendar.ics", auth(cfg.Handlers.GetSessionCalendar)).Methods("GET")
+	api.HandleFunc("/game/sessions/{id}/polls", auth(cfg.Handlers.ListAvailabilityPolls)).Methods("GET")
+	api.HandleFunc("/game/sessions/{id}/polls", dmOnly(cfg.Handlers.CreateAvailabilityPoll)).Methods("POST")
+	api.HandleFunc("/schedules/{id}", dmOnly(cfg.Handlers.UpdateSessionSchedule)).Methods("PUT")
+	api.HandleFunc("/schedules/{id}", dmOnly(cfg.Handlers.DeleteSessionSchedule)).Methods("DELETE")
+	api.HandleFunc("/schedules/{id}/rsvp", auth(cfg.Handlers.RSVPSessionSchedule)).Methods("POST")
+	api.HandleFunc("/polls/{id}", auth(cfg.Handlers.GetAvailabilityPoll)).Methods("GET")
+	api.HandleFunc("/polls/{id}/vote", auth(cfg.Handlers.VoteAvailabilityPoll)).Methods("POST")
+	api.HandleFunc("/polls/{id}/close", dmOnly(cfg.Handlers.CloseAvailabilityPoll)).Methods("POST")
+	api.HandleFunc("/calendar/feed", auth(cfg.Handlers.GetCalendarFeed)).Methods("GET", "POST")
+	api.HandleFunc("/calendar/{token}.ics", cfg.Handlers.ServeCalendarFeed).Methods("GET")
+
+	// Invites to private sessions: emailed invites, shareable links and redeeming them
+	api.HandleFunc("/game/sessions/{id}/invites", dmOnly(cfg.Handlers.ListGameInvites)).Methods("GET")
+	api.HandleFunc("/game/sessions/{id}/invites", dmOnly(cfg.Handlers.CreateGameInvite)).Methods("POST")
+	api.HandleFunc("/invites/{id}", dmOnly(cfg.Handlers.RevokeGameInvite)).Methods("DELETE")
+	api.HandleFunc("/invites/{code}/redeem", auth(cfg.Handlers.RedeemGameInvite)).Methods("POST")
+
+	// Session treasure and economy audit
+	api.HandleFunc("/game/sessions/{id}/loot", dmOnly(cfg.Handlers.AwardSessionLoot)).Methods("POST")
+	api.HandleFunc("/game/sessions/{id}/ledger", dmOnly(cfg.Handlers.GetSessionLedger)).Methods("GET")
+
+	// Shared items and the DM's homebrew items for the session
+	api.HandleFunc("/game/sessions/{id}/items", auth(cfg.Handlers.ListSessionItems)).Methods("GET")
+	api.HandleFunc("/game/sessions/{id}/items", dmOnly(cfg.Handlers.CreateHomebrewItems)).Methods("POST")
+	api.HandleFunc("/game/sessions/{id}/items/{itemId}", dmOnly(cfg.Handlers.UpdateHomebrewItem)).Methods("PUT")
+	api.HandleFunc("/game/sessions/{id}/items/{itemId}", dmOnly(cfg.Handlers.DeleteHomebrewItem)).Methods("DELETE")
+
+	// Loot pools from fights and objectives, divided by the DM or the party
+	api.HandleFunc("/game/sessions/{id}/loot-pools", auth(cfg.Handlers.ListLootPools)).Methods("GET")
+	api.HandleFunc("/game/sessions/{id}/loot-pools", dmOnly(cfg.Handlers.CreateLootPool)).Methods("POST")
+	api.HandleFunc("/game/sessions/{id}/treasure", dmOnly(cfg.Handlers.GenerateTreasureLootPool)).Methods("POST")
+	api.HandleFunc("/loot-pools/{id}", auth(cfg.Handlers.GetLootPool)).Methods("GET")
+	api.HandleFunc("/loot-pools/{id}/assign", dmOnly(cfg.Handlers.AssignLoot)).Methods("POST")
+	api.HandleFunc("/loot-pools/{id}/claim", auth(cfg.Handlers.ClaimLoot)).Methods("POST")
+	api.HandleFunc("/loot-pools/{id}/roll", auth(cfg.Handlers.RollForLoot)).Methods("POST")
+	api.HandleFunc("/loot-pools/{id}/distribute", dmOnly(cfg.Handlers.DistributeLoot)).Methods("POST")
+
+	// Party stash shared by the session's characters
+	api.HandleFunc("/game/sessions/{id}/party", auth(cfg.Handlers.GetPartyStash)).Methods("GET")
+	api.HandleFunc("/game/sessions/{id}/party/deposit", auth(cfg.Handlers.DepositToPartyStash)).Methods("POST")
+	api.HandleFunc("/game/sessions/{id}/party/withdraw", auth(cfg.Handlers.WithdrawFromPartyStash)).Methods("POST")
+
+	// Crafting recipes, including the DM's homebrew for the session
+	api.HandleFunc("/game/sessions/{id}/recipes", auth(cfg.Handlers.ListSessionRecipes)).Methods("GET")
+	api.HandleFunc("/game/sessions/{id}/recipes", dmOnly(cfg.Handlers.CreateHomebrewRecipe)).Methods("POST")
+	api.HandleFunc("/game/sessions/{id}/recipes/{recipeId}", dmOnly(cfg.Handlers.DeleteHomebrewRecipe)).Methods("DELETE")
 }
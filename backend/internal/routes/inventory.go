@@ -34,12 +34,26 @@ func RegisterInventoryRoutes(api *mux.Router, cfg *Config) {
 	api.HandleFunc("/characters/{characterId}/inventory/{itemId}/unequip",
 		auth(inventoryHandler.UnequipItem)).Methods("POST")
 
+	// Containers and storage
+	api.HandleFunc("/characters/{characterId}/inventory/{itemId}/move",
+		auth(inventoryHandler.MoveItem)).Methods("POST")
+
 	// Attunement
 	api.HandleFunc("/characters/{characterId}/inventory/{itemId}/attune",
 		auth(inventoryHandler.AttuneItem)).Methods("POST")
 	api.HandleFunc("/characters/{characterId}/inventory/{itemId}/unattune",
 		auth(inventoryHandler.UnattuneItem)).Methods("POST")
 
+	// Magic item charges and bonuses
+	api.HandleFunc("/characters/{characterId}/inventory/effects",
+		auth(inventoryHandler.GetItemEffects)).Methods("GET")
+	api.HandleFunc("/characters/{characterId}/inventory/{itemId}/use-charges",
+		auth(inventoryHandler.UseItemCharges)).Methods("POST")
+
+	// Consumables: potions, thrown flasks and spell scrolls
+	api.HandleFunc("/characters/{characterId}/inventory/{itemId}/use",
+		auth(inventoryHandler.UseItem)).Methods("POST")
+
 	// Currency management
 	api.HandleFunc("/characters/{characterId}/currency",
 		auth(inventoryHandler.GetCharacterCurrency)).Methods("GET")
@@ -4,8 +4,11 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/ctclostio/DnD-Game/backend/internal/auth"
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/crdt"
 	"github.com/ctclostio/DnD-Game/backend/internal/handlers"
 	"github.com/ctclostio/DnD-Game/backend/internal/middleware"
+	"github.com/ctclostio/DnD-Game/backend/internal/websocket"
 )
 
 // Config holds all dependencies needed for route registration
@@ -17,6 +20,8 @@ type Config struct {
 	CombatAutomationHandler interface{}
 	WorldBuildingHandler    interface{}
 	NarrativeHandler        interface{}
+	WebSocketHandler        *websocket.HandlerV2
+	CRDTHandler             *crdt.Handler
 	AuthMiddleware          *auth.Middleware
 	CSRFStore               *auth.CSRFStore
 	AuthRateLimiter         *middleware.RateLimiter
@@ -44,6 +49,12 @@ func RegisterRoutes(router *mux.Router, cfg *Config) {
 	api.HandleFunc("/health/detailed",
 		cfg.AuthMiddleware.Authenticate(cfg.Handlers.DetailedHealth)).Methods("GET")
 
+	// WebSocket endpoint; clients authenticate over the socket itself
+	if cfg.WebSocketHandler != nil {
+		router.HandleFunc(constants.WebSocketPath, cfg.WebSocketHandler.HandleWebSocket)
+	}
+	api.HandleFunc("/ws/schema", cfg.Handlers.WebSocketSchema).Methods("GET")
+
 	// CSRF token endpoint
 	api.HandleFunc("/csrf-token", cfg.Handlers.GetCSRFToken).Methods("GET")
 
@@ -64,5 +75,5 @@ func RegisterRoutes(router *mux.Router, cfg *Config) {
 	RegisterWorldBuildingRoutes(api, cfg)
 	RegisterRuleBuilderRoutes(api, cfg)
 	RegisterNarrativeRoutes(api, cfg)
-	RegisterCRDTRoutes(router)
+	RegisterCRDTRoutes(router, cfg)
 }
@@ -8,6 +8,7 @@ import (
 const (
 	settlementByIDPath = "/world/settlements/{id}"
 	factionByIDPath = "/world/factions/{id}"
+	shopByIDPath = "/world/shops/{id}"
 )
 
 // RegisterWorldBuildingRoutes registers all world building-related routes
@@ -23,6 +24,13 @@ func RegisterWorldBuildingRoutes(api *mux.Router, cfg *Config) {
 	api.HandleFunc(settlementByIDPath, dmOnly(cfg.Handlers.DeleteSettlement)).Methods("DELETE")
 	api.HandleFunc("/world/settlements/generate", dmOnly(cfg.Handlers.GenerateSettlement)).Methods("POST")
 
+	// Settlement shops
+	api.HandleFunc(shopByIDPath, auth(cfg.Handlers.GetShop)).Methods("GET")
+	api.HandleFunc(shopByIDPath+"/quote", auth(cfg.Handlers.QuoteShopTrade)).Methods("GET")
+	api.HandleFunc(shopByIDPath+"/buy", auth(cfg.Handlers.BuyFromShop)).Methods("POST")
+	api.HandleFunc(shopByIDPath+"/sell", auth(cfg.Handlers.SellToShop)).Methods("POST")
+	api.HandleFunc(shopByIDPath+"/restock", dmOnly(cfg.Handlers.RestockShop)).Methods("POST")
+
 	// Faction management
 	api.HandleFunc("/world/factions", auth(cfg.Handlers.GetFactions)).Methods("GET")
 	api.HandleFunc("/world/factions", dmOnly(cfg.Handlers.CreateFaction)).Methods("POST")
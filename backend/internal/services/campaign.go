@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,6 +18,7 @@ type CampaignService struct {
 	campaignRepo database.CampaignRepository
 	gameRepo     database.GameSessionRepository
 	aiManager    AICampaignManagerInterface
+	events       *GameEventService
 }
 
 func NewCampaignService(
@@ -31,6 +33,12 @@ func NewCampaignService(
 	}
 }
 
+// SetEventLog lets recaps cover what the session event log holds since the last session
+// memory was written
+func (cs *CampaignService) SetEventLog(events *GameEventService) {
+	cs.events = events
+}
+
 // Story Arc Management
 
 func (cs *CampaignService) CreateStoryArc(_ context.Context, sessionID uuid.UUID, req models.CreateStoryArcRequest) (*models.StoryArc, error) {
@@ -175,6 +183,21 @@ func (cs *CampaignService) GenerateRecap(ctx context.Context, sessionID uuid.UUI
 		return nil, fmt.Errorf("failed to get session memories: %w", err)
 	}
 
+	// What the event log holds since the last memory is the session nobody summarized yet
+	if cs.events != nil {
+		filter := models.GameEventFilter{}
+		if len(memories) > 0 {
+			filter.Since = &memories[0].CreatedAt
+		}
+		events, err := cs.events.GetSessionEvents(ctx, sessionID.String(), filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get session events: %w", err)
+		}
+		if latest := memoryFromEvents(sessionID, memories, events); latest != nil {
+			memories = append([]*models.SessionMemory{latest}, memories...)
+		}
+	}
+
 	if len(memories) == 0 {
 		return &models.GeneratedRecap{
 			Summary:   "This is the beginning of your adventure...",
@@ -335,3 +358,108 @@ func (cs *CampaignService) generateRecapFromEvents(req *models.CreateSessionMemo
 
 	return recap
 }
+
+// memoryFromEvents turns the events logged since the last session memory into one for
+// the session in progress, or nil when nothing has happened since
+func memoryFromEvents(sessionID uuid.UUID, memories []*models.SessionMemory, events []*models.GameEvent) *models.SessionMemory {
+	if len(events) == 0 {
+		return nil
+	}
+
+	var keyEvents []models.KeyEvent
+	var npcs, items []string
+	seenNPCs := make(map[string]bool)
+	rolls, combatActions, experience := 0, 0, 0
+	for _, event := range events {
+		switch event.Type {
+		case models.GameEventRoll:
+			rolls++
+		case models.GameEventCombatAction:
+			combatActions++
+		case models.GameEventChat:
+			if event.Data["kind"] == string(models.ChatKindNarration) {
+				keyEvents = append(keyEvents, models.KeyEvent{Time: event.GameTime, Description: eventText(event, "message")})
+			}
+		case models.GameEventNPCDialog:
+			if npc := eventText(event, "npc"); npc != "" && !seenNPCs[npc] {
+				seenNPCs[npc] = true
+				npcs = append(npcs, npc)
+			}
+		case models.GameEventLoot:
+			items = append(items, eventTexts(event, "items")...)
+		case models.GameEventExperience:
+			if xp, ok := event.Data["experience"].(float64); ok {
+				experience += int(xp)
+			} else if xp, ok := event.Data["experience"].(int); ok {
+				experience += xp
+			}
+		}
+	}
+
+	recap := "Since the last session, the party "
+	parts := make([]string, 0, 5)
+	if rolls > 0 {
+		parts = append(parts, fmt.Sprintf("rolled the dice %d times", rolls))
+	}
+	if combatActions > 0 {
+		parts = append(parts, fmt.Sprintf("took %d actions in combat", combatActions))
+	}
+	if len(npcs) > 0 {
+		parts = append(parts, "spoke with "+strings.Join(npcs, ", "))
+	}
+	if len(items) > 0 {
+		parts = append(parts, "found "+strings.Join(items, ", "))
+	}
+	if experience > 0 {
+		parts = append(parts, fmt.Sprintf("earned %d experience", experience))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "continued their adventure")
+	}
+	recap += strings.Join(parts, "; ") + "."
+
+	keyEventsJSON, _ := json.Marshal(keyEvents)
+	npcsJSON, _ := json.Marshal(npcs)
+	itemsJSON, _ := json.Marshal(items)
+	memory := &models.SessionMemory{
+		GameSessionID:    sessionID,
+		SessionNumber:    1,
+		SessionDate:      events[0].Timestamp,
+		RecapSummary:     recap,
+		KeyEvents:        models.JSONB(keyEventsJSON),
+		NPCsEncountered:  models.JSONB(npcsJSON),
+		DecisionsMade:    models.JSONB(`[]`),
+		ItemsAcquired:    models.JSONB(itemsJSON),
+		LocationsVisited: models.JSONB(`[]`),
+		CombatEncounters: models.JSONB(`[]`),
+		PlotDevelopments: models.JSONB(`[]`),
+	}
+	if len(memories) > 0 {
+		memory.SessionNumber = memories[0].SessionNumber + 1
+	}
+	return memory
+}
+
+// eventText reads a string from an event's data
+func eventText(event *models.GameEvent, key string) string {
+	text, _ := event.Data[key].(string)
+	return text
+}
+
+// eventTexts reads a list of strings from an event's data, whether it was recorded in
+// this process or decoded from the log
+func eventTexts(event *models.GameEvent, key string) []string {
+	switch values := event.Data[key].(type) {
+	case []string:
+		return values
+	case []interface{}:
+		texts := make([]string, 0, len(values))
+		for _, value := range values {
+			if text, ok := value.(string); ok {
+				texts = append(texts, text)
+			}
+		}
+		return texts
+	}
+	return nil
+}
@@ -17,6 +17,7 @@ type CharacterService struct {
 	customClassRepo *database.CustomClassRepository
 	classGenerator  *AIClassGenerator
 	llmProvider     LLMProvider
+	versions        *CharacterVersionService
 }
 
 func NewCharacterService(repo database.CharacterRepository, customClassRepo *database.CustomClassRepository, llmProvider LLMProvider) *CharacterService {
@@ -28,6 +29,11 @@ func NewCharacterService(repo database.CharacterRepository, customClassRepo *dat
 	}
 }
 
+// SetVersionService enables recording a version snapshot on every character change
+func (s *CharacterService) SetVersionService(versions *CharacterVersionService) {
+	s.versions = versions
+}
+
 func (s *CharacterService) GetAllCharacters(ctx context.Context, userID string) ([]*models.Character, error) {
 	// If userID is provided, get characters for that user
 	if userID != "" {
@@ -88,10 +94,19 @@ func (s *CharacterService) CreateCharacter(ctx context.Context, char *models.Cha
 		char.ID = uuid.New().String()
 	}
 
-	return s.repo.Create(ctx, char)
+	if err := s.repo.Create(ctx, char); err != nil {
+		return err
+	}
+	s.versions.RecordChange(ctx, char, models.CharacterChangeCreate, "")
+	return nil
 }
 
 func (s *CharacterService) UpdateCharacter(ctx context.Context, char *models.Character) error {
+	return s.updateCharacter(ctx, char, models.CharacterChangeUpdate, "")
+}
+
+// updateCharacter merges and saves a character, recording a version labelled with the change
+func (s *CharacterService) updateCharacter(ctx context.Context, char *models.Character, changeType models.CharacterChangeType, reason string) error {
 	// Validate character ID
 	if char.ID == "" {
 		return fmt.Errorf("character ID is required")
@@ -139,7 +154,11 @@ func (s *CharacterService) UpdateCharacter(ctx context.Context, char *models.Cha
 	}
 	// Update other fields similarly...
 
-	return s.repo.Update(ctx, existing)
+	if err := s.repo.Update(ctx, existing); err != nil {
+		return err
+	}
+	s.versions.RecordChange(ctx, existing, changeType, reason)
+	return nil
 }
 
 func (s *CharacterService) DeleteCharacter(ctx context.Context, id string) error {
@@ -304,7 +323,7 @@ func (s *CharacterService) UseSpellSlot(ctx context.Context, characterID string,
 		if char.Spells.SpellSlots[i].Level == slotLevel {
 			if char.Spells.SpellSlots[i].Remaining > 0 {
 				char.Spells.SpellSlots[i].Remaining--
-				return s.UpdateCharacter(ctx, char)
+				return s.updateCharacter(ctx, char, models.CharacterChangeSpells, fmt.Sprintf("used a level %d spell slot", slotLevel))
 			}
 			return fmt.Errorf("no remaining spell slots of level %d", slotLevel)
 		}
@@ -339,7 +358,7 @@ func (s *CharacterService) RestoreSpellSlots(ctx context.Context, characterID, r
 		return fmt.Errorf("invalid rest type: %s", restType)
 	}
 
-	return s.UpdateCharacter(ctx, char)
+	return s.updateCharacter(ctx, char, models.CharacterChangeRest, restType+" rest")
 }
 
 // Experience and Level Management
@@ -352,17 +371,20 @@ func (s *CharacterService) AddExperience(ctx context.Context, characterID string
 	}
 
 	char.ExperiencePoints += xp
+	if err := s.updateCharacter(ctx, char, models.CharacterChangeExperience, fmt.Sprintf("gained %d XP", xp)); err != nil {
+		return err
+	}
 
-	// Check for level up
+	// Level up once the XP is saved, since LevelUp reloads the character
 	newLevel := s.calculateLevelFromXP(char.ExperiencePoints)
-	if newLevel > char.Level {
+	for level := char.Level; level < newLevel; level++ {
 		// For automatic level up, use class hit die average
 		hpIncrease := s.calculateHPIncrease(char.Class, getModifier(char.Attributes.Constitution))
-		_, err := s.LevelUp(ctx, char.ID, hpIncrease, "")
-		return err
+		if _, err := s.LevelUp(ctx, char.ID, hpIncrease, ""); err != nil {
+			return err
+		}
 	}
-
-	return s.UpdateCharacter(ctx, char)
+	return nil
 }
 
 // calculateLevelFromXP determines character level based on XP
@@ -498,7 +520,7 @@ func (s *CharacterService) LevelUp(ctx context.Context, characterID string, hitP
 	}
 
 	// Update the character
-	if err := s.UpdateCharacter(ctx, char); err != nil {
+	if err := s.updateCharacter(ctx, char, models.CharacterChangeLevelUp, fmt.Sprintf("reached level %d", newLevel)); err != nil {
 		return nil, err
 	}
 
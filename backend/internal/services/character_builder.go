@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 
@@ -131,6 +132,87 @@ func (cb *CharacterBuilder) BuildCharacter(params map[string]interface{}) (*mode
 	return character, nil
 }
 
+// ValidateCharacter checks a complete character sheet, such as an imported one, against the
+// builder's rules and game data. It returns one message per problem found.
+func (cb *CharacterBuilder) ValidateCharacter(character *models.Character) []string {
+	var problems []string
+
+	if strings.TrimSpace(character.Name) == "" {
+		problems = append(problems, "character name is required")
+	}
+	if character.Level < 1 || character.Level > 20 {
+		problems = append(problems, "level must be between 1 and 20")
+	}
+	attrs := character.Attributes
+	for _, score := range []struct {
+		ability string
+		value   int
+	}{
+		{"strength", attrs.Strength}, {"dexterity", attrs.Dexterity}, {"constitution", attrs.Constitution},
+		{"intelligence", attrs.Intelligence}, {"wisdom", attrs.Wisdom}, {"charisma", attrs.Charisma},
+	} {
+		if score.value < 1 || score.value > 30 {
+			problems = append(problems, fmt.Sprintf("%s must be between 1 and 30", score.ability))
+		}
+	}
+	if character.MaxHitPoints < 1 {
+		problems = append(problems, "max hit points must be at least 1")
+	}
+	if character.HitPoints < 0 || character.HitPoints > character.MaxHitPoints {
+		problems = append(problems, "hit points must be between 0 and max hit points")
+	}
+
+	problems = append(problems, cb.validateRace(character)...)
+	if character.CustomClassID == nil {
+		if character.Class == "" {
+			problems = append(problems, "character class is required")
+		} else if classes, err := cb.loadClasses(); err != nil || !slices.Contains(classes, dataFileName(character.Class)) {
+			problems = append(problems, fmt.Sprintf("unknown class %q", character.Class))
+		}
+	}
+	if character.Background != "" {
+		if _, err := cb.loadBackgroundData(dataFileName(character.Background)); err != nil {
+			problems = append(problems, fmt.Sprintf("unknown background %q", character.Background))
+		}
+	}
+
+	for _, spell := range character.Spells.SpellsKnown {
+		if spell.Name == "" || spell.Level < 0 || spell.Level > 9 {
+			problems = append(problems, fmt.Sprintf("invalid spell %q at level %d", spell.Name, spell.Level))
+		}
+	}
+	for _, slot := range character.Spells.SpellSlots {
+		if slot.Level < 1 || slot.Level > 9 || slot.Remaining < 0 || slot.Remaining > slot.Total {
+			problems = append(problems, fmt.Sprintf("invalid level %d spell slots", slot.Level))
+		}
+	}
+
+	return problems
+}
+
+func (cb *CharacterBuilder) validateRace(character *models.Character) []string {
+	if character.CustomRaceID != nil {
+		return nil
+	}
+	if character.Race == "" {
+		return []string{"character race is required"}
+	}
+
+	raceData, err := cb.loadRaceData(dataFileName(character.Race))
+	if err != nil {
+		return []string{fmt.Sprintf("unknown race %q", character.Race)}
+	}
+	if character.Subrace == "" {
+		return nil
+	}
+	for _, subrace := range raceData.Subraces {
+		if strings.EqualFold(subrace.Name, character.Subrace) {
+			return nil
+		}
+	}
+	return []string{fmt.Sprintf("unknown %s subrace %q", raceData.Name, character.Subrace)}
+}
+
 type buildParameters struct {
 	race            string
 	customRaceID    string
@@ -0,0 +1,352 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/database"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+// CharacterExportService converts characters to and from the versioned interchange
+// document and renders printable sheets. It implements ExportServiceInterface.
+type CharacterExportService struct {
+	dataPath      string
+	builder       *CharacterBuilder
+	catalog       *ItemCatalog
+	characterRepo database.CharacterRepository
+	inventoryRepo database.InventoryRepository
+	versions      *CharacterVersionService
+	events        *GameEventService
+}
+
+// NewCharacterExportService creates a new character export service
+func NewCharacterExportService(dataPath string, catalog *ItemCatalog, characterRepo database.CharacterRepository, inventoryRepo database.InventoryRepository) *CharacterExportService {
+	return &CharacterExportService{
+		dataPath:      dataPath,
+		builder:       NewCharacterBuilder(dataPath),
+		catalog:       catalog,
+		characterRepo: characterRepo,
+		inventoryRepo: inventoryRepo,
+	}
+}
+
+// SetVersionService records imported characters in the version history
+func (s *CharacterExportService) SetVersionService(versions *CharacterVersionService) {
+	s.versions = versions
+}
+
+// SetEventLog sets the session event log exported characters' history is read from
+func (s *CharacterExportService) SetEventLog(events *GameEventService) {
+	s.events = events
+}
+
+// Schema returns the JSON Schema describing the current interchange document
+func (s *CharacterExportService) Schema() (json.RawMessage, error) {
+	name := fmt.Sprintf("character-v%d.schema.json", models.CharacterExportSchemaVersion)
+	data, err := os.ReadFile(filepath.Join(s.dataPath, "schemas", name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load character schema: %w", err)
+	}
+	return data, nil
+}
+
+// ExportCharacter builds the interchange document for a single character
+func (s *CharacterExportService) ExportCharacter(ctx context.Context, characterID string) (*models.CharacterExportDocument, error) {
+	char, err := s.characterRepo.GetByID(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+	return s.exportDocument(ctx, char)
+}
+
+// RenderCharacterSheet renders a single character as a print-ready PDF
+func (s *CharacterExportService) RenderCharacterSheet(ctx context.Context, characterID string) ([]byte, error) {
+	doc, err := s.ExportCharacter(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+	return RenderCharacterSheetPDF([]*models.CharacterExportDocument{doc}), nil
+}
+
+// ExportCharacters exports the user's characters as interchange documents ("json") or as
+// a single PDF with one sheet per character ("pdf"). No IDs exports every character.
+func (s *CharacterExportService) ExportCharacters(ctx context.Context, userID string, characterIDs []string, format string) (interface{}, error) {
+	exportFormat := models.CharacterExportFormat(strings.ToLower(format))
+	if exportFormat == "" {
+		exportFormat = models.CharacterExportJSON
+	}
+	if exportFormat != models.CharacterExportJSON && exportFormat != models.CharacterExportPDF {
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+
+	characters, err := s.userCharacters(ctx, userID, characterIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]*models.CharacterExportDocument, 0, len(characters))
+	for _, char := range characters {
+		doc, err := s.exportDocument(ctx, char)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+
+	if exportFormat == models.CharacterExportPDF {
+		return RenderCharacterSheetPDF(docs), nil
+	}
+	return docs, nil
+}
+
+// ExportCampaigns is not supported; campaigns have no interchange format yet
+func (s *CharacterExportService) ExportCampaigns(ctx context.Context, userID string, campaignIDs []string, format string) (interface{}, error) {
+	return nil, fmt.Errorf("campaign export is not supported")
+}
+
+// ExportUserData exports every character the user owns as interchange documents
+func (s *CharacterExportService) ExportUserData(ctx context.Context, userID string) (interface{}, error) {
+	return s.ExportCharacters(ctx, userID, nil, string(models.CharacterExportJSON))
+}
+
+// ImportCharacter validates an interchange document through the character builder and
+// creates the character, its inventory and purse for the importing user
+func (s *CharacterExportService) ImportCharacter(ctx context.Context, userID string, doc *models.CharacterExportDocument) (*models.CharacterImportResult, error) {
+	if doc.SchemaVersion != models.CharacterExportSchemaVersion {
+		return nil, fmt.Errorf("unsupported schema version %d, expected %d", doc.SchemaVersion, models.CharacterExportSchemaVersion)
+	}
+	if doc.Character == nil {
+		return nil, fmt.Errorf("document has no character")
+	}
+	if doc.Character.CustomRaceID != nil || doc.Character.CustomClassID != nil {
+		return nil, fmt.Errorf("characters with custom races or classes cannot be imported")
+	}
+
+	char := *doc.Character
+	if problems := s.builder.ValidateCharacter(&char); len(problems) > 0 {
+		return nil, fmt.Errorf("invalid character: %s", strings.Join(problems, "; "))
+	}
+	if err := validateImportedInventory(doc); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	char.ID = uuid.New().String()
+	char.UserID = userID
+	char.CarryCapacity = CalculateCarryCapacity(char.Attributes.Strength)
+	char.CurrentWeight = 0
+	char.AttunementSlotsUsed = 0
+	if char.AttunementSlotsMax == 0 {
+		char.AttunementSlotsMax = 3
+	}
+	char.CreatedAt = now
+	char.UpdatedAt = now
+
+	// Every item is resolved before anything is written, and the character, its
+	// inventory and purse are then created in one transaction
+	result := &models.CharacterImportResult{Character: &char}
+	txn := &models.EconomyTransaction{
+		Type:         models.LedgerEntryAdjustment,
+		Description:  fmt.Sprintf("imported from schema version %d", doc.SchemaVersion),
+		CreatedBy:    userID,
+		NewCharacter: &char,
+	}
+	imported := make([]importedItem, 0, len(doc.Inventory))
+	for _, entry := range doc.Inventory {
+		item, creating, err := s.resolveImportedItem(entry.Item)
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("%s is not a known item and was not imported; ask your DM to add it as homebrew", entry.Item.Name))
+			continue
+		}
+		txn.Items = append(txn.Items, models.ItemMovement{CharacterID: char.ID, ItemID: item.ID, Quantity: entry.Quantity})
+		imported = append(imported, importedItem{item: item, entry: entry, creating: creating})
+	}
+	if coins := models.Coins(doc.Currency); !coins.IsZero() {
+		txn.Currency = []models.CurrencyMovement{{CharacterID: char.ID, Coins: coins}}
+	}
+
+	for _, item := range imported {
+		if !item.creating {
+			continue
+		}
+		if err := s.inventoryRepo.CreateItem(item.item); err != nil {
+			return nil, fmt.Errorf("failed to create item %s: %w", item.item.Name, err)
+		}
+	}
+	if _, err := s.inventoryRepo.ApplyTransaction(txn); err != nil {
+		return nil, fmt.Errorf("failed to import character: %w", err)
+	}
+	result.ItemsImported = len(imported)
+
+	// Equipping and attuning depend on the live inventory rules, so they are applied
+	// to the committed character and only warn when an item cannot be worn
+	for _, item := range imported {
+		if warning := s.wearImportedItem(char.ID, item); warning != "" {
+			result.Warnings = append(result.Warnings, warning)
+		}
+	}
+
+	s.versions.RecordChange(ctx, &char, models.CharacterChangeCreate, txn.Description)
+	return result, nil
+}
+
+// importedItem pairs an exported inventory entry with the item it resolved to
+type importedItem struct {
+	item  *models.Item
+	entry models.ExportedInventoryItem
+	// creating is set for SRD catalog items not yet in the item table
+	creating bool
+}
+
+func (s *CharacterExportService) userCharacters(ctx context.Context, userID string, characterIDs []string) ([]*models.Character, error) {
+	if len(characterIDs) == 0 {
+		return s.characterRepo.GetByUserID(ctx, userID)
+	}
+
+	characters := make([]*models.Character, 0, len(characterIDs))
+	for _, id := range characterIDs {
+		char, err := s.characterRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if char.UserID != userID {
+			return nil, fmt.Errorf("character %s does not belong to user", id)
+		}
+		characters = append(characters, char)
+	}
+	return characters, nil
+}
+
+func (s *CharacterExportService) exportDocument(ctx context.Context, char *models.Character) (*models.CharacterExportDocument, error) {
+	inventory, err := s.inventoryRepo.GetCharacterInventory(char.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load inventory: %w", err)
+	}
+	currency, err := s.inventoryRepo.GetCharacterCurrency(char.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load currency: %w", err)
+	}
+
+	sheet := *char
+	// Ownership does not travel with the character
+	sheet.UserID = ""
+
+	doc := &models.CharacterExportDocument{
+		Schema:        models.CharacterExportSchemaID,
+		SchemaVersion: models.CharacterExportSchemaVersion,
+		ExportedAt:    time.Now(),
+		Character:     &sheet,
+		Inventory:     make([]models.ExportedInventoryItem, 0, len(inventory)),
+	}
+	for _, inv := range inventory {
+		item := inv.Item
+		if item == nil {
+			if item, err = s.inventoryRepo.GetItem(inv.ItemID); err != nil || item == nil {
+				continue
+			}
+		}
+		doc.Inventory = append(doc.Inventory, models.ExportedInventoryItem{
+			Quantity:         inv.Quantity,
+			Equipped:         inv.Equipped,
+			Attuned:          inv.Attuned,
+			CustomProperties: inv.CustomProperties,
+			Notes:            inv.Notes,
+			Item:             item,
+		})
+	}
+	if currency != nil {
+		doc.Currency = models.ExportedCurrency{
+			Copper:   currency.Copper,
+			Silver:   currency.Silver,
+			Electrum: currency.Electrum,
+			Gold:     currency.Gold,
+			Platinum: currency.Platinum,
+		}
+	}
+	if s.events != nil {
+		history, err := s.events.GetCharacterEvents(ctx, char.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load history: %w", err)
+		}
+		// The document leaves the table, so it only carries what the players saw
+		doc.History = VisibleEvents(history, models.ParticipantRolePlayer)
+	}
+	return doc, nil
+}
+
+func validateImportedInventory(doc *models.CharacterExportDocument) error {
+	for i, entry := range doc.Inventory {
+		if entry.Item == nil || strings.TrimSpace(entry.Item.Name) == "" {
+			return fmt.Errorf("inventory entry %d has no item", i)
+		}
+		if entry.Quantity < 1 {
+			return fmt.Errorf("inventory entry %s must have a positive quantity", entry.Item.Name)
+		}
+	}
+	c := doc.Currency
+	if c.Copper < 0 || c.Silver < 0 || c.Electrum < 0 || c.Gold < 0 || c.Platinum < 0 {
+		return fmt.Errorf("currency cannot be negative")
+	}
+	return nil
+}
+
+// wearImportedItem restores an imported item's equipped and attuned state, returning a
+// warning when it cannot be
+func (s *CharacterExportService) wearImportedItem(characterID string, imported importedItem) string {
+	item, entry := imported.item, imported.entry
+	if entry.Equipped {
+		if err := s.inventoryRepo.EquipItem(characterID, item.ID, true); err != nil {
+			return fmt.Sprintf("could not equip %s: %v", item.Name, err)
+		}
+	}
+	if entry.Attuned {
+		if err := s.inventoryRepo.AttuneItem(characterID, item.ID); err != nil {
+			return fmt.Sprintf("could not attune to %s: %v", item.Name, err)
+		}
+	}
+	return ""
+}
+
+// resolveImportedItem matches an exported item to a shared item by ID, then to the SRD
+// catalog by name, reporting whether a catalog item still has to be added to the item table.
+// Items that are neither are not trusted from the file and resolve to nil; homebrew
+// belongs to a game session and is only created by its DM.
+func (s *CharacterExportService) resolveImportedItem(exported *models.Item) (*models.Item, bool, error) {
+	if exported.ID != "" {
+		item, err := s.inventoryRepo.GetItem(exported.ID)
+		if err != nil {
+			return nil, false, err
+		}
+		if item != nil && item.SessionID == nil && strings.EqualFold(item.Name, exported.Name) {
+			return item, false, nil
+		}
+	}
+
+	if s.catalog == nil {
+		return nil, false, nil
+	}
+	catalogItem := s.catalog.Find(exported.Name)
+	if catalogItem == nil {
+		return nil, false, nil
+	}
+	item, err := s.inventoryRepo.GetItem(catalogItem.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	if item != nil {
+		return item, false, nil
+	}
+	return catalogItem.ToItem(), true, nil
+}
@@ -0,0 +1,290 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/internal/services"
+	"github.com/ctclostio/DnD-Game/backend/internal/services/mocks"
+)
+
+const testExportCharacterID = "char-export-1"
+
+var _ services.ExportServiceInterface = (*services.CharacterExportService)(nil)
+
+func createTestCharacterExportService(catalog *services.ItemCatalog, characterRepo *mocks.MockCharacterRepository, inventoryRepo *mocks.MockInventoryRepository) *services.CharacterExportService {
+	return services.NewCharacterExportService(testGameDataPath, catalog, characterRepo, inventoryRepo)
+}
+
+// setupExportCharacter stores exportTestCharacter with a quarterstaff and some coin
+func setupExportCharacter(characterRepo *mocks.MockCharacterRepository, inventoryRepo *mocks.MockInventoryRepository) {
+	characterRepo.On(testMethodGetByID, mock.Anything, testExportCharacterID).Return(exportTestCharacter(), nil)
+	inventoryRepo.On(testMethodGetCharacterInventory, testExportCharacterID).Return([]*models.InventoryItem{
+		{ItemID: "quarterstaff", Quantity: 1, Equipped: true, Item: &models.Item{ID: "quarterstaff", Name: "Quarterstaff", Weight: 4}},
+	}, nil)
+	inventoryRepo.On(testMethodGetCharacterCurrency, testExportCharacterID).Return(&models.Currency{Gold: 12, Silver: 3}, nil)
+}
+
+func exportTestCharacter() *models.Character {
+	return &models.Character{
+		ID: testExportCharacterID, UserID: "player-1", Name: "Elara (the Bold)",
+		Race: "elf", Subrace: "High Elf", Class: "wizard", Background: "sage",
+		Level: 3, HitPoints: 14, MaxHitPoints: 17, ArmorClass: 12,
+		Attributes: models.Attributes{Strength: 8, Dexterity: 14, Constitution: 13, Intelligence: 17, Wisdom: 12, Charisma: 10},
+		Spells: models.SpellData{
+			SpellcastingAbility: "intelligence",
+			SpellSlots:          []models.SpellSlot{{Level: 1, Total: 4, Remaining: 2}, {Level: 2, Total: 2, Remaining: 2}},
+			SpellsKnown: []models.Spell{
+				{Name: "Fire Bolt", Level: 0},
+				{Name: "Magic Missile", Level: 1, Prepared: true},
+				{Name: "Detect Magic", Level: 1, Ritual: true},
+			},
+		},
+	}
+}
+
+func TestCharacterExportService_ExportCharacters(t *testing.T) {
+	catalog, err := services.NewItemCatalog(testGameDataPath)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		userID      string
+		format      string
+		expectError bool
+		validate    func(*testing.T, interface{})
+	}{
+		{
+			name:   "JSON document",
+			userID: "player-1",
+			format: "json",
+			validate: func(t *testing.T, result interface{}) {
+				docs, ok := result.([]*models.CharacterExportDocument)
+				require.True(t, ok)
+				require.Len(t, docs, 1)
+				doc := docs[0]
+				assert.Equal(t, models.CharacterExportSchemaVersion, doc.SchemaVersion)
+				assert.Empty(t, doc.Character.UserID)
+				assert.Len(t, doc.Character.Spells.SpellsKnown, 3)
+				require.Len(t, doc.Inventory, 1)
+				assert.Equal(t, "Quarterstaff", doc.Inventory[0].Item.Name)
+				assert.True(t, doc.Inventory[0].Equipped)
+				assert.Equal(t, 12, doc.Currency.Gold)
+			},
+		},
+		{
+			name:   "PDF sheet",
+			userID: "player-1",
+			format: "pdf",
+			validate: func(t *testing.T, result interface{}) {
+				pdf, ok := result.([]byte)
+				require.True(t, ok)
+				assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4")))
+				assert.True(t, bytes.HasSuffix(pdf, []byte("%%EOF\n")))
+				assert.Contains(t, string(pdf), `Elara \(the Bold\)`)
+				assert.Contains(t, string(pdf), "Magic Missile")
+			},
+		},
+		{
+			name:        "Other users' characters",
+			userID:      "player-2",
+			format:      "json",
+			expectError: true,
+		},
+		{
+			name:        "Unknown format",
+			userID:      "player-1",
+			format:      "xml",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			characterRepo := new(mocks.MockCharacterRepository)
+			inventoryRepo := new(mocks.MockInventoryRepository)
+			setupExportCharacter(characterRepo, inventoryRepo)
+
+			svc := createTestCharacterExportService(catalog, characterRepo, inventoryRepo)
+			result, err := svc.ExportCharacters(context.Background(), tt.userID, []string{testExportCharacterID}, tt.format)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.validate != nil {
+				tt.validate(t, result)
+			}
+		})
+	}
+}
+
+func TestCharacterExportService_ExportCharacter(t *testing.T) {
+	catalog, err := services.NewItemCatalog(testGameDataPath)
+	require.NoError(t, err)
+	characterRepo := new(mocks.MockCharacterRepository)
+	inventoryRepo := new(mocks.MockInventoryRepository)
+	eventRepo := new(mocks.MockGameEventRepository)
+	setupExportCharacter(characterRepo, inventoryRepo)
+	eventRepo.On("List", mock.Anything, models.GameEventFilter{CharacterID: testExportCharacterID}).Return([]*models.GameEvent{
+		{ID: "event-1", Type: models.GameEventLoot, Data: map[string]interface{}{"items": []string{"Wand"}}},
+		{ID: "event-2", Type: "note", Data: map[string]interface{}{models.GameEventDataDMOnly: true}},
+	}, nil)
+
+	svc := createTestCharacterExportService(catalog, characterRepo, inventoryRepo)
+	svc.SetEventLog(services.NewGameEventService(eventRepo))
+	doc, err := svc.ExportCharacter(context.Background(), testExportCharacterID)
+
+	require.NoError(t, err)
+	require.Len(t, doc.History, 1, "DM-only events stay out of the document")
+	assert.Equal(t, "event-1", doc.History[0].ID)
+	eventRepo.AssertExpectations(t)
+}
+
+func TestCharacterExportService_ImportCharacter(t *testing.T) {
+	catalog, err := services.NewItemCatalog(testGameDataPath)
+	require.NoError(t, err)
+	doc := func() *models.CharacterExportDocument {
+		return &models.CharacterExportDocument{
+			SchemaVersion: models.CharacterExportSchemaVersion,
+			Character:     exportTestCharacter(),
+			Inventory: []models.ExportedInventoryItem{
+				{Quantity: 1, Equipped: true, Item: &models.Item{Name: "Longsword", Type: models.ItemTypeWeapon}},
+				{Quantity: 2, Item: &models.Item{ID: "other-server-id", Name: "Moonpetal Tea", Type: models.ItemTypeConsumable}},
+			},
+			Currency: models.ExportedCurrency{Gold: 25},
+		}
+	}
+	homebrew := doc()
+	homebrew.Inventory = []models.ExportedInventoryItem{
+		{Quantity: 1, Item: &models.Item{ID: "homebrew-1", Name: "Blade of Ages", Type: models.ItemTypeWeapon}},
+	}
+	invalid := doc()
+	invalid.Character.Class = "spellsword"
+	invalid.Character.Attributes.Strength = 0
+	future := doc()
+	future.SchemaVersion = 2
+
+	tests := []struct {
+		name        string
+		document    *models.CharacterExportDocument
+		setupMocks  func(*mocks.MockInventoryRepository)
+		expectError []string
+		validate    func(*testing.T, *models.CharacterImportResult)
+	}{
+		{
+			name:     "Creates character, items and currency in one transaction",
+			document: doc(),
+			setupMocks: func(inventoryRepo *mocks.MockInventoryRepository) {
+				inventoryRepo.On(testMethodGetItem, "longsword").Return(nil, nil)
+				inventoryRepo.On("CreateItem", mock.MatchedBy(func(i *models.Item) bool { return i.ID == "longsword" })).Return(nil)
+				inventoryRepo.On(testMethodGetItem, "other-server-id").Return(nil, nil)
+				inventoryRepo.On(testMethodApplyTransaction, mock.MatchedBy(func(txn *models.EconomyTransaction) bool {
+					c := txn.NewCharacter
+					return c != nil && c.UserID == "player-2" && c.ID != testExportCharacterID &&
+						c.HitPoints == 14 && len(c.Spells.SpellsKnown) == 3 &&
+						len(txn.Items) == 1 && txn.Items[0].ItemID == "longsword" && txn.Items[0].CharacterID == c.ID &&
+						len(txn.Currency) == 1 && txn.Currency[0].Coins == models.Coins{Gold: 25}
+				})).Return([]*models.LedgerEntry{}, nil).Once()
+				inventoryRepo.On("EquipItem", mock.Anything, "longsword", true).Return(nil)
+			},
+			validate: func(t *testing.T, result *models.CharacterImportResult) {
+				assert.Equal(t, 1, result.ItemsImported)
+				assert.Equal(t, "player-2", result.Character.UserID)
+				require.Len(t, result.Warnings, 1)
+				assert.Contains(t, result.Warnings[0], "Moonpetal Tea is not a known item")
+			},
+		},
+		{
+			name:     "Another session's homebrew does not match",
+			document: homebrew,
+			setupMocks: func(inventoryRepo *mocks.MockInventoryRepository) {
+				sessionID := "session-9"
+				inventoryRepo.On(testMethodGetItem, "homebrew-1").Return(&models.Item{ID: "homebrew-1", Name: "Blade of Ages", SessionID: &sessionID}, nil)
+				inventoryRepo.On(testMethodApplyTransaction, mock.MatchedBy(func(txn *models.EconomyTransaction) bool {
+					return txn.NewCharacter != nil && len(txn.Items) == 0
+				})).Return([]*models.LedgerEntry{}, nil).Once()
+			},
+			validate: func(t *testing.T, result *models.CharacterImportResult) {
+				assert.Equal(t, 0, result.ItemsImported)
+				assert.Len(t, result.Warnings, 1)
+			},
+		},
+		{
+			name:     "Nothing is written when the transaction fails",
+			document: doc(),
+			setupMocks: func(inventoryRepo *mocks.MockInventoryRepository) {
+				inventoryRepo.On(testMethodGetItem, mock.Anything).Return(&models.Item{ID: "longsword", Name: "Longsword"}, nil)
+				inventoryRepo.On(testMethodApplyTransaction, mock.Anything).Return(nil, errors.New("insufficient funds"))
+			},
+			expectError: []string{"insufficient funds"},
+		},
+		{
+			name:        "Documents the builder does not accept",
+			document:    invalid,
+			expectError: []string{`unknown class "spellsword"`, "strength must be between 1 and 30"},
+		},
+		{
+			name:        "Newer schema version",
+			document:    future,
+			expectError: []string{"schema"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			characterRepo := new(mocks.MockCharacterRepository)
+			inventoryRepo := new(mocks.MockInventoryRepository)
+			if tt.setupMocks != nil {
+				tt.setupMocks(inventoryRepo)
+			}
+
+			svc := createTestCharacterExportService(catalog, characterRepo, inventoryRepo)
+			result, err := svc.ImportCharacter(context.Background(), "player-2", tt.document)
+
+			if len(tt.expectError) > 0 {
+				require.Error(t, err)
+				for _, message := range tt.expectError {
+					assert.Contains(t, err.Error(), message)
+				}
+			} else {
+				require.NoError(t, err)
+				if tt.validate != nil {
+					tt.validate(t, result)
+				}
+			}
+
+			characterRepo.AssertExpectations(t)
+			inventoryRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCharacterBuilder_ValidateCharacter(t *testing.T) {
+	builder := services.NewCharacterBuilder(testGameDataPath)
+
+	assert.Empty(t, builder.ValidateCharacter(exportTestCharacter()))
+
+	char := exportTestCharacter()
+	char.Race = "Half-Elf"
+	char.Subrace = ""
+	char.Background = "Folk Hero"
+	assert.Empty(t, builder.ValidateCharacter(char))
+
+	char.Subrace = "Wood Dwarf"
+	char.HitPoints = 40
+	char.Spells.SpellSlots[0].Remaining = 5
+	assert.ElementsMatch(t, []string{
+		"hit points must be between 0 and max hit points",
+		`unknown Half-Elf subrace "Wood Dwarf"`,
+		"invalid level 1 spell slots",
+	}, builder.ValidateCharacter(char))
+}
@@ -0,0 +1,588 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/database"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/dice"
+)
+
+// unlimitedResourceUses stands in for features that become unlimited, such as a level 20 barbarian's rage
+const unlimitedResourceUses = 99
+
+var hitDiceNotationPattern = regexp.MustCompile(`d(\d+)$`)
+
+// classHitDie is the hit die size for each class
+var classHitDie = map[string]int{
+	constants.ClassBarbarian: 12,
+	constants.ClassFighter:   10,
+	constants.ClassPaladin:   10,
+	constants.ClassRanger:    10,
+	constants.ClassBard:      8,
+	constants.ClassCleric:    8,
+	constants.ClassDruid:     8,
+	constants.ClassMonk:      8,
+	constants.ClassRogue:     8,
+	constants.ClassWarlock:   8,
+	constants.ClassSorcerer:  6,
+	constants.ClassWizard:    6,
+}
+
+// CharacterResourceService tracks class resources, hit dice and exhaustion, and applies rests
+type CharacterResourceService struct {
+	resourceRepo  database.CharacterResourceRepository
+	characterRepo database.CharacterRepository
+	versions      *CharacterVersionService
+	inventory     *InventoryService
+	roller        *dice.Roller
+}
+
+// NewCharacterResourceService creates a new character resource service
+func NewCharacterResourceService(resourceRepo database.CharacterResourceRepository, characterRepo database.CharacterRepository) *CharacterResourceService {
+	return &CharacterResourceService{
+		resourceRepo:  resourceRepo,
+		characterRepo: characterRepo,
+		roller:        dice.NewRoller(),
+	}
+}
+
+// SetVersionService enables recording a version snapshot after each rest
+func (s *CharacterResourceService) SetVersionService(versions *CharacterVersionService) {
+	s.versions = versions
+}
+
+// SetInventoryService enables recharging magic items on rests and at dawn
+func (s *CharacterResourceService) SetInventoryService(inventory *InventoryService) {
+	s.inventory = inventory
+}
+
+// GetResources returns the character's resource ledger, refreshing maximums from class and level
+func (s *CharacterResourceService) GetResources(ctx context.Context, characterID string) ([]*models.CharacterResource, error) {
+	char, err := s.characterRepo.GetByID(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+	return s.syncResources(ctx, char)
+}
+
+// UseResource spends uses of a resource, failing if not enough remain
+func (s *CharacterResourceService) UseResource(ctx context.Context, characterID, key string, amount int) (*models.CharacterResource, error) {
+	if amount <= 0 {
+		amount = 1
+	}
+
+	resource, err := s.findResource(ctx, characterID, key)
+	if err != nil {
+		return nil, err
+	}
+	// The check and the spend happen in one statement so a combat action and a
+	// request spending the same uses at once cannot both succeed
+	spent, err := s.resourceRepo.SpendResource(ctx, characterID, resource.Key, amount)
+	if err != nil {
+		return nil, err
+	}
+	if !spent {
+		if current, err := s.resourceRepo.GetResource(ctx, characterID, resource.Key); err == nil && current != nil {
+			resource = current
+		}
+		return nil, fmt.Errorf("not enough %s remaining: %d of %d", resource.Name, resource.Current, amount)
+	}
+	return s.reloadResource(ctx, resource)
+}
+
+// RestoreResource regains uses of a resource. A non-positive amount restores it fully.
+func (s *CharacterResourceService) RestoreResource(ctx context.Context, characterID, key string, amount int) (*models.CharacterResource, error) {
+	resource, err := s.findResource(ctx, characterID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.resourceRepo.RegainResource(ctx, characterID, resource.Key, amount); err != nil {
+		return nil, err
+	}
+	return s.reloadResource(ctx, resource)
+}
+
+// SetCustomResource adds or updates a DM-defined resource that is not derived from class features
+func (s *CharacterResourceService) SetCustomResource(ctx context.Context, characterID string, resource *models.CharacterResource) (*models.CharacterResource, error) {
+	if resource.Key == "" || resource.Name == "" {
+		return nil, fmt.Errorf("resource key and name are required")
+	}
+	if resource.Max < 0 || resource.Current < 0 || resource.Current > resource.Max {
+		return nil, fmt.Errorf("invalid resource uses: %d of %d", resource.Current, resource.Max)
+	}
+	switch resource.Recovery {
+	case models.RecoveryShortRest, models.RecoveryLongRest, models.RecoveryDawn, models.RecoveryCustom:
+	default:
+		return nil, fmt.Errorf("invalid recovery rule: %s", resource.Recovery)
+	}
+
+	existing, err := s.resourceRepo.GetResource(ctx, characterID, resource.Key)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && !existing.Custom {
+		return nil, fmt.Errorf("%s is a class resource and cannot be overridden", existing.Name)
+	}
+	if existing != nil {
+		resource.ID = existing.ID
+		resource.CreatedAt = existing.CreatedAt
+	}
+
+	resource.CharacterID = characterID
+	resource.Custom = true
+	if err := s.resourceRepo.UpsertResource(ctx, resource); err != nil {
+		return nil, err
+	}
+	return resource, nil
+}
+
+// RecoverAtDawn refills resources that recharge at dawn
+func (s *CharacterResourceService) RecoverAtDawn(ctx context.Context, characterID string) ([]*models.CharacterResource, error) {
+	resources, err := s.GetResources(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+	for _, resource := range resources {
+		if resource.Recovery == models.RecoveryDawn && resource.Current < resource.Max {
+			resource.Current = resource.Max
+			if err := s.resourceRepo.UpsertResource(ctx, resource); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return resources, nil
+}
+
+// Dawn refills resources and recharges magic items that renew at dawn
+func (s *CharacterResourceService) Dawn(ctx context.Context, characterID string) (*models.RestResult, error) {
+	char, err := s.characterRepo.GetByID(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := s.syncResources(ctx, char)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.RestResult{
+		RestType:          models.RestTypeDawn,
+		ResourcesRestored: make(map[string]int),
+	}
+	if err := s.restoreOnRest(ctx, resources, result, models.RecoveryDawn); err != nil {
+		return nil, err
+	}
+	if err := s.rechargeItems(char.ID, result, models.RecoveryDawn); err != nil {
+		return nil, err
+	}
+	if len(result.ResourcesRestored) > 0 || len(result.ItemsRecharged) > 0 {
+		s.versions.RecordChange(ctx, char, models.CharacterChangeRest, "dawn")
+	}
+
+	state, err := s.GetRestState(ctx, char.ID)
+	if err != nil {
+		return nil, err
+	}
+	result.ExhaustionLevel = state.ExhaustionLevel
+	result.Character = char
+	result.Resources = resources
+	return result, nil
+}
+
+// GetRestState returns exhaustion and rest timestamps for a character
+func (s *CharacterResourceService) GetRestState(ctx context.Context, characterID string) (*models.CharacterRestState, error) {
+	state, err := s.resourceRepo.GetRestState(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		state = &models.CharacterRestState{CharacterID: characterID}
+	}
+	return state, nil
+}
+
+// SetExhaustion sets the character's exhaustion level (0-6)
+func (s *CharacterResourceService) SetExhaustion(ctx context.Context, characterID string, level int) (*models.CharacterRestState, error) {
+	if level < 0 || level > models.MaxExhaustionLevel {
+		return nil, fmt.Errorf("exhaustion level must be between 0 and %d", models.MaxExhaustionLevel)
+	}
+
+	state, err := s.GetRestState(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+	state.ExhaustionLevel = level
+	if err := s.resourceRepo.UpsertRestState(ctx, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// ShortRest spends up to hitDice hit dice, adding the Constitution modifier to each
+// roll, and refills short rest resources and warlock pact slots.
+func (s *CharacterResourceService) ShortRest(ctx context.Context, characterID string, hitDice int) (*models.RestResult, error) {
+	if hitDice < 0 {
+		return nil, fmt.Errorf("hit dice to spend cannot be negative")
+	}
+
+	char, err := s.characterRepo.GetByID(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := s.syncResources(ctx, char)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.RestResult{
+		RestType:          models.RestTypeShort,
+		ResourcesRestored: make(map[string]int),
+	}
+
+	if hitDice > 0 {
+		pool := findResourceByKey(resources, models.ResourceHitDice)
+		if pool == nil || pool.Current < hitDice {
+			return nil, fmt.Errorf("not enough hit dice remaining")
+		}
+
+		conMod := getModifier(char.Attributes.Constitution)
+		for i := 0; i < hitDice && char.HitPoints < char.MaxHitPoints; i++ {
+			roll, err := s.roller.Roll("1" + pool.Die)
+			if err != nil {
+				return nil, fmt.Errorf("failed to roll hit die: %w", err)
+			}
+			healed := max(0, roll.Total+conMod)
+			healed = min(healed, char.MaxHitPoints-char.HitPoints)
+			char.HitPoints += healed
+			pool.Current--
+			result.HitDiceSpent++
+			result.HitDiceRolls = append(result.HitDiceRolls, roll.Total)
+			result.HitPointsRecovered += healed
+		}
+		if err := s.resourceRepo.UpsertResource(ctx, pool); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.restoreOnRest(ctx, resources, result, models.RecoveryShortRest); err != nil {
+		return nil, err
+	}
+	if err := s.rechargeItems(char.ID, result, models.RecoveryShortRest); err != nil {
+		return nil, err
+	}
+
+	// Warlocks recover pact magic slots on a short rest
+	if strings.EqualFold(char.Class, constants.ClassWarlock) {
+		restoreAllSpellSlots(char)
+	}
+
+	return s.finishRest(ctx, char, resources, result)
+}
+
+// LongRest restores hit points, spell slots and rest resources, regains half the
+// character's hit dice and clears exhaustion.
+func (s *CharacterResourceService) LongRest(ctx context.Context, characterID string) (*models.RestResult, error) {
+	char, err := s.characterRepo.GetByID(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := s.syncResources(ctx, char)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.RestResult{
+		RestType:          models.RestTypeLong,
+		ResourcesRestored: make(map[string]int),
+	}
+
+	result.HitPointsRecovered = char.MaxHitPoints - char.HitPoints
+	char.HitPoints = char.MaxHitPoints
+	restoreAllSpellSlots(char)
+
+	if pool := findResourceByKey(resources, models.ResourceHitDice); pool != nil {
+		regained := min(max(1, pool.Max/2), pool.Max-pool.Current)
+		if regained > 0 {
+			pool.Current += regained
+			result.HitDiceRecovered = regained
+			if err := s.resourceRepo.UpsertResource(ctx, pool); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := s.restoreOnRest(ctx, resources, result, models.RecoveryShortRest, models.RecoveryLongRest); err != nil {
+		return nil, err
+	}
+	if err := s.rechargeItems(char.ID, result, models.RecoveryShortRest, models.RecoveryLongRest); err != nil {
+		return nil, err
+	}
+
+	return s.finishRest(ctx, char, resources, result)
+}
+
+// restoreOnRest refills every resource whose recovery rule is in rules
+func (s *CharacterResourceService) restoreOnRest(ctx context.Context, resources []*models.CharacterResource, result *models.RestResult, rules ...models.ResourceRecovery) error {
+	for _, resource := range resources {
+		if resource.Key == models.ResourceHitDice || resource.Current >= resource.Max {
+			continue
+		}
+		for _, rule := range rules {
+			if resource.Recovery != rule {
+				continue
+			}
+			result.ResourcesRestored[resource.Key] = resource.Max - resource.Current
+			resource.Current = resource.Max
+			if err := s.resourceRepo.UpsertResource(ctx, resource); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// rechargeItems recharges the character's magic items that renew under one of rules
+func (s *CharacterResourceService) rechargeItems(characterID string, result *models.RestResult, rules ...models.ResourceRecovery) error {
+	if s.inventory == nil {
+		return nil
+	}
+	regained, err := s.inventory.RechargeItems(characterID, rules...)
+	if err != nil {
+		return err
+	}
+	if len(regained) > 0 {
+		result.ItemsRecharged = regained
+	}
+	return nil
+}
+
+func (s *CharacterResourceService) finishRest(ctx context.Context, char *models.Character, resources []*models.CharacterResource, result *models.RestResult) (*models.RestResult, error) {
+	if err := s.characterRepo.Update(ctx, char); err != nil {
+		return nil, err
+	}
+	s.versions.RecordChange(ctx, char, models.CharacterChangeRest, fmt.Sprintf("%s rest", result.RestType))
+
+	state, err := s.GetRestState(ctx, char.ID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if result.RestType == models.RestTypeLong {
+		state.ExhaustionLevel = 0
+		state.LastLongRestAt = &now
+	} else {
+		state.LastShortRestAt = &now
+	}
+	if err := s.resourceRepo.UpsertRestState(ctx, state); err != nil {
+		return nil, err
+	}
+
+	result.ExhaustionLevel = state.ExhaustionLevel
+	result.Character = char
+	result.Resources = resources
+	return result, nil
+}
+
+func (s *CharacterResourceService) findResource(ctx context.Context, characterID, key string) (*models.CharacterResource, error) {
+	resources, err := s.GetResources(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+	resource := findResourceByKey(resources, key)
+	if resource == nil {
+		return nil, fmt.Errorf("character has no %s resource", key)
+	}
+	return resource, nil
+}
+
+// reloadResource reads a resource back after an update made in the database
+func (s *CharacterResourceService) reloadResource(ctx context.Context, resource *models.CharacterResource) (*models.CharacterResource, error) {
+	updated, err := s.resourceRepo.GetResource(ctx, resource.CharacterID, resource.Key)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("character has no %s resource", resource.Key)
+	}
+	return updated, nil
+}
+
+// syncResources reconciles the stored ledger with the resources the character's
+// class and level grant. New resources start full; when a maximum changes the
+// current value moves by the same amount.
+func (s *CharacterResourceService) syncResources(ctx context.Context, char *models.Character) ([]*models.CharacterResource, error) {
+	stored, err := s.resourceRepo.GetResources(ctx, char.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	storedByKey := make(map[string]*models.CharacterResource, len(stored))
+	for _, resource := range stored {
+		storedByKey[resource.Key] = resource
+	}
+
+	derived := ClassResources(char)
+	result := make([]*models.CharacterResource, 0, len(derived)+len(stored))
+	seen := make(map[string]bool, len(derived))
+	for _, def := range derived {
+		seen[def.Key] = true
+		existing, ok := storedByKey[def.Key]
+		if !ok || existing.Custom {
+			if ok {
+				// A custom resource shadows the class one
+				result = append(result, existing)
+				continue
+			}
+			def.CharacterID = char.ID
+			def.Current = def.Max
+			if err := s.resourceRepo.UpsertResource(ctx, def); err != nil {
+				return nil, err
+			}
+			result = append(result, def)
+			continue
+		}
+
+		if existing.Max != def.Max || existing.Recovery != def.Recovery || existing.Die != def.Die || existing.Name != def.Name {
+			existing.Current = min(def.Max, max(0, existing.Current+def.Max-existing.Max))
+			existing.Max = def.Max
+			existing.Name = def.Name
+			existing.Recovery = def.Recovery
+			existing.Die = def.Die
+			if err := s.resourceRepo.UpsertResource(ctx, existing); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, existing)
+	}
+
+	for _, resource := range stored {
+		if seen[resource.Key] {
+			continue
+		}
+		if resource.Custom {
+			result = append(result, resource)
+			continue
+		}
+		// Class resources the character no longer has (e.g. after a class change)
+		if err := s.resourceRepo.DeleteResource(ctx, char.ID, resource.Key); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// ClassResources derives the class resources and hit dice a character has at its
+// current level. Current uses are left at zero for the caller to fill.
+func ClassResources(char *models.Character) []*models.CharacterResource {
+	level := max(1, char.Level)
+	class := strings.ToLower(char.Class)
+
+	resources := []*models.CharacterResource{{
+		Key:      models.ResourceHitDice,
+		Name:     "Hit Dice",
+		Max:      level,
+		Recovery: models.RecoveryLongRest,
+		Die:      fmt.Sprintf("d%d", hitDieSize(char)),
+	}}
+
+	add := func(key, name string, maxUses int, recovery models.ResourceRecovery, die string) {
+		if maxUses > 0 {
+			resources = append(resources, &models.CharacterResource{
+				Key: key, Name: name, Max: maxUses, Recovery: recovery, Die: die,
+			})
+		}
+	}
+
+	switch class {
+	case constants.ClassBarbarian:
+		add(models.ResourceRage, "Rage", rageUses(level), models.RecoveryLongRest, "")
+	case constants.ClassBard:
+		recovery := models.RecoveryLongRest
+		if level >= 5 {
+			// Font of Inspiration
+			recovery = models.RecoveryShortRest
+		}
+		add(models.ResourceBardicInspiration, "Bardic Inspiration",
+			max(1, getModifier(char.Attributes.Charisma)), recovery, bardicInspirationDie(level))
+	case constants.ClassCleric:
+		add(models.ResourceChannelDivinity, "Channel Divinity",
+			levelScaled(level, map[int]int{2: 1, 6: 2, 18: 3}), models.RecoveryShortRest, "")
+	case constants.ClassDruid:
+		add(models.ResourceWildShape, "Wild Shape", levelScaled(level, map[int]int{2: 2}), models.RecoveryShortRest, "")
+	case constants.ClassFighter:
+		add(models.ResourceSecondWind, "Second Wind", 1, models.RecoveryShortRest, "")
+		add(models.ResourceActionSurge, "Action Surge",
+			levelScaled(level, map[int]int{2: 1, 17: 2}), models.RecoveryShortRest, "")
+	case constants.ClassMonk:
+		if level >= 2 {
+			add(models.ResourceKi, "Ki", level, models.RecoveryShortRest, "")
+		}
+	case constants.ClassPaladin:
+		add(models.ResourceLayOnHands, "Lay on Hands", 5*level, models.RecoveryLongRest, "")
+		add(models.ResourceChannelDivinity, "Channel Divinity", levelScaled(level, map[int]int{3: 1}), models.RecoveryShortRest, "")
+	case constants.ClassSorcerer:
+		if level >= 2 {
+			add(models.ResourceSorceryPoints, "Sorcery Points", level, models.RecoveryLongRest, "")
+		}
+	}
+
+	return resources
+}
+
+func hitDieSize(char *models.Character) int {
+	if m := hitDiceNotationPattern.FindStringSubmatch(char.HitDice); m != nil {
+		if size, err := strconv.Atoi(m[1]); err == nil && size > 0 {
+			return size
+		}
+	}
+	if size, ok := classHitDie[strings.ToLower(char.Class)]; ok {
+		return size
+	}
+	return 8
+}
+
+func rageUses(level int) int {
+	if level >= 20 {
+		return unlimitedResourceUses
+	}
+	return levelScaled(level, map[int]int{1: 2, 3: 3, 6: 4, 12: 5, 17: 6})
+}
+
+func bardicInspirationDie(level int) string {
+	return fmt.Sprintf("d%d", levelScaled(level, map[int]int{1: 6, 5: 8, 10: 10, 15: 12}))
+}
+
+// levelScaled returns the value for the highest threshold at or below level
+func levelScaled(level int, thresholds map[int]int) int {
+	best, value := 0, 0
+	for threshold, v := range thresholds {
+		if level >= threshold && threshold > best {
+			best, value = threshold, v
+		}
+	}
+	return value
+}
+
+func restoreAllSpellSlots(char *models.Character) {
+	for i := range char.Spells.SpellSlots {
+		char.Spells.SpellSlots[i].Remaining = char.Spells.SpellSlots[i].Total
+	}
+}
+
+func findResourceByKey(resources []*models.CharacterResource, key string) *models.CharacterResource {
+	for _, resource := range resources {
+		if resource.Key == key {
+			return resource
+		}
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/internal/services"
+	"github.com/ctclostio/DnD-Game/backend/internal/services/mocks"
+)
+
+const (
+	testResourceCharacterID  = "char-res-1"
+	testMethodGetResources   = "GetResources"
+	testMethodUpsertResource = "UpsertResource"
+	testMethodGetResource    = "GetResource"
+	testMethodSpendResource  = "SpendResource"
+	testMethodGetRestState   = "GetRestState"
+	testMethodUpsertRest     = "UpsertRestState"
+)
+
+func resourceByKey(resources []*models.CharacterResource, key string) *models.CharacterResource {
+	for _, r := range resources {
+		if r.Key == key {
+			return r
+		}
+	}
+	return nil
+}
+
+func TestClassResources(t *testing.T) {
+	tests := []struct {
+		name     string
+		char     *models.Character
+		key      string
+		max      int
+		recovery models.ResourceRecovery
+		die      string
+	}{
+		{"barbarian rage", &models.Character{Class: "barbarian", Level: 6}, models.ResourceRage, 4, models.RecoveryLongRest, ""},
+		{"level 20 rage is unlimited", &models.Character{Class: "barbarian", Level: 20}, models.ResourceRage, 99, models.RecoveryLongRest, ""},
+		{"monk ki", &models.Character{Class: "Monk", Level: 7}, models.ResourceKi, 7, models.RecoveryShortRest, ""},
+		{"low level bard inspiration", &models.Character{Class: "bard", Level: 3, Attributes: models.Attributes{Charisma: 16}}, models.ResourceBardicInspiration, 3, models.RecoveryLongRest, "d6"},
+		{"font of inspiration", &models.Character{Class: "bard", Level: 5, Attributes: models.Attributes{Charisma: 8}}, models.ResourceBardicInspiration, 1, models.RecoveryShortRest, "d8"},
+		{"cleric channel divinity", &models.Character{Class: "cleric", Level: 6}, models.ResourceChannelDivinity, 2, models.RecoveryShortRest, ""},
+		{"sorcery points", &models.Character{Class: "sorcerer", Level: 4}, models.ResourceSorceryPoints, 4, models.RecoveryLongRest, ""},
+		{"action surge", &models.Character{Class: "fighter", Level: 17}, models.ResourceActionSurge, 2, models.RecoveryShortRest, ""},
+		{"hit dice from class", &models.Character{Class: "wizard", Level: 5}, models.ResourceHitDice, 5, models.RecoveryLongRest, "d6"},
+		{"hit dice from notation", &models.Character{Class: "custom", Level: 2, HitDice: "1d10"}, models.ResourceHitDice, 2, models.RecoveryLongRest, "d10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := resourceByKey(services.ClassResources(tt.char), tt.key)
+			require.NotNil(t, resource)
+			assert.Equal(t, tt.max, resource.Max)
+			assert.Equal(t, tt.recovery, resource.Recovery)
+			assert.Equal(t, tt.die, resource.Die)
+		})
+	}
+
+	t.Run("no ki before level 2", func(t *testing.T) {
+		assert.Nil(t, resourceByKey(services.ClassResources(&models.Character{Class: "monk", Level: 1}), models.ResourceKi))
+	})
+}
+
+func TestCharacterResourceService_UseResource(t *testing.T) {
+	ctx := context.Background()
+	monk := &models.Character{ID: testResourceCharacterID, Class: "monk", Level: 5}
+
+	setup := func(ki int) (*services.CharacterResourceService, *mocks.MockCharacterResourceRepository) {
+		resourceRepo := new(mocks.MockCharacterResourceRepository)
+		characterRepo := new(mocks.MockCharacterRepository)
+		characterRepo.On(testMethodGetByID, ctx, testResourceCharacterID).Return(monk, nil)
+		resourceRepo.On(testMethodGetResources, ctx, testResourceCharacterID).Return([]*models.CharacterResource{
+			{CharacterID: testResourceCharacterID, Key: models.ResourceHitDice, Name: "Hit Dice", Current: 5, Max: 5, Recovery: models.RecoveryLongRest, Die: "d8"},
+			{CharacterID: testResourceCharacterID, Key: models.ResourceKi, Name: "Ki", Current: ki, Max: 5, Recovery: models.RecoveryShortRest},
+		}, nil)
+		return services.NewCharacterResourceService(resourceRepo, characterRepo), resourceRepo
+	}
+
+	t.Run("spends uses", func(t *testing.T) {
+		svc, resourceRepo := setup(5)
+		resourceRepo.On(testMethodSpendResource, ctx, testResourceCharacterID, models.ResourceKi, 2).Return(true, nil)
+		resourceRepo.On(testMethodGetResource, ctx, testResourceCharacterID, models.ResourceKi).Return(
+			&models.CharacterResource{CharacterID: testResourceCharacterID, Key: models.ResourceKi, Name: "Ki", Current: 3, Max: 5}, nil)
+
+		resource, err := svc.UseResource(ctx, testResourceCharacterID, models.ResourceKi, 2)
+		require.NoError(t, err)
+		assert.Equal(t, 3, resource.Current)
+		resourceRepo.AssertNotCalled(t, testMethodUpsertResource, mock.Anything, mock.Anything)
+	})
+
+	t.Run("not enough remaining", func(t *testing.T) {
+		// Another spend took the uses after they were read
+		svc, resourceRepo := setup(5)
+		resourceRepo.On(testMethodSpendResource, ctx, testResourceCharacterID, models.ResourceKi, 2).Return(false, nil)
+		resourceRepo.On(testMethodGetResource, ctx, testResourceCharacterID, models.ResourceKi).Return(
+			&models.CharacterResource{CharacterID: testResourceCharacterID, Key: models.ResourceKi, Name: "Ki", Current: 1, Max: 5}, nil)
+
+		_, err := svc.UseResource(ctx, testResourceCharacterID, models.ResourceKi, 2)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not enough Ki remaining: 1 of 2")
+		resourceRepo.AssertNotCalled(t, testMethodUpsertResource, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown resource", func(t *testing.T) {
+		svc, _ := setup(5)
+
+		_, err := svc.UseResource(ctx, testResourceCharacterID, models.ResourceRage, 1)
+		assert.Error(t, err)
+	})
+}
+
+func TestCharacterResourceService_Rests(t *testing.T) {
+	ctx := context.Background()
+
+	newFighter := func() *models.Character {
+		return &models.Character{
+			ID: testResourceCharacterID, Class: "fighter", Level: 4,
+			HitPoints: 10, MaxHitPoints: 40,
+			Attributes: models.Attributes{Constitution: 14},
+			Spells:     models.SpellData{SpellSlots: []models.SpellSlot{{Level: 1, Total: 2, Remaining: 0}}},
+		}
+	}
+	storedResources := func(hitDice int) []*models.CharacterResource {
+		return []*models.CharacterResource{
+			{CharacterID: testResourceCharacterID, Key: models.ResourceHitDice, Name: "Hit Dice", Current: hitDice, Max: 4, Recovery: models.RecoveryLongRest, Die: "d10"},
+			{CharacterID: testResourceCharacterID, Key: models.ResourceSecondWind, Name: "Second Wind", Current: 0, Max: 1, Recovery: models.RecoveryShortRest},
+			{CharacterID: testResourceCharacterID, Key: models.ResourceActionSurge, Name: "Action Surge", Current: 0, Max: 1, Recovery: models.RecoveryShortRest},
+			{CharacterID: testResourceCharacterID, Key: "luck", Name: "Luck", Current: 0, Max: 3, Recovery: models.RecoveryDawn, Custom: true},
+		}
+	}
+
+	t.Run("short rest spends hit dice with constitution", func(t *testing.T) {
+		resourceRepo := new(mocks.MockCharacterResourceRepository)
+		characterRepo := new(mocks.MockCharacterRepository)
+		fighter := newFighter()
+		characterRepo.On(testMethodGetByID, ctx, testResourceCharacterID).Return(fighter, nil)
+		characterRepo.On("Update", ctx, fighter).Return(nil)
+		resourceRepo.On(testMethodGetResources, ctx, testResourceCharacterID).Return(storedResources(4), nil)
+		resourceRepo.On(testMethodUpsertResource, ctx, mock.Anything).Return(nil)
+		resourceRepo.On(testMethodGetRestState, ctx, testResourceCharacterID).Return(nil, nil)
+		resourceRepo.On(testMethodUpsertRest, ctx, mock.Anything).Return(nil)
+
+		svc := services.NewCharacterResourceService(resourceRepo, characterRepo)
+		result, err := svc.ShortRest(ctx, testResourceCharacterID, 2)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, result.HitDiceSpent)
+		require.Len(t, result.HitDiceRolls, 2)
+		expected := 0
+		for _, roll := range result.HitDiceRolls {
+			assert.True(t, roll >= 1 && roll <= 10)
+			expected += roll + 2
+		}
+		assert.Equal(t, expected, result.HitPointsRecovered)
+		assert.Equal(t, 10+expected, fighter.HitPoints)
+		assert.Equal(t, 2, resourceByKey(result.Resources, models.ResourceHitDice).Current)
+		assert.Equal(t, 1, resource(result, models.ResourceSecondWind).Current)
+		assert.Equal(t, 1, result.ResourcesRestored[models.ResourceActionSurge])
+		assert.Equal(t, 0, resource(result, "luck").Current, "dawn resources do not recover on rests")
+		assert.Equal(t, 0, fighter.Spells.SpellSlots[0].Remaining, "only warlocks regain slots on a short rest")
+	})
+
+	t.Run("short rest without enough hit dice", func(t *testing.T) {
+		resourceRepo := new(mocks.MockCharacterResourceRepository)
+		characterRepo := new(mocks.MockCharacterRepository)
+		characterRepo.On(testMethodGetByID, ctx, testResourceCharacterID).Return(newFighter(), nil)
+		resourceRepo.On(testMethodGetResources, ctx, testResourceCharacterID).Return(storedResources(1), nil)
+
+		svc := services.NewCharacterResourceService(resourceRepo, characterRepo)
+		_, err := svc.ShortRest(ctx, testResourceCharacterID, 2)
+		assert.Error(t, err)
+	})
+
+	t.Run("long rest restores half hit dice and clears exhaustion", func(t *testing.T) {
+		resourceRepo := new(mocks.MockCharacterResourceRepository)
+		characterRepo := new(mocks.MockCharacterRepository)
+		fighter := newFighter()
+		characterRepo.On(testMethodGetByID, ctx, testResourceCharacterID).Return(fighter, nil)
+		characterRepo.On("Update", ctx, fighter).Return(nil)
+		resourceRepo.On(testMethodGetResources, ctx, testResourceCharacterID).Return(storedResources(0), nil)
+		resourceRepo.On(testMethodUpsertResource, ctx, mock.Anything).Return(nil)
+		resourceRepo.On(testMethodGetRestState, ctx, testResourceCharacterID).
+			Return(&models.CharacterRestState{CharacterID: testResourceCharacterID, ExhaustionLevel: 3}, nil)
+		resourceRepo.On(testMethodUpsertRest, ctx, mock.MatchedBy(func(s *models.CharacterRestState) bool {
+			return s.ExhaustionLevel == 0 && s.LastLongRestAt != nil
+		})).Return(nil)
+
+		svc := services.NewCharacterResourceService(resourceRepo, characterRepo)
+		result, err := svc.LongRest(ctx, testResourceCharacterID)
+		require.NoError(t, err)
+
+		assert.Equal(t, 40, fighter.HitPoints)
+		assert.Equal(t, 30, result.HitPointsRecovered)
+		assert.Equal(t, 2, result.HitDiceRecovered)
+		assert.Equal(t, 2, resource(result, models.ResourceHitDice).Current)
+		assert.Equal(t, 0, result.ExhaustionLevel)
+		assert.Equal(t, 2, fighter.Spells.SpellSlots[0].Remaining)
+		resourceRepo.AssertExpectations(t)
+	})
+}
+
+func TestCharacterResourceService_SetExhaustion(t *testing.T) {
+	ctx := context.Background()
+	svc := services.NewCharacterResourceService(new(mocks.MockCharacterResourceRepository), new(mocks.MockCharacterRepository))
+
+	_, err := svc.SetExhaustion(ctx, testResourceCharacterID, 7)
+	assert.Error(t, err)
+}
+
+func resource(result *models.RestResult, key string) *models.CharacterResource {
+	return resourceByKey(result.Resources, key)
+}
+
+func TestCombatService_UseResourceAction(t *testing.T) {
+	ctx := context.Background()
+	barbarian := &models.Character{ID: testResourceCharacterID, Class: "barbarian", Level: 3}
+
+	resourceRepo := new(mocks.MockCharacterResourceRepository)
+	characterRepo := new(mocks.MockCharacterRepository)
+	characterRepo.On(testMethodGetByID, ctx, testResourceCharacterID).Return(barbarian, nil)
+	resourceRepo.On(testMethodGetResources, ctx, testResourceCharacterID).Return([]*models.CharacterResource{
+		{CharacterID: testResourceCharacterID, Key: models.ResourceHitDice, Name: "Hit Dice", Current: 3, Max: 3, Recovery: models.RecoveryLongRest, Die: "d12"},
+		{CharacterID: testResourceCharacterID, Key: models.ResourceRage, Name: "Rage", Current: 3, Max: 3, Recovery: models.RecoveryLongRest},
+	}, nil)
+	resourceRepo.On(testMethodSpendResource, ctx, testResourceCharacterID, models.ResourceRage, 1).Return(true, nil)
+	resourceRepo.On(testMethodGetResource, ctx, testResourceCharacterID, models.ResourceRage).Return(
+		&models.CharacterResource{CharacterID: testResourceCharacterID, Key: models.ResourceRage, Name: "Rage", Current: 2, Max: 3}, nil)
+
+	combatService := services.NewCombatService()
+	combatService.SetResourceService(services.NewCharacterResourceService(resourceRepo, characterRepo))
+
+	combat, err := combatService.StartCombat(ctx, "session-1", []models.Combatant{
+		{ID: "barbarian", CharacterID: testResourceCharacterID, Name: "Grog", Type: models.CombatantTypeCharacter, HP: 30, MaxHP: 30},
+		{ID: "goblin", Name: "Goblin", Type: models.CombatantTypeNPC, HP: 7, MaxHP: 7},
+	})
+	require.NoError(t, err)
+
+	action, err := combatService.ProcessAction(ctx, combat.ID, models.CombatRequest{
+		ActorID:     "barbarian",
+		Action:      models.ActionTypeUseResource,
+		ResourceKey: models.ResourceRage,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, action.Description, "Rage (2/3 remaining)")
+
+	_, err = combatService.ProcessAction(ctx, combat.ID, models.CombatRequest{
+		ActorID:     "goblin",
+		Action:      models.ActionTypeUseResource,
+		ResourceKey: models.ResourceRage,
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,402 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+// US Letter page geometry in PDF points
+const (
+	pdfPageWidth  = 612.0
+	pdfPageHeight = 792.0
+	pdfMargin     = 48.0
+	pdfBodySize   = 9.0
+	pdfLineHeight = 12.0
+)
+
+// sheetWriter lays out text on PDF pages top to bottom using the standard Helvetica
+// fonts, so sheets render without any font files or external tools
+type sheetWriter struct {
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+	y     float64
+}
+
+// RenderCharacterSheetPDF renders print-ready character sheets, one or more pages per character
+func RenderCharacterSheetPDF(docs []*models.CharacterExportDocument) []byte {
+	w := &sheetWriter{}
+	for _, doc := range docs {
+		w.newPage()
+		w.writeCharacter(doc)
+	}
+	if len(w.pages) == 0 {
+		w.newPage()
+	}
+	return w.bytes()
+}
+
+func (w *sheetWriter) writeCharacter(doc *models.CharacterExportDocument) {
+	char := doc.Character
+
+	w.text(pdfMargin, w.y, 20, true, char.Name)
+	w.y -= 18
+	identity := fmt.Sprintf("Level %d %s %s", char.Level, titleCase(joinNonEmpty(" ", char.Subrace, char.Race)), titleCase(char.Class))
+	if char.Subclass != "" {
+		identity += " (" + char.Subclass + ")"
+	}
+	w.text(pdfMargin, w.y, 11, false, identity)
+	w.y -= 14
+	w.text(pdfMargin, w.y, pdfBodySize, false, joinNonEmpty("   ",
+		sheetLabel("Background", titleCase(char.Background)),
+		sheetLabel("Alignment", char.Alignment),
+		fmt.Sprintf("XP: %d", char.ExperiencePoints)))
+	w.y -= 8
+	w.rule()
+
+	w.writeAbilities(char)
+	w.writeCombat(char)
+	w.writeProficiencies(char)
+	w.writeFeatures(char)
+	w.writeInventory(doc)
+	w.writeSpells(char)
+}
+
+func (w *sheetWriter) writeAbilities(char *models.Character) {
+	w.heading("Ability Scores")
+	attrs := char.Attributes
+	saves := char.SavingThrows
+	abilities := []struct {
+		name  string
+		score int
+		save  models.SavingThrow
+	}{
+		{"STR", attrs.Strength, saves.Strength},
+		{"DEX", attrs.Dexterity, saves.Dexterity},
+		{"CON", attrs.Constitution, saves.Constitution},
+		{"INT", attrs.Intelligence, saves.Intelligence},
+		{"WIS", attrs.Wisdom, saves.Wisdom},
+		{"CHA", attrs.Charisma, saves.Charisma},
+	}
+
+	boxWidth := (pdfPageWidth - 2*pdfMargin) / float64(len(abilities))
+	w.ensure(56)
+	for i, ability := range abilities {
+		x := pdfMargin + float64(i)*boxWidth
+		w.rect(x+2, w.y-48, boxWidth-4, 52)
+		w.text(x+8, w.y-8, 8, true, ability.name)
+		w.text(x+8, w.y-28, 18, true, fmt.Sprintf("%d", ability.score))
+		w.text(x+boxWidth/2, w.y-28, 11, false, signedModifier(getModifier(ability.score)))
+		save := "Save " + signedModifier(ability.save.Modifier)
+		if ability.save.Proficiency {
+			save += " *"
+		}
+		w.text(x+8, w.y-42, 8, false, save)
+	}
+	w.y -= 62
+}
+
+func (w *sheetWriter) writeCombat(char *models.Character) {
+	w.heading("Combat")
+	w.line(fmt.Sprintf("Armor Class: %d    Initiative: %s    Speed: %d ft    Proficiency Bonus: %s",
+		char.ArmorClass, signedModifier(char.Initiative), char.Speed, signedModifier(char.ProficiencyBonus)))
+	hp := fmt.Sprintf("Hit Points: %d / %d", char.HitPoints, char.MaxHitPoints)
+	if char.TempHitPoints > 0 {
+		hp += fmt.Sprintf(" (+%d temporary)", char.TempHitPoints)
+	}
+	w.line(joinNonEmpty("    ", hp, sheetLabel("Hit Dice", char.HitDice)))
+
+	if len(char.Skills) == 0 {
+		return
+	}
+	w.heading("Skills")
+	skills := make([]string, len(char.Skills))
+	for i, skill := range char.Skills {
+		mark := "  "
+		if skill.Proficiency {
+			mark = "* "
+		}
+		skills[i] = fmt.Sprintf("%s%s %s", mark, skill.Name, signedModifier(skill.Modifier))
+	}
+	w.columns(skills, 3)
+}
+
+func (w *sheetWriter) writeProficiencies(char *models.Character) {
+	p := char.Proficiencies
+	if len(p.Armor)+len(p.Weapons)+len(p.Tools)+len(p.Languages) == 0 {
+		return
+	}
+	w.heading("Proficiencies & Languages")
+	for _, entry := range []struct {
+		label  string
+		values []string
+	}{
+		{"Armor", p.Armor}, {"Weapons", p.Weapons}, {"Tools", p.Tools}, {"Languages", p.Languages},
+	} {
+		if len(entry.values) > 0 {
+			w.line(entry.label + ": " + strings.Join(entry.values, ", "))
+		}
+	}
+}
+
+func (w *sheetWriter) writeFeatures(char *models.Character) {
+	if len(char.Features) == 0 {
+		return
+	}
+	w.heading("Features & Traits")
+	for _, feature := range char.Features {
+		w.ensure(pdfLineHeight * 2)
+		w.text(pdfMargin, w.y, pdfBodySize, true, feature.Name)
+		w.y -= pdfLineHeight
+		if feature.Description != "" {
+			w.line(feature.Description)
+		}
+	}
+}
+
+func (w *sheetWriter) writeInventory(doc *models.CharacterExportDocument) {
+	w.heading("Equipment")
+	c := doc.Currency
+	w.line(fmt.Sprintf("CP %d   SP %d   EP %d   GP %d   PP %d", c.Copper, c.Silver, c.Electrum, c.Gold, c.Platinum))
+
+	var totalWeight float64
+	for _, entry := range doc.Inventory {
+		if entry.Item == nil {
+			continue
+		}
+		marks := joinNonEmpty(", ", sheetFlag(entry.Equipped, "equipped"), sheetFlag(entry.Attuned, "attuned"))
+		if marks != "" {
+			marks = " [" + marks + "]"
+		}
+		weight := entry.Item.Weight * float64(entry.Quantity)
+		totalWeight += weight
+		w.line(fmt.Sprintf("%d x %s%s   %.1f lb", entry.Quantity, entry.Item.Name, marks, weight))
+	}
+	w.line(fmt.Sprintf("Total weight: %.1f lb", totalWeight))
+}
+
+func (w *sheetWriter) writeSpells(char *models.Character) {
+	spells := char.Spells
+	if len(spells.SpellsKnown) == 0 && len(spells.SpellSlots) == 0 {
+		return
+	}
+	w.heading("Spellcasting")
+	w.line(joinNonEmpty("    ",
+		sheetLabel("Ability", titleCase(spells.SpellcastingAbility)),
+		fmt.Sprintf("Save DC: %d", spells.SpellSaveDC),
+		"Attack Bonus: "+signedModifier(spells.SpellAttackBonus)))
+
+	if len(spells.SpellSlots) > 0 {
+		slots := make([]string, len(spells.SpellSlots))
+		for i, slot := range spells.SpellSlots {
+			slots[i] = fmt.Sprintf("L%d: %d/%d", slot.Level, slot.Remaining, slot.Total)
+		}
+		w.line("Slots  " + strings.Join(slots, "   "))
+	}
+
+	known := append([]models.Spell(nil), spells.SpellsKnown...)
+	sort.SliceStable(known, func(i, j int) bool {
+		if known[i].Level != known[j].Level {
+			return known[i].Level < known[j].Level
+		}
+		return known[i].Name < known[j].Name
+	})
+	level := -1
+	for _, spell := range known {
+		if spell.Level != level {
+			level = spell.Level
+			label := "Cantrips"
+			if level > 0 {
+				label = fmt.Sprintf("Level %d", level)
+			}
+			w.ensure(pdfLineHeight * 2)
+			w.text(pdfMargin, w.y, pdfBodySize, true, label)
+			w.y -= pdfLineHeight
+		}
+		mark := "  "
+		if spell.Prepared {
+			mark = "* "
+		}
+		w.line(mark + joinNonEmpty("   ", spell.Name, sheetFlag(spell.Ritual, "(ritual)"), spell.CastingTime, spell.Range, spell.Components))
+	}
+}
+
+// heading starts a new section with a bold title and a rule underneath
+func (w *sheetWriter) heading(title string) {
+	w.ensure(pdfLineHeight * 3)
+	w.y -= 6
+	w.text(pdfMargin, w.y, 12, true, title)
+	w.y -= 4
+	w.rule()
+}
+
+// line writes body text, wrapping it to the page width
+func (w *sheetWriter) line(s string) {
+	width := pdfPageWidth - 2*pdfMargin
+	maxChars := int(width / (pdfBodySize * 0.5))
+	for _, wrapped := range wrapText(s, maxChars) {
+		w.ensure(pdfLineHeight)
+		w.text(pdfMargin, w.y, pdfBodySize, false, wrapped)
+		w.y -= pdfLineHeight
+	}
+}
+
+// columns writes short entries in a fixed number of columns
+func (w *sheetWriter) columns(entries []string, count int) {
+	width := (pdfPageWidth - 2*pdfMargin) / float64(count)
+	for i := 0; i < len(entries); i += count {
+		w.ensure(pdfLineHeight)
+		for j := 0; j < count && i+j < len(entries); j++ {
+			w.text(pdfMargin+float64(j)*width, w.y, pdfBodySize, false, entries[i+j])
+		}
+		w.y -= pdfLineHeight
+	}
+}
+
+func (w *sheetWriter) rule() {
+	fmt.Fprintf(w.page, "0.5 w %.2f %.2f m %.2f %.2f l S\n", pdfMargin, w.y, pdfPageWidth-pdfMargin, w.y)
+	w.y -= pdfLineHeight
+}
+
+func (w *sheetWriter) rect(x, y, width, height float64) {
+	fmt.Fprintf(w.page, "0.5 w %.2f %.2f %.2f %.2f re S\n", x, y, width, height)
+}
+
+func (w *sheetWriter) text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(w.page, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfEscape(s))
+}
+
+// ensure starts a new page when fewer than height points remain above the bottom margin
+func (w *sheetWriter) ensure(height float64) {
+	if w.y-height < pdfMargin {
+		w.newPage()
+	}
+}
+
+func (w *sheetWriter) newPage() {
+	w.page = &bytes.Buffer{}
+	w.pages = append(w.pages, w.page)
+	w.y = pdfPageHeight - pdfMargin - 16
+}
+
+// bytes assembles the PDF file: catalog, page tree, two fonts, then a page and content
+// stream object per page, followed by the cross-reference table
+func (w *sheetWriter) bytes() []byte {
+	const firstPageObject = 5
+	var kids []string
+	for i := range w.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", firstPageObject+2*i))
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(w.pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	}
+	for i, page := range w.pages {
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+				pdfPageWidth, pdfPageHeight, firstPageObject+2*i+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()),
+		)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes()
+}
+
+// pdfEscape escapes a string for a PDF literal, mapping text to the Latin-1 range of
+// WinAnsiEncoding and replacing anything outside it
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n' || r == '\t':
+			b.WriteByte(' ')
+		case r == '’' || r == '‘':
+			b.WriteByte('\'')
+		case r == '–' || r == '—':
+			b.WriteByte('-')
+		case r < 32 || r > 255:
+			b.WriteByte('?')
+		case r < 128:
+			b.WriteRune(r)
+		default:
+			fmt.Fprintf(&b, "\\%03o", r)
+		}
+	}
+	return b.String()
+}
+
+// wrapText splits text into lines of at most maxChars characters on word boundaries
+func wrapText(s string, maxChars int) []string {
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		return []string{""}
+	}
+	var lines []string
+	current := words[0]
+	for _, word := range words[1:] {
+		if len(current)+1+len(word) > maxChars {
+			lines = append(lines, current)
+			current = word
+			continue
+		}
+		current += " " + word
+	}
+	return append(lines, current)
+}
+
+func signedModifier(n int) string {
+	if n >= 0 {
+		return fmt.Sprintf("+%d", n)
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+func sheetLabel(label, value string) string {
+	if value == "" {
+		return ""
+	}
+	return label + ": " + value
+}
+
+func sheetFlag(set bool, label string) string {
+	if set {
+		return label
+	}
+	return ""
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	var kept []string
+	for _, part := range parts {
+		if part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, sep)
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/auth"
+	"github.com/ctclostio/DnD-Game/backend/internal/database"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/logger"
+)
+
+// versionDiffIgnoredFields change on every save and would drown out real edits
+var versionDiffIgnoredFields = map[string]bool{
+	"createdAt": true,
+	"updatedAt": true,
+}
+
+type characterChangeKey struct{}
+
+type characterChange struct {
+	changeType models.CharacterChangeType
+	reason     string
+}
+
+// WithCharacterChange labels versions recorded under ctx with a change type and reason.
+// An empty change type keeps the type chosen by the code that saves the character.
+func WithCharacterChange(ctx context.Context, changeType models.CharacterChangeType, reason string) context.Context {
+	return context.WithValue(ctx, characterChangeKey{}, characterChange{changeType: changeType, reason: reason})
+}
+
+// CharacterVersionService records immutable character snapshots and restores or compares them
+type CharacterVersionService struct {
+	versionRepo   database.CharacterVersionRepository
+	characterRepo database.CharacterRepository
+}
+
+// NewCharacterVersionService creates a new character version service
+func NewCharacterVersionService(versionRepo database.CharacterVersionRepository, characterRepo database.CharacterRepository) *CharacterVersionService {
+	return &CharacterVersionService{
+		versionRepo:   versionRepo,
+		characterRepo: characterRepo,
+	}
+}
+
+// RecordChange snapshots a character after a change. Labels set with WithCharacterChange take
+// precedence, and the acting user is read from the request context. The change itself is already
+// saved, so a failure to record it is logged rather than failing the request. A nil service records nothing.
+func (s *CharacterVersionService) RecordChange(ctx context.Context, char *models.Character, changeType models.CharacterChangeType, reason string) {
+	if s == nil || char == nil {
+		return
+	}
+
+	if change, ok := ctx.Value(characterChangeKey{}).(characterChange); ok {
+		if change.changeType != "" {
+			changeType = change.changeType
+		}
+		if change.reason != "" {
+			reason = change.reason
+		}
+	}
+	changedBy, _ := auth.GetUserIDFromContext(ctx)
+
+	snapshot := models.CharacterSnapshot(*char)
+	version := &models.CharacterVersion{
+		CharacterID: char.ID,
+		ChangeType:  changeType,
+		Reason:      reason,
+		ChangedBy:   changedBy,
+		Snapshot:    &snapshot,
+	}
+	if err := s.versionRepo.CreateVersion(ctx, version); err != nil {
+		logger.WithContext(ctx).WithError(err).Error().
+			Str("character_id", char.ID).
+			Str("change_type", string(changeType)).
+			Msg("Failed to record character version")
+	}
+}
+
+// ListVersions returns a character's history, newest first
+func (s *CharacterVersionService) ListVersions(ctx context.Context, characterID string) ([]*models.CharacterVersion, error) {
+	return s.versionRepo.GetVersions(ctx, characterID)
+}
+
+// GetVersion returns a single version including its snapshot
+func (s *CharacterVersionService) GetVersion(ctx context.Context, characterID string, version int) (*models.CharacterVersion, error) {
+	v, err := s.versionRepo.GetVersion(ctx, characterID, version)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, fmt.Errorf("version %d not found", version)
+	}
+	return v, nil
+}
+
+// DiffVersions compares two versions field by field
+func (s *CharacterVersionService) DiffVersions(ctx context.Context, characterID string, from, to int) (*models.CharacterVersionDiff, error) {
+	fromVersion, err := s.GetVersion(ctx, characterID, from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := s.GetVersion(ctx, characterID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := DiffCharacterSnapshots(fromVersion.Snapshot, toVersion.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return &models.CharacterVersionDiff{
+		CharacterID: characterID,
+		FromVersion: from,
+		ToVersion:   to,
+		Changes:     changes,
+	}, nil
+}
+
+// RestoreVersion overwrites the character sheet with a prior version and records the restore
+func (s *CharacterVersionService) RestoreVersion(ctx context.Context, characterID string, version int) (*models.Character, error) {
+	target, err := s.GetVersion(ctx, characterID, version)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.characterRepo.GetByID(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+
+	restored := models.Character(*target.Snapshot)
+	// Identity and ownership always come from the live record
+	restored.ID = current.ID
+	restored.UserID = current.UserID
+	restored.CreatedAt = current.CreatedAt
+
+	if err := s.characterRepo.Update(ctx, &restored); err != nil {
+		return nil, err
+	}
+	s.RecordChange(ctx, &restored, models.CharacterChangeRestore, fmt.Sprintf("restored version %d", version))
+	return &restored, nil
+}
+
+// AuditSince lists the versions recorded after a point in time, such as the end of the last
+// session, together with the net field changes over that period
+func (s *CharacterVersionService) AuditSince(ctx context.Context, characterID string, since time.Time) (*models.CharacterAudit, error) {
+	versions, err := s.versionRepo.GetVersionsSince(ctx, characterID, since)
+	if err != nil {
+		return nil, err
+	}
+	audit := &models.CharacterAudit{
+		CharacterID: characterID,
+		Since:       since,
+		Versions:    versions,
+		Changes:     []models.CharacterFieldChange{},
+	}
+	if len(versions) == 0 {
+		return audit, nil
+	}
+
+	baseline, err := s.versionRepo.GetVersionAt(ctx, characterID, since)
+	if err != nil {
+		return nil, err
+	}
+	if baseline == nil {
+		// No history before the window; measure from the first version inside it
+		if baseline, err = s.GetVersion(ctx, characterID, versions[0].Version); err != nil {
+			return nil, err
+		}
+	}
+	latest, err := s.versionRepo.GetLatestVersion(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := DiffCharacterSnapshots(baseline.Snapshot, latest.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+	audit.Changes = changes
+	return audit, nil
+}
+
+// DiffCharacterSnapshots compares two snapshots by JSON field path, e.g. "attributes.strength".
+// Lists such as skills or spell slots are compared as a whole.
+func DiffCharacterSnapshots(before, after *models.CharacterSnapshot) ([]models.CharacterFieldChange, error) {
+	beforeFields, err := flattenSnapshot(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := flattenSnapshot(after)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]bool, len(beforeFields)+len(afterFields))
+	for field := range beforeFields {
+		fields[field] = true
+	}
+	for field := range afterFields {
+		fields[field] = true
+	}
+
+	changes := []models.CharacterFieldChange{}
+	for field := range fields {
+		if versionDiffIgnoredFields[field] {
+			continue
+		}
+		if !reflect.DeepEqual(beforeFields[field], afterFields[field]) {
+			changes = append(changes, models.CharacterFieldChange{
+				Field:  field,
+				Before: beforeFields[field],
+				After:  afterFields[field],
+			})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+func flattenSnapshot(snapshot *models.CharacterSnapshot) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if snapshot == nil {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	flattenFields("", raw, fields)
+	return fields, nil
+}
+
+func flattenFields(prefix string, value map[string]interface{}, out map[string]interface{}) {
+	for key, v := range value {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if nested, ok := v.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenFields(path, nested, out)
+			continue
+		}
+		out[path] = v
+	}
+}
@@ -0,0 +1,154 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/auth"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/internal/services"
+	"github.com/ctclostio/DnD-Game/backend/internal/services/mocks"
+)
+
+const (
+	testVersionCharacterID = "char-version-1"
+	testMethodCreateVer    = "CreateVersion"
+	testMethodGetVersion   = "GetVersion"
+)
+
+func snapshotOf(char models.Character) *models.CharacterSnapshot {
+	snapshot := models.CharacterSnapshot(char)
+	return &snapshot
+}
+
+func TestDiffCharacterSnapshots(t *testing.T) {
+	before := snapshotOf(models.Character{
+		Name: "Grog", Level: 3, HitPoints: 30,
+		Attributes: models.Attributes{Strength: 18, Dexterity: 12},
+		Skills:     []models.Skill{{Name: "Athletics", Proficiency: true}},
+		UpdatedAt:  time.Now().Add(-time.Hour),
+	})
+	after := snapshotOf(models.Character{
+		Name: "Grog", Level: 4, HitPoints: 30,
+		Attributes: models.Attributes{Strength: 20, Dexterity: 12},
+		Skills:     []models.Skill{{Name: "Athletics", Proficiency: true}, {Name: "Intimidation", Proficiency: true}},
+		UpdatedAt:  time.Now(),
+	})
+
+	changes, err := services.DiffCharacterSnapshots(before, after)
+	require.NoError(t, err)
+
+	fields := make([]string, len(changes))
+	for i, change := range changes {
+		fields[i] = change.Field
+	}
+	assert.Equal(t, []string{"attributes.strength", "level", "skills"}, fields)
+	assert.Equal(t, float64(18), changes[0].Before)
+	assert.Equal(t, float64(20), changes[0].After)
+}
+
+func TestCharacterVersionService_RecordChange(t *testing.T) {
+	versionRepo := new(mocks.MockCharacterVersionRepository)
+	svc := services.NewCharacterVersionService(versionRepo, new(mocks.MockCharacterRepository))
+
+	ctx := context.WithValue(context.Background(), auth.UserContextKey, &auth.Claims{UserID: "dm-1"})
+	ctx = services.WithCharacterChange(ctx, "", "fixed armor class")
+
+	versionRepo.On(testMethodCreateVer, ctx, mock.MatchedBy(func(v *models.CharacterVersion) bool {
+		return v.CharacterID == testVersionCharacterID &&
+			v.ChangeType == models.CharacterChangeUpdate &&
+			v.Reason == "fixed armor class" &&
+			v.ChangedBy == "dm-1" &&
+			v.Snapshot.ArmorClass == 16
+	})).Return(nil)
+
+	svc.RecordChange(ctx, &models.Character{ID: testVersionCharacterID, ArmorClass: 16}, models.CharacterChangeUpdate, "")
+	versionRepo.AssertExpectations(t)
+
+	var disabled *services.CharacterVersionService
+	assert.NotPanics(t, func() { disabled.RecordChange(ctx, &models.Character{}, models.CharacterChangeUpdate, "") })
+}
+
+func TestCharacterVersionService_RestoreVersion(t *testing.T) {
+	ctx := context.Background()
+	versionRepo := new(mocks.MockCharacterVersionRepository)
+	characterRepo := new(mocks.MockCharacterRepository)
+	svc := services.NewCharacterVersionService(versionRepo, characterRepo)
+
+	created := time.Now().Add(-48 * time.Hour)
+	current := &models.Character{ID: testVersionCharacterID, UserID: "player-1", Name: "Grog", Level: 5, CreatedAt: created}
+	versionRepo.On(testMethodGetVersion, ctx, testVersionCharacterID, 2).Return(&models.CharacterVersion{
+		CharacterID: testVersionCharacterID,
+		Version:     2,
+		Snapshot:    snapshotOf(models.Character{ID: testVersionCharacterID, UserID: "someone-else", Name: "Grog", Level: 3}),
+	}, nil)
+	characterRepo.On(testMethodGetByID, ctx, testVersionCharacterID).Return(current, nil)
+	characterRepo.On("Update", ctx, mock.MatchedBy(func(c *models.Character) bool {
+		return c.Level == 3 && c.UserID == "player-1" && c.CreatedAt.Equal(created)
+	})).Return(nil)
+	versionRepo.On(testMethodCreateVer, ctx, mock.MatchedBy(func(v *models.CharacterVersion) bool {
+		return v.ChangeType == models.CharacterChangeRestore && v.Reason == "restored version 2"
+	})).Return(nil)
+
+	restored, err := svc.RestoreVersion(ctx, testVersionCharacterID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, restored.Level)
+	versionRepo.AssertExpectations(t)
+	characterRepo.AssertExpectations(t)
+
+	versionRepo.On(testMethodGetVersion, ctx, testVersionCharacterID, 9).Return(nil, nil)
+	_, err = svc.RestoreVersion(ctx, testVersionCharacterID, 9)
+	assert.Error(t, err)
+}
+
+func TestCharacterVersionService_AuditSince(t *testing.T) {
+	ctx := context.Background()
+	versionRepo := new(mocks.MockCharacterVersionRepository)
+	svc := services.NewCharacterVersionService(versionRepo, new(mocks.MockCharacterRepository))
+	sessionEnded := time.Now().Add(-24 * time.Hour)
+
+	versionRepo.On("GetVersionsSince", ctx, testVersionCharacterID, sessionEnded).Return([]*models.CharacterVersion{
+		{Version: 4, ChangeType: models.CharacterChangeInventory, ChangedBy: "player-1"},
+		{Version: 5, ChangeType: models.CharacterChangeUpdate, ChangedBy: "player-1"},
+	}, nil)
+	versionRepo.On("GetVersionAt", ctx, testVersionCharacterID, sessionEnded).Return(&models.CharacterVersion{
+		Version:  3,
+		Snapshot: snapshotOf(models.Character{HitPoints: 12, MaxHitPoints: 30}),
+	}, nil)
+	versionRepo.On("GetLatestVersion", ctx, testVersionCharacterID).Return(&models.CharacterVersion{
+		Version:  5,
+		Snapshot: snapshotOf(models.Character{HitPoints: 30, MaxHitPoints: 45}),
+	}, nil)
+
+	audit, err := svc.AuditSince(ctx, testVersionCharacterID, sessionEnded)
+	require.NoError(t, err)
+	assert.Len(t, audit.Versions, 2)
+	require.Len(t, audit.Changes, 2)
+	assert.Equal(t, "hitPoints", audit.Changes[0].Field)
+	assert.Equal(t, "maxHitPoints", audit.Changes[1].Field)
+	assert.Equal(t, float64(45), audit.Changes[1].After)
+}
+
+func TestCharacterService_RecordsVersions(t *testing.T) {
+	ctx := context.Background()
+	characterRepo := new(mocks.MockCharacterRepository)
+	versionRepo := new(mocks.MockCharacterVersionRepository)
+	svc := services.NewCharacterService(characterRepo, nil, nil)
+	svc.SetVersionService(services.NewCharacterVersionService(versionRepo, characterRepo))
+
+	existing := &models.Character{ID: testVersionCharacterID, Level: 1, Class: "fighter", Attributes: models.Attributes{Constitution: 14}}
+	characterRepo.On(testMethodGetByID, ctx, testVersionCharacterID).Return(existing, nil)
+	characterRepo.On("Update", ctx, existing).Return(nil)
+	versionRepo.On(testMethodCreateVer, ctx, mock.MatchedBy(func(v *models.CharacterVersion) bool {
+		return v.ChangeType == models.CharacterChangeLevelUp && v.Reason == "reached level 2"
+	})).Return(nil).Once()
+
+	_, err := svc.LevelUp(ctx, testVersionCharacterID, 0, "")
+	require.NoError(t, err)
+	versionRepo.AssertExpectations(t)
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/database"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+const (
+	maxChatMessageLength = 2000
+	defaultChatPageSize  = 50
+	maxChatPageSize      = 100
+)
+
+// chatCommands maps the slash commands a message may start with to the kind of message
+// they send
+var chatCommands = map[string]models.ChatKind{
+	"/me":      models.ChatKindEmote,
+	"/em":      models.ChatKindEmote,
+	"/r":       models.ChatKindRoll,
+	"/roll":    models.ChatKindRoll,
+	"/narrate": models.ChatKindNarration,
+}
+
+// ChatService keeps each game session's chat: in and out of character talk, emotes, DM
+// narration, whispers and dice rolls made inline with /r
+type ChatService struct {
+	repo     database.ChatRepository
+	sessions database.GameSessionRepository
+	dice     *DiceRollService
+	events   *GameEventService
+}
+
+// NewChatService creates a new chat service
+func NewChatService(repo database.ChatRepository, sessions database.GameSessionRepository, dice *DiceRollService) *ChatService {
+	return &ChatService{
+		repo:     repo,
+		sessions: sessions,
+		dice:     dice,
+	}
+}
+
+// SetEventLog sets the session event log public chat is recorded in. Inline rolls are
+// recorded by the dice service and whispers are left out.
+func (s *ChatService) SetEventLog(events *GameEventService) {
+	s.events = events
+}
+
+// SendMessage stores a chat message from a member of the session, running its command
+// first: /r rolls through the dice service and /narrate is for the DM alone
+func (s *ChatService) SendMessage(ctx context.Context, sessionID, userID, username string, req *models.ChatMessageRequest) (*models.ChatMessage, error) {
+	text := strings.TrimSpace(req.Message)
+	if text == "" {
+		return nil, fmt.Errorf("message is required")
+	}
+	if len(text) > maxChatMessageLength {
+		return nil, fmt.Errorf("message cannot be longer than %d characters", maxChatMessageLength)
+	}
+	channel := req.Channel
+	if channel == "" {
+		channel = models.ChatChannelIC
+	}
+	if channel != models.ChatChannelIC && channel != models.ChatChannelOOC {
+		return nil, fmt.Errorf("unknown chat channel %q", channel)
+	}
+
+	session, err := s.sessions.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.sessionMembers(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	spectator := members[userID] == models.ParticipantRoleSpectator
+	if spectator {
+		if !session.TableRules().SpectatorChat {
+			return nil, fmt.Errorf("spectator chat is turned off for this session")
+		}
+		if req.Channel == "" {
+			channel = models.ChatChannelOOC
+		}
+	}
+
+	kind, body, err := parseChatCommand(text)
+	if err != nil {
+		return nil, err
+	}
+	message := &models.ChatMessage{
+		SessionID: sessionID,
+		UserID:    userID,
+		Username:  username,
+		Channel:   channel,
+		Kind:      kind,
+		Message:   body,
+	}
+	if message.Recipients, err = whisperRecipients(userID, req.Recipients, members); err != nil {
+		return nil, err
+	}
+	if spectator {
+		if err := checkSpectatorMessage(message); err != nil {
+			return nil, err
+		}
+	}
+
+	switch kind {
+	case models.ChatKindNarration:
+		if session.DMID != userID {
+			return nil, fmt.Errorf("only the DM can narrate")
+		}
+	case models.ChatKindRoll:
+		notation, purpose, _ := strings.Cut(body, " ")
+		roll := &models.DiceRoll{
+			GameSessionID: sessionID,
+			UserID:        userID,
+			RollNotation:  notation,
+			Purpose:       strings.TrimSpace(purpose),
+		}
+		if err := s.dice.RollDice(ctx, roll); err != nil {
+			return nil, err
+		}
+		message.DiceRollID = &roll.ID
+		message.Roll = roll
+	}
+
+	if err := s.repo.CreateMessage(ctx, message); err != nil {
+		return nil, err
+	}
+	if !message.IsWhisper() && kind != models.ChatKindRoll {
+		recordGameEvent(ctx, s.events, &models.GameEvent{
+			SessionID: sessionID,
+			Type:      models.GameEventChat,
+			PlayerID:  userID,
+			Data: map[string]interface{}{
+				"messageId": message.ID,
+				"channel":   string(message.Channel),
+				"kind":      string(message.Kind),
+				"message":   message.Message,
+			},
+			Timestamp: message.CreatedAt,
+		})
+	}
+	return message, nil
+}
+
+// GetHistory returns a page of the chat the user can see, newest first, with the dice
+// roll of each roll message
+func (s *ChatService) GetHistory(ctx context.Context, sessionID, userID string, filter models.ChatFilter) (*models.ChatPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultChatPageSize
+	}
+	if filter.Limit > maxChatPageSize {
+		filter.Limit = maxChatPageSize
+	}
+	pageSize := filter.Limit
+	// One extra message tells us whether there is another page
+	filter.Limit++
+
+	messages, err := s.repo.ListMessages(ctx, sessionID, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.ChatPage{Messages: messages}
+	if len(messages) > pageSize {
+		page.Messages = messages[:pageSize]
+		page.NextCursor = page.Messages[pageSize-1].ID
+	}
+	for _, message := range page.Messages {
+		if message.DiceRollID == nil {
+			continue
+		}
+		if roll, err := s.dice.GetRollByID(ctx, *message.DiceRollID); err == nil {
+			message.Roll = roll
+		}
+	}
+	return page, nil
+}
+
+// sessionMembers lists the users of a session by their role: its DM, its players and its
+// spectators
+func (s *ChatService) sessionMembers(ctx context.Context, session *models.GameSession) (map[string]models.ParticipantRole, error) {
+	participants, err := s.sessions.GetParticipants(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	members := make(map[string]models.ParticipantRole, len(participants)+1)
+	for _, participant := range participants {
+		members[participant.UserID] = participant.Role
+		if participant.Role == "" {
+			members[participant.UserID] = models.ParticipantRolePlayer
+		}
+	}
+	members[session.DMID] = models.ParticipantRoleDM
+	return members, nil
+}
+
+// checkSpectatorMessage keeps spectators, when the DM lets them chat at all, to talking and
+// emoting out of character where the whole table can see it
+func checkSpectatorMessage(message *models.ChatMessage) error {
+	switch {
+	case message.Channel != models.ChatChannelOOC:
+		return fmt.Errorf("spectators can only chat out of character")
+	case message.IsWhisper():
+		return fmt.Errorf("spectators cannot whisper")
+	case message.Kind != models.ChatKindMessage && message.Kind != models.ChatKindEmote:
+		return fmt.Errorf("spectators can only talk and emote")
+	}
+	return nil
+}
+
+// parseChatCommand splits a message into its kind and the text to show
+func parseChatCommand(text string) (models.ChatKind, string, error) {
+	if !strings.HasPrefix(text, "/") {
+		return models.ChatKindMessage, text, nil
+	}
+
+	command, body, _ := strings.Cut(text, " ")
+	kind, ok := chatCommands[strings.ToLower(command)]
+	if !ok {
+		return "", "", fmt.Errorf("unknown chat command %s", command)
+	}
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", "", fmt.Errorf("%s needs something to say", command)
+	}
+	return kind, body, nil
+}
+
+// whisperRecipients checks a whisper goes to other members of the session, dropping
+// repeats and the sender
+func whisperRecipients(senderID string, requested []string, members map[string]models.ParticipantRole) ([]string, error) {
+	if len(requested) == 0 {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool, len(requested))
+	recipients := make([]string, 0, len(requested))
+	for _, userID := range requested {
+		if userID == senderID || seen[userID] {
+			continue
+		}
+		if _, ok := members[userID]; !ok {
+			return nil, fmt.Errorf("user %s is not in this game session", userID)
+		}
+		seen[userID] = true
+		recipients = append(recipients, userID)
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("a whisper needs someone else to hear it")
+	}
+	return recipients, nil
+}
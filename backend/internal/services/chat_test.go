@@ -0,0 +1,314 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/internal/services/mocks"
+)
+
+// MockChatRepository mocks chat history storage
+type MockChatRepository struct {
+	mock.Mock
+}
+
+func (m *MockChatRepository) CreateMessage(ctx context.Context, message *models.ChatMessage) error {
+	args := m.Called(ctx, message)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockChatRepository) ListMessages(ctx context.Context, sessionID, viewerID string, filter models.ChatFilter) ([]*models.ChatMessage, error) {
+	args := m.Called(ctx, sessionID, viewerID, filter)
+	return mockSliceReturn[models.ChatMessage](args, 0, 1)
+}
+
+func createTestChatService(repo *MockChatRepository, sessions *mocks.MockGameSessionRepository, dice *mocks.MockDiceRollRepository) *ChatService {
+	return NewChatService(repo, sessions, NewDiceRollService(dice))
+}
+
+// setupChatSession runs session-1 for dm-1 with the given table rules and participants
+func setupChatSession(sessions *mocks.MockGameSessionRepository, tableRules map[string]interface{}, participants ...*models.GameParticipant) {
+	session := &models.GameSession{ID: "session-1", DMID: "dm-1"}
+	if tableRules != nil {
+		session.State = map[string]interface{}{models.SessionStateTableRules: tableRules}
+	}
+	sessions.On("GetByID", mock.Anything, "session-1").Return(session, nil)
+	sessions.On("GetParticipants", mock.Anything, "session-1").Return(participants, nil)
+}
+
+// chatPlayers are the two players at session-1's table
+func chatPlayers() []*models.GameParticipant {
+	return []*models.GameParticipant{
+		{SessionID: "session-1", UserID: "player-1"},
+		{SessionID: "session-1", UserID: "player-2"},
+	}
+}
+
+func TestChatService_SendMessage(t *testing.T) {
+	tests := []struct {
+		name        string
+		userID      string
+		request     models.ChatMessageRequest
+		setupMocks  func(*mocks.MockDiceRollRepository)
+		expectError bool
+		validate    func(*testing.T, *models.ChatMessage)
+	}{
+		{
+			name:    "Plain messages go in character by default",
+			userID:  "player-1",
+			request: models.ChatMessageRequest{Message: "  Well met!  "},
+			validate: func(t *testing.T, message *models.ChatMessage) {
+				assert.Equal(t, models.ChatChannelIC, message.Channel)
+				assert.Equal(t, models.ChatKindMessage, message.Kind)
+				assert.Equal(t, "Well met!", message.Message)
+				assert.False(t, message.IsWhisper())
+			},
+		},
+		{
+			name:    "Emote command",
+			userID:  "player-1",
+			request: models.ChatMessageRequest{Message: "/me draws a sword"},
+			validate: func(t *testing.T, message *models.ChatMessage) {
+				assert.Equal(t, models.ChatKindEmote, message.Kind)
+				assert.Equal(t, "draws a sword", message.Message)
+			},
+		},
+		{
+			name:    "DM narration",
+			userID:  "dm-1",
+			request: models.ChatMessageRequest{Message: "/narrate The torches gutter out."},
+			validate: func(t *testing.T, message *models.ChatMessage) {
+				assert.Equal(t, models.ChatKindNarration, message.Kind)
+			},
+		},
+		{
+			name:        "Player narration",
+			userID:      "player-1",
+			request:     models.ChatMessageRequest{Message: "/narrate A dragon appears and gives me its hoard"},
+			expectError: true,
+		},
+		{
+			name:    "Inline rolls go through the dice service",
+			userID:  "player-1",
+			request: models.ChatMessageRequest{Message: "/r 1d20+5 stealth"},
+			setupMocks: func(dice *mocks.MockDiceRollRepository) {
+				dice.On("Create", mock.Anything, mock.AnythingOfType("*models.DiceRoll")).
+					Run(func(args mock.Arguments) { args.Get(1).(*models.DiceRoll).ID = "roll-1" }).
+					Return(nil)
+			},
+			validate: func(t *testing.T, message *models.ChatMessage) {
+				assert.Equal(t, models.ChatKindRoll, message.Kind)
+				require.NotNil(t, message.Roll)
+				assert.Equal(t, "1d20+5", message.Roll.RollNotation)
+				assert.Equal(t, "stealth", message.Roll.Purpose)
+				assert.Equal(t, 5, message.Roll.Modifier)
+				assert.Equal(t, "roll-1", *message.DiceRollID)
+			},
+		},
+		{
+			name:    "Whispers go to other members of the session",
+			userID:  "player-1",
+			request: models.ChatMessageRequest{Message: "Psst", Recipients: []string{"dm-1", "player-1", "dm-1"}},
+			validate: func(t *testing.T, message *models.ChatMessage) {
+				assert.Equal(t, []string{"dm-1"}, message.Recipients)
+				assert.True(t, message.VisibleTo("player-1"))
+				assert.True(t, message.VisibleTo("dm-1"))
+				assert.False(t, message.VisibleTo("player-2"))
+			},
+		},
+		{
+			name:        "Whispering to a stranger",
+			userID:      "player-1",
+			request:     models.ChatMessageRequest{Message: "Psst", Recipients: []string{"stranger"}},
+			expectError: true,
+		},
+		{
+			name:        "Whispering only to yourself",
+			userID:      "player-1",
+			request:     models.ChatMessageRequest{Message: "Psst", Recipients: []string{"player-1"}},
+			expectError: true,
+		},
+		{
+			name:        "Empty message",
+			userID:      "player-1",
+			request:     models.ChatMessageRequest{Message: "   "},
+			expectError: true,
+		},
+		{
+			name:        "Unknown command",
+			userID:      "player-1",
+			request:     models.ChatMessageRequest{Message: "/dance wildly"},
+			expectError: true,
+		},
+		{
+			name:        "Command without text",
+			userID:      "player-1",
+			request:     models.ChatMessageRequest{Message: "/me"},
+			expectError: true,
+		},
+		{
+			name:        "Unknown channel",
+			userID:      "player-1",
+			request:     models.ChatMessageRequest{Message: "Hello", Channel: "dm"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockChatRepository)
+			sessions := new(mocks.MockGameSessionRepository)
+			dice := new(mocks.MockDiceRollRepository)
+			setupChatSession(sessions, nil, chatPlayers()...)
+			if !tt.expectError {
+				repo.On("CreateMessage", mock.Anything, mock.AnythingOfType("*models.ChatMessage")).Return(nil)
+			}
+			if tt.setupMocks != nil {
+				tt.setupMocks(dice)
+			}
+
+			service := createTestChatService(repo, sessions, dice)
+			message, err := service.SendMessage(context.Background(), "session-1", tt.userID, tt.userID, &tt.request)
+
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				tt.validate(t, message)
+			}
+
+			repo.AssertExpectations(t)
+			dice.AssertExpectations(t)
+		})
+	}
+}
+
+func TestChatService_SpectatorChat(t *testing.T) {
+	participants := []*models.GameParticipant{
+		{SessionID: "session-1", UserID: "player-1", Role: models.ParticipantRolePlayer},
+		{SessionID: "session-1", UserID: "viewer-1", Role: models.ParticipantRoleSpectator},
+	}
+
+	tests := []struct {
+		name          string
+		spectatorChat bool
+		userID        string
+		request       models.ChatMessageRequest
+		expectError   string
+		validate      func(*testing.T, *models.ChatMessage)
+	}{
+		{
+			name:        "Spectators are quiet until the DM turns spectator chat on",
+			userID:      "viewer-1",
+			request:     models.ChatMessageRequest{Message: "Hi chat!"},
+			expectError: "spectator chat is turned off for this session",
+		},
+		{
+			name:          "Spectators talk out of character",
+			spectatorChat: true,
+			userID:        "viewer-1",
+			request:       models.ChatMessageRequest{Message: "Hi chat!"},
+			validate: func(t *testing.T, message *models.ChatMessage) {
+				assert.Equal(t, models.ChatChannelOOC, message.Channel)
+			},
+		},
+		{
+			name:          "Spectators emote",
+			spectatorChat: true,
+			userID:        "viewer-1",
+			request:       models.ChatMessageRequest{Message: "/me cheers"},
+			validate: func(t *testing.T, message *models.ChatMessage) {
+				assert.Equal(t, models.ChatKindEmote, message.Kind)
+			},
+		},
+		{
+			name:          "Spectators cannot roll",
+			spectatorChat: true,
+			userID:        "viewer-1",
+			request:       models.ChatMessageRequest{Message: "/r 1d20"},
+			expectError:   "spectator",
+		},
+		{
+			name:          "Spectators cannot narrate",
+			spectatorChat: true,
+			userID:        "viewer-1",
+			request:       models.ChatMessageRequest{Message: "/narrate The dragon wins"},
+			expectError:   "spectators can only talk and emote",
+		},
+		{
+			name:          "Spectators cannot whisper",
+			spectatorChat: true,
+			userID:        "viewer-1",
+			request:       models.ChatMessageRequest{Message: "Psst", Recipients: []string{"player-1"}},
+			expectError:   "spectator",
+		},
+		{
+			name:          "Spectators cannot speak in character",
+			spectatorChat: true,
+			userID:        "viewer-1",
+			request:       models.ChatMessageRequest{Message: "I am the dragon", Channel: models.ChatChannelIC},
+			expectError:   "spectator",
+		},
+		{
+			name:    "Players can whisper to spectators",
+			userID:  "player-1",
+			request: models.ChatMessageRequest{Message: "Thanks for watching", Recipients: []string{"viewer-1"}},
+			validate: func(t *testing.T, message *models.ChatMessage) {
+				assert.Equal(t, []string{"viewer-1"}, message.Recipients)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockChatRepository)
+			sessions := new(mocks.MockGameSessionRepository)
+			dice := new(mocks.MockDiceRollRepository)
+			setupChatSession(sessions, map[string]interface{}{"spectator_chat": tt.spectatorChat}, participants...)
+			if tt.expectError == "" {
+				repo.On("CreateMessage", mock.Anything, mock.AnythingOfType("*models.ChatMessage")).Return(nil)
+			}
+
+			service := createTestChatService(repo, sessions, dice)
+			message, err := service.SendMessage(context.Background(), "session-1", tt.userID, tt.userID, &tt.request)
+
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+			} else {
+				require.NoError(t, err)
+				tt.validate(t, message)
+			}
+
+			repo.AssertExpectations(t)
+			dice.AssertExpectations(t)
+		})
+	}
+}
+
+func TestChatService_GetHistory(t *testing.T) {
+	repo := new(MockChatRepository)
+	dice := new(mocks.MockDiceRollRepository)
+	rollID := "roll-1"
+	messages := []*models.ChatMessage{
+		{ID: "m3", Kind: models.ChatKindRoll, DiceRollID: &rollID},
+		{ID: "m2", Kind: models.ChatKindMessage},
+		{ID: "m1", Kind: models.ChatKindMessage},
+	}
+	repo.On("ListMessages", mock.Anything, "session-1", "player-1", models.ChatFilter{Search: "gold", Limit: 3}).
+		Return(messages, nil)
+	dice.On("GetByID", mock.Anything, "roll-1").Return(&models.DiceRoll{ID: "roll-1", Total: 17}, nil)
+
+	service := createTestChatService(repo, new(mocks.MockGameSessionRepository), dice)
+	page, err := service.GetHistory(context.Background(), "session-1", "player-1", models.ChatFilter{Search: "gold", Limit: 2})
+
+	require.NoError(t, err)
+	require.Len(t, page.Messages, 2)
+	assert.Equal(t, "m2", page.NextCursor, "a full page points at the next one")
+	assert.Equal(t, 17, page.Messages[0].Roll.Total)
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/ctclostio/DnD-Game/backend/internal/auth"
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
 	"github.com/ctclostio/DnD-Game/backend/internal/game"
 	"github.com/ctclostio/DnD-Game/backend/internal/models"
 )
@@ -19,8 +21,11 @@ const (
 )
 
 type CombatService struct {
-	engine  *game.CombatEngine
-	combats map[string]*models.Combat // In-memory storage for active combats
+	engine          *game.CombatEngine
+	combats          map[string]*models.Combat // In-memory storage for active combats
+	resourceService  *CharacterResourceService
+	inventoryService *InventoryService
+	events           *GameEventService
 }
 
 func NewCombatService() *CombatService {
@@ -30,7 +35,36 @@ func NewCombatService() *CombatService {
 	}
 }
 
-func (s *CombatService) StartCombat(_ context.Context, gameSessionID string, combatants []models.Combatant) (*models.Combat, error) {
+// SetResourceService enables class resource usage (rage, ki, ...) from combat actions
+func (s *CombatService) SetResourceService(resourceService *CharacterResourceService) {
+	s.resourceService = resourceService
+}
+
+// SetInventoryService lets combatants use consumables from their character's inventory
+func (s *CombatService) SetInventoryService(inventoryService *InventoryService) {
+	s.inventoryService = inventoryService
+}
+
+// SetEventLog sets the session event log combat actions are recorded in
+func (s *CombatService) SetEventLog(events *GameEventService) {
+	s.events = events
+}
+
+func (s *CombatService) StartCombat(ctx context.Context, gameSessionID string, combatants []models.Combatant) (*models.Combat, error) {
+	// Characters fight with the bonuses of the items they have equipped and attuned
+	if s.inventoryService != nil {
+		for i := range combatants {
+			if combatants[i].CharacterID == "" {
+				continue
+			}
+			effects, err := s.inventoryService.GetItemEffects(ctx, combatants[i].CharacterID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load items of %s: %w", combatants[i].Name, err)
+			}
+			effects.ApplyToCombatant(&combatants[i])
+		}
+	}
+
 	combat, err := s.engine.StartCombat(gameSessionID, combatants)
 	if err != nil {
 		return nil, err
@@ -87,19 +121,70 @@ func (s *CombatService) ProcessAction(ctx context.Context, combatID string, requ
 	action := s.createCombatAction(combatID, combat.Round, request)
 
 	// Process the action
-	err = s.executeAction(combat, actor, request, action)
+	err = s.executeAction(ctx, combat, actor, request, action)
 	if err != nil {
 		return nil, err
 	}
 
-	// Auto-advance turn after most actions (except reactions and some special cases)
-	if s.shouldAdvanceTurn(request.Action) {
+	s.recordAction(ctx, combat, actor, action)
+
+	// Auto-advance turn after most actions (except reactions, bonus actions and some special cases)
+	if s.shouldAdvanceTurn(action.ActionType) {
 		s.engine.NextTurn(combat)
 	}
 
 	return action, nil
 }
 
+// recordAction writes a combat action to the session's event log, which combat analytics
+// are worked out from
+func (s *CombatService) recordAction(ctx context.Context, combat *models.Combat, actor *models.Combatant, action *models.CombatAction) {
+	target := s.findCombatant(combat, action.TargetID)
+	event := &models.GameEvent{
+		SessionID: combat.GameSessionID,
+		Type:      models.GameEventCombatAction,
+		Data: map[string]interface{}{
+			"combatId":    combat.ID,
+			"round":       action.Round,
+			"actorId":     actor.ID,
+			"actorType":   string(actor.Type),
+			"actor":       actor.Name,
+			"action":      string(action.ActionType),
+			"targetId":    action.TargetID,
+			"outcome":     actionOutcome(action, target),
+			"description": action.Description,
+			"damage":      action.Damage,
+			"healing":     action.Healing,
+		},
+	}
+	if !actor.IsVisible || (target != nil && !target.IsVisible) {
+		event.Data[models.GameEventDataHidden] = true
+	}
+	event.PlayerID, _ = auth.GetUserIDFromContext(ctx)
+	if actor.CharacterID != "" {
+		event.CharacterID = &actor.CharacterID
+	}
+	recordGameEvent(ctx, s.events, event)
+}
+
+// actionOutcome sums up how an attack went: a miss, a hit, a critical hit, or the blow
+// that dropped its target
+func actionOutcome(action *models.CombatAction, target *models.Combatant) string {
+	if action.ActionType != models.ActionTypeAttack {
+		return ""
+	}
+	switch {
+	case len(action.Damage) == 0:
+		return constants.OutcomeMiss
+	case target != nil && target.HP <= 0:
+		return constants.OutcomeKillingBlow
+	case len(action.Rolls) > 0 && action.Rolls[0].Critical:
+		return constants.ActionCritical
+	default:
+		return constants.OutcomeHit
+	}
+}
+
 func (s *CombatService) findCombatant(combat *models.Combat, combatantID string) *models.Combatant {
 	for i := range combat.Combatants {
 		if combat.Combatants[i].ID == combatantID {
@@ -122,10 +207,11 @@ func (s *CombatService) createCombatAction(combatID string, round int, request m
 }
 
 func (s *CombatService) shouldAdvanceTurn(actionType models.ActionType) bool {
-	return actionType != models.ActionTypeReaction && actionType != models.ActionTypeConcentration
+	return actionType != models.ActionTypeReaction && actionType != models.ActionTypeConcentration &&
+		actionType != models.ActionTypeUseResource && actionType != models.ActionTypeBonusAction
 }
 
-func (s *CombatService) executeAction(combat *models.Combat, actor *models.Combatant, request models.CombatRequest, action *models.CombatAction) error {
+func (s *CombatService) executeAction(ctx context.Context, combat *models.Combat, actor *models.Combatant, request models.CombatRequest, action *models.CombatAction) error {
 	// Handle implemented actions
 	switch request.Action {
 	case models.ActionTypeAttack:
@@ -138,6 +224,10 @@ func (s *CombatService) executeAction(combat *models.Combat, actor *models.Comba
 		return s.processDash(combat, actor, action)
 	case models.ActionTypeDodge:
 		return s.processDodge(combat, actor, action)
+	case models.ActionTypeUseResource:
+		return s.processUseResource(ctx, actor, request, action)
+	case models.ActionTypeUseItem:
+		return s.processUseItem(ctx, combat, actor, request, action)
 	case models.ActionTypeEndTurn:
 		action.Description = fmt.Sprintf("%s ends their turn", actor.Name)
 		return nil
@@ -154,7 +244,6 @@ func (s *CombatService) handleUnimplementedAction(actionType models.ActionType)
 		models.ActionTypeHide:          "hide action",
 		models.ActionTypeReady:         "ready action",
 		models.ActionTypeSearch:        "search action",
-		models.ActionTypeUseItem:       "use item action",
 		models.ActionTypeBonusAction:   "bonus action",
 		models.ActionTypeReaction:      "reaction",
 		models.ActionTypeConcentration: "concentration check",
@@ -298,6 +387,105 @@ func (s *CombatService) processDodge(_ *models.Combat, actor *models.Combatant,
 	return nil
 }
 
+func (s *CombatService) processUseResource(ctx context.Context, actor *models.Combatant, request models.CombatRequest, action *models.CombatAction) error {
+	if s.resourceService == nil {
+		return fmt.Errorf("class resources are not available")
+	}
+	if actor.CharacterID == "" {
+		return fmt.Errorf("%s has no class resources", actor.Name)
+	}
+	if request.ResourceKey == "" {
+		return fmt.Errorf("resource key is required")
+	}
+
+	resource, err := s.resourceService.UseResource(ctx, actor.CharacterID, request.ResourceKey, request.ResourceAmount)
+	if err != nil {
+		return err
+	}
+
+	action.Description = fmt.Sprintf("%s uses %s (%d/%d remaining)", actor.Name, resource.Name, resource.Current, resource.Max)
+	action.Effects = append(action.Effects, resource.Key)
+	return nil
+}
+
+// processUseItem drinks, throws or reads a consumable from the actor's inventory. The item's
+// effect lands on the target combatant, or the actor when there is none. A potion drunk as a
+// bonus action under the table rules is recorded as a bonus action and leaves the turn open.
+func (s *CombatService) processUseItem(ctx context.Context, combat *models.Combat, actor *models.Combatant, request models.CombatRequest, action *models.CombatAction) error {
+	if s.inventoryService == nil {
+		return fmt.Errorf("items are not available in combat")
+	}
+	if actor.CharacterID == "" {
+		return fmt.Errorf("%s has no inventory", actor.Name)
+	}
+	if request.ItemID == "" {
+		return fmt.Errorf("item ID is required")
+	}
+	target := actor
+	if request.TargetID != "" {
+		if target = s.findCombatant(combat, request.TargetID); target == nil {
+			return fmt.Errorf(errTargetNotFound)
+		}
+	}
+
+	req := &models.UseItemRequest{TargetID: target.ID, Spell: request.SpellID, SessionID: combat.GameSessionID}
+	spend := func(cost models.ActionType) error {
+		if err := s.engine.UseAction(actor, cost); err != nil {
+			return err
+		}
+		action.ActionType = cost
+		return nil
+	}
+	use, err := s.inventoryService.useItem(ctx, actor.CharacterID, request.ItemID, req, &combatantTarget{engine: s.engine, combatant: target}, spend)
+	if err != nil {
+		return err
+	}
+	if err := s.inventoryService.consumeItem(ctx, actor.CharacterID, use); err != nil {
+		return err
+	}
+
+	action.TargetID = target.ID
+	if use.ScrollCheck != nil {
+		action.Rolls = append(action.Rolls, use.ScrollCheck.Roll)
+	}
+	action.Rolls = append(action.Rolls, use.Rolls...)
+	if use.Save != nil {
+		action.Rolls = append(action.Rolls, use.Save.Roll)
+	}
+	action.Damage = use.Damage
+	action.Healing = use.Healing
+	if use.Spell != nil {
+		action.SpellName = use.Spell.Name
+		action.SpellLevel = use.Spell.Level
+		action.SpellDC = use.SpellSaveDC
+	}
+	action.Description = describeItemUse(actor.Name, use)
+	action.Effects = append(action.Effects, use.ItemID)
+	return nil
+}
+
+// combatantTarget applies an item's effect to a combatant through the combat engine
+type combatantTarget struct {
+	engine    *game.CombatEngine
+	combatant *models.Combatant
+}
+
+func (t *combatantTarget) name() string    { return t.combatant.Name }
+func (t *combatantTarget) armorClass() int { return t.combatant.AC }
+func (t *combatantTarget) hp() int         { return t.combatant.HP }
+
+func (t *combatantTarget) savingThrow(ability string, dc int) (*models.Roll, bool, error) {
+	return t.engine.SavingThrow(t.combatant, ability, dc, false, false)
+}
+
+func (t *combatantTarget) heal(amount int) {
+	healCombatant(t.combatant, amount)
+}
+
+func (t *combatantTarget) takeDamage(damage []models.Damage) int {
+	return t.engine.ApplyDamage(t.combatant, damage)
+}
+
 func (s *CombatService) EndCombat(ctx context.Context, combatID string) error {
 	combat, err := s.GetCombat(ctx, combatID)
 	if err != nil {
@@ -366,6 +554,11 @@ func (s *CombatService) HealCombatant(ctx context.Context, combatID, combatantID
 		return fmt.Errorf(errCombatantNotFound)
 	}
 
+	healCombatant(combatant, healing)
+	return nil
+}
+
+func healCombatant(combatant *models.Combatant, healing int) {
 	// Heal cannot exceed max HP
 	combatant.HP += healing
 	if combatant.HP > combatant.MaxHP {
@@ -376,6 +569,4 @@ func (s *CombatService) HealCombatant(ctx context.Context, combatID, combatantID
 	if combatant.HP > 0 && (combatant.DeathSaves.Successes > 0 || combatant.DeathSaves.Failures > 0) {
 		combatant.DeathSaves = models.DeathSaves{}
 	}
-
-	return nil
 }
@@ -17,6 +17,7 @@ import (
 type CombatAnalyticsService struct {
 	analyticsRepo database.CombatAnalyticsRepository
 	combatService *CombatService
+	events        *GameEventService
 }
 
 func NewCombatAnalyticsService(
@@ -29,25 +30,30 @@ func NewCombatAnalyticsService(
 	}
 }
 
+// SetEventLog sets the session event log combat actions are read from
+func (cas *CombatAnalyticsService) SetEventLog(events *GameEventService) {
+	cas.events = events
+}
+
 // TrackCombatAction logs a combat action for analytics
 func (cas *CombatAnalyticsService) TrackCombatAction(_ context.Context, action *models.CombatActionLog) error {
 	return cas.analyticsRepo.CreateCombatAction(action)
 }
 
-// FinalizeCombatAnalytics generates the final combat report when combat ends
+// FinalizeCombatAnalytics generates the final combat report when combat ends, from the
+// combat's actions in the session event log
 func (cas *CombatAnalyticsService) FinalizeCombatAnalytics(
-	_ context.Context,
+	ctx context.Context,
 	combat *models.Combat,
 	sessionID uuid.UUID,
 ) (*models.CombatAnalyticsReport, error) {
-	// Get all combat actions
 	// Convert Combat.ID string to uuid.UUID
 	combatUUID, err := uuid.Parse(combat.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse combat ID: %w", err)
 	}
 
-	actions, err := cas.analyticsRepo.GetCombatActions(combatUUID)
+	actions, err := cas.combatActions(ctx, combatUUID, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get combat actions: %w", err)
 	}
@@ -92,6 +98,74 @@ func (cas *CombatAnalyticsService) FinalizeCombatAnalytics(
 	}, nil
 }
 
+// combatActionEvent is the data CombatService records for a combat action
+type combatActionEvent struct {
+	CombatID  string          `json:"combatId"`
+	Round     int             `json:"round"`
+	ActorID   string          `json:"actorId"`
+	ActorType string          `json:"actorType"`
+	Action    string          `json:"action"`
+	TargetID  string          `json:"targetId"`
+	Outcome   string          `json:"outcome"`
+	Damage    []models.Damage `json:"damage"`
+	Healing   int             `json:"healing"`
+}
+
+// combatActions reads a combat's actions from its session's event log
+func (cas *CombatAnalyticsService) combatActions(ctx context.Context, combatID, sessionID uuid.UUID) ([]*models.CombatActionLog, error) {
+	if cas.events == nil {
+		return nil, fmt.Errorf("no session event log to read combat actions from")
+	}
+	events, err := cas.events.GetSessionEvents(ctx, sessionID.String(), models.GameEventFilter{
+		Types: []string{models.GameEventCombatAction},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	actions := make([]*models.CombatActionLog, 0, len(events))
+	for _, event := range events {
+		var data combatActionEvent
+		encoded, err := json.Marshal(event.Data)
+		if err != nil || json.Unmarshal(encoded, &data) != nil || data.CombatID != combatID.String() {
+			continue
+		}
+		actions = append(actions, data.actionLog(combatID, event))
+	}
+	return actions, nil
+}
+
+// actionLog converts a recorded combat action to the form the analytics work on. Spells
+// count as spells whatever they were cast as, and healing as positive damage.
+func (data *combatActionEvent) actionLog(combatID uuid.UUID, event *models.GameEvent) *models.CombatActionLog {
+	action := &models.CombatActionLog{
+		CombatID:    combatID,
+		RoundNumber: data.Round,
+		ActorID:     data.ActorID,
+		ActorType:   data.ActorType,
+		ActionType:  data.Action,
+		Outcome:     data.Outcome,
+		Timestamp:   event.Timestamp,
+	}
+	action.ID, _ = uuid.Parse(event.ID)
+	if data.TargetID != "" {
+		action.TargetID = &data.TargetID
+	}
+	for _, damage := range data.Damage {
+		action.DamageDealt += damage.Amount
+	}
+
+	switch models.ActionType(data.Action) {
+	case models.ActionTypeCast, models.ActionTypeCastSpell:
+		action.ActionType = constants.ActionTypeSpell
+	}
+	if data.Healing > 0 && action.DamageDealt == 0 {
+		action.ActionType = constants.ActionHeal
+		action.DamageDealt = data.Healing
+	}
+	return action
+}
+
 func (cas *CombatAnalyticsService) calculateCombatAnalytics(
 	combat *models.Combat,
 	sessionID uuid.UUID,
@@ -279,7 +353,7 @@ func (cas *CombatAnalyticsService) updateAttackOutcome(stats *models.CombatantAn
 	case constants.ActionCritical:
 		stats.AttacksHit++
 		stats.CriticalHits++
-	case "miss":
+	case constants.OutcomeMiss:
 		stats.AttacksMissed++
 	case "critical_miss":
 		stats.AttacksMissed++
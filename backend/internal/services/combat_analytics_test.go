@@ -197,22 +197,42 @@ func TestCombatAnalytics_FinalizeCombatAnalytics(t *testing.T) {
 			},
 		}
 
-		targetID := "npc-1"
-		actions := []*models.CombatActionLog{
+		// Actions are read back from the event log as the database decodes them
+		events := []*models.GameEvent{
 			{
-				ID:          uuid.New(),
-				CombatID:    combatID,
-				ActorID:     testCharacterID1,
-				ActorType:   "character",
-				ActionType:  "attack",
-				TargetID:    &targetID,
-				DamageDealt: 20,
-				Outcome:     "killing_blow",
-				RoundNumber: 5,
+				ID:        uuid.New().String(),
+				SessionID: sessionID.String(),
+				Type:      models.GameEventCombatAction,
+				Data: map[string]interface{}{
+					"combatId":  combatID.String(),
+					"round":     float64(5),
+					"actorId":   testCharacterID1,
+					"actorType": "character",
+					"action":    "attack",
+					"targetId":  "npc-1",
+					"outcome":   "killing_blow",
+					"damage":    []interface{}{map[string]interface{}{"amount": float64(20), "type": "slashing"}},
+				},
+			},
+			{
+				ID:        uuid.New().String(),
+				SessionID: sessionID.String(),
+				Type:      models.GameEventCombatAction,
+				Data: map[string]interface{}{
+					"combatId": uuid.New().String(),
+					"actorId":  testCharacterID1,
+					"action":   "attack",
+					"damage":   []interface{}{map[string]interface{}{"amount": float64(99)}},
+				},
 			},
 		}
+		eventRepo := new(MockGameEventRepository)
+		eventRepo.On("List", mock.Anything, models.GameEventFilter{
+			SessionID: sessionID.String(),
+			Types:     []string{models.GameEventCombatAction},
+		}).Return(events, nil)
+		analytics.SetEventLog(NewGameEventService(eventRepo))
 
-		mockRepo.On("GetCombatActions", combatID).Return(actions, nil)
 		mockRepo.On("CreateCombatAnalytics", mock.AnythingOfType("*models.CombatAnalytics")).Return(nil)
 		mockRepo.On("CreateCombatantAnalytics", mock.AnythingOfType("*models.CombatantAnalytics")).Return(nil).Times(2)
 		mockRepo.On("UpdateCombatAnalytics", mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("map[string]interface {}")).Return(nil)
@@ -223,8 +243,8 @@ func TestCombatAnalytics_FinalizeCombatAnalytics(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, result)
 		require.NotNil(t, result.Analytics)
-		require.Equal(t, 20, result.Analytics.TotalDamageDealt) // Based on single action with 20 damage
-		require.Equal(t, 0, result.Analytics.TotalHealingDone)  // No healing actions provided
+		require.Equal(t, 20, result.Analytics.TotalDamageDealt, "only this combat's actions count")
+		require.Equal(t, 0, result.Analytics.TotalHealingDone) // No healing actions provided
 		require.Equal(t, testCharacterID1, result.Analytics.MVPID)
 
 		mockRepo.AssertExpectations(t)
@@ -245,9 +265,10 @@ func TestCombatAnalytics_FinalizeCombatAnalytics(t *testing.T) {
 			Combatants:    []models.Combatant{},
 		}
 
-		actions := []*models.CombatActionLog{}
+		eventRepo := new(MockGameEventRepository)
+		eventRepo.On("List", mock.Anything, mock.Anything).Return([]*models.GameEvent{}, nil)
+		analytics.SetEventLog(NewGameEventService(eventRepo))
 
-		mockRepo.On("GetCombatActions", combatID).Return(actions, nil)
 		mockRepo.On("CreateCombatAnalytics", mock.AnythingOfType("*models.CombatAnalytics")).Return(nil)
 		mockRepo.On("UpdateCombatAnalytics", mock.AnythingOfType("uuid.UUID"), mock.AnythingOfType("map[string]interface {}")).Return(nil)
 
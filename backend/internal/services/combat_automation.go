@@ -14,6 +14,7 @@ import (
 	"github.com/ctclostio/DnD-Game/backend/internal/database"
 	"github.com/ctclostio/DnD-Game/backend/internal/models"
 	"github.com/ctclostio/DnD-Game/backend/pkg/dice"
+	"github.com/ctclostio/DnD-Game/backend/pkg/logger"
 )
 
 // Error message constants
@@ -26,6 +27,7 @@ type CombatAutomationService struct {
 	characterRepo database.CharacterRepository
 	npcRepo       database.NPCRepository
 	diceRoller    *dice.Roller
+	loot          *LootService
 }
 
 func NewCombatAutomationService(
@@ -41,9 +43,14 @@ func NewCombatAutomationService(
 	}
 }
 
+// SetLootService puts the loot and experience of auto-resolved fights into loot pools for the party
+func (cas *CombatAutomationService) SetLootService(loot *LootService) {
+	cas.loot = loot
+}
+
 // AutoResolveCombat performs quick combat resolution for minor encounters
 func (cas *CombatAutomationService) AutoResolveCombat(
-	_ context.Context,
+	ctx context.Context,
 	sessionID uuid.UUID,
 	characters []*models.Character,
 	req models.AutoResolveRequest,
@@ -111,6 +118,19 @@ func (cas *CombatAutomationService) AutoResolveCombat(
 		return nil, fmt.Errorf("failed to save combat resolution: %w", err)
 	}
 
+	// The resolution is already saved, so a loot pool that can't be made is logged for the DM
+	// to raise by hand rather than failing the fight
+	if cas.loot != nil {
+		pool, err := cas.loot.PoolFromAutoResolution(ctx, resolution, characters)
+		if err != nil {
+			logger.WithContext(ctx).WithError(err).Error().
+				Str("resolution_id", resolution.ID.String()).
+				Msg("Failed to create loot pool for auto-resolved combat")
+		} else if pool != nil {
+			resolution.LootPoolID = &pool.ID
+		}
+	}
+
 	return resolution, nil
 }
 
@@ -353,7 +373,7 @@ func (cas *CombatAutomationService) determineCombatOutcome(partyStrength, encoun
 	} else if strengthRatio > 0.7 {
 		return constants.OutcomeCostlyVictory, 5 + rand.Intn(5)
 	} else if strengthRatio > 0.5 {
-		return constants.ActionTypeRetreat, 3 + rand.Intn(3)
+		return constants.OutcomeRetreat, 3 + rand.Intn(3)
 	}
 	return constants.OutcomeDefeat, 4 + rand.Intn(4)
 }
@@ -390,7 +410,7 @@ func (cas *CombatAutomationService) calculateHPLossPercent(outcome string) float
 		return 0.2 + rand.Float64()*0.2 // 20-40%
 	case constants.OutcomeCostlyVictory:
 		return 0.4 + rand.Float64()*0.3 // 40-70%
-	case constants.ActionTypeRetreat:
+	case constants.OutcomeRetreat:
 		return 0.3 + rand.Float64()*0.3 // 30-60%
 	case constants.OutcomeDefeat:
 		return 0.6 + rand.Float64()*0.3 // 60-90%
@@ -605,7 +625,7 @@ func (cas *CombatAutomationService) generateNarrativeSummary(
 			"Bloodied but unbowed, the adventurers managed to defeat their foes after a grueling combat.",
 			"Victory came at a cost, with several party members bearing serious wounds.",
 		},
-		constants.ActionTypeRetreat: {
+		constants.OutcomeRetreat: {
 			"Recognizing the danger, the party made a tactical withdrawal from the battlefield.",
 			"The adventurers fought a retreating action, escaping with their lives if not their pride.",
 			"Discretion proved the better part of valor as the party retreated from overwhelming odds.",
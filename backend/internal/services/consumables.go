@@ -0,0 +1,472 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/auth"
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/database"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/dice"
+)
+
+// itemTarget is the creature a consumable's effect lands on: a combatant during combat,
+// a character outside it
+type itemTarget interface {
+	name() string
+	armorClass() int
+	hp() int
+	savingThrow(ability string, dc int) (*models.Roll, bool, error)
+	heal(amount int)
+	takeDamage(damage []models.Damage) int
+}
+
+// SetSpellService lets characters read spell scrolls, which needs the spell catalog and class lists
+func (s *InventoryService) SetSpellService(spells *SpellManagementService) {
+	s.spells = spells
+}
+
+// SetGameSessionRepository enables table rules, such as drinking potions as a bonus action
+func (s *InventoryService) SetGameSessionRepository(sessionRepo database.GameSessionRepository) {
+	s.sessionRepo = sessionRepo
+}
+
+// UseItem uses a consumable outside combat. A potion is drunk, a flask thrown or a scroll read
+// at the target character, who is the user when req.TargetID is empty and must otherwise play
+// in req.SessionID with the user. The item is used up and the target's hit points saved in one
+// transaction.
+func (s *InventoryService) UseItem(ctx context.Context, characterID, itemID string, req *models.UseItemRequest) (*models.ItemUse, error) {
+	targetID := req.TargetID
+	if targetID == "" {
+		targetID = characterID
+	}
+	if targetID != characterID {
+		if err := s.requireSameSession(ctx, req.SessionID, characterID, targetID); err != nil {
+			return nil, err
+		}
+	}
+	char, err := s.characterRepo.GetByID(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if char == nil {
+		return nil, fmt.Errorf("target character not found")
+	}
+
+	inventory, err := s.inventoryRepo.GetCharacterInventory(targetID)
+	if err != nil {
+		return nil, err
+	}
+	target := &characterTarget{char: char, effects: models.ApplyItemBonuses(char, inventory), roller: s.roller}
+
+	use, err := s.useItem(ctx, characterID, itemID, req, target, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	txn := &models.EconomyTransaction{
+		Type:        models.LedgerEntryUse,
+		Description: "used " + use.ItemName,
+		SessionID:   req.SessionID,
+		Items:       []models.ItemMovement{{CharacterID: characterID, ItemID: itemID, Quantity: -1}},
+	}
+	txn.CreatedBy, _ = auth.GetUserIDFromContext(ctx)
+	changed := use.Healing != 0 || use.DamageTaken != 0
+	if changed {
+		txn.ItemTarget = char
+	}
+	if _, err := s.applyTransaction(ctx, txn); err != nil {
+		return nil, err
+	}
+	if changed {
+		s.versions.RecordChange(ctx, char, models.CharacterChangeUpdate, fmt.Sprintf("%s used on %s", use.ItemName, char.Name))
+	}
+	return use, nil
+}
+
+// requireSameSession checks both characters play in the game session, so items are only
+// ever used on the user's own party
+func (s *InventoryService) requireSameSession(ctx context.Context, sessionID, characterID, targetID string) error {
+	if sessionID == "" || s.sessionRepo == nil {
+		return fmt.Errorf("items can only be used on another character in a game session you share")
+	}
+	participants, err := s.sessionRepo.GetParticipants(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	joined := make(map[string]bool, len(participants))
+	for _, p := range participants {
+		if p.CharacterID != nil {
+			joined[*p.CharacterID] = true
+		}
+	}
+	if !joined[characterID] || !joined[targetID] {
+		return fmt.Errorf("target character is not in this game session")
+	}
+	return nil
+}
+
+// consumeItem uses up one of an item used in combat
+func (s *InventoryService) consumeItem(ctx context.Context, characterID string, use *models.ItemUse) error {
+	if err := s.inventoryRepo.RemoveItemFromInventory(characterID, use.ItemID, 1); err != nil {
+		return err
+	}
+	s.recordInventoryChange(ctx, characterID, "used "+use.ItemName)
+	return nil
+}
+
+// useItem is the pipeline every consumable goes through, in combat or out. It checks the
+// item, spends the action it costs, makes any scroll check and rolls its effect onto the
+// target; the caller then uses one up. spend is nil outside combat, where actions are not counted.
+func (s *InventoryService) useItem(ctx context.Context, characterID, itemID string, req *models.UseItemRequest, target itemTarget, spend func(models.ActionType) error) (*models.ItemUse, error) {
+	inventory, err := s.inventoryRepo.GetCharacterInventory(characterID)
+	if err != nil {
+		return nil, err
+	}
+	inv := s.findItemInInventory(inventory, itemID)
+	if inv == nil || inv.Item == nil {
+		return nil, fmt.Errorf("item not found in inventory")
+	}
+	item := inv.Item
+	if !item.IsConsumable() {
+		return nil, fmt.Errorf("%s is not a consumable", item.Name)
+	}
+	if inv.Location != "" && inv.Location != models.ItemLocationCarried {
+		return nil, fmt.Errorf("%s is not being carried", item.Name)
+	}
+
+	use := &models.ItemUse{ItemID: itemID, ItemName: item.Name, Target: target.name()}
+	if use.Cost, err = s.itemUseCost(ctx, item, req.SessionID); err != nil {
+		return nil, err
+	}
+
+	effect := item.ConsumableEffect()
+	var scroll *scrollReading
+	if item.IsSpellScroll() {
+		if scroll, err = s.readScroll(ctx, characterID, item, req.Spell); err != nil {
+			return nil, err
+		}
+		effect = scroll.effect
+		spell := scroll.spell.ToSpell()
+		spell.Level = scroll.level
+		use.Spell = &spell
+		use.SpellSaveDC = effect.SaveDC
+		if effect.AttackBonus != nil {
+			use.SpellAttackBonus = *effect.AttackBonus
+		}
+	} else if effect.IsZero() {
+		return nil, fmt.Errorf("%s has no effect to roll", item.Name)
+	}
+
+	if spend != nil {
+		if err := spend(use.Cost); err != nil {
+			return nil, err
+		}
+	}
+
+	if scroll != nil && scroll.check != nil {
+		if err := s.rollAbilityCheck(scroll.check, scroll.checkModifier); err != nil {
+			return nil, err
+		}
+		use.ScrollCheck = scroll.check
+		use.Wasted = !scroll.check.Success
+	}
+
+	use.QuantityRemaining = inv.Quantity - 1
+
+	if !use.Wasted {
+		if err := s.resolveItemEffect(effect, target, use); err != nil {
+			return nil, err
+		}
+	}
+	use.TargetHP = target.hp()
+	return use, nil
+}
+
+// itemUseCost is the action an item takes to use, after the session's table rules
+func (s *InventoryService) itemUseCost(ctx context.Context, item *models.Item, sessionID string) (models.ActionType, error) {
+	cost := item.UseCost()
+	if cost == models.ActionTypeBonusAction || !item.IsPotion() || sessionID == "" || s.sessionRepo == nil {
+		return cost, nil
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return "", err
+	}
+	if session != nil && session.TableRules().PotionsAsBonusAction {
+		return models.ActionTypeBonusAction, nil
+	}
+	return cost, nil
+}
+
+// scrollReading is a scroll's spell as its reader would cast it, with the check they must
+// pass first when they cannot cast it outright
+type scrollReading struct {
+	spell         *SpellDefinition
+	level         int
+	effect        models.ItemEffect
+	check         *models.AbilityCheck
+	checkModifier int
+}
+
+// readScroll works out what happens when the character reads a spell scroll. A caster reads
+// a spell on their class list of a level they can cast without trouble; anyone else, caster
+// or not, must pass an ability check of 10 + the spell's level or the spell fades.
+func (s *InventoryService) readScroll(ctx context.Context, characterID string, item *models.Item, spellName string) (*scrollReading, error) {
+	if s.spells == nil {
+		return nil, fmt.Errorf("spell scrolls are not available")
+	}
+	name, level := item.ScrollSpell()
+	if name == "" {
+		name = spellName
+	}
+	if name == "" {
+		return nil, fmt.Errorf("say which spell is written on the %s", item.Name)
+	}
+	definition := s.spells.catalog.Find(name)
+	if definition == nil {
+		return nil, fmt.Errorf("unknown spell: %s", name)
+	}
+	if definition.Level > level {
+		return nil, fmt.Errorf("%s cannot hold %s, a level %d spell", item.Name, definition.Name, definition.Level)
+	}
+
+	reader, err := s.characterRepo.GetByID(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+	if reader == nil {
+		return nil, fmt.Errorf("character not found")
+	}
+
+	reading := &scrollReading{spell: definition, level: level}
+	castingModifier := 0
+	checkAbility := constants.AbilityIntelligence
+	profile, err := s.spells.profileFor(reader)
+	if err != nil {
+		profile = nil // not a spellcaster
+	} else {
+		checkAbility = strings.ToLower(profile.ability)
+		castingModifier = getModifier(abilityScore(reader, profile.ability))
+	}
+	if profile == nil || !profile.onList(definition) || level > maxSpellSlotLevel(reader) {
+		reading.check = &models.AbilityCheck{Ability: checkAbility, DC: 10 + level}
+		reading.checkModifier = getModifier(abilityScore(reader, checkAbility))
+	}
+
+	reading.effect = scrollSpellEffect(definition, item, castingModifier)
+	return reading, nil
+}
+
+// scrollSpellEffect is what a scroll's spell does, cast with the scroll's save DC and attack bonus
+func scrollSpellEffect(spell *SpellDefinition, item *models.Item, castingModifier int) models.ItemEffect {
+	scroll := item.ConsumableEffect()
+	effect := models.ItemEffect{SaveDC: scroll.SaveDC}
+	if spell.Healing != nil {
+		effect.Healing = spell.Healing.Dice
+		if spell.Healing.Modifier == "spellcasting" {
+			effect.HealingBonus = castingModifier
+		}
+	}
+	if spell.Damage != nil && spell.Damage.Trigger == "" {
+		effect.Damage = spell.Damage.Dice
+		effect.DamageType = models.DamageType(strings.ToLower(spell.Damage.Type))
+		if spell.Damage.PerDart {
+			effect.Darts = spell.Damage.Darts
+		}
+	}
+	if spell.SavingThrow != nil {
+		effect.SaveAbility = strings.ToLower(spell.SavingThrow.Ability)
+		effect.HalfOnSave = strings.Contains(strings.ToLower(spell.SavingThrow.Effect), "half")
+	}
+	if strings.Contains(spell.AttackType, "melee") || strings.Contains(spell.AttackType, "ranged") {
+		bonus := 0
+		if scroll.AttackBonus != nil {
+			bonus = *scroll.AttackBonus
+		}
+		effect.AttackBonus = &bonus
+	}
+	return effect
+}
+
+func (s *InventoryService) rollAbilityCheck(check *models.AbilityCheck, modifier int) error {
+	result, err := s.roller.Roll("1d20")
+	if err != nil {
+		return err
+	}
+	check.Roll = models.Roll{
+		Type:       models.RollTypeAbilityCheck,
+		Dice:       "1d20",
+		Modifier:   modifier,
+		Result:     result.Total + modifier,
+		Individual: result.Dice,
+	}
+	check.Success = check.Roll.Result >= check.DC
+	return nil
+}
+
+// resolveItemEffect rolls an item's effect and applies it to the target. Damage that needs
+// an attack roll must beat the target's AC; a successful save halves or negates it.
+func (s *InventoryService) resolveItemEffect(effect models.ItemEffect, target itemTarget, use *models.ItemUse) error {
+	if effect.Healing != "" {
+		roll, err := s.rollEffectDice(models.RollTypeHealing, effect.Healing, effect.HealingBonus, false)
+		if err != nil {
+			return err
+		}
+		use.Rolls = append(use.Rolls, *roll)
+		use.Healing = max(0, roll.Result)
+		target.heal(use.Healing)
+	}
+	if effect.Damage == "" {
+		return nil
+	}
+
+	critical := false
+	if effect.AttackBonus != nil {
+		result, err := s.roller.Roll("1d20")
+		if err != nil {
+			return err
+		}
+		attack := models.Roll{
+			Type:         models.RollTypeAttack,
+			Dice:         "1d20",
+			Modifier:     *effect.AttackBonus,
+			Result:       result.Total + *effect.AttackBonus,
+			Individual:   result.Dice,
+			Critical:     result.Dice[0] == 20,
+			CriticalMiss: result.Dice[0] == 1,
+		}
+		use.Rolls = append(use.Rolls, attack)
+		if attack.CriticalMiss || (!attack.Critical && attack.Result < target.armorClass()) {
+			use.Missed = true
+			return nil
+		}
+		critical = attack.Critical
+	}
+
+	amount := 0
+	for i := 0; i < max(1, effect.Darts); i++ {
+		roll, err := s.rollEffectDice(models.RollTypeDamage, effect.Damage, 0, critical)
+		if err != nil {
+			return err
+		}
+		use.Rolls = append(use.Rolls, *roll)
+		amount += max(0, roll.Result)
+	}
+
+	if effect.SaveAbility != "" && effect.SaveDC > 0 {
+		roll, success, err := target.savingThrow(effect.SaveAbility, effect.SaveDC)
+		if err != nil {
+			return err
+		}
+		use.Save = &models.ItemSave{Ability: effect.SaveAbility, DC: effect.SaveDC, Roll: *roll, Success: success}
+		if success {
+			if effect.HalfOnSave {
+				amount /= 2
+			} else {
+				amount = 0
+			}
+		}
+	}
+	if amount == 0 {
+		return nil
+	}
+
+	use.Damage = []models.Damage{{Amount: amount, Type: effect.DamageType}}
+	use.DamageTaken = target.takeDamage(use.Damage)
+	return nil
+}
+
+// rollEffectDice rolls effect dice such as "2d4+2", doubling the dice on a critical hit.
+// A bare number, as Revivify's "1", is a fixed amount.
+func (s *InventoryService) rollEffectDice(rollType models.RollType, notation string, bonus int, critical bool) (*models.Roll, error) {
+	notation = strings.ReplaceAll(notation, " ", "")
+	if fixed, err := strconv.Atoi(notation); err == nil {
+		return &models.Roll{Type: rollType, Dice: notation, Modifier: bonus, Result: fixed + bonus}, nil
+	}
+	if critical {
+		if count, rest, ok := strings.Cut(notation, "d"); ok {
+			if n, err := strconv.Atoi(count); err == nil {
+				notation = fmt.Sprintf("%dd%s", n*2, rest)
+			}
+		}
+	}
+
+	result, err := s.roller.Roll(notation)
+	if err != nil {
+		return nil, fmt.Errorf("invalid effect dice %q: %w", notation, err)
+	}
+	return &models.Roll{
+		Type:       rollType,
+		Dice:       notation,
+		Modifier:   result.Modifier + bonus,
+		Result:     result.Total + bonus,
+		Individual: result.Dice,
+		Critical:   critical,
+	}, nil
+}
+
+// describeItemUse sums up an item use for a combat log
+func describeItemUse(user string, use *models.ItemUse) string {
+	switch {
+	case use.Wasted:
+		return fmt.Sprintf("%s reads %s but the spell fades from the scroll", user, use.ItemName)
+	case use.Missed:
+		return fmt.Sprintf("%s uses %s on %s and misses", user, use.ItemName, use.Target)
+	case use.Healing > 0:
+		return fmt.Sprintf("%s uses %s on %s, restoring %d hit points", user, use.ItemName, use.Target, use.Healing)
+	case use.DamageTaken > 0:
+		return fmt.Sprintf("%s uses %s on %s for %d damage", user, use.ItemName, use.Target, use.DamageTaken)
+	}
+	return fmt.Sprintf("%s uses %s", user, use.ItemName)
+}
+
+// characterTarget applies an item's effect to a character outside combat. Attacks and
+// saves use the character's statistics with their items' bonuses.
+type characterTarget struct {
+	char    *models.Character
+	effects *models.ItemEffects
+	roller  *dice.Roller
+}
+
+func (t *characterTarget) name() string    { return t.char.Name }
+func (t *characterTarget) armorClass() int { return t.effects.ArmorClass }
+func (t *characterTarget) hp() int         { return t.char.HitPoints }
+
+func (t *characterTarget) savingThrow(ability string, dc int) (*models.Roll, bool, error) {
+	result, err := t.roller.Roll("1d20")
+	if err != nil {
+		return nil, false, err
+	}
+	modifier := t.effects.SavingThrowModifier(ability)
+	roll := &models.Roll{
+		Type:         models.RollTypeSavingThrow,
+		Dice:         "1d20",
+		Modifier:     modifier,
+		Result:       result.Total + modifier,
+		Individual:   result.Dice,
+		Critical:     result.Dice[0] == 20,
+		CriticalMiss: result.Dice[0] == 1,
+	}
+	return roll, roll.Result >= dc || roll.Critical, nil
+}
+
+func (t *characterTarget) heal(amount int) {
+	t.char.HitPoints = min(t.char.MaxHitPoints, t.char.HitPoints+amount)
+}
+
+// takeDamage spends temporary hit points first; hit points never drop below 0
+func (t *characterTarget) takeDamage(damage []models.Damage) int {
+	total := 0
+	for _, d := range damage {
+		total += d.Amount
+	}
+	absorbed := min(t.char.TempHitPoints, total)
+	t.char.TempHitPoints -= absorbed
+	t.char.HitPoints = max(0, t.char.HitPoints-(total-absorbed))
+	return total
+}
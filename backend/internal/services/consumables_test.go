@@ -0,0 +1,294 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/internal/services"
+	"github.com/ctclostio/DnD-Game/backend/internal/services/mocks"
+)
+
+func healingPotion() *models.Item {
+	return &models.Item{
+		ID:   "healing_potion",
+		Name: "Healing Potion",
+		Type: models.ItemTypeConsumable,
+		Properties: models.ItemProperties{
+			"healing": "2d4+2", "consumable": true, "action_type": "action",
+		},
+	}
+}
+
+func alchemistsFire() *models.Item {
+	return &models.Item{
+		ID:   "alchemists_fire",
+		Name: "Alchemist's Fire",
+		Type: models.ItemTypeConsumable,
+		Properties: models.ItemProperties{
+			"consumable": true, "damage": "1d4", "damage_type": "fire",
+			"save_ability": "dexterity", "save_dc": float64(10),
+		},
+	}
+}
+
+func spellScroll() *models.Item {
+	return &models.Item{
+		ID:   "spell_scroll_1st",
+		Name: "Spell Scroll (1st Level)",
+		Type: models.ItemTypeConsumable,
+		Properties: models.ItemProperties{
+			"consumable": true, "spell_level": "1st", "save_dc": float64(13), "attack_bonus": float64(5),
+		},
+	}
+}
+
+func createTestConsumableService(t *testing.T, mockInvRepo *mocks.MockInventoryRepository, mockCharRepo *mocks.MockCharacterRepository) *services.InventoryService {
+	service := services.NewInventoryService(mockInvRepo, mockCharRepo)
+	catalog, err := services.NewSpellCatalog("../../../data")
+	require.NoError(t, err)
+	service.SetSpellService(services.NewSpellManagementService("../../../data", catalog, mockCharRepo, mockInvRepo))
+	return service
+}
+
+// setupConsumableStack gives the test character two of an item
+func setupConsumableStack(mockInvRepo *mocks.MockInventoryRepository, item *models.Item) {
+	stack := mocks.CreateTestInventoryItem(constants.TestCharacterID, item.ID, 2, false, false, item)
+	mockInvRepo.On(testMethodGetCharacterInventory, constants.TestCharacterID).Return([]*models.InventoryItem{stack}, nil)
+}
+
+func TestInventoryService_UseItem(t *testing.T) {
+	user := constants.TestCharacterID
+
+	tests := []struct {
+		name        string
+		item        *models.Item
+		character   *models.Character
+		request     *models.UseItemRequest
+		setupMocks  func(*mocks.MockInventoryRepository, *mocks.MockGameSessionRepository, *models.Character)
+		expectError string
+		validate    func(*testing.T, *models.ItemUse, *models.Character)
+	}{
+		{
+			name:      "Drinking a healing potion heals the drinker and uses one up in one transaction",
+			item:      healingPotion(),
+			character: &models.Character{ID: constants.TestCharacterID, Name: "Brom", HitPoints: 3, MaxHitPoints: 30},
+			request:   &models.UseItemRequest{},
+			setupMocks: func(mockInvRepo *mocks.MockInventoryRepository, _ *mocks.MockGameSessionRepository, char *models.Character) {
+				setupConsumableStack(mockInvRepo, healingPotion())
+				mockInvRepo.On("ApplyTransaction", mock.MatchedBy(func(txn *models.EconomyTransaction) bool {
+					return txn.Type == models.LedgerEntryUse && txn.ItemTarget == char &&
+						len(txn.Items) == 1 && txn.Items[0].ItemID == "healing_potion" && txn.Items[0].Quantity == -1
+				})).Return([]*models.LedgerEntry{}, nil)
+			},
+			validate: func(t *testing.T, use *models.ItemUse, char *models.Character) {
+				assert.Equal(t, models.ActionTypeUseItem, use.Cost)
+				assert.GreaterOrEqual(t, use.Healing, 4)
+				assert.LessOrEqual(t, use.Healing, 10)
+				assert.Equal(t, 3+use.Healing, char.HitPoints)
+				assert.Equal(t, 1, use.QuantityRemaining)
+			},
+		},
+		{
+			name: "A non-caster must pass a check to read a scroll",
+			item: spellScroll(),
+			character: &models.Character{
+				ID: constants.TestCharacterID, Name: "Brom", Class: "Fighter", HitPoints: 3, MaxHitPoints: 30,
+				Attributes: models.Attributes{Intelligence: 14},
+			},
+			request: &models.UseItemRequest{Spell: "Cure Wounds"},
+			setupMocks: func(mockInvRepo *mocks.MockInventoryRepository, _ *mocks.MockGameSessionRepository, _ *models.Character) {
+				setupConsumableStack(mockInvRepo, spellScroll())
+				mockInvRepo.On("ApplyTransaction", mock.Anything).Return([]*models.LedgerEntry{}, nil)
+			},
+			validate: func(t *testing.T, use *models.ItemUse, _ *models.Character) {
+				require.NotNil(t, use.ScrollCheck)
+				assert.Equal(t, constants.AbilityIntelligence, use.ScrollCheck.Ability)
+				assert.Equal(t, 11, use.ScrollCheck.DC)
+				assert.Equal(t, 2, use.ScrollCheck.Roll.Modifier)
+				assert.Equal(t, !use.ScrollCheck.Success, use.Wasted)
+				if use.Wasted {
+					assert.Zero(t, use.Healing, "a failed check wastes the scroll")
+				} else {
+					assert.Positive(t, use.Healing)
+				}
+				assert.Equal(t, "Cure Wounds", use.Spell.Name)
+				assert.Equal(t, 13, use.SpellSaveDC, "scrolls cast with their own DC")
+			},
+		},
+		{
+			name: "A cleric reads a scroll of a spell they can cast without a check",
+			item: spellScroll(),
+			character: &models.Character{
+				ID: constants.TestCharacterID, Name: "Ysolde", Class: "Cleric", HitPoints: 1, MaxHitPoints: 40,
+				Attributes: models.Attributes{Wisdom: 16},
+				Spells:     models.SpellData{SpellSlots: []models.SpellSlot{{Level: 1, Total: 2, Remaining: 0}}},
+			},
+			request: &models.UseItemRequest{Spell: "Cure Wounds"},
+			setupMocks: func(mockInvRepo *mocks.MockInventoryRepository, _ *mocks.MockGameSessionRepository, _ *models.Character) {
+				setupConsumableStack(mockInvRepo, spellScroll())
+				mockInvRepo.On("ApplyTransaction", mock.Anything).Return([]*models.LedgerEntry{}, nil)
+			},
+			validate: func(t *testing.T, use *models.ItemUse, cleric *models.Character) {
+				assert.Nil(t, use.ScrollCheck)
+				assert.GreaterOrEqual(t, use.Healing, 4, "1d8 plus the Wisdom modifier")
+				assert.Equal(t, 1+use.Healing, cleric.HitPoints)
+			},
+		},
+		{
+			name:      "A scroll cannot hold a spell above its level",
+			item:      spellScroll(),
+			character: &models.Character{ID: constants.TestCharacterID},
+			request:   &models.UseItemRequest{Spell: "Revivify"},
+			setupMocks: func(mockInvRepo *mocks.MockInventoryRepository, _ *mocks.MockGameSessionRepository, _ *models.Character) {
+				setupConsumableStack(mockInvRepo, spellScroll())
+			},
+			expectError: "cannot hold Revivify",
+		},
+		{
+			name:    "Another character outside the game session",
+			item:    healingPotion(),
+			request: &models.UseItemRequest{TargetID: "stranger", SessionID: "session-1"},
+			setupMocks: func(_ *mocks.MockInventoryRepository, sessionRepo *mocks.MockGameSessionRepository, _ *models.Character) {
+				sessionRepo.On("GetParticipants", mock.Anything, "session-1").Return([]*models.GameParticipant{{CharacterID: &user}}, nil)
+			},
+			expectError: "not in this game session",
+		},
+		{
+			name:        "Another character without a game session",
+			item:        healingPotion(),
+			request:     &models.UseItemRequest{TargetID: "stranger"},
+			expectError: "session",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockInvRepo := new(mocks.MockInventoryRepository)
+			mockCharRepo := new(mocks.MockCharacterRepository)
+			sessionRepo := new(mocks.MockGameSessionRepository)
+			if tt.character != nil {
+				mockCharRepo.On("GetByID", mock.Anything, constants.TestCharacterID).Return(tt.character, nil)
+			}
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockInvRepo, sessionRepo, tt.character)
+			}
+
+			service := createTestConsumableService(t, mockInvRepo, mockCharRepo)
+			service.SetGameSessionRepository(sessionRepo)
+			use, err := service.UseItem(context.Background(), constants.TestCharacterID, tt.item.ID, tt.request)
+
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+			} else {
+				require.NoError(t, err)
+				tt.validate(t, use, tt.character)
+			}
+
+			mockInvRepo.AssertExpectations(t)
+			mockCharRepo.AssertExpectations(t)
+			sessionRepo.AssertExpectations(t)
+		})
+	}
+}
+
+// startItemCombat starts a fight between the test character and a goblin
+func startItemCombat(t *testing.T, service *services.InventoryService) (*services.CombatService, *models.Combat) {
+	combatService := services.NewCombatService()
+	combatService.SetInventoryService(service)
+	combat, err := combatService.StartCombat(context.Background(), "session-1", []models.Combatant{
+		{ID: "hero", CharacterID: constants.TestCharacterID, Name: "Brom", Initiative: 20, HP: 5, MaxHP: 30, AC: 16, Speed: 30},
+		{ID: "goblin", Name: "Goblin", Initiative: 5, HP: 7, MaxHP: 7, AC: 15, Speed: 30, SavingThrows: map[string]int{"dexterity": 2}},
+	})
+	require.NoError(t, err)
+	return combatService, combat
+}
+
+func TestCombatService_UseItem(t *testing.T) {
+	tests := []struct {
+		name       string
+		item       *models.Item
+		targetID   string
+		setupMocks func(*mocks.MockGameSessionRepository)
+		validate   func(*testing.T, *models.CombatAction, *models.Combat)
+	}{
+		{
+			name:     "A thrown flask spends the action and forces a save",
+			item:     alchemistsFire(),
+			targetID: "goblin",
+			validate: func(t *testing.T, action *models.CombatAction, combat *models.Combat) {
+				assert.Equal(t, models.ActionTypeUseItem, action.ActionType)
+				assert.Equal(t, "goblin", action.TargetID)
+				require.NotEmpty(t, action.Rolls)
+				assert.Equal(t, models.RollTypeSavingThrow, action.Rolls[len(action.Rolls)-1].Type)
+				for _, c := range combat.Combatants {
+					switch c.ID {
+					case "hero":
+						assert.Zero(t, c.Actions)
+					case "goblin":
+						assert.Equal(t, 7-sumDamage(action.Damage), c.HP)
+					}
+				}
+			},
+		},
+		{
+			name: "Table rules let a potion be drunk as a bonus action",
+			item: healingPotion(),
+			setupMocks: func(sessionRepo *mocks.MockGameSessionRepository) {
+				sessionRepo.On("GetByID", mock.Anything, "session-1").Return(&models.GameSession{
+					ID:    "session-1",
+					State: map[string]interface{}{models.SessionStateTableRules: map[string]interface{}{"potions_as_bonus_action": true}},
+				}, nil)
+			},
+			validate: func(t *testing.T, action *models.CombatAction, combat *models.Combat) {
+				assert.Equal(t, models.ActionTypeBonusAction, action.ActionType)
+				assert.Zero(t, combat.CurrentTurn, "a bonus action leaves the turn open")
+				hero := combat.Combatants[0]
+				assert.Equal(t, 1, hero.Actions)
+				assert.Zero(t, hero.BonusActions)
+				assert.Equal(t, 5+action.Healing, hero.HP)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockInvRepo := new(mocks.MockInventoryRepository)
+			mockCharRepo := new(mocks.MockCharacterRepository)
+			sessionRepo := new(mocks.MockGameSessionRepository)
+			setupConsumableStack(mockInvRepo, tt.item)
+			mockInvRepo.On("RemoveItemFromInventory", constants.TestCharacterID, tt.item.ID, 1).Return(nil)
+			mockCharRepo.On("GetByID", mock.Anything, constants.TestCharacterID).
+				Return(&models.Character{ID: constants.TestCharacterID, Name: "Brom", ArmorClass: 16, Speed: 30}, nil)
+			if tt.setupMocks != nil {
+				tt.setupMocks(sessionRepo)
+			}
+
+			service := createTestConsumableService(t, mockInvRepo, mockCharRepo)
+			service.SetGameSessionRepository(sessionRepo)
+			combatService, combat := startItemCombat(t, service)
+			action, err := combatService.ProcessAction(context.Background(), combat.ID, models.CombatRequest{
+				Action: models.ActionTypeUseItem, ActorID: "hero", TargetID: tt.targetID, ItemID: tt.item.ID,
+			})
+
+			require.NoError(t, err)
+			tt.validate(t, action, combat)
+			mockInvRepo.AssertExpectations(t)
+			sessionRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func sumDamage(damage []models.Damage) int {
+	total := 0
+	for _, d := range damage {
+		total += d.Amount
+	}
+	return total
+}
@@ -0,0 +1,417 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/auth"
+	"github.com/ctclostio/DnD-Game/backend/internal/database"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+// CraftingService runs downtime crafting: characters work on a recipe for a number of in-game
+// days, then roll a check that turns the ingredients and gold into the finished item
+type CraftingService struct {
+	craftingRepo  database.CraftingRepository
+	inventory     *InventoryService
+	inventoryRepo database.InventoryRepository
+	characterRepo database.CharacterRepository
+	diceService   *DiceRollService
+	recipes       *RecipeCatalog
+	items         *ItemCatalog
+}
+
+// NewCraftingService creates a new crafting service
+func NewCraftingService(craftingRepo database.CraftingRepository, inventory *InventoryService, inventoryRepo database.InventoryRepository, characterRepo database.CharacterRepository, diceService *DiceRollService) *CraftingService {
+	return &CraftingService{
+		craftingRepo:  craftingRepo,
+		inventory:     inventory,
+		inventoryRepo: inventoryRepo,
+		characterRepo: characterRepo,
+		diceService:   diceService,
+	}
+}
+
+// SetRecipeCatalog enables the built-in recipes, whose items come from the item catalog
+func (s *CraftingService) SetRecipeCatalog(recipes *RecipeCatalog, items *ItemCatalog) {
+	s.recipes = recipes
+	s.items = items
+}
+
+// ListRecipes returns the built-in recipes, followed by the session's homebrew when sessionID is set
+func (s *CraftingService) ListRecipes(ctx context.Context, sessionID string) ([]*models.Recipe, error) {
+	recipes := make([]*models.Recipe, 0)
+	if s.recipes != nil {
+		recipes = append(recipes, s.recipes.Recipes()...)
+	}
+	if sessionID == "" {
+		return recipes, nil
+	}
+
+	homebrew, err := s.craftingRepo.ListRecipes(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return append(recipes, homebrew...), nil
+}
+
+// GetRecipe returns a built-in recipe, or a homebrew recipe of the given session
+func (s *CraftingService) GetRecipe(ctx context.Context, recipeID, sessionID string) (*models.Recipe, error) {
+	if s.recipes != nil {
+		if recipe := s.recipes.Get(recipeID); recipe != nil {
+			return recipe, nil
+		}
+	}
+	if _, err := uuid.Parse(recipeID); err != nil {
+		return nil, fmt.Errorf("recipe not found")
+	}
+
+	recipe, err := s.craftingRepo.GetRecipe(ctx, recipeID)
+	if err != nil {
+		return nil, err
+	}
+	if recipe == nil || recipe.SessionID == nil || *recipe.SessionID != sessionID {
+		return nil, fmt.Errorf("recipe not found")
+	}
+	return recipe, nil
+}
+
+// CreateHomebrewRecipe stores a DM's recipe for their game session
+func (s *CraftingService) CreateHomebrewRecipe(ctx context.Context, sessionID string, recipe *models.Recipe) error {
+	recipe.ID = ""
+	recipe.SessionID = &sessionID
+	recipe.CreatedBy, _ = auth.GetUserIDFromContext(ctx)
+	if recipe.Category == "" {
+		recipe.Category = models.RecipeCategoryGear
+	}
+	if err := recipe.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := s.resolveItem(recipe.Output.ItemID, sessionID); err != nil {
+		return err
+	}
+	for _, ingredient := range recipe.Ingredients {
+		if _, err := s.resolveItem(ingredient.ItemID, sessionID); err != nil {
+			return err
+		}
+	}
+	return s.craftingRepo.CreateRecipe(ctx, recipe)
+}
+
+// DeleteHomebrewRecipe removes one of the session's homebrew recipes
+func (s *CraftingService) DeleteHomebrewRecipe(ctx context.Context, sessionID, recipeID string) error {
+	if _, err := uuid.Parse(recipeID); err != nil {
+		return fmt.Errorf("recipe not found")
+	}
+	recipe, err := s.craftingRepo.GetRecipe(ctx, recipeID)
+	if err != nil {
+		return err
+	}
+	if recipe == nil || recipe.SessionID == nil || *recipe.SessionID != sessionID {
+		return fmt.Errorf("recipe not found")
+	}
+	return s.craftingRepo.DeleteRecipe(ctx, recipeID)
+}
+
+// StartProject begins work on a recipe. The character needs the recipe's tool proficiency and
+// must hold its ingredients and gold now, though nothing is spent until the work is finished.
+func (s *CraftingService) StartProject(ctx context.Context, characterID string, req *models.StartCraftingRequest) (*models.CraftingProject, error) {
+	recipe, err := s.GetRecipe(ctx, req.RecipeID, req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	character, err := s.characterRepo.GetByID(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+	if character == nil {
+		return nil, fmt.Errorf("character not found")
+	}
+	if !recipe.ProficientCrafter(character) {
+		return nil, fmt.Errorf("crafting %s requires proficiency with %s", recipe.Name, recipe.Tool)
+	}
+
+	active, err := s.craftingRepo.ListProjects(ctx, characterID, models.CraftingStatusInProgress)
+	if err != nil {
+		return nil, err
+	}
+	if len(active) > 0 {
+		return nil, fmt.Errorf("%s is already crafting %s", character.Name, active[0].Recipe.Name)
+	}
+	if err := s.checkCrafterHolds(characterID, recipe); err != nil {
+		return nil, err
+	}
+
+	project := &models.CraftingProject{
+		CharacterID: characterID,
+		Recipe:      *recipe,
+		Status:      models.CraftingStatusInProgress,
+	}
+	if req.SessionID != "" {
+		project.SessionID = &req.SessionID
+	}
+	project.CreatedBy, _ = auth.GetUserIDFromContext(ctx)
+	if err := s.craftingRepo.CreateProject(ctx, project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// Work spends in-game days on a project, taken from the downtime the DM has granted the
+// crafter. When the last workday is done the crafting check is rolled: success turns the
+// ingredients and gold into the recipe's output, while failure ruins them and produces
+// nothing. Either way they are spent in one ledgered transaction.
+func (s *CraftingService) Work(ctx context.Context, projectID string, days int) (*models.CraftingProgress, error) {
+	if days < 1 {
+		return nil, fmt.Errorf("days must be at least 1")
+	}
+	project, err := s.activeProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the days the project still needs are spent from the crafter's downtime
+	worked := min(project.Recipe.Workdays, project.DaysWorked+days)
+	spent := worked - project.DaysWorked
+	available, err := s.craftingRepo.GetDowntime(ctx, project.CharacterID)
+	if err != nil {
+		return nil, err
+	}
+	if available < spent {
+		return nil, fmt.Errorf("only %d downtime days available; ask your DM to grant more", available)
+	}
+
+	if worked < project.Recipe.Workdays {
+		progress := *project
+		progress.DaysWorked = worked
+		if err := s.craftingRepo.WorkOnProject(ctx, &progress, spent); err != nil {
+			return nil, err
+		}
+		progress.UpdatedAt = time.Now()
+		return &models.CraftingProgress{Project: &progress}, nil
+	}
+
+	return s.finish(ctx, project, worked)
+}
+
+// GetDowntime returns how many downtime days the character has left to spend
+func (s *CraftingService) GetDowntime(ctx context.Context, characterID string) (int, error) {
+	return s.craftingRepo.GetDowntime(ctx, characterID)
+}
+
+// GrantDowntime gives a character downtime days to spend, as the DM lets time pass in the story
+func (s *CraftingService) GrantDowntime(ctx context.Context, characterID string, days int) (int, error) {
+	if days < 1 {
+		return 0, fmt.Errorf("days must be at least 1")
+	}
+	grantedBy, _ := auth.GetUserIDFromContext(ctx)
+	return s.craftingRepo.GrantDowntime(ctx, characterID, days, grantedBy)
+}
+
+// AbandonProject stops work on a project without spending anything
+func (s *CraftingService) AbandonProject(ctx context.Context, projectID string) (*models.CraftingProject, error) {
+	project, err := s.activeProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.craftingRepo.AbandonProject(ctx, project.ID); err != nil {
+		return nil, err
+	}
+	project.Status = models.CraftingStatusAbandoned
+	return project, nil
+}
+
+// GetProject returns a crafting project
+func (s *CraftingService) GetProject(ctx context.Context, projectID string) (*models.CraftingProject, error) {
+	project, err := s.craftingRepo.GetProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, fmt.Errorf("crafting project not found")
+	}
+	return project, nil
+}
+
+// ListProjects returns a character's crafting projects, optionally only those in one status
+func (s *CraftingService) ListProjects(ctx context.Context, characterID string, status models.CraftingStatus) ([]*models.CraftingProject, error) {
+	return s.craftingRepo.ListProjects(ctx, characterID, status)
+}
+
+func (s *CraftingService) activeProject(ctx context.Context, projectID string) (*models.CraftingProject, error) {
+	project, err := s.GetProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project.Status != models.CraftingStatusInProgress {
+		return nil, fmt.Errorf("crafting project is already %s", project.Status)
+	}
+	return project, nil
+}
+
+// finish rolls the crafting check and settles the project's inputs and output
+func (s *CraftingService) finish(ctx context.Context, project *models.CraftingProject, worked int) (*models.CraftingProgress, error) {
+	recipe := &project.Recipe
+	if err := s.checkCrafterHolds(project.CharacterID, recipe); err != nil {
+		return nil, err
+	}
+	character, err := s.characterRepo.GetByID(ctx, project.CharacterID)
+	if err != nil {
+		return nil, err
+	}
+	if character == nil {
+		return nil, fmt.Errorf("character not found")
+	}
+
+	check, err := s.rollCheck(ctx, project, recipe.CheckModifier(character))
+	if err != nil {
+		return nil, err
+	}
+
+	finished := *project
+	finished.DaysWorked = worked
+	finished.CheckRoll = &check.Roll
+	finished.CheckTotal = &check.Total
+	finished.Status = models.CraftingStatusFailed
+	if check.Success {
+		finished.Status = models.CraftingStatusCompleted
+	}
+
+	txn := &models.EconomyTransaction{
+		Type:            models.LedgerEntryCraft,
+		Description:     fmt.Sprintf("crafted %s", recipe.Name),
+		CraftingProject: &finished,
+		CraftingDays:    worked - project.DaysWorked,
+	}
+	txn.CreatedBy, _ = auth.GetUserIDFromContext(ctx)
+	if project.SessionID != nil {
+		txn.SessionID = *project.SessionID
+	}
+	if recipe.GoldCost > 0 {
+		txn.Currency = append(txn.Currency, models.CurrencyMovement{
+			CharacterID: project.CharacterID,
+			Coins:       models.Coins{Gold: -recipe.GoldCost},
+		})
+	}
+	for _, ingredient := range recipe.Ingredients {
+		txn.Items = append(txn.Items, models.ItemMovement{
+			CharacterID: project.CharacterID,
+			ItemID:      ingredient.ItemID,
+			Quantity:    -ingredient.Quantity,
+		})
+	}
+	if check.Success {
+		if _, err := s.resolveItem(recipe.Output.ItemID, txn.SessionID); err != nil {
+			return nil, err
+		}
+		txn.Items = append(txn.Items, models.ItemMovement{
+			CharacterID: project.CharacterID,
+			ItemID:      recipe.Output.ItemID,
+			Quantity:    recipe.Output.Quantity,
+		})
+	} else {
+		txn.Description = fmt.Sprintf("failed to craft %s", recipe.Name)
+	}
+
+	entries, err := s.inventory.applyTransaction(ctx, txn)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	finished.TransactionID = &txn.ID
+	finished.CompletedAt = &now
+	finished.UpdatedAt = now
+	return &models.CraftingProgress{Project: &finished, Check: check, Ledger: entries}, nil
+}
+
+// rollCheck rolls the crafting check through the dice service, recording it in the session's
+// roll history when the project belongs to a session
+func (s *CraftingService) rollCheck(ctx context.Context, project *models.CraftingProject, modifier int) (*models.CraftingCheck, error) {
+	notation := fmt.Sprintf("1d20%+d", modifier)
+
+	var roll *models.DiceRoll
+	userID, _ := auth.GetUserIDFromContext(ctx)
+	if project.SessionID != nil && userID != "" {
+		roll = &models.DiceRoll{
+			GameSessionID: *project.SessionID,
+			UserID:        userID,
+			RollNotation:  notation,
+			Purpose:       "crafting: " + project.Recipe.Name,
+		}
+		if err := s.diceService.RollDice(ctx, roll); err != nil {
+			return nil, err
+		}
+	} else {
+		simulated, err := s.diceService.SimulateRoll(notation)
+		if err != nil {
+			return nil, err
+		}
+		roll = simulated
+	}
+
+	check := &models.CraftingCheck{
+		Roll:       roll.Total - roll.Modifier,
+		Modifier:   roll.Modifier,
+		Total:      roll.Total,
+		DC:         project.Recipe.DC,
+		DiceRollID: roll.ID,
+	}
+	check.Success = check.Total >= check.DC
+	return check, nil
+}
+
+// checkCrafterHolds rejects crafting the character can't currently pay for. Finishing a
+// project checks again, as the character may have used the ingredients in the meantime.
+func (s *CraftingService) checkCrafterHolds(characterID string, recipe *models.Recipe) error {
+	if recipe.GoldCost > 0 {
+		purse, err := s.inventoryRepo.GetCharacterCurrency(characterID)
+		if err != nil {
+			return err
+		}
+		if purse == nil || !purse.CanAfford(recipe.GoldCost*100) {
+			return fmt.Errorf("crafting %s costs %d gp", recipe.Name, recipe.GoldCost)
+		}
+	}
+	if len(recipe.Ingredients) == 0 {
+		return nil
+	}
+
+	inventory, err := s.inventoryRepo.GetCharacterInventory(characterID)
+	if err != nil {
+		return err
+	}
+	held := make(map[string]int, len(inventory))
+	for _, inv := range inventory {
+		held[inv.ItemID] += inv.Quantity
+	}
+	for _, ingredient := range recipe.Ingredients {
+		if held[ingredient.ItemID] < ingredient.Quantity {
+			return fmt.Errorf("crafting %s needs %d of item %s, but only %d are in the inventory",
+				recipe.Name, ingredient.Quantity, ingredient.ItemID, held[ingredient.ItemID])
+		}
+	}
+	return nil
+}
+
+// resolveItem finds a shared item or one of the session's homebrew items in the items table,
+// adding it from the item catalog if it is a catalog item that hasn't been used yet
+func (s *CraftingService) resolveItem(itemID, sessionID string) (*models.Item, error) {
+	item, err := s.inventoryRepo.GetSessionItem(itemID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if item != nil {
+		return item, nil
+	}
+	if s.items != nil {
+		if catalogItem := s.items.Get(itemID); catalogItem != nil {
+			return ensureCatalogItem(s.inventoryRepo, catalogItem)
+		}
+	}
+	return nil, fmt.Errorf("item %s not found", itemID)
+}
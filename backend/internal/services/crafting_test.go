@@ -0,0 +1,356 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/internal/services/mocks"
+)
+
+// MockCraftingRepository mocks homebrew recipe and crafting project storage
+type MockCraftingRepository struct {
+	mock.Mock
+}
+
+func (m *MockCraftingRepository) CreateRecipe(ctx context.Context, recipe *models.Recipe) error {
+	args := m.Called(ctx, recipe)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockCraftingRepository) GetRecipe(ctx context.Context, id string) (*models.Recipe, error) {
+	args := m.Called(ctx, id)
+	return mockSingleReturn[models.Recipe](args, 0, 1)
+}
+
+func (m *MockCraftingRepository) ListRecipes(ctx context.Context, sessionID string) ([]*models.Recipe, error) {
+	args := m.Called(ctx, sessionID)
+	return mockSliceReturn[models.Recipe](args, 0, 1)
+}
+
+func (m *MockCraftingRepository) DeleteRecipe(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockCraftingRepository) CreateProject(ctx context.Context, project *models.CraftingProject) error {
+	args := m.Called(ctx, project)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockCraftingRepository) GetProject(ctx context.Context, id string) (*models.CraftingProject, error) {
+	args := m.Called(ctx, id)
+	return mockSingleReturn[models.CraftingProject](args, 0, 1)
+}
+
+func (m *MockCraftingRepository) ListProjects(ctx context.Context, characterID string, status models.CraftingStatus) ([]*models.CraftingProject, error) {
+	args := m.Called(ctx, characterID, status)
+	return mockSliceReturn[models.CraftingProject](args, 0, 1)
+}
+
+func (m *MockCraftingRepository) WorkOnProject(ctx context.Context, project *models.CraftingProject, days int) error {
+	args := m.Called(ctx, project, days)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockCraftingRepository) AbandonProject(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockCraftingRepository) GetDowntime(ctx context.Context, characterID string) (int, error) {
+	args := m.Called(ctx, characterID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockCraftingRepository) GrantDowntime(ctx context.Context, characterID string, days int, grantedBy string) (int, error) {
+	args := m.Called(ctx, characterID, days, grantedBy)
+	return args.Int(0), args.Error(1)
+}
+
+func loadCraftingCatalogs(t *testing.T) (*ItemCatalog, *RecipeCatalog) {
+	items, err := NewItemCatalog("../../../data")
+	require.NoError(t, err)
+	recipes, err := NewRecipeCatalog("../../../data", items)
+	require.NoError(t, err)
+	return items, recipes
+}
+
+func createTestCraftingService(items *ItemCatalog, recipes *RecipeCatalog, craftingRepo *MockCraftingRepository, inventoryRepo *mocks.MockInventoryRepository, characterRepo *mocks.MockCharacterRepository) *CraftingService {
+	inventory := NewInventoryService(inventoryRepo, characterRepo)
+	service := NewCraftingService(craftingRepo, inventory, inventoryRepo, characterRepo, NewDiceRollService(nil))
+	service.SetRecipeCatalog(recipes, items)
+	return service
+}
+
+// testCrafter is proficient with an herbalism kit and has a +0 Wisdom
+func testCrafter() *models.Character {
+	return &models.Character{
+		ID:               "char-1",
+		Name:             "Brenna",
+		ProficiencyBonus: 2,
+		Attributes:       models.Attributes{Wisdom: 10},
+		Proficiencies:    models.Proficiencies{Tools: []string{"herbalism kit"}},
+	}
+}
+
+// potionProject is a healing potion brew with the given DC and workdays
+func potionProject(recipes *RecipeCatalog, dc, workdays int) *models.CraftingProject {
+	recipe := *recipes.Get("healing_potion")
+	recipe.DC = dc
+	recipe.Workdays = workdays
+	return &models.CraftingProject{
+		ID:          "project-1",
+		CharacterID: "char-1",
+		Recipe:      recipe,
+		Status:      models.CraftingStatusInProgress,
+	}
+}
+
+// setupCraftingIngredients gives the crafter gold and one bundle of healing herbs
+func setupCraftingIngredients(inventoryRepo *mocks.MockInventoryRepository) {
+	inventoryRepo.On("GetCharacterCurrency", "char-1").Return(&models.Currency{Gold: 20}, nil)
+	inventoryRepo.On("GetCharacterInventory", "char-1").Return([]*models.InventoryItem{{ItemID: "healing_herbs", Quantity: 1}}, nil)
+}
+
+func TestRecipeCatalog(t *testing.T) {
+	items, err := NewItemCatalog("../../../data")
+	require.NoError(t, err)
+
+	recipes, err := NewRecipeCatalog("../../../data", items)
+
+	require.NoError(t, err)
+	potion := recipes.Get("healing_potion")
+	require.NotNil(t, potion)
+	assert.Equal(t, "healing_potion", potion.Output.ItemID)
+	assert.Equal(t, []models.RecipeComponent{{ItemID: "healing_herbs", Quantity: 1}}, potion.Ingredients)
+	for _, recipe := range recipes.Recipes() {
+		assert.NoError(t, recipe.Validate(), recipe.ID)
+	}
+}
+
+func TestCraftingService_StartProject(t *testing.T) {
+	items, recipes := loadCraftingCatalogs(t)
+	unskilled := testCrafter()
+	unskilled.Proficiencies.Tools = nil
+	homebrewID := "0b6f3c2e-6a51-4d8e-9a3e-2f1b7c9d4e10"
+	otherSession := "session-2"
+
+	tests := []struct {
+		name        string
+		request     *models.StartCraftingRequest
+		setupMocks  func(*MockCraftingRepository, *mocks.MockInventoryRepository, *mocks.MockCharacterRepository)
+		expectError string
+	}{
+		{
+			name:    "Crafter with the tool proficiency and materials",
+			request: &models.StartCraftingRequest{RecipeID: "healing_potion"},
+			setupMocks: func(craftingRepo *MockCraftingRepository, inventoryRepo *mocks.MockInventoryRepository, characterRepo *mocks.MockCharacterRepository) {
+				characterRepo.On("GetByID", mock.Anything, "char-1").Return(testCrafter(), nil)
+				craftingRepo.On("ListProjects", mock.Anything, "char-1", models.CraftingStatusInProgress).Return([]*models.CraftingProject{}, nil)
+				setupCraftingIngredients(inventoryRepo)
+				craftingRepo.On("CreateProject", mock.Anything, mock.MatchedBy(func(p *models.CraftingProject) bool {
+					return p.Recipe.ID == "healing_potion" && p.Status == models.CraftingStatusInProgress
+				})).Return(nil)
+			},
+		},
+		{
+			name:    "Missing the recipe's tool proficiency",
+			request: &models.StartCraftingRequest{RecipeID: "healing_potion"},
+			setupMocks: func(_ *MockCraftingRepository, _ *mocks.MockInventoryRepository, characterRepo *mocks.MockCharacterRepository) {
+				characterRepo.On("GetByID", mock.Anything, "char-1").Return(unskilled, nil)
+			},
+			expectError: "Herbalism Kit",
+		},
+		{
+			name:    "Missing the ingredients",
+			request: &models.StartCraftingRequest{RecipeID: "healing_potion"},
+			setupMocks: func(craftingRepo *MockCraftingRepository, inventoryRepo *mocks.MockInventoryRepository, characterRepo *mocks.MockCharacterRepository) {
+				characterRepo.On("GetByID", mock.Anything, "char-1").Return(testCrafter(), nil)
+				craftingRepo.On("ListProjects", mock.Anything, "char-1", models.CraftingStatusInProgress).Return([]*models.CraftingProject{}, nil)
+				inventoryRepo.On("GetCharacterCurrency", "char-1").Return(&models.Currency{Gold: 20}, nil)
+				inventoryRepo.On("GetCharacterInventory", "char-1").Return([]*models.InventoryItem{}, nil)
+			},
+			expectError: "only 0 are in the inventory",
+		},
+		{
+			name:    "Homebrew recipes belong to their session",
+			request: &models.StartCraftingRequest{RecipeID: homebrewID, SessionID: "session-1"},
+			setupMocks: func(craftingRepo *MockCraftingRepository, _ *mocks.MockInventoryRepository, _ *mocks.MockCharacterRepository) {
+				craftingRepo.On("GetRecipe", mock.Anything, homebrewID).Return(&models.Recipe{ID: homebrewID, SessionID: &otherSession}, nil)
+			},
+			expectError: "recipe not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			craftingRepo := new(MockCraftingRepository)
+			inventoryRepo := new(mocks.MockInventoryRepository)
+			characterRepo := new(mocks.MockCharacterRepository)
+			tt.setupMocks(craftingRepo, inventoryRepo, characterRepo)
+
+			service := createTestCraftingService(items, recipes, craftingRepo, inventoryRepo, characterRepo)
+			project, err := service.StartProject(context.Background(), "char-1", tt.request)
+
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, 1, project.DaysRemaining())
+			}
+
+			craftingRepo.AssertExpectations(t)
+			inventoryRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCraftingService_Work(t *testing.T) {
+	items, recipes := loadCraftingCatalogs(t)
+	completed := potionProject(recipes, 10, 1)
+	completed.Status = models.CraftingStatusCompleted
+
+	tests := []struct {
+		name        string
+		project     *models.CraftingProject
+		days        int
+		setupMocks  func(*MockCraftingRepository, *mocks.MockInventoryRepository, *mocks.MockCharacterRepository)
+		expectError string
+		validate    func(*testing.T, *models.CraftingProgress)
+	}{
+		{
+			name:    "Records progress until the last workday",
+			project: potionProject(recipes, 10, 3),
+			days:    2,
+			setupMocks: func(craftingRepo *MockCraftingRepository, _ *mocks.MockInventoryRepository, _ *mocks.MockCharacterRepository) {
+				craftingRepo.On("GetDowntime", mock.Anything, "char-1").Return(5, nil)
+				craftingRepo.On("WorkOnProject", mock.Anything, mock.MatchedBy(func(p *models.CraftingProject) bool {
+					return p.ID == "project-1" && p.DaysWorked == 2
+				}), 2).Return(nil)
+			},
+			validate: func(t *testing.T, progress *models.CraftingProgress) {
+				assert.Nil(t, progress.Check)
+				assert.Equal(t, 1, progress.Project.DaysRemaining())
+			},
+		},
+		{
+			name:    "Needs downtime days granted by the DM",
+			project: potionProject(recipes, 10, 3),
+			days:    2,
+			setupMocks: func(craftingRepo *MockCraftingRepository, _ *mocks.MockInventoryRepository, _ *mocks.MockCharacterRepository) {
+				craftingRepo.On("GetDowntime", mock.Anything, "char-1").Return(1, nil)
+			},
+			expectError: "only 1 downtime days available",
+		},
+		{
+			name:    "A successful check turns ingredients and gold into the output",
+			project: potionProject(recipes, 1, 1),
+			days:    5,
+			setupMocks: func(craftingRepo *MockCraftingRepository, inventoryRepo *mocks.MockInventoryRepository, characterRepo *mocks.MockCharacterRepository) {
+				craftingRepo.On("GetDowntime", mock.Anything, "char-1").Return(1, nil)
+				characterRepo.On("GetByID", mock.Anything, "char-1").Return(testCrafter(), nil)
+				setupCraftingIngredients(inventoryRepo)
+				inventoryRepo.On("GetSessionItem", "healing_potion", mock.Anything).Return(&models.Item{ID: "healing_potion"}, nil)
+				inventoryRepo.On("ApplyTransaction", mock.MatchedBy(func(txn *models.EconomyTransaction) bool {
+					return txn.Type == models.LedgerEntryCraft && txn.CraftingDays == 1 &&
+						txn.CraftingProject.Status == models.CraftingStatusCompleted &&
+						txn.Currency[0].Coins.Gold == -15 &&
+						len(txn.Items) == 2 && txn.Items[0].Quantity == -1 && txn.Items[1].ItemID == "healing_potion"
+				})).Return([]*models.LedgerEntry{{CharacterID: "char-1"}}, nil)
+			},
+			validate: func(t *testing.T, progress *models.CraftingProgress) {
+				require.NotNil(t, progress.Check)
+				assert.True(t, progress.Check.Success)
+				assert.Equal(t, 2, progress.Check.Modifier, "proficiency bonus with a +0 Wisdom")
+				assert.Equal(t, models.CraftingStatusCompleted, progress.Project.Status)
+				assert.Equal(t, 1, progress.Project.DaysWorked)
+			},
+		},
+		{
+			name:    "A failed check ruins the ingredients",
+			project: potionProject(recipes, 30, 1),
+			days:    1,
+			setupMocks: func(craftingRepo *MockCraftingRepository, inventoryRepo *mocks.MockInventoryRepository, characterRepo *mocks.MockCharacterRepository) {
+				craftingRepo.On("GetDowntime", mock.Anything, "char-1").Return(3, nil)
+				characterRepo.On("GetByID", mock.Anything, "char-1").Return(testCrafter(), nil)
+				setupCraftingIngredients(inventoryRepo)
+				inventoryRepo.On("ApplyTransaction", mock.MatchedBy(func(txn *models.EconomyTransaction) bool {
+					return txn.CraftingProject.Status == models.CraftingStatusFailed &&
+						len(txn.Items) == 1 && txn.Items[0].Quantity == -1
+				})).Return([]*models.LedgerEntry{{CharacterID: "char-1"}}, nil)
+			},
+			validate: func(t *testing.T, progress *models.CraftingProgress) {
+				assert.False(t, progress.Check.Success)
+				assert.Equal(t, models.CraftingStatusFailed, progress.Project.Status)
+			},
+		},
+		{
+			name:        "Finished projects",
+			project:     completed,
+			days:        1,
+			expectError: "crafting project is already completed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			craftingRepo := new(MockCraftingRepository)
+			inventoryRepo := new(mocks.MockInventoryRepository)
+			characterRepo := new(mocks.MockCharacterRepository)
+			craftingRepo.On("GetProject", mock.Anything, "project-1").Return(tt.project, nil)
+			if tt.setupMocks != nil {
+				tt.setupMocks(craftingRepo, inventoryRepo, characterRepo)
+			}
+
+			service := createTestCraftingService(items, recipes, craftingRepo, inventoryRepo, characterRepo)
+			progress, err := service.Work(context.Background(), "project-1", tt.days)
+
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+			} else {
+				require.NoError(t, err)
+				if tt.validate != nil {
+					tt.validate(t, progress)
+				}
+			}
+
+			craftingRepo.AssertExpectations(t)
+			inventoryRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCraftingService_CreateHomebrewRecipe(t *testing.T) {
+	items, recipes := loadCraftingCatalogs(t)
+	craftingRepo := new(MockCraftingRepository)
+	inventoryRepo := new(mocks.MockInventoryRepository)
+	inventoryRepo.On("GetSessionItem", "glowcap_tonic", "session-1").Return(&models.Item{ID: "glowcap_tonic"}, nil)
+	inventoryRepo.On("GetSessionItem", "healing_herbs", "session-1").Return(nil, nil)
+	inventoryRepo.On("GetItem", "healing_herbs").Return(nil, nil)
+	inventoryRepo.On("CreateItem", mock.MatchedBy(func(item *models.Item) bool {
+		return item.ID == "healing_herbs"
+	})).Return(nil)
+	craftingRepo.On("CreateRecipe", mock.Anything, mock.Anything).Return(nil)
+
+	recipe := &models.Recipe{
+		Name:        "Glowcap Tonic",
+		Output:      models.RecipeComponent{ItemID: "glowcap_tonic", Quantity: 1},
+		Ingredients: []models.RecipeComponent{{ItemID: "healing_herbs", Quantity: 2}},
+		Workdays:    2,
+		DC:          12,
+	}
+	service := createTestCraftingService(items, recipes, craftingRepo, inventoryRepo, new(mocks.MockCharacterRepository))
+	err := service.CreateHomebrewRecipe(context.Background(), "session-1", recipe)
+
+	require.NoError(t, err)
+	assert.Equal(t, "session-1", *recipe.SessionID)
+	assert.Equal(t, models.RecipeCategoryGear, recipe.Category)
+	inventoryRepo.AssertExpectations(t)
+}
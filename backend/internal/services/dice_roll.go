@@ -17,6 +17,7 @@ import (
 type DiceRollService struct {
 	repo            database.DiceRollRepository
 	gameSessionRepo database.GameSessionRepository
+	events          *GameEventService
 }
 
 func NewDiceRollService(repo database.DiceRollRepository) *DiceRollService {
@@ -30,6 +31,11 @@ func (s *DiceRollService) SetGameSessionRepo(repo database.GameSessionRepository
 	s.gameSessionRepo = repo
 }
 
+// SetEventLog sets the session event log rolls are recorded in
+func (s *DiceRollService) SetEventLog(events *GameEventService) {
+	s.events = events
+}
+
 // RollDice performs a dice roll and saves it to the database
 func (s *DiceRollService) RollDice(ctx context.Context, roll *models.DiceRoll) error {
 	// Validate input
@@ -68,7 +74,24 @@ func (s *DiceRollService) RollDice(ctx context.Context, roll *models.DiceRoll) e
 	}
 
 	// Save to database
-	return s.repo.Create(ctx, roll)
+	if err := s.repo.Create(ctx, roll); err != nil {
+		return err
+	}
+
+	recordGameEvent(ctx, s.events, &models.GameEvent{
+		SessionID: roll.GameSessionID,
+		Type:      models.GameEventRoll,
+		PlayerID:  roll.UserID,
+		Data: map[string]interface{}{
+			"rollId":   roll.ID,
+			"notation": roll.RollNotation,
+			"results":  roll.Results,
+			"total":    roll.Total,
+			"purpose":  roll.Purpose,
+		},
+		Timestamp: roll.Timestamp,
+	})
+	return nil
 }
 
 // GetRollByID retrieves a dice roll by ID
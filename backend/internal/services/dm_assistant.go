@@ -16,6 +16,7 @@ import (
 type DMAssistantService struct {
 	repo        database.DMAssistantRepository
 	aiAssistant AIDMAssistantInterface
+	events      *GameEventService
 }
 
 // NewDMAssistantService creates a new DM assistant service
@@ -26,6 +27,11 @@ func NewDMAssistantService(repo database.DMAssistantRepository, aiAssistant AIDM
 	}
 }
 
+// SetEventLog sets the session event log NPC dialog is recorded in
+func (s *DMAssistantService) SetEventLog(events *GameEventService) {
+	s.events = events
+}
+
 // ProcessRequest handles a DM assistant request
 func (s *DMAssistantService) ProcessRequest(ctx context.Context, userID uuid.UUID, req models.DMAssistantRequest) (interface{}, error) {
 	gameSessionID, err := uuid.Parse(req.GameSessionID)
@@ -68,7 +74,7 @@ func (s *DMAssistantService) createHistoryEntry(gameSessionID, userID uuid.UUID,
 func (s *DMAssistantService) processRequestByType(ctx context.Context, gameSessionID, userID uuid.UUID, req models.DMAssistantRequest) (interface{}, string, error) {
 	switch req.Type {
 	case models.RequestTypeNPCDialog:
-		return s.handleNPCDialog(ctx, req)
+		return s.handleNPCDialog(ctx, gameSessionID, userID, req)
 	case models.RequestTypeLocationDesc:
 		return s.handleLocationDescription(ctx, gameSessionID, userID, req)
 	case models.RequestTypeCombatNarration:
@@ -83,7 +89,7 @@ func (s *DMAssistantService) processRequestByType(ctx context.Context, gameSessi
 }
 
 // handleNPCDialog processes NPC dialog generation requests
-func (s *DMAssistantService) handleNPCDialog(ctx context.Context, req models.DMAssistantRequest) (interface{}, string, error) {
+func (s *DMAssistantService) handleNPCDialog(ctx context.Context, gameSessionID, userID uuid.UUID, req models.DMAssistantRequest) (interface{}, string, error) {
 	npcReq, err := s.parseNPCDialogRequest(req.Parameters)
 	if err != nil {
 		return nil, "", err
@@ -96,6 +102,20 @@ func (s *DMAssistantService) handleNPCDialog(ctx context.Context, req models.DMA
 		return nil, "", err
 	}
 
+	recordGameEvent(ctx, s.events, &models.GameEvent{
+		SessionID: gameSessionID.String(),
+		Type:      models.GameEventNPCDialog,
+		PlayerID:  userID.String(),
+		Data: map[string]interface{}{
+			"npc":         npcReq.NPCName,
+			"situation":   npcReq.Situation,
+			"playerInput": npcReq.PlayerInput,
+			"dialog":      dialog,
+			// The DM drafts dialog before voicing it, so spectators don't see it early
+			models.GameEventDataHidden: true,
+		},
+	})
+
 	result := map[string]string{"dialog": dialog}
 	return result, prompt, nil
 }
@@ -28,9 +28,25 @@ const (
 	goodsAncientArtifacts = "ancient artifacts"
 )
 
+// Player trade feedback
+const (
+	// playerTradePressure is how far each net unit players buy (or sell) moves a price
+	playerTradePressure = 0.02
+	// playerTradeThreshold net units turn a category into high demand or surplus at the next cycle
+	playerTradeThreshold = 10
+)
+
+// MarketTradeRepository stores the units players trade with each settlement's market
+type MarketTradeRepository interface {
+	RecordMarketTrade(settlementID uuid.UUID, category string, bought, sold int) error
+	GetMarketTradeVolumes(settlementID uuid.UUID) ([]*models.MarketTradeVolume, error)
+	DecayMarketTradeVolumes(settlementID uuid.UUID) error
+}
+
 // EconomicSimulatorService manages market dynamics and trade
 type EconomicSimulatorService struct {
 	worldRepo WorldBuildingRepository
+	tradeRepo MarketTradeRepository
 }
 
 // NewEconomicSimulatorService creates a new economic simulator service
@@ -40,6 +56,11 @@ func NewEconomicSimulatorService(worldRepo WorldBuildingRepository) *EconomicSim
 	}
 }
 
+// SetTradeRepository lets player purchases and sales feed back into market supply and demand
+func (s *EconomicSimulatorService) SetTradeRepository(tradeRepo MarketTradeRepository) {
+	s.tradeRepo = tradeRepo
+}
+
 // SimulateEconomicCycle updates all market conditions based on various factors
 func (s *EconomicSimulatorService) SimulateEconomicCycle(ctx context.Context, gameSessionID uuid.UUID) error {
 	// Get all settlements
@@ -144,6 +165,51 @@ func (s *EconomicSimulatorService) CalculateItemPrice(settlementID uuid.UUID, ba
 		return basePrice, nil
 	}
 
+	modifier := marketPriceModifier(market, itemType)
+
+	// Add some random market fluctuation (±5%)
+	fluctuation := 0.95 + (rand.Float64() * 0.1)
+	modifier *= fluctuation
+
+	return basePrice * modifier, nil
+}
+
+// QuoteItemPrice prices an item in a settlement without random fluctuation, so a shop
+// quotes the same price it charges. It uses the market the last cycle built from world
+// events and trade routes (or builds one on the spot if the settlement has none yet)
+// and the pressure of recent player trades.
+func (s *EconomicSimulatorService) QuoteItemPrice(settlementID uuid.UUID, basePrice float64, itemType string) (float64, []models.PriceFactor) {
+	factors := make([]models.PriceFactor, 0, 2)
+
+	market, err := s.worldRepo.GetMarketBySettlement(settlementID)
+	if err != nil {
+		market = s.projectMarket(settlementID)
+	}
+	if market != nil {
+		factors = append(factors, models.PriceFactor{Name: "market", Multiplier: marketPriceModifier(market, itemType)})
+	}
+
+	if pressure := s.playerTradeModifier(settlementID, itemType); pressure != 1.0 {
+		factors = append(factors, models.PriceFactor{Name: "player trade", Multiplier: pressure})
+	}
+
+	price := basePrice
+	for _, factor := range factors {
+		price *= factor.Multiplier
+	}
+	return price, factors
+}
+
+// RecordPlayerTrade feeds units players bought from or sold to a settlement into its market
+func (s *EconomicSimulatorService) RecordPlayerTrade(settlementID uuid.UUID, itemType string, bought, sold int) error {
+	if s.tradeRepo == nil {
+		return nil
+	}
+	return s.tradeRepo.RecordMarketTrade(settlementID, itemType, bought, sold)
+}
+
+// marketPriceModifier combines a market's category modifier with its demand and conditions
+func marketPriceModifier(market *models.Market, itemType string) float64 {
 	// Apply type-specific modifiers
 	modifier := market.CommonGoodsModifier
 	switch itemType {
@@ -187,11 +253,44 @@ func (s *EconomicSimulatorService) CalculateItemPrice(settlementID uuid.UUID, ba
 		modifier *= 1.3 // Prices higher in depression
 	}
 
-	// Add some random market fluctuation (±5%)
-	fluctuation := 0.95 + (rand.Float64() * 0.1)
-	modifier *= fluctuation
+	return modifier
+}
 
-	return basePrice * modifier, nil
+// projectMarket builds market conditions for a settlement the simulator has not visited yet
+func (s *EconomicSimulatorService) projectMarket(settlementID uuid.UUID) *models.Market {
+	settlement, err := s.worldRepo.GetSettlement(settlementID)
+	if err != nil || settlement == nil {
+		return nil
+	}
+	activeEvents, err := s.worldRepo.GetActiveWorldEvents(settlement.GameSessionID)
+	if err != nil {
+		activeEvents = nil
+	}
+
+	market := newDefaultMarket(settlement.ID)
+	s.applySettlementFactors(market, settlement)
+	s.applyEventEffects(market, settlement, activeEvents)
+	s.simulateSupplyDemand(market, settlement)
+	s.applyTradeRouteEffects(market, settlement)
+	return market
+}
+
+// playerTradeModifier raises prices for goods players keep buying and lowers them for goods they keep selling
+func (s *EconomicSimulatorService) playerTradeModifier(settlementID uuid.UUID, itemType string) float64 {
+	if s.tradeRepo == nil {
+		return 1.0
+	}
+	volumes, err := s.tradeRepo.GetMarketTradeVolumes(settlementID)
+	if err != nil {
+		return 1.0
+	}
+	for _, volume := range volumes {
+		if volume.Category == itemType {
+			net := float64(volume.UnitsBought - volume.UnitsSold)
+			return math.Max(0.7, math.Min(1.5, 1.0+net*playerTradePressure))
+		}
+	}
+	return 1.0
 }
 
 // DisruptTradeRoute applies a disruption to a trade route
@@ -207,14 +306,7 @@ func (s *EconomicSimulatorService) updateSettlementMarket(_ context.Context, set
 	market, err := s.worldRepo.GetMarketBySettlement(settlement.ID)
 	if err != nil {
 		// Create new market if none exists
-		market = &models.Market{
-			SettlementID:             settlement.ID,
-			FoodPriceModifier:        1.0,
-			CommonGoodsModifier:      1.0,
-			WeaponsArmorModifier:     1.0,
-			MagicalItemsModifier:     1.0,
-			AncientArtifactsModifier: 2.0,
-		}
+		market = newDefaultMarket(settlement.ID)
 	}
 
 	// Base adjustments based on settlement properties
@@ -225,6 +317,7 @@ func (s *EconomicSimulatorService) updateSettlementMarket(_ context.Context, set
 
 	// Supply and demand simulation
 	s.simulateSupplyDemand(market, settlement)
+	s.applyPlayerTrade(market, settlement)
 
 	// Trade route effects
 	s.applyTradeRouteEffects(market, settlement)
@@ -238,6 +331,51 @@ func (s *EconomicSimulatorService) updateSettlementMarket(_ context.Context, set
 	return s.worldRepo.CreateOrUpdateMarket(market)
 }
 
+func newDefaultMarket(settlementID uuid.UUID) *models.Market {
+	return &models.Market{
+		SettlementID:             settlementID,
+		FoodPriceModifier:        1.0,
+		CommonGoodsModifier:      1.0,
+		WeaponsArmorModifier:     1.0,
+		MagicalItemsModifier:     1.0,
+		AncientArtifactsModifier: 2.0,
+		HighDemandItems:          models.JSONB("[]"),
+		SurplusItems:             models.JSONB("[]"),
+	}
+}
+
+// applyPlayerTrade turns goods the party keeps buying into high demand and goods they keep
+// selling into surplus, then decays the volumes so the market recovers over later cycles
+func (s *EconomicSimulatorService) applyPlayerTrade(market *models.Market, settlement *models.Settlement) {
+	if s.tradeRepo == nil {
+		return
+	}
+	volumes, err := s.tradeRepo.GetMarketTradeVolumes(settlement.ID)
+	if err != nil || len(volumes) == 0 {
+		return
+	}
+
+	var highDemand, surplus []string
+	_ = json.Unmarshal([]byte(market.HighDemandItems), &highDemand)
+	_ = json.Unmarshal([]byte(market.SurplusItems), &surplus)
+	for _, volume := range volumes {
+		net := volume.UnitsBought - volume.UnitsSold
+		switch {
+		case net >= playerTradeThreshold:
+			highDemand = append(highDemand, volume.Category)
+		case net <= -playerTradeThreshold:
+			surplus = append(surplus, volume.Category)
+		}
+	}
+
+	demandJSON, _ := json.Marshal(highDemand)
+	market.HighDemandItems = models.JSONB(demandJSON)
+	surplusJSON, _ := json.Marshal(surplus)
+	market.SurplusItems = models.JSONB(surplusJSON)
+
+	_ = s.tradeRepo.DecayMarketTradeVolumes(settlement.ID)
+}
+
 func (s *EconomicSimulatorService) applySettlementFactors(market *models.Market, settlement *models.Settlement) {
 	// Wealth affects general prices based on settlement level
 	wealthFactor := float64(settlement.WealthLevel) / 5.0
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/config"
+)
+
+// SMTPEmailService sends mail through an SMTP server. The job queue's email worker uses it
+// for invites and session reminders.
+type SMTPEmailService struct {
+	addr string
+	auth smtp.Auth
+	from string
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPEmailService creates an email service for the configured SMTP server
+func NewSMTPEmailService(cfg config.EmailConfig) *SMTPEmailService {
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.SMTPHost)
+	}
+	return &SMTPEmailService{
+		addr: fmt.Sprintf("%s:%d", cfg.SMTPHost, cfg.SMTPPort),
+		auth: auth,
+		from: cfg.From,
+		send: smtp.SendMail,
+	}
+}
+
+// Send sends a plain text or HTML email
+func (s *SMTPEmailService) Send(ctx context.Context, to []string, subject, body string, isHTML bool) error {
+	return s.SendWithAttachment(ctx, to, subject, body, isHTML, nil)
+}
+
+// SendWithAttachment sends an email with files attached
+func (s *SMTPEmailService) SendWithAttachment(ctx context.Context, to []string, subject, body string, isHTML bool, attachments []Attachment) error {
+	if len(to) == 0 {
+		return fmt.Errorf("email has no recipients")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	message, err := s.buildMessage(to, subject, body, isHTML, attachments)
+	if err != nil {
+		return err
+	}
+	if err := s.send(s.addr, s.auth, s.from, to, message); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// SendTemplate is not supported: the game writes its emails itself
+func (s *SMTPEmailService) SendTemplate(ctx context.Context, to []string, templateName string, data interface{}) error {
+	return fmt.Errorf("email template %q is not available", templateName)
+}
+
+func (s *SMTPEmailService) buildMessage(to []string, subject, body string, isHTML bool, attachments []Attachment) ([]byte, error) {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+
+	contentType := "text/plain; charset=utf-8"
+	if isHTML {
+		contentType = "text/html; charset=utf-8"
+	}
+	if len(attachments) == 0 {
+		fmt.Fprintf(&msg, "Content-Type: %s\r\n\r\n%s", contentType, body)
+		return msg.Bytes(), nil
+	}
+
+	parts := multipart.NewWriter(&msg)
+	fmt.Fprintf(&msg, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", parts.Boundary())
+	text, err := parts.CreatePart(textproto.MIMEHeader{"Content-Type": {contentType}})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := text.Write([]byte(body)); err != nil {
+		return nil, err
+	}
+	for _, attachment := range attachments {
+		part, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {attachment.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := part.Write([]byte(base64.StdEncoding.EncodeToString(attachment.Data))); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return msg.Bytes(), nil
+}
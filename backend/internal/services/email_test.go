@@ -0,0 +1,34 @@
+package services
+
+import (
+	"context"
+	"net/smtp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/config"
+)
+
+func TestSMTPEmailService_Send(t *testing.T) {
+	svc := NewSMTPEmailService(config.EmailConfig{SMTPHost: "mail.example.com", SMTPPort: 2525, From: "dm@example.com"})
+	var addr, from string
+	var to []string
+	var message []byte
+	svc.send = func(a string, _ smtp.Auth, f string, t []string, msg []byte) error {
+		addr, from, to, message = a, f, t, msg
+		return nil
+	}
+
+	err := svc.Send(context.Background(), []string{"alice@example.com"}, "Game night", "Bring dice.", false)
+	require.NoError(t, err)
+
+	assert.Equal(t, "mail.example.com:2525", addr)
+	assert.Equal(t, "dm@example.com", from)
+	assert.Equal(t, []string{"alice@example.com"}, to)
+	assert.Contains(t, string(message), "Subject: Game night\r\n")
+	assert.Contains(t, string(message), "Content-Type: text/plain; charset=utf-8\r\n\r\nBring dice.")
+
+	assert.Error(t, svc.Send(context.Background(), nil, "Game night", "Bring dice.", false))
+}
@@ -30,6 +30,7 @@ type EncounterService struct {
 	repo             *database.EncounterRepository
 	encounterBuilder *AIEncounterBuilder
 	combatService    *CombatService
+	loot             *LootService
 }
 
 func NewEncounterService(repo *database.EncounterRepository, builder *AIEncounterBuilder, combat *CombatService) *EncounterService {
@@ -40,6 +41,11 @@ func NewEncounterService(repo *database.EncounterRepository, builder *AIEncounte
 	}
 }
 
+// SetLootService puts the rewards of completed objectives into loot pools for the party
+func (s *EncounterService) SetLootService(loot *LootService) {
+	s.loot = loot
+}
+
 // GenerateEncounter creates a new AI-generated encounter
 func (s *EncounterService) GenerateEncounter(ctx context.Context, req *EncounterRequest, gameSessionID, userID string) (*models.Encounter, error) {
 	// Generate the encounter using AI
@@ -404,15 +410,20 @@ func (s *EncounterService) applyScalingAdjustment(encounter *models.Encounter, a
 	// Add terrain - future enhancement
 }
 
-func (s *EncounterService) awardObjectiveRewards(_ context.Context, objective *models.EncounterObjective, _ string) {
-	// Award XP to party members
-	// This would integrate with character service to add XP
-
-	// Award gold
-	// This would integrate with inventory service
-
-	// Award items
-	_ = objective.ItemRewards // Placeholder for item rewards integration
+// awardObjectiveRewards puts a completed objective's XP, gold and items into a loot pool for the
+// session's characters, to be handed out like any other loot
+func (s *EncounterService) awardObjectiveRewards(ctx context.Context, objective *models.EncounterObjective, gameSessionID string) {
+	description := fmt.Sprintf("Objective completed: %s. Rewards: %d XP, %d gold",
+		objective.Description, objective.XPReward, objective.GoldReward)
+	if s.loot != nil {
+		pool, err := s.loot.PoolFromObjective(ctx, objective, gameSessionID)
+		switch {
+		case err != nil:
+			description += fmt.Sprintf(" (loot pool could not be created: %v)", err)
+		case pool != nil:
+			description += fmt.Sprintf(", held in loot pool %s", pool.ID)
+		}
+	}
 
 	// Log the rewards
 	event := &models.EncounterEvent{
@@ -421,8 +432,7 @@ func (s *EncounterService) awardObjectiveRewards(_ context.Context, objective *m
 		EventType:   "rewards_granted",
 		ActorType:   "system",
 		ActorName:   "System",
-		Description: fmt.Sprintf("Objective completed: %s. Rewards: %d XP, %d gold",
-			objective.Description, objective.XPReward, objective.GoldReward),
+		Description: description,
 	}
 	_ = s.repo.CreateEvent(event)
 }
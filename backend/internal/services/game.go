@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"time"
@@ -19,6 +20,7 @@ type GameService struct {
 	mu       sync.RWMutex
 	sessions map[string]*models.GameSession
 	events   map[string][]*models.GameEvent
+	log      *GameEventService
 }
 
 func NewGameService() *GameService {
@@ -28,6 +30,11 @@ func NewGameService() *GameService {
 	}
 }
 
+// SetEventLog records events in the durable session event log rather than in memory
+func (s *GameService) SetEventLog(log *GameEventService) {
+	s.log = log
+}
+
 func (s *GameService) CreateSession(session *models.GameSession) (*models.GameSession, error) {
 	session.ID = generateID()
 	session.Status = models.GameStatusActive
@@ -68,6 +75,10 @@ func (s *GameService) AddPlayerToSession(sessionID, _ string, player *models.Pla
 }
 
 func (s *GameService) RecordGameEvent(event *models.GameEvent) error {
+	if s.log != nil {
+		return s.log.Record(context.Background(), event)
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -83,6 +94,10 @@ func (s *GameService) RecordGameEvent(event *models.GameEvent) error {
 }
 
 func (s *GameService) GetSessionEvents(sessionID string) ([]*models.GameEvent, error) {
+	if s.log != nil {
+		return s.log.GetSessionEvents(context.Background(), sessionID, models.GameEventFilter{})
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	events, exists := s.events[sessionID]
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/database"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/logger"
+)
+
+const (
+	defaultTimelinePageSize = 100
+	maxTimelinePageSize     = 500
+)
+
+// GameEventService keeps each session's event log. Rolls, chat, combat, NPC dialog, loot
+// and experience are all recorded here, and the timeline, recap, analytics and exports
+// all read from it.
+type GameEventService struct {
+	repo database.GameEventRepository
+}
+
+// NewGameEventService creates a new game event service
+func NewGameEventService(repo database.GameEventRepository) *GameEventService {
+	return &GameEventService{repo: repo}
+}
+
+// Record appends an event to its session's log
+func (s *GameEventService) Record(ctx context.Context, event *models.GameEvent) error {
+	if event.SessionID == "" {
+		return fmt.Errorf("game session ID is required")
+	}
+	if event.Type == "" {
+		return fmt.Errorf("event type is required")
+	}
+	return s.repo.Append(ctx, event)
+}
+
+// GetSessionTimeline returns a page of a session's log, oldest first
+func (s *GameEventService) GetSessionTimeline(ctx context.Context, sessionID string, filter models.GameEventFilter) (*models.GameEventPage, error) {
+	filter.SessionID = sessionID
+	return s.page(ctx, filter)
+}
+
+// GetCharacterTimeline returns a page of everything that happened to a character, across
+// every session they played in, oldest first
+func (s *GameEventService) GetCharacterTimeline(ctx context.Context, characterID string, filter models.GameEventFilter) (*models.GameEventPage, error) {
+	filter.CharacterID = characterID
+	return s.page(ctx, filter)
+}
+
+// GetSessionEvents returns every event matching the filter in a session, for recaps and
+// exports that need the whole log rather than a page of it
+func (s *GameEventService) GetSessionEvents(ctx context.Context, sessionID string, filter models.GameEventFilter) ([]*models.GameEvent, error) {
+	filter.SessionID = sessionID
+	filter.After = ""
+	filter.Limit = 0
+	return s.repo.List(ctx, filter)
+}
+
+// GetCharacterEvents returns everything that happened to a character, across every
+// session they played in, for exports that need the whole log
+func (s *GameEventService) GetCharacterEvents(ctx context.Context, characterID string) ([]*models.GameEvent, error) {
+	return s.repo.List(ctx, models.GameEventFilter{CharacterID: characterID})
+}
+
+// Summarize counts the events of a session a participant with the given role may see, by
+// type, player and character
+func (s *GameEventService) Summarize(ctx context.Context, sessionID string, role models.ParticipantRole) (*models.GameEventSummary, error) {
+	events, err := s.GetSessionEvents(ctx, sessionID, models.GameEventFilter{})
+	if err != nil {
+		return nil, err
+	}
+	events = VisibleEvents(events, role)
+
+	summary := &models.GameEventSummary{
+		SessionID:   sessionID,
+		Total:       len(events),
+		ByType:      make(map[string]int),
+		ByPlayer:    make(map[string]int),
+		ByCharacter: make(map[string]int),
+	}
+	for _, event := range events {
+		summary.ByType[event.Type]++
+		if event.PlayerID != "" {
+			summary.ByPlayer[event.PlayerID]++
+		}
+		if event.CharacterID != nil {
+			summary.ByCharacter[*event.CharacterID]++
+		}
+	}
+	if len(events) > 0 {
+		summary.FirstAt = &events[0].Timestamp
+		summary.LastAt = &events[len(events)-1].Timestamp
+	}
+	return summary, nil
+}
+
+// VisibleEvents keeps the events a participant with the given role may see
+func VisibleEvents(events []*models.GameEvent, role models.ParticipantRole) []*models.GameEvent {
+	visible := make([]*models.GameEvent, 0, len(events))
+	for _, event := range events {
+		if event.VisibleTo(role) {
+			visible = append(visible, event)
+		}
+	}
+	return visible
+}
+
+// page fetches one page of the log, one event past the page to tell whether there is
+// another
+func (s *GameEventService) page(ctx context.Context, filter models.GameEventFilter) (*models.GameEventPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultTimelinePageSize
+	}
+	if filter.Limit > maxTimelinePageSize {
+		filter.Limit = maxTimelinePageSize
+	}
+	pageSize := filter.Limit
+	filter.Limit++
+
+	events, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.GameEventPage{Events: events}
+	if len(events) > pageSize {
+		page.Events = events[:pageSize]
+		page.NextCursor = page.Events[pageSize-1].ID
+	}
+	return page, nil
+}
+
+// recordGameEvent writes an event to the log for the services that feed it. The log is
+// optional and a failure to write it is logged rather than undoing what already happened.
+func recordGameEvent(ctx context.Context, log *GameEventService, event *models.GameEvent) {
+	if log == nil {
+		return
+	}
+	if err := log.Record(ctx, event); err != nil {
+		logger.WithContext(ctx).WithError(err).Error().
+			Str("session_id", event.SessionID).
+			Str("type", event.Type).
+			Msg("Failed to record game event")
+	}
+}
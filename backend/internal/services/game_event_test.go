@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/internal/services/mocks"
+)
+
+// MockGameEventRepository mocks the session event log
+type MockGameEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockGameEventRepository) Append(ctx context.Context, event *models.GameEvent) error {
+	args := m.Called(ctx, event)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockGameEventRepository) List(ctx context.Context, filter models.GameEventFilter) ([]*models.GameEvent, error) {
+	args := m.Called(ctx, filter)
+	return mockSliceReturn[models.GameEvent](args, 0, 1)
+}
+
+func gameEvents(n int) []*models.GameEvent {
+	start := time.Date(2024, 1, 1, 19, 0, 0, 0, time.UTC)
+	events := make([]*models.GameEvent, n)
+	for i := range events {
+		events[i] = &models.GameEvent{
+			ID:        uuid.New().String(),
+			SessionID: "session-1",
+			Type:      models.GameEventRoll,
+			PlayerID:  "player-1",
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+		}
+	}
+	return events
+}
+
+func TestGameEventService_Record(t *testing.T) {
+	t.Run("appends to the session's log", func(t *testing.T) {
+		repo := new(MockGameEventRepository)
+		service := NewGameEventService(repo)
+		event := &models.GameEvent{SessionID: "session-1", Type: models.GameEventRoll}
+		repo.On("Append", mock.Anything, event).Return(nil)
+
+		require.NoError(t, service.Record(context.Background(), event))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("requires a session and a type", func(t *testing.T) {
+		repo := new(MockGameEventRepository)
+		service := NewGameEventService(repo)
+
+		assert.Error(t, service.Record(context.Background(), &models.GameEvent{Type: models.GameEventRoll}))
+		assert.Error(t, service.Record(context.Background(), &models.GameEvent{SessionID: "session-1"}))
+		repo.AssertNotCalled(t, "Append", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGameEventService_GetSessionTimeline(t *testing.T) {
+	t.Run("a full page carries the cursor to the next", func(t *testing.T) {
+		repo := new(MockGameEventRepository)
+		service := NewGameEventService(repo)
+		events := gameEvents(3)
+		repo.On("List", mock.Anything, models.GameEventFilter{SessionID: "session-1", Types: []string{models.GameEventRoll}, Limit: 3}).
+			Return(events, nil)
+
+		page, err := service.GetSessionTimeline(context.Background(), "session-1",
+			models.GameEventFilter{Types: []string{models.GameEventRoll}, Limit: 2})
+
+		require.NoError(t, err)
+		assert.Len(t, page.Events, 2)
+		assert.Equal(t, events[1].ID, page.NextCursor)
+	})
+
+	t.Run("the last page has no cursor", func(t *testing.T) {
+		repo := new(MockGameEventRepository)
+		service := NewGameEventService(repo)
+		repo.On("List", mock.Anything, models.GameEventFilter{SessionID: "session-1", Limit: defaultTimelinePageSize + 1}).
+			Return(gameEvents(2), nil)
+
+		page, err := service.GetSessionTimeline(context.Background(), "session-1", models.GameEventFilter{})
+
+		require.NoError(t, err)
+		assert.Len(t, page.Events, 2)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("page size is capped", func(t *testing.T) {
+		repo := new(MockGameEventRepository)
+		service := NewGameEventService(repo)
+		repo.On("List", mock.Anything, models.GameEventFilter{CharacterID: "char-1", Limit: maxTimelinePageSize + 1}).
+			Return([]*models.GameEvent{}, nil)
+
+		_, err := service.GetCharacterTimeline(context.Background(), "char-1", models.GameEventFilter{Limit: 10000})
+
+		require.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestGameEventService_Summarize(t *testing.T) {
+	repo := new(MockGameEventRepository)
+	service := NewGameEventService(repo)
+	characterID := "char-1"
+	events := gameEvents(3)
+	events[2].Type = models.GameEventLoot
+	events[2].PlayerID = "dm-1"
+	events[2].CharacterID = &characterID
+	repo.On("List", mock.Anything, models.GameEventFilter{SessionID: "session-1"}).Return(events, nil)
+
+	summary, err := service.Summarize(context.Background(), "session-1", models.ParticipantRoleDM)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, summary.Total)
+	assert.Equal(t, map[string]int{models.GameEventRoll: 2, models.GameEventLoot: 1}, summary.ByType)
+	assert.Equal(t, map[string]int{"player-1": 2, "dm-1": 1}, summary.ByPlayer)
+	assert.Equal(t, map[string]int{"char-1": 1}, summary.ByCharacter)
+	assert.Equal(t, events[0].Timestamp, *summary.FirstAt)
+	assert.Equal(t, events[2].Timestamp, *summary.LastAt)
+}
+
+func TestGameEventService_SummarizeVisibility(t *testing.T) {
+	repo := new(MockGameEventRepository)
+	service := NewGameEventService(repo)
+	events := gameEvents(3)
+	events[1].Data = map[string]interface{}{models.GameEventDataHidden: true}
+	events[2].Data = map[string]interface{}{models.GameEventDataDMOnly: true}
+	repo.On("List", mock.Anything, models.GameEventFilter{SessionID: "session-1"}).Return(events, nil)
+
+	for role, total := range map[models.ParticipantRole]int{
+		models.ParticipantRoleDM:        3,
+		models.ParticipantRolePlayer:    2,
+		models.ParticipantRoleSpectator: 1,
+	} {
+		summary, err := service.Summarize(context.Background(), "session-1", role)
+		require.NoError(t, err)
+		assert.Equal(t, total, summary.Total, "%s sees %d events", role, total)
+		assert.Equal(t, total, summary.ByPlayer["player-1"])
+	}
+}
+
+func TestGameEventLog_Feeds(t *testing.T) {
+	chatFeeds := []struct {
+		name       string
+		request    models.ChatMessageRequest
+		setupMocks func(*MockGameEventRepository)
+	}{
+		{
+			name:    "public chat is recorded",
+			request: models.ChatMessageRequest{Message: "Well met!"},
+			setupMocks: func(repo *MockGameEventRepository) {
+				repo.On("Append", mock.Anything, mock.MatchedBy(func(event *models.GameEvent) bool {
+					return event.Type == models.GameEventChat && event.Data["message"] == "Well met!"
+				})).Return(nil).Once()
+			},
+		},
+		{
+			name:       "whispers are not recorded",
+			request:    models.ChatMessageRequest{Message: "psst", Recipients: []string{"player-2"}},
+			setupMocks: func(*MockGameEventRepository) {},
+		},
+		{
+			name:    "a failed write does not fail the message",
+			request: models.ChatMessageRequest{Message: "Well met!"},
+			setupMocks: func(repo *MockGameEventRepository) {
+				repo.On("Append", mock.Anything, mock.Anything).Return(assert.AnError)
+			},
+		},
+	}
+	for _, tt := range chatFeeds {
+		t.Run(tt.name, func(t *testing.T) {
+			chatRepo := new(MockChatRepository)
+			sessions := new(mocks.MockGameSessionRepository)
+			repo := new(MockGameEventRepository)
+			setupChatSession(sessions, nil, chatPlayers()...)
+			chatRepo.On("CreateMessage", mock.Anything, mock.AnythingOfType("*models.ChatMessage")).Return(nil)
+			tt.setupMocks(repo)
+
+			service := createTestChatService(chatRepo, sessions, new(mocks.MockDiceRollRepository))
+			service.SetEventLog(NewGameEventService(repo))
+			_, err := service.SendMessage(context.Background(), "session-1", "player-1", "player-1", &tt.request)
+
+			require.NoError(t, err)
+			repo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("NPC dialog is hidden from spectators", func(t *testing.T) {
+		dmRepo := new(mocks.MockDMAssistantRepository)
+		ai := new(mocks.MockAIDMAssistantService)
+		assistant := NewDMAssistantService(dmRepo, ai)
+		repo := new(MockGameEventRepository)
+		assistant.SetEventLog(NewGameEventService(repo))
+		ai.On("GenerateNPCDialog", mock.Anything, mock.Anything).Return("Mind the cellar.", nil)
+		dmRepo.On("SaveHistory", mock.Anything, mock.Anything).Return(nil)
+		repo.On("Append", mock.Anything, mock.MatchedBy(func(event *models.GameEvent) bool {
+			return event.Type == models.GameEventNPCDialog && event.HiddenFromSpectators()
+		})).Return(nil).Once()
+
+		_, err := assistant.ProcessRequest(context.Background(), uuid.New(), models.DMAssistantRequest{
+			Type:          models.RequestTypeNPCDialog,
+			GameSessionID: uuid.New().String(),
+			Parameters:    map[string]interface{}{"npcName": "Bartok", "playerInput": "Any work?"},
+		})
+		require.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestMemoryFromEvents(t *testing.T) {
+	sessionID := uuid.New()
+	characterID := "char-1"
+	events := gameEvents(2)
+	events = append(events,
+		&models.GameEvent{Type: models.GameEventNPCDialog, Data: map[string]interface{}{"npc": "Bartok"}},
+		&models.GameEvent{Type: models.GameEventNPCDialog, Data: map[string]interface{}{"npc": "Bartok"}},
+		&models.GameEvent{Type: models.GameEventLoot, CharacterID: &characterID,
+			Data: map[string]interface{}{"items": []interface{}{"Longsword"}}},
+		&models.GameEvent{Type: models.GameEventExperience, Data: map[string]interface{}{"experience": float64(150)}},
+	)
+
+	memory := memoryFromEvents(sessionID, []*models.SessionMemory{{SessionNumber: 4}}, events)
+
+	require.NotNil(t, memory)
+	assert.Equal(t, 5, memory.SessionNumber)
+	assert.Equal(t, "Since the last session, the party rolled the dice 2 times; spoke with Bartok; found Longsword; earned 150 experience.",
+		memory.RecapSummary)
+	assert.JSONEq(t, `["Bartok"]`, string(memory.NPCsEncountered))
+	assert.Nil(t, memoryFromEvents(sessionID, nil, nil))
+}
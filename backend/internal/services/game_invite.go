@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/database"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/logger"
+)
+
+const (
+	errMsgInviteNotFound = "invite not found"
+
+	defaultInviteLifetime = 7 * 24 * time.Hour
+	maxInviteUses         = 100
+)
+
+// GameInviteService lets a DM invite players to a private game session, by email or with
+// a shareable link, and lets invited players join with one of their characters
+type GameInviteService struct {
+	repo     database.GameInviteRepository
+	sessions *GameSessionService
+	users    database.UserRepository
+	emails   EmailQueue
+	now      func() time.Time
+}
+
+// NewGameInviteService creates a new game invite service
+func NewGameInviteService(repo database.GameInviteRepository, sessions *GameSessionService, users database.UserRepository) *GameInviteService {
+	return &GameInviteService{
+		repo:     repo,
+		sessions: sessions,
+		users:    users,
+		now:      time.Now,
+	}
+}
+
+// SetEmailQueue sets the job queue invite emails are sent through
+func (s *GameInviteService) SetEmailQueue(emails EmailQueue) {
+	s.emails = emails
+}
+
+// CreateInvite invites a player to a session by email or, without an email, creates a
+// shareable link. An emailed invite can be redeemed once; a link up to its max uses, which
+// default to the session's player seats.
+func (s *GameInviteService) CreateInvite(ctx context.Context, sessionID, inviterID string, req *models.GameInviteRequest) (*models.GameInvite, error) {
+	session, err := s.sessions.GetSessionByID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status == models.GameStatusCompleted {
+		return nil, fmt.Errorf("cannot invite players to a completed session")
+	}
+
+	lifetime := defaultInviteLifetime
+	if req.ExpiresInHours > 0 {
+		lifetime = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+	invite := &models.GameInvite{
+		SessionID:    sessionID,
+		InviterID:    inviterID,
+		InviteeEmail: strings.ToLower(strings.TrimSpace(req.Email)),
+		MaxUses:      1,
+		ExpiresAt:    s.now().Add(lifetime),
+	}
+	if invite.IsLink() {
+		invite.MaxUses = req.MaxUses
+		if invite.MaxUses == 0 {
+			invite.MaxUses = session.MaxPlayers - 1 // the DM doesn't take a seat
+		}
+		if invite.MaxUses < 1 || invite.MaxUses > maxInviteUses {
+			return nil, fmt.Errorf("max uses must be between 1 and %d", maxInviteUses)
+		}
+	} else {
+		if !strings.Contains(invite.InviteeEmail, "@") {
+			return nil, fmt.Errorf("invalid email address")
+		}
+		if req.MaxUses > 1 {
+			return nil, fmt.Errorf("an emailed invite can only be used once")
+		}
+	}
+
+	code, err := generateInviteCode()
+	if err != nil {
+		return nil, err
+	}
+	invite.Code = code
+	if err := s.repo.Create(ctx, invite); err != nil {
+		return nil, err
+	}
+
+	if !invite.IsLink() {
+		s.sendInvite(ctx, session, invite)
+	}
+	return invite, nil
+}
+
+// GetInvite returns an invite by ID
+func (s *GameInviteService) GetInvite(ctx context.Context, inviteID string) (*models.GameInvite, error) {
+	invite, err := s.repo.GetByID(ctx, inviteID)
+	if err != nil {
+		return nil, err
+	}
+	if invite == nil {
+		return nil, fmt.Errorf(errMsgInviteNotFound)
+	}
+	return invite, nil
+}
+
+// ListPendingInvites returns a session's invites that can still be redeemed
+func (s *GameInviteService) ListPendingInvites(ctx context.Context, sessionID string) ([]*models.GameInvite, error) {
+	return s.repo.ListPending(ctx, sessionID, s.now())
+}
+
+// RevokeInvite stops an invite from being redeemed. Players who already joined with it
+// stay in the session.
+func (s *GameInviteService) RevokeInvite(ctx context.Context, inviteID string) error {
+	return s.repo.Revoke(ctx, inviteID, s.now())
+}
+
+// RedeemInvite joins the user to the invite's session with one of their characters, or as
+// a spectator. An emailed invite can only be redeemed by the account with that email
+// address.
+func (s *GameInviteService) RedeemInvite(ctx context.Context, code, userID string, req *models.RedeemInviteRequest) (*models.GameInvite, error) {
+	if req.CharacterID == "" && !req.Spectate {
+		return nil, fmt.Errorf("a character is required to join")
+	}
+	invite, err := s.repo.GetByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if invite == nil {
+		return nil, fmt.Errorf(errMsgInviteNotFound)
+	}
+	if err := s.checkRedeemable(ctx, invite, userID); err != nil {
+		return nil, err
+	}
+
+	characterID := req.CharacterID
+	join := func() error { return s.sessions.repo.AddParticipant(ctx, invite.SessionID, userID, &characterID) }
+	if req.Spectate {
+		err = s.sessions.prepareSpectate(ctx, invite.SessionID, userID, true)
+		join = func() error { return s.sessions.repo.AddSpectator(ctx, invite.SessionID, userID) }
+	} else {
+		err = s.sessions.prepareJoin(ctx, invite.SessionID, userID, &characterID, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+	claimed, err := s.repo.Claim(ctx, invite.ID, userID, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, fmt.Errorf("invite is no longer valid")
+	}
+	if err := join(); err != nil {
+		if releaseErr := s.repo.Release(ctx, invite.ID); releaseErr != nil {
+			logger.WithContext(ctx).WithError(releaseErr).Error().
+				Str("invite_id", invite.ID).
+				Msg("Failed to release game invite")
+		}
+		return nil, err
+	}
+
+	return s.GetInvite(ctx, invite.ID)
+}
+
+// checkRedeemable explains why an invite cannot be redeemed by the user, if it cannot
+func (s *GameInviteService) checkRedeemable(ctx context.Context, invite *models.GameInvite, userID string) error {
+	switch {
+	case invite.RevokedAt != nil:
+		return fmt.Errorf("invite has been revoked")
+	case !s.now().Before(invite.ExpiresAt):
+		return fmt.Errorf("invite has expired")
+	case invite.UseCount >= invite.MaxUses && invite.IsLink():
+		return fmt.Errorf("invite link has reached its maximum uses")
+	case invite.UseCount >= invite.MaxUses:
+		return fmt.Errorf("invite has already been used")
+	}
+	if invite.IsLink() {
+		return nil
+	}
+
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(user.Email, invite.InviteeEmail) {
+		return fmt.Errorf("this invite was sent to a different email address")
+	}
+	return nil
+}
+
+// sendInvite queues the email carrying an invite's code. Failing to queue it is logged
+// rather than undoing the invite, which the DM can still pass on themselves.
+func (s *GameInviteService) sendInvite(ctx context.Context, session *models.GameSession, invite *models.GameInvite) {
+	if s.emails == nil {
+		return
+	}
+
+	inviter := "Your dungeon master"
+	if user, err := s.users.GetByID(ctx, invite.InviterID); err == nil && user.Username != "" {
+		inviter = user.Username
+	}
+	subject := fmt.Sprintf("%s invited you to %s", inviter, session.Name)
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s has invited you to join the game session %s.\n\n", inviter, session.Name)
+	if session.Description != "" {
+		fmt.Fprintf(&body, "%s\n\n", session.Description)
+	}
+	fmt.Fprintf(&body, "Your invite code is %s. Sign in with this email address and redeem it with the character "+
+		"you want to play before %s.\n", invite.Code, invite.ExpiresAt.UTC().Format("January 2, 2006 at 15:04 MST"))
+
+	if err := s.emails.EnqueueEmail(ctx, []string{invite.InviteeEmail}, subject, body.String(), false); err != nil {
+		logger.WithContext(ctx).WithError(err).Error().
+			Str("invite_id", invite.ID).
+			Str("session_id", session.ID).
+			Msg("Failed to queue game invite email")
+	}
+}
+
+// generateInviteCode generates the secret code an invite is redeemed with
+func generateInviteCode() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate invite code: %w", err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
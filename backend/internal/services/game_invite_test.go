@@ -0,0 +1,301 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/internal/services/mocks"
+)
+
+// MockGameInviteRepository mocks game invite storage
+type MockGameInviteRepository struct {
+	mock.Mock
+}
+
+func (m *MockGameInviteRepository) Create(ctx context.Context, invite *models.GameInvite) error {
+	args := m.Called(ctx, invite)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockGameInviteRepository) GetByID(ctx context.Context, id string) (*models.GameInvite, error) {
+	args := m.Called(ctx, id)
+	return mockSingleReturn[models.GameInvite](args, 0, 1)
+}
+
+func (m *MockGameInviteRepository) GetByCode(ctx context.Context, code string) (*models.GameInvite, error) {
+	args := m.Called(ctx, code)
+	return mockSingleReturn[models.GameInvite](args, 0, 1)
+}
+
+func (m *MockGameInviteRepository) ListPending(ctx context.Context, sessionID string, now time.Time) ([]*models.GameInvite, error) {
+	args := m.Called(ctx, sessionID, now)
+	return mockSliceReturn[models.GameInvite](args, 0, 1)
+}
+
+func (m *MockGameInviteRepository) Claim(ctx context.Context, id, userID string, now time.Time) (bool, error) {
+	args := m.Called(ctx, id, userID, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockGameInviteRepository) Release(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockGameInviteRepository) Revoke(ctx context.Context, id string, now time.Time) error {
+	args := m.Called(ctx, id, now)
+	return mockErrorReturn(args, 0)
+}
+
+// fakeEmailQueue records the emails queued for sending
+type fakeEmailQueue struct {
+	to       [][]string
+	subjects []string
+	bodies   []string
+}
+
+func (q *fakeEmailQueue) EnqueueEmail(_ context.Context, to []string, subject, body string, _ bool) error {
+	q.to = append(q.to, to)
+	q.subjects = append(q.subjects, subject)
+	q.bodies = append(q.bodies, body)
+	return nil
+}
+
+var testInviteNow = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func createTestGameInviteService(repo *MockGameInviteRepository, sessions *mocks.MockGameSessionRepository, characters *mocks.MockCharacterRepository, users *mocks.MockUserRepository, emails *fakeEmailQueue) *GameInviteService {
+	gameSessions := NewGameSessionService(sessions)
+	gameSessions.SetCharacterRepository(characters)
+	service := NewGameInviteService(repo, gameSessions, users)
+	service.SetEmailQueue(emails)
+	service.now = func() time.Time { return testInviteNow }
+	return service
+}
+
+// setupInviteSession runs the invite only session-1 for dm-1, with player-1 and their char-1 waiting to join
+func setupInviteSession(sessions *mocks.MockGameSessionRepository, characters *mocks.MockCharacterRepository, users *mocks.MockUserRepository) {
+	sessions.On("GetByID", mock.Anything, "session-1").Return(&models.GameSession{
+		ID: "session-1", DMID: "dm-1", Name: "Curse of Strahd", Status: models.GameStatusActive, IsActive: true,
+		MaxPlayers: 5, RequiresInvite: true,
+	}, nil)
+	sessions.On("GetParticipants", mock.Anything, "session-1").Return([]*models.GameParticipant{
+		{SessionID: "session-1", UserID: "dm-1"},
+	}, nil)
+	users.On("GetByID", mock.Anything, "dm-1").Return(&models.User{ID: "dm-1", Username: "Mira"}, nil)
+	users.On("GetByID", mock.Anything, "player-1").
+		Return(&models.User{ID: "player-1", Username: "Rook", Email: "rook@example.com"}, nil)
+	characters.On("GetByID", mock.Anything, "char-1").Return(&models.Character{ID: "char-1", UserID: "player-1", Level: 3}, nil)
+}
+
+// pendingInvite is an unused emailed invite to rook@example.com
+func pendingInvite() *models.GameInvite {
+	return &models.GameInvite{ID: "invite-1", SessionID: "session-1", InviteeEmail: "rook@example.com",
+		Code: "code-1", MaxUses: 1, ExpiresAt: testInviteNow.Add(time.Hour)}
+}
+
+func TestGameInviteService_CreateInvite(t *testing.T) {
+	tests := []struct {
+		name        string
+		request     models.GameInviteRequest
+		expectError bool
+		validate    func(*testing.T, *models.GameInvite, *fakeEmailQueue)
+	}{
+		{
+			name:    "Emailed invite",
+			request: models.GameInviteRequest{Email: " Rook@Example.com "},
+			validate: func(t *testing.T, invite *models.GameInvite, emails *fakeEmailQueue) {
+				assert.Equal(t, "rook@example.com", invite.InviteeEmail)
+				assert.Equal(t, 1, invite.MaxUses)
+				assert.Len(t, invite.Code, 32)
+				assert.Equal(t, testInviteNow.Add(defaultInviteLifetime), invite.ExpiresAt)
+				require.Len(t, emails.to, 1)
+				assert.Equal(t, []string{"rook@example.com"}, emails.to[0])
+				assert.Equal(t, "Mira invited you to Curse of Strahd", emails.subjects[0])
+				assert.Contains(t, emails.bodies[0], invite.Code)
+			},
+		},
+		{
+			name:    "Shareable link",
+			request: models.GameInviteRequest{ExpiresInHours: 48},
+			validate: func(t *testing.T, invite *models.GameInvite, emails *fakeEmailQueue) {
+				assert.True(t, invite.IsLink())
+				assert.Equal(t, 4, invite.MaxUses, "a link defaults to the session's player seats")
+				assert.Equal(t, testInviteNow.Add(48*time.Hour), invite.ExpiresAt)
+				assert.Empty(t, emails.to)
+			},
+		},
+		{
+			name:        "Emailed invite with more than one use",
+			request:     models.GameInviteRequest{Email: "rook@example.com", MaxUses: 3},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockGameInviteRepository)
+			sessions := new(mocks.MockGameSessionRepository)
+			characters := new(mocks.MockCharacterRepository)
+			users := new(mocks.MockUserRepository)
+			emails := &fakeEmailQueue{}
+			setupInviteSession(sessions, characters, users)
+			if !tt.expectError {
+				repo.On("Create", mock.Anything, mock.AnythingOfType("*models.GameInvite")).Return(nil)
+			}
+
+			service := createTestGameInviteService(repo, sessions, characters, users, emails)
+			invite, err := service.CreateInvite(context.Background(), "session-1", "dm-1", &tt.request)
+
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				tt.validate(t, invite, emails)
+			}
+
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGameInviteService_RedeemInvite(t *testing.T) {
+	revokedAt := testInviteNow.Add(-time.Minute)
+
+	tests := []struct {
+		name        string
+		request     models.RedeemInviteRequest
+		setupMocks  func(*MockGameInviteRepository, *mocks.MockGameSessionRepository)
+		expectError string
+	}{
+		{
+			name:    "Player with their character",
+			request: models.RedeemInviteRequest{CharacterID: "char-1"},
+			setupMocks: func(repo *MockGameInviteRepository, sessions *mocks.MockGameSessionRepository) {
+				invite := pendingInvite()
+				repo.On("GetByCode", mock.Anything, "code-1").Return(invite, nil)
+				repo.On("Claim", mock.Anything, "invite-1", "player-1", testInviteNow).Return(true, nil)
+				sessions.On("AddParticipant", mock.Anything, "session-1", "player-1",
+					mock.MatchedBy(func(id *string) bool { return *id == "char-1" })).Return(nil)
+				repo.On("GetByID", mock.Anything, "invite-1").Return(invite, nil)
+			},
+		},
+		{
+			name:    "Spectator without a character",
+			request: models.RedeemInviteRequest{Spectate: true},
+			setupMocks: func(repo *MockGameInviteRepository, sessions *mocks.MockGameSessionRepository) {
+				invite := pendingInvite()
+				repo.On("GetByCode", mock.Anything, "code-1").Return(invite, nil)
+				repo.On("Claim", mock.Anything, "invite-1", "player-1", testInviteNow).Return(true, nil)
+				sessions.On("AddSpectator", mock.Anything, "session-1", "player-1").Return(nil)
+				repo.On("GetByID", mock.Anything, "invite-1").Return(invite, nil)
+			},
+		},
+		{
+			name:    "Expired invite",
+			request: models.RedeemInviteRequest{CharacterID: "char-1"},
+			setupMocks: func(repo *MockGameInviteRepository, _ *mocks.MockGameSessionRepository) {
+				invite := pendingInvite()
+				invite.ExpiresAt = testInviteNow
+				repo.On("GetByCode", mock.Anything, "code-1").Return(invite, nil)
+			},
+			expectError: "invite has expired",
+		},
+		{
+			name:    "Revoked invite",
+			request: models.RedeemInviteRequest{CharacterID: "char-1"},
+			setupMocks: func(repo *MockGameInviteRepository, _ *mocks.MockGameSessionRepository) {
+				invite := pendingInvite()
+				invite.RevokedAt = &revokedAt
+				repo.On("GetByCode", mock.Anything, "code-1").Return(invite, nil)
+			},
+			expectError: "invite has been revoked",
+		},
+		{
+			name:    "Used up invite",
+			request: models.RedeemInviteRequest{CharacterID: "char-1"},
+			setupMocks: func(repo *MockGameInviteRepository, _ *mocks.MockGameSessionRepository) {
+				invite := pendingInvite()
+				invite.UseCount = 1
+				repo.On("GetByCode", mock.Anything, "code-1").Return(invite, nil)
+			},
+			expectError: "invite has already been used",
+		},
+		{
+			name:    "Invite emailed to another address",
+			request: models.RedeemInviteRequest{CharacterID: "char-1"},
+			setupMocks: func(repo *MockGameInviteRepository, _ *mocks.MockGameSessionRepository) {
+				invite := pendingInvite()
+				invite.InviteeEmail = "someone.else@example.com"
+				repo.On("GetByCode", mock.Anything, "code-1").Return(invite, nil)
+			},
+			expectError: "invite",
+		},
+		{
+			name:    "Losing the race for the last use of a link",
+			request: models.RedeemInviteRequest{CharacterID: "char-1"},
+			setupMocks: func(repo *MockGameInviteRepository, _ *mocks.MockGameSessionRepository) {
+				link := pendingInvite()
+				link.InviteeEmail = ""
+				link.MaxUses = 3
+				repo.On("GetByCode", mock.Anything, "code-1").Return(link, nil)
+				repo.On("Claim", mock.Anything, "invite-1", "player-1", testInviteNow).Return(false, nil)
+			},
+			expectError: "invite",
+		},
+		{
+			name:    "Joining fails and the use is given back",
+			request: models.RedeemInviteRequest{CharacterID: "char-1"},
+			setupMocks: func(repo *MockGameInviteRepository, sessions *mocks.MockGameSessionRepository) {
+				repo.On("GetByCode", mock.Anything, "code-1").Return(pendingInvite(), nil)
+				repo.On("Claim", mock.Anything, "invite-1", "player-1", testInviteNow).Return(true, nil)
+				repo.On("Release", mock.Anything, "invite-1").Return(nil)
+				sessions.On("AddParticipant", mock.Anything, "session-1", "player-1", mock.Anything).Return(errors.New("db down"))
+			},
+			expectError: "db down",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockGameInviteRepository)
+			sessions := new(mocks.MockGameSessionRepository)
+			characters := new(mocks.MockCharacterRepository)
+			users := new(mocks.MockUserRepository)
+			setupInviteSession(sessions, characters, users)
+			tt.setupMocks(repo, sessions)
+
+			service := createTestGameInviteService(repo, sessions, characters, users, &fakeEmailQueue{})
+			redeemed, err := service.RedeemInvite(context.Background(), "code-1", "player-1", &tt.request)
+
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "session-1", redeemed.SessionID)
+			}
+
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGameSessionService_JoinSessionRequiresInvite(t *testing.T) {
+	sessions := new(mocks.MockGameSessionRepository)
+	characters := new(mocks.MockCharacterRepository)
+	setupInviteSession(sessions, characters, new(mocks.MockUserRepository))
+	service := NewGameSessionService(sessions)
+	service.SetCharacterRepository(characters)
+	characterID := "char-1"
+
+	err := service.JoinSession(context.Background(), "session-1", "player-1", &characterID)
+
+	assert.EqualError(t, err, "this session is invite only")
+}
@@ -157,13 +157,29 @@ func (s *GameSessionService) DeleteSession(ctx context.Context, id string) error
 
 // JoinSession adds a player to a game session with comprehensive security checks
 func (s *GameSessionService) JoinSession(ctx context.Context, sessionID, userID string, characterID *string) error {
-	// Validate input
-	if err := s.validateJoinInput(sessionID, userID); err != nil {
+	if err := s.prepareJoin(ctx, sessionID, userID, characterID, false); err != nil {
 		return err
 	}
 
-	// Get session and check if it can be joined
-	session, err := s.getJoinableSession(ctx, sessionID)
+	// Add participant
+	return s.repo.AddParticipant(ctx, sessionID, userID, characterID)
+}
+
+// SpectateSession adds a user who watches a game session without playing: a stream, a new
+// player looking on or a player sitting a session out. Spectators take no seat, so a full
+// session can still be watched.
+func (s *GameSessionService) SpectateSession(ctx context.Context, sessionID, userID string) error {
+	if err := s.prepareSpectate(ctx, sessionID, userID, false); err != nil {
+		return err
+	}
+
+	return s.repo.AddSpectator(ctx, sessionID, userID)
+}
+
+// prepareJoin runs the checks a player must pass before joining a session. Only invited
+// players may join a session that requires an invite.
+func (s *GameSessionService) prepareJoin(ctx context.Context, sessionID, userID string, characterID *string, invited bool) error {
+	session, err := s.prepareEntry(ctx, sessionID, userID, invited)
 	if err != nil {
 		return err
 	}
@@ -174,12 +190,47 @@ func (s *GameSessionService) JoinSession(ctx context.Context, sessionID, userID
 	}
 
 	// Validate character if provided
-	if err := s.validateCharacterForSession(ctx, userID, characterID, session); err != nil {
+	return s.validateCharacterForSession(ctx, userID, characterID, session)
+}
+
+// prepareSpectate runs the checks a user must pass before spectating a session, which are
+// a player's without the seat and the character
+func (s *GameSessionService) prepareSpectate(ctx context.Context, sessionID, userID string, invited bool) error {
+	if _, err := s.prepareEntry(ctx, sessionID, userID, invited); err != nil {
 		return err
 	}
 
-	// Add participant
-	return s.repo.AddParticipant(ctx, sessionID, userID, characterID)
+	participants, err := s.repo.GetParticipants(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to check participants: %w", err)
+	}
+	for _, p := range participants {
+		if p.UserID == userID {
+			return fmt.Errorf("you are already in this session")
+		}
+	}
+	return nil
+}
+
+// prepareEntry returns the session a user wants to join as a player or a spectator, if it
+// can be joined at all
+func (s *GameSessionService) prepareEntry(ctx context.Context, sessionID, userID string, invited bool) (*models.GameSession, error) {
+	// Validate input
+	if err := s.validateJoinInput(sessionID, userID); err != nil {
+		return nil, err
+	}
+
+	// Get session and check if it can be joined
+	session, err := s.getJoinableSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Security check: private sessions are joined by redeeming an invite
+	if session.RequiresInvite && !invited {
+		return nil, fmt.Errorf("this session is invite only")
+	}
+	return session, nil
 }
 
 // validateJoinInput validates the basic input parameters
@@ -226,8 +277,8 @@ func (s *GameSessionService) validateParticipantStatus(ctx context.Context, sess
 		if p.UserID == userID {
 			return fmt.Errorf("you are already in this session")
 		}
-		// Count non-DM players
-		if p.UserID != session.DMID {
+		// Count non-DM players; spectators don't take a seat
+		if p.UserID != session.DMID && p.Role != models.ParticipantRoleSpectator {
 			currentPlayerCount++
 		}
 	}
@@ -325,6 +376,34 @@ func (s *GameSessionService) ValidateUserInSession(ctx context.Context, sessionI
 	return fmt.Errorf("user is not a participant in this session")
 }
 
+// ParticipantRole returns the role a user has in a session: the DM, a player or a
+// spectator
+func (s *GameSessionService) ParticipantRole(ctx context.Context, sessionID, userID string) (models.ParticipantRole, error) {
+	session, err := s.repo.GetByID(ctx, sessionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve session %s for user validation: %w", sessionID, err)
+	}
+	if session.DMID == userID {
+		return models.ParticipantRoleDM, nil
+	}
+
+	participants, err := s.repo.GetParticipants(ctx, sessionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get participants: %w", err)
+	}
+	for _, p := range participants {
+		if p.UserID != userID {
+			continue
+		}
+		if p.Role == "" {
+			return models.ParticipantRolePlayer, nil
+		}
+		return p.Role, nil
+	}
+
+	return "", fmt.Errorf("user is not a participant in this session")
+}
+
 // GetGameSession gets a game session by ID (alias for GetSessionByID)
 func (s *GameSessionService) GetGameSession(ctx context.Context, id string) (*models.GameSession, error) {
 	return s.GetSessionByID(ctx, id)
@@ -282,6 +282,36 @@ func TestGameSessionService_JoinSession(t *testing.T) {
 			},
 			expectedError: testutil.TestDatabaseError,
 		},
+		{
+			name:      "spectators don't take a seat",
+			sessionID: testutil.TestSessionID,
+			userID:    testutil.TestUserID,
+			setupMock: func(m *mocks.MockGameSessionRepository) {
+				session := &models.GameSession{ID: testutil.TestSessionID, DMID: "dm-1", IsActive: true,
+					Status: models.GameStatusActive, MaxPlayers: 2}
+				m.On("GetByID", ctx, testutil.TestSessionID).Return(session, nil)
+				m.On("GetParticipants", ctx, testutil.TestSessionID).Return([]*models.GameParticipant{
+					{UserID: "dm-1", Role: models.ParticipantRoleDM},
+					{UserID: "viewer-1", Role: models.ParticipantRoleSpectator},
+				}, nil)
+				m.On("AddParticipant", ctx, testutil.TestSessionID, testutil.TestUserID, (*string)(nil)).Return(nil)
+			},
+		},
+		{
+			name:      "full session",
+			sessionID: testutil.TestSessionID,
+			userID:    testutil.TestUserID,
+			setupMock: func(m *mocks.MockGameSessionRepository) {
+				session := &models.GameSession{ID: testutil.TestSessionID, DMID: "dm-1", IsActive: true,
+					Status: models.GameStatusActive, MaxPlayers: 2}
+				m.On("GetByID", ctx, testutil.TestSessionID).Return(session, nil)
+				m.On("GetParticipants", ctx, testutil.TestSessionID).Return([]*models.GameParticipant{
+					{UserID: "dm-1", Role: models.ParticipantRoleDM},
+					{UserID: "player-1", Role: models.ParticipantRolePlayer},
+				}, nil)
+			},
+			expectedError: "session is full",
+		},
 	}
 
 	for _, tt := range tests {
@@ -306,6 +336,94 @@ func TestGameSessionService_JoinSession(t *testing.T) {
 	}
 }
 
+func TestGameSessionService_SpectateSession(t *testing.T) {
+	ctx := context.Background()
+	fullSession := func(requiresInvite bool) *models.GameSession {
+		return &models.GameSession{ID: testutil.TestSessionID, DMID: "dm-1", IsActive: true,
+			Status: models.GameStatusActive, MaxPlayers: 2, IsPublic: !requiresInvite, RequiresInvite: requiresInvite}
+	}
+	seated := []*models.GameParticipant{
+		{UserID: "dm-1", Role: models.ParticipantRoleDM},
+		{UserID: "player-1", Role: models.ParticipantRolePlayer},
+	}
+
+	tests := []struct {
+		name          string
+		userID        string
+		setupMock     func(*mocks.MockGameSessionRepository)
+		expectedError string
+	}{
+		{
+			name:   "a full session can still be watched",
+			userID: testutil.TestUserID,
+			setupMock: func(m *mocks.MockGameSessionRepository) {
+				m.On("GetByID", ctx, testutil.TestSessionID).Return(fullSession(false), nil)
+				m.On("GetParticipants", ctx, testutil.TestSessionID).Return(seated, nil)
+				m.On("AddSpectator", ctx, testutil.TestSessionID, testutil.TestUserID).Return(nil)
+			},
+		},
+		{
+			name:   "already in the session",
+			userID: "player-1",
+			setupMock: func(m *mocks.MockGameSessionRepository) {
+				m.On("GetByID", ctx, testutil.TestSessionID).Return(fullSession(false), nil)
+				m.On("GetParticipants", ctx, testutil.TestSessionID).Return(seated, nil)
+			},
+			expectedError: "you are already in this session",
+		},
+		{
+			name:   "invite only session",
+			userID: testutil.TestUserID,
+			setupMock: func(m *mocks.MockGameSessionRepository) {
+				m.On("GetByID", ctx, testutil.TestSessionID).Return(fullSession(true), nil)
+			},
+			expectedError: "this session is invite only",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(mocks.MockGameSessionRepository)
+			tt.setupMock(mockRepo)
+
+			service := services.NewGameSessionService(mockRepo)
+			err := service.SpectateSession(ctx, testutil.TestSessionID, tt.userID)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				mockRepo.AssertNotCalled(t, "AddSpectator", mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				require.NoError(t, err)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGameSessionService_ParticipantRole(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(mocks.MockGameSessionRepository)
+	mockRepo.On("GetByID", ctx, testutil.TestSessionID).Return(&models.GameSession{ID: testutil.TestSessionID, DMID: "dm-1"}, nil)
+	mockRepo.On("GetParticipants", ctx, testutil.TestSessionID).Return([]*models.GameParticipant{
+		{UserID: "player-1", Role: models.ParticipantRolePlayer},
+		{UserID: "viewer-1", Role: models.ParticipantRoleSpectator},
+	}, nil)
+	service := services.NewGameSessionService(mockRepo)
+
+	for userID, expected := range map[string]models.ParticipantRole{
+		"dm-1":     models.ParticipantRoleDM,
+		"player-1": models.ParticipantRolePlayer,
+		"viewer-1": models.ParticipantRoleSpectator,
+	} {
+		role, err := service.ParticipantRole(ctx, testutil.TestSessionID, userID)
+		require.NoError(t, err)
+		assert.Equal(t, expected, role, userID)
+	}
+
+	_, err := service.ParticipantRole(ctx, testutil.TestSessionID, "stranger")
+	assert.Error(t, err)
+}
+
 func TestGameSessionService_LeaveSession(t *testing.T) {
 	ctx := context.Background()
 
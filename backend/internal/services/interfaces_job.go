@@ -14,6 +14,11 @@ type EmailServiceInterface interface {
 	SendTemplate(ctx context.Context, to []string, templateName string, data interface{}) error
 }
 
+// EmailQueue queues an email to be sent by the job queue's email worker
+type EmailQueue interface {
+	EnqueueEmail(ctx context.Context, to []string, subject, body string, isHTML bool) error
+}
+
 // Attachment represents an email attachment
 type Attachment struct {
 	Filename    string
@@ -73,4 +78,15 @@ type CharacterServiceInterface interface {
 	UpdateCharacter(ctx context.Context, character *models.Character) error
 	DeleteCharacter(ctx context.Context, characterID string) error
 	LevelUp(ctx context.Context, characterID string) error
-}
\ No newline at end of file
+}
+
+// SessionReminderQueue queues the reminder for a scheduled session to be sent at sendAt
+type SessionReminderQueue interface {
+	EnqueueSessionReminder(ctx context.Context, scheduleID string, startsAt, sendAt time.Time) error
+}
+
+// SessionReminderServiceInterface builds the reminder for a scheduled session when it
+// comes due, or nil when it should no longer be sent
+type SessionReminderServiceInterface interface {
+	SessionReminder(ctx context.Context, scheduleID string, startsAt time.Time) (*models.SessionReminder, error)
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/ctclostio/DnD-Game/backend/internal/auth"
 	"github.com/ctclostio/DnD-Game/backend/internal/database"
 	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/dice"
+	"github.com/ctclostio/DnD-Game/backend/pkg/logger"
 )
 
 // Error message constants
@@ -16,17 +19,43 @@ const (
 type InventoryService struct {
 	inventoryRepo database.InventoryRepository
 	characterRepo database.CharacterRepository
+	versions      *CharacterVersionService
+	spells        *SpellManagementService
+	sessionRepo   database.GameSessionRepository
+	roller        *dice.Roller
+	events        *GameEventService
 }
 
 func NewInventoryService(inventoryRepo database.InventoryRepository, characterRepo database.CharacterRepository) *InventoryService {
 	return &InventoryService{
 		inventoryRepo: inventoryRepo,
 		characterRepo: characterRepo,
+		roller:        dice.NewRoller(),
 	}
 }
 
-func (s *InventoryService) AddItemToCharacter(characterID, itemID string, quantity int) error {
-	character, err := s.characterRepo.GetByID(context.Background(), characterID)
+// SetVersionService enables recording a character version after each inventory change
+func (s *InventoryService) SetVersionService(versions *CharacterVersionService) {
+	s.versions = versions
+}
+
+// recordInventoryChange snapshots the character after its inventory or purse changed
+func (s *InventoryService) recordInventoryChange(ctx context.Context, characterID, reason string) {
+	if s.versions == nil {
+		return
+	}
+	character, err := s.characterRepo.GetByID(ctx, characterID)
+	if err != nil {
+		logger.WithContext(ctx).WithError(err).Error().
+			Str("character_id", characterID).
+			Msg("Failed to load character for its inventory version")
+		return
+	}
+	s.versions.RecordChange(ctx, character, models.CharacterChangeInventory, reason)
+}
+
+func (s *InventoryService) AddItemToCharacter(ctx context.Context, characterID, itemID string, quantity int) error {
+	character, err := s.characterRepo.GetByID(ctx, characterID)
 	if err != nil {
 		return err
 	}
@@ -34,7 +63,7 @@ func (s *InventoryService) AddItemToCharacter(characterID, itemID string, quanti
 		return fmt.Errorf("character not found")
 	}
 
-	item, err := s.inventoryRepo.GetItem(itemID)
+	item, err := s.inventoryRepo.GetCharacterItem(itemID, characterID)
 	if err != nil {
 		return err
 	}
@@ -42,18 +71,26 @@ func (s *InventoryService) AddItemToCharacter(characterID, itemID string, quanti
 		return fmt.Errorf(errMsgItemNotFound)
 	}
 
-	return s.inventoryRepo.AddItemToInventory(characterID, itemID, quantity)
+	if err := s.inventoryRepo.AddItemToInventory(characterID, itemID, quantity); err != nil {
+		return err
+	}
+	s.recordInventoryChange(ctx, characterID, fmt.Sprintf("added %d x %s", quantity, itemID))
+	return nil
 }
 
-func (s *InventoryService) RemoveItemFromCharacter(characterID, itemID string, quantity int) error {
-	return s.inventoryRepo.RemoveItemFromInventory(characterID, itemID, quantity)
+func (s *InventoryService) RemoveItemFromCharacter(ctx context.Context, characterID, itemID string, quantity int) error {
+	if err := s.inventoryRepo.RemoveItemFromInventory(characterID, itemID, quantity); err != nil {
+		return err
+	}
+	s.recordInventoryChange(ctx, characterID, fmt.Sprintf("removed %d x %s", quantity, itemID))
+	return nil
 }
 
 func (s *InventoryService) GetCharacterInventory(characterID string) ([]*models.InventoryItem, error) {
 	return s.inventoryRepo.GetCharacterInventory(characterID)
 }
 
-func (s *InventoryService) EquipItem(characterID, itemID string) error {
+func (s *InventoryService) EquipItem(ctx context.Context, characterID, itemID string) error {
 	inventory, err := s.inventoryRepo.GetCharacterInventory(characterID)
 	if err != nil {
 		return err
@@ -63,6 +100,12 @@ func (s *InventoryService) EquipItem(characterID, itemID string) error {
 	if targetItem == nil {
 		return fmt.Errorf("item not found in inventory")
 	}
+	if targetItem.ContainerID != nil {
+		return fmt.Errorf("take %s out of its container before equipping it", targetItem.Item.Name)
+	}
+	if targetItem.Location != "" && targetItem.Location != models.ItemLocationCarried {
+		return fmt.Errorf("%s is not being carried", targetItem.Item.Name)
+	}
 
 	// Handle armor equipping
 	if targetItem.Item.Type == models.ItemTypeArmor {
@@ -78,7 +121,17 @@ func (s *InventoryService) EquipItem(characterID, itemID string) error {
 		}
 	}
 
-	return s.inventoryRepo.EquipItem(characterID, itemID, true)
+	if err := s.inventoryRepo.EquipItem(characterID, itemID, true); err != nil {
+		return err
+	}
+	// Cursed items that need no attunement bind whoever puts them on
+	if targetItem.Item.IsCursed() && !targetItem.Item.RequiresAttunement {
+		if err := s.inventoryRepo.SetItemCurse(characterID, itemID, true); err != nil {
+			return err
+		}
+	}
+	s.recordInventoryChange(ctx, characterID, "equipped "+itemID)
+	return nil
 }
 
 // findItemInInventory searches for an item in the inventory
@@ -95,6 +148,9 @@ func (s *InventoryService) findItemInInventory(inventory []*models.InventoryItem
 func (s *InventoryService) unequipExistingArmor(characterID, itemID string, inventory []*models.InventoryItem) error {
 	for _, inv := range inventory {
 		if inv.Equipped && inv.Item.Type == models.ItemTypeArmor && inv.ItemID != itemID {
+			if inv.CurseActive {
+				return cursedItemError(inv)
+			}
 			if err := s.inventoryRepo.EquipItem(characterID, inv.ItemID, false); err != nil {
 				return err
 			}
@@ -137,96 +193,281 @@ func (s *InventoryService) countEquippedWeaponSlots(inventory []*models.Inventor
 	return currentSlots
 }
 
-func (s *InventoryService) UnequipItem(characterID, itemID string) error {
-	return s.inventoryRepo.EquipItem(characterID, itemID, false)
-}
-
-func (s *InventoryService) AttuneToItem(characterID, itemID string) error {
+// MoveItem puts a stack into another container in the inventory, or takes it out and
+// leaves it carried, on the character's mount or in storage. Everything inside the
+// stack goes with it.
+func (s *InventoryService) MoveItem(ctx context.Context, characterID, itemID string, req *models.MoveItemRequest) error {
 	inventory, err := s.inventoryRepo.GetCharacterInventory(characterID)
 	if err != nil {
 		return err
 	}
 
-	found := false
-	for _, inv := range inventory {
-		if inv.ItemID == itemID {
-			found = true
-			if !inv.Item.RequiresAttunement {
-				return fmt.Errorf("item does not require attunement")
-			}
-			if inv.Attuned {
-				return fmt.Errorf("already attuned to this item")
-			}
-			break
+	target := s.findItemInInventory(inventory, itemID)
+	if req.FromContainerItemID != "" {
+		target = nil
+		if from := s.findItemInInventory(inventory, req.FromContainerItemID); from != nil {
+			target = findStack(inventory, itemID, &from.ID)
 		}
 	}
-
-	if !found {
+	if target == nil {
 		return fmt.Errorf("item not found in inventory")
 	}
+	if req.Quantity < 0 || req.Quantity > target.Quantity {
+		return fmt.Errorf("only %d %s in that stack", target.Quantity, target.Item.Name)
+	}
+	split := req.Quantity > 0 && req.Quantity < target.Quantity
+
+	contents := models.ContentsOf(inventory, target.ID)
+	move := &models.InventoryMove{
+		StackID:     target.ID,
+		ItemID:      itemID,
+		Location:    req.Location,
+		StorageNote: req.StorageNote,
+	}
+	if split {
+		move.Quantity = req.Quantity
+	}
+	for _, inv := range contents {
+		move.Contents = append(move.Contents, inv.ID)
+	}
+
+	moved := target
+	if split {
+		if len(contents) > 0 {
+			return fmt.Errorf("empty %s before splitting the stack", target.Item.Name)
+		}
+		if target.Equipped || target.Attuned {
+			return fmt.Errorf("unequip %s before splitting the stack", target.Item.Name)
+		}
+		partial := *target
+		partial.Quantity = req.Quantity
+		moved = &partial
+	}
+
+	if req.ContainerItemID != "" {
+		container := s.findItemInInventory(inventory, req.ContainerItemID)
+		if container == nil {
+			return fmt.Errorf("container not found in inventory")
+		}
+		if err := s.validateContainerFits(inventory, moved, container, contents); err != nil {
+			return err
+		}
+		// Items take on the location of the container they are packed in
+		move.ContainerID = &container.ID
+		move.Location = container.Location
+		move.StorageNote = container.StorageNote
+	}
 
-	return s.inventoryRepo.AttuneItem(characterID, itemID)
+	if move.Location == "" {
+		move.Location = models.ItemLocationCarried
+	}
+	if !move.Location.IsValid() {
+		return fmt.Errorf("invalid location: %s", move.Location)
+	}
+	if move.Location != models.ItemLocationStored {
+		move.StorageNote = ""
+	}
+	if target.Equipped && (move.ContainerID != nil || move.Location != models.ItemLocationCarried) {
+		return fmt.Errorf("unequip %s before packing it away", target.Item.Name)
+	}
+
+	sameContainer := (move.ContainerID == nil && target.ContainerID == nil) ||
+		(move.ContainerID != nil && target.ContainerID != nil && *move.ContainerID == *target.ContainerID)
+	if split && sameContainer {
+		return fmt.Errorf("a split stack must go to a different container")
+	}
+	// Stacks of an item in the same container merge, so the moved one must be plain
+	if existing := findStack(inventory, itemID, move.ContainerID); existing != nil && existing.ID != target.ID {
+		if len(contents) > 0 || target.Equipped || target.Attuned {
+			return fmt.Errorf("%s would merge with another stack; empty and unequip it first", target.Item.Name)
+		}
+		if move.ContainerID == nil && existing.Location != move.Location {
+			return fmt.Errorf("%s already has a loose stack %s; move that stack instead", target.Item.Name, existing.Location)
+		}
+	}
+
+	if err := s.inventoryRepo.MoveInventoryItem(characterID, move); err != nil {
+		return err
+	}
+	s.recordInventoryChange(ctx, characterID, fmt.Sprintf("moved %s", itemID))
+	return nil
 }
 
-func (s *InventoryService) UnattuneFromItem(characterID, itemID string) error {
-	return s.inventoryRepo.UnattuneItem(characterID, itemID)
+// findStack returns the stack of itemID held in the given container row, or the loose stack when containerID is nil
+func findStack(inventory []*models.InventoryItem, itemID string, containerID *string) *models.InventoryItem {
+	for _, inv := range inventory {
+		if inv.ItemID != itemID {
+			continue
+		}
+		if (containerID == nil && inv.ContainerID == nil) ||
+			(containerID != nil && inv.ContainerID != nil && *inv.ContainerID == *containerID) {
+			return inv
+		}
+	}
+	return nil
 }
 
-func (s *InventoryService) GetCharacterCurrency(characterID string) (*models.Currency, error) {
-	return s.inventoryRepo.GetCharacterCurrency(characterID)
+// validateContainerFits checks that target can go inside container without exceeding
+// its count, weight or volume limits
+func (s *InventoryService) validateContainerFits(inventory []*models.InventoryItem, target, container *models.InventoryItem, contents []*models.InventoryItem) error {
+	capacity := container.Item.Container()
+	if capacity == nil {
+		return fmt.Errorf("%s is not a container", container.Item.Name)
+	}
+	if container.ID == target.ID {
+		return fmt.Errorf("cannot put %s inside itself", target.Item.Name)
+	}
+	for _, inv := range contents {
+		if inv.ID == container.ID {
+			return fmt.Errorf("cannot put %s inside %s, which it contains", target.Item.Name, container.Item.Name)
+		}
+	}
+	if capacity.FixedWeight {
+		for _, inv := range append([]*models.InventoryItem{target}, contents...) {
+			if inner := inv.Item.Container(); inner != nil && inner.FixedWeight {
+				return fmt.Errorf("putting %s inside %s would destroy both", inv.Item.Name, container.Item.Name)
+			}
+		}
+	}
+
+	// Measure the container without the target, in case it is already inside
+	others := make([]*models.InventoryItem, 0, len(inventory))
+	for _, inv := range inventory {
+		if inv.ID != target.ID {
+			others = append(others, inv)
+		}
+	}
+	load := models.LoadOf(others, container.ID)
+	added := models.StackLoad(inventory, target)
+
+	// A stack of containers shares its contents, so two backpacks hold twice as much
+	stacked := max(container.Quantity, 1)
+	if limit := capacity.Count * stacked; limit > 0 && load.Count+added.Count > limit {
+		return fmt.Errorf("%s can hold only %d items", container.Item.Name, limit)
+	}
+	if limit := capacity.Weight * float64(stacked); limit > 0 && load.Weight+added.Weight > limit {
+		return fmt.Errorf("%s can hold only %g lb", container.Item.Name, limit)
+	}
+	if limit := capacity.Volume * float64(stacked); limit > 0 && load.Volume+added.Volume > limit {
+		return fmt.Errorf("%s can hold only %g cubic feet", container.Item.Name, limit)
+	}
+	return nil
 }
 
-func (s *InventoryService) UpdateCharacterCurrency(characterID string, copper, silver, electrum, gold, platinum int) error {
-	currency, err := s.inventoryRepo.GetCharacterCurrency(characterID)
+func (s *InventoryService) UnequipItem(ctx context.Context, characterID, itemID string) error {
+	inventory, err := s.inventoryRepo.GetCharacterInventory(characterID)
 	if err != nil {
 		return err
 	}
-
-	currency.Copper += copper
-	currency.Silver += silver
-	currency.Electrum += electrum
-	currency.Gold += gold
-	currency.Platinum += platinum
-
-	if currency.Copper < 0 || currency.Silver < 0 || currency.Electrum < 0 ||
-		currency.Gold < 0 || currency.Platinum < 0 {
-		return fmt.Errorf("insufficient funds")
+	if inv := s.findItemInInventory(inventory, itemID); inv != nil && inv.CurseActive {
+		return cursedItemError(inv)
 	}
 
-	return s.inventoryRepo.UpdateCharacterCurrency(currency)
+	if err := s.inventoryRepo.EquipItem(characterID, itemID, false); err != nil {
+		return err
+	}
+	s.recordInventoryChange(ctx, characterID, "unequipped "+itemID)
+	return nil
 }
 
-func (s *InventoryService) PurchaseItem(characterID, itemID string, quantity int) error {
-	item, err := s.inventoryRepo.GetItem(itemID)
+func (s *InventoryService) AttuneToItem(ctx context.Context, characterID, itemID string) error {
+	inventory, err := s.inventoryRepo.GetCharacterInventory(characterID)
 	if err != nil {
 		return err
 	}
-	if item == nil {
-		return fmt.Errorf(errMsgItemNotFound)
+
+	target := s.findItemInInventory(inventory, itemID)
+	if target == nil {
+		return fmt.Errorf("item not found in inventory")
+	}
+	if !target.Item.RequiresAttunement {
+		return fmt.Errorf("item does not require attunement")
+	}
+	if target.Attuned {
+		return fmt.Errorf("already attuned to this item")
 	}
 
-	totalCost := item.Value * quantity
-	currency, err := s.inventoryRepo.GetCharacterCurrency(characterID)
-	if err != nil {
+	if err := s.inventoryRepo.AttuneItem(characterID, itemID); err != nil {
 		return err
 	}
+	// A cursed item's curse takes hold when its owner attunes to it
+	if target.Item.IsCursed() {
+		if err := s.inventoryRepo.SetItemCurse(characterID, itemID, true); err != nil {
+			return err
+		}
+	}
+	s.recordInventoryChange(ctx, characterID, "attuned to "+itemID)
+	return nil
+}
 
-	if !currency.CanAfford(totalCost) {
-		return fmt.Errorf("insufficient funds")
+// UnattuneFromItem ends attunement, which a cursed item's owner cannot do until the curse is removed
+func (s *InventoryService) UnattuneFromItem(ctx context.Context, characterID, itemID string) error {
+	inventory, err := s.inventoryRepo.GetCharacterInventory(characterID)
+	if err != nil {
+		return err
+	}
+	target := s.findItemInInventory(inventory, itemID)
+	if target == nil {
+		return fmt.Errorf("item not found in inventory")
+	}
+	if !target.Attuned {
+		return fmt.Errorf("not attuned to this item")
+	}
+	if target.CurseActive {
+		return cursedItemError(target)
 	}
 
-	if !currency.Subtract(totalCost) {
-		return fmt.Errorf("failed to subtract currency")
+	if err := s.inventoryRepo.UnattuneItem(characterID, itemID); err != nil {
+		return err
 	}
+	s.recordInventoryChange(ctx, characterID, "ended attunement to "+itemID)
+	return nil
+}
+
+func (s *InventoryService) GetCharacterCurrency(characterID string) (*models.Currency, error) {
+	return s.inventoryRepo.GetCharacterCurrency(characterID)
+}
 
-	if err := s.inventoryRepo.UpdateCharacterCurrency(currency); err != nil {
+func (s *InventoryService) UpdateCharacterCurrency(ctx context.Context, characterID string, copper, silver, electrum, gold, platinum int) error {
+	_, err := s.applyTransaction(ctx, &models.EconomyTransaction{
+		Type:        models.LedgerEntryAdjustment,
+		Description: "adjusted currency",
+		Currency: []models.CurrencyMovement{{
+			CharacterID: characterID,
+			Coins:       models.Coins{Copper: copper, Silver: silver, Electrum: electrum, Gold: gold, Platinum: platinum},
+		}},
+	})
+	return err
+}
+
+// PurchaseItem pays for and adds items in a single transaction
+func (s *InventoryService) PurchaseItem(ctx context.Context, characterID, itemID string, quantity int) error {
+	if quantity < 1 {
+		return fmt.Errorf("quantity must be positive")
+	}
+	item, err := s.inventoryRepo.GetCharacterItem(itemID, characterID)
+	if err != nil {
 		return err
 	}
+	if item == nil {
+		return fmt.Errorf(errMsgItemNotFound)
+	}
 
-	return s.inventoryRepo.AddItemToInventory(characterID, itemID, quantity)
+	totalCost := item.Value * quantity
+	_, err = s.applyTransaction(ctx, &models.EconomyTransaction{
+		Type:        models.LedgerEntryPurchase,
+		Description: fmt.Sprintf("purchased %d x %s", quantity, item.Name),
+		Currency:    []models.CurrencyMovement{{CharacterID: characterID, Coins: models.Coins{Copper: -totalCost}}},
+		Items:       []models.ItemMovement{{CharacterID: characterID, ItemID: itemID, Quantity: quantity}},
+	})
+	return err
 }
 
-func (s *InventoryService) SellItem(characterID, itemID string, quantity int) error {
+// SellItem removes items and pays half their value in a single transaction
+func (s *InventoryService) SellItem(ctx context.Context, characterID, itemID string, quantity int) error {
+	if quantity < 1 {
+		return fmt.Errorf("quantity must be positive")
+	}
 	item, err := s.inventoryRepo.GetItem(itemID)
 	if err != nil {
 		return err
@@ -236,31 +477,159 @@ func (s *InventoryService) SellItem(characterID, itemID string, quantity int) er
 	}
 
 	salePrice := (item.Value * quantity) / 2
+	_, err = s.applyTransaction(ctx, &models.EconomyTransaction{
+		Type:        models.LedgerEntrySale,
+		Description: fmt.Sprintf("sold %d x %s", quantity, item.Name),
+		Currency:    []models.CurrencyMovement{{CharacterID: characterID, Coins: models.CoinsFromCopper(salePrice)}},
+		Items:       []models.ItemMovement{{CharacterID: characterID, ItemID: itemID, Quantity: -quantity}},
+	})
+	return err
+}
 
-	if err := s.inventoryRepo.RemoveItemFromInventory(characterID, itemID, quantity); err != nil {
-		return err
+// Transfer hands coins and items from one character to another in a single transaction
+func (s *InventoryService) Transfer(ctx context.Context, req *models.TransferRequest) ([]*models.LedgerEntry, error) {
+	txn, err := transferTransaction(ctx, req)
+	if err != nil {
+		return nil, err
 	}
+	return s.applyTransaction(ctx, txn)
+}
 
-	currency, err := s.inventoryRepo.GetCharacterCurrency(characterID)
-	if err != nil {
-		return err
+// transferTransaction validates a transfer and builds the transaction that makes it
+func transferTransaction(ctx context.Context, req *models.TransferRequest) (*models.EconomyTransaction, error) {
+	if req.FromCharacterID == "" || req.ToCharacterID == "" {
+		return nil, fmt.Errorf("both characters are required")
+	}
+	if req.FromCharacterID == req.ToCharacterID {
+		return nil, fmt.Errorf("cannot transfer to the same character")
+	}
+	if req.Coins.IsNegative() {
+		return nil, fmt.Errorf("coins cannot be negative")
+	}
+	if req.Coins.IsZero() && len(req.Items) == 0 {
+		return nil, fmt.Errorf("nothing to transfer")
 	}
 
-	total := currency.TotalInCopper() + salePrice
+	txn := &models.EconomyTransaction{
+		Type:        models.LedgerEntryTransfer,
+		Description: fmt.Sprintf("transfer from %s to %s", req.FromCharacterID, req.ToCharacterID),
+		SessionID:   req.SessionID,
+	}
+	txn.CreatedBy, _ = auth.GetUserIDFromContext(ctx)
+	if !req.Coins.IsZero() {
+		txn.Currency = []models.CurrencyMovement{
+			{CharacterID: req.FromCharacterID, Coins: req.Coins.Negate()},
+			{CharacterID: req.ToCharacterID, Coins: req.Coins},
+		}
+	}
+	for _, item := range req.Items {
+		if item.Quantity < 1 {
+			return nil, fmt.Errorf("quantity of %s must be positive", item.ItemID)
+		}
+		txn.Items = append(txn.Items,
+			models.ItemMovement{CharacterID: req.FromCharacterID, ItemID: item.ItemID, Quantity: -item.Quantity},
+			models.ItemMovement{CharacterID: req.ToCharacterID, ItemID: item.ItemID, Quantity: item.Quantity},
+		)
+	}
+	return txn, nil
+}
 
-	currency.Platinum = total / 1000
-	total %= 1000
+// SetEventLog sets the session event log loot awarded during a session is recorded in
+func (s *InventoryService) SetEventLog(events *GameEventService) {
+	s.events = events
+}
 
-	currency.Gold = total / 100
-	total %= 100
+// AwardLoot hands out treasure to every recipient in a single transaction
+func (s *InventoryService) AwardLoot(ctx context.Context, award *models.LootAward) ([]*models.LedgerEntry, error) {
+	if len(award.Recipients) == 0 {
+		return nil, fmt.Errorf("loot award has no recipients")
+	}
 
-	currency.Electrum = total / 50
-	total %= 50
+	description := award.Description
+	if description == "" {
+		description = "loot award"
+	}
+	txn := &models.EconomyTransaction{
+		Type:        models.LedgerEntryLoot,
+		Description: description,
+		SessionID:   award.SessionID,
+	}
+	txn.CreatedBy, _ = auth.GetUserIDFromContext(ctx)
 
-	currency.Silver = total / 10
-	currency.Copper = total % 10
+	names := make(map[string][]string, len(award.Recipients))
+	for _, recipient := range award.Recipients {
+		if recipient.CharacterID == "" {
+			return nil, fmt.Errorf("loot recipient has no character")
+		}
+		if recipient.Coins.IsNegative() {
+			return nil, fmt.Errorf("loot coins cannot be negative")
+		}
+		if !recipient.Coins.IsZero() {
+			txn.Currency = append(txn.Currency, models.CurrencyMovement{CharacterID: recipient.CharacterID, Coins: recipient.Coins})
+		}
+		for _, loot := range recipient.Items {
+			if loot.Quantity < 1 {
+				return nil, fmt.Errorf("quantity of %s must be positive", loot.ItemID)
+			}
+			item, err := s.inventoryRepo.GetSessionItem(loot.ItemID, award.SessionID)
+			if err != nil {
+				return nil, err
+			}
+			if item == nil {
+				return nil, fmt.Errorf("%s: %s", errMsgItemNotFound, loot.ItemID)
+			}
+			txn.Items = append(txn.Items, models.ItemMovement{CharacterID: recipient.CharacterID, ItemID: loot.ItemID, Quantity: loot.Quantity})
+			names[recipient.CharacterID] = append(names[recipient.CharacterID], item.Name)
+		}
+	}
+	if len(txn.Currency) == 0 && len(txn.Items) == 0 {
+		return nil, fmt.Errorf("loot award is empty")
+	}
+	entries, err := s.applyTransaction(ctx, txn)
+	if err != nil {
+		return nil, err
+	}
+
+	if award.SessionID != "" {
+		for i := range award.Recipients {
+			recipient := award.Recipients[i]
+			recordGameEvent(ctx, s.events, &models.GameEvent{
+				SessionID:   award.SessionID,
+				Type:        models.GameEventLoot,
+				PlayerID:    txn.CreatedBy,
+				CharacterID: &recipient.CharacterID,
+				Data: map[string]interface{}{
+					"description": description,
+					"coins":       recipient.Coins,
+					"items":       names[recipient.CharacterID],
+				},
+			})
+		}
+	}
+	return entries, nil
+}
+
+// GetLedger returns the ledger entries matching the filter, newest first
+func (s *InventoryService) GetLedger(filter models.LedgerFilter) ([]*models.LedgerEntry, error) {
+	return s.inventoryRepo.GetLedgerEntries(filter)
+}
 
-	return s.inventoryRepo.UpdateCharacterCurrency(currency)
+// applyTransaction commits an economy transaction and snapshots each character it touched
+func (s *InventoryService) applyTransaction(ctx context.Context, txn *models.EconomyTransaction) ([]*models.LedgerEntry, error) {
+	entries, err := s.inventoryRepo.ApplyTransaction(txn)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := make(map[string]bool)
+	for _, entry := range entries {
+		if recorded[entry.CharacterID] {
+			continue
+		}
+		recorded[entry.CharacterID] = true
+		s.recordInventoryChange(ctx, entry.CharacterID, txn.Description)
+	}
+	return entries, nil
 }
 
 func (s *InventoryService) GetCharacterWeight(characterID string) (*models.InventoryWeight, error) {
@@ -271,6 +640,7 @@ func (s *InventoryService) CreateItem(item *models.Item) error {
 	return s.inventoryRepo.CreateItem(item)
 }
 
-func (s *InventoryService) GetItemsByType(itemType models.ItemType) ([]*models.Item, error) {
-	return s.inventoryRepo.GetItemsByType(itemType)
+// GetItemsByType lists the shared items of a type plus, when sessionID is set, that session's homebrew
+func (s *InventoryService) GetItemsByType(itemType models.ItemType, sessionID string) ([]*models.Item, error) {
+	return s.inventoryRepo.GetItemsByType(itemType, sessionID)
 }
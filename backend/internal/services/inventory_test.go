@@ -19,13 +19,15 @@ import (
 const (
 	// Repository method names
 	testMethodGetItem               = "GetItem"
+	testMethodGetCharacterItem      = "GetCharacterItem"
+	testMethodGetSessionItem        = "GetSessionItem"
 	testMethodGetCharacterInventory = "GetCharacterInventory"
 	testMethodGetCharacterCurrency  = "GetCharacterCurrency"
-	testMethodUpdateCharCurrency    = "UpdateCharacterCurrency"
 	testMethodRemoveItem            = "RemoveItemFromInventory"
 	testMethodEquipItem             = "EquipItem"
 	testMethodAddItem               = "AddItemToInventory"
 	testMethodGetByID               = "GetByID"
+	testMethodApplyTransaction      = "ApplyTransaction"
 	
 	// Test values
 	testIDNonexistent   = "nonexistent"
@@ -136,7 +138,7 @@ func TestInventoryService_AddItemToCharacter(t *testing.T) {
 
 				// Item exists
 				item := mocks.CreateTestItem(constants.TestItemID, constants.TestHealingPotion, models.ItemTypeConsumable, 50, 0.5)
-				invRepo.On(testMethodGetItem, constants.TestItemID).Return(item, nil)
+				invRepo.On(testMethodGetCharacterItem, constants.TestItemID, constants.TestCharacterID).Return(item, nil)
 
 				// Add to inventory
 				invRepo.On("AddItemToInventory", constants.TestCharacterID, constants.TestItemID, 2).Return(nil)
@@ -170,7 +172,7 @@ func TestInventoryService_AddItemToCharacter(t *testing.T) {
 			setupMock: func(invRepo *mocks.MockInventoryRepository, charRepo *mocks.MockCharacterRepository) {
 				char := mocks.CreateTestCharacter(constants.TestCharacterID, constants.TestUserID, constants.TestCharacterName, testRaceHuman, testClassFighter)
 				charRepo.On(testMethodGetByID, ctx, constants.TestCharacterID).Return(char, nil)
-				invRepo.On(testMethodGetItem, testIDNonexistent).Return(nil, errors.New(testErrNotFound))
+				invRepo.On(testMethodGetCharacterItem, testIDNonexistent, constants.TestCharacterID).Return(nil, errors.New(testErrNotFound))
 			},
 			expectedError: testErrNotFound,
 		},
@@ -182,7 +184,7 @@ func TestInventoryService_AddItemToCharacter(t *testing.T) {
 			setupMock: func(invRepo *mocks.MockInventoryRepository, charRepo *mocks.MockCharacterRepository) {
 				char := mocks.CreateTestCharacter(constants.TestCharacterID, constants.TestUserID, constants.TestCharacterName, testRaceHuman, testClassFighter)
 				charRepo.On(testMethodGetByID, ctx, constants.TestCharacterID).Return(char, nil)
-				invRepo.On(testMethodGetItem, constants.TestItemID).Return(nil, nil)
+				invRepo.On(testMethodGetCharacterItem, constants.TestItemID, constants.TestCharacterID).Return(nil, nil)
 			},
 			expectedError: testErrItemNotFound,
 		},
@@ -196,7 +198,7 @@ func TestInventoryService_AddItemToCharacter(t *testing.T) {
 				charRepo.On(testMethodGetByID, ctx, constants.TestCharacterID).Return(char, nil)
 
 				item := mocks.CreateTestItem(constants.TestItemID, constants.TestHealingPotion, models.ItemTypeConsumable, 50, 0.5)
-				invRepo.On(testMethodGetItem, constants.TestItemID).Return(item, nil)
+				invRepo.On(testMethodGetCharacterItem, constants.TestItemID, constants.TestCharacterID).Return(item, nil)
 
 				invRepo.On("AddItemToInventory", constants.TestCharacterID, constants.TestItemID, 1).Return(errors.New(constants.TestDatabaseError))
 			},
@@ -212,7 +214,7 @@ func TestInventoryService_AddItemToCharacter(t *testing.T) {
 				charRepo.On(testMethodGetByID, ctx, constants.TestCharacterID).Return(char, nil)
 
 				item := mocks.CreateTestItem(constants.TestItemID, constants.TestHealingPotion, models.ItemTypeConsumable, 50, 0.5)
-				invRepo.On(testMethodGetItem, constants.TestItemID).Return(item, nil)
+				invRepo.On(testMethodGetCharacterItem, constants.TestItemID, constants.TestCharacterID).Return(item, nil)
 
 				invRepo.On("AddItemToInventory", constants.TestCharacterID, constants.TestItemID, 0).Return(nil)
 			},
@@ -229,7 +231,7 @@ func TestInventoryService_AddItemToCharacter(t *testing.T) {
 			}
 
 			service := services.NewInventoryService(mockInvRepo, mockCharRepo)
-			err := service.AddItemToCharacter(tt.characterID, tt.itemID, tt.quantity)
+			err := service.AddItemToCharacter(context.Background(), tt.characterID, tt.itemID, tt.quantity)
 
 			if tt.expectedError != "" {
 				require.Error(t, err)
@@ -327,6 +329,19 @@ func TestInventoryService_EquipItem(t *testing.T) {
 			},
 			expectedError: "not enough hands to equip this weapon",
 		},
+		{
+			name:        "weapon packed in a container",
+			characterID: constants.TestCharacterID,
+			itemID:      constants.TestSwordID,
+			setupMock: func(m *mocks.MockInventoryRepository) {
+				sword := mocks.CreateTestItem(constants.TestSwordID, constants.TestLongsword, models.ItemTypeWeapon, 15, 3.0)
+				invItem := mocks.CreateTestInventoryItem(constants.TestCharacterID, constants.TestSwordID, 1, false, false, sword)
+				packID := "inv-pack"
+				invItem.ContainerID = &packID
+				m.On(testMethodGetCharacterInventory, constants.TestCharacterID).Return([]*models.InventoryItem{invItem}, nil)
+			},
+			expectedError: "out of its container",
+		},
 		{
 			name:        testErrItemNotInInv,
 			characterID: constants.TestCharacterID,
@@ -348,10 +363,165 @@ func TestInventoryService_EquipItem(t *testing.T) {
 	}
 
 	runInventoryServiceTest(t, tests, func(service *services.InventoryService, characterID, itemID string) error {
-		return service.EquipItem(characterID, itemID)
+		return service.EquipItem(context.Background(), characterID, itemID)
 	})
 }
 
+func TestInventoryService_MoveItem(t *testing.T) {
+	packID, quiverID := "inv-pack", "inv-quiver"
+	newInventory := func() []*models.InventoryItem {
+		pack := mocks.CreateTestItem("backpack", "Backpack", models.ItemTypeOther, 200, 5)
+		pack.Properties["capacity_weight"] = 30.0
+		quiver := mocks.CreateTestItem("quiver", "Quiver", models.ItemTypeOther, 100, 1)
+		quiver.Properties["capacity_count"] = 20.0
+		bag := mocks.CreateTestItem("bag_of_holding", "Bag of Holding", models.ItemTypeMagic, 50000, 15)
+		bag.Properties["container"] = true
+		bag.Properties["extradimensional"] = true
+
+		inventory := []*models.InventoryItem{
+			mocks.CreateTestInventoryItem(constants.TestCharacterID, "backpack", 1, false, false, pack),
+			mocks.CreateTestInventoryItem(constants.TestCharacterID, "quiver", 1, false, false, quiver),
+			mocks.CreateTestInventoryItem(constants.TestCharacterID, "bag_of_holding", 1, false, false, bag),
+			mocks.CreateTestInventoryItem(constants.TestCharacterID, "rope", 1, false, false,
+				mocks.CreateTestItem("rope", "Rope", models.ItemTypeOther, 100, 10)),
+			mocks.CreateTestInventoryItem(constants.TestCharacterID, testItemArrow, 40, false, false,
+				mocks.CreateTestItem(testItemArrow, "Arrow", models.ItemTypeOther, 5, 0.05)),
+			mocks.CreateTestInventoryItem(constants.TestCharacterID, "anvil", 1, false, false,
+				mocks.CreateTestItem("anvil", "Anvil", models.ItemTypeOther, 500, 25)),
+			mocks.CreateTestInventoryItem(constants.TestCharacterID, constants.TestSwordID, 1, true, false,
+				mocks.CreateTestItem(constants.TestSwordID, constants.TestLongsword, models.ItemTypeWeapon, 1500, 3)),
+		}
+		for _, inv := range inventory {
+			inv.ID = "inv-" + inv.ItemID
+			inv.Location = models.ItemLocationCarried
+		}
+		inventory[0].ID = packID
+		inventory[1].ID = quiverID
+		// The rope is already in the backpack
+		inventory[3].ContainerID = &packID
+		return inventory
+	}
+
+	tests := []struct {
+		name          string
+		itemID        string
+		request       *models.MoveItemRequest
+		expectedMove  func(*models.InventoryMove) bool
+		expectedError string
+	}{
+		{
+			name:    "pack an item into a container",
+			itemID:  "quiver",
+			request: &models.MoveItemRequest{ContainerItemID: "backpack"},
+			expectedMove: func(move *models.InventoryMove) bool {
+				return move.ContainerID != nil && *move.ContainerID == packID && move.Location == models.ItemLocationCarried
+			},
+		},
+		{
+			name:    "store a container with its contents",
+			itemID:  "backpack",
+			request: &models.MoveItemRequest{Location: models.ItemLocationStored, StorageNote: "at the Yawning Portal"},
+			expectedMove: func(move *models.InventoryMove) bool {
+				return move.ContainerID == nil && move.Location == models.ItemLocationStored &&
+					move.StorageNote == "at the Yawning Portal" && len(move.Contents) == 1 && move.Contents[0] == "inv-rope"
+			},
+		},
+		{
+			name:    "bag of holding holds far more than its weight",
+			itemID:  "anvil",
+			request: &models.MoveItemRequest{ContainerItemID: "bag_of_holding"},
+			expectedMove: func(move *models.InventoryMove) bool {
+				return move.ContainerID != nil && *move.ContainerID == "inv-bag_of_holding"
+			},
+		},
+		{
+			name:    "split part of a stack into a container",
+			itemID:  testItemArrow,
+			request: &models.MoveItemRequest{ContainerItemID: "quiver", Quantity: 20},
+			expectedMove: func(move *models.InventoryMove) bool {
+				return move.StackID == "inv-"+testItemArrow && move.Quantity == 20 &&
+					move.ContainerID != nil && *move.ContainerID == quiverID
+			},
+		},
+		{
+			name:          "split more than the stack holds",
+			itemID:        testItemArrow,
+			request:       &models.MoveItemRequest{ContainerItemID: "quiver", Quantity: 41},
+			expectedError: "only 40 Arrow",
+		},
+		{
+			name:          "split stack left where it was",
+			itemID:        testItemArrow,
+			request:       &models.MoveItemRequest{Quantity: 10},
+			expectedError: "different container",
+		},
+		{
+			name:          "move from a container that does not hold the item",
+			itemID:        "anvil",
+			request:       &models.MoveItemRequest{FromContainerItemID: "backpack"},
+			expectedError: "item not found",
+		},
+		{
+			name:          "container over its weight limit",
+			itemID:        "anvil",
+			request:       &models.MoveItemRequest{ContainerItemID: "backpack"},
+			expectedError: "Backpack can hold only 30 lb",
+		},
+		{
+			name:          "container over its item count",
+			itemID:        testItemArrow,
+			request:       &models.MoveItemRequest{ContainerItemID: "quiver"},
+			expectedError: "Quiver can hold only 20 items",
+		},
+		{
+			name:          "destination is not a container",
+			itemID:        "anvil",
+			request:       &models.MoveItemRequest{ContainerItemID: "rope"},
+			expectedError: "Rope is not a container",
+		},
+		{
+			name:          "container inside itself",
+			itemID:        "backpack",
+			request:       &models.MoveItemRequest{ContainerItemID: "backpack"},
+			expectedError: "inside itself",
+		},
+		{
+			name:          "equipped items must be unequipped first",
+			itemID:        constants.TestSwordID,
+			request:       &models.MoveItemRequest{ContainerItemID: "backpack"},
+			expectedError: "unequip " + constants.TestLongsword,
+		},
+		{
+			name:          "unknown location",
+			itemID:        "anvil",
+			request:       &models.MoveItemRequest{Location: "moon"},
+			expectedError: "invalid location",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockInventoryRepo := new(mocks.MockInventoryRepository)
+			service := services.NewInventoryService(mockInventoryRepo, new(mocks.MockCharacterRepository))
+			mockInventoryRepo.On(testMethodGetCharacterInventory, constants.TestCharacterID).Return(newInventory(), nil)
+			if tt.expectedMove != nil {
+				mockInventoryRepo.On("MoveInventoryItem", constants.TestCharacterID, mock.MatchedBy(tt.expectedMove)).Return(nil)
+			}
+
+			err := service.MoveItem(context.Background(), constants.TestCharacterID, tt.itemID, tt.request)
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				mockInventoryRepo.AssertNotCalled(t, "MoveInventoryItem", mock.Anything, mock.Anything)
+			} else {
+				require.NoError(t, err)
+			}
+			mockInventoryRepo.AssertExpectations(t)
+		})
+	}
+}
+
 func TestInventoryService_AttuneToItem(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -439,7 +609,22 @@ func TestInventoryService_AttuneToItem(t *testing.T) {
 	}
 
 	runInventoryServiceTest(t, tests, func(service *services.InventoryService, characterID, itemID string) error {
-		return service.AttuneToItem(characterID, itemID)
+		return service.AttuneToItem(context.Background(), characterID, itemID)
+	})
+}
+
+// matchTransaction matches an economy transaction by type, currency deltas in copper and item movements
+func matchTransaction(entryType models.LedgerEntryType, copper map[string]int, items []models.ItemMovement) interface{} {
+	return mock.MatchedBy(func(txn *models.EconomyTransaction) bool {
+		if txn.Type != entryType || len(txn.Currency) != len(copper) {
+			return false
+		}
+		for _, movement := range txn.Currency {
+			if delta, ok := copper[movement.CharacterID]; !ok || movement.Coins.TotalInCopper() != delta {
+				return false
+			}
+		}
+		return assert.ObjectsAreEqual(items, txn.Items) || (len(items) == 0 && len(txn.Items) == 0)
 	})
 }
 
@@ -454,7 +639,6 @@ func TestInventoryService_UpdateCharacterCurrency(t *testing.T) {
 		platinum      int
 		setupMock     func(*mocks.MockInventoryRepository)
 		expectedError string
-		validate      func(*testing.T, *models.Currency)
 	}{
 		{
 			name:        "add currency successfully",
@@ -463,16 +647,10 @@ func TestInventoryService_UpdateCharacterCurrency(t *testing.T) {
 			silver:      3,
 			gold:        10,
 			setupMock: func(m *mocks.MockInventoryRepository) {
-				existingCurrency := &models.Currency{
-					CharacterID: constants.TestCharacterID,
-					Copper:      10,
-					Silver:      5,
-					Gold:        2,
-				}
-				m.On(testMethodGetCharacterCurrency, constants.TestCharacterID).Return(existingCurrency, nil)
-				m.On("UpdateCharacterCurrency", mock.MatchedBy(func(c *models.Currency) bool {
-					return c.Copper == 15 && c.Silver == 8 && c.Gold == 12
-				})).Return(nil)
+				m.On(testMethodApplyTransaction, mock.MatchedBy(func(txn *models.EconomyTransaction) bool {
+					return txn.Type == models.LedgerEntryAdjustment && len(txn.Currency) == 1 &&
+						txn.Currency[0].Coins == models.Coins{Copper: 5, Silver: 3, Gold: 10}
+				})).Return([]*models.LedgerEntry{{CharacterID: constants.TestCharacterID}}, nil)
 			},
 		},
 		{
@@ -482,16 +660,9 @@ func TestInventoryService_UpdateCharacterCurrency(t *testing.T) {
 			silver:      -2,
 			gold:        -1,
 			setupMock: func(m *mocks.MockInventoryRepository) {
-				existingCurrency := &models.Currency{
-					CharacterID: constants.TestCharacterID,
-					Copper:      10,
-					Silver:      5,
-					Gold:        2,
-				}
-				m.On(testMethodGetCharacterCurrency, constants.TestCharacterID).Return(existingCurrency, nil)
-				m.On("UpdateCharacterCurrency", mock.MatchedBy(func(c *models.Currency) bool {
-					return c.Copper == 5 && c.Silver == 3 && c.Gold == 1
-				})).Return(nil)
+				m.On(testMethodApplyTransaction, matchTransaction(models.LedgerEntryAdjustment,
+					map[string]int{constants.TestCharacterID: -125}, nil)).
+					Return([]*models.LedgerEntry{{CharacterID: constants.TestCharacterID}}, nil)
 			},
 		},
 		{
@@ -499,34 +670,16 @@ func TestInventoryService_UpdateCharacterCurrency(t *testing.T) {
 			characterID: constants.TestCharacterID,
 			gold:        -10,
 			setupMock: func(m *mocks.MockInventoryRepository) {
-				existingCurrency := &models.Currency{
-					CharacterID: constants.TestCharacterID,
-					Gold:        2,
-				}
-				m.On(testMethodGetCharacterCurrency, constants.TestCharacterID).Return(existingCurrency, nil)
+				m.On(testMethodApplyTransaction, mock.Anything).Return(nil, errors.New(testErrInsufficientFunds))
 			},
 			expectedError: testErrInsufficientFunds,
 		},
 		{
-			name:        "get currency error",
-			characterID: constants.TestCharacterID,
-			gold:        10,
-			setupMock: func(m *mocks.MockInventoryRepository) {
-				m.On(testMethodGetCharacterCurrency, constants.TestCharacterID).Return(nil, errors.New(constants.TestDatabaseError))
-			},
-			expectedError: constants.TestDatabaseError,
-		},
-		{
-			name:        "update currency error",
+			name:        "transaction error",
 			characterID: constants.TestCharacterID,
 			gold:        10,
 			setupMock: func(m *mocks.MockInventoryRepository) {
-				existingCurrency := &models.Currency{
-					CharacterID: constants.TestCharacterID,
-					Gold:        2,
-				}
-				m.On(testMethodGetCharacterCurrency, constants.TestCharacterID).Return(existingCurrency, nil)
-				m.On("UpdateCharacterCurrency", mock.Anything).Return(errors.New(testErrUpdateFailed))
+				m.On(testMethodApplyTransaction, mock.Anything).Return(nil, errors.New(testErrUpdateFailed))
 			},
 			expectedError: testErrUpdateFailed,
 		},
@@ -540,7 +693,7 @@ func TestInventoryService_UpdateCharacterCurrency(t *testing.T) {
 			}
 
 			service := services.NewInventoryService(mockRepo, nil)
-			err := service.UpdateCharacterCurrency(tt.characterID, tt.copper, tt.silver, tt.electrum, tt.gold, tt.platinum)
+			err := service.UpdateCharacterCurrency(context.Background(), tt.characterID, tt.copper, tt.silver, tt.electrum, tt.gold, tt.platinum)
 
 			if tt.expectedError != "" {
 				require.Error(t, err)
@@ -569,30 +722,14 @@ func TestInventoryService_PurchaseItem(t *testing.T) {
 			itemID:      testItemPotion,
 			quantity:    3,
 			setupMock: func(m *mocks.MockInventoryRepository) {
-				// Get item
 				potion := mocks.CreateTestItem(testItemPotion, "Healing Potion", models.ItemTypeConsumable, 50, 0.5)
-				m.On("GetItem", testItemPotion).Return(potion, nil)
+				m.On(testMethodGetCharacterItem, testItemPotion, constants.TestCharacterID).Return(potion, nil)
 
-				// Get currency (has 200 gold = 20000 copper)
-				currency := &models.Currency{
-					CharacterID: constants.TestCharacterID,
-					Gold:        200,
-				}
-				m.On(testMethodGetCharacterCurrency, constants.TestCharacterID).Return(currency, nil)
-
-				// Update currency (150 copper cost for 3 potions)
-				m.On("UpdateCharacterCurrency", mock.MatchedBy(func(c *models.Currency) bool {
-					// 20000 - 150 = 19850 copper = 19 platinum, 8 gold, 1 electrum
-					return c.CharacterID == constants.TestCharacterID &&
-						c.Platinum == 19 &&
-						c.Gold == 8 &&
-						c.Electrum == 1 &&
-						c.Silver == 0 &&
-						c.Copper == 0
-				})).Return(nil)
-
-				// Add items to inventory
-				m.On("AddItemToInventory", constants.TestCharacterID, testItemPotion, 3).Return(nil)
+				// 150 copper leaves the purse and 3 potions arrive in the same transaction
+				m.On(testMethodApplyTransaction, matchTransaction(models.LedgerEntryPurchase,
+					map[string]int{constants.TestCharacterID: -150},
+					[]models.ItemMovement{{CharacterID: constants.TestCharacterID, ItemID: testItemPotion, Quantity: 3}})).
+					Return([]*models.LedgerEntry{{CharacterID: constants.TestCharacterID}}, nil)
 			},
 		},
 		{
@@ -601,7 +738,7 @@ func TestInventoryService_PurchaseItem(t *testing.T) {
 			itemID:      testIDNonexistent,
 			quantity:    1,
 			setupMock: func(m *mocks.MockInventoryRepository) {
-				m.On("GetItem", "nonexistent").Return(nil, errors.New(testErrNotFound))
+				m.On(testMethodGetCharacterItem, "nonexistent", constants.TestCharacterID).Return(nil, errors.New(testErrNotFound))
 			},
 			expectedError: testErrNotFound,
 		},
@@ -611,70 +748,33 @@ func TestInventoryService_PurchaseItem(t *testing.T) {
 			itemID:      constants.TestItemID,
 			quantity:    1,
 			setupMock: func(m *mocks.MockInventoryRepository) {
-				m.On("GetItem", constants.TestItemID).Return(nil, nil)
+				m.On(testMethodGetCharacterItem, constants.TestItemID, constants.TestCharacterID).Return(nil, nil)
 			},
 			expectedError: testErrItemNotFound,
 		},
+		{
+			name:          "non-positive quantity",
+			characterID:   constants.TestCharacterID,
+			itemID:        constants.TestItemID,
+			quantity:      0,
+			expectedError: "quantity must be positive",
+		},
 		{
 			name:        testErrInsufficientFunds,
 			characterID: constants.TestCharacterID,
 			itemID:      testItemExpensive,
 			quantity:    1,
 			setupMock: func(m *mocks.MockInventoryRepository) {
-				// Expensive item
-				item := mocks.CreateTestItem("expensive-item", "Plate Armor", models.ItemTypeArmor, 150000, 65.0)
-				m.On("GetItem", testItemExpensive).Return(item, nil)
-
-				// Poor character
-				currency := &models.Currency{
-					CharacterID: constants.TestCharacterID,
-					Gold:        10,
-				}
-				m.On(testMethodGetCharacterCurrency, constants.TestCharacterID).Return(currency, nil)
+				item := mocks.CreateTestItem(testItemExpensive, "Plate Armor", models.ItemTypeArmor, 150000, 65.0)
+				m.On(testMethodGetCharacterItem, testItemExpensive, constants.TestCharacterID).Return(item, nil)
+				m.On(testMethodApplyTransaction, mock.Anything).Return(nil, errors.New(testErrInsufficientFunds))
 			},
 			expectedError: testErrInsufficientFunds,
 		},
-		{
-			name:        "currency update error",
-			characterID: constants.TestCharacterID,
-			itemID:      constants.TestItemID,
-			quantity:    1,
-			setupMock: func(m *mocks.MockInventoryRepository) {
-				item := mocks.CreateTestItem(constants.TestItemID, "Item", models.ItemTypeOther, 10, 1.0)
-				m.On("GetItem", constants.TestItemID).Return(item, nil)
-
-				currency := &models.Currency{
-					CharacterID: constants.TestCharacterID,
-					Gold:        100,
-				}
-				m.On(testMethodGetCharacterCurrency, constants.TestCharacterID).Return(currency, nil)
-				m.On("UpdateCharacterCurrency", mock.Anything).Return(errors.New(testErrUpdateFailed))
-			},
-			expectedError: testErrUpdateFailed,
-		},
-		{
-			name:        "add to inventory error",
-			characterID: constants.TestCharacterID,
-			itemID:      constants.TestItemID,
-			quantity:    1,
-			setupMock: func(m *mocks.MockInventoryRepository) {
-				item := mocks.CreateTestItem(constants.TestItemID, "Item", models.ItemTypeOther, 10, 1.0)
-				m.On("GetItem", constants.TestItemID).Return(item, nil)
-
-				currency := &models.Currency{
-					CharacterID: constants.TestCharacterID,
-					Gold:        100,
-				}
-				m.On(testMethodGetCharacterCurrency, constants.TestCharacterID).Return(currency, nil)
-				m.On("UpdateCharacterCurrency", mock.Anything).Return(nil)
-				m.On("AddItemToInventory", constants.TestCharacterID, constants.TestItemID, 1).Return(errors.New("inventory full"))
-			},
-			expectedError: "inventory full",
-		},
 	}
 
 	runInventoryServiceTestWithQuantity(t, tests, func(service *services.InventoryService, characterID, itemID string, quantity int) error {
-		return service.PurchaseItem(characterID, itemID, quantity)
+		return service.PurchaseItem(context.Background(), characterID, itemID, quantity)
 	})
 }
 
@@ -693,30 +793,14 @@ func TestInventoryService_SellItem(t *testing.T) {
 			itemID:      constants.TestSwordID,
 			quantity:    1,
 			setupMock: func(m *mocks.MockInventoryRepository) {
-				// Get item (worth 100 gold)
 				sword := mocks.CreateTestItem(constants.TestSwordID, "Longsword", models.ItemTypeWeapon, 10000, 3.0)
-				m.On("GetItem", constants.TestSwordID).Return(sword, nil)
-
-				// Remove from inventory
-				m.On("RemoveItemFromInventory", constants.TestCharacterID, constants.TestSwordID, 1).Return(nil)
+				m.On(testMethodGetItem, constants.TestSwordID).Return(sword, nil)
 
-				// Get current currency
-				currency := &models.Currency{
-					CharacterID: constants.TestCharacterID,
-					Gold:        10,
-				}
-				m.On(testMethodGetCharacterCurrency, constants.TestCharacterID).Return(currency, nil)
-
-				// Update currency (sale price is 50% = 5000 copper)
-				m.On("UpdateCharacterCurrency", mock.MatchedBy(func(c *models.Currency) bool {
-					// 1000 + 5000 = 6000 copper = 6 platinum
-					return c.CharacterID == constants.TestCharacterID &&
-						c.Platinum == 6 &&
-						c.Gold == 0 &&
-						c.Electrum == 0 &&
-						c.Silver == 0 &&
-						c.Copper == 0
-				})).Return(nil)
+				// Sale price is 50% = 5000 copper
+				m.On(testMethodApplyTransaction, matchTransaction(models.LedgerEntrySale,
+					map[string]int{constants.TestCharacterID: 5000},
+					[]models.ItemMovement{{CharacterID: constants.TestCharacterID, ItemID: constants.TestSwordID, Quantity: -1}})).
+					Return([]*models.LedgerEntry{{CharacterID: constants.TestCharacterID}}, nil)
 			},
 		},
 		{
@@ -725,64 +809,126 @@ func TestInventoryService_SellItem(t *testing.T) {
 			itemID:      testIDNonexistent,
 			quantity:    1,
 			setupMock: func(m *mocks.MockInventoryRepository) {
-				m.On("GetItem", "nonexistent").Return(nil, errors.New(testErrNotFound))
+				m.On(testMethodGetItem, "nonexistent").Return(nil, errors.New(testErrNotFound))
 			},
 			expectedError: testErrNotFound,
 		},
 		{
-			name:        "remove from inventory error",
+			name:        "item not in inventory",
 			characterID: constants.TestCharacterID,
 			itemID:      constants.TestItemID,
 			quantity:    1,
 			setupMock: func(m *mocks.MockInventoryRepository) {
 				item := mocks.CreateTestItem(constants.TestItemID, "Item", models.ItemTypeOther, 100, 1.0)
-				m.On("GetItem", constants.TestItemID).Return(item, nil)
-				m.On("RemoveItemFromInventory", constants.TestCharacterID, constants.TestItemID, 1).Return(errors.New(testErrItemNotInInv))
+				m.On(testMethodGetItem, constants.TestItemID).Return(item, nil)
+				m.On(testMethodApplyTransaction, mock.Anything).Return(nil, errors.New(testErrItemNotInInv))
 			},
 			expectedError: testErrItemNotInInv,
 		},
-		{
-			name:        "currency retrieval error",
-			characterID: constants.TestCharacterID,
-			itemID:      constants.TestItemID,
-			quantity:    1,
-			setupMock: func(m *mocks.MockInventoryRepository) {
-				item := mocks.CreateTestItem(constants.TestItemID, "Item", models.ItemTypeOther, 100, 1.0)
-				m.On("GetItem", constants.TestItemID).Return(item, nil)
-				m.On("RemoveItemFromInventory", constants.TestCharacterID, constants.TestItemID, 1).Return(nil)
-				m.On(testMethodGetCharacterCurrency, constants.TestCharacterID).Return(nil, errors.New(constants.TestDatabaseError))
-			},
-			expectedError: constants.TestDatabaseError,
-		},
 		{
 			name:        "sell multiple items",
 			characterID: constants.TestCharacterID,
 			itemID:      testItemArrow,
 			quantity:    20,
 			setupMock: func(m *mocks.MockInventoryRepository) {
-				// Arrows worth 1 copper each
+				// Arrows worth 1 copper each sell for 10 copper
 				arrow := mocks.CreateTestItem(testItemArrow, "Arrow", models.ItemTypeOther, 1, 0.05)
-				m.On("GetItem", testItemArrow).Return(arrow, nil)
-
-				m.On("RemoveItemFromInventory", constants.TestCharacterID, testItemArrow, 20).Return(nil)
-
-				currency := &models.Currency{
-					CharacterID: constants.TestCharacterID,
-					Copper:      5,
-				}
-				m.On(testMethodGetCharacterCurrency, constants.TestCharacterID).Return(currency, nil)
-
-				// Sale price is 10 copper (20 * 1 / 2)
-				m.On("UpdateCharacterCurrency", mock.MatchedBy(func(c *models.Currency) bool {
-					// 5 + 10 = 15 copper = 1 silver, 5 copper
-					return c.Silver == 1 && c.Copper == 5
-				})).Return(nil)
+				m.On(testMethodGetItem, testItemArrow).Return(arrow, nil)
+				m.On(testMethodApplyTransaction, matchTransaction(models.LedgerEntrySale,
+					map[string]int{constants.TestCharacterID: 10},
+					[]models.ItemMovement{{CharacterID: constants.TestCharacterID, ItemID: testItemArrow, Quantity: -20}})).
+					Return([]*models.LedgerEntry{{CharacterID: constants.TestCharacterID}}, nil)
 			},
 		},
 	}
 
 	runInventoryServiceTestWithQuantity(t, tests, func(service *services.InventoryService, characterID, itemID string, quantity int) error {
-		return service.SellItem(characterID, itemID, quantity)
+		return service.SellItem(context.Background(), characterID, itemID, quantity)
+	})
+}
+
+func TestInventoryService_Transfer(t *testing.T) {
+	ctx := context.Background()
+	const toCharacterID = "char-recipient"
+
+	t.Run("moves coins and items between characters", func(t *testing.T) {
+		mockRepo := new(mocks.MockInventoryRepository)
+		service := services.NewInventoryService(mockRepo, nil)
+		mockRepo.On(testMethodApplyTransaction, matchTransaction(models.LedgerEntryTransfer,
+			map[string]int{constants.TestCharacterID: -500, toCharacterID: 500},
+			[]models.ItemMovement{
+				{CharacterID: constants.TestCharacterID, ItemID: testItemPotion, Quantity: -2},
+				{CharacterID: toCharacterID, ItemID: testItemPotion, Quantity: 2},
+			})).Return([]*models.LedgerEntry{{CharacterID: constants.TestCharacterID}, {CharacterID: toCharacterID}}, nil)
+
+		entries, err := service.Transfer(ctx, &models.TransferRequest{
+			FromCharacterID: constants.TestCharacterID,
+			ToCharacterID:   toCharacterID,
+			Coins:           models.Coins{Gold: 5},
+			Items:           []models.LootItem{{ItemID: testItemPotion, Quantity: 2}},
+		})
+		require.NoError(t, err)
+		assert.Len(t, entries, 2)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects invalid transfers", func(t *testing.T) {
+		service := services.NewInventoryService(new(mocks.MockInventoryRepository), nil)
+		for _, req := range []*models.TransferRequest{
+			{FromCharacterID: constants.TestCharacterID, ToCharacterID: constants.TestCharacterID, Coins: models.Coins{Gold: 1}},
+			{FromCharacterID: constants.TestCharacterID, ToCharacterID: toCharacterID, Coins: models.Coins{Gold: -1}},
+			{FromCharacterID: constants.TestCharacterID, ToCharacterID: toCharacterID},
+			{FromCharacterID: constants.TestCharacterID, ToCharacterID: toCharacterID, Items: []models.LootItem{{ItemID: testItemPotion}}},
+		} {
+			_, err := service.Transfer(ctx, req)
+			assert.Error(t, err)
+		}
+	})
+}
+
+func TestInventoryService_AwardLoot(t *testing.T) {
+	ctx := context.Background()
+	const otherCharacterID = "char-other"
+
+	t.Run("awards every share in one transaction", func(t *testing.T) {
+		mockRepo := new(mocks.MockInventoryRepository)
+		service := services.NewInventoryService(mockRepo, nil)
+		potion := mocks.CreateTestItem(testItemPotion, "Healing Potion", models.ItemTypeConsumable, 50, 0.5)
+		mockRepo.On(testMethodGetSessionItem, testItemPotion, "session-1").Return(potion, nil)
+		mockRepo.On(testMethodApplyTransaction, mock.MatchedBy(func(txn *models.EconomyTransaction) bool {
+			return txn.Type == models.LedgerEntryLoot && txn.SessionID == "session-1" &&
+				len(txn.Currency) == 2 && len(txn.Items) == 1 && txn.Items[0].CharacterID == otherCharacterID
+		})).Return([]*models.LedgerEntry{{CharacterID: constants.TestCharacterID}, {CharacterID: otherCharacterID}}, nil)
+
+		entries, err := service.AwardLoot(ctx, &models.LootAward{
+			SessionID:   "session-1",
+			Description: "dragon hoard",
+			Recipients: []models.LootRecipient{
+				{CharacterID: constants.TestCharacterID, Coins: models.Coins{Gold: 50}},
+				{CharacterID: otherCharacterID, Coins: models.Coins{Gold: 50}, Items: []models.LootItem{{ItemID: testItemPotion, Quantity: 1}}},
+			},
+		})
+		require.NoError(t, err)
+		assert.Len(t, entries, 2)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown item aborts the award", func(t *testing.T) {
+		mockRepo := new(mocks.MockInventoryRepository)
+		service := services.NewInventoryService(mockRepo, nil)
+		mockRepo.On(testMethodGetSessionItem, testIDNonexistent, "").Return(nil, nil)
+
+		_, err := service.AwardLoot(ctx, &models.LootAward{Recipients: []models.LootRecipient{
+			{CharacterID: constants.TestCharacterID, Items: []models.LootItem{{ItemID: testIDNonexistent, Quantity: 1}}},
+		}})
+		require.Error(t, err)
+		mockRepo.AssertNotCalled(t, testMethodApplyTransaction, mock.Anything)
+	})
+
+	t.Run("empty award", func(t *testing.T) {
+		service := services.NewInventoryService(new(mocks.MockInventoryRepository), nil)
+		_, err := service.AwardLoot(ctx, &models.LootAward{})
+		assert.Error(t, err)
 	})
 }
 
@@ -954,7 +1100,7 @@ func TestInventoryService_GetItemsByType(t *testing.T) {
 					mocks.CreateTestItem("axe-1", "Battleaxe", models.ItemTypeWeapon, 10, 4.0),
 					mocks.CreateTestItem("bow-1", "Longbow", models.ItemTypeWeapon, 50, 2.0),
 				}
-				m.On("GetItemsByType", models.ItemTypeWeapon).Return(weapons, nil)
+				m.On("GetItemsByType", models.ItemTypeWeapon, "session-1").Return(weapons, nil)
 			},
 			expected: []*models.Item{
 				mocks.CreateTestItem(testItemSword1, "Longsword", models.ItemTypeWeapon, 15, 3.0),
@@ -966,7 +1112,7 @@ func TestInventoryService_GetItemsByType(t *testing.T) {
 			name:     "get consumables - empty result",
 			itemType: models.ItemTypeConsumable,
 			setupMock: func(m *mocks.MockInventoryRepository) {
-				m.On("GetItemsByType", models.ItemTypeConsumable).Return([]*models.Item{}, nil)
+				m.On("GetItemsByType", models.ItemTypeConsumable, "session-1").Return([]*models.Item{}, nil)
 			},
 			expected: []*models.Item{},
 		},
@@ -974,7 +1120,7 @@ func TestInventoryService_GetItemsByType(t *testing.T) {
 			name:     testErrInventoryRepository,
 			itemType: models.ItemTypeMagic,
 			setupMock: func(m *mocks.MockInventoryRepository) {
-				m.On("GetItemsByType", models.ItemTypeMagic).Return(nil, errors.New(constants.TestDatabaseError))
+				m.On("GetItemsByType", models.ItemTypeMagic, "session-1").Return(nil, errors.New(constants.TestDatabaseError))
 			},
 			expectedError: constants.TestDatabaseError,
 		},
@@ -988,7 +1134,7 @@ func TestInventoryService_GetItemsByType(t *testing.T) {
 			}
 
 			service := services.NewInventoryService(mockRepo, nil)
-			items, err := service.GetItemsByType(tt.itemType)
+			items, err := service.GetItemsByType(tt.itemType, "session-1")
 
 			if tt.expectedError != "" {
 				require.Error(t, err)
@@ -1011,7 +1157,7 @@ func TestInventoryService_PassThroughMethods(t *testing.T) {
 
 	t.Run("RemoveItemFromCharacter", func(t *testing.T) {
 		mockInvRepo.On("RemoveItemFromInventory", constants.TestCharacterID, constants.TestItemID, 2).Return(nil).Once()
-		err := service.RemoveItemFromCharacter(constants.TestCharacterID, constants.TestItemID, 2)
+		err := service.RemoveItemFromCharacter(context.Background(), constants.TestCharacterID, constants.TestItemID, 2)
 		assert.NoError(t, err)
 	})
 
@@ -1027,14 +1173,22 @@ func TestInventoryService_PassThroughMethods(t *testing.T) {
 	})
 
 	t.Run("UnequipItem", func(t *testing.T) {
+		equipped := []*models.InventoryItem{
+			mocks.CreateTestInventoryItem(constants.TestCharacterID, constants.TestItemID, 1, true, false, nil),
+		}
+		mockInvRepo.On("GetCharacterInventory", constants.TestCharacterID).Return(equipped, nil).Once()
 		mockInvRepo.On("EquipItem", constants.TestCharacterID, constants.TestItemID, false).Return(nil).Once()
-		err := service.UnequipItem(constants.TestCharacterID, constants.TestItemID)
+		err := service.UnequipItem(context.Background(), constants.TestCharacterID, constants.TestItemID)
 		assert.NoError(t, err)
 	})
 
 	t.Run("UnattuneFromItem", func(t *testing.T) {
+		attuned := []*models.InventoryItem{
+			mocks.CreateTestInventoryItem(constants.TestCharacterID, constants.TestItemID, 1, false, true, nil),
+		}
+		mockInvRepo.On("GetCharacterInventory", constants.TestCharacterID).Return(attuned, nil).Once()
 		mockInvRepo.On("UnattuneItem", constants.TestCharacterID, constants.TestItemID).Return(nil).Once()
-		err := service.UnattuneFromItem(constants.TestCharacterID, constants.TestItemID)
+		err := service.UnattuneFromItem(context.Background(), constants.TestCharacterID, constants.TestItemID)
 		assert.NoError(t, err)
 	})
 
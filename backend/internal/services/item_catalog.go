@@ -0,0 +1,385 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+// equipmentPacksFile holds pack definitions rather than items
+const equipmentPacksFile = "equipment-packs.json"
+
+// CatalogItem is an item definition loaded from data/items
+type CatalogItem struct {
+	ID                     string                `json:"id"`
+	Name                   string                `json:"name"`
+	Type                   models.ItemType       `json:"type"`
+	Rarity                 models.ItemRarity     `json:"rarity"`
+	Weight                 float64               `json:"weight"`
+	Value                  int                   `json:"value"` // in copper pieces
+	Properties             models.ItemProperties `json:"properties"`
+	RequiresAttunement     bool                  `json:"requiresAttunement"`
+	AttunementRequirements string                `json:"attunementRequirements,omitempty"`
+	Description            string                `json:"description,omitempty"`
+	Tags                   []string              `json:"tags,omitempty"`
+	Aliases                []string              `json:"aliases,omitempty"`
+}
+
+// ToItem converts a catalog entry into an items table row
+func (c *CatalogItem) ToItem() *models.Item {
+	return &models.Item{
+		ID:                     c.ID,
+		Name:                   c.Name,
+		Type:                   c.Type,
+		Rarity:                 c.Rarity,
+		Weight:                 c.Weight,
+		Value:                  c.Value,
+		Properties:             c.Properties,
+		RequiresAttunement:     c.RequiresAttunement,
+		AttunementRequirements: c.AttunementRequirements,
+		Description:            c.Description,
+	}
+}
+
+// HasTag reports whether the item carries the given catalog tag
+func (c *CatalogItem) HasTag(tag string) bool {
+	for _, t := range c.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// PackContent is a single item inside an equipment pack
+type PackContent struct {
+	Item     string `json:"item"`
+	Quantity int    `json:"quantity"`
+}
+
+// EquipmentPack is a bundle such as an explorer's pack
+type EquipmentPack struct {
+	ID          string        `json:"id"`
+	Name        string        `json:"name"`
+	Value       int           `json:"value"` // in gold pieces
+	Contents    []PackContent `json:"contents"`
+	Description string        `json:"description"`
+}
+
+// ItemCatalog indexes the item and pack definitions shipped in data/items
+type ItemCatalog struct {
+	items   map[string]*CatalogItem
+	byName  map[string]*CatalogItem
+	packs   map[string]*EquipmentPack
+	version string
+}
+
+// rawCatalogItem covers both item file layouts in data/items: the flat
+// weapon/armor/gear lists (camelCase fields, value in gp) and the
+// {"items": [...]} files (snake_case properties, value in cp).
+type rawCatalogItem struct {
+	ID                     string          `json:"id"`
+	Name                   string          `json:"name"`
+	Type                   string          `json:"type"`
+	Rarity                 string          `json:"rarity"`
+	Weight                 float64         `json:"weight"`
+	Value                  float64         `json:"value"`
+	Description            string          `json:"description"`
+	Properties             json.RawMessage `json:"properties"`
+	RequiresAttunement     bool            `json:"requires_attunement"`
+	AttunementRequirements string          `json:"attunement_requirements"`
+	Tags                   []string        `json:"tags"`
+	Aliases                []string        `json:"aliases"`
+
+	// Weapon fields
+	WeaponType string `json:"weaponType"`
+	Damage     string `json:"damage"`
+	DamageType string `json:"damageType"`
+
+	// Armor fields
+	ArmorType           string `json:"armorType"`
+	ArmorClass          int    `json:"armorClass"`
+	DexModifier         *bool  `json:"dexModifier"`
+	MaxDexBonus         *int   `json:"maxDexBonus"`
+	StrengthRequirement int    `json:"strengthRequirement"`
+	StealthDisadvantage bool   `json:"stealthDisadvantage"`
+}
+
+var (
+	catalogSlugPattern      = regexp.MustCompile(`[^a-z0-9]+`)
+	weaponPropertyPattern   = regexp.MustCompile(`^([a-z-]+)\s*(?:\((.+)\))?$`)
+	weaponPropertyRangeText = regexp.MustCompile(`range\s+(\d+/\d+)`)
+)
+
+// NewItemCatalog loads every item file under dataPath/items
+func NewItemCatalog(dataPath string) (*ItemCatalog, error) {
+	catalog := &ItemCatalog{
+		items:  make(map[string]*CatalogItem),
+		byName: make(map[string]*CatalogItem),
+		packs:  make(map[string]*EquipmentPack),
+	}
+
+	dir := filepath.Join(dataPath, "items")
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read item data: %w", err)
+	}
+
+	// ReadDir sorts by name, so the hash only changes when the data does
+	hash := sha256.New()
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		hash.Write([]byte(file.Name()))
+		hash.Write(data)
+		if file.Name() == equipmentPacksFile {
+			if err := catalog.loadPacks(data); err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", file.Name(), err)
+			}
+			continue
+		}
+		if err := catalog.loadItems(data); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file.Name(), err)
+		}
+	}
+	catalog.version = hex.EncodeToString(hash.Sum(nil))[:16]
+
+	return catalog, nil
+}
+
+// Version identifies the item data the catalog was loaded from; it changes whenever a file in data/items does
+func (c *ItemCatalog) Version() string {
+	return c.version
+}
+
+// ParseCatalogItems reads items in either data/items file layout, mapping weapon and
+// armor fields onto structured properties the same way the shipped catalog does
+func ParseCatalogItems(data []byte) ([]*CatalogItem, error) {
+	// Flat list: value is expressed in gold pieces
+	var list []rawCatalogItem
+	if err := json.Unmarshal(data, &list); err == nil {
+		items := make([]*CatalogItem, 0, len(list))
+		for i := range list {
+			items = append(items, convertRawCatalogItem(&list[i], 100))
+		}
+		return items, nil
+	}
+
+	// Wrapped list: value is expressed in copper pieces
+	var wrapped struct {
+		Items []rawCatalogItem `json:"items"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return nil, err
+	}
+	items := make([]*CatalogItem, 0, len(wrapped.Items))
+	for i := range wrapped.Items {
+		items = append(items, convertRawCatalogItem(&wrapped.Items[i], 1))
+	}
+	return items, nil
+}
+
+func (c *ItemCatalog) loadItems(data []byte) error {
+	items, err := ParseCatalogItems(data)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		c.add(item)
+	}
+	return nil
+}
+
+func (c *ItemCatalog) loadPacks(data []byte) error {
+	var packs []*EquipmentPack
+	if err := json.Unmarshal(data, &packs); err != nil {
+		return err
+	}
+	for _, pack := range packs {
+		c.packs[normalizeCatalogName(pack.Name)] = pack
+		c.packs[pack.ID] = pack
+	}
+	return nil
+}
+
+func (c *ItemCatalog) add(item *CatalogItem) {
+	// The first definition of an ID wins; later files only add aliases
+	if _, exists := c.items[item.ID]; exists {
+		return
+	}
+	c.items[item.ID] = item
+	c.byName[normalizeCatalogName(item.Name)] = item
+	c.byName[strings.ReplaceAll(item.ID, "_", " ")] = item
+	for _, alias := range item.Aliases {
+		c.byName[normalizeCatalogName(alias)] = item
+	}
+}
+
+// Get returns the catalog item with the given ID
+func (c *ItemCatalog) Get(id string) *CatalogItem {
+	return c.items[id]
+}
+
+// Find looks an item up by ID, name or alias, tolerating simple plurals
+func (c *ItemCatalog) Find(name string) *CatalogItem {
+	key := normalizeCatalogName(name)
+	if item, ok := c.items[key]; ok {
+		return item
+	}
+	if item, ok := c.byName[key]; ok {
+		return item
+	}
+	for _, suffix := range []string{"es", "s"} {
+		if strings.HasSuffix(key, suffix) {
+			if item, ok := c.byName[strings.TrimSuffix(key, suffix)]; ok {
+				return item
+			}
+		}
+	}
+	return nil
+}
+
+// FindPack looks an equipment pack up by ID or name
+func (c *ItemCatalog) FindPack(name string) *EquipmentPack {
+	return c.packs[normalizeCatalogName(name)]
+}
+
+// Items returns all catalog items sorted by ID
+func (c *ItemCatalog) Items() []*CatalogItem {
+	items := make([]*CatalogItem, 0, len(c.items))
+	for _, item := range c.items {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items
+}
+
+func normalizeCatalogName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func catalogSlug(name string) string {
+	return strings.Trim(catalogSlugPattern.ReplaceAllString(strings.ToLower(name), "_"), "_")
+}
+
+func convertRawCatalogItem(raw *rawCatalogItem, valueMultiplier float64) *CatalogItem {
+	item := &CatalogItem{
+		ID:                     raw.ID,
+		Name:                   raw.Name,
+		Type:                   models.ItemType(raw.Type),
+		Rarity:                 models.ItemRarity(raw.Rarity),
+		Weight:                 raw.Weight,
+		Value:                  int(raw.Value*valueMultiplier + 0.5),
+		RequiresAttunement:     raw.RequiresAttunement,
+		AttunementRequirements: raw.AttunementRequirements,
+		Description:            raw.Description,
+		Tags:                   raw.Tags,
+		Aliases:                raw.Aliases,
+		Properties:             make(models.ItemProperties),
+	}
+	if item.ID == "" {
+		item.ID = catalogSlug(raw.Name)
+	}
+	if item.Type == "" {
+		item.Type = models.ItemTypeOther
+	}
+	if item.Rarity == "" {
+		item.Rarity = models.ItemRarityCommon
+	}
+
+	// Wrapped files already use the structured property map
+	if len(raw.Properties) > 0 {
+		_ = json.Unmarshal(raw.Properties, &item.Properties)
+	}
+	if item.HasTag("container") {
+		item.Properties["container"] = true
+	}
+
+	switch item.Type {
+	case models.ItemTypeWeapon:
+		applyWeaponProperties(item, raw)
+	case models.ItemTypeArmor:
+		applyArmorProperties(item, raw)
+	}
+
+	return item
+}
+
+// applyWeaponProperties maps the flat weapon fields (damage, damageType and
+// the PHB property list) onto the snake_case keys the inventory service uses.
+func applyWeaponProperties(item *CatalogItem, raw *rawCatalogItem) {
+	if raw.Damage != "" {
+		item.Properties["damage"] = raw.Damage
+	}
+	if raw.DamageType != "" {
+		item.Properties["damage_type"] = raw.DamageType
+	}
+	if raw.WeaponType != "" {
+		item.Properties["weapon_type"] = raw.WeaponType
+	}
+
+	var list []string
+	if err := json.Unmarshal(raw.Properties, &list); err != nil {
+		return
+	}
+	// Structured keys replace the raw list
+	delete(item.Properties, "")
+	melee := true
+	for _, prop := range list {
+		matches := weaponPropertyPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(prop)))
+		if matches == nil {
+			continue
+		}
+		key := strings.ReplaceAll(matches[1], "-", "_")
+		detail := matches[2]
+		switch key {
+		case "versatile":
+			item.Properties["versatile"] = detail
+		case "ammunition", "thrown":
+			item.Properties[key] = true
+			if r := weaponPropertyRangeText.FindStringSubmatch(detail); r != nil {
+				item.Properties["range"] = r[1]
+			}
+			if key == "ammunition" {
+				melee = false
+			}
+		default:
+			item.Properties[key] = true
+		}
+	}
+	item.Properties["melee"] = melee
+}
+
+func applyArmorProperties(item *CatalogItem, raw *rawCatalogItem) {
+	if raw.ArmorClass > 0 {
+		item.Properties["ac"] = raw.ArmorClass
+	}
+	if raw.ArmorType != "" {
+		item.Properties["armor_type"] = raw.ArmorType
+	}
+	if raw.DexModifier != nil {
+		item.Properties["dex_modifier"] = *raw.DexModifier
+	}
+	if raw.MaxDexBonus != nil {
+		item.Properties["max_dex_bonus"] = *raw.MaxDexBonus
+	}
+	if raw.StrengthRequirement > 0 {
+		item.Properties["strength_requirement"] = raw.StrengthRequirement
+	}
+	if raw.ArmorType != "" && raw.ArmorType != "shield" {
+		item.Properties["stealth_disadvantage"] = raw.StealthDisadvantage
+	}
+}
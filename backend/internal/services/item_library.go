@@ -0,0 +1,173 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/database"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+const errMsgHomebrewItemNotFound = "homebrew item not found"
+
+// ItemLibraryService keeps the items table in step with data/items and manages the homebrew
+// items a DM writes for one game session, which only that session can see
+type ItemLibraryService struct {
+	libraryRepo database.ItemLibraryRepository
+	catalog     *ItemCatalog
+}
+
+// NewItemLibraryService creates a new item library service. catalog may be nil when
+// data/items failed to load; homebrew items still work without it.
+func NewItemLibraryService(libraryRepo database.ItemLibraryRepository, catalog *ItemCatalog) *ItemLibraryService {
+	return &ItemLibraryService{
+		libraryRepo: libraryRepo,
+		catalog:     catalog,
+	}
+}
+
+// SyncCatalog upserts every catalog item into the items table. It does nothing when the
+// database already holds this version of data/items, unless force is set.
+func (s *ItemLibraryService) SyncCatalog(ctx context.Context, force bool) (*models.CatalogSync, error) {
+	if s.catalog == nil {
+		return nil, fmt.Errorf("item catalog is not loaded")
+	}
+
+	catalogItems := s.catalog.Items()
+	sync := &models.CatalogSync{Version: s.catalog.Version(), ItemCount: len(catalogItems)}
+	if !force {
+		current, err := s.libraryRepo.LatestCatalogVersion(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if current == sync.Version {
+			sync.Skipped = true
+			return sync, nil
+		}
+	}
+
+	items := make([]*models.Item, 0, len(catalogItems))
+	for _, catalogItem := range catalogItems {
+		items = append(items, catalogItem.ToItem())
+	}
+	if err := s.libraryRepo.SyncCatalog(ctx, sync.Version, items); err != nil {
+		return nil, err
+	}
+	sync.SyncedAt = time.Now()
+	return sync, nil
+}
+
+// ListItems returns the shared items, plus the session's homebrew when filter.SessionID is set
+func (s *ItemLibraryService) ListItems(ctx context.Context, filter models.ItemFilter) ([]*models.Item, error) {
+	return s.libraryRepo.ListItems(ctx, filter)
+}
+
+// CreateHomebrewItems adds items to a game session from JSON in any data/items layout: a single
+// item or flat list priced in gp, or an {"items": [...]} file priced in cp
+func (s *ItemLibraryService) CreateHomebrewItems(ctx context.Context, sessionID string, data []byte) ([]*models.Item, error) {
+	catalogItems, err := parseHomebrewItems(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(catalogItems) == 0 {
+		return nil, fmt.Errorf("no items given")
+	}
+
+	items := make([]*models.Item, 0, len(catalogItems))
+	for _, catalogItem := range catalogItems {
+		item, err := homebrewItem(catalogItem, sessionID)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := s.libraryRepo.CreateHomebrewItems(ctx, items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// UpdateHomebrewItem replaces one of the session's homebrew items with a single item in data/items format
+func (s *ItemLibraryService) UpdateHomebrewItem(ctx context.Context, sessionID, itemID string, data []byte) (*models.Item, error) {
+	existing, err := s.getHomebrewItem(ctx, sessionID, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	catalogItems, err := parseHomebrewItems(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(catalogItems) != 1 {
+		return nil, fmt.Errorf("give exactly one item")
+	}
+	item, err := homebrewItem(catalogItems[0], sessionID)
+	if err != nil {
+		return nil, err
+	}
+	item.ID = existing.ID
+	item.CreatedAt = existing.CreatedAt
+	if err := s.libraryRepo.UpdateHomebrewItem(ctx, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// DeleteHomebrewItem removes one of the session's homebrew items
+func (s *ItemLibraryService) DeleteHomebrewItem(ctx context.Context, sessionID, itemID string) error {
+	if _, err := s.getHomebrewItem(ctx, sessionID, itemID); err != nil {
+		return err
+	}
+	return s.libraryRepo.DeleteHomebrewItem(ctx, sessionID, itemID)
+}
+
+func (s *ItemLibraryService) getHomebrewItem(ctx context.Context, sessionID, itemID string) (*models.Item, error) {
+	item, err := s.libraryRepo.GetItem(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil || item.Source != models.ItemSourceHomebrew || item.SessionID == nil || *item.SessionID != sessionID {
+		return nil, fmt.Errorf(errMsgHomebrewItemNotFound)
+	}
+	return item, nil
+}
+
+// parseHomebrewItems accepts a single item object as well as the two item file layouts
+func parseHomebrewItems(data []byte) ([]*CatalogItem, error) {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &fields); err != nil {
+			return nil, fmt.Errorf("invalid item JSON: %w", err)
+		}
+		if _, wrapped := fields["items"]; !wrapped {
+			trimmed = append(append([]byte("["), trimmed...), ']')
+		}
+	}
+
+	items, err := ParseCatalogItems(trimmed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid item JSON: %w", err)
+	}
+	return items, nil
+}
+
+// homebrewItem turns a parsed item into a session's homebrew. Homebrew gets its own ID so it
+// can never replace a catalog item.
+func homebrewItem(catalogItem *CatalogItem, sessionID string) (*models.Item, error) {
+	if catalogItem.Name == "" {
+		return nil, fmt.Errorf("every item needs a name")
+	}
+	if catalogItem.Value < 0 || catalogItem.Weight < 0 {
+		return nil, fmt.Errorf("%s cannot have a negative value or weight", catalogItem.Name)
+	}
+
+	item := catalogItem.ToItem()
+	item.ID = ""
+	item.Source = models.ItemSourceHomebrew
+	item.SessionID = &sessionID
+	return item, nil
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+// MockItemLibraryRepository mocks catalog sync and homebrew item storage
+type MockItemLibraryRepository struct {
+	mock.Mock
+}
+
+func (m *MockItemLibraryRepository) LatestCatalogVersion(ctx context.Context) (string, error) {
+	args := m.Called(ctx)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockItemLibraryRepository) SyncCatalog(ctx context.Context, version string, items []*models.Item) error {
+	args := m.Called(ctx, version, items)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockItemLibraryRepository) ListItems(ctx context.Context, filter models.ItemFilter) ([]*models.Item, error) {
+	args := m.Called(ctx, filter)
+	return mockSliceReturn[models.Item](args, 0, 1)
+}
+
+func (m *MockItemLibraryRepository) GetItem(ctx context.Context, id string) (*models.Item, error) {
+	args := m.Called(ctx, id)
+	return mockSingleReturn[models.Item](args, 0, 1)
+}
+
+func (m *MockItemLibraryRepository) CreateHomebrewItems(ctx context.Context, items []*models.Item) error {
+	args := m.Called(ctx, items)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockItemLibraryRepository) UpdateHomebrewItem(ctx context.Context, item *models.Item) error {
+	args := m.Called(ctx, item)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockItemLibraryRepository) DeleteHomebrewItem(ctx context.Context, sessionID, id string) error {
+	args := m.Called(ctx, sessionID, id)
+	return mockErrorReturn(args, 0)
+}
+
+func createTestItemLibraryService(catalog *ItemCatalog, repo *MockItemLibraryRepository) *ItemLibraryService {
+	return NewItemLibraryService(repo, catalog)
+}
+
+func TestItemCatalog_Version(t *testing.T) {
+	first, err := NewItemCatalog("../../../data")
+	require.NoError(t, err)
+	second, err := NewItemCatalog("../../../data")
+	require.NoError(t, err)
+
+	assert.Len(t, first.Version(), 16)
+	assert.Equal(t, first.Version(), second.Version(), "the same data gives the same version")
+}
+
+func TestItemLibraryService_SyncCatalog(t *testing.T) {
+	catalog, err := NewItemCatalog("../../../data")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		force      bool
+		setupMocks func(*MockItemLibraryRepository)
+		validate   func(*testing.T, *models.CatalogSync)
+	}{
+		{
+			name: "Version already in the database",
+			setupMocks: func(repo *MockItemLibraryRepository) {
+				repo.On("LatestCatalogVersion", mock.Anything).Return(catalog.Version(), nil)
+			},
+			validate: func(t *testing.T, sync *models.CatalogSync) {
+				assert.True(t, sync.Skipped)
+			},
+		},
+		{
+			name: "Upserts every catalog item with structured properties",
+			setupMocks: func(repo *MockItemLibraryRepository) {
+				repo.On("LatestCatalogVersion", mock.Anything).Return("older", nil)
+				repo.On("SyncCatalog", mock.Anything, catalog.Version(), mock.MatchedBy(func(items []*models.Item) bool {
+					for _, item := range items {
+						if item.ID == "longsword" {
+							return item.Properties["damage"] == "1d8" && item.Properties["damage_type"] == "slashing"
+						}
+					}
+					return false
+				})).Return(nil)
+			},
+			validate: func(t *testing.T, sync *models.CatalogSync) {
+				assert.False(t, sync.Skipped)
+				assert.Equal(t, len(catalog.Items()), sync.ItemCount)
+			},
+		},
+		{
+			name:  "Force syncs without checking the version",
+			force: true,
+			setupMocks: func(repo *MockItemLibraryRepository) {
+				repo.On("SyncCatalog", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockItemLibraryRepository)
+			tt.setupMocks(repo)
+
+			service := createTestItemLibraryService(catalog, repo)
+			sync, err := service.SyncCatalog(context.Background(), tt.force)
+
+			require.NoError(t, err)
+			if tt.validate != nil {
+				tt.validate(t, sync)
+			}
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestItemLibraryService_CreateHomebrewItems(t *testing.T) {
+	catalog, err := NewItemCatalog("../../../data")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		body        string
+		setupMocks  func(*MockItemLibraryRepository)
+		expectError string
+		validate    func(*testing.T, []*models.Item)
+	}{
+		{
+			name: "Maps a single flat item onto structured properties",
+			body: `{"name": "Sunforged Plate", "type": "armor", "armorType": "heavy", "armorClass": 18,
+				"stealthDisadvantage": true, "value": 1500, "weight": 65}`,
+			setupMocks: func(repo *MockItemLibraryRepository) {
+				repo.On("CreateHomebrewItems", mock.Anything, mock.AnythingOfType("[]*models.Item")).Return(nil)
+			},
+			validate: func(t *testing.T, items []*models.Item) {
+				require.Len(t, items, 1)
+				item := items[0]
+				assert.Empty(t, item.ID, "the repository assigns homebrew IDs")
+				assert.Equal(t, models.ItemSourceHomebrew, item.Source)
+				assert.Equal(t, "session-1", *item.SessionID)
+				assert.Equal(t, 150000, item.Value, "flat items are priced in gp")
+				assert.Equal(t, 18, item.Properties["ac"])
+				assert.Equal(t, true, item.Properties["stealth_disadvantage"])
+			},
+		},
+		{
+			name: "Accepts a wrapped data file priced in copper",
+			body: `{"items": [{"id": "moon_draught", "name": "Moon Draught", "type": "consumable", "value": 250},
+				{"name": "Gloamleaf", "value": 5}]}`,
+			setupMocks: func(repo *MockItemLibraryRepository) {
+				repo.On("CreateHomebrewItems", mock.Anything, mock.AnythingOfType("[]*models.Item")).Return(nil)
+			},
+			validate: func(t *testing.T, items []*models.Item) {
+				require.Len(t, items, 2)
+				assert.Equal(t, 250, items[0].Value)
+				assert.Empty(t, items[0].ID, "homebrew never reuses a catalog-style ID")
+			},
+		},
+		{
+			name:        "Nameless items",
+			body:        `[{"value": 5}]`,
+			expectError: "every item needs a name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockItemLibraryRepository)
+			if tt.setupMocks != nil {
+				tt.setupMocks(repo)
+			}
+
+			service := createTestItemLibraryService(catalog, repo)
+			items, err := service.CreateHomebrewItems(context.Background(), "session-1", []byte(tt.body))
+
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+			} else {
+				require.NoError(t, err)
+				tt.validate(t, items)
+			}
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestItemLibraryService_DeleteHomebrewItem(t *testing.T) {
+	catalog, err := NewItemCatalog("../../../data")
+	require.NoError(t, err)
+	repo := new(MockItemLibraryRepository)
+	other := "session-2"
+	repo.On("GetItem", mock.Anything, "item-1").Return(&models.Item{ID: "item-1", Source: models.ItemSourceHomebrew, SessionID: &other}, nil)
+
+	service := createTestItemLibraryService(catalog, repo)
+	err = service.DeleteHomebrewItem(context.Background(), "session-1", "item-1")
+
+	assert.EqualError(t, err, "homebrew item not found", "another session's homebrew cannot be changed")
+	repo.AssertExpectations(t)
+}
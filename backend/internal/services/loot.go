@@ -0,0 +1,559 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/auth"
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/database"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/dice"
+	"github.com/ctclostio/DnD-Game/backend/pkg/logger"
+)
+
+const errMsgLootPoolNotFound = "loot pool not found"
+
+// LootService holds the treasure from fights and encounter objectives in loot pools until the
+// party divides it, then writes every share to the characters in one transaction and awards
+// their experience through the character service so they level up
+type LootService struct {
+	lootRepo      database.LootPoolRepository
+	inventory     *InventoryService
+	inventoryRepo database.InventoryRepository
+	sessionRepo   database.GameSessionRepository
+	diceRoller    *dice.Roller
+	items         *ItemCatalog
+	events        *GameEventService
+	characters    *CharacterService
+}
+
+// NewLootService creates a new loot service
+func NewLootService(lootRepo database.LootPoolRepository, inventory *InventoryService, inventoryRepo database.InventoryRepository, sessionRepo database.GameSessionRepository) *LootService {
+	return &LootService{
+		lootRepo:      lootRepo,
+		inventory:     inventory,
+		inventoryRepo: inventoryRepo,
+		sessionRepo:   sessionRepo,
+		diceRoller:    dice.NewRoller(),
+	}
+}
+
+// SetItemCatalog lets loot name catalog items and turn rolled rarities into real magic items
+func (s *LootService) SetItemCatalog(items *ItemCatalog) {
+	s.items = items
+}
+
+// SetEventLog sets the session event log each character's share of loot and experience
+// is recorded in
+func (s *LootService) SetEventLog(events *GameEventService) {
+	s.events = events
+}
+
+// SetCharacterService sets the character service experience from distributed pools is
+// awarded through, so characters level up and the award is versioned
+func (s *LootService) SetCharacterService(characters *CharacterService) {
+	s.characters = characters
+}
+
+// CreatePool puts treasure up for the characters of a game session
+func (s *LootService) CreatePool(ctx context.Context, sessionID string, req *models.CreateLootPoolRequest) (*models.LootPool, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	characterIDs, err := s.sessionCharacters(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.CharacterIDs) > 0 {
+		joined := make(map[string]bool, len(characterIDs))
+		for _, id := range characterIDs {
+			joined[id] = true
+		}
+		for _, id := range req.CharacterIDs {
+			if !joined[id] {
+				return nil, fmt.Errorf("character %s is not part of this game session", id)
+			}
+		}
+		characterIDs = req.CharacterIDs
+	}
+
+	pool := &models.LootPool{
+		SessionID:    sessionID,
+		SourceType:   req.SourceType,
+		SourceID:     req.SourceID,
+		Description:  req.Description,
+		Mode:         req.Mode,
+		Coins:        req.Coins,
+		Experience:   req.Experience,
+		CharacterIDs: characterIDs,
+	}
+	for _, loot := range req.Items {
+		item, err := s.resolveItem(loot.ItemID, sessionID)
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			return nil, fmt.Errorf("%s: %s", errMsgItemNotFound, loot.ItemID)
+		}
+		pool.Items = append(pool.Items, &models.LootPoolItem{ItemID: item.ID, Name: item.Name, Rarity: item.Rarity, Quantity: loot.Quantity})
+	}
+	return s.createPool(ctx, pool)
+}
+
+// PoolFromAutoResolution turns the loot and experience of an auto-resolved fight into a loot pool
+// for the characters who fought it. A fight the party lost or fled yields nothing.
+func (s *LootService) PoolFromAutoResolution(ctx context.Context, resolution *models.AutoCombatResolution, characters []*models.Character) (*models.LootPool, error) {
+	if resolution.Outcome == constants.OutcomeDefeat || resolution.Outcome == constants.OutcomeRetreat {
+		return nil, nil
+	}
+
+	var generated []map[string]interface{}
+	if len(resolution.LootGenerated) > 0 {
+		if err := json.Unmarshal(resolution.LootGenerated, &generated); err != nil {
+			return nil, fmt.Errorf("failed to decode generated loot: %w", err)
+		}
+	}
+
+	pool := &models.LootPool{
+		SessionID:   resolution.GameSessionID.String(),
+		SourceType:  models.LootSourceAutoResolution,
+		SourceID:    resolution.ID.String(),
+		Description: fmt.Sprintf("Spoils of a %s %s", resolution.EncounterDifficulty, strings.ReplaceAll(resolution.Outcome, "_", " ")),
+		Experience:  resolution.ExperienceAwarded,
+	}
+	for _, character := range characters {
+		pool.CharacterIDs = append(pool.CharacterIDs, character.ID)
+	}
+	for _, loot := range generated {
+		switch loot["type"] {
+		case "currency":
+			amount, _ := loot["amount"].(float64)
+			currency, _ := loot["currency"].(string)
+			pool.Coins = addCoins(pool.Coins, coinsOf(currency, int(amount)))
+		case "item":
+			name, _ := loot["name"].(string)
+			rarity, _ := loot["rarity"].(string)
+			item, err := s.randomMagicItem(strings.TrimPrefix(name, "Random "), models.ItemRarity(rarity))
+			if err != nil {
+				return nil, err
+			}
+			if item != nil {
+				pool.Items = append(pool.Items, &models.LootPoolItem{ItemID: item.ID, Name: item.Name, Rarity: item.Rarity, Quantity: 1})
+			}
+		}
+	}
+	if pool.Coins.IsZero() && pool.Experience == 0 && len(pool.Items) == 0 {
+		return nil, nil
+	}
+	return s.createPool(ctx, pool)
+}
+
+// PoolFromObjective turns the rewards of a completed encounter objective into a loot pool for the
+// session's characters. Item rewards that name nothing in the item table or catalog are left out.
+func (s *LootService) PoolFromObjective(ctx context.Context, objective *models.EncounterObjective, sessionID string) (*models.LootPool, error) {
+	characterIDs, err := s.sessionCharacters(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := &models.LootPool{
+		SessionID:    sessionID,
+		SourceType:   models.LootSourceObjective,
+		SourceID:     objective.ID,
+		Description:  fmt.Sprintf("Objective completed: %s", objective.Description),
+		Coins:        models.Coins{Gold: objective.GoldReward},
+		Experience:   objective.XPReward,
+		CharacterIDs: characterIDs,
+	}
+	for _, reward := range objective.ItemRewards {
+		item, err := s.resolveItem(reward.ItemID, sessionID)
+		if err == nil && item == nil {
+			item, err = s.resolveItem(reward.ItemName, sessionID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			continue
+		}
+		quantity := max(reward.Quantity, 1)
+		pool.Items = append(pool.Items, &models.LootPoolItem{ItemID: item.ID, Name: item.Name, Rarity: item.Rarity, Quantity: quantity})
+	}
+	if pool.Coins.IsZero() && pool.Experience == 0 && len(pool.Items) == 0 {
+		return nil, nil
+	}
+	return s.createPool(ctx, pool)
+}
+
+// GetPool returns a loot pool with its items and rolls
+func (s *LootService) GetPool(ctx context.Context, poolID string) (*models.LootPool, error) {
+	pool, err := s.lootRepo.GetPool(ctx, poolID)
+	if err != nil {
+		return nil, err
+	}
+	if pool == nil {
+		return nil, fmt.Errorf(errMsgLootPoolNotFound)
+	}
+	return pool, nil
+}
+
+// ListPools returns a session's loot pools, optionally only those in one status
+func (s *LootService) ListPools(ctx context.Context, sessionID string, status models.LootPoolStatus) ([]*models.LootPool, error) {
+	return s.lootRepo.ListPools(ctx, sessionID, status)
+}
+
+// AssignItem is the DM handing a pool item to a character, whatever the distribution mode
+func (s *LootService) AssignItem(ctx context.Context, poolID string, req *models.LootPickRequest) (*models.LootPool, error) {
+	if _, _, err := s.openPoolItem(ctx, poolID, req); err != nil {
+		return nil, err
+	}
+	if err := s.lootRepo.AssignItem(ctx, req.PoolItemID, req.CharacterID); err != nil {
+		return nil, err
+	}
+	return s.GetPool(ctx, poolID)
+}
+
+// ClaimItem takes an unclaimed pool item for a character in a claim pool
+func (s *LootService) ClaimItem(ctx context.Context, poolID string, req *models.LootPickRequest) (*models.LootPool, error) {
+	pool, _, err := s.openPoolItem(ctx, poolID, req)
+	if err != nil {
+		return nil, err
+	}
+	if pool.Mode != models.LootModeClaim {
+		return nil, fmt.Errorf("items in this loot pool cannot be claimed")
+	}
+	if err := s.lootRepo.ClaimItem(ctx, req.PoolItemID, req.CharacterID); err != nil {
+		return nil, err
+	}
+	return s.GetPool(ctx, poolID)
+}
+
+// RollForItem records a character's need, greed or pass on an item in a need/greed pool.
+// Need and greed roll a d20; the item is settled when the pool is distributed.
+func (s *LootService) RollForItem(ctx context.Context, poolID string, req *models.LootPickRequest) (*models.LootRoll, error) {
+	pool, _, err := s.openPoolItem(ctx, poolID, req)
+	if err != nil {
+		return nil, err
+	}
+	if pool.Mode != models.LootModeNeedGreed {
+		return nil, fmt.Errorf("items in this loot pool are not rolled for")
+	}
+
+	roll := &models.LootRoll{PoolItemID: req.PoolItemID, CharacterID: req.CharacterID, Choice: req.Choice}
+	switch req.Choice {
+	case models.LootRollNeed, models.LootRollGreed:
+		result, err := s.diceRoller.Roll("1d20")
+		if err != nil {
+			return nil, fmt.Errorf("failed to roll for loot: %w", err)
+		}
+		roll.Roll = result.Total
+	case models.LootRollPass:
+	default:
+		return nil, fmt.Errorf("choose need, greed or pass")
+	}
+
+	if err := s.lootRepo.RecordRoll(ctx, roll); err != nil {
+		return nil, err
+	}
+	return roll, nil
+}
+
+// Distribute hands out an open loot pool. Items nobody has yet are settled by the pool's mode:
+// round robin deals them out in turn and need/greed goes to the best roll, while DM and claim
+// pools must have every item assigned first. Coins are split evenly with change made down to
+// the copper and land in the same transaction as the items. Experience is split evenly and
+// awarded once the transaction commits.
+func (s *LootService) Distribute(ctx context.Context, poolID string) (*models.LootDistribution, error) {
+	pool, err := s.GetPool(ctx, poolID)
+	if err != nil {
+		return nil, err
+	}
+	if pool.Status != models.LootPoolStatusOpen {
+		return nil, fmt.Errorf("loot pool has already been distributed")
+	}
+	if len(pool.CharacterIDs) == 0 {
+		return nil, fmt.Errorf("loot pool has no characters to share it")
+	}
+	if err := settleUnassignedItems(pool); err != nil {
+		return nil, err
+	}
+
+	shares := make(map[string]*models.LootShare, len(pool.CharacterIDs))
+	distribution := &models.LootDistribution{Pool: pool}
+	coins := models.SplitCoins(pool.Coins, len(pool.CharacterIDs))
+	experience := models.SplitEvenly(pool.Experience, len(pool.CharacterIDs))
+	for i, characterID := range pool.CharacterIDs {
+		share := &models.LootShare{CharacterID: characterID, Coins: coins[i], Experience: experience[i]}
+		shares[characterID] = share
+		distribution.Shares = append(distribution.Shares, share)
+	}
+	for _, item := range pool.Items {
+		share := shares[*item.AssignedTo]
+		share.Items = append(share.Items, models.LootItem{ItemID: item.ItemID, Quantity: item.Quantity})
+	}
+
+	description := pool.Description
+	if description == "" {
+		description = "loot"
+	}
+	txn := &models.EconomyTransaction{
+		Type:        models.LedgerEntryLoot,
+		Description: description,
+		SessionID:   pool.SessionID,
+		LootPool:    pool,
+	}
+	txn.CreatedBy, _ = auth.GetUserIDFromContext(ctx)
+	for _, share := range distribution.Shares {
+		if !share.Coins.IsZero() {
+			txn.Currency = append(txn.Currency, models.CurrencyMovement{CharacterID: share.CharacterID, Coins: share.Coins})
+		}
+		for _, loot := range share.Items {
+			txn.Items = append(txn.Items, models.ItemMovement{CharacterID: share.CharacterID, ItemID: loot.ItemID, Quantity: loot.Quantity})
+		}
+	}
+
+	entries, err := s.inventory.applyTransaction(ctx, txn)
+	if err != nil {
+		return nil, err
+	}
+	pool.Status = models.LootPoolStatusDistributed
+	pool.TransactionID = &txn.ID
+	distribution.Ledger = entries
+	s.awardExperience(ctx, distribution)
+	s.recordDistribution(ctx, txn.CreatedBy, distribution)
+	return distribution, nil
+}
+
+// awardExperience gives each character their share of the pool's experience. The loot has
+// already been handed out, so an award that fails is logged for the DM to grant by hand.
+func (s *LootService) awardExperience(ctx context.Context, distribution *models.LootDistribution) {
+	if s.characters == nil {
+		return
+	}
+	for _, share := range distribution.Shares {
+		if share.Experience <= 0 {
+			continue
+		}
+		if err := s.characters.AddExperience(ctx, share.CharacterID, share.Experience); err != nil {
+			logger.WithContext(ctx).WithError(err).Error().
+				Str("pool_id", distribution.Pool.ID).
+				Str("character_id", share.CharacterID).
+				Int("experience", share.Experience).
+				Msg("Failed to award loot pool experience")
+		}
+	}
+}
+
+// recordDistribution writes each character's share to the session's event log: a loot
+// event for their coins and items and an experience event for their experience
+func (s *LootService) recordDistribution(ctx context.Context, userID string, distribution *models.LootDistribution) {
+	pool := distribution.Pool
+	names := make(map[string][]string, len(pool.CharacterIDs))
+	for _, item := range pool.Items {
+		names[*item.AssignedTo] = append(names[*item.AssignedTo], item.Name)
+	}
+
+	for _, share := range distribution.Shares {
+		characterID := share.CharacterID
+		if !share.Coins.IsZero() || len(share.Items) > 0 {
+			recordGameEvent(ctx, s.events, &models.GameEvent{
+				SessionID:   pool.SessionID,
+				Type:        models.GameEventLoot,
+				PlayerID:    userID,
+				CharacterID: &characterID,
+				Data: map[string]interface{}{
+					"poolId":      pool.ID,
+					"description": pool.Description,
+					"coins":       share.Coins,
+					"items":       names[characterID],
+				},
+			})
+		}
+		if share.Experience > 0 {
+			recordGameEvent(ctx, s.events, &models.GameEvent{
+				SessionID:   pool.SessionID,
+				Type:        models.GameEventExperience,
+				PlayerID:    userID,
+				CharacterID: &characterID,
+				Data: map[string]interface{}{
+					"poolId":     pool.ID,
+					"experience": share.Experience,
+				},
+			})
+		}
+	}
+}
+
+// settleUnassignedItems gives every item nobody holds yet to a character according to the pool's mode
+func settleUnassignedItems(pool *models.LootPool) error {
+	next := 0
+	for _, item := range pool.Items {
+		if item.AssignedTo != nil {
+			next++
+		}
+	}
+	for _, item := range pool.Items {
+		if item.AssignedTo != nil {
+			continue
+		}
+		switch pool.Mode {
+		case models.LootModeRoundRobin:
+			characterID := pool.CharacterIDs[next%len(pool.CharacterIDs)]
+			item.AssignedTo = &characterID
+			next++
+		case models.LootModeNeedGreed:
+			winner := item.RollWinner()
+			if winner == "" {
+				return fmt.Errorf("nobody rolled need or greed for %s; the DM must assign it", item.Name)
+			}
+			item.AssignedTo = &winner
+		default:
+			return fmt.Errorf("%s has not been assigned", item.Name)
+		}
+	}
+	for _, item := range pool.Items {
+		if !pool.HasCharacter(*item.AssignedTo) {
+			return fmt.Errorf("%s is assigned to a character outside the loot pool", item.Name)
+		}
+	}
+	return nil
+}
+
+// openPoolItem checks a pick names an item of an open pool and a character sharing in it
+func (s *LootService) openPoolItem(ctx context.Context, poolID string, req *models.LootPickRequest) (*models.LootPool, *models.LootPoolItem, error) {
+	pool, err := s.GetPool(ctx, poolID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pool.Status != models.LootPoolStatusOpen {
+		return nil, nil, fmt.Errorf("loot pool has already been distributed")
+	}
+	item := pool.Item(req.PoolItemID)
+	if item == nil {
+		return nil, nil, fmt.Errorf("item is not in this loot pool")
+	}
+	if !pool.HasCharacter(req.CharacterID) {
+		return nil, nil, fmt.Errorf("character does not share in this loot pool")
+	}
+	return pool, item, nil
+}
+
+func (s *LootService) createPool(ctx context.Context, pool *models.LootPool) (*models.LootPool, error) {
+	pool.CreatedBy, _ = auth.GetUserIDFromContext(ctx)
+	if err := s.lootRepo.CreatePool(ctx, pool); err != nil {
+		return nil, err
+	}
+	return pool, nil
+}
+
+// sessionCharacters lists the characters playing in a game session
+func (s *LootService) sessionCharacters(ctx context.Context, sessionID string) ([]string, error) {
+	participants, err := s.sessionRepo.GetParticipants(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	characterIDs := make([]string, 0, len(participants))
+	for _, p := range participants {
+		if p.CharacterID != nil && *p.CharacterID != "" {
+			characterIDs = append(characterIDs, *p.CharacterID)
+		}
+	}
+	return characterIDs, nil
+}
+
+// resolveItem finds a shared item or one of the session's homebrew items by ID in the item
+// table, or a catalog item by ID or name
+func (s *LootService) resolveItem(idOrName, sessionID string) (*models.Item, error) {
+	if idOrName == "" {
+		return nil, nil
+	}
+	item, err := s.inventoryRepo.GetSessionItem(idOrName, sessionID)
+	if err != nil || item != nil {
+		return item, err
+	}
+	if s.items == nil {
+		return nil, nil
+	}
+	catalogItem := s.items.Get(idOrName)
+	if catalogItem == nil {
+		catalogItem = s.items.Find(idOrName)
+	}
+	if catalogItem == nil {
+		return nil, nil
+	}
+	return ensureCatalogItem(s.inventoryRepo, catalogItem)
+}
+
+// randomMagicItem picks a catalog item of the rolled rarity, preferring the rolled kind
+// (potion, scroll, weapon, armor or trinket). It returns nil without an item catalog.
+func (s *LootService) randomMagicItem(kind string, rarity models.ItemRarity) (*models.Item, error) {
+	if s.items == nil {
+		return nil, nil
+	}
+
+	var ofRarity, ofKind []*CatalogItem
+	for _, item := range s.items.Items() {
+		if item.Rarity != rarity {
+			continue
+		}
+		ofRarity = append(ofRarity, item)
+		if lootKindMatches(item, kind) {
+			ofKind = append(ofKind, item)
+		}
+	}
+	candidates := ofKind
+	if len(candidates) == 0 {
+		candidates = ofRarity
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	return ensureCatalogItem(s.inventoryRepo, candidates[rand.Intn(len(candidates))])
+}
+
+func lootKindMatches(item *CatalogItem, kind string) bool {
+	name := strings.ToLower(item.Name)
+	switch kind {
+	case "potion":
+		return strings.Contains(name, "potion")
+	case "scroll":
+		return strings.Contains(name, "scroll")
+	case "weapon":
+		return item.Type == models.ItemTypeWeapon
+	case "armor":
+		return item.Type == models.ItemTypeArmor
+	case "trinket":
+		return item.Type == models.ItemTypeMagic || item.Type == models.ItemTypeOther
+	}
+	return false
+}
+
+// coinsOf converts an amount of one denomination, named by its abbreviation, to coins
+func coinsOf(currency string, amount int) models.Coins {
+	switch currency {
+	case "cp":
+		return models.Coins{Copper: amount}
+	case "sp":
+		return models.Coins{Silver: amount}
+	case "ep":
+		return models.Coins{Electrum: amount}
+	case "pp":
+		return models.Coins{Platinum: amount}
+	}
+	return models.Coins{Gold: amount}
+}
+
+func addCoins(a, b models.Coins) models.Coins {
+	return models.Coins{
+		Copper:   a.Copper + b.Copper,
+		Silver:   a.Silver + b.Silver,
+		Electrum: a.Electrum + b.Electrum,
+		Gold:     a.Gold + b.Gold,
+		Platinum: a.Platinum + b.Platinum,
+	}
+}
@@ -0,0 +1,334 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/internal/services/mocks"
+)
+
+// MockLootPoolRepository mocks loot pool storage
+type MockLootPoolRepository struct {
+	mock.Mock
+}
+
+func (m *MockLootPoolRepository) CreatePool(ctx context.Context, pool *models.LootPool) error {
+	args := m.Called(ctx, pool)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockLootPoolRepository) GetPool(ctx context.Context, id string) (*models.LootPool, error) {
+	args := m.Called(ctx, id)
+	return mockSingleReturn[models.LootPool](args, 0, 1)
+}
+
+func (m *MockLootPoolRepository) ListPools(ctx context.Context, sessionID string, status models.LootPoolStatus) ([]*models.LootPool, error) {
+	args := m.Called(ctx, sessionID, status)
+	return mockSliceReturn[models.LootPool](args, 0, 1)
+}
+
+func (m *MockLootPoolRepository) AssignItem(ctx context.Context, poolItemID, characterID string) error {
+	args := m.Called(ctx, poolItemID, characterID)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockLootPoolRepository) ClaimItem(ctx context.Context, poolItemID, characterID string) error {
+	args := m.Called(ctx, poolItemID, characterID)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockLootPoolRepository) RecordRoll(ctx context.Context, roll *models.LootRoll) error {
+	args := m.Called(ctx, roll)
+	return mockErrorReturn(args, 0)
+}
+
+func createTestLootService(t *testing.T, lootRepo *MockLootPoolRepository, inventoryRepo *mocks.MockInventoryRepository, sessionRepo *mocks.MockGameSessionRepository, charRepo *mocks.MockCharacterRepository) *LootService {
+	items, err := NewItemCatalog("../../../data")
+	require.NoError(t, err)
+
+	inventory := NewInventoryService(inventoryRepo, new(mocks.MockCharacterRepository))
+	service := NewLootService(lootRepo, inventory, inventoryRepo, sessionRepo)
+	service.SetItemCatalog(items)
+	service.SetCharacterService(NewCharacterService(charRepo, nil, nil))
+	return service
+}
+
+// openPool is an open pool shared by two characters, holding the given items
+func openPool(mode models.LootDistributionMode, items ...*models.LootPoolItem) *models.LootPool {
+	return &models.LootPool{
+		ID:           "pool-1",
+		SessionID:    "session-1",
+		Mode:         mode,
+		Status:       models.LootPoolStatusOpen,
+		CharacterIDs: []string{"char-1", "char-2"},
+		Items:        items,
+	}
+}
+
+func poolItem(id, itemID string) *models.LootPoolItem {
+	return &models.LootPoolItem{ID: id, ItemID: itemID, Name: itemID, Quantity: 1}
+}
+
+func TestSplitCoins(t *testing.T) {
+	shares := models.SplitCoins(models.Coins{Platinum: 1, Gold: 1}, 3)
+
+	require.Len(t, shares, 3)
+	assert.Equal(t, models.Coins{Gold: 3, Silver: 6, Copper: 7}, shares[0])
+	assert.Equal(t, models.Coins{Gold: 3, Silver: 6, Copper: 7}, shares[1])
+	assert.Equal(t, models.Coins{Gold: 3, Silver: 6, Copper: 6}, shares[2])
+	total := 0
+	for _, share := range shares {
+		total += share.TotalInCopper()
+	}
+	assert.Equal(t, 1100, total, "change making never loses a coin")
+}
+
+func TestLootService_Distribute(t *testing.T) {
+	roundRobin := openPool(models.LootModeRoundRobin, poolItem("a", "dagger"), poolItem("b", "rope"), poolItem("c", "torch"))
+	roundRobin.Coins = models.Coins{Gold: 5}
+	roundRobin.Experience = 301
+	fighter := &models.Character{ID: "char-1", Level: 1, ExperiencePoints: 100, Class: "fighter"}
+	wizard := &models.Character{ID: "char-2", Level: 1, Class: "wizard"}
+	wand := poolItem("a", "wand_of_magic_missiles")
+	wand.Rolls = []models.LootRoll{
+		{CharacterID: "char-1", Choice: models.LootRollGreed, Roll: 20},
+		{CharacterID: "char-2", Choice: models.LootRollNeed, Roll: 3},
+	}
+	distributed := openPool(models.LootModeDM)
+	distributed.Status = models.LootPoolStatusDistributed
+
+	tests := []struct {
+		name        string
+		pool        *models.LootPool
+		setupMocks  func(*mocks.MockInventoryRepository, *mocks.MockCharacterRepository)
+		expectError string
+		validate    func(*testing.T, *models.LootDistribution)
+	}{
+		{
+			name: "Round robin deals items in turn with coins in one transaction, then awards XP",
+			pool: roundRobin,
+			setupMocks: func(inventoryRepo *mocks.MockInventoryRepository, charRepo *mocks.MockCharacterRepository) {
+				inventoryRepo.On("ApplyTransaction", mock.MatchedBy(func(txn *models.EconomyTransaction) bool {
+					return txn.Type == models.LedgerEntryLoot &&
+						txn.LootPool == roundRobin &&
+						len(txn.Currency) == 2 && txn.Currency[0].Coins == models.Coins{Gold: 2, Silver: 5} &&
+						len(txn.Items) == 3
+				})).Return([]*models.LedgerEntry{{CharacterID: "char-1"}, {CharacterID: "char-2"}}, nil)
+				charRepo.On("GetByID", mock.Anything, "char-1").Return(fighter, nil)
+				charRepo.On("GetByID", mock.Anything, "char-2").Return(wizard, nil)
+				charRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+			},
+			validate: func(t *testing.T, distribution *models.LootDistribution) {
+				items := distribution.Pool.Items
+				assert.Equal(t, "char-1", *items[0].AssignedTo)
+				assert.Equal(t, "char-2", *items[1].AssignedTo)
+				assert.Equal(t, "char-1", *items[2].AssignedTo)
+				assert.Len(t, distribution.Shares[0].Items, 2)
+				assert.Equal(t, models.LootPoolStatusDistributed, distribution.Pool.Status)
+				assert.Equal(t, 251, fighter.ExperiencePoints)
+				assert.Equal(t, 150, wizard.ExperiencePoints)
+			},
+		},
+		{
+			name: "Need beats a higher greed roll",
+			pool: openPool(models.LootModeNeedGreed, wand),
+			setupMocks: func(inventoryRepo *mocks.MockInventoryRepository, _ *mocks.MockCharacterRepository) {
+				inventoryRepo.On("ApplyTransaction", mock.MatchedBy(func(txn *models.EconomyTransaction) bool {
+					return len(txn.Items) == 1 && txn.Items[0].CharacterID == "char-2"
+				})).Return([]*models.LedgerEntry{{CharacterID: "char-2"}}, nil)
+			},
+		},
+		{
+			name:        "DM pools need every item assigned",
+			pool:        openPool(models.LootModeDM, poolItem("a", "dagger")),
+			expectError: "dagger has not been assigned",
+		},
+		{
+			name:        "Pool already handed out",
+			pool:        distributed,
+			expectError: "loot pool has already been distributed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lootRepo := new(MockLootPoolRepository)
+			inventoryRepo := new(mocks.MockInventoryRepository)
+			charRepo := new(mocks.MockCharacterRepository)
+			lootRepo.On("GetPool", mock.Anything, "pool-1").Return(tt.pool, nil)
+			if tt.setupMocks != nil {
+				tt.setupMocks(inventoryRepo, charRepo)
+			}
+
+			service := createTestLootService(t, lootRepo, inventoryRepo, new(mocks.MockGameSessionRepository), charRepo)
+			distribution, err := service.Distribute(context.Background(), "pool-1")
+
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+			} else {
+				require.NoError(t, err)
+				if tt.validate != nil {
+					tt.validate(t, distribution)
+				}
+			}
+
+			inventoryRepo.AssertExpectations(t)
+			charRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLootService_ClaimItem(t *testing.T) {
+	lootRepo := new(MockLootPoolRepository)
+	lootRepo.On("GetPool", mock.Anything, "pool-1").Return(openPool(models.LootModeDM, poolItem("a", "dagger")), nil)
+
+	service := createTestLootService(t, lootRepo, new(mocks.MockInventoryRepository), new(mocks.MockGameSessionRepository), new(mocks.MockCharacterRepository))
+	_, err := service.ClaimItem(context.Background(), "pool-1", &models.LootPickRequest{PoolItemID: "a", CharacterID: "char-1"})
+
+	assert.EqualError(t, err, "items in this loot pool cannot be claimed")
+	lootRepo.AssertExpectations(t)
+}
+
+func TestLootService_RollForItem(t *testing.T) {
+	tests := []struct {
+		name        string
+		characterID string
+		setupMocks  func(*MockLootPoolRepository)
+		expectError string
+	}{
+		{
+			name:        "Need rolls a d20",
+			characterID: "char-1",
+			setupMocks: func(lootRepo *MockLootPoolRepository) {
+				lootRepo.On("RecordRoll", mock.Anything, mock.AnythingOfType("*models.LootRoll")).Return(nil)
+			},
+		},
+		{
+			name:        "Characters outside the pool",
+			characterID: "char-9",
+			expectError: "character does not share in this loot pool",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lootRepo := new(MockLootPoolRepository)
+			lootRepo.On("GetPool", mock.Anything, "pool-1").Return(openPool(models.LootModeNeedGreed, poolItem("a", "dagger")), nil)
+			if tt.setupMocks != nil {
+				tt.setupMocks(lootRepo)
+			}
+
+			service := createTestLootService(t, lootRepo, new(mocks.MockInventoryRepository), new(mocks.MockGameSessionRepository), new(mocks.MockCharacterRepository))
+			roll, err := service.RollForItem(context.Background(), "pool-1", &models.LootPickRequest{PoolItemID: "a", CharacterID: tt.characterID, Choice: models.LootRollNeed})
+
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+			} else {
+				require.NoError(t, err)
+				assert.GreaterOrEqual(t, roll.Roll, 1)
+				assert.LessOrEqual(t, roll.Roll, 20)
+			}
+
+			lootRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLootService_PoolFromAutoResolution(t *testing.T) {
+	characters := []*models.Character{{ID: "char-1"}, {ID: "char-2"}}
+	loot, err := json.Marshal([]map[string]interface{}{
+		{"type": "currency", "currency": "gp", "amount": 120},
+		{"type": "item", "name": "Random potion", "rarity": "common"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		resolution *models.AutoCombatResolution
+		setupMocks func(*MockLootPoolRepository, *mocks.MockInventoryRepository)
+		validate   func(*testing.T, *models.LootPool)
+	}{
+		{
+			name: "Victory pools the coins, a real item and the XP",
+			resolution: &models.AutoCombatResolution{
+				ID:                  uuid.New(),
+				GameSessionID:       uuid.New(),
+				EncounterDifficulty: "easy",
+				Outcome:             constants.OutcomeVictory,
+				LootGenerated:       loot,
+				ExperienceAwarded:   450,
+			},
+			setupMocks: func(lootRepo *MockLootPoolRepository, inventoryRepo *mocks.MockInventoryRepository) {
+				inventoryRepo.On("GetItem", mock.Anything).Return(&models.Item{ID: "potion"}, nil)
+				lootRepo.On("CreatePool", mock.Anything, mock.AnythingOfType("*models.LootPool")).Return(nil)
+			},
+			validate: func(t *testing.T, pool *models.LootPool) {
+				require.NotNil(t, pool)
+				assert.Equal(t, models.LootSourceAutoResolution, pool.SourceType)
+				assert.Equal(t, models.Coins{Gold: 120}, pool.Coins)
+				assert.Equal(t, 450, pool.Experience)
+				assert.Equal(t, []string{"char-1", "char-2"}, pool.CharacterIDs)
+				assert.Len(t, pool.Items, 1)
+			},
+		},
+		{
+			name:       "A defeat yields nothing",
+			resolution: &models.AutoCombatResolution{Outcome: constants.OutcomeDefeat, LootGenerated: loot},
+			validate: func(t *testing.T, pool *models.LootPool) {
+				assert.Nil(t, pool)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lootRepo := new(MockLootPoolRepository)
+			inventoryRepo := new(mocks.MockInventoryRepository)
+			if tt.setupMocks != nil {
+				tt.setupMocks(lootRepo, inventoryRepo)
+			}
+
+			service := createTestLootService(t, lootRepo, inventoryRepo, new(mocks.MockGameSessionRepository), new(mocks.MockCharacterRepository))
+			pool, err := service.PoolFromAutoResolution(context.Background(), tt.resolution, characters)
+
+			require.NoError(t, err)
+			tt.validate(t, pool)
+			lootRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLootService_PoolFromObjective(t *testing.T) {
+	lootRepo := new(MockLootPoolRepository)
+	inventoryRepo := new(mocks.MockInventoryRepository)
+	sessionRepo := new(mocks.MockGameSessionRepository)
+	character := "char-1"
+	sessionRepo.On("GetParticipants", mock.Anything, "session-1").Return([]*models.GameParticipant{{CharacterID: &character}, {}}, nil)
+	inventoryRepo.On("GetSessionItem", mock.Anything, "session-1").Return(nil, nil)
+	lootRepo.On("CreatePool", mock.Anything, mock.AnythingOfType("*models.LootPool")).Return(nil)
+	objective := &models.EncounterObjective{
+		ID:          "objective-1",
+		Description: "Rescue the miller",
+		XPReward:    200,
+		GoldReward:  50,
+		ItemRewards: []models.ItemReward{{ItemName: "No Such Relic", Quantity: 1}},
+	}
+
+	service := createTestLootService(t, lootRepo, inventoryRepo, sessionRepo, new(mocks.MockCharacterRepository))
+	pool, err := service.PoolFromObjective(context.Background(), objective, "session-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, models.LootSourceObjective, pool.SourceType)
+	assert.Equal(t, []string{"char-1"}, pool.CharacterIDs)
+	assert.Equal(t, models.Coins{Gold: 50}, pool.Coins)
+	assert.Equal(t, 200, pool.Experience)
+	assert.Empty(t, pool.Items, "rewards naming unknown items are left out")
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+// removeCurseSpell is the spell that ends a curse's hold on a character
+const removeCurseSpell = "Remove Curse"
+
+// UseItemCharges spends an item's charges, casting one of its spells when req names one.
+// Spending extra charges on a spell that allows it raises the spell's level.
+func (s *InventoryService) UseItemCharges(ctx context.Context, characterID, itemID string, req *models.UseChargesRequest) (*models.ChargeUse, error) {
+	inventory, err := s.inventoryRepo.GetCharacterInventory(characterID)
+	if err != nil {
+		return nil, err
+	}
+	target := s.findItemInInventory(inventory, itemID)
+	if target == nil {
+		return nil, fmt.Errorf("item not found in inventory")
+	}
+	charges := target.Item.Charges()
+	if charges == nil {
+		return nil, fmt.Errorf("%s has no charges", target.Item.Name)
+	}
+	if target.Location != "" && target.Location != models.ItemLocationCarried {
+		return nil, fmt.Errorf("%s is not being carried", target.Item.Name)
+	}
+	if target.Item.RequiresAttunement && !target.Attuned {
+		return nil, fmt.Errorf("you must be attuned to %s to use it", target.Item.Name)
+	}
+
+	use := &models.ChargeUse{ItemID: itemID, ChargesSpent: max(1, req.Charges)}
+	if req.Spell != "" {
+		spell := target.Item.Spell(req.Spell)
+		if spell == nil {
+			return nil, fmt.Errorf("%s cannot cast %s", target.Item.Name, req.Spell)
+		}
+		use.Spell = spell
+		use.ChargesSpent = max(spell.Charges, req.Charges)
+		use.CastLevel = spell.Level
+		if extra := use.ChargesSpent - spell.Charges; extra > 0 {
+			if !spell.Upcast {
+				return nil, fmt.Errorf("%s always costs %d charges", spell.Name, spell.Charges)
+			}
+			use.CastLevel += extra
+		}
+	}
+
+	remaining := target.ChargesRemaining()
+	if remaining < use.ChargesSpent {
+		return nil, fmt.Errorf("%s has only %d charges left", target.Item.Name, remaining)
+	}
+	use.ChargesRemaining = remaining - use.ChargesSpent
+
+	if use.ChargesRemaining == 0 && charges.DestroyOnEmpty {
+		roll, err := s.roller.Roll("1d20")
+		if err != nil {
+			return nil, err
+		}
+		use.DestroyRoll = roll.Total
+		use.Destroyed = roll.Total == 1
+	}
+
+	if use.Destroyed {
+		err = s.inventoryRepo.RemoveItemFromInventory(characterID, itemID, 1)
+	} else {
+		err = s.inventoryRepo.SetItemCharges(characterID, itemID, use.ChargesRemaining)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reason := fmt.Sprintf("spent %d charges of %s", use.ChargesSpent, target.Item.Name)
+	if use.Destroyed {
+		reason += ", destroying it"
+	}
+	s.recordInventoryChange(ctx, characterID, reason)
+	return use, nil
+}
+
+// RechargeItems restores charges to the character's items that recharge under one of
+// the given rules, rolling recharge dice where the item has them. It returns the charges
+// regained by item ID.
+func (s *InventoryService) RechargeItems(characterID string, rules ...models.ResourceRecovery) (map[string]int, error) {
+	inventory, err := s.inventoryRepo.GetCharacterInventory(characterID)
+	if err != nil {
+		return nil, err
+	}
+
+	regained := make(map[string]int)
+	for _, inv := range inventory {
+		if inv.Item == nil || inv.Charges == nil {
+			continue // never spent, so still full
+		}
+		charges := inv.Item.Charges()
+		if charges == nil || *inv.Charges >= charges.Max || !rechargesOn(charges.Recharge, rules) {
+			continue
+		}
+
+		amount := charges.Max
+		if charges.RechargeDice != "" {
+			roll, err := s.roller.Roll(charges.RechargeDice)
+			if err != nil {
+				return nil, fmt.Errorf("invalid recharge dice for %s: %w", inv.Item.Name, err)
+			}
+			amount = roll.Total
+		}
+		restored := min(charges.Max, *inv.Charges+max(0, amount))
+		if restored == *inv.Charges {
+			continue
+		}
+		if err := s.inventoryRepo.SetItemCharges(characterID, inv.ItemID, restored); err != nil {
+			return nil, err
+		}
+		regained[inv.ItemID] = restored - *inv.Charges
+	}
+	return regained, nil
+}
+
+// RemoveCurse lifts the curse binding the character to an item. As with the Remove Curse
+// spell, the item itself stays cursed: attunement ends and the item comes off, so it can be
+// discarded, but attuning to or wearing it again lets the curse take hold once more. Lifting
+// it by spell casts Remove Curse, spending the caster's spell slot.
+func (s *InventoryService) RemoveCurse(ctx context.Context, characterID, itemID string, req *models.RemoveCurseRequest) error {
+	if req.Method != models.RemoveCurseByDM && req.Method != models.RemoveCurseBySpell {
+		return fmt.Errorf("unknown way to remove a curse: %s", req.Method)
+	}
+
+	inventory, err := s.inventoryRepo.GetCharacterInventory(characterID)
+	if err != nil {
+		return err
+	}
+	target := s.findItemInInventory(inventory, itemID)
+	if target == nil {
+		return fmt.Errorf("item not found in inventory")
+	}
+	if !target.CurseActive {
+		return fmt.Errorf("%s has no curse on its owner", target.Item.Name)
+	}
+
+	by := "the DM"
+	if req.Method == models.RemoveCurseBySpell {
+		if s.spells == nil {
+			return fmt.Errorf("spellcasting is not available")
+		}
+		casterID := req.CasterID
+		if casterID == "" {
+			casterID = characterID
+		}
+		if _, err := s.spells.CastSpell(ctx, casterID, &models.CastSpellRequest{Spell: removeCurseSpell, SlotLevel: req.SlotLevel}); err != nil {
+			return err
+		}
+		by = "remove curse"
+	}
+
+	if err := s.inventoryRepo.SetItemCurse(characterID, itemID, false); err != nil {
+		return err
+	}
+	if target.Attuned {
+		if err := s.inventoryRepo.UnattuneItem(characterID, itemID); err != nil {
+			return err
+		}
+	}
+	if target.Equipped {
+		if err := s.inventoryRepo.EquipItem(characterID, itemID, false); err != nil {
+			return err
+		}
+	}
+
+	s.recordInventoryChange(ctx, characterID, fmt.Sprintf("curse of %s lifted by %s", target.Item.Name, by))
+	return nil
+}
+
+// GetItemEffects returns the character's statistics with their active magic items' bonuses applied
+func (s *InventoryService) GetItemEffects(ctx context.Context, characterID string) (*models.ItemEffects, error) {
+	character, err := s.characterRepo.GetByID(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+	if character == nil {
+		return nil, fmt.Errorf("character not found")
+	}
+	inventory, err := s.inventoryRepo.GetCharacterInventory(characterID)
+	if err != nil {
+		return nil, err
+	}
+	return models.ApplyItemBonuses(character, inventory), nil
+}
+
+func rechargesOn(recharge models.ResourceRecovery, rules []models.ResourceRecovery) bool {
+	for _, rule := range rules {
+		if recharge == rule {
+			return true
+		}
+	}
+	return false
+}
+
+func cursedItemError(inv *models.InventoryItem) error {
+	if curse := inv.Item.Curse(); curse != "" {
+		return fmt.Errorf("%s is cursed (%s); the curse must be removed first", inv.Item.Name, curse)
+	}
+	return fmt.Errorf("%s is cursed; the curse must be removed first", inv.Item.Name)
+}
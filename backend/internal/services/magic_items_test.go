@@ -0,0 +1,204 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/internal/services"
+	"github.com/ctclostio/DnD-Game/backend/internal/services/mocks"
+)
+
+func chargedItem(recharge string) *models.Item {
+	return &models.Item{
+		ID:   constants.TestItemID,
+		Name: "Wand of Magic Missiles",
+		Type: models.ItemTypeMagic,
+		Properties: models.ItemProperties{
+			"charges":  float64(7),
+			"recharge": recharge,
+			"spells": []interface{}{
+				map[string]interface{}{"name": "magic missile", "level": float64(1), "charges": float64(1), "upcast": true},
+			},
+		},
+	}
+}
+
+func cursedItem() *models.Item {
+	return &models.Item{
+		ID:                 constants.TestItemID,
+		Name:               "Berserker Axe",
+		Type:               models.ItemTypeWeapon,
+		RequiresAttunement: true,
+		Properties:         models.ItemProperties{"cursed": true, "magic_bonus": float64(1)},
+	}
+}
+
+func TestInventoryService_UseItemCharges(t *testing.T) {
+	t.Run("spends extra charges to upcast a spell", func(t *testing.T) {
+		mockInvRepo := new(mocks.MockInventoryRepository)
+		service := services.NewInventoryService(mockInvRepo, nil)
+		wand := mocks.CreateTestInventoryItem(constants.TestCharacterID, constants.TestItemID, 1, false, false, chargedItem("dawn"))
+		mockInvRepo.On(testMethodGetCharacterInventory, constants.TestCharacterID).Return([]*models.InventoryItem{wand}, nil)
+		mockInvRepo.On("SetItemCharges", constants.TestCharacterID, constants.TestItemID, 4).Return(nil)
+
+		use, err := service.UseItemCharges(context.Background(), constants.TestCharacterID, constants.TestItemID, &models.UseChargesRequest{Spell: "Magic Missile", Charges: 3})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, use.CastLevel)
+		assert.Equal(t, 4, use.ChargesRemaining)
+		mockInvRepo.AssertExpectations(t)
+	})
+
+	t.Run("refuses to spend more charges than are left", func(t *testing.T) {
+		mockInvRepo := new(mocks.MockInventoryRepository)
+		service := services.NewInventoryService(mockInvRepo, nil)
+		remaining := 1
+		wand := mocks.CreateTestInventoryItem(constants.TestCharacterID, constants.TestItemID, 1, false, false, chargedItem("dawn"))
+		wand.Charges = &remaining
+		mockInvRepo.On(testMethodGetCharacterInventory, constants.TestCharacterID).Return([]*models.InventoryItem{wand}, nil)
+
+		_, err := service.UseItemCharges(context.Background(), constants.TestCharacterID, constants.TestItemID, &models.UseChargesRequest{Charges: 2})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only 1 charges left")
+		mockInvRepo.AssertNotCalled(t, "SetItemCharges", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestInventoryService_RechargeItems(t *testing.T) {
+	mockInvRepo := new(mocks.MockInventoryRepository)
+	service := services.NewInventoryService(mockInvRepo, nil)
+
+	spent, other := 2, 0
+	staff := mocks.CreateTestInventoryItem(constants.TestCharacterID, constants.TestItemID, 1, false, false, chargedItem("long rest"))
+	staff.Charges = &spent
+	wand := mocks.CreateTestInventoryItem(constants.TestCharacterID, "wand", 1, false, false, chargedItem("1d6+1 at dawn"))
+	wand.Charges = &other
+	mockInvRepo.On(testMethodGetCharacterInventory, constants.TestCharacterID).Return([]*models.InventoryItem{staff, wand}, nil)
+	mockInvRepo.On("SetItemCharges", constants.TestCharacterID, constants.TestItemID, 7).Return(nil)
+
+	regained, err := service.RechargeItems(constants.TestCharacterID, models.RecoveryShortRest, models.RecoveryLongRest)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{constants.TestItemID: 5}, regained)
+	mockInvRepo.AssertNotCalled(t, "SetItemCharges", constants.TestCharacterID, "wand", mock.Anything)
+}
+
+func TestInventoryService_Curses(t *testing.T) {
+	t.Run("attuning to a cursed item binds the curse", func(t *testing.T) {
+		mockInvRepo := new(mocks.MockInventoryRepository)
+		service := services.NewInventoryService(mockInvRepo, nil)
+		axe := mocks.CreateTestInventoryItem(constants.TestCharacterID, constants.TestItemID, 1, false, false, cursedItem())
+		mockInvRepo.On(testMethodGetCharacterInventory, constants.TestCharacterID).Return([]*models.InventoryItem{axe}, nil)
+		mockInvRepo.On("AttuneItem", constants.TestCharacterID, constants.TestItemID).Return(nil)
+		mockInvRepo.On("SetItemCurse", constants.TestCharacterID, constants.TestItemID, true).Return(nil)
+
+		require.NoError(t, service.AttuneToItem(context.Background(), constants.TestCharacterID, constants.TestItemID))
+		mockInvRepo.AssertExpectations(t)
+	})
+
+	t.Run("an active curse blocks unattuning and unequipping", func(t *testing.T) {
+		mockInvRepo := new(mocks.MockInventoryRepository)
+		service := services.NewInventoryService(mockInvRepo, nil)
+		axe := mocks.CreateTestInventoryItem(constants.TestCharacterID, constants.TestItemID, 1, true, true, cursedItem())
+		axe.CurseActive = true
+		mockInvRepo.On(testMethodGetCharacterInventory, constants.TestCharacterID).Return([]*models.InventoryItem{axe}, nil)
+
+		err := service.UnattuneFromItem(context.Background(), constants.TestCharacterID, constants.TestItemID)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cursed")
+
+		err = service.UnequipItem(context.Background(), constants.TestCharacterID, constants.TestItemID)
+		require.Error(t, err)
+		mockInvRepo.AssertNotCalled(t, "UnattuneItem", mock.Anything, mock.Anything)
+		mockInvRepo.AssertNotCalled(t, testMethodEquipItem, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	newCurseService := func(t *testing.T, caster *models.Character) (*services.InventoryService, *mocks.MockInventoryRepository, *mocks.MockCharacterRepository) {
+		mockInvRepo := new(mocks.MockInventoryRepository)
+		mockCharRepo := new(mocks.MockCharacterRepository)
+		service := services.NewInventoryService(mockInvRepo, mockCharRepo)
+		catalog, err := services.NewSpellCatalog(testGameDataPath)
+		require.NoError(t, err)
+		service.SetSpellService(services.NewSpellManagementService(testGameDataPath, catalog, mockCharRepo, mockInvRepo))
+
+		axe := mocks.CreateTestInventoryItem(constants.TestCharacterID, constants.TestItemID, 1, true, true, cursedItem())
+		axe.CurseActive = true
+		mockInvRepo.On(testMethodGetCharacterInventory, constants.TestCharacterID).Return([]*models.InventoryItem{axe}, nil)
+		mockCharRepo.On(testMethodGetByID, mock.Anything, caster.ID).Return(caster, nil)
+		return service, mockInvRepo, mockCharRepo
+	}
+	cleric := func(known ...models.Spell) *models.Character {
+		return &models.Character{
+			ID: testSpellCharacterID, Name: "Tomas", Class: "cleric", Level: 5,
+			Spells: models.SpellData{SpellSlots: spellSlots(4, 3, 2), SpellsKnown: known},
+		}
+	}
+
+	t.Run("remove curse spends the caster's slot and frees the character from the item", func(t *testing.T) {
+		caster := cleric(models.Spell{ID: "remove_curse", Name: "Remove Curse", Level: 3, Prepared: true})
+		service, mockInvRepo, mockCharRepo := newCurseService(t, caster)
+		mockCharRepo.On("Update", mock.Anything, caster).Return(nil)
+		mockInvRepo.On("SetItemCurse", constants.TestCharacterID, constants.TestItemID, false).Return(nil)
+		mockInvRepo.On("UnattuneItem", constants.TestCharacterID, constants.TestItemID).Return(nil)
+		mockInvRepo.On(testMethodEquipItem, constants.TestCharacterID, constants.TestItemID, false).Return(nil)
+
+		err := service.RemoveCurse(context.Background(), constants.TestCharacterID, constants.TestItemID,
+			&models.RemoveCurseRequest{Method: models.RemoveCurseBySpell, CasterID: caster.ID})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, caster.Spells.SpellSlots[2].Remaining)
+		mockInvRepo.AssertExpectations(t)
+		mockCharRepo.AssertExpectations(t)
+	})
+
+	t.Run("a caster who cannot cast remove curse leaves the curse in place", func(t *testing.T) {
+		service, mockInvRepo, _ := newCurseService(t, cleric())
+
+		err := service.RemoveCurse(context.Background(), constants.TestCharacterID, constants.TestItemID,
+			&models.RemoveCurseRequest{Method: models.RemoveCurseBySpell, CasterID: testSpellCharacterID})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not know Remove Curse")
+		mockInvRepo.AssertNotCalled(t, "SetItemCurse", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestApplyItemBonuses(t *testing.T) {
+	char := &models.Character{
+		ArmorClass: 15,
+		Speed:      30,
+		Attributes: models.Attributes{Strength: 10},
+	}
+	ring := mocks.CreateTestInventoryItem(constants.TestCharacterID, "ring", 1, false, true, &models.Item{
+		Name:               "Ring of Protection",
+		Type:               models.ItemTypeMagic,
+		RequiresAttunement: true,
+		Properties:         models.ItemProperties{"ac_bonus": float64(1), "saving_throw_bonus": float64(1)},
+	})
+	gauntlets := mocks.CreateTestInventoryItem(constants.TestCharacterID, "gauntlets", 1, true, false, &models.Item{
+		Name:               "Gauntlets of Ogre Power",
+		Type:               models.ItemTypeMagic,
+		RequiresAttunement: true,
+		Properties:         models.ItemProperties{"ability_scores": map[string]interface{}{"strength": float64(19)}},
+	})
+
+	effects := models.ApplyItemBonuses(char, []*models.InventoryItem{ring, gauntlets})
+
+	assert.Equal(t, 16, effects.ArmorClass)
+	assert.Equal(t, 10, effects.Attributes.Strength, "gauntlets need attunement before they work")
+	assert.Equal(t, 15, char.ArmorClass, "the stored character is unchanged")
+	assert.Len(t, effects.Sources, 1)
+
+	combatant := models.Combatant{AC: 15, AttackBonus: 5}
+	effects.ApplyToCombatant(&combatant)
+	assert.Equal(t, 16, combatant.AC)
+	assert.Equal(t, 5, combatant.AttackBonus)
+	assert.Equal(t, 1, combatant.SavingThrows["dexterity"], "the ring adds to every save")
+}
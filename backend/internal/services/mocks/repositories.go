@@ -204,6 +204,11 @@ func (m *MockGameSessionRepository) AddParticipant(ctx context.Context, sessionI
 	return handleErrorReturn(args, 0)
 }
 
+func (m *MockGameSessionRepository) AddSpectator(ctx context.Context, sessionID, userID string) error {
+	args := m.Called(ctx, sessionID, userID)
+	return handleErrorReturn(args, 0)
+}
+
 func (m *MockGameSessionRepository) RemoveParticipant(ctx context.Context, sessionID, userID string) error {
 	args := m.Called(ctx, sessionID, userID)
 	return handleErrorReturn(args, 0)
@@ -234,8 +239,18 @@ func (m *MockInventoryRepository) GetItem(itemID string) (*models.Item, error) {
 	return handleSingleReturn[models.Item](args, 0, 1)
 }
 
-func (m *MockInventoryRepository) GetItemsByType(itemType models.ItemType) ([]*models.Item, error) {
-	args := m.Called(itemType)
+func (m *MockInventoryRepository) GetSessionItem(itemID, sessionID string) (*models.Item, error) {
+	args := m.Called(itemID, sessionID)
+	return handleSingleReturn[models.Item](args, 0, 1)
+}
+
+func (m *MockInventoryRepository) GetCharacterItem(itemID, characterID string) (*models.Item, error) {
+	args := m.Called(itemID, characterID)
+	return handleSingleReturn[models.Item](args, 0, 1)
+}
+
+func (m *MockInventoryRepository) GetItemsByType(itemType models.ItemType, sessionID string) ([]*models.Item, error) {
+	args := m.Called(itemType, sessionID)
 	return handleSliceReturn[models.Item](args, 0, 1)
 }
 
@@ -284,11 +299,41 @@ func (m *MockInventoryRepository) UpdateCharacterCurrency(currency *models.Curre
 	return handleErrorReturn(args, 0)
 }
 
+func (m *MockInventoryRepository) MoveInventoryItem(characterID string, move *models.InventoryMove) error {
+	args := m.Called(characterID, move)
+	return handleErrorReturn(args, 0)
+}
+
+func (m *MockInventoryRepository) SetItemCharges(characterID, itemID string, charges int) error {
+	args := m.Called(characterID, itemID, charges)
+	return handleErrorReturn(args, 0)
+}
+
+func (m *MockInventoryRepository) SetItemCurse(characterID, itemID string, active bool) error {
+	args := m.Called(characterID, itemID, active)
+	return handleErrorReturn(args, 0)
+}
+
 func (m *MockInventoryRepository) GetCharacterWeight(characterID string) (*models.InventoryWeight, error) {
 	args := m.Called(characterID)
 	return handleSingleReturn[models.InventoryWeight](args, 0, 1)
 }
 
+func (m *MockInventoryRepository) GetStartingEquipmentGrant(characterID string) (*models.StartingEquipmentGrant, error) {
+	args := m.Called(characterID)
+	return handleSingleReturn[models.StartingEquipmentGrant](args, 0, 1)
+}
+
+func (m *MockInventoryRepository) ApplyTransaction(txn *models.EconomyTransaction) ([]*models.LedgerEntry, error) {
+	args := m.Called(txn)
+	return handleSliceReturn[models.LedgerEntry](args, 0, 1)
+}
+
+func (m *MockInventoryRepository) GetLedgerEntries(filter models.LedgerFilter) ([]*models.LedgerEntry, error) {
+	args := m.Called(filter)
+	return handleSliceReturn[models.LedgerEntry](args, 0, 1)
+}
+
 // MockRefreshTokenRepository is a mock implementation of database.RefreshTokenRepository
 type MockRefreshTokenRepository struct {
 	mock.Mock
@@ -796,3 +841,98 @@ func (m *MockDMAssistantRepository) GetNarrationsByType(ctx context.Context, ses
 	args := m.Called(ctx, sessionID, narrationType)
 	return handleSliceReturn[models.AINarration](args, 0, 1)
 }
+
+// MockCharacterResourceRepository is a mock implementation of database.CharacterResourceRepository
+type MockCharacterResourceRepository struct {
+	mock.Mock
+}
+
+func (m *MockCharacterResourceRepository) GetResources(ctx context.Context, characterID string) ([]*models.CharacterResource, error) {
+	args := m.Called(ctx, characterID)
+	return handleSliceReturn[models.CharacterResource](args, 0, 1)
+}
+
+func (m *MockCharacterResourceRepository) GetResource(ctx context.Context, characterID, key string) (*models.CharacterResource, error) {
+	args := m.Called(ctx, characterID, key)
+	return handleSingleReturn[models.CharacterResource](args, 0, 1)
+}
+
+func (m *MockCharacterResourceRepository) UpsertResource(ctx context.Context, resource *models.CharacterResource) error {
+	args := m.Called(ctx, resource)
+	return handleErrorReturn(args, 0)
+}
+
+func (m *MockCharacterResourceRepository) SpendResource(ctx context.Context, characterID, key string, amount int) (bool, error) {
+	args := m.Called(ctx, characterID, key, amount)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCharacterResourceRepository) RegainResource(ctx context.Context, characterID, key string, amount int) error {
+	args := m.Called(ctx, characterID, key, amount)
+	return handleErrorReturn(args, 0)
+}
+
+func (m *MockCharacterResourceRepository) DeleteResource(ctx context.Context, characterID, key string) error {
+	args := m.Called(ctx, characterID, key)
+	return handleErrorReturn(args, 0)
+}
+
+func (m *MockCharacterResourceRepository) GetRestState(ctx context.Context, characterID string) (*models.CharacterRestState, error) {
+	args := m.Called(ctx, characterID)
+	return handleSingleReturn[models.CharacterRestState](args, 0, 1)
+}
+
+func (m *MockCharacterResourceRepository) UpsertRestState(ctx context.Context, state *models.CharacterRestState) error {
+	args := m.Called(ctx, state)
+	return handleErrorReturn(args, 0)
+}
+
+// MockCharacterVersionRepository is a mock implementation of database.CharacterVersionRepository
+type MockCharacterVersionRepository struct {
+	mock.Mock
+}
+
+func (m *MockCharacterVersionRepository) CreateVersion(ctx context.Context, version *models.CharacterVersion) error {
+	args := m.Called(ctx, version)
+	return handleErrorReturn(args, 0)
+}
+
+func (m *MockCharacterVersionRepository) GetVersions(ctx context.Context, characterID string) ([]*models.CharacterVersion, error) {
+	args := m.Called(ctx, characterID)
+	return handleSliceReturn[models.CharacterVersion](args, 0, 1)
+}
+
+func (m *MockCharacterVersionRepository) GetVersion(ctx context.Context, characterID string, version int) (*models.CharacterVersion, error) {
+	args := m.Called(ctx, characterID, version)
+	return handleSingleReturn[models.CharacterVersion](args, 0, 1)
+}
+
+func (m *MockCharacterVersionRepository) GetVersionAt(ctx context.Context, characterID string, at time.Time) (*models.CharacterVersion, error) {
+	args := m.Called(ctx, characterID, at)
+	return handleSingleReturn[models.CharacterVersion](args, 0, 1)
+}
+
+func (m *MockCharacterVersionRepository) GetLatestVersion(ctx context.Context, characterID string) (*models.CharacterVersion, error) {
+	args := m.Called(ctx, characterID)
+	return handleSingleReturn[models.CharacterVersion](args, 0, 1)
+}
+
+func (m *MockCharacterVersionRepository) GetVersionsSince(ctx context.Context, characterID string, since time.Time) ([]*models.CharacterVersion, error) {
+	args := m.Called(ctx, characterID, since)
+	return handleSliceReturn[models.CharacterVersion](args, 0, 1)
+}
+
+// MockGameEventRepository is a mock implementation of GameEventRepository
+type MockGameEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockGameEventRepository) Append(ctx context.Context, event *models.GameEvent) error {
+	args := m.Called(ctx, event)
+	return handleErrorReturn(args, 0)
+}
+
+func (m *MockGameEventRepository) List(ctx context.Context, filter models.GameEventFilter) ([]*models.GameEvent, error) {
+	args := m.Called(ctx, filter)
+	return handleSliceReturn[models.GameEvent](args, 0, 1)
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/auth"
+	"github.com/ctclostio/DnD-Game/backend/internal/database"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+// PartyService manages each game session's party, with its shared stash and coin purse,
+// and the trade offers characters make each other
+type PartyService struct {
+	partyRepo     database.PartyRepository
+	inventory     *InventoryService
+	inventoryRepo database.InventoryRepository
+}
+
+// NewPartyService creates a new party service
+func NewPartyService(partyRepo database.PartyRepository, inventory *InventoryService, inventoryRepo database.InventoryRepository) *PartyService {
+	return &PartyService{
+		partyRepo:     partyRepo,
+		inventory:     inventory,
+		inventoryRepo: inventoryRepo,
+	}
+}
+
+// GetParty returns the session's party, creating it the first time it is needed
+func (s *PartyService) GetParty(ctx context.Context, sessionID string) (*models.Party, error) {
+	party, err := s.partyRepo.GetPartyBySession(ctx, sessionID)
+	if err != nil || party != nil {
+		return party, err
+	}
+
+	if err := s.partyRepo.CreateParty(ctx, &models.Party{SessionID: sessionID, Name: "The Party"}); err != nil {
+		return nil, err
+	}
+	// Read back rather than trusting our insert, which loses to a concurrent one
+	party, err = s.partyRepo.GetPartyBySession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if party == nil {
+		return nil, fmt.Errorf("failed to create party for session %s", sessionID)
+	}
+	return party, nil
+}
+
+// GetStash returns the party's shared items and coins
+func (s *PartyService) GetStash(ctx context.Context, sessionID string) (*models.PartyStash, error) {
+	party, err := s.GetParty(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.partyRepo.GetPartyStash(ctx, party.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range items {
+		item, err := s.inventoryRepo.GetItem(entry.ItemID)
+		if err != nil || item == nil {
+			continue
+		}
+		entry.Item = item
+	}
+
+	coins, err := s.partyRepo.GetPartyCurrency(ctx, party.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PartyStash{Party: party, Coins: coins, Items: items}, nil
+}
+
+// DepositToStash moves a character's coins and items into the party stash
+func (s *PartyService) DepositToStash(ctx context.Context, sessionID string, req *models.StashRequest) ([]*models.LedgerEntry, error) {
+	return s.moveStash(ctx, sessionID, req, true)
+}
+
+// WithdrawFromStash moves coins and items from the party stash to a character
+func (s *PartyService) WithdrawFromStash(ctx context.Context, sessionID string, req *models.StashRequest) ([]*models.LedgerEntry, error) {
+	return s.moveStash(ctx, sessionID, req, false)
+}
+
+func (s *PartyService) moveStash(ctx context.Context, sessionID string, req *models.StashRequest, deposit bool) ([]*models.LedgerEntry, error) {
+	if req.CharacterID == "" {
+		return nil, fmt.Errorf("character is required")
+	}
+	if req.Coins.IsNegative() {
+		return nil, fmt.Errorf("coins cannot be negative")
+	}
+	if req.Coins.IsZero() && len(req.Items) == 0 {
+		return nil, fmt.Errorf("nothing to move")
+	}
+
+	party, err := s.GetParty(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// sign is what the character gains; the stash gains the opposite
+	sign, description := 1, "withdrew from the party stash"
+	if deposit {
+		sign, description = -1, "deposited into the party stash"
+	}
+	txn := &models.EconomyTransaction{
+		Type:        models.LedgerEntryStash,
+		Description: description,
+		SessionID:   sessionID,
+		PartyID:     party.ID,
+	}
+	txn.CreatedBy, _ = auth.GetUserIDFromContext(ctx)
+
+	if !req.Coins.IsZero() {
+		characterCoins, stashCoins := req.Coins, req.Coins.Negate()
+		if deposit {
+			characterCoins, stashCoins = stashCoins, characterCoins
+		}
+		txn.Currency = []models.CurrencyMovement{{CharacterID: req.CharacterID, Coins: characterCoins}}
+		txn.PartyCurrency = []models.PartyCurrencyMovement{{PartyID: party.ID, Coins: stashCoins}}
+	}
+	for _, item := range req.Items {
+		if item.Quantity < 1 {
+			return nil, fmt.Errorf("quantity of %s must be positive", item.ItemID)
+		}
+		txn.Items = append(txn.Items, models.ItemMovement{CharacterID: req.CharacterID, ItemID: item.ItemID, Quantity: sign * item.Quantity})
+		txn.PartyStash = append(txn.PartyStash, models.PartyStashMovement{PartyID: party.ID, ItemID: item.ItemID, Quantity: -sign * item.Quantity})
+	}
+	return s.inventory.applyTransaction(ctx, txn)
+}
+
+// OfferTrade records a transfer that waits for the receiving character to accept it
+func (s *PartyService) OfferTrade(ctx context.Context, offer *models.TradeOffer) error {
+	if _, err := transferTransaction(ctx, offer.TransferRequest()); err != nil {
+		return err
+	}
+	if err := s.checkSenderHolds(offer); err != nil {
+		return err
+	}
+
+	offer.ID = ""
+	offer.Status = models.TradeStatusPending
+	offer.CreatedBy, _ = auth.GetUserIDFromContext(ctx)
+	offer.ResolvedBy, offer.ResolvedAt, offer.TransactionID = nil, nil, nil
+	return s.partyRepo.CreateTradeOffer(ctx, offer)
+}
+
+// AcceptTrade carries out a pending offer. The transfer and the offer's acceptance commit
+// together, so an offer can never be paid out twice.
+func (s *PartyService) AcceptTrade(ctx context.Context, offerID string) (*models.TradeOffer, []*models.LedgerEntry, error) {
+	offer, err := s.pendingOffer(ctx, offerID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	txn, err := transferTransaction(ctx, offer.TransferRequest())
+	if err != nil {
+		return nil, nil, err
+	}
+	txn.TradeOfferID = offer.ID
+	txn.Description = fmt.Sprintf("trade from %s to %s", offer.FromCharacterID, offer.ToCharacterID)
+
+	entries, err := s.inventory.applyTransaction(ctx, txn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	offer.Status = models.TradeStatusAccepted
+	offer.TransactionID = &txn.ID
+	if txn.CreatedBy != "" {
+		offer.ResolvedBy = &txn.CreatedBy
+	}
+	return offer, entries, nil
+}
+
+// DeclineTrade turns down a pending offer
+func (s *PartyService) DeclineTrade(ctx context.Context, offerID string) (*models.TradeOffer, error) {
+	return s.resolveTrade(ctx, offerID, models.TradeStatusDeclined)
+}
+
+// CancelTrade withdraws a pending offer
+func (s *PartyService) CancelTrade(ctx context.Context, offerID string) (*models.TradeOffer, error) {
+	return s.resolveTrade(ctx, offerID, models.TradeStatusCancelled)
+}
+
+// GetTradeOffer returns a trade offer
+func (s *PartyService) GetTradeOffer(ctx context.Context, offerID string) (*models.TradeOffer, error) {
+	offer, err := s.partyRepo.GetTradeOffer(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if offer == nil {
+		return nil, fmt.Errorf("trade offer not found")
+	}
+	return offer, nil
+}
+
+// ListTradeOffers returns the offers a character made or received, optionally only those in one status
+func (s *PartyService) ListTradeOffers(ctx context.Context, characterID string, status models.TradeStatus) ([]*models.TradeOffer, error) {
+	return s.partyRepo.ListTradeOffers(ctx, characterID, status)
+}
+
+func (s *PartyService) resolveTrade(ctx context.Context, offerID string, status models.TradeStatus) (*models.TradeOffer, error) {
+	offer, err := s.pendingOffer(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, _ := auth.GetUserIDFromContext(ctx)
+	if err := s.partyRepo.ResolveTradeOffer(ctx, offerID, status, userID); err != nil {
+		return nil, err
+	}
+	offer.Status = status
+	offer.ResolvedBy = &userID
+	return offer, nil
+}
+
+func (s *PartyService) pendingOffer(ctx context.Context, offerID string) (*models.TradeOffer, error) {
+	offer, err := s.GetTradeOffer(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if offer.Status != models.TradeStatusPending {
+		return nil, fmt.Errorf("trade offer is already %s", offer.Status)
+	}
+	return offer, nil
+}
+
+// checkSenderHolds rejects offers the sender could not pay right now. Acceptance checks again,
+// since the sender may spend the coins or items while the offer waits.
+func (s *PartyService) checkSenderHolds(offer *models.TradeOffer) error {
+	if !offer.Coins.IsZero() {
+		purse, err := s.inventoryRepo.GetCharacterCurrency(offer.FromCharacterID)
+		if err != nil {
+			return err
+		}
+		if purse == nil || !purse.CanAfford(offer.Coins.TotalInCopper()) {
+			return fmt.Errorf("insufficient funds")
+		}
+	}
+	if len(offer.Items) == 0 {
+		return nil
+	}
+
+	inventory, err := s.inventoryRepo.GetCharacterInventory(offer.FromCharacterID)
+	if err != nil {
+		return err
+	}
+	held := make(map[string]int, len(inventory))
+	for _, inv := range inventory {
+		held[inv.ItemID] += inv.Quantity
+	}
+	for _, item := range offer.Items {
+		if held[item.ItemID] < item.Quantity {
+			return fmt.Errorf("only %d of item %s in the inventory", held[item.ItemID], item.ItemID)
+		}
+		held[item.ItemID] -= item.Quantity
+	}
+	return nil
+}
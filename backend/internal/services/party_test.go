@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/internal/services/mocks"
+)
+
+// MockPartyRepository mocks party stash and trade offer storage
+type MockPartyRepository struct {
+	mock.Mock
+}
+
+func (m *MockPartyRepository) GetPartyBySession(ctx context.Context, sessionID string) (*models.Party, error) {
+	args := m.Called(ctx, sessionID)
+	return mockSingleReturn[models.Party](args, 0, 1)
+}
+
+func (m *MockPartyRepository) CreateParty(ctx context.Context, party *models.Party) error {
+	args := m.Called(ctx, party)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockPartyRepository) GetPartyStash(ctx context.Context, partyID string) ([]*models.PartyStashItem, error) {
+	args := m.Called(ctx, partyID)
+	return mockSliceReturn[models.PartyStashItem](args, 0, 1)
+}
+
+func (m *MockPartyRepository) GetPartyCurrency(ctx context.Context, partyID string) (models.Coins, error) {
+	args := m.Called(ctx, partyID)
+	return args.Get(0).(models.Coins), args.Error(1)
+}
+
+func (m *MockPartyRepository) CreateTradeOffer(ctx context.Context, offer *models.TradeOffer) error {
+	args := m.Called(ctx, offer)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockPartyRepository) GetTradeOffer(ctx context.Context, id string) (*models.TradeOffer, error) {
+	args := m.Called(ctx, id)
+	return mockSingleReturn[models.TradeOffer](args, 0, 1)
+}
+
+func (m *MockPartyRepository) ListTradeOffers(ctx context.Context, characterID string, status models.TradeStatus) ([]*models.TradeOffer, error) {
+	args := m.Called(ctx, characterID, status)
+	return mockSliceReturn[models.TradeOffer](args, 0, 1)
+}
+
+func (m *MockPartyRepository) ResolveTradeOffer(ctx context.Context, id string, status models.TradeStatus, resolvedBy string) error {
+	args := m.Called(ctx, id, status, resolvedBy)
+	return mockErrorReturn(args, 0)
+}
+
+func createTestPartyService(partyRepo *MockPartyRepository, inventoryRepo *mocks.MockInventoryRepository) *PartyService {
+	inventory := NewInventoryService(inventoryRepo, new(mocks.MockCharacterRepository))
+	return NewPartyService(partyRepo, inventory, inventoryRepo)
+}
+
+func testParty() *models.Party {
+	return &models.Party{ID: "party-1", SessionID: "session-1", Name: "The Party"}
+}
+
+func pendingOffer() *models.TradeOffer {
+	sessionID := "session-1"
+	return &models.TradeOffer{
+		ID:              "offer-1",
+		SessionID:       &sessionID,
+		FromCharacterID: "char-1",
+		ToCharacterID:   "char-2",
+		Coins:           models.Coins{Gold: 5},
+		Items:           []models.LootItem{{ItemID: "potion", Quantity: 1}},
+		Status:          models.TradeStatusPending,
+	}
+}
+
+func TestPartyService_GetParty(t *testing.T) {
+	tests := []struct {
+		name       string
+		setupMocks func(*MockPartyRepository)
+	}{
+		{
+			name: "Creates the session's party on first use",
+			setupMocks: func(partyRepo *MockPartyRepository) {
+				partyRepo.On("GetPartyBySession", mock.Anything, "session-1").Return(nil, nil).Once()
+				partyRepo.On("CreateParty", mock.Anything, mock.MatchedBy(func(p *models.Party) bool {
+					return p.SessionID == "session-1"
+				})).Return(nil)
+				partyRepo.On("GetPartyBySession", mock.Anything, "session-1").Return(testParty(), nil).Once()
+			},
+		},
+		{
+			name: "Existing party",
+			setupMocks: func(partyRepo *MockPartyRepository) {
+				partyRepo.On("GetPartyBySession", mock.Anything, "session-1").Return(testParty(), nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			partyRepo := new(MockPartyRepository)
+			inventoryRepo := new(mocks.MockInventoryRepository)
+			tt.setupMocks(partyRepo)
+
+			service := createTestPartyService(partyRepo, inventoryRepo)
+			party, err := service.GetParty(context.Background(), "session-1")
+
+			require.NoError(t, err)
+			assert.Equal(t, testParty(), party)
+			partyRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPartyService_Stash(t *testing.T) {
+	tests := []struct {
+		name        string
+		withdraw    bool
+		request     *models.StashRequest
+		setupMocks  func(*MockPartyRepository, *mocks.MockInventoryRepository)
+		expectError bool
+		expected    int
+	}{
+		{
+			name: "Deposit moves coins and items from the character to the party",
+			request: &models.StashRequest{
+				CharacterID: "char-1",
+				Coins:       models.Coins{Gold: 3},
+				Items:       []models.LootItem{{ItemID: "rope", Quantity: 2}},
+			},
+			setupMocks: func(partyRepo *MockPartyRepository, inventoryRepo *mocks.MockInventoryRepository) {
+				partyRepo.On("GetPartyBySession", mock.Anything, "session-1").Return(testParty(), nil)
+				inventoryRepo.On("ApplyTransaction", mock.MatchedBy(func(txn *models.EconomyTransaction) bool {
+					return txn.Type == models.LedgerEntryStash && txn.PartyID == "party-1" && txn.SessionID == "session-1" &&
+						txn.Currency[0].Coins.Gold == -3 && txn.PartyCurrency[0].Coins.Gold == 3 &&
+						txn.Items[0].Quantity == -2 && txn.PartyStash[0].Quantity == 2
+				})).Return([]*models.LedgerEntry{{CharacterID: "char-1"}}, nil)
+			},
+			expected: 1,
+		},
+		{
+			name:     "Withdrawal moves items from the party to the character",
+			withdraw: true,
+			request: &models.StashRequest{
+				CharacterID: "char-1",
+				Items:       []models.LootItem{{ItemID: "rope", Quantity: 1}},
+			},
+			setupMocks: func(partyRepo *MockPartyRepository, inventoryRepo *mocks.MockInventoryRepository) {
+				partyRepo.On("GetPartyBySession", mock.Anything, "session-1").Return(testParty(), nil)
+				inventoryRepo.On("ApplyTransaction", mock.MatchedBy(func(txn *models.EconomyTransaction) bool {
+					return len(txn.Currency) == 0 && txn.Items[0].Quantity == 1 && txn.PartyStash[0].Quantity == -1
+				})).Return([]*models.LedgerEntry{{CharacterID: "char-1"}}, nil)
+			},
+			expected: 1,
+		},
+		{
+			name:        "Empty request",
+			request:     &models.StashRequest{CharacterID: "char-1"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			partyRepo := new(MockPartyRepository)
+			inventoryRepo := new(mocks.MockInventoryRepository)
+			if tt.setupMocks != nil {
+				tt.setupMocks(partyRepo, inventoryRepo)
+			}
+
+			service := createTestPartyService(partyRepo, inventoryRepo)
+			move := service.DepositToStash
+			if tt.withdraw {
+				move = service.WithdrawFromStash
+			}
+			entries, err := move(context.Background(), "session-1", tt.request)
+
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Len(t, entries, tt.expected)
+			}
+
+			partyRepo.AssertExpectations(t)
+			inventoryRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPartyService_OfferTrade(t *testing.T) {
+	toSelf := pendingOffer()
+	toSelf.ToCharacterID = toSelf.FromCharacterID
+	answered := pendingOffer()
+	answered.Status = models.TradeStatusAccepted
+
+	tests := []struct {
+		name        string
+		offer       *models.TradeOffer
+		setupMocks  func(*MockPartyRepository, *mocks.MockInventoryRepository)
+		expectError string
+	}{
+		{
+			name:  "Stores a pending offer the sender can pay",
+			offer: answered,
+			setupMocks: func(partyRepo *MockPartyRepository, inventoryRepo *mocks.MockInventoryRepository) {
+				inventoryRepo.On("GetCharacterCurrency", "char-1").Return(&models.Currency{Gold: 10}, nil)
+				inventoryRepo.On("GetCharacterInventory", "char-1").Return([]*models.InventoryItem{{ItemID: "potion", Quantity: 2}}, nil)
+				partyRepo.On("CreateTradeOffer", mock.Anything, mock.MatchedBy(func(o *models.TradeOffer) bool {
+					return o.Status == models.TradeStatusPending
+				})).Return(nil)
+			},
+		},
+		{
+			name:  "Items the sender does not hold",
+			offer: pendingOffer(),
+			setupMocks: func(_ *MockPartyRepository, inventoryRepo *mocks.MockInventoryRepository) {
+				inventoryRepo.On("GetCharacterCurrency", "char-1").Return(&models.Currency{Gold: 10}, nil)
+				inventoryRepo.On("GetCharacterInventory", "char-1").Return([]*models.InventoryItem{}, nil)
+			},
+			expectError: "only 0",
+		},
+		{
+			name:        "Trading with yourself",
+			offer:       toSelf,
+			expectError: "same character",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			partyRepo := new(MockPartyRepository)
+			inventoryRepo := new(mocks.MockInventoryRepository)
+			if tt.setupMocks != nil {
+				tt.setupMocks(partyRepo, inventoryRepo)
+			}
+
+			service := createTestPartyService(partyRepo, inventoryRepo)
+			err := service.OfferTrade(context.Background(), tt.offer)
+
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, models.TradeStatusPending, tt.offer.Status)
+			}
+
+			partyRepo.AssertExpectations(t)
+			inventoryRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPartyService_AcceptTrade(t *testing.T) {
+	declined := pendingOffer()
+	declined.Status = models.TradeStatusDeclined
+
+	tests := []struct {
+		name        string
+		offer       *models.TradeOffer
+		setupMocks  func(*mocks.MockInventoryRepository)
+		expectError string
+	}{
+		{
+			name:  "Transfers and accepts in one transaction",
+			offer: pendingOffer(),
+			setupMocks: func(inventoryRepo *mocks.MockInventoryRepository) {
+				inventoryRepo.On("ApplyTransaction", mock.MatchedBy(func(txn *models.EconomyTransaction) bool {
+					return txn.Type == models.LedgerEntryTransfer && txn.TradeOfferID == "offer-1" &&
+						txn.SessionID == "session-1" && len(txn.Currency) == 2 && len(txn.Items) == 2
+				})).Return([]*models.LedgerEntry{{CharacterID: "char-1"}, {CharacterID: "char-2"}}, nil)
+			},
+		},
+		{
+			name:        "Offer that was already answered",
+			offer:       declined,
+			expectError: "already declined",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			partyRepo := new(MockPartyRepository)
+			inventoryRepo := new(mocks.MockInventoryRepository)
+			partyRepo.On("GetTradeOffer", mock.Anything, "offer-1").Return(tt.offer, nil)
+			if tt.setupMocks != nil {
+				tt.setupMocks(inventoryRepo)
+			}
+
+			service := createTestPartyService(partyRepo, inventoryRepo)
+			offer, entries, err := service.AcceptTrade(context.Background(), "offer-1")
+
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, models.TradeStatusAccepted, offer.Status)
+				assert.NotNil(t, offer.TransactionID)
+				assert.Len(t, entries, 2)
+			}
+
+			inventoryRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPartyService_DeclineTrade(t *testing.T) {
+	partyRepo := new(MockPartyRepository)
+	inventoryRepo := new(mocks.MockInventoryRepository)
+	partyRepo.On("GetTradeOffer", mock.Anything, "offer-1").Return(pendingOffer(), nil)
+	partyRepo.On("ResolveTradeOffer", mock.Anything, "offer-1", models.TradeStatusDeclined, "").Return(nil)
+
+	service := createTestPartyService(partyRepo, inventoryRepo)
+	offer, err := service.DeclineTrade(context.Background(), "offer-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, models.TradeStatusDeclined, offer.Status)
+	partyRepo.AssertExpectations(t)
+}
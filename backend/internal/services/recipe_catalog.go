@@ -0,0 +1,147 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+// reagentTag marks catalog items used as crafting ingredients, which alchemists and magic shops stock
+const reagentTag = "reagent"
+
+// RecipeCatalog holds the built-in crafting recipes shipped in data/recipes
+type RecipeCatalog struct {
+	recipes map[string]*models.Recipe
+}
+
+// rawRecipeComponent names an item by catalog ID, name or alias
+type rawRecipeComponent struct {
+	Item     string `json:"item"`
+	Quantity int    `json:"quantity"`
+}
+
+type rawRecipe struct {
+	ID          string               `json:"id"`
+	Name        string               `json:"name"`
+	Category    string               `json:"category"`
+	Description string               `json:"description"`
+	Output      rawRecipeComponent   `json:"output"`
+	Ingredients []rawRecipeComponent `json:"ingredients"`
+	Tool        string               `json:"tool"`
+	Ability     string               `json:"ability"`
+	GoldCost    int                  `json:"gold_cost"`
+	Workdays    int                  `json:"workdays"`
+	DC          int                  `json:"dc"`
+}
+
+// NewRecipeCatalog loads every recipe file under dataPath/recipes, resolving the items
+// they use and make against the item catalog
+func NewRecipeCatalog(dataPath string, items *ItemCatalog) (*RecipeCatalog, error) {
+	catalog := &RecipeCatalog{recipes: make(map[string]*models.Recipe)}
+
+	dir := filepath.Join(dataPath, "recipes")
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recipe data: %w", err)
+	}
+
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var wrapped struct {
+			Recipes []rawRecipe `json:"recipes"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file.Name(), err)
+		}
+		for i := range wrapped.Recipes {
+			recipe, err := convertRawRecipe(&wrapped.Recipes[i], items)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", file.Name(), err)
+			}
+			if _, exists := catalog.recipes[recipe.ID]; exists {
+				return nil, fmt.Errorf("%s: duplicate recipe %s", file.Name(), recipe.ID)
+			}
+			catalog.recipes[recipe.ID] = recipe
+		}
+	}
+
+	return catalog, nil
+}
+
+// Get returns the built-in recipe with the given ID
+func (c *RecipeCatalog) Get(id string) *models.Recipe {
+	return c.recipes[id]
+}
+
+// Recipes returns all built-in recipes sorted by category and name
+func (c *RecipeCatalog) Recipes() []*models.Recipe {
+	recipes := make([]*models.Recipe, 0, len(c.recipes))
+	for _, recipe := range c.recipes {
+		recipes = append(recipes, recipe)
+	}
+	sort.Slice(recipes, func(i, j int) bool {
+		if recipes[i].Category != recipes[j].Category {
+			return recipes[i].Category < recipes[j].Category
+		}
+		return recipes[i].Name < recipes[j].Name
+	})
+	return recipes
+}
+
+func convertRawRecipe(raw *rawRecipe, items *ItemCatalog) (*models.Recipe, error) {
+	recipe := &models.Recipe{
+		ID:          raw.ID,
+		Name:        raw.Name,
+		Category:    models.RecipeCategory(raw.Category),
+		Description: raw.Description,
+		Tool:        raw.Tool,
+		Ability:     raw.Ability,
+		GoldCost:    raw.GoldCost,
+		Workdays:    raw.Workdays,
+		DC:          raw.DC,
+	}
+	if recipe.ID == "" {
+		recipe.ID = catalogSlug(raw.Name)
+	}
+
+	output, err := resolveRecipeComponent(raw.Output, items)
+	if err != nil {
+		return nil, fmt.Errorf("recipe %s: %w", recipe.ID, err)
+	}
+	recipe.Output = output
+	for _, ingredient := range raw.Ingredients {
+		component, err := resolveRecipeComponent(ingredient, items)
+		if err != nil {
+			return nil, fmt.Errorf("recipe %s: %w", recipe.ID, err)
+		}
+		recipe.Ingredients = append(recipe.Ingredients, component)
+	}
+
+	if err := recipe.Validate(); err != nil {
+		return nil, fmt.Errorf("recipe %s: %w", recipe.ID, err)
+	}
+	return recipe, nil
+}
+
+func resolveRecipeComponent(raw rawRecipeComponent, items *ItemCatalog) (models.RecipeComponent, error) {
+	item := items.Find(raw.Item)
+	if item == nil {
+		return models.RecipeComponent{}, fmt.Errorf("unknown item %q", raw.Item)
+	}
+	quantity := raw.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	return models.RecipeComponent{ItemID: item.ID, Quantity: quantity}, nil
+}
@@ -0,0 +1,711 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/database"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/logger"
+)
+
+const (
+	errMsgScheduleNotFound = "session schedule not found"
+	errMsgPollNotFound     = "availability poll not found"
+
+	defaultSessionMinutes  = 240
+	defaultReminderMinutes = 24 * 60
+	maxScheduleWindow      = 366 * 24 * time.Hour
+)
+
+// localTimeLayouts are the wall clock formats accepted for times given without an offset
+var localTimeLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04"}
+
+// SchedulingService plans game sessions: recurring slots, each player's RSVP to them,
+// polls that find the dates that suit the most players, iCalendar feeds, and the
+// reminders sent through the job queue before each session.
+type SchedulingService struct {
+	repo      database.ScheduleRepository
+	sessions  database.GameSessionRepository
+	users     database.UserRepository
+	reminders SessionReminderQueue
+	now       func() time.Time
+}
+
+// NewSchedulingService creates a new scheduling service
+func NewSchedulingService(repo database.ScheduleRepository, sessions database.GameSessionRepository, users database.UserRepository) *SchedulingService {
+	return &SchedulingService{
+		repo:     repo,
+		sessions: sessions,
+		users:    users,
+		now:      time.Now,
+	}
+}
+
+// SetReminderQueue sets the job queue session reminders are sent through
+func (s *SchedulingService) SetReminderQueue(reminders SessionReminderQueue) {
+	s.reminders = reminders
+}
+
+// CreateSchedule sets aside a slot for a game session and queues its first reminder
+func (s *SchedulingService) CreateSchedule(ctx context.Context, sessionID, userID string, req *models.SessionScheduleRequest) (*models.SessionSchedule, error) {
+	schedule := &models.SessionSchedule{SessionID: sessionID, CreatedBy: userID}
+	if err := applyScheduleRequest(schedule, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+	s.queueReminder(ctx, schedule, s.now())
+	return schedule, nil
+}
+
+// GetSchedule returns a session schedule
+func (s *SchedulingService) GetSchedule(ctx context.Context, scheduleID string) (*models.SessionSchedule, error) {
+	schedule, err := s.repo.GetSchedule(ctx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		return nil, fmt.Errorf(errMsgScheduleNotFound)
+	}
+	return schedule, nil
+}
+
+// ListSchedules returns a game session's schedules
+func (s *SchedulingService) ListSchedules(ctx context.Context, sessionID string) ([]*models.SessionSchedule, error) {
+	return s.repo.ListSchedules(ctx, sessionID)
+}
+
+// UpdateSchedule replaces a schedule's timing and details. RSVPs to occurrences that no
+// longer fall on the schedule are kept but no longer shown.
+func (s *SchedulingService) UpdateSchedule(ctx context.Context, scheduleID string, req *models.SessionScheduleRequest) (*models.SessionSchedule, error) {
+	schedule, err := s.GetSchedule(ctx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyScheduleRequest(schedule, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+	s.queueReminder(ctx, schedule, s.now())
+	return schedule, nil
+}
+
+// DeleteSchedule removes a schedule. Reminders already queued for it are dropped when they
+// come due.
+func (s *SchedulingService) DeleteSchedule(ctx context.Context, scheduleID string) error {
+	return s.repo.DeleteSchedule(ctx, scheduleID)
+}
+
+// GetUpcoming lists the occurrences of a session's schedules that start between from and
+// to, with who has answered and who has not
+func (s *SchedulingService) GetUpcoming(ctx context.Context, sessionID string, from, to time.Time) ([]*models.ScheduledSession, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("the end of the range must not be before its start")
+	}
+	if to.Sub(from) > maxScheduleWindow {
+		return nil, fmt.Errorf("the range cannot be longer than a year")
+	}
+
+	session, err := s.sessions.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.memberIDs(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := s.repo.ListSchedules(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	upcoming := make([]*models.ScheduledSession, 0)
+	for _, schedule := range schedules {
+		starts := occurrences(schedule, from, to)
+		if len(starts) == 0 {
+			continue
+		}
+		rsvps, err := s.repo.ListRSVPs(ctx, schedule.ID, starts[0], starts[len(starts)-1])
+		if err != nil {
+			return nil, err
+		}
+		for _, start := range starts {
+			upcoming = append(upcoming, scheduledSession(schedule, start, rsvps, members))
+		}
+	}
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		return upcoming[i].StartsAt.Before(upcoming[j].StartsAt)
+	})
+	return upcoming, nil
+}
+
+// RSVP records a member's answer to an occurrence of a schedule that has not started yet
+func (s *SchedulingService) RSVP(ctx context.Context, scheduleID, userID string, req *models.RSVPRequest) (*models.SessionRSVP, error) {
+	if !req.Response.Valid() {
+		return nil, fmt.Errorf("response must be yes, no or maybe")
+	}
+	schedule, err := s.GetSchedule(ctx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if !isOccurrence(schedule, req.StartsAt) {
+		return nil, fmt.Errorf("the schedule has no session starting at %s", req.StartsAt.Format(time.RFC3339))
+	}
+	if !req.StartsAt.After(s.now()) {
+		return nil, fmt.Errorf("that session has already started")
+	}
+
+	rsvp := &models.SessionRSVP{
+		ScheduleID: schedule.ID,
+		StartsAt:   req.StartsAt,
+		UserID:     userID,
+		Response:   req.Response,
+		Note:       strings.TrimSpace(req.Note),
+	}
+	if err := s.repo.SetRSVP(ctx, rsvp); err != nil {
+		return nil, err
+	}
+	return rsvp, nil
+}
+
+// CreatePoll proposes dates to a session's members
+func (s *SchedulingService) CreatePoll(ctx context.Context, sessionID, userID string, req *models.AvailabilityPollRequest) (*models.AvailabilityPoll, error) {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+	loc, err := loadTimezone(req.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Options) < 2 {
+		return nil, fmt.Errorf("a poll needs at least two dates")
+	}
+
+	poll := &models.AvailabilityPoll{
+		SessionID:       sessionID,
+		Title:           title,
+		Timezone:        loc.String(),
+		DurationMinutes: req.DurationMinutes,
+		Status:          models.AvailabilityPollOpen,
+		CreatedBy:       userID,
+	}
+	if poll.DurationMinutes <= 0 {
+		poll.DurationMinutes = defaultSessionMinutes
+	}
+	seen := make(map[time.Time]bool, len(req.Options))
+	for _, value := range req.Options {
+		start, err := parseLocalTime(value, loc)
+		if err != nil {
+			return nil, err
+		}
+		if seen[start] {
+			return nil, fmt.Errorf("%s is proposed more than once", value)
+		}
+		seen[start] = true
+		poll.Options = append(poll.Options, &models.PollOption{StartsAt: start})
+	}
+	sort.Slice(poll.Options, func(i, j int) bool {
+		return poll.Options[i].StartsAt.Before(poll.Options[j].StartsAt)
+	})
+
+	if err := s.repo.CreatePoll(ctx, poll); err != nil {
+		return nil, err
+	}
+	rankPollOptions(poll)
+	return poll, nil
+}
+
+// GetPoll returns an availability poll with its options ranked best first
+func (s *SchedulingService) GetPoll(ctx context.Context, pollID string) (*models.AvailabilityPoll, error) {
+	poll, err := s.repo.GetPoll(ctx, pollID)
+	if err != nil {
+		return nil, err
+	}
+	if poll == nil {
+		return nil, fmt.Errorf(errMsgPollNotFound)
+	}
+	rankPollOptions(poll)
+	return poll, nil
+}
+
+// ListPolls returns a session's availability polls, newest first
+func (s *SchedulingService) ListPolls(ctx context.Context, sessionID string) ([]*models.AvailabilityPoll, error) {
+	polls, err := s.repo.ListPolls(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	for _, poll := range polls {
+		rankPollOptions(poll)
+	}
+	return polls, nil
+}
+
+// Vote records a member's availability for the options of an open poll
+func (s *SchedulingService) Vote(ctx context.Context, pollID, userID string, req *models.PollVoteRequest) (*models.AvailabilityPoll, error) {
+	poll, err := s.GetPoll(ctx, pollID)
+	if err != nil {
+		return nil, err
+	}
+	if poll.Status != models.AvailabilityPollOpen {
+		return nil, fmt.Errorf("the poll is closed")
+	}
+	if len(req.Votes) == 0 {
+		return nil, fmt.Errorf("at least one vote is required")
+	}
+
+	options := make(map[string]bool, len(poll.Options))
+	for _, option := range poll.Options {
+		options[option.ID] = true
+	}
+	votes := make([]*models.PollVote, 0, len(req.Votes))
+	for optionID, response := range req.Votes {
+		if !options[optionID] {
+			return nil, fmt.Errorf("option %s is not part of the poll", optionID)
+		}
+		if !response.Valid() {
+			return nil, fmt.Errorf("response must be yes, no or maybe")
+		}
+		votes = append(votes, &models.PollVote{OptionID: optionID, UserID: userID, Response: response})
+	}
+	if err := s.repo.SetVotes(ctx, votes); err != nil {
+		return nil, err
+	}
+	return s.GetPoll(ctx, pollID)
+}
+
+// ClosePoll stops a poll taking votes and books a one-off session on the chosen date, or
+// on the best ranked date when none is chosen
+func (s *SchedulingService) ClosePoll(ctx context.Context, pollID, userID string, req *models.ClosePollRequest) (*models.AvailabilityPoll, error) {
+	poll, err := s.GetPoll(ctx, pollID)
+	if err != nil {
+		return nil, err
+	}
+	if poll.Status != models.AvailabilityPollOpen {
+		return nil, fmt.Errorf("the poll is already closed")
+	}
+
+	var chosen *models.PollOption
+	for _, option := range poll.Options {
+		if (req.OptionID == "" && option.Rank == 1) || option.ID == req.OptionID {
+			chosen = option
+			break
+		}
+	}
+	if chosen == nil {
+		return nil, fmt.Errorf("option %s is not part of the poll", req.OptionID)
+	}
+
+	reminder := defaultReminderMinutes
+	schedule, err := s.CreateSchedule(ctx, poll.SessionID, userID, &models.SessionScheduleRequest{
+		Title:           poll.Title,
+		StartsAt:        chosen.StartsAt.Format(time.RFC3339),
+		DurationMinutes: poll.DurationMinutes,
+		Timezone:        poll.Timezone,
+		Recurrence:      models.RecurrenceNone,
+		ReminderMinutes: &reminder,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	closedAt := s.now()
+	poll.Status = models.AvailabilityPollClosed
+	poll.ChosenOptionID = &chosen.ID
+	poll.ScheduleID = &schedule.ID
+	poll.ClosedAt = &closedAt
+	if err := s.repo.ClosePoll(ctx, poll); err != nil {
+		return nil, err
+	}
+	return poll, nil
+}
+
+// SessionCalendar renders a game session's schedules as an iCalendar document
+func (s *SchedulingService) SessionCalendar(ctx context.Context, sessionID string) ([]byte, error) {
+	session, err := s.sessions.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := s.repo.ListSchedules(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return renderICalendar(session.Name, map[string]string{sessionID: session.Name}, schedules), nil
+}
+
+// CalendarFeedToken returns the secret token of the user's calendar feed, creating it the
+// first time. Rotating it gives the feed a new address and retires the old one.
+func (s *SchedulingService) CalendarFeedToken(ctx context.Context, userID string, rotate bool) (string, error) {
+	if !rotate {
+		token, err := s.repo.GetCalendarToken(ctx, userID)
+		if err != nil || token != "" {
+			return token, err
+		}
+	}
+
+	bytes := make([]byte, 24)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate calendar feed token: %w", err)
+	}
+	token := hex.EncodeToString(bytes)
+	if err := s.repo.SetCalendarToken(ctx, userID, token); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// CalendarFeed renders every session the feed's owner runs or plays in as an iCalendar
+// document, for calendar apps that subscribe to the feed's address
+func (s *SchedulingService) CalendarFeed(ctx context.Context, token string) ([]byte, error) {
+	userID, err := s.repo.GetCalendarUser(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("calendar feed not found")
+	}
+
+	schedules, err := s.repo.ListSchedulesForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string)
+	for _, schedule := range schedules {
+		if _, ok := names[schedule.SessionID]; ok {
+			continue
+		}
+		session, err := s.sessions.GetByID(ctx, schedule.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		names[schedule.SessionID] = session.Name
+	}
+	return renderICalendar("D&D Sessions", names, schedules), nil
+}
+
+// SessionReminder builds the reminder for an occurrence of a schedule and queues the
+// reminder for the occurrence after it. It returns nil when the schedule was deleted or no
+// longer has a session at that time, and the reminder should not be sent. Members who
+// answered no are left out.
+func (s *SchedulingService) SessionReminder(ctx context.Context, scheduleID string, startsAt time.Time) (*models.SessionReminder, error) {
+	schedule, err := s.repo.GetSchedule(ctx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil || schedule.ReminderMinutes == 0 || !isOccurrence(schedule, startsAt) {
+		return nil, nil
+	}
+	s.queueReminder(ctx, schedule, startsAt)
+
+	session, err := s.sessions.GetByID(ctx, schedule.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.memberIDs(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	rsvps, err := s.repo.ListRSVPs(ctx, schedule.ID, startsAt, startsAt)
+	if err != nil {
+		return nil, err
+	}
+	occurrence := scheduledSession(schedule, startsAt, rsvps, members)
+
+	declined := make(map[string]bool)
+	for _, rsvp := range occurrence.RSVPs {
+		if rsvp.Response == models.RSVPNo {
+			declined[rsvp.UserID] = true
+		}
+	}
+	reminder := &models.SessionReminder{ScheduleID: schedule.ID, StartsAt: startsAt}
+	for _, userID := range members {
+		if declined[userID] {
+			continue
+		}
+		user, err := s.users.GetByID(ctx, userID)
+		if err != nil || user.Email == "" {
+			continue
+		}
+		reminder.To = append(reminder.To, user.Email)
+	}
+	if len(reminder.To) == 0 {
+		return nil, nil
+	}
+
+	local := startsAt.In(scheduleLocation(schedule))
+	reminder.Subject = fmt.Sprintf("Reminder: %s - %s", session.Name, local.Format("Mon Jan 2, 3:04 PM MST"))
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s (%s) starts %s and runs for about %s.\n\n", schedule.Title, session.Name,
+		local.Format("Monday, January 2 at 3:04 PM MST"), time.Duration(schedule.DurationMinutes)*time.Minute)
+	if schedule.Notes != "" {
+		fmt.Fprintf(&body, "%s\n\n", schedule.Notes)
+	}
+	fmt.Fprintf(&body, "Coming: %d, maybe: %d, not coming: %d, yet to answer: %d.\n",
+		occurrence.Counts[models.RSVPYes], occurrence.Counts[models.RSVPMaybe], occurrence.Counts[models.RSVPNo],
+		len(occurrence.Pending))
+	reminder.Body = body.String()
+	return reminder, nil
+}
+
+// queueReminder queues the reminder for the schedule's first occurrence after the given
+// time. A reminder already due is sent straight away. Failing to queue it is logged
+// rather than undoing the change to the schedule.
+func (s *SchedulingService) queueReminder(ctx context.Context, schedule *models.SessionSchedule, after time.Time) {
+	if s.reminders == nil || schedule.ReminderMinutes == 0 {
+		return
+	}
+	if after.Before(s.now()) {
+		after = s.now()
+	}
+	starts := occurrences(schedule, after.Add(time.Second), after.Add(maxScheduleWindow*time.Duration(schedule.Interval)))
+	if len(starts) == 0 {
+		return
+	}
+
+	sendAt := starts[0].Add(-time.Duration(schedule.ReminderMinutes) * time.Minute)
+	if sendAt.Before(s.now()) {
+		sendAt = s.now()
+	}
+	if err := s.reminders.EnqueueSessionReminder(ctx, schedule.ID, starts[0], sendAt); err != nil {
+		logger.WithContext(ctx).WithError(err).Error().
+			Str("schedule_id", schedule.ID).
+			Time("starts_at", starts[0]).
+			Msg("Failed to queue session reminder")
+	}
+}
+
+// memberIDs lists the session's DM and players
+func (s *SchedulingService) memberIDs(ctx context.Context, session *models.GameSession) ([]string, error) {
+	participants, err := s.sessions.GetParticipants(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	members := []string{session.DMID}
+	for _, participant := range participants {
+		if participant.UserID != session.DMID {
+			members = append(members, participant.UserID)
+		}
+	}
+	return members, nil
+}
+
+// applyScheduleRequest validates a schedule request and copies it onto the schedule
+func applyScheduleRequest(schedule *models.SessionSchedule, req *models.SessionScheduleRequest) error {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return fmt.Errorf("title is required")
+	}
+	loc, err := loadTimezone(req.Timezone)
+	if err != nil {
+		return err
+	}
+	startsAt, err := parseLocalTime(req.StartsAt, loc)
+	if err != nil {
+		return err
+	}
+
+	recurrence := req.Recurrence
+	if recurrence == "" {
+		recurrence = models.RecurrenceNone
+	}
+	switch recurrence {
+	case models.RecurrenceNone, models.RecurrenceWeekly, models.RecurrenceMonthly:
+	default:
+		return fmt.Errorf("recurrence must be none, weekly or monthly")
+	}
+	if req.Interval < 0 || req.DurationMinutes < 0 {
+		return fmt.Errorf("interval and duration cannot be negative")
+	}
+	if req.Until != nil && req.Until.Before(startsAt) {
+		return fmt.Errorf("a schedule cannot end before it starts")
+	}
+
+	schedule.Title = title
+	schedule.Notes = strings.TrimSpace(req.Notes)
+	schedule.StartsAt = startsAt
+	schedule.Timezone = loc.String()
+	schedule.Recurrence = recurrence
+	schedule.Interval = req.Interval
+	if schedule.Interval == 0 {
+		schedule.Interval = 1
+	}
+	schedule.Until = req.Until
+	schedule.DurationMinutes = req.DurationMinutes
+	if schedule.DurationMinutes == 0 {
+		schedule.DurationMinutes = defaultSessionMinutes
+	}
+	schedule.ReminderMinutes = defaultReminderMinutes
+	if req.ReminderMinutes != nil {
+		if *req.ReminderMinutes < 0 {
+			return fmt.Errorf("reminder cannot be negative")
+		}
+		schedule.ReminderMinutes = *req.ReminderMinutes
+	}
+	return nil
+}
+
+// loadTimezone loads an IANA timezone such as "America/Chicago"
+func loadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, fmt.Errorf("timezone is required")
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return loc, nil
+}
+
+// parseLocalTime reads an RFC 3339 time, or a wall clock time in loc when it has no offset
+func parseLocalTime(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	for _, layout := range localTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date and time such as 2024-05-17T19:00", value)
+}
+
+// scheduleLocation is the timezone a schedule's occurrences keep their wall clock time in
+func scheduleLocation(schedule *models.SessionSchedule) *time.Location {
+	loc, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// occurrences lists when a schedule's sessions start between from and to, inclusive.
+// Monthly schedules skip months without their day, as a session on the 31st does in
+// April.
+func occurrences(schedule *models.SessionSchedule, from, to time.Time) []time.Time {
+	first := schedule.StartsAt.In(scheduleLocation(schedule))
+	interval := schedule.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	// Skip ahead to just before the range rather than walking every occurrence since the first
+	n := 0
+	if from.After(first) {
+		switch schedule.Recurrence {
+		case models.RecurrenceWeekly:
+			n = int(from.Sub(first)/(time.Duration(7*interval)*24*time.Hour)) - 1
+		case models.RecurrenceMonthly:
+			n = (monthsBetween(first, from) / interval) - 1
+		}
+		if n < 0 {
+			n = 0
+		}
+	}
+
+	starts := make([]time.Time, 0)
+	for ; ; n++ {
+		var start time.Time
+		switch schedule.Recurrence {
+		case models.RecurrenceWeekly:
+			start = time.Date(first.Year(), first.Month(), first.Day()+7*interval*n,
+				first.Hour(), first.Minute(), first.Second(), 0, first.Location())
+		case models.RecurrenceMonthly:
+			start = time.Date(first.Year(), first.Month()+time.Month(interval*n), first.Day(),
+				first.Hour(), first.Minute(), first.Second(), 0, first.Location())
+		default:
+			if n > 0 {
+				return starts
+			}
+			start = first
+		}
+		if start.After(to) || (schedule.Until != nil && start.After(*schedule.Until)) {
+			return starts
+		}
+		if start.Day() != first.Day() && schedule.Recurrence == models.RecurrenceMonthly {
+			continue
+		}
+		if !start.Before(from) {
+			starts = append(starts, start.UTC())
+		}
+	}
+}
+
+// monthsBetween counts the calendar months from a to b
+func monthsBetween(a, b time.Time) int {
+	return (b.Year()-a.Year())*12 + int(b.Month()) - int(a.Month())
+}
+
+// isOccurrence reports whether one of the schedule's sessions starts at t
+func isOccurrence(schedule *models.SessionSchedule, t time.Time) bool {
+	starts := occurrences(schedule, t, t)
+	return len(starts) == 1 && starts[0].Equal(t)
+}
+
+// scheduledSession gathers the answers to one occurrence of a schedule
+func scheduledSession(schedule *models.SessionSchedule, start time.Time, rsvps []*models.SessionRSVP, members []string) *models.ScheduledSession {
+	occurrence := &models.ScheduledSession{
+		ScheduleID: schedule.ID,
+		SessionID:  schedule.SessionID,
+		Title:      schedule.Title,
+		StartsAt:   start,
+		EndsAt:     start.Add(time.Duration(schedule.DurationMinutes) * time.Minute),
+		Timezone:   schedule.Timezone,
+		RSVPs:      make([]*models.SessionRSVP, 0),
+		Counts:     map[models.RSVPResponse]int{models.RSVPYes: 0, models.RSVPNo: 0, models.RSVPMaybe: 0},
+		Pending:    make([]string, 0),
+	}
+	answered := make(map[string]bool)
+	for _, rsvp := range rsvps {
+		if !rsvp.StartsAt.Equal(start) {
+			continue
+		}
+		occurrence.RSVPs = append(occurrence.RSVPs, rsvp)
+		occurrence.Counts[rsvp.Response]++
+		answered[rsvp.UserID] = true
+	}
+	for _, userID := range members {
+		if !answered[userID] {
+			occurrence.Pending = append(occurrence.Pending, userID)
+		}
+	}
+	return occurrence
+}
+
+// rankPollOptions counts each option's votes and ranks the options: most yes votes first,
+// then most maybes, then fewest noes, then the earliest date
+func rankPollOptions(poll *models.AvailabilityPoll) {
+	for _, option := range poll.Options {
+		option.Counts = map[models.RSVPResponse]int{models.RSVPYes: 0, models.RSVPNo: 0, models.RSVPMaybe: 0}
+		for _, vote := range option.Votes {
+			option.Counts[vote.Response]++
+		}
+	}
+
+	ranked := make([]*models.PollOption, len(poll.Options))
+	copy(ranked, poll.Options)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i].Counts, ranked[j].Counts
+		if a[models.RSVPYes] != b[models.RSVPYes] {
+			return a[models.RSVPYes] > b[models.RSVPYes]
+		}
+		if a[models.RSVPMaybe] != b[models.RSVPMaybe] {
+			return a[models.RSVPMaybe] > b[models.RSVPMaybe]
+		}
+		if a[models.RSVPNo] != b[models.RSVPNo] {
+			return a[models.RSVPNo] < b[models.RSVPNo]
+		}
+		return ranked[i].StartsAt.Before(ranked[j].StartsAt)
+	})
+	for i, option := range ranked {
+		option.Rank = i + 1
+	}
+}
@@ -0,0 +1,552 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/internal/services/mocks"
+)
+
+// MockScheduleRepository mocks planned session storage
+type MockScheduleRepository struct {
+	mock.Mock
+}
+
+func (m *MockScheduleRepository) CreateSchedule(ctx context.Context, schedule *models.SessionSchedule) error {
+	args := m.Called(ctx, schedule)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockScheduleRepository) GetSchedule(ctx context.Context, id string) (*models.SessionSchedule, error) {
+	args := m.Called(ctx, id)
+	return mockSingleReturn[models.SessionSchedule](args, 0, 1)
+}
+
+func (m *MockScheduleRepository) ListSchedules(ctx context.Context, sessionID string) ([]*models.SessionSchedule, error) {
+	args := m.Called(ctx, sessionID)
+	return mockSliceReturn[models.SessionSchedule](args, 0, 1)
+}
+
+func (m *MockScheduleRepository) ListSchedulesForUser(ctx context.Context, userID string) ([]*models.SessionSchedule, error) {
+	args := m.Called(ctx, userID)
+	return mockSliceReturn[models.SessionSchedule](args, 0, 1)
+}
+
+func (m *MockScheduleRepository) UpdateSchedule(ctx context.Context, schedule *models.SessionSchedule) error {
+	args := m.Called(ctx, schedule)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockScheduleRepository) DeleteSchedule(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockScheduleRepository) SetRSVP(ctx context.Context, rsvp *models.SessionRSVP) error {
+	args := m.Called(ctx, rsvp)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockScheduleRepository) ListRSVPs(ctx context.Context, scheduleID string, from, to time.Time) ([]*models.SessionRSVP, error) {
+	args := m.Called(ctx, scheduleID, from, to)
+	return mockSliceReturn[models.SessionRSVP](args, 0, 1)
+}
+
+func (m *MockScheduleRepository) CreatePoll(ctx context.Context, poll *models.AvailabilityPoll) error {
+	args := m.Called(ctx, poll)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockScheduleRepository) GetPoll(ctx context.Context, id string) (*models.AvailabilityPoll, error) {
+	args := m.Called(ctx, id)
+	return mockSingleReturn[models.AvailabilityPoll](args, 0, 1)
+}
+
+func (m *MockScheduleRepository) ListPolls(ctx context.Context, sessionID string) ([]*models.AvailabilityPoll, error) {
+	args := m.Called(ctx, sessionID)
+	return mockSliceReturn[models.AvailabilityPoll](args, 0, 1)
+}
+
+func (m *MockScheduleRepository) SetVotes(ctx context.Context, votes []*models.PollVote) error {
+	args := m.Called(ctx, votes)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockScheduleRepository) ClosePoll(ctx context.Context, poll *models.AvailabilityPoll) error {
+	args := m.Called(ctx, poll)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockScheduleRepository) GetCalendarToken(ctx context.Context, userID string) (string, error) {
+	args := m.Called(ctx, userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockScheduleRepository) SetCalendarToken(ctx context.Context, userID, token string) error {
+	args := m.Called(ctx, userID, token)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockScheduleRepository) GetCalendarUser(ctx context.Context, token string) (string, error) {
+	args := m.Called(ctx, token)
+	return args.String(0), args.Error(1)
+}
+
+// fakeReminderQueue records the reminders the scheduler queues
+type fakeReminderQueue struct {
+	starts []time.Time
+	sends  []time.Time
+}
+
+func (q *fakeReminderQueue) EnqueueSessionReminder(_ context.Context, _ string, startsAt, sendAt time.Time) error {
+	q.starts = append(q.starts, startsAt)
+	q.sends = append(q.sends, sendAt)
+	return nil
+}
+
+func createTestSchedulingService(repo *MockScheduleRepository, sessions *mocks.MockGameSessionRepository, users *mocks.MockUserRepository, queue *fakeReminderQueue, now time.Time) *SchedulingService {
+	service := NewSchedulingService(repo, sessions, users)
+	service.SetReminderQueue(queue)
+	service.now = func() time.Time { return now }
+	return service
+}
+
+// setupSchedulingSession runs session-1 for dm-1 with two players
+func setupSchedulingSession(sessions *mocks.MockGameSessionRepository) {
+	sessions.On("GetByID", mock.Anything, "session-1").
+		Return(&models.GameSession{ID: "session-1", DMID: "dm-1", Name: "Curse of Strahd"}, nil)
+	sessions.On("GetParticipants", mock.Anything, "session-1").Return([]*models.GameParticipant{
+		{SessionID: "session-1", UserID: "player-1"},
+		{SessionID: "session-1", UserID: "player-2"},
+	}, nil)
+}
+
+func mustLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	return loc
+}
+
+func TestOccurrences(t *testing.T) {
+	t.Run("weekly sessions keep their local time across daylight saving", func(t *testing.T) {
+		newYork := mustLocation(t, "America/New_York")
+		schedule := &models.SessionSchedule{
+			StartsAt:   time.Date(2024, 3, 1, 19, 0, 0, 0, newYork).UTC(),
+			Timezone:   "America/New_York",
+			Recurrence: models.RecurrenceWeekly,
+			Interval:   2,
+		}
+
+		starts := occurrences(schedule, schedule.StartsAt, schedule.StartsAt.Add(30*24*time.Hour))
+
+		require.Len(t, starts, 3)
+		for _, start := range starts {
+			local := start.In(newYork)
+			assert.Equal(t, time.Friday, local.Weekday())
+			assert.Equal(t, 19, local.Hour())
+		}
+		assert.Equal(t, 0, starts[0].Hour(), "7pm EST is midnight UTC")
+		assert.Equal(t, 23, starts[1].Hour(), "7pm EDT is 11pm UTC")
+	})
+
+	t.Run("monthly sessions skip months without their day", func(t *testing.T) {
+		schedule := &models.SessionSchedule{
+			StartsAt:   time.Date(2024, 1, 31, 18, 0, 0, 0, time.UTC),
+			Timezone:   "UTC",
+			Recurrence: models.RecurrenceMonthly,
+			Interval:   1,
+		}
+
+		starts := occurrences(schedule, schedule.StartsAt, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+
+		var months []time.Month
+		for _, start := range starts {
+			months = append(months, start.Month())
+		}
+		assert.Equal(t, []time.Month{time.January, time.March, time.May}, months)
+	})
+
+	t.Run("a range far from the first session starts where it should", func(t *testing.T) {
+		schedule := &models.SessionSchedule{
+			StartsAt:   time.Date(2020, 1, 6, 18, 0, 0, 0, time.UTC),
+			Timezone:   "UTC",
+			Recurrence: models.RecurrenceWeekly,
+			Interval:   1,
+		}
+		from := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+		starts := occurrences(schedule, from, from.Add(14*24*time.Hour))
+
+		assert.Equal(t, []time.Time{
+			time.Date(2024, 7, 1, 18, 0, 0, 0, time.UTC),
+			time.Date(2024, 7, 8, 18, 0, 0, 0, time.UTC),
+		}, starts)
+	})
+
+	t.Run("nothing starts after until, and a one-off session happens once", func(t *testing.T) {
+		start := time.Date(2024, 5, 4, 18, 0, 0, 0, time.UTC)
+		until := start.Add(8 * 24 * time.Hour)
+		weekly := &models.SessionSchedule{StartsAt: start, Timezone: "UTC", Recurrence: models.RecurrenceWeekly, Interval: 1, Until: &until}
+		once := &models.SessionSchedule{StartsAt: start, Timezone: "UTC", Recurrence: models.RecurrenceNone}
+
+		assert.Len(t, occurrences(weekly, start, start.Add(60*24*time.Hour)), 2)
+		assert.Equal(t, []time.Time{start}, occurrences(once, start.Add(-time.Hour), start.Add(60*24*time.Hour)))
+	})
+}
+
+func TestSchedulingService_CreateSchedule(t *testing.T) {
+	tests := []struct {
+		name        string
+		request     models.SessionScheduleRequest
+		setupMocks  func(*MockScheduleRepository)
+		expectError bool
+		validate    func(*testing.T, *models.SessionSchedule, *fakeReminderQueue)
+	}{
+		{
+			name: "Wall clock time in the schedule's timezone",
+			request: models.SessionScheduleRequest{
+				Title:      "Weekly game",
+				StartsAt:   "2024-05-03T19:00",
+				Timezone:   "Europe/Berlin",
+				Recurrence: models.RecurrenceWeekly,
+			},
+			setupMocks: func(repo *MockScheduleRepository) {
+				repo.On("CreateSchedule", mock.Anything, mock.AnythingOfType("*models.SessionSchedule")).Return(nil)
+			},
+			validate: func(t *testing.T, schedule *models.SessionSchedule, queue *fakeReminderQueue) {
+				assert.Equal(t, time.Date(2024, 5, 3, 17, 0, 0, 0, time.UTC), schedule.StartsAt)
+				assert.Equal(t, 1, schedule.Interval)
+				assert.Equal(t, defaultSessionMinutes, schedule.DurationMinutes)
+				require.Len(t, queue.starts, 1)
+				assert.Equal(t, schedule.StartsAt, queue.starts[0])
+				assert.Equal(t, schedule.StartsAt.Add(-24*time.Hour), queue.sends[0])
+			},
+		},
+		{
+			name:        "Unknown timezone",
+			request:     models.SessionScheduleRequest{Title: "Game", StartsAt: "2024-05-03T19:00", Timezone: "Mars/Olympus"},
+			expectError: true,
+		},
+		{
+			name:        "Unknown recurrence",
+			request:     models.SessionScheduleRequest{Title: "Game", StartsAt: "2024-05-03T19:00", Timezone: "UTC", Recurrence: "daily"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockScheduleRepository)
+			sessions := new(mocks.MockGameSessionRepository)
+			queue := &fakeReminderQueue{}
+			setupSchedulingSession(sessions)
+			if tt.setupMocks != nil {
+				tt.setupMocks(repo)
+			}
+
+			now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+			service := createTestSchedulingService(repo, sessions, new(mocks.MockUserRepository), queue, now)
+			schedule, err := service.CreateSchedule(context.Background(), "session-1", "dm-1", &tt.request)
+
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				tt.validate(t, schedule, queue)
+			}
+
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSchedulingService_RSVP(t *testing.T) {
+	start := time.Date(2024, 5, 3, 18, 0, 0, 0, time.UTC)
+	schedule := &models.SessionSchedule{ID: "schedule-1", SessionID: "session-1", StartsAt: start, Timezone: "UTC",
+		Recurrence: models.RecurrenceWeekly, Interval: 1, DurationMinutes: 240}
+
+	tests := []struct {
+		name        string
+		now         time.Time
+		request     models.RSVPRequest
+		setupMocks  func(*MockScheduleRepository)
+		expectError bool
+		validate    func(*testing.T, *models.SessionRSVP)
+	}{
+		{
+			name:    "Upcoming occurrence",
+			now:     start,
+			request: models.RSVPRequest{StartsAt: start.Add(7 * 24 * time.Hour), Response: models.RSVPMaybe, Note: " running late "},
+			setupMocks: func(repo *MockScheduleRepository) {
+				repo.On("SetRSVP", mock.Anything, mock.AnythingOfType("*models.SessionRSVP")).Return(nil)
+			},
+			validate: func(t *testing.T, rsvp *models.SessionRSVP) {
+				assert.Equal(t, models.RSVPMaybe, rsvp.Response)
+				assert.Equal(t, "running late", rsvp.Note)
+			},
+		},
+		{
+			name:        "Time the schedule has no session at",
+			now:         start.Add(time.Hour),
+			request:     models.RSVPRequest{StartsAt: start.Add(24 * time.Hour), Response: models.RSVPYes},
+			expectError: true,
+		},
+		{
+			name:        "Session already started",
+			now:         start.Add(time.Hour),
+			request:     models.RSVPRequest{StartsAt: start, Response: models.RSVPYes},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockScheduleRepository)
+			sessions := new(mocks.MockGameSessionRepository)
+			setupSchedulingSession(sessions)
+			repo.On("GetSchedule", mock.Anything, "schedule-1").Return(schedule, nil)
+			if tt.setupMocks != nil {
+				tt.setupMocks(repo)
+			}
+
+			service := createTestSchedulingService(repo, sessions, new(mocks.MockUserRepository), &fakeReminderQueue{}, tt.now)
+			rsvp, err := service.RSVP(context.Background(), "schedule-1", "player-1", &tt.request)
+
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				tt.validate(t, rsvp)
+			}
+
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSchedulingService_GetUpcoming(t *testing.T) {
+	start := time.Date(2024, 5, 3, 18, 0, 0, 0, time.UTC)
+	repo := new(MockScheduleRepository)
+	sessions := new(mocks.MockGameSessionRepository)
+	setupSchedulingSession(sessions)
+	repo.On("ListSchedules", mock.Anything, "session-1").Return([]*models.SessionSchedule{{
+		ID: "schedule-1", SessionID: "session-1", Title: "Weekly game", StartsAt: start, Timezone: "UTC",
+		Recurrence: models.RecurrenceWeekly, Interval: 1, DurationMinutes: 180,
+	}}, nil)
+	repo.On("ListRSVPs", mock.Anything, "schedule-1", start, start.Add(7*24*time.Hour)).Return([]*models.SessionRSVP{
+		{ScheduleID: "schedule-1", StartsAt: start, UserID: "player-1", Response: models.RSVPYes},
+		{ScheduleID: "schedule-1", StartsAt: start, UserID: "dm-1", Response: models.RSVPYes},
+	}, nil)
+
+	service := createTestSchedulingService(repo, sessions, new(mocks.MockUserRepository), &fakeReminderQueue{}, start)
+	upcoming, err := service.GetUpcoming(context.Background(), "session-1", start, start.Add(10*24*time.Hour))
+
+	require.NoError(t, err)
+	require.Len(t, upcoming, 2)
+	assert.Equal(t, start.Add(3*time.Hour), upcoming[0].EndsAt)
+	assert.Equal(t, 2, upcoming[0].Counts[models.RSVPYes])
+	assert.Equal(t, []string{"player-2"}, upcoming[0].Pending)
+	assert.Equal(t, []string{"dm-1", "player-1", "player-2"}, upcoming[1].Pending)
+}
+
+// pollOptions offers three evenings that player-1 and player-2 have voted on
+func pollOptions() []*models.PollOption {
+	return []*models.PollOption{
+		{ID: "friday", StartsAt: time.Date(2024, 5, 3, 18, 0, 0, 0, time.UTC), Votes: []*models.PollVote{
+			{UserID: "player-1", Response: models.RSVPYes}, {UserID: "player-2", Response: models.RSVPNo},
+		}},
+		{ID: "saturday", StartsAt: time.Date(2024, 5, 4, 18, 0, 0, 0, time.UTC), Votes: []*models.PollVote{
+			{UserID: "player-1", Response: models.RSVPYes}, {UserID: "player-2", Response: models.RSVPMaybe},
+		}},
+		{ID: "sunday", StartsAt: time.Date(2024, 5, 5, 18, 0, 0, 0, time.UTC), Votes: []*models.PollVote{
+			{UserID: "player-1", Response: models.RSVPYes}, {UserID: "player-2", Response: models.RSVPMaybe},
+		}},
+	}
+}
+
+func TestRankPollOptions(t *testing.T) {
+	poll := &models.AvailabilityPoll{Options: pollOptions()}
+
+	rankPollOptions(poll)
+
+	assert.Equal(t, 3, poll.Options[0].Rank)
+	assert.Equal(t, 1, poll.Options[1].Rank, "a maybe beats a no, and the earlier date breaks ties")
+	assert.Equal(t, 2, poll.Options[2].Rank)
+	assert.Equal(t, 1, poll.Options[1].Counts[models.RSVPMaybe])
+}
+
+func TestSchedulingService_ClosePoll(t *testing.T) {
+	repo := new(MockScheduleRepository)
+	sessions := new(mocks.MockGameSessionRepository)
+	setupSchedulingSession(sessions)
+	repo.On("GetPoll", mock.Anything, "poll-1").Return(&models.AvailabilityPoll{
+		ID: "poll-1", SessionID: "session-1", Title: "One-shot", Timezone: "UTC", DurationMinutes: 180,
+		Status: models.AvailabilityPollOpen, Options: pollOptions(),
+	}, nil)
+	repo.On("CreateSchedule", mock.Anything, mock.MatchedBy(func(schedule *models.SessionSchedule) bool {
+		return schedule.StartsAt.Equal(time.Date(2024, 5, 4, 18, 0, 0, 0, time.UTC)) &&
+			schedule.Recurrence == models.RecurrenceNone && schedule.DurationMinutes == 180
+	})).
+		Run(func(args mock.Arguments) { args.Get(1).(*models.SessionSchedule).ID = "schedule-1" }).
+		Return(nil)
+	repo.On("ClosePoll", mock.Anything, mock.AnythingOfType("*models.AvailabilityPoll")).Return(nil)
+
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	service := createTestSchedulingService(repo, sessions, new(mocks.MockUserRepository), &fakeReminderQueue{}, now)
+	poll, err := service.ClosePoll(context.Background(), "poll-1", "dm-1", &models.ClosePollRequest{})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.AvailabilityPollClosed, poll.Status)
+	assert.Equal(t, "saturday", *poll.ChosenOptionID)
+	assert.Equal(t, "schedule-1", *poll.ScheduleID)
+	repo.AssertExpectations(t)
+}
+
+func TestSchedulingService_Vote(t *testing.T) {
+	tests := []struct {
+		name   string
+		status models.AvailabilityPollStatus
+		votes  map[string]models.RSVPResponse
+	}{
+		{
+			name:   "Option from another poll",
+			status: models.AvailabilityPollOpen,
+			votes:  map[string]models.RSVPResponse{"monday": models.RSVPYes},
+		},
+		{
+			name:   "Closed poll",
+			status: models.AvailabilityPollClosed,
+			votes:  map[string]models.RSVPResponse{"friday": models.RSVPYes},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockScheduleRepository)
+			sessions := new(mocks.MockGameSessionRepository)
+			setupSchedulingSession(sessions)
+			repo.On("GetPoll", mock.Anything, "poll-1").Return(&models.AvailabilityPoll{
+				ID: "poll-1", SessionID: "session-1", Status: tt.status, Options: pollOptions(),
+			}, nil)
+
+			service := createTestSchedulingService(repo, sessions, new(mocks.MockUserRepository), &fakeReminderQueue{}, time.Now())
+			_, err := service.Vote(context.Background(), "poll-1", "player-1", &models.PollVoteRequest{Votes: tt.votes})
+
+			assert.Error(t, err)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSchedulingService_SessionReminder(t *testing.T) {
+	start := time.Date(2024, 5, 3, 18, 0, 0, 0, time.UTC)
+	schedule := &models.SessionSchedule{ID: "schedule-1", SessionID: "session-1", Title: "Weekly game", StartsAt: start,
+		Timezone: "UTC", Recurrence: models.RecurrenceWeekly, Interval: 1, DurationMinutes: 240, ReminderMinutes: 60}
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		scheduleID string
+		startsAt   time.Time
+		setupMocks func(*MockScheduleRepository, *mocks.MockUserRepository)
+		validate   func(*testing.T, *models.SessionReminder, *fakeReminderQueue)
+	}{
+		{
+			name:       "Everyone who has not declined",
+			now:        start.Add(-time.Hour),
+			scheduleID: "schedule-1",
+			startsAt:   start,
+			setupMocks: func(repo *MockScheduleRepository, users *mocks.MockUserRepository) {
+				repo.On("GetSchedule", mock.Anything, "schedule-1").Return(schedule, nil)
+				repo.On("ListRSVPs", mock.Anything, "schedule-1", start, start).Return([]*models.SessionRSVP{
+					{ScheduleID: "schedule-1", StartsAt: start, UserID: "player-2", Response: models.RSVPNo},
+				}, nil)
+				users.On("GetByID", mock.Anything, "dm-1").Return(&models.User{ID: "dm-1", Email: "dm@example.com"}, nil)
+				users.On("GetByID", mock.Anything, "player-1").Return(&models.User{ID: "player-1", Email: "p1@example.com"}, nil)
+			},
+			validate: func(t *testing.T, reminder *models.SessionReminder, queue *fakeReminderQueue) {
+				require.NotNil(t, reminder)
+				assert.Equal(t, []string{"dm@example.com", "p1@example.com"}, reminder.To)
+				assert.Contains(t, reminder.Subject, "Curse of Strahd")
+				assert.Contains(t, reminder.Body, "not coming: 1")
+				assert.Equal(t, []time.Time{start.Add(7 * 24 * time.Hour)}, queue.starts, "the next reminder is queued")
+			},
+		},
+		{
+			name:       "Time the schedule has no session at",
+			now:        start,
+			scheduleID: "schedule-1",
+			startsAt:   start.Add(time.Hour),
+			setupMocks: func(repo *MockScheduleRepository, _ *mocks.MockUserRepository) {
+				repo.On("GetSchedule", mock.Anything, "schedule-1").Return(schedule, nil)
+			},
+			validate: func(t *testing.T, reminder *models.SessionReminder, queue *fakeReminderQueue) {
+				assert.Nil(t, reminder)
+				assert.Empty(t, queue.starts)
+			},
+		},
+		{
+			name:       "Deleted schedule",
+			now:        start,
+			scheduleID: "deleted",
+			startsAt:   start,
+			setupMocks: func(repo *MockScheduleRepository, _ *mocks.MockUserRepository) {
+				repo.On("GetSchedule", mock.Anything, "deleted").Return(nil, nil)
+			},
+			validate: func(t *testing.T, reminder *models.SessionReminder, queue *fakeReminderQueue) {
+				assert.Nil(t, reminder)
+				assert.Empty(t, queue.starts)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockScheduleRepository)
+			sessions := new(mocks.MockGameSessionRepository)
+			users := new(mocks.MockUserRepository)
+			queue := &fakeReminderQueue{}
+			setupSchedulingSession(sessions)
+			tt.setupMocks(repo, users)
+
+			service := createTestSchedulingService(repo, sessions, users, queue, tt.now)
+			reminder, err := service.SessionReminder(context.Background(), tt.scheduleID, tt.startsAt)
+
+			require.NoError(t, err)
+			tt.validate(t, reminder, queue)
+			repo.AssertExpectations(t)
+			users.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRenderICalendar(t *testing.T) {
+	berlin := mustLocation(t, "Europe/Berlin")
+	until := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	schedules := []*models.SessionSchedule{{
+		ID: "schedule-1", SessionID: "session-1", Title: "Weekly game", Notes: "Bring snacks, dice; and " + strings.Repeat("x", 80),
+		StartsAt: time.Date(2024, 5, 3, 19, 0, 0, 0, berlin), Timezone: "Europe/Berlin", DurationMinutes: 240,
+		Recurrence: models.RecurrenceWeekly, Interval: 2, Until: &until, ReminderMinutes: 60,
+	}}
+
+	calendar := string(renderICalendar("My games", map[string]string{"session-1": "Curse of Strahd"}, schedules))
+
+	assert.True(t, strings.HasPrefix(calendar, "BEGIN:VCALENDAR\r\n"))
+	assert.Contains(t, calendar, "DTSTART;TZID=Europe/Berlin:20240503T190000\r\n")
+	assert.Contains(t, calendar, "DTEND;TZID=Europe/Berlin:20240503T230000\r\n")
+	assert.Contains(t, calendar, "RRULE:FREQ=WEEKLY;INTERVAL=2;UNTIL=20241231T000000Z\r\n")
+	assert.Contains(t, calendar, "SUMMARY:Curse of Strahd: Weekly game\r\n")
+	assert.Contains(t, calendar, `DESCRIPTION:Bring snacks\, dice\; and`)
+	assert.Contains(t, calendar, "TRIGGER:-PT60M\r\n")
+	for _, line := range strings.Split(calendar, "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+}
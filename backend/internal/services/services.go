@@ -11,11 +11,27 @@ type Services struct {
 	DB                 *database.DB
 	Users              *UserService
 	Characters         *CharacterService
+	CharacterVersions  *CharacterVersionService
 	GameSessions       *GameSessionService
 	DiceRolls          *DiceRollService
+	Chat               *ChatService
+	GameEvents         *GameEventService
+	Scheduling         *SchedulingService
+	GameInvites        *GameInviteService
 	Combat             *CombatService
 	NPCs               *NPCService
 	Inventory          *InventoryService
+	ItemCatalog        *ItemCatalog
+	ItemLibrary        *ItemLibraryService
+	Shops              *ShopService
+	Parties            *PartyService
+	Crafting           *CraftingService
+	Loot               *LootService
+	Treasure           *TreasureService
+	StartingEquipment  *StartingEquipmentService
+	CharacterResources *CharacterResourceService
+	SpellManagement    *SpellManagementService
+	CharacterExport    *CharacterExportService
 	CustomRaces        *CustomRaceService
 	DMAssistant        *DMAssistantService
 	Encounters         *EncounterService
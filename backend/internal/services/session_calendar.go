@@ -0,0 +1,105 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+const (
+	icalDateTime    = "20060102T150405"
+	icalMaxLineSize = 75
+)
+
+// icalEscaper escapes text values as RFC 5545 requires
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// renderICalendar renders schedules as an iCalendar (RFC 5545) document, one event per
+// schedule. Recurring schedules become recurrence rules anchored to their timezone, so
+// calendar apps keep their local start time across daylight saving changes.
+func renderICalendar(name string, sessionNames map[string]string, schedules []*models.SessionSchedule) []byte {
+	var b strings.Builder
+	line := func(format string, args ...interface{}) {
+		writeICalLine(&b, fmt.Sprintf(format, args...))
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//DnD Game//Session Scheduler//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:%s", icalEscaper.Replace(name))
+	for _, schedule := range schedules {
+		loc := scheduleLocation(schedule)
+		start := schedule.StartsAt.In(loc)
+		end := start.Add(time.Duration(schedule.DurationMinutes) * time.Minute)
+
+		line("BEGIN:VEVENT")
+		line("UID:%s@dnd-game", schedule.ID)
+		line("DTSTAMP:%sZ", schedule.UpdatedAt.UTC().Format(icalDateTime))
+		line("DTSTART;TZID=%s:%s", loc.String(), start.Format(icalDateTime))
+		line("DTEND;TZID=%s:%s", loc.String(), end.Format(icalDateTime))
+		if rule := icalRecurrenceRule(schedule); rule != "" {
+			line("RRULE:%s", rule)
+		}
+		summary := schedule.Title
+		if session := sessionNames[schedule.SessionID]; session != "" && session != schedule.Title {
+			summary = session + ": " + schedule.Title
+		}
+		line("SUMMARY:%s", icalEscaper.Replace(summary))
+		if schedule.Notes != "" {
+			line("DESCRIPTION:%s", icalEscaper.Replace(schedule.Notes))
+		}
+		if schedule.ReminderMinutes > 0 {
+			line("BEGIN:VALARM")
+			line("ACTION:DISPLAY")
+			line("DESCRIPTION:%s", icalEscaper.Replace(summary))
+			line("TRIGGER:-PT%dM", schedule.ReminderMinutes)
+			line("END:VALARM")
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return []byte(b.String())
+}
+
+// icalRecurrenceRule is the RRULE for a schedule, or "" for a one-off session. A monthly
+// rule on a fixed day skips months without it, just as the schedule does.
+func icalRecurrenceRule(schedule *models.SessionSchedule) string {
+	var rule string
+	switch schedule.Recurrence {
+	case models.RecurrenceWeekly:
+		rule = "FREQ=WEEKLY"
+	case models.RecurrenceMonthly:
+		rule = fmt.Sprintf("FREQ=MONTHLY;BYMONTHDAY=%d", schedule.StartsAt.In(scheduleLocation(schedule)).Day())
+	default:
+		return ""
+	}
+	if schedule.Interval > 1 {
+		rule += fmt.Sprintf(";INTERVAL=%d", schedule.Interval)
+	}
+	if schedule.Until != nil {
+		rule += ";UNTIL=" + schedule.Until.UTC().Format(icalDateTime) + "Z"
+	}
+	return rule
+}
+
+// writeICalLine writes a content line, folded so no line is longer than 75 octets, without
+// splitting a UTF-8 character. Each folded line starts with a space.
+func writeICalLine(b *strings.Builder, line string) {
+	limit := icalMaxLineSize
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = icalMaxLineSize - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,387 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/auth"
+	"github.com/ctclostio/DnD-Game/backend/internal/database"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/dice"
+	"github.com/ctclostio/DnD-Game/backend/pkg/logger"
+)
+
+// Shop pricing
+const (
+	// shopBuybackRate is the share of its asking price a shop pays for goods it buys
+	shopBuybackRate = 0.5
+	// haggleAdjustment is the price change a successful Persuasion check earns, doubled when beating the DC by 5
+	haggleAdjustment = 0.1
+	// shopRestockQuantity is how many of each catalog item a restocked shop carries
+	shopRestockQuantity = 5
+)
+
+// ShopRepository covers settlement shops, their stock and the player trade volume they generate
+type ShopRepository interface {
+	MarketTradeRepository
+	GetSettlement(id uuid.UUID) (*models.Settlement, error)
+	GetSettlementShop(id uuid.UUID) (*models.SettlementShop, error)
+	GetShopStock(shopID uuid.UUID) ([]*models.ShopStockItem, error)
+	SetShopStock(shopID uuid.UUID, itemID string, quantity int) error
+}
+
+// ShopService trades items between characters and settlement shops at prices set by
+// the settlement's economy
+type ShopService struct {
+	shopRepo      ShopRepository
+	economy       *EconomicSimulatorService
+	inventory     *InventoryService
+	inventoryRepo database.InventoryRepository
+	characterRepo database.CharacterRepository
+	catalog       *ItemCatalog
+	roller        *dice.Roller
+}
+
+// NewShopService creates a new shop service
+func NewShopService(shopRepo ShopRepository, economy *EconomicSimulatorService, inventory *InventoryService, inventoryRepo database.InventoryRepository, characterRepo database.CharacterRepository) *ShopService {
+	return &ShopService{
+		shopRepo:      shopRepo,
+		economy:       economy,
+		inventory:     inventory,
+		inventoryRepo: inventoryRepo,
+		characterRepo: characterRepo,
+		roller:        dice.NewRoller(),
+	}
+}
+
+// SetItemCatalog enables restocking shops from the item catalog
+func (s *ShopService) SetItemCatalog(catalog *ItemCatalog) {
+	s.catalog = catalog
+}
+
+// GetShop returns a shop with its stock at current asking prices
+func (s *ShopService) GetShop(shopID uuid.UUID) (*models.ShopDetails, error) {
+	shop, err := s.shopRepo.GetSettlementShop(shopID)
+	if err != nil {
+		return nil, fmt.Errorf("shop not found: %w", err)
+	}
+
+	stock, err := s.shopRepo.GetShopStock(shopID)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range stock {
+		item, err := s.inventoryRepo.GetItem(entry.ItemID)
+		if err != nil || item == nil {
+			continue
+		}
+		entry.Item = item
+		entry.UnitPrice, _ = s.unitPrice(shop, item, models.ShopTradeBuy)
+	}
+
+	return &models.ShopDetails{Shop: shop, Stock: stock}, nil
+}
+
+// GetShopSessionID returns the game session the shop's settlement belongs to
+func (s *ShopService) GetShopSessionID(shopID uuid.UUID) (string, error) {
+	shop, err := s.shopRepo.GetSettlementShop(shopID)
+	if err != nil {
+		return "", fmt.Errorf("shop not found: %w", err)
+	}
+	settlement, err := s.shopRepo.GetSettlement(shop.SettlementID)
+	if err != nil {
+		return "", fmt.Errorf("settlement not found: %w", err)
+	}
+	return settlement.GameSessionID.String(), nil
+}
+
+// Quote prices a trade without making it
+func (s *ShopService) Quote(shopID uuid.UUID, itemID string, quantity int, side models.ShopTradeSide) (*models.ShopQuote, error) {
+	shop, err := s.shopRepo.GetSettlementShop(shopID)
+	if err != nil {
+		return nil, fmt.Errorf("shop not found: %w", err)
+	}
+	return s.quote(shop, itemID, quantity, side)
+}
+
+// Buy sells items from a shop's stock to a character. The coins, the character's
+// inventory and the shop's stock change in one transaction.
+func (s *ShopService) Buy(ctx context.Context, shopID uuid.UUID, req *models.ShopTradeRequest) (*models.ShopTradeResult, error) {
+	return s.trade(ctx, shopID, req, models.ShopTradeBuy)
+}
+
+// Sell buys items from a character into a shop's stock
+func (s *ShopService) Sell(ctx context.Context, shopID uuid.UUID, req *models.ShopTradeRequest) (*models.ShopTradeResult, error) {
+	return s.trade(ctx, shopID, req, models.ShopTradeSell)
+}
+
+// Restock fills a shop with catalog goods that suit its type
+func (s *ShopService) Restock(shopID uuid.UUID) (*models.ShopDetails, error) {
+	if s.catalog == nil {
+		return nil, fmt.Errorf("item catalog is not available")
+	}
+	shop, err := s.shopRepo.GetSettlementShop(shopID)
+	if err != nil {
+		return nil, fmt.Errorf("shop not found: %w", err)
+	}
+
+	for _, catalogItem := range s.catalog.Items() {
+		if !shopCarries(shop, catalogItem) {
+			continue
+		}
+		item, err := s.ensureCatalogItem(catalogItem)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.shopRepo.SetShopStock(shopID, item.ID, shopRestockQuantity); err != nil {
+			return nil, fmt.Errorf("failed to stock %s: %w", item.Name, err)
+		}
+	}
+
+	return s.GetShop(shopID)
+}
+
+func (s *ShopService) trade(ctx context.Context, shopID uuid.UUID, req *models.ShopTradeRequest, side models.ShopTradeSide) (*models.ShopTradeResult, error) {
+	shop, err := s.shopRepo.GetSettlementShop(shopID)
+	if err != nil {
+		return nil, fmt.Errorf("shop not found: %w", err)
+	}
+	quote, err := s.quote(shop, req.ItemID, req.Quantity, side)
+	if err != nil {
+		return nil, err
+	}
+	if side == models.ShopTradeBuy && quote.InStock < quote.Quantity {
+		return nil, fmt.Errorf("%s only has %d %s in stock", shop.Name, quote.InStock, quote.ItemName)
+	}
+
+	result := &models.ShopTradeResult{Quote: quote}
+	if req.Haggle {
+		character, err := s.characterRepo.GetByID(ctx, req.CharacterID)
+		if err != nil {
+			return nil, err
+		}
+		haggle, err := s.haggle(character, shop)
+		if err != nil {
+			return nil, err
+		}
+		result.Haggle = haggle
+		if haggle.Success {
+			multiplier := 1 - haggle.Adjustment
+			if side == models.ShopTradeSell {
+				multiplier = 1 + haggle.Adjustment
+			}
+			quote.Factors = append(quote.Factors, models.PriceFactor{Name: "haggling", Multiplier: multiplier})
+			quote.UnitPrice = int(math.Round(float64(quote.UnitPrice) * multiplier))
+			quote.TotalPrice = quote.UnitPrice * quote.Quantity
+		}
+	}
+
+	// Coins and items flow one way, stock the other
+	direction := 1
+	verb := "bought"
+	preposition := "from"
+	entryType := models.LedgerEntryPurchase
+	if side == models.ShopTradeSell {
+		direction = -1
+		verb = "sold"
+		preposition = "to"
+		entryType = models.LedgerEntrySale
+	}
+
+	txn := &models.EconomyTransaction{
+		Type:        entryType,
+		Description: fmt.Sprintf("%s %d x %s %s %s", verb, quote.Quantity, quote.ItemName, preposition, shop.Name),
+		Currency:    []models.CurrencyMovement{{CharacterID: req.CharacterID, Coins: models.CoinsFromCopper(quote.TotalPrice)}},
+		Items:       []models.ItemMovement{{CharacterID: req.CharacterID, ItemID: quote.ItemID, Quantity: direction * quote.Quantity}},
+		ShopStock:   []models.ShopStockMovement{{ShopID: shop.ID, ItemID: quote.ItemID, Quantity: -direction * quote.Quantity}},
+	}
+	if side == models.ShopTradeBuy {
+		txn.Currency[0].Coins = models.Coins{Copper: -quote.TotalPrice}
+	}
+	txn.CreatedBy, _ = auth.GetUserIDFromContext(ctx)
+
+	entries, err := s.inventory.applyTransaction(ctx, txn)
+	if err != nil {
+		return nil, err
+	}
+	result.Ledger = entries
+
+	bought, sold := quote.Quantity, 0
+	if side == models.ShopTradeSell {
+		bought, sold = 0, quote.Quantity
+	}
+	if err := s.economy.RecordPlayerTrade(shop.SettlementID, quote.Category, bought, sold); err != nil {
+		logger.WithContext(ctx).WithError(err).WithField("shop_id", shop.ID.String()).Warn().Msg("Failed to record player trade")
+	}
+
+	return result, nil
+}
+
+func (s *ShopService) quote(shop *models.SettlementShop, itemID string, quantity int, side models.ShopTradeSide) (*models.ShopQuote, error) {
+	if quantity < 1 {
+		return nil, fmt.Errorf("quantity must be positive")
+	}
+	if side != models.ShopTradeBuy && side != models.ShopTradeSell {
+		return nil, fmt.Errorf("invalid trade side: %s", side)
+	}
+
+	item, err := s.inventoryRepo.GetItem(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, fmt.Errorf(errMsgItemNotFound)
+	}
+
+	inStock := 0
+	stock, err := s.shopRepo.GetShopStock(shop.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range stock {
+		if entry.ItemID == itemID {
+			inStock = entry.Quantity
+			break
+		}
+	}
+
+	unitPrice, factors := s.unitPrice(shop, item, side)
+	return &models.ShopQuote{
+		ShopID:     shop.ID,
+		ItemID:     item.ID,
+		ItemName:   item.Name,
+		Side:       side,
+		Category:   marketCategory(item),
+		Quantity:   quantity,
+		InStock:    inStock,
+		BasePrice:  item.Value,
+		UnitPrice:  unitPrice,
+		TotalPrice: unitPrice * quantity,
+		Factors:    factors,
+	}, nil
+}
+
+// unitPrice asks the settlement's economy for the item's price and applies the shop's own markup
+func (s *ShopService) unitPrice(shop *models.SettlementShop, item *models.Item, side models.ShopTradeSide) (int, []models.PriceFactor) {
+	price, factors := s.economy.QuoteItemPrice(shop.SettlementID, float64(item.Value), marketCategory(item))
+
+	if shop.PriceModifier > 0 && shop.PriceModifier != 1.0 {
+		price *= shop.PriceModifier
+		factors = append(factors, models.PriceFactor{Name: "shop", Multiplier: shop.PriceModifier})
+	}
+	if side == models.ShopTradeSell {
+		price *= shopBuybackRate
+		factors = append(factors, models.PriceFactor{Name: "buyback", Multiplier: shopBuybackRate})
+	}
+
+	unitPrice := int(math.Round(price))
+	if unitPrice < 1 && item.Value > 0 && side == models.ShopTradeBuy {
+		unitPrice = 1
+	}
+	return unitPrice, factors
+}
+
+// haggle rolls the character's Persuasion against the shopkeeper. Better shops drive harder bargains.
+func (s *ShopService) haggle(character *models.Character, shop *models.SettlementShop) (*models.HaggleResult, error) {
+	roll, err := s.roller.Roll("1d20")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.HaggleResult{
+		Roll:     roll.Total,
+		Modifier: persuasionModifier(character),
+		DC:       10 + shop.QualityLevel,
+	}
+	result.Total = result.Roll + result.Modifier
+	result.Success = result.Total >= result.DC
+	if result.Success {
+		result.Adjustment = haggleAdjustment
+		if result.Total >= result.DC+5 {
+			result.Adjustment *= 2
+		}
+	}
+	return result, nil
+}
+
+func (s *ShopService) ensureCatalogItem(catalogItem *CatalogItem) (*models.Item, error) {
+	return ensureCatalogItem(s.inventoryRepo, catalogItem)
+}
+
+// ensureCatalogItem returns the items table row for a catalog item, creating it on first use
+func ensureCatalogItem(inventoryRepo database.InventoryRepository, catalogItem *CatalogItem) (*models.Item, error) {
+	item, err := inventoryRepo.GetItem(catalogItem.ID)
+	if err != nil {
+		return nil, err
+	}
+	if item != nil {
+		return item, nil
+	}
+
+	item = catalogItem.ToItem()
+	if err := inventoryRepo.CreateItem(item); err != nil {
+		return nil, fmt.Errorf("failed to create item %s: %w", item.Name, err)
+	}
+	return item, nil
+}
+
+// persuasionModifier uses the character's Persuasion skill, falling back to Charisma
+func persuasionModifier(character *models.Character) int {
+	modifier := getModifier(character.Attributes.Charisma)
+	for _, skill := range character.Skills {
+		if !strings.EqualFold(skill.Name, "persuasion") {
+			continue
+		}
+		if skill.Modifier != 0 {
+			return skill.Modifier
+		}
+		if skill.Proficiency {
+			modifier += character.ProficiencyBonus
+		}
+	}
+	return modifier
+}
+
+// marketCategory maps an item to the goods category the settlement's market prices it by
+func marketCategory(item *models.Item) string {
+	switch {
+	case item.Rarity != "" && item.Rarity != models.ItemRarityCommon, item.Type == models.ItemTypeMagic:
+		return "magic"
+	case item.Type == models.ItemTypeWeapon:
+		return "weapon"
+	case item.Type == models.ItemTypeArmor:
+		return "armor"
+	case strings.Contains(strings.ToLower(item.Name), "ration"):
+		return "food"
+	default:
+		return "common"
+	}
+}
+
+// shopCarries reports whether a shop of this type stocks the catalog item
+func shopCarries(shop *models.SettlementShop, item *CatalogItem) bool {
+	if item.Value <= 0 {
+		return false
+	}
+	category := marketCategory(item.ToItem())
+	switch shop.Type {
+	case "weaponsmith":
+		return category == "weapon"
+	case "armorer":
+		return category == "armor"
+	case "alchemist", "herbalist":
+		return item.Type == models.ItemTypeConsumable || item.HasTag(reagentTag)
+	case "magic", "enchanter", "artificer":
+		return category == "magic" || item.HasTag(reagentTag)
+	case "general", "inn", "tavern":
+		return category == "common" || category == "food"
+	case "grand bazaar":
+		return category != "magic"
+	default:
+		return false
+	}
+}
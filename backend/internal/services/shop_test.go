@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/internal/services/mocks"
+)
+
+// MockShopRepository mocks shop stock and player trade volume storage
+type MockShopRepository struct {
+	mock.Mock
+}
+
+func (m *MockShopRepository) GetSettlement(id uuid.UUID) (*models.Settlement, error) {
+	args := m.Called(id)
+	return mockSingleReturn[models.Settlement](args, 0, 1)
+}
+
+func (m *MockShopRepository) GetSettlementShop(id uuid.UUID) (*models.SettlementShop, error) {
+	args := m.Called(id)
+	return mockSingleReturn[models.SettlementShop](args, 0, 1)
+}
+
+func (m *MockShopRepository) GetShopStock(shopID uuid.UUID) ([]*models.ShopStockItem, error) {
+	args := m.Called(shopID)
+	return mockSliceReturn[models.ShopStockItem](args, 0, 1)
+}
+
+func (m *MockShopRepository) SetShopStock(shopID uuid.UUID, itemID string, quantity int) error {
+	args := m.Called(shopID, itemID, quantity)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockShopRepository) RecordMarketTrade(settlementID uuid.UUID, category string, bought, sold int) error {
+	args := m.Called(settlementID, category, bought, sold)
+	return mockErrorReturn(args, 0)
+}
+
+func (m *MockShopRepository) GetMarketTradeVolumes(settlementID uuid.UUID) ([]*models.MarketTradeVolume, error) {
+	args := m.Called(settlementID)
+	return mockSliceReturn[models.MarketTradeVolume](args, 0, 1)
+}
+
+func (m *MockShopRepository) DecayMarketTradeVolumes(settlementID uuid.UUID) error {
+	args := m.Called(settlementID)
+	return mockErrorReturn(args, 0)
+}
+
+var (
+	testShopID       = uuid.MustParse("5b0c3f0e-7d8a-4c1e-9a7b-2f4d6e8a1c3b")
+	testSettlementID = uuid.MustParse("9e2d4a6c-1b3f-4e5a-8c7d-0f1e2d3c4b5a")
+)
+
+func createTestShopService(shopRepo *MockShopRepository, worldRepo *MockWorldBuildingRepository, inventoryRepo *mocks.MockInventoryRepository, characterRepo *mocks.MockCharacterRepository) *ShopService {
+	economy := NewEconomicSimulatorService(worldRepo)
+	economy.SetTradeRepository(shopRepo)
+	inventory := NewInventoryService(inventoryRepo, characterRepo)
+	return NewShopService(shopRepo, economy, inventory, inventoryRepo, characterRepo)
+}
+
+// setupShopStock stocks the Rusty Nail with three coils of rope in a settlement whose
+// market marks common goods up by a fifth
+func setupShopStock(shopRepo *MockShopRepository, worldRepo *MockWorldBuildingRepository, inventoryRepo *mocks.MockInventoryRepository, volumes []*models.MarketTradeVolume) {
+	shopRepo.On("GetSettlementShop", testShopID).Return(&models.SettlementShop{
+		ID:            testShopID,
+		SettlementID:  testSettlementID,
+		Name:          "The Rusty Nail",
+		Type:          "general",
+		QualityLevel:  4,
+		PriceModifier: 1.1,
+	}, nil)
+	shopRepo.On("GetShopStock", testShopID).Return([]*models.ShopStockItem{{ShopID: testShopID, ItemID: "rope", Quantity: 3}}, nil)
+	shopRepo.On("GetMarketTradeVolumes", testSettlementID).Return(volumes, nil).Maybe()
+	worldRepo.On("GetMarketBySettlement", testSettlementID).Return(&models.Market{
+		SettlementID:        testSettlementID,
+		CommonGoodsModifier: 1.2,
+	}, nil)
+	inventoryRepo.On("GetItem", "rope").Return(&models.Item{ID: "rope", Name: "Rope", Type: models.ItemTypeOther, Rarity: models.ItemRarityCommon, Value: 100}, nil)
+}
+
+func TestShopService_Quote(t *testing.T) {
+	tests := []struct {
+		name        string
+		quantity    int
+		side        models.ShopTradeSide
+		volumes     []*models.MarketTradeVolume
+		expectError bool
+		validate    func(*testing.T, *models.ShopQuote)
+	}{
+		{
+			name:     "Buying applies market and shop markups",
+			quantity: 2,
+			side:     models.ShopTradeBuy,
+			validate: func(t *testing.T, quote *models.ShopQuote) {
+				assert.Equal(t, "common", quote.Category)
+				assert.Equal(t, 3, quote.InStock)
+				assert.Equal(t, 132, quote.UnitPrice)
+				assert.Equal(t, 264, quote.TotalPrice)
+				require.Len(t, quote.Factors, 2)
+				assert.Equal(t, "market", quote.Factors[0].Name)
+				assert.Equal(t, "shop", quote.Factors[1].Name)
+			},
+		},
+		{
+			name:     "Selling pays half the asking price",
+			quantity: 1,
+			side:     models.ShopTradeSell,
+			validate: func(t *testing.T, quote *models.ShopQuote) {
+				assert.Equal(t, 66, quote.UnitPrice)
+			},
+		},
+		{
+			name:     "Players buying up goods raises the price",
+			quantity: 1,
+			side:     models.ShopTradeBuy,
+			volumes: []*models.MarketTradeVolume{
+				{SettlementID: testSettlementID, Category: "common", UnitsBought: 20},
+			},
+			validate: func(t *testing.T, quote *models.ShopQuote) {
+				assert.Equal(t, 185, quote.UnitPrice)
+				assert.Equal(t, "player trade", quote.Factors[1].Name)
+			},
+		},
+		{
+			name:        "Non-positive quantity",
+			quantity:    0,
+			side:        models.ShopTradeBuy,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shopRepo := new(MockShopRepository)
+			worldRepo := new(MockWorldBuildingRepository)
+			inventoryRepo := new(mocks.MockInventoryRepository)
+			characterRepo := new(mocks.MockCharacterRepository)
+			setupShopStock(shopRepo, worldRepo, inventoryRepo, tt.volumes)
+
+			service := createTestShopService(shopRepo, worldRepo, inventoryRepo, characterRepo)
+			quote, err := service.Quote(testShopID, "rope", tt.quantity, tt.side)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.validate != nil {
+				tt.validate(t, quote)
+			}
+		})
+	}
+}
+
+func TestShopService_Buy(t *testing.T) {
+	tests := []struct {
+		name        string
+		request     *models.ShopTradeRequest
+		setupMocks  func(*MockShopRepository, *mocks.MockInventoryRepository, *mocks.MockCharacterRepository)
+		expectError string
+		validate    func(*testing.T, *models.ShopTradeResult)
+	}{
+		{
+			name:    "Coins, items and stock move in one transaction",
+			request: &models.ShopTradeRequest{CharacterID: "char-1", ItemID: "rope", Quantity: 2},
+			setupMocks: func(shopRepo *MockShopRepository, inventoryRepo *mocks.MockInventoryRepository, _ *mocks.MockCharacterRepository) {
+				inventoryRepo.On("ApplyTransaction", mock.MatchedBy(func(txn *models.EconomyTransaction) bool {
+					return txn.Type == models.LedgerEntryPurchase &&
+						len(txn.Currency) == 1 && txn.Currency[0].Coins.TotalInCopper() == -264 &&
+						len(txn.Items) == 1 && txn.Items[0].Quantity == 2 &&
+						len(txn.ShopStock) == 1 && txn.ShopStock[0].ShopID == testShopID && txn.ShopStock[0].Quantity == -2
+				})).Return([]*models.LedgerEntry{{CharacterID: "char-1"}}, nil)
+				shopRepo.On("RecordMarketTrade", testSettlementID, "common", 2, 0).Return(nil)
+			},
+			validate: func(t *testing.T, result *models.ShopTradeResult) {
+				assert.Equal(t, 264, result.Quote.TotalPrice)
+				assert.Len(t, result.Ledger, 1)
+			},
+		},
+		{
+			name:        "More than the shop has in stock",
+			request:     &models.ShopTradeRequest{CharacterID: "char-1", ItemID: "rope", Quantity: 4},
+			expectError: "in stock",
+		},
+		{
+			name:    "Successful haggling lowers the price",
+			request: &models.ShopTradeRequest{CharacterID: "char-1", ItemID: "rope", Quantity: 1, Haggle: true},
+			setupMocks: func(shopRepo *MockShopRepository, inventoryRepo *mocks.MockInventoryRepository, characterRepo *mocks.MockCharacterRepository) {
+				characterRepo.On("GetByID", mock.Anything, "char-1").Return(&models.Character{
+					ID:     "char-1",
+					Skills: []models.Skill{{Name: "Persuasion", Modifier: 40}},
+				}, nil)
+				inventoryRepo.On("ApplyTransaction", mock.MatchedBy(func(txn *models.EconomyTransaction) bool {
+					return txn.Currency[0].Coins.TotalInCopper() == -106
+				})).Return([]*models.LedgerEntry{{CharacterID: "char-1"}}, nil)
+				shopRepo.On("RecordMarketTrade", testSettlementID, "common", 1, 0).Return(nil)
+			},
+			validate: func(t *testing.T, result *models.ShopTradeResult) {
+				require.NotNil(t, result.Haggle)
+				assert.True(t, result.Haggle.Success)
+				assert.Equal(t, 14, result.Haggle.DC)
+				assert.InDelta(t, 0.2, result.Haggle.Adjustment, 0.001)
+				assert.Equal(t, 106, result.Quote.UnitPrice)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shopRepo := new(MockShopRepository)
+			worldRepo := new(MockWorldBuildingRepository)
+			inventoryRepo := new(mocks.MockInventoryRepository)
+			characterRepo := new(mocks.MockCharacterRepository)
+			setupShopStock(shopRepo, worldRepo, inventoryRepo, []*models.MarketTradeVolume{})
+			if tt.setupMocks != nil {
+				tt.setupMocks(shopRepo, inventoryRepo, characterRepo)
+			}
+
+			service := createTestShopService(shopRepo, worldRepo, inventoryRepo, characterRepo)
+			result, err := service.Buy(context.Background(), testShopID, tt.request)
+
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+			} else {
+				require.NoError(t, err)
+				if tt.validate != nil {
+					tt.validate(t, result)
+				}
+			}
+
+			shopRepo.AssertExpectations(t)
+			inventoryRepo.AssertExpectations(t)
+			characterRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestShopService_Sell(t *testing.T) {
+	shopRepo := new(MockShopRepository)
+	worldRepo := new(MockWorldBuildingRepository)
+	inventoryRepo := new(mocks.MockInventoryRepository)
+	characterRepo := new(mocks.MockCharacterRepository)
+	setupShopStock(shopRepo, worldRepo, inventoryRepo, []*models.MarketTradeVolume{})
+	inventoryRepo.On("ApplyTransaction", mock.MatchedBy(func(txn *models.EconomyTransaction) bool {
+		return txn.Type == models.LedgerEntrySale &&
+			txn.Currency[0].Coins.TotalInCopper() == 66 &&
+			txn.Items[0].Quantity == -1 &&
+			txn.ShopStock[0].Quantity == 1
+	})).Return([]*models.LedgerEntry{{CharacterID: "char-1"}}, nil)
+	shopRepo.On("RecordMarketTrade", testSettlementID, "common", 0, 1).Return(nil)
+
+	service := createTestShopService(shopRepo, worldRepo, inventoryRepo, characterRepo)
+	result, err := service.Sell(context.Background(), testShopID, &models.ShopTradeRequest{CharacterID: "char-1", ItemID: "rope", Quantity: 1})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.ShopTradeSell, result.Quote.Side)
+	shopRepo.AssertExpectations(t)
+	inventoryRepo.AssertExpectations(t)
+}
+
+func TestMarketCategory(t *testing.T) {
+	tests := []struct {
+		item     *models.Item
+		expected string
+	}{
+		{&models.Item{Name: "Longsword", Type: models.ItemTypeWeapon, Rarity: models.ItemRarityCommon}, "weapon"},
+		{&models.Item{Name: "Chain Mail", Type: models.ItemTypeArmor, Rarity: models.ItemRarityCommon}, "armor"},
+		{&models.Item{Name: "Longsword +1", Type: models.ItemTypeWeapon, Rarity: models.ItemRarityUncommon}, "magic"},
+		{&models.Item{Name: "Rations (1 day)", Type: models.ItemTypeOther}, "food"},
+		{&models.Item{Name: "Torch", Type: models.ItemTypeOther}, "common"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.item.Name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, marketCategory(tt.item))
+		})
+	}
+}
+
+func TestPersuasionModifier(t *testing.T) {
+	character := &models.Character{ProficiencyBonus: 2}
+	character.Attributes.Charisma = 14
+	assert.Equal(t, 2, persuasionModifier(character))
+
+	character.Skills = []models.Skill{{Name: "Persuasion", Proficiency: true}}
+	assert.Equal(t, 4, persuasionModifier(character))
+
+	character.Skills = []models.Skill{{Name: "Persuasion", Modifier: 7, Proficiency: true}}
+	assert.Equal(t, 7, persuasionModifier(character))
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+// SpellComponents lists the components a spell requires
+type SpellComponents struct {
+	Verbal              bool   `json:"verbal"`
+	Somatic             bool   `json:"somatic"`
+	Material            bool   `json:"material"`
+	MaterialDescription string `json:"materialDescription,omitempty"`
+	MaterialCost        int    `json:"materialCost,omitempty"` // in gold pieces
+	MaterialItem        string `json:"materialItem,omitempty"` // item catalog ID
+	MaterialConsumed    bool   `json:"materialConsumed,omitempty"`
+}
+
+// String renders the components in the usual "V, S, M (...)" form
+func (c SpellComponents) String() string {
+	var parts []string
+	if c.Verbal {
+		parts = append(parts, "V")
+	}
+	if c.Somatic {
+		parts = append(parts, "S")
+	}
+	if c.Material {
+		if c.MaterialDescription != "" {
+			parts = append(parts, fmt.Sprintf("M (%s)", c.MaterialDescription))
+		} else {
+			parts = append(parts, "M")
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SpellDamage is the damage a spell deals when it lands. Damage with a trigger, such as
+// Hex's "on hit", rides on other attacks rather than being dealt by the casting itself.
+type SpellDamage struct {
+	Type    string `json:"type"`
+	Dice    string `json:"dice"`
+	Trigger string `json:"trigger,omitempty"`
+	PerDart bool   `json:"perDart,omitempty"`
+	Darts   int    `json:"darts,omitempty"`
+}
+
+// SpellHealing is the hit points a spell restores. A "spellcasting" modifier adds the
+// caster's spellcasting ability modifier to the roll.
+type SpellHealing struct {
+	Dice     string `json:"dice"`
+	Modifier string `json:"modifier,omitempty"`
+}
+
+// SpellSavingThrow is the save a spell's target makes and what a success does
+type SpellSavingThrow struct {
+	Ability string `json:"ability"`
+	Effect  string `json:"effect,omitempty"` // e.g. "half damage"; anything else negates
+}
+
+// SpellDefinition is a spell loaded from data/spells
+type SpellDefinition struct {
+	ID            string          `json:"id"`
+	Name          string          `json:"name"`
+	Level         int             `json:"level"`
+	School        string          `json:"school"`
+	CastingTime   string          `json:"castingTime"`
+	Range         string          `json:"range"`
+	Components    SpellComponents `json:"components"`
+	Duration      string          `json:"duration"`
+	Concentration bool            `json:"concentration,omitempty"`
+	Ritual        bool            `json:"ritual,omitempty"`
+	Classes       []string        `json:"classes"`
+	Description   string          `json:"description"`
+
+	Damage      *SpellDamage      `json:"damage,omitempty"`
+	Healing     *SpellHealing     `json:"healing,omitempty"`
+	AttackType  string            `json:"attackType,omitempty"`
+	SavingThrow *SpellSavingThrow `json:"savingThrow,omitempty"`
+}
+
+// ToSpell converts a definition into the spell entry stored on a character
+func (d *SpellDefinition) ToSpell() models.Spell {
+	return models.Spell{
+		ID:          d.ID,
+		Name:        d.Name,
+		Level:       d.Level,
+		School:      d.School,
+		CastingTime: d.CastingTime,
+		Range:       d.Range,
+		Components:  d.Components.String(),
+		Duration:    d.Duration,
+		Description: d.Description,
+		Ritual:      d.Ritual,
+	}
+}
+
+// OnClassList reports whether the spell appears on the given class's spell list
+func (d *SpellDefinition) OnClassList(class string) bool {
+	for _, c := range d.Classes {
+		if strings.EqualFold(c, class) {
+			return true
+		}
+	}
+	return false
+}
+
+// SpellCatalog indexes the spell definitions shipped in data/spells
+type SpellCatalog struct {
+	spells map[string]*SpellDefinition
+}
+
+// NewSpellCatalog loads every spell file under dataPath/spells
+func NewSpellCatalog(dataPath string) (*SpellCatalog, error) {
+	catalog := &SpellCatalog{spells: make(map[string]*SpellDefinition)}
+
+	root := filepath.Join(dataPath, "spells")
+	err := filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var spell SpellDefinition
+		if err := json.Unmarshal(data, &spell); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", entry.Name(), err)
+		}
+		if spell.ID == "" {
+			spell.ID = catalogSlug(spell.Name)
+		}
+		catalog.spells[spell.ID] = &spell
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spell data: %w", err)
+	}
+
+	return catalog, nil
+}
+
+// Find looks a spell up by ID or name
+func (c *SpellCatalog) Find(name string) *SpellDefinition {
+	return c.spells[catalogSlug(name)]
+}
+
+// Spells returns all spells sorted by level then name
+func (c *SpellCatalog) Spells() []*SpellDefinition {
+	spells := make([]*SpellDefinition, 0, len(c.spells))
+	for _, spell := range c.spells {
+		spells = append(spells, spell)
+	}
+	sort.Slice(spells, func(i, j int) bool {
+		if spells[i].Level != spells[j].Level {
+			return spells[i].Level < spells[j].Level
+		}
+		return spells[i].Name < spells[j].Name
+	})
+	return spells
+}
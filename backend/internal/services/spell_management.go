@@ -0,0 +1,658 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/constants"
+	"github.com/ctclostio/DnD-Game/backend/internal/database"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/logger"
+)
+
+// Wizards start with six spells in their spellbook and add two free spells per level
+const (
+	spellbookStartingSpells = 6
+	spellbookSpellsPerLevel = 2
+)
+
+// preparationLevelDivisor halves the class level for half casters when counting prepared spells
+var preparationLevelDivisor = map[string]int{
+	constants.ClassPaladin: 2,
+}
+
+// classSpellcastingData is the subset of a class file needed for spell management
+type classSpellcastingData struct {
+	Name         string `json:"name"`
+	Spellcasting *struct {
+		Ability              string         `json:"ability"`
+		RitualCasting        bool           `json:"ritualCasting"`
+		PreparingSpells      string         `json:"preparingSpells"`
+		SpellbookDescription string         `json:"spellbookDescription"`
+		CantripsKnown        map[string]int `json:"cantripsKnown"`
+		SpellsKnown          map[string]int `json:"spellsKnown"`
+	} `json:"spellcasting"`
+}
+
+// spellcastingProfile is the resolved set of spellcasting rules for a character's class
+type spellcastingProfile struct {
+	className     string
+	casterType    models.SpellCasterType
+	ability       string
+	ritualCasting bool
+	cantrips      func(level int) int
+	spellsKnown   func(level int) int
+	levelDivisor  int
+	spellList     map[string]bool // custom class spell list keyed by slug; nil uses the data/spells class lists
+}
+
+// SpellManagementService validates learning, preparing and casting spells against class rules
+type SpellManagementService struct {
+	dataPath        string
+	catalog         *SpellCatalog
+	characterRepo   database.CharacterRepository
+	inventoryRepo   database.InventoryRepository
+	customClassRepo *database.CustomClassRepository
+	resourceService *CharacterResourceService
+	versions        *CharacterVersionService
+}
+
+// NewSpellManagementService creates a new spell management service
+func NewSpellManagementService(dataPath string, catalog *SpellCatalog, characterRepo database.CharacterRepository, inventoryRepo database.InventoryRepository) *SpellManagementService {
+	return &SpellManagementService{
+		dataPath:      dataPath,
+		catalog:       catalog,
+		characterRepo: characterRepo,
+		inventoryRepo: inventoryRepo,
+	}
+}
+
+// SetCustomClassRepository enables spell lists and progressions from custom classes
+func (s *SpellManagementService) SetCustomClassRepository(repo *database.CustomClassRepository) {
+	s.customClassRepo = repo
+}
+
+// SetResourceService limits changing prepared spells to once per long rest
+func (s *SpellManagementService) SetResourceService(resourceService *CharacterResourceService) {
+	s.resourceService = resourceService
+}
+
+// SetVersionService enables recording a version snapshot after each spell change
+func (s *SpellManagementService) SetVersionService(versions *CharacterVersionService) {
+	s.versions = versions
+}
+
+// GetSpellcasting summarizes a character's spells against their class limits
+func (s *SpellManagementService) GetSpellcasting(ctx context.Context, characterID string) (*models.SpellcastingSummary, error) {
+	char, profile, err := s.loadCaster(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+	return s.summarize(char, profile), nil
+}
+
+// GetAvailableSpells returns the class list spells the character has slots to learn or prepare
+func (s *SpellManagementService) GetAvailableSpells(ctx context.Context, characterID string) ([]*SpellDefinition, error) {
+	char, profile, err := s.loadCaster(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+
+	maxLevel := maxSpellSlotLevel(char)
+	var spells []*SpellDefinition
+	for _, spell := range s.catalog.Spells() {
+		if spell.Level <= maxLevel && profile.onList(spell) {
+			spells = append(spells, spell)
+		}
+	}
+	return spells, nil
+}
+
+// LearnSpell adds a cantrip or known spell, or copies a spell into a wizard's spellbook
+func (s *SpellManagementService) LearnSpell(ctx context.Context, characterID string, req *models.LearnSpellRequest) (*models.SpellcastingSummary, error) {
+	char, profile, err := s.loadCaster(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+
+	spell, err := s.classSpell(profile, req.Spell)
+	if err != nil {
+		return nil, err
+	}
+	if findKnownSpell(char, spell.ID) != nil {
+		return nil, fmt.Errorf("%s already knows %s", char.Name, spell.Name)
+	}
+
+	summary := s.summarize(char, profile)
+	entry := spell.ToSpell()
+	copyPaid := false
+
+	switch {
+	case spell.Level == 0:
+		if summary.CantripsKnown >= summary.CantripsLimit {
+			return nil, fmt.Errorf("%s already knows the maximum of %d cantrips", char.Name, summary.CantripsLimit)
+		}
+	case spell.Level > summary.MaxSpellLevel:
+		return nil, fmt.Errorf("%s is level %d but %s has no spell slots above level %d", spell.Name, spell.Level, char.Name, summary.MaxSpellLevel)
+	case profile.casterType == models.SpellCasterPrepared:
+		return nil, fmt.Errorf("%s casters prepare spells from their class list instead of learning them", profile.className)
+	case profile.casterType == models.SpellCasterKnown:
+		if summary.SpellsKnownLimit > 0 && summary.SpellsKnown >= summary.SpellsKnownLimit {
+			return nil, fmt.Errorf("%s already knows the maximum of %d spells", char.Name, summary.SpellsKnownLimit)
+		}
+		entry.Prepared = true
+	case profile.casterType == models.SpellCasterSpellbook:
+		if summary.SpellsKnown >= summary.FreeSpellbook {
+			if err := s.payCopyCost(characterID, spell, req.Copy); err != nil {
+				return nil, err
+			}
+			copyPaid = true
+		}
+	}
+
+	char.Spells.SpellsKnown = append(char.Spells.SpellsKnown, entry)
+	if err := s.saveCharacter(ctx, char, "learned "+spell.Name); err != nil {
+		if copyPaid {
+			s.refundCopyCost(ctx, characterID, spell)
+		}
+		return nil, err
+	}
+	return s.summarize(char, profile), nil
+}
+
+// ForgetSpell removes a spell from the character's known spells or spellbook
+func (s *SpellManagementService) ForgetSpell(ctx context.Context, characterID, spellName string) (*models.SpellcastingSummary, error) {
+	char, profile, err := s.loadCaster(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+
+	id := catalogSlug(spellName)
+	for i := range char.Spells.SpellsKnown {
+		if spellEntryID(&char.Spells.SpellsKnown[i]) == id {
+			name := char.Spells.SpellsKnown[i].Name
+			char.Spells.SpellsKnown = append(char.Spells.SpellsKnown[:i], char.Spells.SpellsKnown[i+1:]...)
+			if err := s.saveCharacter(ctx, char, "forgot "+name); err != nil {
+				return nil, err
+			}
+			return s.summarize(char, profile), nil
+		}
+	}
+	return nil, fmt.Errorf("%s does not know %s", char.Name, spellName)
+}
+
+// PrepareSpells replaces the character's prepared spells for the day
+func (s *SpellManagementService) PrepareSpells(ctx context.Context, characterID string, spellNames []string) (*models.SpellcastingSummary, error) {
+	char, profile, err := s.loadCaster(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+	if profile.casterType == models.SpellCasterKnown {
+		return nil, fmt.Errorf("%s casters always have their known spells ready and do not prepare spells", profile.className)
+	}
+	if err := s.checkPreparationWindow(ctx, char); err != nil {
+		return nil, err
+	}
+
+	summary := s.summarize(char, profile)
+	if len(spellNames) > summary.PreparationLimit {
+		return nil, fmt.Errorf("%s can prepare at most %d spells", char.Name, summary.PreparationLimit)
+	}
+
+	selected := make(map[string]*SpellDefinition, len(spellNames))
+	for _, name := range spellNames {
+		spell, err := s.classSpell(profile, name)
+		if err != nil {
+			return nil, err
+		}
+		if spell.Level == 0 {
+			return nil, fmt.Errorf("%s is a cantrip and does not need to be prepared", spell.Name)
+		}
+		if spell.Level > summary.MaxSpellLevel {
+			return nil, fmt.Errorf("%s has no spell slots of level %d for %s", char.Name, spell.Level, spell.Name)
+		}
+		if _, dup := selected[spell.ID]; dup {
+			return nil, fmt.Errorf("%s is listed more than once", spell.Name)
+		}
+		if profile.casterType == models.SpellCasterSpellbook && findKnownSpell(char, spell.ID) == nil {
+			return nil, fmt.Errorf("%s is not in %s's spellbook", spell.Name, char.Name)
+		}
+		selected[spell.ID] = spell
+	}
+
+	if profile.casterType == models.SpellCasterSpellbook {
+		// The spellbook keeps every spell; only the prepared flags change
+		for i := range char.Spells.SpellsKnown {
+			entry := &char.Spells.SpellsKnown[i]
+			_, ok := selected[spellEntryID(entry)]
+			entry.Prepared = ok && entry.Level > 0
+		}
+	} else {
+		// Prepared casters draw from their whole list, so only cantrips persist between preparations
+		spells := make([]models.Spell, 0, len(char.Spells.SpellsKnown)+len(selected))
+		for _, entry := range char.Spells.SpellsKnown {
+			if entry.Level == 0 {
+				spells = append(spells, entry)
+			}
+		}
+		for _, name := range spellNames {
+			entry := selected[catalogSlug(name)].ToSpell()
+			entry.Prepared = true
+			spells = append(spells, entry)
+		}
+		char.Spells.SpellsKnown = spells
+	}
+
+	now := time.Now()
+	char.Spells.PreparedAt = &now
+	if err := s.saveCharacter(ctx, char, fmt.Sprintf("prepared %d spells", len(selected))); err != nil {
+		return nil, err
+	}
+	return s.summarize(char, profile), nil
+}
+
+// CastSpell casts a known or prepared spell, spending a slot unless it is a cantrip or ritual,
+// and checks or consumes any costly material component from the character's inventory
+func (s *SpellManagementService) CastSpell(ctx context.Context, characterID string, req *models.CastSpellRequest) (*models.SpellCastResult, error) {
+	char, profile, err := s.loadCaster(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+
+	id := catalogSlug(req.Spell)
+	known := findKnownSpell(char, id)
+	if known == nil {
+		return nil, fmt.Errorf("%s does not know %s", char.Name, req.Spell)
+	}
+	spell := *known
+	definition := s.catalog.Find(id)
+	if definition != nil {
+		spell.Ritual = definition.Ritual
+	}
+
+	result := &models.SpellCastResult{Spell: spell, Ritual: req.Ritual}
+	switch {
+	case req.Ritual:
+		if !profile.ritualCasting {
+			return nil, fmt.Errorf("%s casters cannot cast rituals", profile.className)
+		}
+		if !spell.Ritual {
+			return nil, fmt.Errorf("%s does not have the ritual tag", spell.Name)
+		}
+		// Wizards may cast any ritual in their spellbook; other casters need it prepared
+		if profile.casterType == models.SpellCasterPrepared && !spell.Prepared {
+			return nil, fmt.Errorf("%s is not prepared", spell.Name)
+		}
+		result.SlotLevel = spell.Level
+	case spell.Level == 0:
+		result.SlotLevel = 0
+	default:
+		if profile.casterType != models.SpellCasterKnown && !spell.Prepared {
+			return nil, fmt.Errorf("%s is not prepared", spell.Name)
+		}
+		result.SlotLevel = req.SlotLevel
+		if result.SlotLevel == 0 {
+			result.SlotLevel = spell.Level
+		}
+		if result.SlotLevel < spell.Level {
+			return nil, fmt.Errorf("%s requires a spell slot of at least level %d", spell.Name, spell.Level)
+		}
+	}
+
+	if definition != nil && definition.Components.MaterialCost > 0 {
+		use, err := s.checkMaterialComponent(characterID, definition)
+		if err != nil {
+			return nil, err
+		}
+		result.Components = use
+	}
+
+	// The slot is only taken from the loaded character here; it is saved once the
+	// component has been consumed, so a failed consumption never costs a slot
+	spendsSlot := spell.Level > 0 && !req.Ritual
+	if spendsSlot {
+		if err := spendSpellSlot(char, result.SlotLevel); err != nil {
+			return nil, err
+		}
+	}
+
+	if result.Components != nil && result.Components.Consumed {
+		if err := s.inventoryRepo.RemoveItemFromInventory(characterID, result.Components.ItemID, result.Components.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to consume %s: %w", result.Components.ItemID, err)
+		}
+	}
+
+	if spendsSlot {
+		reason := fmt.Sprintf("cast %s at level %d", spell.Name, result.SlotLevel)
+		if err := s.saveCharacter(ctx, char, reason); err != nil {
+			if result.Components != nil && result.Components.Consumed {
+				if refundErr := s.inventoryRepo.AddItemToInventory(characterID, result.Components.ItemID, result.Components.Quantity); refundErr != nil {
+					logger.WithContext(ctx).WithError(refundErr).Error().
+						Str("character_id", characterID).
+						Str("item_id", result.Components.ItemID).
+						Msg("Failed to return material component after a failed cast")
+				}
+			}
+			return nil, err
+		}
+	}
+
+	result.Character = char
+	return result, nil
+}
+
+func (s *SpellManagementService) saveCharacter(ctx context.Context, char *models.Character, reason string) error {
+	if err := s.characterRepo.Update(ctx, char); err != nil {
+		return err
+	}
+	s.versions.RecordChange(ctx, char, models.CharacterChangeSpells, reason)
+	return nil
+}
+
+func (s *SpellManagementService) loadCaster(ctx context.Context, characterID string) (*models.Character, *spellcastingProfile, error) {
+	char, err := s.characterRepo.GetByID(ctx, characterID)
+	if err != nil {
+		return nil, nil, err
+	}
+	profile, err := s.profileFor(char)
+	if err != nil {
+		return nil, nil, err
+	}
+	return char, profile, nil
+}
+
+func (s *SpellManagementService) profileFor(char *models.Character) (*spellcastingProfile, error) {
+	if char.CustomClassID != nil && s.customClassRepo != nil {
+		class, err := s.customClassRepo.GetByID(*char.CustomClassID)
+		if err != nil {
+			return nil, err
+		}
+		return customClassProfile(class)
+	}
+
+	className := dataFileName(char.Class)
+	if err := validateFileName(className); err != nil {
+		return nil, fmt.Errorf("invalid class name: %w", err)
+	}
+	data, err := os.ReadFile(filepath.Join(s.dataPath, "classes", className+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("unknown class %s", char.Class)
+	}
+
+	var classData classSpellcastingData
+	if err := json.Unmarshal(data, &classData); err != nil {
+		return nil, err
+	}
+	if classData.Spellcasting == nil {
+		return nil, fmt.Errorf("%s is not a spellcasting class", classData.Name)
+	}
+
+	casting := classData.Spellcasting
+	profile := &spellcastingProfile{
+		className:     classData.Name,
+		casterType:    models.SpellCasterKnown,
+		ability:       casting.Ability,
+		ritualCasting: casting.RitualCasting,
+		cantrips:      progressionTable(casting.CantripsKnown),
+		spellsKnown:   progressionTable(casting.SpellsKnown),
+		levelDivisor:  1,
+	}
+	switch {
+	case casting.SpellbookDescription != "":
+		profile.casterType = models.SpellCasterSpellbook
+	case casting.PreparingSpells != "":
+		profile.casterType = models.SpellCasterPrepared
+	}
+	if divisor, ok := preparationLevelDivisor[className]; ok {
+		profile.levelDivisor = divisor
+	}
+	return profile, nil
+}
+
+func customClassProfile(class *models.CustomClass) (*spellcastingProfile, error) {
+	if class.SpellcastingAbility == "" || len(class.SpellList) == 0 {
+		return nil, fmt.Errorf("%s is not a spellcasting class", class.Name)
+	}
+
+	profile := &spellcastingProfile{
+		className:     class.Name,
+		casterType:    models.SpellCasterPrepared,
+		ability:       class.SpellcastingAbility,
+		ritualCasting: class.RitualCasting,
+		cantrips:      progressionList(class.CantripsKnownProgression),
+		spellsKnown:   progressionList(class.SpellsKnownProgression),
+		levelDivisor:  1,
+		spellList:     make(map[string]bool, len(class.SpellList)),
+	}
+	if len(class.SpellsKnownProgression) > 0 {
+		profile.casterType = models.SpellCasterKnown
+	}
+	for _, name := range class.SpellList {
+		profile.spellList[catalogSlug(name)] = true
+	}
+	return profile, nil
+}
+
+// onList reports whether a spell is on the profile's class spell list
+func (p *spellcastingProfile) onList(spell *SpellDefinition) bool {
+	if p.spellList != nil {
+		return p.spellList[spell.ID]
+	}
+	return spell.OnClassList(p.className)
+}
+
+// classSpell looks a spell up in the catalog and checks it is on the class list
+func (s *SpellManagementService) classSpell(profile *spellcastingProfile, name string) (*SpellDefinition, error) {
+	spell := s.catalog.Find(name)
+	if spell == nil {
+		return nil, fmt.Errorf("unknown spell: %s", name)
+	}
+	if !profile.onList(spell) {
+		return nil, fmt.Errorf("%s is not on the %s spell list", spell.Name, profile.className)
+	}
+	return spell, nil
+}
+
+func (s *SpellManagementService) summarize(char *models.Character, profile *spellcastingProfile) *models.SpellcastingSummary {
+	summary := &models.SpellcastingSummary{
+		CharacterID:   char.ID,
+		CasterType:    profile.casterType,
+		Ability:       profile.ability,
+		RitualCasting: profile.ritualCasting,
+		MaxSpellLevel: maxSpellSlotLevel(char),
+		CantripsLimit: profile.cantrips(char.Level),
+		Spells:        char.Spells.SpellsKnown,
+	}
+
+	for _, spell := range char.Spells.SpellsKnown {
+		switch {
+		case spell.Level == 0:
+			summary.CantripsKnown++
+		case spell.Prepared && profile.casterType != models.SpellCasterKnown:
+			summary.Prepared++
+			summary.SpellsKnown++
+		default:
+			summary.SpellsKnown++
+		}
+	}
+
+	switch profile.casterType {
+	case models.SpellCasterKnown:
+		summary.SpellsKnownLimit = profile.spellsKnown(char.Level)
+		summary.Prepared = summary.SpellsKnown
+	case models.SpellCasterSpellbook:
+		summary.FreeSpellbook = spellbookStartingSpells + spellbookSpellsPerLevel*(max(char.Level, 1)-1)
+		fallthrough
+	case models.SpellCasterPrepared:
+		modifier := getModifier(abilityScore(char, profile.ability))
+		summary.PreparationLimit = max(1, modifier+char.Level/profile.levelDivisor)
+	}
+	return summary
+}
+
+// checkPreparationWindow allows re-preparing spells only after a long rest since the last preparation
+func (s *SpellManagementService) checkPreparationWindow(ctx context.Context, char *models.Character) error {
+	if s.resourceService == nil || char.Spells.PreparedAt == nil {
+		return nil
+	}
+	state, err := s.resourceService.GetRestState(ctx, char.ID)
+	if err != nil {
+		return err
+	}
+	if state.LastLongRestAt == nil || state.LastLongRestAt.Before(*char.Spells.PreparedAt) {
+		return fmt.Errorf("%s must finish a long rest before preparing a new list of spells", char.Name)
+	}
+	return nil
+}
+
+// payCopyCost charges the gold and confirms the copy when a wizard has used their free spellbook spells
+func (s *SpellManagementService) payCopyCost(characterID string, spell *SpellDefinition, confirmed bool) error {
+	cost := spell.Level * models.SpellbookCopyCostPerLevel
+	if !confirmed {
+		return fmt.Errorf("no free spellbook spells remain; copying %s costs %d gp", spell.Name, cost)
+	}
+
+	_, err := s.inventoryRepo.ApplyTransaction(&models.EconomyTransaction{
+		Type:        models.LedgerEntryPurchase,
+		Description: fmt.Sprintf("copied %s into spellbook", spell.Name),
+		Currency:    []models.CurrencyMovement{{CharacterID: characterID, Coins: models.Coins{Gold: -cost}}},
+	})
+	if err != nil {
+		return fmt.Errorf("copying %s costs %d gp: %w", spell.Name, cost, err)
+	}
+	return nil
+}
+
+// refundCopyCost gives back the gold paid to copy a spell whose spellbook entry could not be saved
+func (s *SpellManagementService) refundCopyCost(ctx context.Context, characterID string, spell *SpellDefinition) {
+	cost := spell.Level * models.SpellbookCopyCostPerLevel
+	_, err := s.inventoryRepo.ApplyTransaction(&models.EconomyTransaction{
+		Type:        models.LedgerEntryAdjustment,
+		Description: fmt.Sprintf("refunded copying %s into spellbook", spell.Name),
+		Currency:    []models.CurrencyMovement{{CharacterID: characterID, Coins: models.Coins{Gold: cost}}},
+	})
+	if err != nil {
+		logger.WithContext(ctx).WithError(err).Error().
+			Str("character_id", characterID).
+			Str("spell", spell.Name).
+			Msg("Failed to refund spellbook copy cost after a failed save")
+	}
+}
+
+// checkMaterialComponent verifies the character carries enough of a costly component
+func (s *SpellManagementService) checkMaterialComponent(characterID string, spell *SpellDefinition) (*models.MaterialComponentUse, error) {
+	components := spell.Components
+	inventory, err := s.inventoryRepo.GetCharacterInventory(characterID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, inv := range inventory {
+		if inv.ItemID != components.MaterialItem {
+			continue
+		}
+		needed := 1
+		if inv.Item != nil && inv.Item.Value > 0 {
+			needed = (components.MaterialCost*100 + inv.Item.Value - 1) / inv.Item.Value
+		}
+		if inv.Quantity < needed {
+			break
+		}
+		return &models.MaterialComponentUse{
+			ItemID:   inv.ItemID,
+			Quantity: needed,
+			Consumed: components.MaterialConsumed,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%s requires %s", spell.Name, components.MaterialDescription)
+}
+
+func spendSpellSlot(char *models.Character, level int) error {
+	for i := range char.Spells.SpellSlots {
+		if char.Spells.SpellSlots[i].Level == level {
+			if char.Spells.SpellSlots[i].Remaining > 0 {
+				char.Spells.SpellSlots[i].Remaining--
+				return nil
+			}
+			return fmt.Errorf("no remaining spell slots of level %d", level)
+		}
+	}
+	return fmt.Errorf("character does not have spell slots of level %d", level)
+}
+
+// maxSpellSlotLevel is the highest level the character has spell slots for
+func maxSpellSlotLevel(char *models.Character) int {
+	highest := 0
+	for _, slot := range char.Spells.SpellSlots {
+		if slot.Total > 0 && slot.Level > highest {
+			highest = slot.Level
+		}
+	}
+	return highest
+}
+
+func findKnownSpell(char *models.Character, id string) *models.Spell {
+	for i := range char.Spells.SpellsKnown {
+		if spellEntryID(&char.Spells.SpellsKnown[i]) == id {
+			return &char.Spells.SpellsKnown[i]
+		}
+	}
+	return nil
+}
+
+// spellEntryID matches stored spells by catalog slug, since older entries may lack an ID
+func spellEntryID(spell *models.Spell) string {
+	if spell.ID != "" {
+		return spell.ID
+	}
+	return catalogSlug(spell.Name)
+}
+
+func abilityScore(char *models.Character, ability string) int {
+	switch strings.ToLower(ability) {
+	case constants.AbilityStrength:
+		return char.Attributes.Strength
+	case constants.AbilityDexterity:
+		return char.Attributes.Dexterity
+	case constants.AbilityConstitution:
+		return char.Attributes.Constitution
+	case constants.AbilityIntelligence:
+		return char.Attributes.Intelligence
+	case constants.AbilityWisdom:
+		return char.Attributes.Wisdom
+	case constants.AbilityCharisma:
+		return char.Attributes.Charisma
+	default:
+		return 10
+	}
+}
+
+// progressionTable reads a class file table keyed by level, such as cantripsKnown
+func progressionTable(table map[string]int) func(level int) int {
+	thresholds := make(map[int]int, len(table))
+	for key, value := range table {
+		if level, err := strconv.Atoi(key); err == nil {
+			thresholds[level] = value
+		}
+	}
+	return func(level int) int {
+		return levelScaled(level, thresholds)
+	}
+}
+
+// progressionList reads a custom class progression indexed by level minus one
+func progressionList(values []int) func(level int) int {
+	return func(level int) int {
+		if len(values) == 0 || level < 1 {
+			return 0
+		}
+		return values[min(level, len(values))-1]
+	}
+}
@@ -0,0 +1,456 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/internal/services"
+	"github.com/ctclostio/DnD-Game/backend/internal/services/mocks"
+)
+
+const testSpellCharacterID = "char-spells-1"
+
+func createTestSpellService(catalog *services.SpellCatalog, characterRepo *mocks.MockCharacterRepository, inventoryRepo *mocks.MockInventoryRepository) *services.SpellManagementService {
+	return services.NewSpellManagementService(testGameDataPath, catalog, characterRepo, inventoryRepo)
+}
+
+func spellSlots(levels ...int) []models.SpellSlot {
+	slots := make([]models.SpellSlot, len(levels))
+	for i, total := range levels {
+		slots[i] = models.SpellSlot{Level: i + 1, Total: total, Remaining: total}
+	}
+	return slots
+}
+
+func TestSpellCatalog(t *testing.T) {
+	catalog, err := services.NewSpellCatalog(testGameDataPath)
+	require.NoError(t, err)
+
+	revivify := catalog.Find("Revivify")
+	require.NotNil(t, revivify)
+	assert.Equal(t, 3, revivify.Level)
+	assert.Equal(t, 300, revivify.Components.MaterialCost)
+	assert.True(t, revivify.Components.MaterialConsumed)
+	assert.True(t, revivify.OnClassList("cleric"))
+	assert.False(t, revivify.OnClassList("wizard"))
+
+	assert.True(t, catalog.Find("detect magic").Ritual)
+	assert.Equal(t, "V, S, M (a tiny ball of bat guano and sulfur)", catalog.Find("fireball").ToSpell().Components)
+	assert.Nil(t, catalog.Find("wish"))
+}
+
+// wizardWithFreeSpellsUsed is a first level wizard who has already written the six
+// spells a new wizard's spellbook gets for free
+func wizardWithFreeSpellsUsed() *models.Character {
+	known := make([]models.Spell, 6)
+	for i := range known {
+		known[i] = models.Spell{ID: "filler_" + string(rune('a'+i)), Name: "Filler", Level: 1}
+	}
+	return &models.Character{
+		ID: testSpellCharacterID, Class: "wizard", Level: 1,
+		Spells: models.SpellData{SpellSlots: spellSlots(2), SpellsKnown: known},
+	}
+}
+
+func TestSpellManagementService_PrepareSpells(t *testing.T) {
+	catalog, err := services.NewSpellCatalog(testGameDataPath)
+	require.NoError(t, err)
+	newCleric := func() *models.Character {
+		return &models.Character{
+			ID: testSpellCharacterID, Name: "Tharivol", Class: "Cleric", Level: 3,
+			Attributes: models.Attributes{Wisdom: 16},
+			Spells: models.SpellData{
+				SpellSlots:  spellSlots(4, 2),
+				SpellsKnown: []models.Spell{{ID: "sacred_flame", Name: "Sacred Flame"}},
+			},
+		}
+	}
+	preparedAt := time.Now()
+	preparedCleric := newCleric()
+	preparedCleric.Spells.PreparedAt = &preparedAt
+	restedAt := preparedAt.Add(-time.Hour)
+
+	tests := []struct {
+		name         string
+		character    *models.Character
+		spells       []string
+		lastLongRest *time.Time
+		expectError  string
+		validate     func(*testing.T, *models.SpellcastingSummary, *models.Character)
+	}{
+		{
+			name:      "Prepares from the class list",
+			character: newCleric(),
+			spells:    []string{"Cure Wounds", "spiritual weapon"},
+			validate: func(t *testing.T, summary *models.SpellcastingSummary, cleric *models.Character) {
+				assert.Equal(t, models.SpellCasterPrepared, summary.CasterType)
+				assert.Equal(t, 6, summary.PreparationLimit)
+				assert.Equal(t, 2, summary.Prepared)
+				assert.Equal(t, 1, summary.CantripsKnown)
+				require.Len(t, cleric.Spells.SpellsKnown, 3)
+				assert.True(t, cleric.Spells.SpellsKnown[1].Prepared)
+				assert.NotNil(t, cleric.Spells.PreparedAt)
+			},
+		},
+		{
+			name:        "Spells off the class list",
+			character:   newCleric(),
+			spells:      []string{"Magic Missile"},
+			expectError: "not on the Cleric spell list",
+		},
+		{
+			name:        "Spells above available slots",
+			character:   newCleric(),
+			spells:      []string{"Revivify"},
+			expectError: "Revivify",
+		},
+		{
+			name: "Half casters prepare fewer spells",
+			character: &models.Character{
+				ID: testSpellCharacterID, Class: "paladin", Level: 2,
+				Attributes: models.Attributes{Charisma: 10},
+				Spells:     models.SpellData{SpellSlots: spellSlots(2)},
+			},
+			spells:      []string{"Cure Wounds", "Detect Magic"},
+			expectError: "at most 1 spells",
+		},
+		{
+			name:        "Known casters do not prepare",
+			character:   &models.Character{ID: testSpellCharacterID, Class: "sorcerer", Level: 1},
+			spells:      []string{"Shield"},
+			expectError: "do not prepare spells",
+		},
+		{
+			name:         "Only once per long rest",
+			character:    preparedCleric,
+			spells:       []string{"Cure Wounds"},
+			lastLongRest: &restedAt,
+			expectError:  "long rest",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			characterRepo := new(mocks.MockCharacterRepository)
+			inventoryRepo := new(mocks.MockInventoryRepository)
+			characterRepo.On(testMethodGetByID, mock.Anything, testSpellCharacterID).Return(tt.character, nil)
+			if tt.expectError == "" {
+				characterRepo.On("Update", mock.Anything, tt.character).Return(nil)
+			}
+
+			svc := createTestSpellService(catalog, characterRepo, inventoryRepo)
+			if tt.lastLongRest != nil {
+				resourceRepo := new(mocks.MockCharacterResourceRepository)
+				resourceRepo.On(testMethodGetRestState, mock.Anything, testSpellCharacterID).
+					Return(&models.CharacterRestState{CharacterID: testSpellCharacterID, LastLongRestAt: tt.lastLongRest}, nil)
+				svc.SetResourceService(services.NewCharacterResourceService(resourceRepo, characterRepo))
+			}
+			summary, err := svc.PrepareSpells(context.Background(), testSpellCharacterID, tt.spells)
+
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+			} else {
+				require.NoError(t, err)
+				if tt.validate != nil {
+					tt.validate(t, summary, tt.character)
+				}
+			}
+
+			characterRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSpellManagementService_LearnSpell(t *testing.T) {
+	catalog, err := services.NewSpellCatalog(testGameDataPath)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		character   *models.Character
+		request     *models.LearnSpellRequest
+		setupMocks  func(*mocks.MockCharacterRepository, *mocks.MockInventoryRepository, *models.Character)
+		expectError string
+		validate    func(*testing.T, *models.SpellcastingSummary, *models.Character)
+	}{
+		{
+			name: "Known casters learn up to their class progression",
+			character: &models.Character{
+				ID: testSpellCharacterID, Class: "Sorcerer", Level: 1,
+				Spells: models.SpellData{SpellSlots: spellSlots(2)},
+			},
+			request: &models.LearnSpellRequest{Spell: "Shield"},
+			setupMocks: func(characterRepo *mocks.MockCharacterRepository, _ *mocks.MockInventoryRepository, char *models.Character) {
+				characterRepo.On("Update", mock.Anything, char).Return(nil)
+			},
+			validate: func(t *testing.T, summary *models.SpellcastingSummary, sorcerer *models.Character) {
+				assert.Equal(t, 2, summary.SpellsKnownLimit)
+				assert.True(t, sorcerer.Spells.SpellsKnown[0].Prepared)
+			},
+		},
+		{
+			name: "Known casters at their class progression",
+			character: &models.Character{
+				ID: testSpellCharacterID, Class: "Sorcerer", Level: 1,
+				Spells: models.SpellData{
+					SpellSlots: spellSlots(2),
+					SpellsKnown: []models.Spell{
+						{ID: "shield", Name: "Shield", Level: 1, Prepared: true},
+						{ID: "magic_missile", Name: "Magic Missile", Level: 1, Prepared: true},
+					},
+				},
+			},
+			request:     &models.LearnSpellRequest{Spell: "Detect Magic"},
+			expectError: "maximum of 2 spells",
+		},
+		{
+			name:        "Wizards must agree to pay for copying beyond their free spells",
+			character:   wizardWithFreeSpellsUsed(),
+			request:     &models.LearnSpellRequest{Spell: "Shield"},
+			expectError: "costs 50 gp",
+		},
+		{
+			name:      "Wizards pay to copy beyond their free spells",
+			character: wizardWithFreeSpellsUsed(),
+			request:   &models.LearnSpellRequest{Spell: "Shield", Copy: true},
+			setupMocks: func(characterRepo *mocks.MockCharacterRepository, inventoryRepo *mocks.MockInventoryRepository, char *models.Character) {
+				inventoryRepo.On(testMethodApplyTransaction, mock.MatchedBy(func(txn *models.EconomyTransaction) bool {
+					return txn.Type == models.LedgerEntryPurchase && len(txn.Currency) == 1 &&
+						txn.Currency[0].Coins.TotalInCopper() == -5000
+				})).Return([]*models.LedgerEntry{}, nil)
+				characterRepo.On("Update", mock.Anything, char).Return(nil)
+			},
+			validate: func(t *testing.T, summary *models.SpellcastingSummary, wizard *models.Character) {
+				assert.Equal(t, models.SpellCasterSpellbook, summary.CasterType)
+				assert.Equal(t, 7, summary.SpellsKnown)
+				assert.False(t, wizard.Spells.SpellsKnown[6].Prepared)
+			},
+		},
+		{
+			name:      "A failed save refunds the copy cost",
+			character: wizardWithFreeSpellsUsed(),
+			request:   &models.LearnSpellRequest{Spell: "Shield", Copy: true},
+			setupMocks: func(characterRepo *mocks.MockCharacterRepository, inventoryRepo *mocks.MockInventoryRepository, char *models.Character) {
+				characterRepo.On("Update", mock.Anything, char).Return(errors.New("version conflict"))
+				inventoryRepo.On(testMethodApplyTransaction, mock.MatchedBy(func(txn *models.EconomyTransaction) bool {
+					return txn.Type == models.LedgerEntryPurchase && txn.Currency[0].Coins.TotalInCopper() == -5000
+				})).Return([]*models.LedgerEntry{}, nil).Once()
+				inventoryRepo.On(testMethodApplyTransaction, mock.MatchedBy(func(txn *models.EconomyTransaction) bool {
+					return txn.Type == models.LedgerEntryAdjustment && txn.Currency[0].Coins.TotalInCopper() == 5000
+				})).Return([]*models.LedgerEntry{}, nil).Once()
+			},
+			expectError: "version conflict",
+		},
+		{
+			name:      "Prepared casters learn cantrips",
+			character: &models.Character{ID: testSpellCharacterID, Class: "cleric", Level: 1, Spells: models.SpellData{SpellSlots: spellSlots(2)}},
+			request:   &models.LearnSpellRequest{Spell: "Guidance"},
+			setupMocks: func(characterRepo *mocks.MockCharacterRepository, _ *mocks.MockInventoryRepository, char *models.Character) {
+				characterRepo.On("Update", mock.Anything, char).Return(nil)
+			},
+		},
+		{
+			name:        "Prepared casters do not learn leveled spells",
+			character:   &models.Character{ID: testSpellCharacterID, Class: "cleric", Level: 1, Spells: models.SpellData{SpellSlots: spellSlots(2)}},
+			request:     &models.LearnSpellRequest{Spell: "Cure Wounds"},
+			expectError: "prepare spells from their class list",
+		},
+		{
+			name:        "Non casters",
+			character:   &models.Character{ID: testSpellCharacterID, Class: "fighter", Level: 1},
+			request:     &models.LearnSpellRequest{Spell: "Shield"},
+			expectError: "not a spellcasting class",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			characterRepo := new(mocks.MockCharacterRepository)
+			inventoryRepo := new(mocks.MockInventoryRepository)
+			characterRepo.On(testMethodGetByID, mock.Anything, testSpellCharacterID).Return(tt.character, nil)
+			if tt.setupMocks != nil {
+				tt.setupMocks(characterRepo, inventoryRepo, tt.character)
+			}
+
+			svc := createTestSpellService(catalog, characterRepo, inventoryRepo)
+			summary, err := svc.LearnSpell(context.Background(), testSpellCharacterID, tt.request)
+
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+			} else {
+				require.NoError(t, err)
+				if tt.validate != nil {
+					tt.validate(t, summary, tt.character)
+				}
+			}
+
+			characterRepo.AssertExpectations(t)
+			inventoryRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSpellManagementService_CastSpell(t *testing.T) {
+	catalog, err := services.NewSpellCatalog(testGameDataPath)
+	require.NoError(t, err)
+	newCleric := func() *models.Character {
+		return &models.Character{
+			ID: testSpellCharacterID, Class: "cleric", Level: 5,
+			Spells: models.SpellData{
+				SpellSlots:  spellSlots(4, 3, 2),
+				SpellsKnown: []models.Spell{{ID: "revivify", Name: "Revivify", Level: 3, Prepared: true}},
+			},
+		}
+	}
+	newWizard := func() *models.Character {
+		return &models.Character{
+			ID: testSpellCharacterID, Class: "wizard", Level: 1,
+			Spells: models.SpellData{
+				SpellSlots:  spellSlots(2),
+				SpellsKnown: []models.Spell{{ID: "detect_magic", Name: "Detect Magic", Level: 1}},
+			},
+		}
+	}
+	newSorcerer := func() *models.Character {
+		return &models.Character{
+			ID: testSpellCharacterID, Class: "sorcerer", Level: 1,
+			Spells: models.SpellData{
+				SpellSlots:  spellSlots(2),
+				SpellsKnown: []models.Spell{{ID: "detect_magic", Name: "Detect Magic", Level: 1, Prepared: true}},
+			},
+		}
+	}
+	diamonds := []*models.InventoryItem{{ItemID: "diamond", Quantity: 4, Item: &models.Item{ID: "diamond", Value: 10000}}}
+
+	tests := []struct {
+		name        string
+		character   *models.Character
+		request     *models.CastSpellRequest
+		setupMocks  func(*mocks.MockCharacterRepository, *mocks.MockInventoryRepository, *models.Character)
+		expectError string
+		validate    func(*testing.T, *models.SpellCastResult, *models.Character)
+	}{
+		{
+			name:      "Wizard casts an unprepared ritual from the spellbook without a slot",
+			character: newWizard(),
+			request:   &models.CastSpellRequest{Spell: "Detect Magic", Ritual: true},
+			validate: func(t *testing.T, result *models.SpellCastResult, wizard *models.Character) {
+				assert.True(t, result.Ritual)
+				assert.Equal(t, 2, wizard.Spells.SpellSlots[0].Remaining)
+			},
+		},
+		{
+			name:        "Wizard casting an unprepared spell normally",
+			character:   newWizard(),
+			request:     &models.CastSpellRequest{Spell: "Detect Magic"},
+			expectError: "not prepared",
+		},
+		{
+			name:        "Classes without ritual casting cannot cast rituals",
+			character:   newSorcerer(),
+			request:     &models.CastSpellRequest{Spell: "Detect Magic", Ritual: true},
+			expectError: "ritual",
+		},
+		{
+			name:      "Classes without ritual casting spend a slot",
+			character: newSorcerer(),
+			request:   &models.CastSpellRequest{Spell: "Detect Magic"},
+			setupMocks: func(characterRepo *mocks.MockCharacterRepository, _ *mocks.MockInventoryRepository, char *models.Character) {
+				characterRepo.On("Update", mock.Anything, char).Return(nil)
+			},
+			validate: func(t *testing.T, result *models.SpellCastResult, sorcerer *models.Character) {
+				assert.Equal(t, 1, result.SlotLevel)
+				assert.Equal(t, 1, sorcerer.Spells.SpellSlots[0].Remaining)
+			},
+		},
+		{
+			name:      "Consumes costly material components",
+			character: newCleric(),
+			request:   &models.CastSpellRequest{Spell: "Revivify", SlotLevel: 3},
+			setupMocks: func(characterRepo *mocks.MockCharacterRepository, inventoryRepo *mocks.MockInventoryRepository, char *models.Character) {
+				inventoryRepo.On(testMethodGetCharacterInventory, testSpellCharacterID).Return(diamonds, nil)
+				inventoryRepo.On(testMethodRemoveItem, testSpellCharacterID, "diamond", 3).Return(nil)
+				characterRepo.On("Update", mock.Anything, char).Return(nil)
+			},
+			validate: func(t *testing.T, result *models.SpellCastResult, cleric *models.Character) {
+				require.NotNil(t, result.Components)
+				assert.Equal(t, 3, result.Components.Quantity)
+				assert.True(t, result.Components.Consumed)
+				assert.Equal(t, 1, cleric.Spells.SpellSlots[2].Remaining)
+			},
+		},
+		{
+			name:      "Failed consumption keeps the slot",
+			character: newCleric(),
+			request:   &models.CastSpellRequest{Spell: "Revivify", SlotLevel: 3},
+			setupMocks: func(_ *mocks.MockCharacterRepository, inventoryRepo *mocks.MockInventoryRepository, _ *models.Character) {
+				inventoryRepo.On(testMethodGetCharacterInventory, testSpellCharacterID).Return(diamonds, nil)
+				inventoryRepo.On(testMethodRemoveItem, testSpellCharacterID, "diamond", 3).Return(errors.New("inventory locked"))
+			},
+			expectError: "failed to consume diamond",
+		},
+		{
+			name: "Missing components block the casting",
+			character: &models.Character{
+				ID: testSpellCharacterID, Class: "bard", Level: 1,
+				Spells: models.SpellData{
+					SpellSlots:  spellSlots(2),
+					SpellsKnown: []models.Spell{{ID: "identify", Name: "Identify", Level: 1, Prepared: true}},
+				},
+			},
+			request: &models.CastSpellRequest{Spell: "Identify", Ritual: true},
+			setupMocks: func(_ *mocks.MockCharacterRepository, inventoryRepo *mocks.MockInventoryRepository, _ *models.Character) {
+				inventoryRepo.On(testMethodGetCharacterInventory, testSpellCharacterID).Return([]*models.InventoryItem{}, nil)
+			},
+			expectError: "pearl",
+		},
+		{
+			name: "Slot below spell level",
+			character: &models.Character{
+				ID: testSpellCharacterID, Class: "wizard", Level: 5,
+				Spells: models.SpellData{
+					SpellSlots:  spellSlots(4, 3, 2),
+					SpellsKnown: []models.Spell{{ID: "fireball", Name: "Fireball", Level: 3, Prepared: true}},
+				},
+			},
+			request:     &models.CastSpellRequest{Spell: "Fireball", SlotLevel: 2},
+			expectError: "level",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			characterRepo := new(mocks.MockCharacterRepository)
+			inventoryRepo := new(mocks.MockInventoryRepository)
+			characterRepo.On(testMethodGetByID, mock.Anything, testSpellCharacterID).Return(tt.character, nil)
+			if tt.setupMocks != nil {
+				tt.setupMocks(characterRepo, inventoryRepo, tt.character)
+			}
+
+			svc := createTestSpellService(catalog, characterRepo, inventoryRepo)
+			result, err := svc.CastSpell(context.Background(), testSpellCharacterID, tt.request)
+
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+			} else {
+				require.NoError(t, err)
+				if tt.validate != nil {
+					tt.validate(t, result, tt.character)
+				}
+			}
+
+			characterRepo.AssertExpectations(t)
+			inventoryRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,589 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/database"
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/dice"
+)
+
+// Starting equipment placeholder categories. Entries with a category must be
+// replaced by a concrete catalog item chosen by the player.
+const (
+	EquipmentCategorySimpleWeapon       = "simple weapon"
+	EquipmentCategorySimpleMeleeWeapon  = "simple melee weapon"
+	EquipmentCategoryMartialWeapon      = "martial weapon"
+	EquipmentCategoryMartialMeleeWeapon = "martial melee weapon"
+	EquipmentCategoryMusicalInstrument  = "musical instrument"
+	EquipmentCategoryArtisansTools      = "artisan's tools"
+)
+
+var (
+	equipmentGoldPattern     = regexp.MustCompile(`(?:pouch|purse) containing (\d+) gp`)
+	equipmentQuiverPattern   = regexp.MustCompile(`^quiver of (\d+) (.+)$`)
+	equipmentAndSplitPattern = regexp.MustCompile(`,\s*(?:and\s+)?|\s+and\s+`)
+
+	equipmentQuantityWords = map[string]int{
+		"one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+		"six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10,
+	}
+
+	// Longest names first so "simple melee weapon" wins over "simple weapon"
+	equipmentCategories = []string{
+		EquipmentCategoryMartialMeleeWeapon,
+		EquipmentCategorySimpleMeleeWeapon,
+		EquipmentCategoryMartialWeapon,
+		EquipmentCategorySimpleWeapon,
+		EquipmentCategoryMusicalInstrument,
+		EquipmentCategoryArtisansTools,
+	}
+)
+
+// StartingEquipmentService turns class and background starting equipment into inventory
+type StartingEquipmentService struct {
+	dataPath         string
+	builder          *CharacterBuilder
+	catalog          *ItemCatalog
+	inventoryService *InventoryService
+	inventoryRepo    database.InventoryRepository
+	characterRepo    database.CharacterRepository
+	roller           *dice.Roller
+}
+
+// NewStartingEquipmentService creates a new starting equipment service
+func NewStartingEquipmentService(
+	dataPath string,
+	catalog *ItemCatalog,
+	inventoryService *InventoryService,
+	inventoryRepo database.InventoryRepository,
+	characterRepo database.CharacterRepository,
+) *StartingEquipmentService {
+	return &StartingEquipmentService{
+		dataPath:         dataPath,
+		builder:          NewCharacterBuilder(dataPath),
+		catalog:          catalog,
+		inventoryService: inventoryService,
+		inventoryRepo:    inventoryRepo,
+		characterRepo:    characterRepo,
+		roller:           dice.NewRoller(),
+	}
+}
+
+// GetStartingEquipmentOptions parses the class and background equipment into selectable options
+func (s *StartingEquipmentService) GetStartingEquipmentOptions(class, background string) (*models.StartingEquipmentOptions, error) {
+	classData, err := s.loadClassEquipment(dataFileName(class))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load class data: %w", err)
+	}
+
+	options := &models.StartingEquipmentOptions{
+		Class:               classData.Name,
+		Choices:             []models.EquipmentChoiceGroup{},
+		Fixed:               []models.EquipmentEntry{},
+		BackgroundEquipment: []models.EquipmentEntry{},
+		StartingWealth:      classData.StartingWealth,
+	}
+
+	for _, raw := range classData.StartingEquipment {
+		switch entry := raw.(type) {
+		case string:
+			options.Fixed = append(options.Fixed, s.parseEquipmentText(entry)...)
+		case map[string]interface{}:
+			choices, _ := entry["choice"].([]interface{})
+			group := models.EquipmentChoiceGroup{Index: len(options.Choices)}
+			for _, choice := range choices {
+				text, ok := choice.(string)
+				if !ok {
+					continue
+				}
+				group.Options = append(group.Options, models.EquipmentOption{
+					Description: text,
+					Entries:     s.parseEquipmentText(text),
+				})
+			}
+			if len(group.Options) > 0 {
+				options.Choices = append(options.Choices, group)
+			}
+		}
+	}
+
+	if background != "" {
+		backgroundData, err := s.builder.loadBackgroundData(dataFileName(background))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load background data: %w", err)
+		}
+		options.Background = backgroundData.Name
+		for _, text := range backgroundData.Equipment {
+			options.BackgroundEquipment = append(options.BackgroundEquipment, s.parseEquipmentText(text)...)
+		}
+	}
+
+	return options, nil
+}
+
+// classEquipmentData is the subset of a class file needed for starting equipment
+type classEquipmentData struct {
+	Name              string                 `json:"name"`
+	StartingEquipment []interface{}          `json:"startingEquipment"`
+	StartingWealth    *models.StartingWealth `json:"startingWealth"`
+}
+
+func (s *StartingEquipmentService) loadClassEquipment(class string) (*classEquipmentData, error) {
+	// Validate input to prevent path traversal
+	if err := validateFileName(class); err != nil {
+		return nil, fmt.Errorf("invalid class name: %w", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.dataPath, "classes", class+".json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var classData classEquipmentData
+	if err := json.Unmarshal(data, &classData); err != nil {
+		return nil, err
+	}
+
+	return &classData, nil
+}
+
+// GetStartingEquipmentGrant returns the recorded starting equipment for a character, if any
+func (s *StartingEquipmentService) GetStartingEquipmentGrant(characterID string) (*models.StartingEquipmentGrant, error) {
+	return s.inventoryRepo.GetStartingEquipmentGrant(characterID)
+}
+
+// ApplyStartingEquipment resolves the player's selection and writes the items and
+// gold to the character's inventory. It can only be applied once per character.
+func (s *StartingEquipmentService) ApplyStartingEquipment(ctx context.Context, characterID string, selection *models.StartingEquipmentSelection) (*models.StartingEquipmentGrant, error) {
+	existing, err := s.inventoryRepo.GetStartingEquipmentGrant(characterID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("starting equipment has already been granted")
+	}
+
+	character, err := s.characterRepo.GetByID(ctx, characterID)
+	if err != nil {
+		return nil, err
+	}
+	if character == nil {
+		return nil, fmt.Errorf("character not found")
+	}
+
+	options, err := s.GetStartingEquipmentOptions(character.Class, character.Background)
+	if err != nil {
+		return nil, err
+	}
+
+	method := selection.Method
+	if method == "" {
+		method = models.StartingEquipmentMethodEquipment
+	}
+
+	grant := &models.StartingEquipmentGrant{
+		CharacterID: characterID,
+		Method:      method,
+		Items:       models.GrantedItems{},
+	}
+
+	var lines []grantLine
+	switch method {
+	case models.StartingEquipmentMethodEquipment:
+		classLines, err := s.resolveClassEquipment(options, selection)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, classLines...)
+	case models.StartingEquipmentMethodGold:
+		if options.StartingWealth == nil {
+			return nil, fmt.Errorf("class %s has no starting wealth formula", options.Class)
+		}
+		result, err := s.roller.Roll(options.StartingWealth.Dice)
+		if err != nil {
+			return nil, fmt.Errorf("failed to roll starting wealth: %w", err)
+		}
+		grant.WealthRoll = result.Total
+		grant.GoldAwarded += result.Total * options.StartingWealth.Multiplier
+	default:
+		return nil, fmt.Errorf("invalid starting equipment method: %s", method)
+	}
+
+	// Background equipment is granted with either method
+	backgroundLines, err := s.resolveEntries(options.BackgroundEquipment, selection.BackgroundSubstitutions)
+	if err != nil {
+		return nil, err
+	}
+	lines = append(lines, backgroundLines...)
+
+	txn := &models.EconomyTransaction{
+		Type:              models.LedgerEntryStartingEquipment,
+		Description:       fmt.Sprintf("starting equipment (%s)", method),
+		StartingEquipment: grant,
+	}
+	for _, line := range lines {
+		if line.gold > 0 {
+			grant.GoldAwarded += line.gold
+			continue
+		}
+		item, err := s.ensureItem(line)
+		if err != nil {
+			return nil, err
+		}
+		txn.Items = append(txn.Items, models.ItemMovement{CharacterID: characterID, ItemID: item.ID, Quantity: line.quantity})
+		grant.Items = appendGrantedItem(grant.Items, item, line.quantity)
+	}
+	if grant.GoldAwarded > 0 {
+		txn.Currency = []models.CurrencyMovement{{CharacterID: characterID, Coins: models.Coins{Gold: grant.GoldAwarded}}}
+	}
+
+	// The grant row, items and gold are written together, so a failure or a concurrent
+	// request can never leave the character with its equipment applied twice
+	if _, err := s.inventoryService.applyTransaction(ctx, txn); err != nil {
+		return nil, fmt.Errorf("failed to apply starting equipment: %w", err)
+	}
+
+	return grant, nil
+}
+
+// grantLine is a resolved entry ready to be written to the inventory
+type grantLine struct {
+	catalogItem *CatalogItem
+	name        string
+	quantity    int
+	gold        int
+}
+
+func (s *StartingEquipmentService) resolveClassEquipment(options *models.StartingEquipmentOptions, selection *models.StartingEquipmentSelection) ([]grantLine, error) {
+	chosen := make(map[int]models.EquipmentChoiceSelection, len(selection.Choices))
+	for _, choice := range selection.Choices {
+		chosen[choice.Group] = choice
+	}
+
+	var lines []grantLine
+	for _, group := range options.Choices {
+		choice, ok := chosen[group.Index]
+		if !ok {
+			return nil, fmt.Errorf("missing selection for equipment choice %d", group.Index)
+		}
+		if choice.Option < 0 || choice.Option >= len(group.Options) {
+			return nil, fmt.Errorf("invalid option %d for equipment choice %d", choice.Option, group.Index)
+		}
+		groupLines, err := s.resolveEntries(group.Options[choice.Option].Entries, choice.Substitutions)
+		if err != nil {
+			return nil, fmt.Errorf("equipment choice %d: %w", group.Index, err)
+		}
+		lines = append(lines, groupLines...)
+	}
+
+	fixedLines, err := s.resolveEntries(options.Fixed, selection.FixedSubstitutions)
+	if err != nil {
+		return nil, err
+	}
+	return append(lines, fixedLines...), nil
+}
+
+// resolveEntries expands packs and applies substitutions for placeholder and
+// "X or Y" entries.
+func (s *StartingEquipmentService) resolveEntries(entries []models.EquipmentEntry, substitutions map[int][]string) ([]grantLine, error) {
+	var lines []grantLine
+	for i, entry := range entries {
+		if entry.Gold > 0 {
+			lines = append(lines, grantLine{gold: entry.Gold})
+			continue
+		}
+
+		picks := substitutions[i]
+		if entry.Category != "" && len(picks) == 0 {
+			return nil, fmt.Errorf("a %s must be chosen for %q", entry.Category, entry.Name)
+		}
+		if len(picks) == 0 {
+			line, err := s.entryLine(entry)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, line...)
+			continue
+		}
+
+		// Either one pick for the whole quantity or one pick per item
+		if len(picks) != 1 && len(picks) != entry.Quantity {
+			return nil, fmt.Errorf("expected 1 or %d choices for %q, got %d", entry.Quantity, entry.Name, len(picks))
+		}
+		perPick := entry.Quantity
+		if len(picks) > 1 {
+			perPick = 1
+		}
+		for _, pick := range picks {
+			item, err := s.validateSubstitution(entry, pick)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, grantLine{catalogItem: item, name: item.Name, quantity: perPick})
+		}
+	}
+	return lines, nil
+}
+
+func (s *StartingEquipmentService) entryLine(entry models.EquipmentEntry) ([]grantLine, error) {
+	if entry.IsPack {
+		pack := s.catalog.FindPack(entry.ItemID)
+		if pack == nil {
+			return nil, fmt.Errorf("unknown equipment pack: %s", entry.Name)
+		}
+		lines := make([]grantLine, 0, len(pack.Contents))
+		for _, content := range pack.Contents {
+			item := s.catalog.Get(content.Item)
+			if item == nil {
+				return nil, fmt.Errorf("equipment pack %s references unknown item %s", pack.Name, content.Item)
+			}
+			lines = append(lines, grantLine{catalogItem: item, name: item.Name, quantity: content.Quantity * entry.Quantity})
+		}
+		return lines, nil
+	}
+
+	line := grantLine{name: entry.Name, quantity: entry.Quantity}
+	if entry.ItemID != "" {
+		line.catalogItem = s.catalog.Get(entry.ItemID)
+	}
+	return []grantLine{line}, nil
+}
+
+func (s *StartingEquipmentService) validateSubstitution(entry models.EquipmentEntry, pick string) (*CatalogItem, error) {
+	item := s.catalog.Find(pick)
+	if item == nil {
+		return nil, fmt.Errorf("unknown item: %s", pick)
+	}
+
+	if entry.Category != "" {
+		if !catalogItemMatchesCategory(item, entry.Category) {
+			return nil, fmt.Errorf("%s is not a %s", item.Name, entry.Category)
+		}
+		return item, nil
+	}
+
+	allowed := append([]string{entry.Name}, entry.Alternatives...)
+	for _, alternative := range allowed {
+		if match := s.catalog.Find(alternative); match != nil && match.ID == item.ID {
+			return item, nil
+		}
+	}
+	return nil, fmt.Errorf("%s is not an option for %q", item.Name, entry.Name)
+}
+
+// ensureItem makes sure the granted item exists in the items table, creating it
+// from the catalog (or as a generic item for flavor gear) when missing.
+func (s *StartingEquipmentService) ensureItem(line grantLine) (*models.Item, error) {
+	var candidate *models.Item
+	if line.catalogItem != nil {
+		candidate = line.catalogItem.ToItem()
+	} else {
+		candidate = &models.Item{
+			ID:          "starting_" + catalogSlug(line.name),
+			Name:        titleCase(line.name),
+			Type:        models.ItemTypeOther,
+			Rarity:      models.ItemRarityCommon,
+			Properties:  models.ItemProperties{},
+			Description: "Starting equipment.",
+		}
+	}
+
+	item, err := s.inventoryRepo.GetItem(candidate.ID)
+	if err != nil {
+		return nil, err
+	}
+	if item != nil {
+		return item, nil
+	}
+
+	if err := s.inventoryRepo.CreateItem(candidate); err != nil {
+		return nil, fmt.Errorf("failed to create item %s: %w", candidate.Name, err)
+	}
+	return candidate, nil
+}
+
+func appendGrantedItem(items models.GrantedItems, item *models.Item, quantity int) models.GrantedItems {
+	for i := range items {
+		if items[i].ItemID == item.ID {
+			items[i].Quantity += quantity
+			return items
+		}
+	}
+	return append(items, models.GrantedItem{ItemID: item.ID, Name: item.Name, Quantity: quantity})
+}
+
+// parseEquipmentText splits a line such as "Leather armor, longbow, and 20 arrows"
+// into individual entries.
+func (s *StartingEquipmentService) parseEquipmentText(text string) []models.EquipmentEntry {
+	lower := strings.ToLower(strings.TrimSpace(text))
+
+	// Background gold pouches keep the container as flavor but grant coins
+	if m := equipmentGoldPattern.FindStringSubmatch(lower); m != nil {
+		gold, _ := strconv.Atoi(m[1])
+		return []models.EquipmentEntry{{Name: strings.TrimSpace(text), Quantity: 1, Gold: gold}}
+	}
+
+	// Commas and "and" separate items
+	var entries []models.EquipmentEntry
+	for _, part := range equipmentAndSplitPattern.Split(lower, -1) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		entries = append(entries, s.parseEquipmentPart(part)...)
+	}
+	return entries
+}
+
+func (s *StartingEquipmentService) parseEquipmentPart(part string) []models.EquipmentEntry {
+	part = strings.TrimSpace(strings.ReplaceAll(part, "(if proficient)", ""))
+
+	category := ""
+	if strings.Contains(part, "(one of your choice)") {
+		part = strings.TrimSpace(strings.ReplaceAll(part, "(one of your choice)", ""))
+		category = matchEquipmentCategory(part)
+	}
+
+	quantity, name := splitEquipmentQuantity(part)
+
+	// "a quiver of 20 arrows" is two items
+	if m := equipmentQuiverPattern.FindStringSubmatch(name); m != nil {
+		count, _ := strconv.Atoi(m[1])
+		quiver := s.newEquipmentEntry("quiver", quantity)
+		ammo := s.newEquipmentEntry(m[2], count)
+		return []models.EquipmentEntry{quiver, ammo}
+	}
+
+	if strings.HasPrefix(name, "any ") {
+		name = strings.TrimPrefix(strings.TrimPrefix(name, "any "), "other ")
+		category = matchEquipmentCategory(name)
+	} else if category == "" {
+		// "Two martial weapons" / "two simple melee weapons"
+		category = exactEquipmentCategory(name)
+	}
+
+	if category != "" {
+		return []models.EquipmentEntry{{Name: name, Quantity: quantity, Category: category}}
+	}
+
+	alternatives := strings.Split(name, " or ")
+	entry := s.newEquipmentEntry(strings.TrimSpace(alternatives[0]), quantity)
+	if len(alternatives) > 1 {
+		for _, alternative := range alternatives {
+			entry.Alternatives = append(entry.Alternatives, stripEquipmentArticle(strings.TrimSpace(alternative)))
+		}
+	}
+	return []models.EquipmentEntry{entry}
+}
+
+func (s *StartingEquipmentService) newEquipmentEntry(name string, quantity int) models.EquipmentEntry {
+	entry := models.EquipmentEntry{Name: name, Quantity: quantity}
+	if s.catalog == nil {
+		return entry
+	}
+	if pack := s.catalog.FindPack(name); pack != nil {
+		entry.ItemID = pack.ID
+		entry.IsPack = true
+		return entry
+	}
+	if item := s.catalog.Find(name); item != nil {
+		entry.ItemID = item.ID
+	}
+	return entry
+}
+
+// splitEquipmentQuantity removes leading articles and counts, e.g.
+// "two handaxes" -> (2, "handaxes"), "a set of common clothes" -> (1, "common clothes")
+func splitEquipmentQuantity(part string) (int, string) {
+	quantity := 1
+	words := strings.Fields(part)
+	if len(words) > 1 {
+		if n, ok := equipmentQuantityWords[words[0]]; ok {
+			quantity = n
+			words = words[1:]
+		} else if n, err := strconv.Atoi(words[0]); err == nil && n > 0 {
+			quantity = n
+			words = words[1:]
+		}
+	}
+	name := stripEquipmentArticle(strings.Join(words, " "))
+	for _, prefix := range []string{"set of ", "sticks of "} {
+		name = strings.TrimPrefix(name, prefix)
+	}
+	return quantity, name
+}
+
+func stripEquipmentArticle(name string) string {
+	for _, article := range []string{"a ", "an ", "the "} {
+		if strings.HasPrefix(name, article) {
+			return strings.TrimPrefix(name, article)
+		}
+	}
+	return name
+}
+
+func matchEquipmentCategory(name string) string {
+	for _, category := range equipmentCategories {
+		if strings.Contains(name, category) || strings.Contains(name, strings.ReplaceAll(category, "'", "")) {
+			return category
+		}
+	}
+	return ""
+}
+
+func exactEquipmentCategory(name string) string {
+	singular := strings.TrimSuffix(name, "s")
+	for _, category := range equipmentCategories {
+		if name == category || singular == category {
+			return category
+		}
+	}
+	return ""
+}
+
+func catalogItemMatchesCategory(item *CatalogItem, category string) bool {
+	switch category {
+	case EquipmentCategoryMusicalInstrument:
+		return item.HasTag("musical_instrument")
+	case EquipmentCategoryArtisansTools:
+		return item.HasTag("artisans_tools")
+	}
+
+	if item.Type != models.ItemTypeWeapon {
+		return false
+	}
+	weaponType, _ := item.Properties["weapon_type"].(string)
+	melee, _ := item.Properties["melee"].(bool)
+	switch category {
+	case EquipmentCategorySimpleWeapon:
+		return weaponType == "simple"
+	case EquipmentCategorySimpleMeleeWeapon:
+		return weaponType == "simple" && melee
+	case EquipmentCategoryMartialWeapon:
+		return weaponType == "martial"
+	case EquipmentCategoryMartialMeleeWeapon:
+		return weaponType == "martial" && melee
+	}
+	return false
+}
+
+// dataFileName maps a display name such as "Folk Hero" to its data file name
+func dataFileName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "-")
+}
+
+func titleCase(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
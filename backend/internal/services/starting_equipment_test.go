@@ -0,0 +1,283 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/internal/services"
+	"github.com/ctclostio/DnD-Game/backend/internal/services/mocks"
+)
+
+const (
+	testGameDataPath        = "../../../data"
+	testStartingCharacterID = "char-start-1"
+	testMethodGetGrant      = "GetStartingEquipmentGrant"
+)
+
+func createTestStartingEquipmentService(catalog *services.ItemCatalog, inventoryRepo *mocks.MockInventoryRepository, characterRepo *mocks.MockCharacterRepository) *services.StartingEquipmentService {
+	inventoryService := services.NewInventoryService(inventoryRepo, characterRepo)
+	return services.NewStartingEquipmentService(testGameDataPath, catalog, inventoryService, inventoryRepo, characterRepo)
+}
+
+// wizardStartingItems is what a wizard taking the first option of every choice starts with
+func wizardStartingItems(catalog *services.ItemCatalog) map[string]int {
+	expected := map[string]int{"quarterstaff": 1, "component_pouch": 1, "spellbook": 1}
+	for _, content := range catalog.FindPack("scholars_pack").Contents {
+		expected[content.Item] += content.Quantity
+	}
+	return expected
+}
+
+// appliedTransaction returns the economy transaction a test's service applied, if any
+func appliedTransaction(inventoryRepo *mocks.MockInventoryRepository) *models.EconomyTransaction {
+	for _, call := range inventoryRepo.Calls {
+		if call.Method == testMethodApplyTransaction {
+			return call.Arguments.Get(0).(*models.EconomyTransaction)
+		}
+	}
+	return nil
+}
+
+func TestItemCatalog_Find(t *testing.T) {
+	catalog, err := services.NewItemCatalog(testGameDataPath)
+	require.NoError(t, err)
+
+	longsword := catalog.Find("Longsword")
+	require.NotNil(t, longsword)
+	assert.Equal(t, 1500, longsword.Value)
+	assert.Equal(t, "1d10", longsword.Properties["versatile"])
+	assert.Equal(t, "martial", longsword.Properties["weapon_type"])
+
+	greataxe := catalog.Find("greataxe")
+	require.NotNil(t, greataxe)
+	assert.Equal(t, true, greataxe.Properties["two_handed"])
+
+	assert.Equal(t, "crossbow_bolt", catalog.Find("bolts").ID)
+	assert.Equal(t, "handaxe", catalog.Find("handaxes").ID)
+	assert.Equal(t, "chain_mail", catalog.Find("chain mail").ID)
+	assert.NotNil(t, catalog.FindPack("explorer's pack"))
+	assert.Nil(t, catalog.Find("vorpal spoon"))
+}
+
+func TestStartingEquipmentService_GetStartingEquipmentOptions(t *testing.T) {
+	catalog, err := services.NewItemCatalog(testGameDataPath)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		class       string
+		background  string
+		expectError bool
+		validate    func(*testing.T, *models.StartingEquipmentOptions)
+	}{
+		{
+			name:       "Barbarian with folk hero",
+			class:      "Barbarian",
+			background: "Folk Hero",
+			validate: func(t *testing.T, options *models.StartingEquipmentOptions) {
+				require.Len(t, options.Choices, 2)
+				greataxe := options.Choices[0].Options[0].Entries[0]
+				assert.Equal(t, "greataxe", greataxe.ItemID)
+				martial := options.Choices[0].Options[1].Entries[0]
+				assert.Equal(t, services.EquipmentCategoryMartialMeleeWeapon, martial.Category)
+				handaxes := options.Choices[1].Options[0].Entries[0]
+				assert.Equal(t, "handaxe", handaxes.ItemID)
+				assert.Equal(t, 2, handaxes.Quantity)
+
+				require.Len(t, options.Fixed, 2)
+				assert.True(t, options.Fixed[0].IsPack)
+				assert.Equal(t, "explorers_pack", options.Fixed[0].ItemID)
+				assert.Equal(t, "javelin", options.Fixed[1].ItemID)
+				assert.Equal(t, 4, options.Fixed[1].Quantity)
+
+				require.NotNil(t, options.StartingWealth)
+				assert.Equal(t, "2d4", options.StartingWealth.Dice)
+				assert.Equal(t, 10, options.StartingWealth.Multiplier)
+
+				assert.Equal(t, services.EquipmentCategoryArtisansTools, options.BackgroundEquipment[0].Category)
+				gold := options.BackgroundEquipment[len(options.BackgroundEquipment)-1]
+				assert.Equal(t, 10, gold.Gold)
+			},
+		},
+		{
+			name:       "Quiver and alternatives",
+			class:      "ranger",
+			background: "soldier",
+			validate: func(t *testing.T, options *models.StartingEquipmentOptions) {
+				require.Len(t, options.Fixed, 3)
+				assert.Equal(t, "longbow", options.Fixed[0].ItemID)
+				assert.Equal(t, "quiver", options.Fixed[1].ItemID)
+				assert.Equal(t, "arrow", options.Fixed[2].ItemID)
+				assert.Equal(t, 20, options.Fixed[2].Quantity)
+
+				var dice *models.EquipmentEntry
+				for i := range options.BackgroundEquipment {
+					if len(options.BackgroundEquipment[i].Alternatives) > 0 {
+						dice = &options.BackgroundEquipment[i]
+					}
+				}
+				require.NotNil(t, dice)
+				assert.Equal(t, []string{"bone dice", "deck of cards"}, dice.Alternatives)
+			},
+		},
+		{
+			name:        "Unknown class",
+			class:       "../etc",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := createTestStartingEquipmentService(catalog, new(mocks.MockInventoryRepository), new(mocks.MockCharacterRepository))
+			options, err := svc.GetStartingEquipmentOptions(tt.class, tt.background)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.validate != nil {
+				tt.validate(t, options)
+			}
+		})
+	}
+}
+
+func TestStartingEquipmentService_ApplyStartingEquipment(t *testing.T) {
+	catalog, err := services.NewItemCatalog(testGameDataPath)
+	require.NoError(t, err)
+	wizard := &models.Character{ID: testStartingCharacterID, Class: "Wizard"}
+	fighter := &models.Character{ID: testStartingCharacterID, Class: "Fighter"}
+
+	tests := []struct {
+		name        string
+		selection   *models.StartingEquipmentSelection
+		setupMocks  func(*mocks.MockInventoryRepository, *mocks.MockCharacterRepository)
+		expectError string
+		validate    func(*testing.T, *models.StartingEquipmentGrant, *models.EconomyTransaction)
+	}{
+		{
+			name: "Equipment method grants items and packs",
+			selection: &models.StartingEquipmentSelection{
+				Method: models.StartingEquipmentMethodEquipment,
+				Choices: []models.EquipmentChoiceSelection{
+					{Group: 0, Option: 0},
+					{Group: 1, Option: 0},
+					{Group: 2, Option: 0},
+				},
+			},
+			setupMocks: func(inventoryRepo *mocks.MockInventoryRepository, characterRepo *mocks.MockCharacterRepository) {
+				inventoryRepo.On(testMethodGetGrant, testStartingCharacterID).Return(nil, nil)
+				characterRepo.On(testMethodGetByID, mock.Anything, testStartingCharacterID).Return(wizard, nil)
+				for id := range wizardStartingItems(catalog) {
+					inventoryRepo.On(testMethodGetItem, id).Return(catalog.Get(id).ToItem(), nil)
+				}
+				inventoryRepo.On(testMethodApplyTransaction, mock.AnythingOfType("*models.EconomyTransaction")).
+					Return([]*models.LedgerEntry{{CharacterID: testStartingCharacterID}}, nil).Once()
+			},
+			validate: func(t *testing.T, grant *models.StartingEquipmentGrant, applied *models.EconomyTransaction) {
+				expected := wizardStartingItems(catalog)
+				assert.Equal(t, models.StartingEquipmentMethodEquipment, grant.Method)
+				assert.Equal(t, 0, grant.GoldAwarded)
+				granted := map[string]int{}
+				for _, item := range grant.Items {
+					granted[item.ItemID] = item.Quantity
+				}
+				assert.Equal(t, expected, granted)
+
+				require.NotNil(t, applied)
+				assert.Same(t, grant, applied.StartingEquipment)
+				assert.Empty(t, applied.Currency)
+				moved := map[string]int{}
+				for _, movement := range applied.Items {
+					moved[movement.ItemID] += movement.Quantity
+				}
+				assert.Equal(t, expected, moved)
+			},
+		},
+		{
+			name:      "Gold method rolls starting wealth",
+			selection: &models.StartingEquipmentSelection{Method: models.StartingEquipmentMethodGold},
+			setupMocks: func(inventoryRepo *mocks.MockInventoryRepository, characterRepo *mocks.MockCharacterRepository) {
+				inventoryRepo.On(testMethodGetGrant, testStartingCharacterID).Return(nil, nil)
+				characterRepo.On(testMethodGetByID, mock.Anything, testStartingCharacterID).Return(wizard, nil)
+				inventoryRepo.On(testMethodApplyTransaction, mock.MatchedBy(func(txn *models.EconomyTransaction) bool {
+					return txn.StartingEquipment != nil && len(txn.Currency) == 1 &&
+						txn.Currency[0].Coins.Gold >= 40 && txn.Currency[0].Coins.Gold <= 160
+				})).Return([]*models.LedgerEntry{{CharacterID: testStartingCharacterID}}, nil).Once()
+			},
+			validate: func(t *testing.T, grant *models.StartingEquipmentGrant, _ *models.EconomyTransaction) {
+				assert.Empty(t, grant.Items)
+				assert.GreaterOrEqual(t, grant.WealthRoll, 4)
+				assert.LessOrEqual(t, grant.WealthRoll, 16)
+				assert.Equal(t, grant.WealthRoll*10, grant.GoldAwarded)
+			},
+		},
+		{
+			name: "Substitution outside the category",
+			selection: &models.StartingEquipmentSelection{
+				Choices: []models.EquipmentChoiceSelection{
+					{Group: 0, Option: 0},
+					{Group: 1, Option: 0, Substitutions: map[int][]string{0: {"dagger"}}},
+					{Group: 2, Option: 1},
+					{Group: 3, Option: 1},
+				},
+			},
+			setupMocks: func(inventoryRepo *mocks.MockInventoryRepository, characterRepo *mocks.MockCharacterRepository) {
+				inventoryRepo.On(testMethodGetGrant, testStartingCharacterID).Return(nil, nil)
+				characterRepo.On(testMethodGetByID, mock.Anything, testStartingCharacterID).Return(fighter, nil)
+			},
+			expectError: "is not a martial weapon",
+		},
+		{
+			name:      "Missing choice",
+			selection: &models.StartingEquipmentSelection{},
+			setupMocks: func(inventoryRepo *mocks.MockInventoryRepository, characterRepo *mocks.MockCharacterRepository) {
+				inventoryRepo.On(testMethodGetGrant, testStartingCharacterID).Return(nil, nil)
+				characterRepo.On(testMethodGetByID, mock.Anything, testStartingCharacterID).Return(wizard, nil)
+			},
+			expectError: "missing selection",
+		},
+		{
+			name:      "Already granted",
+			selection: &models.StartingEquipmentSelection{},
+			setupMocks: func(inventoryRepo *mocks.MockInventoryRepository, _ *mocks.MockCharacterRepository) {
+				inventoryRepo.On(testMethodGetGrant, testStartingCharacterID).
+					Return(&models.StartingEquipmentGrant{CharacterID: testStartingCharacterID}, nil)
+			},
+			expectError: "already been granted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inventoryRepo := new(mocks.MockInventoryRepository)
+			characterRepo := new(mocks.MockCharacterRepository)
+			if tt.setupMocks != nil {
+				tt.setupMocks(inventoryRepo, characterRepo)
+			}
+
+			svc := createTestStartingEquipmentService(catalog, inventoryRepo, characterRepo)
+			grant, err := svc.ApplyStartingEquipment(context.Background(), testStartingCharacterID, tt.selection)
+
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+			} else {
+				require.NoError(t, err)
+				if tt.validate != nil {
+					tt.validate(t, grant, appliedTransaction(inventoryRepo))
+				}
+			}
+
+			inventoryRepo.AssertExpectations(t)
+			characterRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/pkg/dice"
+	"github.com/ctclostio/DnD-Game/backend/pkg/game"
+)
+
+// TreasureService rolls individual and hoard treasure on the treasure tables. The same
+// request and seed always roll the same treasure; the AI only adds a description on top.
+type TreasureService struct {
+	tables      *TreasureTables
+	llmProvider LLMProvider
+	loot        *LootService
+}
+
+// NewTreasureService creates a new treasure service
+func NewTreasureService(tables *TreasureTables) *TreasureService {
+	return &TreasureService{
+		tables: tables,
+	}
+}
+
+// SetLLMProvider lets treasure requests ask for a description of what was found
+func (s *TreasureService) SetLLMProvider(llmProvider LLMProvider) {
+	s.llmProvider = llmProvider
+}
+
+// SetLootService lets rolled treasure go straight into a session's loot pool
+func (s *TreasureService) SetLootService(loot *LootService) {
+	s.loot = loot
+}
+
+// treasureRoll draws every die of one treasure roll from a single seeded source
+type treasureRoll struct {
+	rng    *game.Random
+	roller *dice.Roller
+}
+
+// Generate rolls treasure for a creature or hoard of the given challenge rating. Without a
+// seed it picks one and returns it, so the DM can roll the same treasure again.
+func (s *TreasureService) Generate(ctx context.Context, req *models.TreasureRequest) (*models.TreasureHoard, error) {
+	treasureType := strings.ToLower(req.TreasureType)
+	if treasureType == "" {
+		treasureType = models.TreasureTypeHoard
+	}
+	if treasureType != models.TreasureTypeHoard && treasureType != models.TreasureTypeIndividual {
+		return nil, fmt.Errorf("unknown treasure type %q", req.TreasureType)
+	}
+	if req.ChallengeRating < 0 {
+		return nil, fmt.Errorf("challenge rating cannot be negative")
+	}
+
+	seed := time.Now().UnixNano()
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
+	rng := game.NewSeededRandom(seed)
+	roll := &treasureRoll{rng: rng, roller: dice.NewRollerWithRandom(rng)}
+
+	hoard := &models.TreasureHoard{
+		TreasureType:    treasureType,
+		ChallengeRating: req.ChallengeRating,
+		Seed:            seed,
+	}
+	var err error
+	if treasureType == models.TreasureTypeIndividual {
+		err = s.rollIndividual(roll, hoard)
+	} else {
+		err = s.rollHoard(roll, hoard)
+	}
+	if err != nil {
+		return nil, err
+	}
+	summarizeTreasure(hoard)
+
+	if req.WithFlavor && s.llmProvider != nil {
+		// The description is decoration; the treasure stands without it
+		if flavor, err := s.describeTreasure(ctx, hoard, req.Context); err == nil {
+			hoard.Flavor = flavor
+		}
+	}
+	return hoard, nil
+}
+
+// GenerateLootPool rolls treasure and puts it up for a game session's characters
+func (s *TreasureService) GenerateLootPool(ctx context.Context, sessionID string, req *models.TreasurePoolRequest) (*models.TreasurePool, error) {
+	if s.loot == nil {
+		return nil, fmt.Errorf("loot pools are not available")
+	}
+
+	hoard, err := s.Generate(ctx, &req.TreasureRequest)
+	if err != nil {
+		return nil, err
+	}
+	poolReq := hoard.LootPoolRequest(req.Mode)
+	poolReq.CharacterIDs = req.CharacterIDs
+	if poolReq.Coins.IsZero() && len(poolReq.Items) == 0 {
+		return &models.TreasurePool{Hoard: hoard}, nil
+	}
+
+	pool, err := s.loot.CreatePool(ctx, sessionID, poolReq)
+	if err != nil {
+		return nil, err
+	}
+	return &models.TreasurePool{Hoard: hoard, Pool: pool}, nil
+}
+
+func (s *TreasureService) rollIndividual(roll *treasureRoll, hoard *models.TreasureHoard) error {
+	tier := treasureTierFor(s.tables.individual, hoard.ChallengeRating)
+	row := tier.rowFor(roll.d100())
+	return roll.coins(row.Coins, &hoard.Coins)
+}
+
+func (s *TreasureService) rollHoard(roll *treasureRoll, hoard *models.TreasureHoard) error {
+	tier := treasureTierFor(s.tables.hoard, hoard.ChallengeRating)
+	if err := roll.coins(tier.Coins, &hoard.Coins); err != nil {
+		return err
+	}
+
+	row := tier.rowFor(roll.d100())
+	if err := roll.coins(row.Coins, &hoard.Coins); err != nil {
+		return err
+	}
+	if row.Gems != nil {
+		picked, err := roll.valuables(row.Gems, s.tables.gems[row.Gems.Value])
+		if err != nil {
+			return err
+		}
+		for _, p := range picked {
+			hoard.Gems = append(hoard.Gems, models.Gem{
+				ItemID: p.item.ID, Name: p.item.Name, Value: row.Gems.Value, Quantity: p.quantity, Description: p.item.Description,
+			})
+		}
+	}
+	if row.Art != nil {
+		picked, err := roll.valuables(row.Art, s.tables.art[row.Art.Value])
+		if err != nil {
+			return err
+		}
+		for _, p := range picked {
+			hoard.ArtObjects = append(hoard.ArtObjects, models.ArtObject{
+				ItemID: p.item.ID, Name: p.item.Name, Value: row.Art.Value, Quantity: p.quantity, Description: p.item.Description,
+			})
+		}
+	}
+	for _, magic := range row.MagicItems {
+		count, err := roll.count(magic.Dice)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < count; i++ {
+			item := s.tables.magicItemFor(magic.Table, roll.d100())
+			hoard.MagicItemDetails = append(hoard.MagicItemDetails, treasureMagicItem(item, magic.Table))
+		}
+	}
+	return nil
+}
+
+func (r *treasureRoll) d100() int {
+	return r.rng.RollDice(100)
+}
+
+// count rolls how many of something there are; a bare number is fixed
+func (r *treasureRoll) count(notation string) (int, error) {
+	if n, err := strconv.Atoi(notation); err == nil {
+		return n, nil
+	}
+	result, err := r.roller.Roll(notation)
+	if err != nil {
+		return 0, fmt.Errorf("invalid treasure dice %q: %w", notation, err)
+	}
+	return result.Total, nil
+}
+
+func (r *treasureRoll) coins(rolls []treasureCoins, into *models.CoinageBreakdown) error {
+	for _, c := range rolls {
+		amount, err := r.count(c.Dice)
+		if err != nil {
+			return err
+		}
+		if c.Multiplier > 0 {
+			amount *= c.Multiplier
+		}
+		switch c.Currency {
+		case "cp":
+			into.Copper += amount
+		case "sp":
+			into.Silver += amount
+		case "ep":
+			into.Electrum += amount
+		case "gp":
+			into.Gold += amount
+		case "pp":
+			into.Platinum += amount
+		default:
+			return fmt.Errorf("unknown currency %q", c.Currency)
+		}
+	}
+	return nil
+}
+
+type pickedValuable struct {
+	item     *CatalogItem
+	quantity int
+}
+
+// valuables rolls how many gems or art objects there are and picks each one from the
+// value table, grouping repeats
+func (r *treasureRoll) valuables(roll *treasureValuables, table []*CatalogItem) ([]pickedValuable, error) {
+	count, err := r.count(roll.Dice)
+	if err != nil {
+		return nil, err
+	}
+
+	var picked []pickedValuable
+	index := make(map[string]int)
+	for i := 0; i < count; i++ {
+		item := table[r.rng.Intn(len(table))]
+		if at, ok := index[item.ID]; ok {
+			picked[at].quantity++
+			continue
+		}
+		index[item.ID] = len(picked)
+		picked = append(picked, pickedValuable{item: item, quantity: 1})
+	}
+	return picked, nil
+}
+
+func treasureMagicItem(item *CatalogItem, table string) models.MagicItem {
+	magic := models.MagicItem{
+		ItemID:      item.ID,
+		Table:       table,
+		Name:        item.Name,
+		Rarity:      string(item.Rarity),
+		Description: item.Description,
+		Properties:  []string{},
+	}
+	if item.RequiresAttunement {
+		attunement := "requires attunement"
+		if item.AttunementRequirements != "" {
+			attunement += " " + item.AttunementRequirements
+		}
+		magic.Properties = append(magic.Properties, attunement)
+	}
+	if item.Properties["cursed"] == true {
+		magic.Properties = append(magic.Properties, "cursed")
+	}
+	return magic
+}
+
+// summarizeTreasure fills the flat currency and item lists and the total value in gp.
+// Magic items are not counted in the total; they are rarely for sale.
+func summarizeTreasure(hoard *models.TreasureHoard) {
+	coins := hoard.Coins
+	hoard.Currency = make(map[string]int)
+	for currency, amount := range map[string]int{"cp": coins.Copper, "sp": coins.Silver, "ep": coins.Electrum, "gp": coins.Gold, "pp": coins.Platinum} {
+		if amount > 0 {
+			hoard.Currency[currency] = amount
+		}
+	}
+
+	total := coins.Coins().TotalInCopper() / 100
+	hoard.Items = []string{}
+	for _, gem := range hoard.Gems {
+		hoard.Items = append(hoard.Items, valuableName(gem.Name, gem.Value, gem.Quantity))
+		total += gem.Value * gem.Quantity
+	}
+	for _, art := range hoard.ArtObjects {
+		hoard.Items = append(hoard.Items, valuableName(art.Name, art.Value, art.Quantity))
+		total += art.Value * art.Quantity
+	}
+	hoard.MagicItems = []string{}
+	for _, item := range hoard.MagicItemDetails {
+		hoard.MagicItems = append(hoard.MagicItems, item.Name)
+	}
+	hoard.SpecialItems = []string{}
+	hoard.TotalValue = total
+}
+
+func valuableName(name string, value, quantity int) string {
+	if quantity > 1 {
+		return fmt.Sprintf("%d x %s (%d gp each)", quantity, name, value)
+	}
+	return fmt.Sprintf("%s (%d gp)", name, value)
+}
+
+// describeTreasure asks the AI for a short description of the rolled treasure. It may not
+// add, remove or change anything.
+func (s *TreasureService) describeTreasure(ctx context.Context, hoard *models.TreasureHoard, setting string) (string, error) {
+	systemPrompt := `You are a Dungeon Master describing treasure the party has just found in a D&D game.
+Describe only the treasure you are given, in 2-4 vivid sentences: how it is stored, how it looks and what hints at its history.
+Never add, remove or change any coins or items, and never mention game statistics.`
+
+	var contents []string
+	currencies := make([]string, 0, len(hoard.Currency))
+	for currency := range hoard.Currency {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	for _, currency := range currencies {
+		contents = append(contents, fmt.Sprintf("%d %s", hoard.Currency[currency], currency))
+	}
+	contents = append(contents, hoard.Items...)
+	contents = append(contents, hoard.MagicItems...)
+
+	userPrompt := fmt.Sprintf(`Treasure: %s
+Where it was found: %s
+
+Describe this treasure.`, strings.Join(contents, "; "), setting)
+
+	flavor, err := s.llmProvider.GenerateCompletion(ctx, userPrompt, systemPrompt)
+	if err != nil {
+		return "", fmt.Errorf("failed to describe treasure: %w", err)
+	}
+	return strings.TrimSpace(flavor), nil
+}
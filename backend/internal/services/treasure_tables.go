@@ -0,0 +1,237 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Catalog tags that put an item on the gem and art object value tables
+const (
+	gemstoneTag  = "gemstone"
+	artObjectTag = "art_object"
+)
+
+// TreasureTables holds the treasure tables shipped in data/treasure: individual and hoard
+// treasure by challenge rating tier, and magic item tables A to I keyed to the item catalog.
+// The gem and art object value tables are every catalog item tagged gemstone or art_object,
+// grouped by its value in gp.
+type TreasureTables struct {
+	individual []*treasureTier
+	hoard      []*treasureTier
+	magicItems map[string][]*magicItemRow
+	gems       map[int][]*CatalogItem
+	art        map[int][]*CatalogItem
+}
+
+// treasureCoins is a coin roll such as 4d6 x 100 gp
+type treasureCoins struct {
+	Currency   string `json:"currency"`
+	Dice       string `json:"dice"`
+	Multiplier int    `json:"multiplier"`
+}
+
+// treasureValuables is a roll for a number of gems or art objects worth Value gp each
+type treasureValuables struct {
+	Dice  string `json:"dice"`
+	Value int    `json:"value"`
+}
+
+// treasureMagicRoll is a roll for a number of items on one magic item table
+type treasureMagicRoll struct {
+	Table string `json:"table"`
+	Dice  string `json:"dice"`
+}
+
+// treasureRow is one d100 range of a treasure table, covering rolls up to Max
+type treasureRow struct {
+	Max        int                 `json:"max"`
+	Coins      []treasureCoins     `json:"coins"`
+	Gems       *treasureValuables  `json:"gems"`
+	Art        *treasureValuables  `json:"art"`
+	MagicItems []treasureMagicRoll `json:"magic_items"`
+}
+
+// treasureTier is the table for one challenge rating band. Hoards always hold the tier's coins.
+type treasureTier struct {
+	MinCR int             `json:"min_cr"`
+	MaxCR int             `json:"max_cr"`
+	Coins []treasureCoins `json:"coins"`
+	Rows  []treasureRow   `json:"rows"`
+}
+
+type rawMagicItemRow struct {
+	Max  int    `json:"max"`
+	Item string `json:"item"`
+}
+
+// magicItemRow is one d100 range of a magic item table
+type magicItemRow struct {
+	max  int
+	item *CatalogItem
+}
+
+// NewTreasureTables loads every treasure file under dataPath/treasure, resolving magic items
+// against the item catalog
+func NewTreasureTables(dataPath string, items *ItemCatalog) (*TreasureTables, error) {
+	tables := &TreasureTables{
+		magicItems: make(map[string][]*magicItemRow),
+		gems:       make(map[int][]*CatalogItem),
+		art:        make(map[int][]*CatalogItem),
+	}
+
+	dir := filepath.Join(dataPath, "treasure")
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read treasure data: %w", err)
+	}
+
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var wrapped struct {
+			Individual      []*treasureTier              `json:"individual"`
+			Hoard           []*treasureTier              `json:"hoard"`
+			MagicItemTables map[string][]rawMagicItemRow `json:"magic_item_tables"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file.Name(), err)
+		}
+		tables.individual = append(tables.individual, wrapped.Individual...)
+		tables.hoard = append(tables.hoard, wrapped.Hoard...)
+		for name, rows := range wrapped.MagicItemTables {
+			if _, exists := tables.magicItems[name]; exists {
+				return nil, fmt.Errorf("%s: duplicate magic item table %s", file.Name(), name)
+			}
+			resolved, err := resolveMagicItemTable(name, rows, items)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", file.Name(), err)
+			}
+			tables.magicItems[name] = resolved
+		}
+	}
+
+	for _, item := range items.Items() {
+		gp := item.Value / 100
+		switch {
+		case item.HasTag(gemstoneTag):
+			tables.gems[gp] = append(tables.gems[gp], item)
+		case item.HasTag(artObjectTag):
+			tables.art[gp] = append(tables.art[gp], item)
+		}
+	}
+
+	if err := tables.validate(); err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+// treasureTierFor returns the table covering a challenge rating; ratings past the last tier use it
+func treasureTierFor(tiers []*treasureTier, cr int) *treasureTier {
+	for _, tier := range tiers {
+		if cr >= tier.MinCR && cr <= tier.MaxCR {
+			return tier
+		}
+	}
+	if len(tiers) == 0 {
+		return nil
+	}
+	return tiers[len(tiers)-1]
+}
+
+// rowFor returns the row a d100 roll lands on
+func (tier *treasureTier) rowFor(roll int) *treasureRow {
+	for i := range tier.Rows {
+		if roll <= tier.Rows[i].Max {
+			return &tier.Rows[i]
+		}
+	}
+	return &tier.Rows[len(tier.Rows)-1]
+}
+
+// magicItemFor returns the item a d100 roll lands on
+func (t *TreasureTables) magicItemFor(table string, roll int) *CatalogItem {
+	rows := t.magicItems[table]
+	for _, row := range rows {
+		if roll <= row.max {
+			return row.item
+		}
+	}
+	return rows[len(rows)-1].item
+}
+
+// validate checks every table covers 1-100 and every roll it makes can be looked up
+func (t *TreasureTables) validate() error {
+	if len(t.individual) == 0 || len(t.hoard) == 0 {
+		return fmt.Errorf("treasure data needs individual and hoard tables")
+	}
+	for kind, tiers := range map[string][]*treasureTier{"individual": t.individual, "hoard": t.hoard} {
+		sort.Slice(tiers, func(i, j int) bool { return tiers[i].MinCR < tiers[j].MinCR })
+		for _, tier := range tiers {
+			name := fmt.Sprintf("%s treasure CR %d-%d", kind, tier.MinCR, tier.MaxCR)
+			maxes := make([]int, 0, len(tier.Rows))
+			for _, row := range tier.Rows {
+				maxes = append(maxes, row.Max)
+				if row.Gems != nil && len(t.gems[row.Gems.Value]) == 0 {
+					return fmt.Errorf("%s: no %d gp gemstones in the item catalog", name, row.Gems.Value)
+				}
+				if row.Art != nil && len(t.art[row.Art.Value]) == 0 {
+					return fmt.Errorf("%s: no %d gp art objects in the item catalog", name, row.Art.Value)
+				}
+				for _, roll := range row.MagicItems {
+					if len(t.magicItems[roll.Table]) == 0 {
+						return fmt.Errorf("%s: unknown magic item table %s", name, roll.Table)
+					}
+				}
+			}
+			if err := checkD100(name, maxes); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func resolveMagicItemTable(name string, rows []rawMagicItemRow, items *ItemCatalog) ([]*magicItemRow, error) {
+	resolved := make([]*magicItemRow, 0, len(rows))
+	maxes := make([]int, 0, len(rows))
+	for _, row := range rows {
+		item := items.Find(row.Item)
+		if item == nil {
+			return nil, fmt.Errorf("magic item table %s: unknown item %q", name, row.Item)
+		}
+		resolved = append(resolved, &magicItemRow{max: row.Max, item: item})
+		maxes = append(maxes, row.Max)
+	}
+	if err := checkD100("magic item table "+name, maxes); err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// checkD100 checks a table's ranges climb without gaps and end on 100
+func checkD100(name string, maxes []int) error {
+	if len(maxes) == 0 {
+		return fmt.Errorf("%s has no rows", name)
+	}
+	previous := 0
+	for _, upTo := range maxes {
+		if upTo <= previous {
+			return fmt.Errorf("%s: row ending on %d overlaps the row before it", name, upTo)
+		}
+		previous = upTo
+	}
+	if previous != 100 {
+		return fmt.Errorf("%s: rows end on %d instead of 100", name, previous)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+	"github.com/ctclostio/DnD-Game/backend/internal/services"
+)
+
+func loadTreasureTables(t *testing.T) (*services.ItemCatalog, *services.TreasureTables) {
+	items, err := services.NewItemCatalog("../../../data")
+	require.NoError(t, err)
+	tables, err := services.NewTreasureTables("../../../data", items)
+	require.NoError(t, err)
+	return items, tables
+}
+
+func createTestTreasureService(tables *services.TreasureTables) *services.TreasureService {
+	return services.NewTreasureService(tables)
+}
+
+func treasureSeed(seed int64) *int64 {
+	return &seed
+}
+
+func TestTreasureService_Generate(t *testing.T) {
+	items, tables := loadTreasureTables(t)
+
+	tests := []struct {
+		name        string
+		request     models.TreasureRequest
+		seeds       int64
+		expectError string
+		validate    func(*testing.T, *models.TreasureHoard)
+	}{
+		{
+			name:    "Hoards hold their tier's coins and catalog items",
+			request: models.TreasureRequest{ChallengeRating: 2},
+			seeds:   200,
+			validate: func(t *testing.T, hoard *models.TreasureHoard) {
+				assert.GreaterOrEqual(t, hoard.Coins.Copper, 600, "6d6 x 100 cp")
+				assert.LessOrEqual(t, hoard.Coins.Copper, 3600)
+				assert.Zero(t, hoard.Coins.Platinum)
+				for _, gem := range hoard.Gems {
+					item := items.Get(gem.ItemID)
+					require.NotNil(t, item, gem.Name)
+					assert.True(t, item.HasTag("gemstone"))
+					assert.Equal(t, gem.Value*100, item.Value)
+				}
+				for _, art := range hoard.ArtObjects {
+					require.NotNil(t, items.Get(art.ItemID), art.Name)
+					assert.Equal(t, 25, art.Value)
+				}
+				for _, magic := range hoard.MagicItemDetails {
+					require.NotNil(t, items.Get(magic.ItemID), magic.Name)
+					assert.Contains(t, []string{"A", "B", "C", "F", "G"}, magic.Table)
+				}
+				assert.GreaterOrEqual(t, hoard.TotalValue, hoard.Coins.Coins().TotalInCopper()/100)
+			},
+		},
+		{
+			name:    "Individual treasure is only coins",
+			request: models.TreasureRequest{ChallengeRating: 18, TreasureType: models.TreasureTypeIndividual},
+			seeds:   50,
+			validate: func(t *testing.T, hoard *models.TreasureHoard) {
+				assert.False(t, hoard.Coins.Coins().IsZero())
+				assert.Zero(t, hoard.Coins.Copper)
+				assert.Empty(t, hoard.Gems)
+				assert.Empty(t, hoard.ArtObjects)
+				assert.Empty(t, hoard.MagicItemDetails)
+			},
+		},
+		{
+			name:        "Unknown treasure type",
+			request:     models.TreasureRequest{TreasureType: "dragon"},
+			seeds:       1,
+			expectError: `unknown treasure type "dragon"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := createTestTreasureService(tables)
+
+			for seed := int64(1); seed <= tt.seeds; seed++ {
+				req := tt.request
+				req.Seed = treasureSeed(seed)
+				hoard, err := service.Generate(context.Background(), &req)
+
+				if tt.expectError != "" {
+					assert.EqualError(t, err, tt.expectError)
+					continue
+				}
+				require.NoError(t, err)
+				tt.validate(t, hoard)
+			}
+		})
+	}
+}
+
+func TestTreasureService_Seed(t *testing.T) {
+	_, tables := loadTreasureTables(t)
+
+	tests := []struct {
+		name     string
+		request  models.TreasureRequest
+		validate func(*testing.T, *models.TreasureHoard)
+	}{
+		{
+			name:    "The same seed rolls the same hoard",
+			request: models.TreasureRequest{ChallengeRating: 12, TreasureType: models.TreasureTypeHoard, Seed: treasureSeed(42)},
+			validate: func(t *testing.T, hoard *models.TreasureHoard) {
+				assert.Equal(t, int64(42), hoard.Seed)
+			},
+		},
+		{
+			name:    "An unseeded roll returns the seed that repeats it",
+			request: models.TreasureRequest{ChallengeRating: 3},
+			validate: func(t *testing.T, hoard *models.TreasureHoard) {
+				assert.Equal(t, models.TreasureTypeHoard, hoard.TreasureType)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := createTestTreasureService(tables)
+			req := tt.request
+
+			first, err := service.Generate(context.Background(), &req)
+			require.NoError(t, err)
+			req.Seed = treasureSeed(first.Seed)
+			again, err := service.Generate(context.Background(), &req)
+			require.NoError(t, err)
+
+			assert.Equal(t, first, again)
+			tt.validate(t, first)
+		})
+	}
+}
+
+func TestTreasureService_Flavor(t *testing.T) {
+	_, tables := loadTreasureTables(t)
+	req := &models.TreasureRequest{ChallengeRating: 8, Seed: treasureSeed(7), WithFlavor: true}
+	plain, err := createTestTreasureService(tables).Generate(context.Background(), req)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		provider *services.MockLLMProvider
+		flavor   string
+	}{
+		{
+			name:     "Flavor text is added to the treasure",
+			provider: &services.MockLLMProvider{Response: "Coins spill from a rotted chest."},
+			flavor:   "Coins spill from a rotted chest.",
+		},
+		{
+			name:     "A failed description still returns the treasure",
+			provider: &services.MockLLMProvider{Error: errors.New("offline")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := createTestTreasureService(tables)
+			service.SetLLMProvider(tt.provider)
+
+			hoard, err := service.Generate(context.Background(), req)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.flavor, hoard.Flavor)
+			hoard.Flavor = ""
+			assert.Equal(t, plain, hoard, "flavor text never changes the treasure")
+		})
+	}
+}
+
+func TestTreasureHoard_LootPoolRequest(t *testing.T) {
+	_, tables := loadTreasureTables(t)
+	hoard, err := createTestTreasureService(tables).Generate(context.Background(), &models.TreasureRequest{ChallengeRating: 20, Seed: treasureSeed(3)})
+	require.NoError(t, err)
+
+	req := hoard.LootPoolRequest(models.LootModeNeedGreed)
+
+	require.NoError(t, req.Validate())
+	assert.Equal(t, models.LootModeNeedGreed, req.Mode)
+	assert.Equal(t, hoard.Coins.Coins(), req.Coins)
+	assert.Contains(t, req.Description, "seed 3")
+	quantity := 0
+	for _, item := range req.Items {
+		quantity += item.Quantity
+	}
+	expected := len(hoard.MagicItemDetails)
+	for _, gem := range hoard.Gems {
+		expected += gem.Quantity
+	}
+	for _, art := range hoard.ArtObjects {
+		expected += art.Quantity
+	}
+	assert.Equal(t, expected, quantity)
+}
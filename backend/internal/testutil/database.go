@@ -137,6 +137,8 @@ func SetupTestDB(t *testing.T) *sqlx.DB {
 		requires_attunement BOOLEAN DEFAULT FALSE,
 		attunement_requirements TEXT,
 		description TEXT,
+		source TEXT NOT NULL DEFAULT 'custom',
+		session_id TEXT,
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
@@ -150,10 +152,18 @@ func SetupTestDB(t *testing.T) *sqlx.DB {
 		attuned BOOLEAN DEFAULT FALSE,
 		custom_properties JSONB DEFAULT '{}',
 		notes TEXT,
+		container_id TEXT REFERENCES character_inventory(id) ON DELETE SET NULL,
+		location TEXT NOT NULL DEFAULT 'carried',
+		storage_note TEXT,
+		charges INTEGER,
+		curse_active BOOLEAN NOT NULL DEFAULT FALSE,
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		CONSTRAINT unique_character_item UNIQUE(character_id, item_id)
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_character_inventory_loose_stack
+		ON character_inventory(character_id, item_id) WHERE container_id IS NULL;
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_character_inventory_container_stack
+		ON character_inventory(character_id, item_id, container_id) WHERE container_id IS NOT NULL;
 
 	CREATE TABLE IF NOT EXISTS character_currency (
 		character_id TEXT PRIMARY KEY REFERENCES characters(id) ON DELETE CASCADE,
@@ -192,6 +202,7 @@ func SetupTestDB(t *testing.T) *sqlx.DB {
 		character_id TEXT REFERENCES characters(id),
 		joined_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 		is_online BOOLEAN DEFAULT FALSE,
+		role TEXT NOT NULL DEFAULT 'player',
 		CONSTRAINT unique_session_user UNIQUE(session_id, user_id)
 	);
 
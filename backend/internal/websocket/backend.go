@@ -0,0 +1,405 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+// Backend carries room traffic and presence between the server nodes running a hub, so
+// players connected to different replicas still share a room
+type Backend interface {
+	// NodeID identifies this server node
+	NodeID() string
+	// Publish numbers a message with the room's next sequence number, keeps it in the
+	// room's replay buffer and sends it to every node subscribed to the room, this one
+	// included. Every node sees the messages of one room in sequence order.
+	Publish(ctx context.Context, roomID string, message []byte) (int64, error)
+	// Replay returns the buffered messages of a room after a sequence number
+	Replay(ctx context.Context, roomID string, afterSeq int64) (*ReplayResult, error)
+	// Subscribe starts delivering a room's messages to this node; Unsubscribe stops it
+	Subscribe(ctx context.Context, roomID string) error
+	Unsubscribe(ctx context.Context, roomID string) error
+	// Messages delivers the messages of subscribed rooms
+	Messages() <-chan BackendMessage
+	// SetPresence and RemovePresence record who is connected to a room on this node;
+	// Presence lists everyone connected to it on any live node
+	SetPresence(ctx context.Context, roomID string, member Presence) error
+	RemovePresence(ctx context.Context, roomID, connectionID string) error
+	Presence(ctx context.Context, roomID string) ([]Presence, error)
+	// Close stops delivery and forgets this node's presence
+	Close() error
+}
+
+// BackendMessage is a message published to a room
+type BackendMessage struct {
+	RoomID string
+	Seq    int64
+	Data   []byte
+}
+
+// ReplayResult is what a reconnecting client missed. Complete is false when the replay
+// buffer no longer reaches back far enough, and the client needs a full snapshot instead.
+type ReplayResult struct {
+	Messages  []BackendMessage
+	LatestSeq int64
+	Complete  bool
+}
+
+const (
+	// replayBufferSize is how many messages each room keeps for reconnecting clients
+	replayBufferSize = 500
+	// replayTTL is how long a room's sequence and replay buffer outlive its last message
+	replayTTL = 24 * time.Hour
+)
+
+// replayAfter picks the buffered messages after afterSeq. A client ahead of the room has
+// seen a history that has since expired, so it needs a snapshot too.
+func replayAfter(buffer []BackendMessage, latestSeq, afterSeq int64) *ReplayResult {
+	result := &ReplayResult{LatestSeq: latestSeq}
+	if afterSeq > latestSeq {
+		return result
+	}
+	for _, message := range buffer {
+		if message.Seq > afterSeq {
+			result.Messages = append(result.Messages, message)
+		}
+	}
+	result.Complete = afterSeq == latestSeq ||
+		(len(result.Messages) > 0 && result.Messages[0].Seq == afterSeq+1)
+	if !result.Complete {
+		result.Messages = nil
+	}
+	return result
+}
+
+// Presence is one connection to a room
+type Presence struct {
+	ConnectionID string    `json:"connectionId"`
+	PlayerID     string    `json:"playerId"`
+	Username     string    `json:"username"`
+	Role         string    `json:"role"`
+	NodeID       string    `json:"nodeId"`
+	JoinedAt     time.Time `json:"joinedAt"`
+}
+
+// ReplayStore keeps rooms' sequence numbers and replay buffers outside process memory, so a
+// single server picks its rooms up where they were after a restart
+type ReplayStore interface {
+	AppendRoomMessage(ctx context.Context, message *models.RoomMessage, keep int) error
+	ListRoomMessages(ctx context.Context, roomID string) ([]*models.RoomMessage, error)
+	DeleteQuietRooms(ctx context.Context, before time.Time) error
+}
+
+// memoryBus is the shared state of in-process backends
+type memoryBus struct {
+	mu          sync.Mutex
+	nodes       map[*MemoryBackend]bool
+	presence    map[string]map[string]Presence
+	history     map[string]*memoryHistory
+	replayLimit int
+	lastPrune   time.Time
+	store       ReplayStore
+}
+
+// memoryHistory is a room's sequence and replay buffer
+type memoryHistory struct {
+	seq        int64
+	buffer     []BackendMessage
+	lastActive time.Time
+}
+
+// MemoryBackend keeps rooms in process memory. It is the default for a single server, which
+// gives it a replay store so room sequences survive a restart; Peer adds more nodes on the
+// same bus, which stands in for a cluster in tests.
+type MemoryBackend struct {
+	bus      *memoryBus
+	nodeID   string
+	rooms    map[string]bool // guarded by bus.mu
+	messages chan BackendMessage
+
+	mu      sync.Mutex
+	pending []BackendMessage
+	ready   chan struct{}
+	done    chan struct{}
+	closing sync.Once
+}
+
+// NewMemoryBackend creates an in-process backend
+func NewMemoryBackend() *MemoryBackend {
+	bus := &memoryBus{
+		nodes:       make(map[*MemoryBackend]bool),
+		presence:    make(map[string]map[string]Presence),
+		history:     make(map[string]*memoryHistory),
+		replayLimit: replayBufferSize,
+	}
+	return bus.connect()
+}
+
+// Peer creates another node sharing this backend's rooms and presence
+func (m *MemoryBackend) Peer() *MemoryBackend {
+	return m.bus.connect()
+}
+
+func (b *memoryBus) connect() *MemoryBackend {
+	node := &MemoryBackend{
+		bus:      b,
+		nodeID:   uuid.New().String(),
+		rooms:    make(map[string]bool),
+		messages: make(chan BackendMessage),
+		ready:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	b.mu.Lock()
+	b.nodes[node] = true
+	b.mu.Unlock()
+	go node.deliver()
+	return node
+}
+
+// NodeID identifies this node
+func (m *MemoryBackend) NodeID() string {
+	return m.nodeID
+}
+
+// SetReplayLimit changes how many messages each room keeps for reconnecting clients
+func (m *MemoryBackend) SetReplayLimit(limit int) {
+	m.bus.mu.Lock()
+	defer m.bus.mu.Unlock()
+	m.bus.replayLimit = limit
+}
+
+// SetReplayStore keeps every room's sequence and replay buffer in store as well, loading a
+// room from it the first time the room is used
+func (m *MemoryBackend) SetReplayStore(store ReplayStore) {
+	m.bus.mu.Lock()
+	defer m.bus.mu.Unlock()
+	m.bus.store = store
+}
+
+// Publish numbers the message and queues it for every node subscribed to the room.
+// Publishing holds the bus lock, so every node queues a room's messages in sequence order.
+// With a replay store, a message the store cannot keep is not published.
+func (m *MemoryBackend) Publish(ctx context.Context, roomID string, message []byte) (int64, error) {
+	m.bus.mu.Lock()
+	defer m.bus.mu.Unlock()
+	if !m.bus.nodes[m] {
+		return 0, fmt.Errorf("websocket backend is closed")
+	}
+
+	now := time.Now()
+	m.bus.pruneHistory(ctx, now)
+	history, err := m.bus.loadHistory(ctx, roomID)
+	if err != nil {
+		return 0, err
+	}
+	published := BackendMessage{RoomID: roomID, Seq: history.seq + 1, Data: message}
+	if m.bus.store != nil {
+		stored := &models.RoomMessage{RoomID: roomID, Seq: published.Seq, Data: message, CreatedAt: now}
+		if err := m.bus.store.AppendRoomMessage(ctx, stored, m.bus.replayLimit); err != nil {
+			return 0, fmt.Errorf("failed to store room message: %w", err)
+		}
+	}
+	history.seq = published.Seq
+	history.lastActive = now
+	history.buffer = append(history.buffer, published)
+	if over := len(history.buffer) - m.bus.replayLimit; over > 0 {
+		history.buffer = append([]BackendMessage(nil), history.buffer[over:]...)
+	}
+
+	for node := range m.bus.nodes {
+		if node.rooms[roomID] {
+			node.enqueue(published)
+		}
+	}
+	return published.Seq, nil
+}
+
+// Replay returns the buffered messages of a room after a sequence number
+func (m *MemoryBackend) Replay(ctx context.Context, roomID string, afterSeq int64) (*ReplayResult, error) {
+	m.bus.mu.Lock()
+	defer m.bus.mu.Unlock()
+	history, err := m.bus.loadHistory(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	return replayAfter(history.buffer, history.seq, afterSeq), nil
+}
+
+// loadHistory returns a room's sequence and replay buffer, reading them from the replay
+// store the first time the room is used. Callers hold the bus lock.
+func (b *memoryBus) loadHistory(ctx context.Context, roomID string) (*memoryHistory, error) {
+	if history := b.history[roomID]; history != nil {
+		return history, nil
+	}
+	history := &memoryHistory{}
+	if b.store != nil {
+		stored, err := b.store.ListRoomMessages(ctx, roomID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load room messages: %w", err)
+		}
+		for _, message := range stored {
+			history.buffer = append(history.buffer, BackendMessage{RoomID: roomID, Seq: message.Seq, Data: message.Data})
+			history.seq = message.Seq
+			history.lastActive = message.CreatedAt
+		}
+		if over := len(history.buffer) - b.replayLimit; over > 0 {
+			history.buffer = history.buffer[over:]
+		}
+	}
+	b.history[roomID] = history
+	return history, nil
+}
+
+// pruneHistory forgets rooms that have been quiet for replayTTL, checking at most once a
+// minute. Callers hold the bus lock. A store that fails to prune is tried again a minute
+// later; until then its quiet rooms simply keep their messages.
+func (b *memoryBus) pruneHistory(ctx context.Context, now time.Time) {
+	if now.Sub(b.lastPrune) < time.Minute {
+		return
+	}
+	b.lastPrune = now
+	for roomID, history := range b.history {
+		if now.Sub(history.lastActive) > replayTTL {
+			delete(b.history, roomID)
+		}
+	}
+	if b.store != nil {
+		_ = b.store.DeleteQuietRooms(ctx, now.Add(-replayTTL))
+	}
+}
+
+// Subscribe starts delivering the room's messages to this node
+func (m *MemoryBackend) Subscribe(_ context.Context, roomID string) error {
+	m.bus.mu.Lock()
+	defer m.bus.mu.Unlock()
+	m.rooms[roomID] = true
+	return nil
+}
+
+// Unsubscribe stops delivering the room's messages to this node
+func (m *MemoryBackend) Unsubscribe(_ context.Context, roomID string) error {
+	m.bus.mu.Lock()
+	defer m.bus.mu.Unlock()
+	delete(m.rooms, roomID)
+	return nil
+}
+
+// Messages delivers the messages of subscribed rooms
+func (m *MemoryBackend) Messages() <-chan BackendMessage {
+	return m.messages
+}
+
+// SetPresence records a connection to a room
+func (m *MemoryBackend) SetPresence(_ context.Context, roomID string, member Presence) error {
+	m.bus.mu.Lock()
+	defer m.bus.mu.Unlock()
+	if m.bus.presence[roomID] == nil {
+		m.bus.presence[roomID] = make(map[string]Presence)
+	}
+	member.NodeID = m.nodeID
+	m.bus.presence[roomID][member.ConnectionID] = member
+	return nil
+}
+
+// RemovePresence forgets a connection to a room
+func (m *MemoryBackend) RemovePresence(_ context.Context, roomID, connectionID string) error {
+	m.bus.mu.Lock()
+	defer m.bus.mu.Unlock()
+	delete(m.bus.presence[roomID], connectionID)
+	if len(m.bus.presence[roomID]) == 0 {
+		delete(m.bus.presence, roomID)
+	}
+	return nil
+}
+
+// Presence lists every connection to a room on any node
+func (m *MemoryBackend) Presence(_ context.Context, roomID string) ([]Presence, error) {
+	m.bus.mu.Lock()
+	defer m.bus.mu.Unlock()
+	members := make([]Presence, 0, len(m.bus.presence[roomID]))
+	for _, member := range m.bus.presence[roomID] {
+		members = append(members, member)
+	}
+	sortPresence(members)
+	return members, nil
+}
+
+// Close stops delivery and forgets this node's presence
+func (m *MemoryBackend) Close() error {
+	m.bus.mu.Lock()
+	delete(m.bus.nodes, m)
+	for roomID, members := range m.bus.presence {
+		for id, member := range members {
+			if member.NodeID == m.nodeID {
+				delete(members, id)
+			}
+		}
+		if len(members) == 0 {
+			delete(m.bus.presence, roomID)
+		}
+	}
+	m.bus.mu.Unlock()
+
+	m.closing.Do(func() { close(m.done) })
+	return nil
+}
+
+// enqueue never blocks, so a hub may publish from its own run loop
+func (m *MemoryBackend) enqueue(message BackendMessage) {
+	m.mu.Lock()
+	m.pending = append(m.pending, message)
+	m.mu.Unlock()
+	m.signal()
+}
+
+func (m *MemoryBackend) signal() {
+	select {
+	case m.ready <- struct{}{}:
+	default:
+	}
+}
+
+// deliver hands queued messages to Messages in order until the node closes
+func (m *MemoryBackend) deliver() {
+	defer close(m.messages)
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-m.ready:
+		}
+		for {
+			m.mu.Lock()
+			if len(m.pending) == 0 {
+				m.mu.Unlock()
+				break
+			}
+			message := m.pending[0]
+			m.pending = m.pending[1:]
+			m.mu.Unlock()
+
+			select {
+			case m.messages <- message:
+			case <-m.done:
+				return
+			}
+		}
+	}
+}
+
+// sortPresence lists connections in the order they joined
+func sortPresence(members []Presence) {
+	sort.Slice(members, func(i, j int) bool {
+		if !members[i].JoinedAt.Equal(members[j].JoinedAt) {
+			return members[i].JoinedAt.Before(members[j].JoinedAt)
+		}
+		return members[i].ConnectionID < members[j].ConnectionID
+	})
+}
@@ -0,0 +1,68 @@
+package websocket
+
+import "sync"
+
+// backendQueue runs the hub's room subscriptions, presence updates and replays against its
+// backend one after another on a goroutine of their own, so a slow backend never holds up
+// the hub's run loop. Calls run in the order they were queued: a room is subscribed to
+// before a client's presence is recorded or its missed messages replayed.
+type backendQueue struct {
+	mu      sync.Mutex
+	calls   []func()
+	ready   chan struct{}
+	done    chan struct{}
+	closing sync.Once
+}
+
+func newBackendQueue() *backendQueue {
+	return &backendQueue{
+		ready: make(chan struct{}, 1),
+		done:  make(chan struct{}),
+	}
+}
+
+// push queues a call without blocking
+func (q *backendQueue) push(call func()) {
+	q.mu.Lock()
+	q.calls = append(q.calls, call)
+	q.mu.Unlock()
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (q *backendQueue) pop() (func(), bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.calls) == 0 {
+		return nil, false
+	}
+	call := q.calls[0]
+	q.calls = q.calls[1:]
+	return call, true
+}
+
+// close stops the queue once the calls already queued have run
+func (q *backendQueue) close() {
+	q.closing.Do(func() { close(q.done) })
+}
+
+// run makes the queued calls until the queue closes
+func (q *backendQueue) run() {
+	for {
+		if call, ok := q.pop(); ok {
+			call()
+			continue
+		}
+		select {
+		case <-q.ready:
+		case <-q.done:
+			for call, ok := q.pop(); ok; call, ok = q.pop() {
+				call()
+			}
+			return
+		}
+	}
+}
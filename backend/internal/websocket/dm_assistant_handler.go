@@ -38,7 +38,7 @@ func (c *Client) HandleDMAssistantMessage(message []byte, dmAssistant *services.
 	}
 
 	// Ensure user is DM
-	if c.role != "dm" {
+	if c.role != RoleDM {
 		c.sendError(msg.RequestID, "DM privileges required")
 		return
 	}
@@ -308,7 +308,7 @@ func (c *Client) handleEnvironmentalHazard(msg DMAssistantMessage, _ *services.D
 
 func (c *Client) sendDMAssistantResponse(response DMAssistantResponse) {
 	data, _ := json.Marshal(response)
-	c.send <- data
+	c.outbox().push(data)
 }
 
 func (c *Client) sendError(requestID, errorMsg string) {
@@ -328,5 +328,5 @@ func (c *Client) broadcastToSession(sessionID string, message *Message) {
 	}
 	message.RoomID = sessionID
 	data, _ := json.Marshal(message)
-	c.hub.broadcast <- data
+	c.hub.Broadcast(data)
 }
@@ -3,6 +3,7 @@ package websocket
 import (
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -51,8 +52,17 @@ var (
 
 // InitHub initializes and starts the websocket hub
 func InitHub() *Hub {
+	return InitHubWithBackend(nil)
+}
+
+// InitHubWithBackend initializes and starts the websocket hub, sharing its rooms with
+// other server nodes through backend. A nil backend keeps rooms in process memory.
+func InitHubWithBackend(backend Backend) *Hub {
 	if hub == nil {
 		hub = NewHub()
+		if backend != nil {
+			hub.SetBackend(backend)
+		}
 		go hub.Run()
 	}
 	return hub
@@ -71,11 +81,26 @@ func SetJWTManager(manager *auth.JWTManager) {
 	jwtManager = manager
 }
 
-// AuthMessage represents the authentication message
+// AuthMessage represents the authentication message. A reconnecting client sends the
+// last sequence number it saw to be sent what it missed.
 type AuthMessage struct {
-	Type  string `json:"type"`
-	Token string `json:"token"`
-	Room  string `json:"room"`
+	Type    string `json:"type"`
+	Token   string `json:"token"`
+	Room    string `json:"room"`
+	LastSeq *int64 `json:"last_seq,omitempty"`
+}
+
+// resumePoint returns the sequence number a client is resuming from, taken from its auth
+// message or, failing that, the last_seq query parameter
+func resumePoint(lastSeq *int64, r *http.Request) *int64 {
+	if lastSeq != nil {
+		return lastSeq
+	}
+	seq, err := strconv.ParseInt(r.URL.Query().Get("last_seq"), 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &seq
 }
 
 func HandleWebSocket(w http.ResponseWriter, r *http.Request) {
@@ -198,6 +223,8 @@ func HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 		username: claims.Username,
 		roomID:   roomID,
 		role:     claims.Role,
+
+		resumeFrom: resumePoint(authMsg.LastSeq, r),
 	}
 
 	// Send authentication success
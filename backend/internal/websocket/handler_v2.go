@@ -1,6 +1,7 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"os"
@@ -38,11 +39,14 @@ var (
 	space = []byte{' '}
 )
 
-// AuthMessageV2 represents the authentication message
+// AuthMessageV2 represents the authentication message. Protocol picks one of the
+// versions offered in auth_required and defaults to protocol v1.
 type AuthMessageV2 struct {
-	Type  string `json:"type"`
-	Token string `json:"token"`
-	Room  string `json:"room"`
+	Type     string `json:"type"`
+	Token    string `json:"token"`
+	Room     string `json:"room"`
+	LastSeq  *int64 `json:"last_seq,omitempty"`
+	Protocol int    `json:"protocol,omitempty"`
 }
 
 // MessageV2 represents a WebSocket message
@@ -58,6 +62,10 @@ type BroadcastMessage struct {
 	Sender  string
 }
 
+// RoleFunc looks up the role a user has in a room: RoleDM, RolePlayer or RoleSpectator.
+// An error keeps the user out of the room.
+type RoleFunc func(ctx context.Context, roomID, userID string) (string, error)
+
 // HandlerV2 is the enhanced WebSocket handler with structured logging
 type HandlerV2 struct {
 	hub            *Hub
@@ -65,6 +73,8 @@ type HandlerV2 struct {
 	log            *logger.LoggerV2
 	upgrader       websocket.Upgrader
 	allowedOrigins []string
+	protocol       *Protocol
+	roomRoles      RoleFunc
 }
 
 // NewHandlerV2 creates a new WebSocket handler with logging
@@ -99,6 +109,38 @@ func NewHandlerV2(hub *Hub, jwtManager *auth.JWTManager, log *logger.LoggerV2) *
 	return h
 }
 
+// SetProtocol offers protocol v2, running the registry's commands for clients that ask for it
+func (h *HandlerV2) SetProtocol(protocol *Protocol) {
+	h.protocol = protocol
+}
+
+// SetRoomRoles has clients join their room with the role they have in it, instead of the
+// role on their account, and turns away users who are not members of the room
+func (h *HandlerV2) SetRoomRoles(roles RoleFunc) {
+	h.roomRoles = roles
+}
+
+// protocolVersions lists the protocol versions clients may ask for
+func (h *HandlerV2) protocolVersions() []int {
+	if h.protocol == nil {
+		return []int{ProtocolV1}
+	}
+	return []int{ProtocolV1, ProtocolV2}
+}
+
+// negotiateProtocol picks the version a client asked for, if this handler speaks it
+func (h *HandlerV2) negotiateProtocol(requested int) (int, bool) {
+	if requested == 0 {
+		requested = ProtocolV1
+	}
+	for _, version := range h.protocolVersions() {
+		if version == requested {
+			return version, true
+		}
+	}
+	return 0, false
+}
+
 // checkOrigin validates the origin of WebSocket connections
 func (h *HandlerV2) checkOrigin(r *http.Request) bool {
 	origin := r.Header.Get("Origin")
@@ -178,9 +220,10 @@ func (h *HandlerV2) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	tempConn := conn
 
 	// Send authentication request
-	authRequest := map[string]string{
-		"type":    "auth_required",
-		"message": "Please authenticate",
+	authRequest := map[string]interface{}{
+		"type":      "auth_required",
+		"message":   "Please authenticate",
+		"protocols": h.protocolVersions(),
 	}
 
 	authData, _ := json.Marshal(authRequest)
@@ -238,19 +281,55 @@ func (h *HandlerV2) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 	userID := claims.UserID
 
+	version, ok := h.negotiateProtocol(authMsg.Protocol)
+	if !ok {
+		log.Warn().
+			Str("client_id", clientID).
+			Int("protocol", authMsg.Protocol).
+			Msg("Unsupported protocol version")
+		errorData, _ := json.Marshal(ErrorFrame{
+			Type:  FrameTypeError,
+			Code:  ErrCodeUnsupportedProtocol,
+			Error: "Unsupported protocol version",
+		})
+		_ = tempConn.WriteMessage(websocket.TextMessage, errorData)
+		_ = conn.Close()
+		return
+	}
+
 	// Create authenticated client
 	client := &Client{
-		id:       clientID,
+		id:       userID,
 		username: claims.Username,
 		conn:     conn,
 		send:     make(chan []byte, 256),
 		hub:      h.hub,
 		role:     claims.Role,
 	}
+	if version == ProtocolV2 {
+		client.commands = h.protocol
+	}
 
 	// Join room if specified
 	if authMsg.Room != "" {
+		if h.roomRoles != nil {
+			role, err := h.roomRoles(ctx, authMsg.Room, userID)
+			if err != nil {
+				log.Warn().
+					Err(err).
+					Str("client_id", clientID).
+					Str("room", authMsg.Room).
+					Msg("Client is not a member of the room")
+				errorMsg := map[string]string{"type": "error", "message": "Not a member of this room"}
+				errorData, _ := json.Marshal(errorMsg)
+				_ = tempConn.WriteMessage(websocket.TextMessage, errorData)
+				_ = conn.Close()
+				return
+			}
+			client.role = role
+		}
 		client.roomID = authMsg.Room
+		client.resumeFrom = resumePoint(authMsg.LastSeq, r)
 		// Logger already has room context from creation
 
 		log.Info().
@@ -268,6 +347,7 @@ func (h *HandlerV2) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 		"message":   "Authentication successful",
 		"user_id":   userID,
 		"client_id": clientID,
+		"protocol":  version,
 	}
 
 	successData, _ := json.Marshal(successMsg)
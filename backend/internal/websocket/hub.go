@@ -3,52 +3,133 @@ package websocket
 import (
 	"context"
 	"encoding/json"
+	"sync"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 
 	"github.com/ctclostio/DnD-Game/backend/pkg/logger"
 )
 
+// Hub tracks the clients connected to this server node and fans room messages out to
+// them. Rooms span nodes: every message goes through the backend, which numbers it and
+// delivers it to each node with clients in the room, so all nodes see a room's messages
+// in one order. A client reconnecting with the last sequence number it saw is sent what
+// it missed. The run loop never waits on the backend: subscriptions, presence and replays
+// go through a queue of their own.
 type Hub struct {
 	clients    map[*Client]bool
-	broadcast  chan []byte
 	register   chan *Client
 	unregister chan *Client
 	rooms      map[string]map[*Client]bool
 	shutdown   chan struct{}
+	backend    Backend
+	calls      *backendQueue
+	replays    chan replayResult
 }
 
+// replayResult is what a reconnecting client missed, handed back to the run loop
+type replayResult struct {
+	client   *Client
+	afterSeq int64
+	replay   *ReplayResult
+	err      error
+}
+
+// Roles a client has in its room. A spectator's room is read-only: it is sent the room's
+// messages but cannot send any of its own.
+const (
+	RoleDM        = "dm"
+	RolePlayer    = "player"
+	RoleSpectator = "spectator"
+)
+
 type Client struct {
-	hub      *Hub
-	conn     *websocket.Conn
-	send     chan []byte
-	id       string
-	username string
-	roomID   string
-	role     string // "player" or "dm"
+	hub          *Hub
+	conn         *websocket.Conn
+	send         chan []byte
+	id           string
+	connectionID string
+	username     string
+	roomID       string
+	role         string // RoleDM, RolePlayer or RoleSpectator
+
+	// commands is set for protocol v2 clients, whose messages are command requests
+	commands *Protocol
+
+	out     *outbox
+	outOnce sync.Once
+	// resumeFrom is the last sequence number a reconnecting client saw
+	resumeFrom *int64
+	// lastSeq is the last room message queued for the client; only the hub's run loop
+	// touches it
+	lastSeq int64
+	// resuming holds back room messages until the client's replay is in, so they follow
+	// what it missed; only the hub's run loop touches it or held
+	resuming bool
+	held     []BackendMessage
 }
 
+// Message is a room message. To limits it to those users; without it everyone in the room
+// gets it.
 type Message struct {
 	Type     string          `json:"type"`
 	RoomID   string          `json:"roomId"`
 	PlayerID string          `json:"playerId"`
 	Username string          `json:"username"`
 	Role     string          `json:"role"`
+	Seq      int64           `json:"seq,omitempty"`
+	To       []string        `json:"to,omitempty"`
 	Data     json.RawMessage `json:"data"`
 }
 
+// Messages the hub sends a client about its place in the room's sequence
+const (
+	MessageTypeResumed          = "resumed"
+	MessageTypeSnapshotRequired = "snapshot_required"
+)
+
+// Why a client has to fetch a snapshot instead of carrying on from the messages it has
+const (
+	SnapshotReasonGap         = "gap"
+	SnapshotReasonOverflow    = "overflow"
+	SnapshotReasonUnavailable = "unavailable"
+)
+
+// ResumeStatus is the data of resumed and snapshot_required messages. LastSeq is the
+// room message the client is caught up to, or that its snapshot should cover.
+type ResumeStatus struct {
+	LastSeq  int64  `json:"lastSeq"`
+	Replayed int    `json:"replayed,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// backendTimeout bounds each call the hub makes to its backend
+const backendTimeout = 5 * time.Second
+
+// NewHub creates a hub that keeps its rooms in process memory
 func NewHub() *Hub {
 	return &Hub{
-		broadcast:  make(chan []byte),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		clients:    make(map[*Client]bool),
 		rooms:      make(map[string]map[*Client]bool),
 		shutdown:   make(chan struct{}),
+		backend:    NewMemoryBackend(),
+		calls:      newBackendQueue(),
+		replays:    make(chan replayResult),
 	}
 }
 
+// SetBackend shares the hub's rooms with other server nodes. Call it before Run.
+func (h *Hub) SetBackend(backend Backend) {
+	h.backend = backend
+}
+
 func (h *Hub) Run() {
+	go h.calls.run()
+	messages := h.backend.Messages()
 	for {
 		select {
 		case <-h.shutdown:
@@ -58,18 +139,30 @@ func (h *Hub) Run() {
 			h.handleRegister(client)
 		case client := <-h.unregister:
 			h.handleUnregister(client)
-		case message := <-h.broadcast:
-			h.handleBroadcast(message)
+		case message, ok := <-messages:
+			if !ok {
+				messages = nil
+				continue
+			}
+			h.broadcastToRoom(message)
+		case result := <-h.replays:
+			h.finishResume(result)
 		}
 	}
 }
 
-// handleShutdown closes all client connections
+// handleShutdown closes all client connections and takes this node out of its rooms,
+// closing the backend once the queued calls have run
 func (h *Hub) handleShutdown() {
 	for client := range h.clients {
-		close(client.send)
-		_ = client.conn.Close()
+		h.leaveRoom(client)
+		client.outbox().close()
+		if client.conn != nil {
+			_ = client.conn.Close()
+		}
 	}
+	h.calls.push(func() { _ = h.backend.Close() })
+	h.calls.close()
 }
 
 // handleRegister adds a new client to the hub
@@ -77,16 +170,49 @@ func (h *Hub) handleRegister(client *Client) {
 	h.clients[client] = true
 	if client.roomID != "" {
 		h.addClientToRoom(client)
+		if client.resumeFrom != nil {
+			h.resume(client, *client.resumeFrom)
+		}
 	}
 	h.logClientConnection(client, "Client connected to room")
 }
 
-// addClientToRoom adds a client to a specific room
+// addClientToRoom adds a client to a specific room, subscribing this node to the room
+// when it is the first local client, and records the client's presence
 func (h *Hub) addClientToRoom(client *Client) {
-	if h.rooms[client.roomID] == nil {
-		h.rooms[client.roomID] = make(map[*Client]bool)
+	roomID := client.roomID
+	if h.rooms[roomID] == nil {
+		h.rooms[roomID] = make(map[*Client]bool)
+		h.queueBackendCall(roomID, "Failed to subscribe to room", func(ctx context.Context) error {
+			return h.backend.Subscribe(ctx, roomID)
+		})
+	}
+	h.rooms[roomID][client] = true
+
+	if client.connectionID == "" {
+		client.connectionID = uuid.New().String()
+	}
+	presence := Presence{
+		ConnectionID: client.connectionID,
+		PlayerID:     client.id,
+		Username:     client.username,
+		Role:         client.role,
+		JoinedAt:     time.Now(),
 	}
-	h.rooms[client.roomID][client] = true
+	h.queueBackendCall(roomID, "Failed to record presence", func(ctx context.Context) error {
+		return h.backend.SetPresence(ctx, roomID, presence)
+	})
+}
+
+// queueBackendCall runs a backend call on the hub's backend queue, logging its failure
+func (h *Hub) queueBackendCall(roomID, failure string, call func(ctx context.Context) error) {
+	h.calls.push(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), backendTimeout)
+		defer cancel()
+		if err := call(ctx); err != nil {
+			h.logBackendError(err, roomID, failure)
+		}
+	})
 }
 
 // handleUnregister removes a client from the hub
@@ -94,25 +220,31 @@ func (h *Hub) handleUnregister(client *Client) {
 	if _, ok := h.clients[client]; !ok {
 		return
 	}
-	
+
 	delete(h.clients, client)
-	if client.roomID != "" && h.rooms[client.roomID] != nil {
-		delete(h.rooms[client.roomID], client)
-	}
-	close(client.send)
+	h.leaveRoom(client)
+	client.outbox().close()
 	h.logClientConnection(client, "Client disconnected from room")
 }
 
-// handleBroadcast sends a message to all clients in a room
-func (h *Hub) handleBroadcast(message []byte) {
-	msg, err := h.parseMessage(message)
-	if err != nil {
+// leaveRoom takes a client out of its room, unsubscribing this node once no local client
+// is left in it
+func (h *Hub) leaveRoom(client *Client) {
+	roomID, connectionID := client.roomID, client.connectionID
+	if roomID == "" || h.rooms[roomID] == nil {
 		return
 	}
 
-	if msg.RoomID != "" && h.rooms[msg.RoomID] != nil {
-		h.broadcastToRoom(msg.RoomID, message)
+	delete(h.rooms[roomID], client)
+	if len(h.rooms[roomID]) == 0 {
+		delete(h.rooms, roomID)
+		h.queueBackendCall(roomID, "Failed to unsubscribe from room", func(ctx context.Context) error {
+			return h.backend.Unsubscribe(ctx, roomID)
+		})
 	}
+	h.queueBackendCall(roomID, "Failed to clear presence", func(ctx context.Context) error {
+		return h.backend.RemovePresence(ctx, roomID, connectionID)
+	})
 }
 
 // parseMessage unmarshals a message
@@ -127,22 +259,168 @@ func (h *Hub) parseMessage(message []byte) (*Message, error) {
 	return &msg, nil
 }
 
-// broadcastToRoom sends a message to all clients in a specific room
-func (h *Hub) broadcastToRoom(roomID string, message []byte) {
-	for client := range h.rooms[roomID] {
+// broadcastToRoom queues a room message for all of this node's clients in the room
+func (h *Hub) broadcastToRoom(message BackendMessage) {
+	data := withSeq(message.Data, message.Seq)
+	to := recipients(message.Data)
+	for client := range h.rooms[message.RoomID] {
+		h.deliver(client, message.Seq, data, to)
+	}
+}
+
+// deliver queues a room message for a client, skipping messages it already has and
+// messages for other users. A client too far behind loses its backlog and is told to
+// fetch a snapshot, rather than being disconnected.
+func (h *Hub) deliver(client *Client, seq int64, message []byte, to map[string]bool) {
+	if client.resuming {
+		client.held = append(client.held, BackendMessage{RoomID: client.roomID, Seq: seq, Data: message})
+		return
+	}
+	if seq <= client.lastSeq {
+		return
+	}
+	client.lastSeq = seq
+	if to != nil && !to[client.id] {
+		return
+	}
+	if client.outbox().push(message) {
+		return
+	}
+
+	logger.Warn().
+		Str("client_id", client.id).
+		Str("room_id", client.roomID).
+		Int64("seq", seq).
+		Msg("Client fell too far behind, requesting a snapshot")
+	h.sendStatus(client, MessageTypeSnapshotRequired, ResumeStatus{LastSeq: seq, Reason: SnapshotReasonOverflow})
+}
+
+// resume fetches what a reconnecting client missed since afterSeq on the backend queue,
+// after the room's subscription, holding back the room's new messages until it is in
+func (h *Hub) resume(client *Client, afterSeq int64) {
+	client.resuming = true
+	roomID := client.roomID
+	h.calls.push(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), backendTimeout)
+		defer cancel()
+
+		result := replayResult{client: client, afterSeq: afterSeq}
+		result.replay, result.err = h.backend.Replay(ctx, roomID, afterSeq)
 		select {
-		case client.send <- message:
-		default:
-			h.removeUnresponsiveClient(client, roomID)
+		case h.replays <- result:
+		case <-h.shutdown:
 		}
+	})
+}
+
+// finishResume replays what a reconnecting client missed, or tells it to fetch a snapshot
+// when the replay buffer no longer reaches back that far, then sends the messages held back
+func (h *Hub) finishResume(result replayResult) {
+	client := result.client
+	if !h.clients[client] {
+		return
+	}
+	held := client.held
+	client.resuming = false
+	client.held = nil
+
+	switch {
+	case result.err != nil:
+		h.logBackendError(result.err, client.roomID, "Failed to replay room messages")
+		h.sendStatus(client, MessageTypeSnapshotRequired, ResumeStatus{Reason: SnapshotReasonUnavailable})
+	case !result.replay.Complete:
+		client.lastSeq = result.replay.LatestSeq
+		h.sendStatus(client, MessageTypeSnapshotRequired, ResumeStatus{LastSeq: result.replay.LatestSeq, Reason: SnapshotReasonGap})
+	default:
+		client.lastSeq = result.afterSeq
+		for _, message := range result.replay.Messages {
+			h.deliver(client, message.Seq, withSeq(message.Data, message.Seq), recipients(message.Data))
+		}
+		h.sendStatus(client, MessageTypeResumed, ResumeStatus{LastSeq: client.lastSeq, Replayed: len(result.replay.Messages)})
+	}
+
+	for _, message := range held {
+		h.deliver(client, message.Seq, message.Data, recipients(message.Data))
 	}
 }
 
-// removeUnresponsiveClient removes a client that can't receive messages
-func (h *Hub) removeUnresponsiveClient(client *Client, roomID string) {
-	close(client.send)
-	delete(h.clients, client)
-	delete(h.rooms[roomID], client)
+// sendStatus queues a resumed or snapshot_required message for a client. If its outbox
+// is full the backlog is gone, so the client is asked for a snapshot instead.
+func (h *Hub) sendStatus(client *Client, messageType string, status ResumeStatus) {
+	message, err := statusMessage(client.roomID, messageType, status)
+	if err != nil {
+		return
+	}
+	if !client.outbox().push(message) {
+		client.requestSnapshot()
+	}
+}
+
+func statusMessage(roomID, messageType string, status ResumeStatus) ([]byte, error) {
+	data, _ := json.Marshal(status)
+	return json.Marshal(Message{Type: messageType, RoomID: roomID, Data: data})
+}
+
+// requestSnapshot tells a client whose backlog was dropped to fetch a snapshot. It is
+// queued on the outbox the drop just emptied.
+func (c *Client) requestSnapshot() bool {
+	message, err := statusMessage(c.roomID, MessageTypeSnapshotRequired, ResumeStatus{Reason: SnapshotReasonOverflow})
+	if err != nil {
+		return false
+	}
+	return c.outbox().push(message)
+}
+
+// reply queues the response to a client's command. A command always gets its response:
+// when the outbox is full the client is asked for a snapshot to replace the backlog it
+// lost, and the response follows. It reports false only when neither could be queued.
+func (c *Client) reply(response []byte) bool {
+	if c.outbox().push(response) {
+		return true
+	}
+	logger.Warn().
+		Str("client_id", c.id).
+		Str("room_id", c.roomID).
+		Msg("Client fell too far behind, requesting a snapshot")
+	return c.requestSnapshot() && c.outbox().push(response)
+}
+
+// recipients returns the users a room message is limited to, or nil if it is for everyone
+func recipients(message []byte) map[string]bool {
+	var msg struct {
+		To []string `json:"to"`
+	}
+	if err := json.Unmarshal(message, &msg); err != nil || len(msg.To) == 0 {
+		return nil
+	}
+	to := make(map[string]bool, len(msg.To))
+	for _, userID := range msg.To {
+		to[userID] = true
+	}
+	return to
+}
+
+// withSeq adds a room message's sequence number to it, keeping fields Message doesn't know
+func withSeq(message []byte, seq int64) []byte {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(message, &fields); err != nil {
+		return message
+	}
+	fields["seq"], _ = json.Marshal(seq)
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return message
+	}
+	return data
+}
+
+// outbox returns the client's send queue, starting its delivery on first use
+func (c *Client) outbox() *outbox {
+	c.outOnce.Do(func() {
+		c.out = newOutbox()
+		go c.out.flush(c.send)
+	})
+	return c.out
 }
 
 // logClientConnection logs client connection/disconnection events
@@ -152,6 +430,15 @@ func (h *Hub) logClientConnection(client *Client, message string) {
 		Str("username", client.username).
 		Str("room_id", client.roomID).
 		Str("role", client.role).
+		Str("node_id", h.backend.NodeID()).
+		Msg(message)
+}
+
+func (h *Hub) logBackendError(err error, roomID, message string) {
+	logger.Error().
+		Err(err).
+		Str("room_id", roomID).
+		Str("node_id", h.backend.NodeID()).
 		Msg(message)
 }
 
@@ -173,10 +460,50 @@ func (c *Client) ReadPump() {
 			}
 			break
 		}
-		c.hub.broadcast <- message
+		if c.commands != nil {
+			if !c.reply(c.commands.Dispatch(context.Background(), c.call(), message)) {
+				// Closing is better than leaving a command without its response
+				logger.Error().
+					Str("client_id", c.id).
+					Str("room_id", c.roomID).
+					Msg("Could not queue a command response, closing the connection")
+				break
+			}
+			continue
+		}
+		c.relay(message)
 	}
 }
 
+// relay broadcasts a message from a client without commands to its room. The room and
+// sender are always the client's own, whatever the message claims, and it reaches the
+// whole room: only the server addresses messages to some players. Spectators only watch.
+func (c *Client) relay(message []byte) {
+	if c.role == RoleSpectator {
+		return
+	}
+	// Fields the hub doesn't know about are the client's own and pass through untouched
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(message, &fields); err != nil {
+		return
+	}
+	for key, value := range map[string]string{"roomId": c.roomID, "playerId": c.id, "username": c.username, "role": c.role} {
+		fields[key], _ = json.Marshal(value)
+	}
+	delete(fields, "seq")
+	delete(fields, "to")
+	stamped, err := json.Marshal(fields)
+	if err != nil {
+		return
+	}
+	c.hub.Broadcast(stamped)
+}
+
+// call describes the client to the commands it runs
+func (c *Client) call() *Call {
+	return &Call{UserID: c.id, Username: c.username, Role: c.role, RoomID: c.roomID, hub: c.hub}
+}
+
 func (c *Client) WritePump() {
 	defer func() { _ = c.conn.Close() }()
 
@@ -186,9 +513,24 @@ func (c *Client) WritePump() {
 	_ = c.conn.WriteMessage(websocket.CloseMessage, []byte{})
 }
 
-// Broadcast sends a message to the hub's broadcast channel
+// Broadcast sends a message to everyone in the room it names, on every server node,
+// numbered with the room's next sequence number
 func (h *Hub) Broadcast(message []byte) {
-	h.broadcast <- message
+	msg, err := h.parseMessage(message)
+	if err != nil || msg.RoomID == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), backendTimeout)
+	defer cancel()
+	if _, err := h.backend.Publish(ctx, msg.RoomID, message); err != nil {
+		h.logBackendError(err, msg.RoomID, "Failed to publish message")
+	}
+}
+
+// Presence lists everyone connected to a room, on every server node
+func (h *Hub) Presence(ctx context.Context, roomID string) ([]Presence, error) {
+	return h.backend.Presence(ctx, roomID)
 }
 
 // Shutdown gracefully stops the hub and closes all connections
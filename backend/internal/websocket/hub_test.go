@@ -0,0 +1,471 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/models"
+)
+
+// startNodes runs one hub per node on a shared in-memory backend, standing in for replicas
+func startNodes(t *testing.T, count int) []*Hub {
+	backend := NewMemoryBackend()
+	hubs := make([]*Hub, count)
+	for i := range hubs {
+		hub := NewHub()
+		if i == 0 {
+			hub.SetBackend(backend)
+		} else {
+			hub.SetBackend(backend.Peer())
+		}
+		go hub.Run()
+		hubs[i] = hub
+	}
+	t.Cleanup(func() {
+		for _, hub := range hubs {
+			_ = hub.Shutdown(context.Background())
+		}
+	})
+	return hubs
+}
+
+func joinRoom(hub *Hub, id, roomID string) *Client {
+	client := &Client{hub: hub, send: make(chan []byte, 256), id: id, username: id, roomID: roomID, role: "player"}
+	hub.register <- client
+	return client
+}
+
+// nextMessage returns the next message of the given type the client receives
+func nextMessage(t *testing.T, client *Client, messageType string) *Message {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case data := <-client.send:
+			var msg Message
+			require.NoError(t, json.Unmarshal(data, &msg))
+			if msg.Type == messageType {
+				return &msg
+			}
+		case <-timeout:
+			t.Fatalf("%s never received a %s message", client.id, messageType)
+			return nil
+		}
+	}
+}
+
+// waitForPresence waits until the room has count connections across all nodes
+func waitForPresence(t *testing.T, hub *Hub, roomID string, count int) []Presence {
+	t.Helper()
+	var members []Presence
+	require.Eventually(t, func() bool {
+		var err error
+		members, err = hub.Presence(context.Background(), roomID)
+		return err == nil && len(members) == count
+	}, time.Second, 5*time.Millisecond)
+	return members
+}
+
+func roomMessage(roomID, messageType, text string) []byte {
+	data, _ := json.Marshal(map[string]string{"text": text})
+	message, _ := json.Marshal(Message{Type: messageType, RoomID: roomID, Data: data})
+	return message
+}
+
+func TestHub_CrossNode(t *testing.T) {
+	t.Run("a broadcast on one node reaches the room on every node", func(t *testing.T) {
+		hubs := startNodes(t, 2)
+		alice := joinRoom(hubs[0], "alice", "game-1")
+		bob := joinRoom(hubs[1], "bob", "game-1")
+		carol := joinRoom(hubs[1], "carol", "game-2")
+		waitForPresence(t, hubs[0], "game-1", 2)
+
+		hubs[0].Broadcast(roomMessage("game-1", "chat", "hello"))
+
+		assert.JSONEq(t, `{"text": "hello"}`, string(nextMessage(t, bob, "chat").Data))
+		assert.JSONEq(t, `{"text": "hello"}`, string(nextMessage(t, alice, "chat").Data))
+		select {
+		case data := <-carol.send:
+			var msg Message
+			require.NoError(t, json.Unmarshal(data, &msg))
+			assert.NotEqual(t, "chat", msg.Type, "other rooms never see the message")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("presence is shared across nodes", func(t *testing.T) {
+		hubs := startNodes(t, 2)
+		joinRoom(hubs[0], "alice", "game-1")
+		joinRoom(hubs[1], "bob", "game-1")
+
+		waitForPresence(t, hubs[0], "game-1", 2)
+		members, err := hubs[1].Presence(context.Background(), "game-1")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"alice", "bob"}, []string{members[0].Username, members[1].Username})
+		assert.NotEqual(t, members[0].NodeID, members[1].NodeID)
+	})
+
+	t.Run("a player leaving drops out of presence", func(t *testing.T) {
+		hubs := startNodes(t, 2)
+		joinRoom(hubs[0], "alice", "game-1")
+		bob := joinRoom(hubs[1], "bob", "game-1")
+		waitForPresence(t, hubs[0], "game-1", 2)
+
+		hubs[1].unregister <- bob
+
+		members := waitForPresence(t, hubs[0], "game-1", 1)
+		assert.Equal(t, "alice", members[0].Username)
+	})
+
+	t.Run("every node sees a room's messages in the same order", func(t *testing.T) {
+		hubs := startNodes(t, 3)
+		clients := []*Client{joinRoom(hubs[0], "a", "game-1"), joinRoom(hubs[1], "b", "game-1"), joinRoom(hubs[2], "c", "game-1")}
+		waitForPresence(t, hubs[0], "game-1", 3)
+
+		var wg sync.WaitGroup
+		for n, hub := range hubs {
+			wg.Add(1)
+			go func(n int, hub *Hub) {
+				defer wg.Done()
+				for i := 0; i < 20; i++ {
+					hub.Broadcast(roomMessage("game-1", "roll", fmt.Sprintf("%d-%d", n, i)))
+				}
+			}(n, hub)
+		}
+		wg.Wait()
+
+		var orders [][]string
+		for _, client := range clients {
+			var order []string
+			for len(order) < 60 {
+				order = append(order, string(nextMessage(t, client, "roll").Data))
+			}
+			orders = append(orders, order)
+		}
+		assert.Equal(t, orders[0], orders[1])
+		assert.Equal(t, orders[0], orders[2])
+	})
+}
+
+func resumeRoom(hub *Hub, id, roomID string, lastSeq int64) *Client {
+	client := &Client{hub: hub, send: make(chan []byte, 256), id: id, username: id, roomID: roomID, role: "player", resumeFrom: &lastSeq}
+	hub.register <- client
+	return client
+}
+
+func resumeStatus(t *testing.T, msg *Message) ResumeStatus {
+	t.Helper()
+	var status ResumeStatus
+	require.NoError(t, json.Unmarshal(msg.Data, &status))
+	return status
+}
+
+func TestHub_Sequencing(t *testing.T) {
+	t.Run("room messages are numbered in order across nodes", func(t *testing.T) {
+		hubs := startNodes(t, 2)
+		alice := joinRoom(hubs[0], "alice", "game-1")
+		joinRoom(hubs[1], "bob", "game-1")
+		waitForPresence(t, hubs[0], "game-1", 2)
+
+		hubs[0].Broadcast(roomMessage("game-1", "roll", "1"))
+		hubs[1].Broadcast(roomMessage("game-1", "roll", "2"))
+
+		assert.Equal(t, int64(1), nextMessage(t, alice, "roll").Seq)
+		assert.Equal(t, int64(2), nextMessage(t, alice, "roll").Seq)
+	})
+
+	t.Run("fields the hub doesn't know survive numbering", func(t *testing.T) {
+		hubs := startNodes(t, 1)
+		alice := joinRoom(hubs[0], "alice", "game-1")
+		waitForPresence(t, hubs[0], "game-1", 1)
+
+		hubs[0].Broadcast([]byte(`{"type": "message", "roomId": "game-1", "content": "hi"}`))
+
+		var received map[string]interface{}
+		require.NoError(t, json.Unmarshal(<-alice.send, &received))
+		assert.Equal(t, "hi", received["content"])
+		assert.Equal(t, float64(1), received["seq"])
+	})
+
+	t.Run("a reconnecting client is sent what it missed", func(t *testing.T) {
+		hubs := startNodes(t, 2)
+		alice := joinRoom(hubs[0], "alice", "game-1")
+		waitForPresence(t, hubs[0], "game-1", 1)
+		for i := 1; i <= 5; i++ {
+			hubs[0].Broadcast(roomMessage("game-1", "roll", fmt.Sprint(i)))
+		}
+		nextMessage(t, alice, "roll")
+		for seq := int64(2); seq <= 5; seq++ {
+			require.Equal(t, seq, nextMessage(t, alice, "roll").Seq)
+		}
+
+		bob := resumeRoom(hubs[1], "bob", "game-1", 2)
+
+		for seq := int64(3); seq <= 5; seq++ {
+			assert.Equal(t, seq, nextMessage(t, bob, "roll").Seq)
+		}
+		status := resumeStatus(t, nextMessage(t, bob, MessageTypeResumed))
+		assert.Equal(t, ResumeStatus{LastSeq: 5, Replayed: 3}, status)
+
+		hubs[0].Broadcast(roomMessage("game-1", "roll", "6"))
+		assert.Equal(t, int64(6), nextMessage(t, bob, "roll").Seq, "live messages follow the replay")
+	})
+
+	t.Run("a client missing more than the buffer holds needs a snapshot", func(t *testing.T) {
+		backend := NewMemoryBackend()
+		backend.SetReplayLimit(3)
+		hub := NewHub()
+		hub.SetBackend(backend)
+		go hub.Run()
+		t.Cleanup(func() { _ = hub.Shutdown(context.Background()) })
+		for i := 1; i <= 10; i++ {
+			hub.Broadcast(roomMessage("game-1", "roll", fmt.Sprint(i)))
+		}
+
+		alice := resumeRoom(hub, "alice", "game-1", 4)
+
+		status := resumeStatus(t, nextMessage(t, alice, MessageTypeSnapshotRequired))
+		assert.Equal(t, ResumeStatus{LastSeq: 10, Reason: SnapshotReasonGap}, status)
+		hub.Broadcast(roomMessage("game-1", "roll", "11"))
+		assert.Equal(t, int64(11), nextMessage(t, alice, "roll").Seq)
+	})
+
+	t.Run("a restarted server continues its rooms from the replay store", func(t *testing.T) {
+		store := &replayStore{}
+		before := NewMemoryBackend()
+		before.SetReplayStore(store)
+		before.SetReplayLimit(3)
+		for i := 1; i <= 5; i++ {
+			_, err := before.Publish(context.Background(), "game-1", roomMessage("game-1", "roll", fmt.Sprint(i)))
+			require.NoError(t, err)
+		}
+		require.NoError(t, before.Close())
+
+		after := NewMemoryBackend()
+		after.SetReplayStore(store)
+		after.SetReplayLimit(3)
+		t.Cleanup(func() { _ = after.Close() })
+
+		replay, err := after.Replay(context.Background(), "game-1", 3)
+		require.NoError(t, err)
+		assert.True(t, replay.Complete)
+		assert.Equal(t, int64(5), replay.LatestSeq)
+		require.Len(t, replay.Messages, 2)
+		assert.Equal(t, int64(4), replay.Messages[0].Seq)
+		seq, err := after.Publish(context.Background(), "game-1", roomMessage("game-1", "roll", "6"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(6), seq)
+		assert.Len(t, store.messages, 3, "the store keeps only the replay buffer")
+	})
+
+	t.Run("a client ahead of the room needs a snapshot", func(t *testing.T) {
+		hubs := startNodes(t, 1)
+
+		alice := resumeRoom(hubs[0], "alice", "game-1", 40)
+
+		status := resumeStatus(t, nextMessage(t, alice, MessageTypeSnapshotRequired))
+		assert.Equal(t, ResumeStatus{Reason: SnapshotReasonGap}, status)
+	})
+
+	t.Run("a slow client is asked for a snapshot instead of being dropped", func(t *testing.T) {
+		hubs := startNodes(t, 1)
+		alice := &Client{hub: hubs[0], send: make(chan []byte), id: "alice", username: "alice", roomID: "game-1", role: "player"}
+		hubs[0].register <- alice
+		total := maxQueuedMessages + 10
+		bob := &Client{hub: hubs[0], send: make(chan []byte, total), id: "bob", username: "bob", roomID: "game-1", role: "player"}
+		hubs[0].register <- bob
+		waitForPresence(t, hubs[0], "game-1", 2)
+
+		for i := 1; i <= total; i++ {
+			hubs[0].Broadcast(roomMessage("game-1", "roll", fmt.Sprint(i)))
+		}
+		received := nextMessage(t, bob, "roll")
+		for received.Seq < int64(total) {
+			received = nextMessage(t, bob, "roll")
+		}
+		joinRoom(hubs[0], "carol", "game-2") // once registered, the hub has finished the last broadcast
+
+		status := resumeStatus(t, nextMessage(t, alice, MessageTypeSnapshotRequired))
+		assert.Equal(t, SnapshotReasonOverflow, status.Reason)
+		assert.Greater(t, status.LastSeq, int64(maxQueuedMessages))
+		last := nextMessage(t, alice, "roll")
+		for last.Seq < int64(total) {
+			next := nextMessage(t, alice, "roll")
+			require.Equal(t, last.Seq+1, next.Seq)
+			last = next
+		}
+		waitForPresence(t, hubs[0], "game-1", 2)
+	})
+}
+
+// replayStore keeps room messages the way the database does, across backends
+type replayStore struct {
+	mu       sync.Mutex
+	messages []*models.RoomMessage
+}
+
+func (s *replayStore) AppendRoomMessage(_ context.Context, message *models.RoomMessage, keep int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := []*models.RoomMessage{message}
+	for _, stored := range s.messages {
+		if stored.RoomID != message.RoomID || stored.Seq > message.Seq-int64(keep) {
+			kept = append(kept, stored)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool { return kept[i].Seq < kept[j].Seq })
+	s.messages = kept
+	return nil
+}
+
+func (s *replayStore) ListRoomMessages(_ context.Context, roomID string) ([]*models.RoomMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var messages []*models.RoomMessage
+	for _, stored := range s.messages {
+		if stored.RoomID == roomID {
+			messages = append(messages, stored)
+		}
+	}
+	return messages, nil
+}
+
+func (s *replayStore) DeleteQuietRooms(context.Context, time.Time) error {
+	return nil
+}
+
+func TestHub_Recipients(t *testing.T) {
+	hubs := startNodes(t, 2)
+	alice := joinRoom(hubs[0], "alice", "game-1")
+	bob := joinRoom(hubs[1], "bob", "game-1")
+	carol := joinRoom(hubs[0], "carol", "game-1")
+	waitForPresence(t, hubs[0], "game-1", 3)
+
+	whisper, _ := json.Marshal(Message{Type: "chat", RoomID: "game-1", To: []string{"alice", "bob"}, Data: json.RawMessage(`{"text":"psst"}`)})
+	hubs[0].Broadcast(whisper)
+	hubs[0].Broadcast(roomMessage("game-1", "chat", "hello"))
+
+	assert.Equal(t, int64(1), nextMessage(t, alice, "chat").Seq)
+	assert.Equal(t, int64(1), nextMessage(t, bob, "chat").Seq)
+
+	public := nextMessage(t, carol, "chat")
+	assert.Equal(t, int64(2), public.Seq, "carol skips the whisper but keeps count")
+	assert.JSONEq(t, `{"text":"hello"}`, string(public.Data))
+}
+
+func TestClient_Relay(t *testing.T) {
+	hubs := startNodes(t, 1)
+	alice := joinRoom(hubs[0], "alice", "game-1")
+	bob := joinRoom(hubs[0], "bob", "game-1")
+	carol := joinRoom(hubs[0], "carol", "game-2")
+	waitForPresence(t, hubs[0], "game-1", 2)
+	waitForPresence(t, hubs[0], "game-2", 1)
+
+	alice.relay([]byte(`{"type":"chat","roomId":"game-2","playerId":"carol","username":"carol","role":"dm",` +
+		`"to":["alice"],"seq":7,"content":"trust me","data":{"text":"trust me"}}`))
+
+	raw := <-bob.send
+	assert.Contains(t, string(raw), `"content":"trust me"`, "the client's own fields pass through")
+	var relayed Message
+	require.NoError(t, json.Unmarshal(raw, &relayed))
+	assert.Equal(t, "game-1", relayed.RoomID, "messages stay in the sender's room")
+	assert.Equal(t, "alice", relayed.PlayerID)
+	assert.Equal(t, "alice", relayed.Username)
+	assert.Equal(t, "player", relayed.Role)
+	assert.Empty(t, relayed.To, "clients cannot whisper through a relay")
+	assert.Equal(t, int64(1), relayed.Seq)
+	nextMessage(t, alice, "chat")
+
+	select {
+	case data := <-carol.send:
+		t.Fatalf("carol's room received a relayed message: %s", data)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// fillOutbox gives the client an outbox holding all it can, with nothing delivering from it
+func fillOutbox(client *Client) {
+	client.outOnce.Do(func() { client.out = newOutbox() })
+	for i := 0; i < maxQueuedMessages; i++ {
+		client.out.push(roomMessage(client.roomID, "roll", fmt.Sprint(i)))
+	}
+}
+
+// queued returns the messages waiting in the client's outbox
+func queued(t *testing.T, client *Client) []Message {
+	t.Helper()
+	var messages []Message
+	for data, ok := client.out.pop(); ok; data, ok = client.out.pop() {
+		var msg Message
+		require.NoError(t, json.Unmarshal(data, &msg))
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+func TestClient_Reply(t *testing.T) {
+	t.Run("a command's response survives a full outbox", func(t *testing.T) {
+		alice := &Client{id: "alice", username: "alice", roomID: "game-1", role: "player"}
+		fillOutbox(alice)
+
+		require.True(t, alice.reply([]byte(`{"type":"response","roomId":"game-1"}`)))
+
+		messages := queued(t, alice)
+		require.Len(t, messages, 2)
+		assert.Equal(t, MessageTypeSnapshotRequired, messages[0].Type)
+		assert.Equal(t, SnapshotReasonOverflow, resumeStatus(t, &messages[0]).Reason)
+		assert.Equal(t, "response", messages[1].Type)
+	})
+
+	t.Run("a status that doesn't fit asks for a snapshot", func(t *testing.T) {
+		alice := &Client{id: "alice", username: "alice", roomID: "game-1", role: "player"}
+		fillOutbox(alice)
+
+		NewHub().sendStatus(alice, MessageTypeResumed, ResumeStatus{LastSeq: 5, Replayed: 3})
+
+		messages := queued(t, alice)
+		require.Len(t, messages, 1)
+		assert.Equal(t, MessageTypeSnapshotRequired, messages[0].Type)
+		assert.Equal(t, SnapshotReasonOverflow, resumeStatus(t, &messages[0]).Reason)
+	})
+}
+
+// stalledBackend holds presence updates until released, standing in for a backend that has stopped answering
+type stalledBackend struct {
+	*MemoryBackend
+	release chan struct{}
+}
+
+func (b *stalledBackend) SetPresence(ctx context.Context, roomID string, member Presence) error {
+	<-b.release
+	return b.MemoryBackend.SetPresence(ctx, roomID, member)
+}
+
+func TestHub_StalledBackend(t *testing.T) {
+	backend := &stalledBackend{MemoryBackend: NewMemoryBackend(), release: make(chan struct{})}
+	hub := NewHub()
+	hub.SetBackend(backend)
+	go hub.Run()
+	t.Cleanup(func() { _ = hub.Shutdown(context.Background()) })
+	defer close(backend.release)
+
+	alice := joinRoom(hub, "alice", "game-1")
+	bob := joinRoom(hub, "bob", "game-1")
+	require.Eventually(t, func() bool {
+		backend.bus.mu.Lock()
+		defer backend.bus.mu.Unlock()
+		return backend.rooms["game-1"]
+	}, time.Second, 5*time.Millisecond, "the room is subscribed to before presence stalls")
+	hub.Broadcast(roomMessage("game-1", "chat", "hello"))
+
+	assert.JSONEq(t, `{"text": "hello"}`, string(nextMessage(t, alice, "chat").Data), "the hub keeps delivering while presence waits")
+	assert.JSONEq(t, `{"text": "hello"}`, string(nextMessage(t, bob, "chat").Data))
+}
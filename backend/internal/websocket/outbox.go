@@ -0,0 +1,83 @@
+package websocket
+
+import "sync"
+
+// maxQueuedMessages is how far a client may fall behind before its backlog is dropped
+// and it is told to fetch a snapshot instead
+const maxQueuedMessages = 1024
+
+// outbox queues a client's messages between the hub and its send channel, so a slow
+// connection never holds up the hub or the rest of its room
+type outbox struct {
+	mu      sync.Mutex
+	queue   [][]byte
+	ready   chan struct{}
+	done    chan struct{}
+	closing sync.Once
+}
+
+func newOutbox() *outbox {
+	return &outbox{
+		ready: make(chan struct{}, 1),
+		done:  make(chan struct{}),
+	}
+}
+
+// push queues a message without blocking. A full queue drops its backlog and refuses the
+// message, so the caller can send something that replaces it.
+func (o *outbox) push(message []byte) bool {
+	o.mu.Lock()
+	if len(o.queue) >= maxQueuedMessages {
+		o.queue = nil
+		o.mu.Unlock()
+		return false
+	}
+	o.queue = append(o.queue, message)
+	o.mu.Unlock()
+
+	select {
+	case o.ready <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+func (o *outbox) pop() ([]byte, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.queue) == 0 {
+		return nil, false
+	}
+	message := o.queue[0]
+	o.queue = o.queue[1:]
+	return message, true
+}
+
+// close stops delivery and closes the client's send channel, dropping anything queued
+func (o *outbox) close() {
+	o.closing.Do(func() { close(o.done) })
+}
+
+// flush moves queued messages onto send, waiting for the connection to keep up, and
+// closes send when the outbox closes
+func (o *outbox) flush(send chan []byte) {
+	defer close(send)
+	for {
+		select {
+		case <-o.done:
+			return
+		case <-o.ready:
+		}
+		for {
+			message, ok := o.pop()
+			if !ok {
+				break
+			}
+			select {
+			case send <- message:
+			case <-o.done:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Protocol versions a client can ask for in its auth message
+const (
+	// ProtocolV1 broadcasts whatever a client sends to its room
+	ProtocolV1 = 1
+	// ProtocolV2 takes typed command requests and answers each with a response or error
+	// frame carrying the request's id. Room events keep the Message shape.
+	ProtocolV2 = 2
+)
+
+// Frame types of protocol v2
+const (
+	FrameTypeRequest  = "request"
+	FrameTypeResponse = "response"
+	FrameTypeError    = "error"
+)
+
+// Error codes carried by error frames
+const (
+	ErrCodeInvalidFrame        = "invalid_frame"
+	ErrCodeUnknownCommand      = "unknown_command"
+	ErrCodeInvalidParams       = "invalid_params"
+	ErrCodeForbidden           = "forbidden"
+	ErrCodeNotFound            = "not_found"
+	ErrCodeCommandFailed       = "command_failed"
+	ErrCodeUnsupportedProtocol = "unsupported_protocol"
+)
+
+// commandTimeout bounds how long one command may run
+const commandTimeout = 30 * time.Second
+
+// RequestFrame asks the server to run a command. ID is chosen by the client and comes
+// back on the matching response or error frame.
+type RequestFrame struct {
+	Type    string          `json:"type"`
+	ID      string          `json:"id"`
+	Command string          `json:"command"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// ResponseFrame carries the result of a command
+type ResponseFrame struct {
+	Type    string      `json:"type"`
+	ID      string      `json:"id"`
+	Command string      `json:"command"`
+	Result  interface{} `json:"result"`
+}
+
+// ErrorFrame reports why a command failed. Fields names the invalid params.
+type ErrorFrame struct {
+	Type    string            `json:"type"`
+	ID      string            `json:"id,omitempty"`
+	Command string            `json:"command,omitempty"`
+	Code    string            `json:"code"`
+	Error   string            `json:"error"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+// CommandError is an error a command handler returns to pick the error frame's code
+type CommandError struct {
+	Code    string
+	Message string
+	Fields  map[string]string
+}
+
+func (e *CommandError) Error() string {
+	return e.Message
+}
+
+// NewCommandError creates a command error with a code
+func NewCommandError(code, message string) *CommandError {
+	return &CommandError{Code: code, Message: message}
+}
+
+// Call is the connection a command arrived on
+type Call struct {
+	UserID   string
+	Username string
+	Role     string
+	RoomID   string
+	hub      *Hub
+}
+
+// Broadcast sends an event to everyone in the caller's room
+func (c *Call) Broadcast(eventType string, data interface{}) error {
+	return c.BroadcastTo(eventType, data, nil)
+}
+
+// BroadcastTo sends an event to some users in the caller's room, or to everyone when to
+// is empty
+func (c *Call) BroadcastTo(eventType string, data interface{}, to []string) error {
+	if c.hub == nil || c.RoomID == "" {
+		return fmt.Errorf("connection is not in a room")
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	message, err := json.Marshal(Message{
+		Type:     eventType,
+		RoomID:   c.RoomID,
+		PlayerID: c.UserID,
+		Username: c.Username,
+		Role:     c.Role,
+		To:       to,
+		Data:     payload,
+	})
+	if err != nil {
+		return err
+	}
+	c.hub.Broadcast(message)
+	return nil
+}
+
+type command struct {
+	description string
+	params      reflect.Type
+	result      reflect.Type
+	handle      func(ctx context.Context, call *Call, params interface{}) (interface{}, error)
+}
+
+type event struct {
+	description string
+	data        reflect.Type
+}
+
+// Protocol is the registry of commands clients may run and events rooms may carry. It
+// validates requests against the registered params and describes itself as a schema.
+type Protocol struct {
+	commands map[string]*command
+	events   map[string]*event
+	validate *validator.Validate
+}
+
+// NewProtocol creates a registry holding the hub's own events
+func NewProtocol() *Protocol {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	p := &Protocol{
+		commands: make(map[string]*command),
+		events:   make(map[string]*event),
+		validate: v,
+	}
+	p.RegisterEvent(MessageTypeResumed, "A reconnecting client has been sent what it missed", ResumeStatus{})
+	p.RegisterEvent(MessageTypeSnapshotRequired, "The client must refetch the game state before carrying on", ResumeStatus{})
+	return p
+}
+
+// RegisterCommand adds a command. Params are decoded strictly and checked against their
+// validate tags before the handler runs.
+func RegisterCommand[P, R any](p *Protocol, name, description string, handler func(ctx context.Context, call *Call, params *P) (*R, error)) {
+	p.commands[name] = &command{
+		description: description,
+		params:      reflect.TypeOf((*P)(nil)).Elem(),
+		result:      reflect.TypeOf((*R)(nil)).Elem(),
+		handle: func(ctx context.Context, call *Call, params interface{}) (interface{}, error) {
+			return handler(ctx, call, params.(*P))
+		},
+	}
+}
+
+// RegisterEvent documents a room event and the shape of its data
+func (p *Protocol) RegisterEvent(name, description string, data interface{}) {
+	p.events[name] = &event{description: description, data: reflect.TypeOf(data)}
+}
+
+// Commands lists the registered command names
+func (p *Protocol) Commands() []string {
+	names := make([]string, 0, len(p.commands))
+	for name := range p.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Dispatch runs one request frame and returns the response or error frame to send back
+func (p *Protocol) Dispatch(ctx context.Context, call *Call, message []byte) []byte {
+	var request RequestFrame
+	if err := json.Unmarshal(message, &request); err != nil {
+		return errorFrame(&request, NewCommandError(ErrCodeInvalidFrame, "Message is not valid JSON"))
+	}
+	if request.Type != FrameTypeRequest || request.ID == "" || request.Command == "" {
+		return errorFrame(&request, NewCommandError(ErrCodeInvalidFrame, "Expected a request frame with an id and a command"))
+	}
+
+	cmd, ok := p.commands[request.Command]
+	if !ok {
+		return errorFrame(&request, NewCommandError(ErrCodeUnknownCommand, fmt.Sprintf("Unknown command: %s", request.Command)))
+	}
+
+	params := reflect.New(cmd.params).Interface()
+	if err := p.decodeParams(request.Params, params); err != nil {
+		return errorFrame(&request, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+	result, err := cmd.handle(ctx, call, params)
+	if err != nil {
+		return errorFrame(&request, err)
+	}
+
+	data, err := json.Marshal(ResponseFrame{Type: FrameTypeResponse, ID: request.ID, Command: request.Command, Result: result})
+	if err != nil {
+		return errorFrame(&request, err)
+	}
+	return data
+}
+
+// decodeParams rejects unknown fields and params failing their validate tags
+func (p *Protocol) decodeParams(raw json.RawMessage, params interface{}) error {
+	if len(raw) == 0 {
+		raw = json.RawMessage("{}")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(params); err != nil {
+		return NewCommandError(ErrCodeInvalidParams, fmt.Sprintf("Invalid params: %v", err))
+	}
+
+	if reflect.TypeOf(params).Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	err := p.validate.Struct(params)
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return err
+	}
+	fields := make(map[string]string, len(invalid))
+	for _, fieldErr := range invalid {
+		fields[fieldErr.Field()] = fieldErr.Tag()
+	}
+	return &CommandError{Code: ErrCodeInvalidParams, Message: "Invalid params", Fields: fields}
+}
+
+func errorFrame(request *RequestFrame, err error) []byte {
+	frame := ErrorFrame{Type: FrameTypeError, ID: request.ID, Command: request.Command, Code: ErrCodeCommandFailed, Error: err.Error()}
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) {
+		frame.Code = cmdErr.Code
+		frame.Fields = cmdErr.Fields
+	}
+	data, _ := json.Marshal(frame)
+	return data
+}
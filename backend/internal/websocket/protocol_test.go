@@ -0,0 +1,191 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	gorilla "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/auth"
+	"github.com/ctclostio/DnD-Game/backend/pkg/logger"
+)
+
+type echoParams struct {
+	Text  string `json:"text" validate:"required"`
+	Times int    `json:"times,omitempty" validate:"omitempty,min=1,max=3"`
+}
+
+type echoResult struct {
+	Text   string `json:"text"`
+	UserID string `json:"userId"`
+}
+
+func newTestProtocol() *Protocol {
+	protocol := NewProtocol()
+	RegisterCommand(protocol, "test.echo", "Echo the text back", func(_ context.Context, call *Call, params *echoParams) (*echoResult, error) {
+		return &echoResult{Text: strings.Repeat(params.Text, max(params.Times, 1)), UserID: call.UserID}, nil
+	})
+	RegisterCommand(protocol, "test.forbidden", "Always refused", func(_ context.Context, _ *Call, _ *struct{}) (*echoResult, error) {
+		return nil, NewCommandError(ErrCodeForbidden, "Not for you")
+	})
+	return protocol
+}
+
+func dispatch(t *testing.T, protocol *Protocol, request string) map[string]interface{} {
+	t.Helper()
+	var frame map[string]interface{}
+	require.NoError(t, json.Unmarshal(protocol.Dispatch(context.Background(), &Call{UserID: "user-1"}, []byte(request)), &frame))
+	return frame
+}
+
+func TestProtocol_Dispatch(t *testing.T) {
+	protocol := newTestProtocol()
+
+	t.Run("a request gets a response with its id", func(t *testing.T) {
+		frame := dispatch(t, protocol, `{"type": "request", "id": "7", "command": "test.echo", "params": {"text": "hi", "times": 2}}`)
+
+		assert.Equal(t, FrameTypeResponse, frame["type"])
+		assert.Equal(t, "7", frame["id"])
+		assert.Equal(t, "test.echo", frame["command"])
+		assert.Equal(t, map[string]interface{}{"text": "hihi", "userId": "user-1"}, frame["result"])
+	})
+
+	tests := []struct {
+		name    string
+		request string
+		code    string
+		fields  map[string]interface{}
+	}{
+		{"not json", `{"type": `, ErrCodeInvalidFrame, nil},
+		{"not a request", `{"type": "chat", "id": "1", "command": "test.echo"}`, ErrCodeInvalidFrame, nil},
+		{"no id", `{"type": "request", "command": "test.echo"}`, ErrCodeInvalidFrame, nil},
+		{"unknown command", `{"type": "request", "id": "1", "command": "test.nope"}`, ErrCodeUnknownCommand, nil},
+		{"missing params", `{"type": "request", "id": "1", "command": "test.echo"}`, ErrCodeInvalidParams, map[string]interface{}{"text": "required"}},
+		{"out of range", `{"type": "request", "id": "1", "command": "test.echo", "params": {"text": "a", "times": 9}}`, ErrCodeInvalidParams, map[string]interface{}{"times": "max"}},
+		{"unknown field", `{"type": "request", "id": "1", "command": "test.echo", "params": {"text": "a", "loud": true}}`, ErrCodeInvalidParams, nil},
+		{"wrong type", `{"type": "request", "id": "1", "command": "test.echo", "params": {"text": 3}}`, ErrCodeInvalidParams, nil},
+		{"refused by the handler", `{"type": "request", "id": "1", "command": "test.forbidden"}`, ErrCodeForbidden, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame := dispatch(t, protocol, tt.request)
+
+			assert.Equal(t, FrameTypeError, frame["type"])
+			assert.Equal(t, tt.code, frame["code"])
+			assert.NotEmpty(t, frame["error"])
+			if tt.fields != nil {
+				assert.Equal(t, tt.fields, frame["fields"])
+			}
+		})
+	}
+}
+
+func TestCall_Broadcast(t *testing.T) {
+	hubs := startNodes(t, 1)
+	alice := joinRoom(hubs[0], "alice", "game-1")
+	waitForPresence(t, hubs[0], "game-1", 1)
+
+	call := &Call{UserID: "bob", Username: "Bob", Role: "player", RoomID: "game-1", hub: hubs[0]}
+	require.NoError(t, call.Broadcast("chat", map[string]string{"message": "hello"}))
+
+	msg := nextMessage(t, alice, "chat")
+	assert.Equal(t, "bob", msg.PlayerID)
+	assert.Equal(t, "Bob", msg.Username)
+	assert.JSONEq(t, `{"message": "hello"}`, string(msg.Data))
+	assert.Error(t, (&Call{UserID: "bob"}).Broadcast("chat", nil), "a connection outside a room can't broadcast")
+}
+
+func TestProtocol_Schema(t *testing.T) {
+	protocol := newTestProtocol()
+	protocol.RegisterEvent("test.echoed", "Text was echoed", echoResult{})
+
+	data, err := json.Marshal(protocol.Schema())
+	require.NoError(t, err)
+	var schema struct {
+		Versions []int `json:"versions"`
+		Commands map[string]struct {
+			Params map[string]string `json:"params"`
+		} `json:"commands"`
+		Events map[string]json.RawMessage `json:"events"`
+		Defs   map[string]struct {
+			Type       string                     `json:"type"`
+			Required   []string                   `json:"required"`
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"$defs"`
+	}
+	require.NoError(t, json.Unmarshal(data, &schema))
+
+	assert.Equal(t, []int{ProtocolV1, ProtocolV2}, schema.Versions)
+	assert.Equal(t, "#/$defs/websocket.echoParams", schema.Commands["test.echo"].Params["$ref"])
+	params := schema.Defs["websocket.echoParams"]
+	assert.Equal(t, "object", params.Type)
+	assert.Equal(t, []string{"text"}, params.Required)
+	assert.JSONEq(t, `{"type": "integer"}`, string(params.Properties["times"]))
+	assert.Contains(t, schema.Events, "test.echoed")
+	assert.Contains(t, schema.Events, MessageTypeSnapshotRequired)
+	assert.Contains(t, schema.Defs, "websocket.RequestFrame")
+}
+
+func TestHandlerV2_ProtocolNegotiation(t *testing.T) {
+	jwtManager := auth.NewJWTManager("test-secret-key-that-is-long-enough", time.Hour, time.Hour)
+	tokens, err := jwtManager.GenerateTokenPair("user-1", "alice", "alice@example.com", "player")
+	require.NoError(t, err)
+	log, err := logger.NewV2(&logger.ConfigV2{Level: "error"})
+	require.NoError(t, err)
+
+	hub := startNodes(t, 1)[0]
+	handler := NewHandlerV2(hub, jwtManager, log)
+	handler.SetProtocol(newTestProtocol())
+	server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+	t.Cleanup(server.Close)
+
+	connect := func(t *testing.T, protocol int) (*gorilla.Conn, map[string]interface{}) {
+		header := http.Header{"Origin": []string{"http://localhost:3000"}}
+		conn, _, err := gorilla.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), header)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+
+		var authRequired map[string]interface{}
+		require.NoError(t, conn.ReadJSON(&authRequired))
+		assert.Equal(t, []interface{}{float64(ProtocolV1), float64(ProtocolV2)}, authRequired["protocols"])
+
+		require.NoError(t, conn.WriteJSON(AuthMessageV2{Type: "auth", Token: tokens.AccessToken, Room: "game-1", Protocol: protocol}))
+		var reply map[string]interface{}
+		require.NoError(t, conn.ReadJSON(&reply))
+		return conn, reply
+	}
+
+	t.Run("protocol v2 clients run commands", func(t *testing.T) {
+		conn, reply := connect(t, ProtocolV2)
+		require.Equal(t, "auth_success", reply["type"])
+		assert.Equal(t, float64(ProtocolV2), reply["protocol"])
+
+		require.NoError(t, conn.WriteJSON(RequestFrame{Type: FrameTypeRequest, ID: "r1", Command: "test.echo", Params: json.RawMessage(`{"text": "hi"}`)}))
+		var response ResponseFrame
+		require.NoError(t, conn.ReadJSON(&response))
+		assert.Equal(t, FrameTypeResponse, response.Type)
+		assert.Equal(t, "r1", response.ID)
+		assert.Equal(t, map[string]interface{}{"text": "hi", "userId": "user-1"}, response.Result)
+	})
+
+	t.Run("clients that don't ask get protocol v1", func(t *testing.T) {
+		_, reply := connect(t, 0)
+
+		assert.Equal(t, "auth_success", reply["type"])
+		assert.Equal(t, float64(ProtocolV1), reply["protocol"])
+	})
+
+	t.Run("unknown versions are refused", func(t *testing.T) {
+		_, reply := connect(t, 9)
+
+		assert.Equal(t, FrameTypeError, reply["type"])
+		assert.Equal(t, ErrCodeUnsupportedProtocol, reply["code"])
+	})
+}
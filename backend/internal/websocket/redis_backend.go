@@ -0,0 +1,259 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	redis "github.com/redis/go-redis/v9"
+
+	"github.com/ctclostio/DnD-Game/backend/internal/cache"
+	"github.com/ctclostio/DnD-Game/backend/pkg/logger"
+)
+
+const (
+	redisRoomChannelPrefix = "ws:room:"
+	redisPresencePrefix    = "ws:presence:"
+	redisNodePrefix        = "ws:node:"
+	redisSeqPrefix         = "ws:seq:"
+	redisReplayPrefix      = "ws:replay:"
+
+	// A node that stops refreshing its heartbeat for nodeTTL is treated as gone,
+	// along with everyone connected through it
+	nodeTTL           = 30 * time.Second
+	heartbeatInterval = 10 * time.Second
+)
+
+// publishScript numbers a message, keeps it in the room's replay list and publishes it in
+// one step, so the channel carries a room's messages in sequence order. Payloads are the
+// sequence number, a colon and the message.
+var publishScript = redis.NewScript(`
+local seq = redis.call('INCR', KEYS[1])
+local payload = seq .. ':' .. ARGV[1]
+redis.call('RPUSH', KEYS[2], payload)
+redis.call('LTRIM', KEYS[2], -tonumber(ARGV[2]), -1)
+redis.call('EXPIRE', KEYS[1], ARGV[3])
+redis.call('EXPIRE', KEYS[2], ARGV[3])
+redis.call('PUBLISH', KEYS[3], payload)
+return seq
+`)
+
+// RedisBackend shares rooms between server nodes over Redis. Each room is a pub/sub
+// channel, which Redis delivers to every subscriber in the order it received the
+// publishes. Sequence numbers and replay buffers live in Redis too, so they survive a
+// node restart while the session is active. Presence is a hash per room, and a heartbeat
+// key per node lets the members of a crashed node drop out.
+type RedisBackend struct {
+	client   *redis.Client
+	pubsub   *redis.PubSub
+	nodeID   string
+	messages chan BackendMessage
+	done     chan struct{}
+	closing  sync.Once
+}
+
+// NewRedisBackend starts a node on the shared Redis
+func NewRedisBackend(rc *cache.RedisClient) (*RedisBackend, error) {
+	client := rc.GetClient()
+	backend := &RedisBackend{
+		client:   client,
+		pubsub:   client.Subscribe(context.Background()),
+		nodeID:   uuid.New().String(),
+		messages: make(chan BackendMessage, 256),
+		done:     make(chan struct{}),
+	}
+	if err := backend.heartbeat(context.Background()); err != nil {
+		_ = backend.pubsub.Close()
+		return nil, fmt.Errorf("failed to register websocket node: %w", err)
+	}
+
+	go backend.receive()
+	go backend.keepAlive()
+	return backend, nil
+}
+
+// NodeID identifies this node
+func (r *RedisBackend) NodeID() string {
+	return r.nodeID
+}
+
+// Publish numbers the message, buffers it and sends it to every node subscribed to the room
+func (r *RedisBackend) Publish(ctx context.Context, roomID string, message []byte) (int64, error) {
+	keys := []string{redisSeqPrefix + roomID, redisReplayPrefix + roomID, redisRoomChannelPrefix + roomID}
+	return publishScript.Run(ctx, r.client, keys, message, replayBufferSize, int(replayTTL.Seconds())).Int64()
+}
+
+// Replay returns the buffered messages of a room after a sequence number
+func (r *RedisBackend) Replay(ctx context.Context, roomID string, afterSeq int64) (*ReplayResult, error) {
+	var seqCmd *redis.StringCmd
+	var bufferCmd *redis.StringSliceCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		seqCmd = pipe.Get(ctx, redisSeqPrefix+roomID)
+		bufferCmd = pipe.LRange(ctx, redisReplayPrefix+roomID, 0, -1)
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	latestSeq, err := seqCmd.Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	buffer := make([]BackendMessage, 0, len(bufferCmd.Val()))
+	for _, payload := range bufferCmd.Val() {
+		message, err := decodeRoomPayload(roomID, payload)
+		if err != nil {
+			return nil, err
+		}
+		buffer = append(buffer, message)
+	}
+	return replayAfter(buffer, latestSeq, afterSeq), nil
+}
+
+// Subscribe starts delivering the room's messages to this node
+func (r *RedisBackend) Subscribe(ctx context.Context, roomID string) error {
+	return r.pubsub.Subscribe(ctx, redisRoomChannelPrefix+roomID)
+}
+
+// Unsubscribe stops delivering the room's messages to this node
+func (r *RedisBackend) Unsubscribe(ctx context.Context, roomID string) error {
+	return r.pubsub.Unsubscribe(ctx, redisRoomChannelPrefix+roomID)
+}
+
+// Messages delivers the messages of subscribed rooms
+func (r *RedisBackend) Messages() <-chan BackendMessage {
+	return r.messages
+}
+
+// SetPresence records a connection to a room
+func (r *RedisBackend) SetPresence(ctx context.Context, roomID string, member Presence) error {
+	member.NodeID = r.nodeID
+	data, err := json.Marshal(member)
+	if err != nil {
+		return err
+	}
+	return r.client.HSet(ctx, redisPresencePrefix+roomID, member.ConnectionID, data).Err()
+}
+
+// RemovePresence forgets a connection to a room
+func (r *RedisBackend) RemovePresence(ctx context.Context, roomID, connectionID string) error {
+	return r.client.HDel(ctx, redisPresencePrefix+roomID, connectionID).Err()
+}
+
+// Presence lists every connection to a room on a live node, clearing out members of
+// nodes whose heartbeat has lapsed
+func (r *RedisBackend) Presence(ctx context.Context, roomID string) ([]Presence, error) {
+	key := redisPresencePrefix + roomID
+	entries, err := r.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]Presence, 0, len(entries))
+	alive := make(map[string]bool)
+	var stale []string
+	for connectionID, data := range entries {
+		var member Presence
+		if err := json.Unmarshal([]byte(data), &member); err != nil {
+			stale = append(stale, connectionID)
+			continue
+		}
+		live, checked := alive[member.NodeID]
+		if !checked {
+			count, err := r.client.Exists(ctx, redisNodePrefix+member.NodeID).Result()
+			if err != nil {
+				return nil, err
+			}
+			live = count > 0
+			alive[member.NodeID] = live
+		}
+		if !live {
+			stale = append(stale, connectionID)
+			continue
+		}
+		members = append(members, member)
+	}
+	if len(stale) > 0 {
+		_ = r.client.HDel(ctx, key, stale...).Err()
+	}
+	sortPresence(members)
+	return members, nil
+}
+
+// Close stops delivery, drops this node's heartbeat and closes the subscription. Presence
+// left behind is cleared by the next Presence call on any node.
+func (r *RedisBackend) Close() error {
+	var err error
+	r.closing.Do(func() {
+		close(r.done)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = r.client.Del(ctx, redisNodePrefix+r.nodeID).Err()
+		err = r.pubsub.Close()
+	})
+	return err
+}
+
+func (r *RedisBackend) heartbeat(ctx context.Context) error {
+	return r.client.Set(ctx, redisNodePrefix+r.nodeID, time.Now().Unix(), nodeTTL).Err()
+}
+
+func (r *RedisBackend) keepAlive() {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := r.heartbeat(ctx); err != nil {
+				logger.Error().
+					Err(err).
+					Str("node_id", r.nodeID).
+					Msg("Failed to refresh websocket node heartbeat")
+			}
+			cancel()
+		}
+	}
+}
+
+// receive forwards room messages from Redis in the order they arrive
+func (r *RedisBackend) receive() {
+	defer close(r.messages)
+	for message := range r.pubsub.Channel() {
+		delivered, err := decodeRoomPayload(strings.TrimPrefix(message.Channel, redisRoomChannelPrefix), message.Payload)
+		if err != nil {
+			logger.Error().
+				Err(err).
+				Str("channel", message.Channel).
+				Msg("Dropping malformed websocket room message")
+			continue
+		}
+		select {
+		case r.messages <- delivered:
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// decodeRoomPayload splits a published payload into its sequence number and message
+func decodeRoomPayload(roomID, payload string) (BackendMessage, error) {
+	seq, data, found := strings.Cut(payload, ":")
+	if !found {
+		return BackendMessage{}, fmt.Errorf("room message has no sequence number")
+	}
+	number, err := strconv.ParseInt(seq, 10, 64)
+	if err != nil {
+		return BackendMessage{}, fmt.Errorf("invalid room message sequence number: %w", err)
+	}
+	return BackendMessage{RoomID: roomID, Seq: number, Data: []byte(data)}, nil
+}
@@ -0,0 +1,171 @@
+package websocket
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// JSONSchema is the subset of JSON Schema the protocol document uses
+type JSONSchema struct {
+	Ref                  string                 `json:"$ref,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+}
+
+// CommandSchema describes one command
+type CommandSchema struct {
+	Description string      `json:"description"`
+	Params      *JSONSchema `json:"params"`
+	Result      *JSONSchema `json:"result"`
+}
+
+// EventSchema describes one room event
+type EventSchema struct {
+	Description string      `json:"description"`
+	Data        *JSONSchema `json:"data"`
+}
+
+// ProtocolSchema is the machine-readable description of the websocket protocol
+type ProtocolSchema struct {
+	Schema     string                   `json:"$schema"`
+	Versions   []int                    `json:"versions"`
+	Frames     map[string]*JSONSchema   `json:"frames"`
+	Commands   map[string]CommandSchema `json:"commands"`
+	Events     map[string]EventSchema   `json:"events"`
+	ErrorCodes []string                 `json:"errorCodes"`
+	Defs       map[string]*JSONSchema   `json:"$defs"`
+}
+
+// Schema describes every frame, command and event of the protocol. Named types are
+// listed once under $defs and referenced from where they are used.
+func (p *Protocol) Schema() *ProtocolSchema {
+	builder := &schemaBuilder{defs: make(map[string]*JSONSchema)}
+	doc := &ProtocolSchema{
+		Schema:   "https://json-schema.org/draft/2020-12/schema",
+		Versions: []int{ProtocolV1, ProtocolV2},
+		Frames: map[string]*JSONSchema{
+			FrameTypeRequest:  builder.schemaFor(reflect.TypeOf(RequestFrame{})),
+			FrameTypeResponse: builder.schemaFor(reflect.TypeOf(ResponseFrame{})),
+			FrameTypeError:    builder.schemaFor(reflect.TypeOf(ErrorFrame{})),
+			"event":           builder.schemaFor(reflect.TypeOf(Message{})),
+		},
+		Commands: make(map[string]CommandSchema, len(p.commands)),
+		Events:   make(map[string]EventSchema, len(p.events)),
+		ErrorCodes: []string{
+			ErrCodeInvalidFrame, ErrCodeUnknownCommand, ErrCodeInvalidParams, ErrCodeForbidden,
+			ErrCodeNotFound, ErrCodeCommandFailed, ErrCodeUnsupportedProtocol,
+		},
+		Defs: builder.defs,
+	}
+	for name, cmd := range p.commands {
+		doc.Commands[name] = CommandSchema{
+			Description: cmd.description,
+			Params:      builder.schemaFor(cmd.params),
+			Result:      builder.schemaFor(cmd.result),
+		}
+	}
+	for name, evt := range p.events {
+		doc.Events[name] = EventSchema{Description: evt.description, Data: builder.schemaFor(evt.data)}
+	}
+	return doc
+}
+
+type schemaBuilder struct {
+	defs map[string]*JSONSchema
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+func (b *schemaBuilder) schemaFor(t reflect.Type) *JSONSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &JSONSchema{Type: "string", Format: "date-time"}
+	case t == rawMessageType || t.Kind() == reflect.Interface:
+		return &JSONSchema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &JSONSchema{Type: "string", Format: "byte"}
+		}
+		return &JSONSchema{Type: "array", Items: b.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: b.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		name := path.Base(t.PkgPath()) + "." + t.Name()
+		if _, ok := b.defs[name]; !ok {
+			b.defs[name] = &JSONSchema{} // placeholder so recursive types terminate
+			b.defs[name] = b.structSchema(t)
+		}
+		return &JSONSchema{Ref: "#/$defs/" + name}
+	}
+	return &JSONSchema{}
+}
+
+// structSchema lists a struct's JSON fields, flattening embedded structs the way
+// encoding/json does. Fields with a required validate tag are required.
+func (b *schemaBuilder) structSchema(t reflect.Type) *JSONSchema {
+	schema := &JSONSchema{Type: "object", Properties: make(map[string]*JSONSchema)}
+	b.addFields(schema, t)
+	return schema
+}
+
+func (b *schemaBuilder) addFields(schema *JSONSchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.SplitN(tag, ",", 2)[0]
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			b.addFields(schema, fieldType)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = b.schemaFor(field.Type)
+		for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+			if rule == "required" {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+	}
+}
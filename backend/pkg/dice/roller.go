@@ -24,6 +24,14 @@ func NewRoller() *Roller {
 	}
 }
 
+// NewRollerWithRandom creates a roller that draws from rng, so a seeded
+// generator repeats the same rolls
+func NewRollerWithRandom(rng *game.Random) *Roller {
+	return &Roller{
+		rng: rng,
+	}
+}
+
 // Roll parses dice notation like "2d6+3" or "1d20-2"
 func (r *Roller) Roll(notation string) (*RollResult, error) {
 	// Parse dice notation using regex
@@ -152,7 +152,7 @@ export class SpellSlotManager {
                 restType: restType
             });
             
-            this.character = response;
+            this.character = response.character || response;
             this.render();
             
             // Notify other components
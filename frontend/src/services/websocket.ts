@@ -3,6 +3,7 @@ import { createElement } from '../utils/dom';
 
 interface WebSocketMessage {
   type: string;
+  seq?: number;
   data: Record<string, unknown>;
 }
 
@@ -35,9 +36,12 @@ export class WebSocketService {
   private maxReconnectAttempts: number = 10;
   private reconnectAttempts: number = 0;
   private cleanupFunctions: Set<CleanupFunction> = new Set();
+  // Last room message seen, sent on reconnect so the server replays what was missed
+  private lastSeq: number | null = null;
 
   connect(roomId: string): void {
     // Clean up any existing connection
+    const previousRoomId = this.roomId;
     this.cleanup();
     
     if (!authService.isAuthenticated()) {
@@ -45,6 +49,9 @@ export class WebSocketService {
       return;
     }
 
+    if (previousRoomId !== roomId) {
+      this.lastSeq = null;
+    }
     this.roomId = roomId;
     this.user = authService.getCurrentUser();
     this.isIntentionalDisconnect = false;
@@ -94,7 +101,8 @@ export class WebSocketService {
             this.ws?.send(JSON.stringify({
               type: 'auth',
               token: token,
-              room: this.roomId
+              room: this.roomId,
+              ...(this.lastSeq !== null && { last_seq: this.lastSeq })
             }));
           } else {
             console.error('No access token available');
@@ -125,6 +133,13 @@ export class WebSocketService {
           return;
         }
         
+        if (typeof message.seq === 'number') {
+          this.lastSeq = message.seq;
+        } else if (message.type === 'snapshot_required' && typeof message.data?.lastSeq === 'number') {
+          // Handlers refetch the game state, which covers everything up to lastSeq
+          this.lastSeq = message.data.lastSeq;
+        }
+
         // Only handle other messages if authenticated
         if (isAuthenticated) {
           this.handleMessage(message);